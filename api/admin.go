@@ -44,6 +44,8 @@ type loginAdminResponse struct {
 	RefreshToken          string        `json:"refresh_token"`
 	RefreshTokenExpiresAt time.Time     `json:"refresh_token_expires_at"`
 	Admin                 adminResponse `json:"admin"`
+	// RecoveryCodes are only returned once, when two-factor authentication was set up during the login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 func (server *Server) loginAdmin(ctx fiber.Ctx) error {
@@ -54,9 +56,14 @@ func (server *Server) loginAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if server.throttled(ctx, throttleScopeAdminLogin, req.Email) {
+		return nil
+	}
+
 	admin, err := server.store.GetAdminByEmail(ctx.Context(), req.Email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
+			server.recordFailedAttempt(ctx, throttleScopeAdminLogin, req.Email)
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
 			return nil
 		}
@@ -77,20 +84,47 @@ func (server *Server) loginAdmin(ctx fiber.Ctx) error {
 
 	err = util.CheckPassword(req.Password, admin.Password)
 	if err != nil {
+		server.recordFailedAttempt(ctx, throttleScopeAdminLogin, req.Email)
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
+	server.resetFailedAttempts(ctx, throttleScopeAdminLogin, req.Email)
+
+	// with two-factor authentication the password only earns a challenge for the second step
+	challenge, err := server.adminLoginChallenge(ctx, admin)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	if challenge != nil {
+		ctx.Status(fiber.StatusOK).JSON(challenge)
+		return nil
+	}
+
+	rsp, err := server.newAdminLoginResponse(ctx, admin)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// newAdminLoginResponse starts a new admin session and issues its access and refresh tokens
+func (server *Server) newAdminLoginResponse(ctx fiber.Ctx, admin *db.Admin) (*loginAdminResponse, error) {
+	sessionID := uuid.New()
+
 	accessToken, accessPayload, err := server.adminTokenMaker.CreateTokenForAdmin(
 		admin.ID,
 		admin.Username,
 		admin.TypeID,
 		admin.Active,
+		sessionID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
+		return nil, err
 	}
 
 	refreshToken, refreshPayload, err := server.adminTokenMaker.CreateTokenForAdmin(
@@ -98,38 +132,37 @@ func (server *Server) loginAdmin(ctx fiber.Ctx) error {
 		admin.Username,
 		admin.TypeID,
 		admin.Active,
+		sessionID,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
+		return nil, err
 	}
 
 	arg := db.CreateAdminSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		AdminID:      admin.ID,
 		RefreshToken: refreshToken,
 		AdminAgent:   string(ctx.UserAgent()),
 		ClientIp:     ctx.IP(),
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		// a login starts a new session family
+		FamilyID: sessionID,
 	}
 
 	adminSession, err := server.store.CreateAdminSession(ctx.Context(), arg)
 	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
+		return nil, err
 	}
 
-	rsp := loginAdminResponse{
+	return &loginAdminResponse{
 		AdminSessionID:        adminSession.ID.String(),
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
 		Admin:                 newAdminResponse(*admin),
-	}
-	ctx.Status(fiber.StatusOK).JSON(rsp)
-	return nil
+	}, nil
 }
 
 // //////////////* Logout API //////////////
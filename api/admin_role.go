@@ -0,0 +1,423 @@
+package api
+
+import (
+	"errors"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type adminRoleResponse struct {
+	Role        *db.AdminRole `json:"role"`
+	Permissions []string      `json:"permissions"`
+}
+
+// newAdminRoleResponses pairs every role with its permissions
+func newAdminRoleResponses(roles []*db.AdminRole, rolePermissions []*db.AdminRolePermission) []adminRoleResponse {
+	permissions := make(map[int64][]string, len(roles))
+	for _, rolePermission := range rolePermissions {
+		permissions[rolePermission.RoleID] = append(permissions[rolePermission.RoleID], rolePermission.Permission)
+	}
+
+	rsp := make([]adminRoleResponse, 0, len(roles))
+	for _, role := range roles {
+		rolePermissions := permissions[role.ID]
+		if rolePermissions == nil {
+			rolePermissions = []string{}
+		}
+		rsp = append(rsp, adminRoleResponse{
+			Role:        role,
+			Permissions: rolePermissions,
+		})
+	}
+	return rsp
+}
+
+// listAdminRoleResponses loads the permissions of the roles in one query
+func (server *Server) listAdminRoleResponses(ctx fiber.Ctx, roles []*db.AdminRole) ([]adminRoleResponse, error) {
+	roleIDs := make([]int64, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	rolePermissions, err := server.store.ListAdminRolePermissionsByRoleIDs(ctx.Context(), roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAdminRoleResponses(roles, rolePermissions), nil
+}
+
+//////////////* Permissions List API //////////////
+
+type listAdminPermissionsParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+func (server *Server) listAdminPermissions(ctx fiber.Ctx) error {
+	params := &listAdminPermissionsParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	permissions, err := server.store.ListAdminPermissions(ctx.Context())
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(permissions)
+	return nil
+}
+
+//////////////* Create API //////////////
+
+type createAdminRoleParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type createAdminRoleJsonRequest struct {
+	Name        string   `json:"name" validate:"required,max=64"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions" validate:"required,min=1,unique,dive,admin_permission"`
+}
+
+func (server *Server) createAdminRole(ctx fiber.Ctx) error {
+	params := &createAdminRoleParamsRequest{}
+	req := &createAdminRoleJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.CreateAdminRoleTxParams{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+
+	role, err := server.store.CreateAdminRoleTx(ctx.Context(), arg)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Message {
+			case util.ForeignKeyViolation, util.UniqueViolation:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(role)
+	return nil
+}
+
+//////////////* Get API //////////////
+
+type getAdminRoleParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+	RoleID  int64 `uri:"roleId" validate:"required,min=1"`
+}
+
+func (server *Server) getAdminRole(ctx fiber.Ctx) error {
+	params := &getAdminRoleParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	role, err := server.store.GetAdminRole(ctx.Context(), params.RoleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp, err := server.listAdminRoleResponses(ctx, []*db.AdminRole{role})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp[0])
+	return nil
+}
+
+//////////////* List API //////////////
+
+type listAdminRolesParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type listAdminRolesQueryRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=10"`
+}
+
+func (server *Server) listAdminRoles(ctx fiber.Ctx) error {
+	params := &listAdminRolesParamsRequest{}
+	query := &listAdminRolesQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.ListAdminRolesParams{
+		Limit:  query.PageSize,
+		Offset: (query.PageID - 1) * query.PageSize,
+	}
+
+	roles, err := server.store.ListAdminRoles(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp, err := server.listAdminRoleResponses(ctx, roles)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+//////////////* Update API //////////////
+
+type updateAdminRoleParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+	RoleID  int64 `uri:"roleId" validate:"required,min=1"`
+}
+
+type updateAdminRoleJsonRequest struct {
+	Name        *string   `json:"name" validate:"omitempty,required,max=64"`
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+	Permissions *[]string `json:"permissions" validate:"omitempty,unique,dive,admin_permission"`
+}
+
+func (server *Server) updateAdminRole(ctx fiber.Ctx) error {
+	params := &updateAdminRoleParamsRequest{}
+	req := &updateAdminRoleJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.UpdateAdminRoleTxParams{
+		ID:          params.RoleID,
+		Name:        null.StringFromPtr(req.Name),
+		Description: null.StringFromPtr(req.Description),
+	}
+	if req.Permissions != nil {
+		arg.Permissions = append([]string{}, *req.Permissions...)
+	}
+
+	role, err := server.store.UpdateAdminRoleTx(ctx.Context(), arg)
+	if err != nil {
+		var protectedErr *db.ProtectedAdminRoleError
+		if errors.As(err, &protectedErr) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Message {
+			case util.ForeignKeyViolation, util.UniqueViolation:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(role)
+	return nil
+}
+
+//////////////* Delete API //////////////
+
+type deleteAdminRoleParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+	RoleID  int64 `uri:"roleId" validate:"required,min=1"`
+}
+
+func (server *Server) deleteAdminRole(ctx fiber.Ctx) error {
+	params := &deleteAdminRoleParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	_, err := server.store.DeleteAdminRoleTx(ctx.Context(), params.RoleID)
+	if err != nil {
+		var protectedErr *db.ProtectedAdminRoleError
+		if errors.As(err, &protectedErr) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+//////////////* Assignment API //////////////
+
+type adminRolesParamsRequest struct {
+	AdminID       int64 `uri:"adminId" validate:"required,min=1"`
+	TargetAdminID int64 `uri:"id" validate:"required,min=1"`
+}
+
+func (server *Server) listRolesOfAdmin(ctx fiber.Ctx) error {
+	params := &adminRolesParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	roles, err := server.store.ListAdminRolesByAdminID(ctx.Context(), params.TargetAdminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp, err := server.listAdminRoleResponses(ctx, roles)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+type setAdminRolesJsonRequest struct {
+	RoleIDs []int64 `json:"role_ids" validate:"required,max=20,unique,dive,min=1"`
+}
+
+func (server *Server) setAdminRoles(ctx fiber.Ctx) error {
+	params := &adminRolesParamsRequest{}
+	req := &setAdminRolesJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	//? an admin can't take the role management away from themselves
+	if params.TargetAdminID == authPayload.AdminID {
+		err := errors.New("admins can't change their own roles")
+		ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.SetAdminRolesTxParams{
+		AdminID: params.TargetAdminID,
+		RoleIDs: req.RoleIDs,
+	}
+
+	roles, err := server.store.SetAdminRolesTx(ctx.Context(), arg)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Message {
+			case util.ForeignKeyViolation, util.UniqueViolation:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp, err := server.listAdminRoleResponses(ctx, roles)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
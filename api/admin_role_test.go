@@ -0,0 +1,679 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateAdminRoleAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	role := randomAdminRole()
+	permissions := []string{db.PermissionOrdersRead, db.PermissionOrdersManage}
+
+	testCases := []struct {
+		name          string
+		AdminID       int64
+		body          fiber.Map
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"description": role.Description,
+				"permissions": permissions,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAdminRoleTxParams{
+					Name:        role.Name,
+					Description: role.Description,
+					Permissions: permissions,
+				}
+
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.AdminRoleTxResult{Role: role, Permissions: permissions}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchAdminRole(t, rsp.Body, role, permissions)
+			},
+		},
+		{
+			name:    "UnknownPermission",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"permissions": []string{"prices:delete"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "DuplicatePermission",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"permissions": []string{db.PermissionOrdersRead, db.PermissionOrdersRead},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "DuplicateName",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"permissions": permissions,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Message: util.UniqueViolation})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Forbidden",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"permissions": permissions,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionRolesManage})).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Unauthorized",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"permissions": permissions,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID+1, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InternalError",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"name":        role.Name,
+				"permissions": permissions,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/roles", tc.AdminID)
+			request, err := http.NewRequest(fiber.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListAdminRolesAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	n := 5
+	roles := make([]*db.AdminRole, n)
+	rolePermissions := make([]*db.AdminRolePermission, n)
+	for i := 0; i < n; i++ {
+		roles[i] = randomAdminRole()
+		rolePermissions[i] = &db.AdminRolePermission{RoleID: roles[i].ID, Permission: db.PermissionOrdersRead}
+	}
+
+	testCases := []struct {
+		name          string
+		pageSize      int
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:     "OK",
+			pageSize: n,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAdminRoles(gomock.Any(), gomock.Eq(db.ListAdminRolesParams{Limit: int32(n), Offset: 0})).
+					Times(1).
+					Return(roles, nil)
+
+				roleIDs := make([]int64, n)
+				for i, role := range roles {
+					roleIDs[i] = role.ID
+				}
+
+				store.EXPECT().
+					ListAdminRolePermissionsByRoleIDs(gomock.Any(), gomock.Eq(roleIDs)).
+					Times(1).
+					Return(rolePermissions, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotRoles []adminRoleResponse
+				err = json.Unmarshal(data, &gotRoles)
+				require.NoError(t, err)
+				require.Len(t, gotRoles, n)
+				for i := range gotRoles {
+					require.Equal(t, roles[i].ID, gotRoles[i].Role.ID)
+					require.Equal(t, []string{db.PermissionOrdersRead}, gotRoles[i].Permissions)
+				}
+			},
+		},
+		{
+			name:     "InvalidPageSize",
+			pageSize: 100,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAdminRoles(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InternalError",
+			pageSize: n,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAdminRoles(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/roles?page_id=%d&page_size=%d", admin.ID, 1, tc.pageSize)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationForAdmin(t, request, server.adminTokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestUpdateAdminRoleAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	role := randomAdminRole()
+	permissions := []string{db.PermissionCatalogManage}
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{
+				"description": role.Description,
+				"permissions": permissions,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAdminRoleTxParams{
+					ID:          role.ID,
+					Description: null.StringFrom(role.Description),
+					Permissions: permissions,
+				}
+
+				store.EXPECT().
+					UpdateAdminRoleTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.AdminRoleTxResult{Role: role, Permissions: permissions}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchAdminRole(t, rsp.Body, role, permissions)
+			},
+		},
+		{
+			name: "OKKeepPermissions",
+			body: fiber.Map{
+				"name": role.Name,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAdminRoleTxParams{
+					ID:   role.ID,
+					Name: null.StringFrom(role.Name),
+				}
+
+				store.EXPECT().
+					UpdateAdminRoleTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.AdminRoleTxResult{Role: role, Permissions: permissions}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "OKClearPermissions",
+			body: fiber.Map{
+				"permissions": []string{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAdminRoleTxParams{
+					ID:          role.ID,
+					Permissions: []string{},
+				}
+
+				store.EXPECT().
+					UpdateAdminRoleTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.AdminRoleTxResult{Role: role, Permissions: []string{}}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ProtectedRole",
+			body: fiber.Map{
+				"permissions": permissions,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.ProtectedAdminRoleError{Name: db.SuperAdminRole})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NotFound",
+			body: fiber.Map{
+				"permissions": permissions,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "UnknownPermission",
+			body: fiber.Map{
+				"permissions": []string{"orders:delete"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAdminRoleTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/roles/%d", admin.ID, role.ID)
+			request, err := http.NewRequest(fiber.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			addAuthorizationForAdmin(t, request, server.adminTokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestDeleteAdminRoleAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	role := randomAdminRole()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteAdminRoleTx(gomock.Any(), gomock.Eq(role.ID)).
+					Times(1).
+					Return(role, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ProtectedRole",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteAdminRoleTx(gomock.Any(), gomock.Eq(role.ID)).
+					Times(1).
+					Return(nil, &db.ProtectedAdminRoleError{Name: db.SuperAdminRole})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteAdminRoleTx(gomock.Any(), gomock.Eq(role.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/roles/%d", admin.ID, role.ID)
+			request, err := http.NewRequest(fiber.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationForAdmin(t, request, server.adminTokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestSetAdminRolesAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	targetAdminID := admin.ID + 1
+	role := randomAdminRole()
+
+	testCases := []struct {
+		name          string
+		TargetAdminID int64
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:          "OK",
+			TargetAdminID: targetAdminID,
+			body: fiber.Map{
+				"role_ids": []int64{role.ID},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetAdminRolesTxParams{
+					AdminID: targetAdminID,
+					RoleIDs: []int64{role.ID},
+				}
+
+				store.EXPECT().
+					SetAdminRolesTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]*db.AdminRole{role}, nil)
+
+				store.EXPECT().
+					ListAdminRolePermissionsByRoleIDs(gomock.Any(), gomock.Eq([]int64{role.ID})).
+					Times(1).
+					Return([]*db.AdminRolePermission{{RoleID: role.ID, Permission: db.PermissionOrdersManage}}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotRoles []adminRoleResponse
+				err = json.Unmarshal(data, &gotRoles)
+				require.NoError(t, err)
+				require.Len(t, gotRoles, 1)
+				require.Equal(t, role.ID, gotRoles[0].Role.ID)
+				require.Equal(t, []string{db.PermissionOrdersManage}, gotRoles[0].Permissions)
+			},
+		},
+		{
+			name:          "OwnRoles",
+			TargetAdminID: admin.ID,
+			body: fiber.Map{
+				"role_ids": []int64{role.ID},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetAdminRolesTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:          "UnknownRole",
+			TargetAdminID: targetAdminID,
+			body: fiber.Map{
+				"role_ids": []int64{role.ID},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetAdminRolesTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Message: util.ForeignKeyViolation})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:          "InvalidRoleID",
+			TargetAdminID: targetAdminID,
+			body: fiber.Map{
+				"role_ids": []int64{0},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetAdminRolesTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/admins/%d/roles", admin.ID, tc.TargetAdminID)
+			request, err := http.NewRequest(fiber.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			addAuthorizationForAdmin(t, request, server.adminTokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func randomAdminRole() *db.AdminRole {
+	return &db.AdminRole{
+		ID:          util.RandomMoney(),
+		Name:        util.RandomString(8),
+		Description: util.RandomString(20),
+	}
+}
+
+func requireBodyMatchAdminRole(t *testing.T, body io.ReadCloser, role *db.AdminRole, permissions []string) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotRole db.AdminRoleTxResult
+	err = json.Unmarshal(data, &gotRole)
+	require.NoError(t, err)
+	require.Equal(t, role.ID, gotRole.Role.ID)
+	require.Equal(t, role.Name, gotRole.Role.Name)
+	require.Equal(t, permissions, gotRole.Permissions)
+}
@@ -46,6 +46,16 @@ func TestLoginAdminAPI(t *testing.T) {
 					Times(1).
 					Return(admin, nil)
 
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true}, nil)
+
 				store.EXPECT().
 					CreateAdminSession(gomock.Any(), gomock.Any()).
 					Times(1).Return(adminSession, nil)
@@ -0,0 +1,697 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/secure"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/totp"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// twoFactorIssuer is the name the authenticator apps show next to the code
+	twoFactorIssuer             = "CShop"
+	adminLoginChallengeDuration = 5 * time.Minute
+	adminRecoveryCodesCount     = 10
+)
+
+var (
+	errInvalidTwoFactorCode    = errors.New("two-factor code is invalid")
+	errTwoFactorEnabled        = errors.New("two-factor authentication is already enabled")
+	errTwoFactorNotSetUp       = errors.New("two-factor authentication isn't set up")
+	errTwoFactorRequired       = errors.New("two-factor authentication is required for every admin")
+	errTwoFactorCodeOrRecovery = errors.New("either code or recovery_code is required")
+	errLoginChallengeNotFound  = errors.New("login challenge is invalid or expired")
+	errTwoFactorNotStarted     = errors.New("two-factor authentication has to be set up before it can be confirmed")
+)
+
+type adminLoginChallengeResponse struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// TwoFactorEnrolled is false when every admin has to use two-factor authentication
+	// and this one still has to set it up with the challenge token
+	TwoFactorEnrolled  bool      `json:"two_factor_enrolled"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+type adminTwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type adminRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// newAdminRecoveryCodes returns the codes shown once to the admin and the hashes to store
+func newAdminRecoveryCodes() (codes []string, hashes []string) {
+	codes = make([]string, 0, adminRecoveryCodesCount)
+	hashes = make([]string, 0, adminRecoveryCodesCount)
+	for range adminRecoveryCodesCount {
+		code := secure.RecoveryCode()
+		codes = append(codes, code)
+		hashes = append(hashes, hashAdminRecoveryCode(code))
+	}
+	return codes, hashes
+}
+
+// hashAdminRecoveryCode ignores the case, the spaces and the dash of the code
+func hashAdminRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(code, "-", ""), " ", ""))
+	return secure.Hash(code)
+}
+
+func newAdminTwoFactorSetupResponse(admin *db.Admin, adminTotp *db.AdminTotp) adminTwoFactorSetupResponse {
+	return adminTwoFactorSetupResponse{
+		Secret:     adminTotp.Secret,
+		OtpauthURI: totp.URI(twoFactorIssuer, admin.Email, adminTotp.Secret),
+	}
+}
+
+// getAdminTotp returns nil when the admin never started setting up two-factor authentication
+func (server *Server) getAdminTotp(ctx context.Context, adminID int64) (*db.AdminTotp, error) {
+	adminTotp, err := server.store.GetAdminTotp(ctx, adminID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return adminTotp, nil
+}
+
+// adminLoginChallenge returns nil when the admin can login with the password alone,
+// otherwise it creates the challenge the second step of the login has to answer
+func (server *Server) adminLoginChallenge(ctx fiber.Ctx, admin *db.Admin) (*adminLoginChallengeResponse, error) {
+	adminTotp, err := server.getAdminTotp(ctx.Context(), admin.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolled := adminTotp != nil && adminTotp.Enabled
+	if !enrolled {
+		setting, err := server.store.GetAdminSecuritySetting(ctx.Context())
+		if err != nil {
+			return nil, err
+		}
+		if !setting.RequireTwoFactor {
+			return nil, nil
+		}
+	}
+
+	challengeToken := secure.Secret(32)
+	challenge, err := server.store.CreateAdminLoginChallenge(ctx.Context(), db.CreateAdminLoginChallengeParams{
+		AdminID:   admin.ID,
+		TokenHash: secure.Hash(challengeToken),
+		ExpiredAt: time.Now().Add(adminLoginChallengeDuration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &adminLoginChallengeResponse{
+		TwoFactorRequired:  true,
+		TwoFactorEnrolled:  enrolled,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: challenge.ExpiredAt,
+	}, nil
+}
+
+// checkAdminTotpCode validates a code of an enabled totp and burns its time step so the code can't be replayed,
+// a wrong or replayed code returns false without an error
+func (server *Server) checkAdminTotpCode(ctx context.Context, adminTotp *db.AdminTotp, code string) (bool, error) {
+	step, err := totp.Validate(adminTotp.Secret, code, time.Now())
+	if err != nil {
+		if errors.Is(err, totp.ErrInvalidCode) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = server.store.UpdateAdminTotpLastUsedStep(ctx, db.UpdateAdminTotpLastUsedStepParams{
+		AdminID:      adminTotp.AdminID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// authorizedAdmin writes a 401 unless the authenticated admin is the one of the route
+func authorizedAdmin(ctx fiber.Ctx, adminID int64) bool {
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != adminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// //////////////* Login Challenge API //////////////
+
+type setupAdminLoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// setupAdminLoginTwoFactor lets an admin who has to use two-factor authentication set it up
+// with the challenge token of the login, the code of the second step then confirms it
+func (server *Server) setupAdminLoginTwoFactor(ctx fiber.Ctx) error {
+	req := &setupAdminLoginTwoFactorRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	challenge, err := server.store.GetAdminLoginChallenge(ctx.Context(), secure.Hash(req.ChallengeToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			server.recordFailedAttempt(ctx, throttleScopeAdminTwoFactor, secure.Hash(req.ChallengeToken))
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(errLoginChallengeNotFound))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	admin, err := server.store.GetAdmin(ctx.Context(), challenge.AdminID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	adminTotp, err := server.store.UpsertAdminTotp(ctx.Context(), db.UpsertAdminTotpParams{
+		AdminID: admin.ID,
+		Secret:  totp.GenerateSecret(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(errTwoFactorEnabled))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(newAdminTwoFactorSetupResponse(admin, adminTotp))
+	return nil
+}
+
+type verifyAdminLoginChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code" validate:"omitempty,max=32"`
+}
+
+// verifyAdminLoginChallenge is the second step of the login, it exchanges the challenge token
+// and a totp or recovery code for the access and refresh tokens
+func (server *Server) verifyAdminLoginChallenge(ctx fiber.Ctx) error {
+	req := &verifyAdminLoginChallengeRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errTwoFactorCodeOrRecovery))
+		return nil
+	}
+
+	challenge, err := server.store.GetAdminLoginChallenge(ctx.Context(), secure.Hash(req.ChallengeToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			server.recordFailedAttempt(ctx, throttleScopeAdminTwoFactor, secure.Hash(req.ChallengeToken))
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(errLoginChallengeNotFound))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	account := strconv.FormatInt(challenge.AdminID, 10)
+	if server.throttled(ctx, throttleScopeAdminTwoFactor, account) {
+		return nil
+	}
+
+	if challenge.Attempts >= maxOTPAttempts {
+		ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+		return nil
+	}
+
+	admin, err := server.store.GetAdmin(ctx.Context(), challenge.AdminID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !admin.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	adminTotp, err := server.getAdminTotp(ctx.Context(), admin.ID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	var ok bool
+	var recoveryCodes []string
+
+	switch {
+	case adminTotp != nil && adminTotp.Enabled && req.Code != "":
+		ok, err = server.checkAdminTotpCode(ctx.Context(), adminTotp, req.Code)
+
+	case adminTotp != nil && adminTotp.Enabled:
+		_, err = server.store.UseAdminRecoveryCode(ctx.Context(), db.UseAdminRecoveryCodeParams{
+			AdminID:  admin.ID,
+			CodeHash: hashAdminRecoveryCode(req.RecoveryCode),
+		})
+		ok = err == nil
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+
+	case adminTotp != nil && req.Code != "":
+		// the code of the pending totp confirms the enrolment started by setupAdminLoginTwoFactor
+		var step int64
+		step, err = totp.Validate(adminTotp.Secret, req.Code, time.Now())
+		if err != nil {
+			if errors.Is(err, totp.ErrInvalidCode) {
+				err = nil
+			}
+			break
+		}
+
+		var hashes []string
+		recoveryCodes, hashes = newAdminRecoveryCodes()
+		_, err = server.store.EnableAdminTotpTx(ctx.Context(), db.EnableAdminTotpTxParams{
+			AdminID:            admin.ID,
+			LastUsedStep:       step,
+			RecoveryCodeHashes: hashes,
+		})
+		ok = err == nil
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+
+	default:
+		ctx.Status(fiber.StatusConflict).JSON(errorResponse(errTwoFactorNotSetUp))
+		return nil
+	}
+
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !ok {
+		server.recordFailedAttempt(ctx, throttleScopeAdminTwoFactor, account)
+
+		challenge, err = server.store.IncrementAdminLoginChallengeAttempts(ctx.Context(), db.IncrementAdminLoginChallengeAttemptsParams{
+			MaxAttempts: maxOTPAttempts,
+			ID:          challenge.ID,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+		if err == nil && challenge.Attempts >= maxOTPAttempts {
+			ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+			return nil
+		}
+
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errInvalidTwoFactorCode))
+		return nil
+	}
+
+	// the challenge is single-use, two requests racing with the same token can't both login
+	_, err = server.store.UseAdminLoginChallenge(ctx.Context(), challenge.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(errLoginChallengeNotFound))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	server.resetFailedAttempts(ctx, throttleScopeAdminTwoFactor, account)
+
+	rsp, err := server.newAdminLoginResponse(ctx, admin)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	rsp.RecoveryCodes = recoveryCodes
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// //////////////* Two-Factor Status API //////////////
+
+type adminTwoFactorParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type adminTwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+func (server *Server) getAdminTwoFactor(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	adminTotp, err := server.getAdminTotp(ctx.Context(), params.AdminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	setting, err := server.store.GetAdminSecuritySetting(ctx.Context())
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := adminTwoFactorStatusResponse{
+		Enabled:  adminTotp != nil && adminTotp.Enabled,
+		Required: setting.RequireTwoFactor,
+	}
+
+	if rsp.Enabled {
+		rsp.RecoveryCodesLeft, err = server.store.CountAdminRecoveryCodesLeft(ctx.Context(), params.AdminID)
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// //////////////* Two-Factor Setup API //////////////
+
+// setupAdminTwoFactor generates a new secret, it isn't asked at login until confirmAdminTwoFactor enables it
+func (server *Server) setupAdminTwoFactor(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	admin, err := server.store.GetAdmin(ctx.Context(), params.AdminID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	adminTotp, err := server.store.UpsertAdminTotp(ctx.Context(), db.UpsertAdminTotpParams{
+		AdminID: admin.ID,
+		Secret:  totp.GenerateSecret(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(errTwoFactorEnabled))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(newAdminTwoFactorSetupResponse(admin, adminTotp))
+	return nil
+}
+
+// //////////////* Two-Factor Confirm API //////////////
+
+type adminTwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// confirmAdminTwoFactor enables the secret of setupAdminTwoFactor once the admin proves the app shows its codes
+func (server *Server) confirmAdminTwoFactor(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+	req := &adminTwoFactorCodeRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	account := strconv.FormatInt(params.AdminID, 10)
+	if server.throttled(ctx, throttleScopeAdminTwoFactor, account) {
+		return nil
+	}
+
+	adminTotp, err := server.getAdminTotp(ctx.Context(), params.AdminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	if adminTotp == nil {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(errTwoFactorNotStarted))
+		return nil
+	}
+	if adminTotp.Enabled {
+		ctx.Status(fiber.StatusConflict).JSON(errorResponse(errTwoFactorEnabled))
+		return nil
+	}
+
+	step, err := totp.Validate(adminTotp.Secret, req.Code, time.Now())
+	if err != nil {
+		if errors.Is(err, totp.ErrInvalidCode) {
+			server.recordFailedAttempt(ctx, throttleScopeAdminTwoFactor, account)
+			ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errInvalidTwoFactorCode))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	codes, hashes := newAdminRecoveryCodes()
+	_, err = server.store.EnableAdminTotpTx(ctx.Context(), db.EnableAdminTotpTxParams{
+		AdminID:            params.AdminID,
+		LastUsedStep:       step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(errTwoFactorEnabled))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	server.resetFailedAttempts(ctx, throttleScopeAdminTwoFactor, account)
+
+	ctx.Status(fiber.StatusOK).JSON(adminRecoveryCodesResponse{RecoveryCodes: codes})
+	return nil
+}
+
+// checkEnabledAdminTwoFactor loads the enabled totp of the admin and checks the code against it,
+// it writes the response and returns nil when the request can't go on
+func (server *Server) checkEnabledAdminTwoFactor(ctx fiber.Ctx, adminID int64, code string) *db.AdminTotp {
+	account := strconv.FormatInt(adminID, 10)
+	if server.throttled(ctx, throttleScopeAdminTwoFactor, account) {
+		return nil
+	}
+
+	adminTotp, err := server.getAdminTotp(ctx.Context(), adminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	if adminTotp == nil || !adminTotp.Enabled {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(errTwoFactorNotSetUp))
+		return nil
+	}
+
+	ok, err := server.checkAdminTotpCode(ctx.Context(), adminTotp, code)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	if !ok {
+		server.recordFailedAttempt(ctx, throttleScopeAdminTwoFactor, account)
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errInvalidTwoFactorCode))
+		return nil
+	}
+
+	server.resetFailedAttempts(ctx, throttleScopeAdminTwoFactor, account)
+	return adminTotp
+}
+
+// //////////////* Recovery Codes API //////////////
+
+// regenerateAdminRecoveryCodes replaces every recovery code of the admin, used or not
+func (server *Server) regenerateAdminRecoveryCodes(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+	req := &adminTwoFactorCodeRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	if server.checkEnabledAdminTwoFactor(ctx, params.AdminID, req.Code) == nil {
+		return nil
+	}
+
+	codes, hashes := newAdminRecoveryCodes()
+	err := server.store.ReplaceAdminRecoveryCodes(ctx.Context(), db.ReplaceAdminRecoveryCodesParams{
+		AdminID:    params.AdminID,
+		CodeHashes: hashes,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(adminRecoveryCodesResponse{RecoveryCodes: codes})
+	return nil
+}
+
+// //////////////* Two-Factor Disable API //////////////
+
+func (server *Server) disableAdminTwoFactor(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+	req := &adminTwoFactorCodeRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	setting, err := server.store.GetAdminSecuritySetting(ctx.Context())
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	if setting.RequireTwoFactor {
+		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errTwoFactorRequired))
+		return nil
+	}
+
+	if server.checkEnabledAdminTwoFactor(ctx, params.AdminID, req.Code) == nil {
+		return nil
+	}
+
+	err = server.store.DeleteAdminTotp(ctx.Context(), params.AdminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+// //////////////* Security Settings API //////////////
+
+func (server *Server) getAdminSecuritySetting(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	setting, err := server.store.GetAdminSecuritySetting(ctx.Context())
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(setting)
+	return nil
+}
+
+type updateAdminSecuritySettingRequest struct {
+	RequireTwoFactor *bool `json:"require_two_factor" validate:"required"`
+}
+
+// updateAdminSecuritySetting lets a super admin require two-factor authentication from every admin,
+// the admins without it are asked to set it up at their next login
+func (server *Server) updateAdminSecuritySetting(ctx fiber.Ctx) error {
+	params := &adminTwoFactorParamsRequest{}
+	req := &updateAdminSecuritySettingRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if !authorizedAdmin(ctx, params.AdminID) {
+		return nil
+	}
+
+	setting, err := server.store.UpdateAdminSecuritySetting(ctx.Context(), *req.RequireTwoFactor)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(setting)
+	return nil
+}
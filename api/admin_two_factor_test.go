@@ -0,0 +1,1097 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/secure"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/totp"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoginAdminTwoFactorAPI(t *testing.T) {
+	admin, password := randomAdminLogin(t)
+	adminTotp := randomAdminTotp(admin, true)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "Enrolled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminByEmail(gomock.Any(), gomock.Eq(admin.Email)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateAdminLoginChallenge(gomock.Any(), EqCreateAdminLoginChallengeParams(admin.ID)).
+					Times(1).
+					Return(&db.AdminLoginChallenge{AdminID: admin.ID, ExpiredAt: time.Now().Add(adminLoginChallengeDuration)}, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				challenge := requireBodyMatchAdminLoginChallenge(t, rsp.Body)
+				require.True(t, challenge.TwoFactorRequired)
+				require.True(t, challenge.TwoFactorEnrolled)
+				require.NotEmpty(t, challenge.ChallengeToken)
+			},
+		},
+		{
+			name: "RequiredNotEnrolled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminByEmail(gomock.Any(), gomock.Eq(admin.Email)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true, RequireTwoFactor: true}, nil)
+
+				store.EXPECT().
+					CreateAdminLoginChallenge(gomock.Any(), EqCreateAdminLoginChallengeParams(admin.ID)).
+					Times(1).
+					Return(&db.AdminLoginChallenge{AdminID: admin.ID, ExpiredAt: time.Now().Add(adminLoginChallengeDuration)}, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				challenge := requireBodyMatchAdminLoginChallenge(t, rsp.Body)
+				require.True(t, challenge.TwoFactorRequired)
+				require.False(t, challenge.TwoFactorEnrolled)
+				require.NotEmpty(t, challenge.ChallengeToken)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminByEmail(gomock.Any(), gomock.Eq(admin.Email)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			rsp := loginAdminRequestForTest(t, server, admin.Email, password)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestVerifyAdminLoginChallengeAPI(t *testing.T) {
+	admin, _ := randomAdminLogin(t)
+	adminSession := randomAdminSession(admin)
+	adminTotp := randomAdminTotp(admin, true)
+	pendingTotp := randomAdminTotp(admin, false)
+
+	challengeToken := secure.Secret(32)
+	challenge := &db.AdminLoginChallenge{
+		ID:        util.RandomMoney(),
+		AdminID:   admin.ID,
+		TokenHash: secure.Hash(challengeToken),
+		ExpiredAt: time.Now().Add(adminLoginChallengeDuration),
+	}
+
+	step := totp.Step(time.Now())
+	code, err := totp.Code(adminTotp.Secret, step)
+	require.NoError(t, err)
+	pendingCode := currentTotpCode(t, pendingTotp.Secret)
+	recoveryCode := secure.RecoveryCode()
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				store.EXPECT().
+					UpdateAdminTotpLastUsedStep(gomock.Any(), gomock.Eq(db.UpdateAdminTotpLastUsedStepParams{
+						AdminID:      admin.ID,
+						LastUsedStep: step,
+					})).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					UseAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(adminSession, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				login := requireBodyMatchLoginAdmin(t, rsp.Body)
+				require.NotEmpty(t, login.AccessToken)
+				require.Empty(t, login.RecoveryCodes)
+			},
+		},
+		{
+			name: "OKWithRecoveryCode",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"recovery_code":   recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				store.EXPECT().
+					UseAdminRecoveryCode(gomock.Any(), gomock.Eq(db.UseAdminRecoveryCodeParams{
+						AdminID:  admin.ID,
+						CodeHash: hashAdminRecoveryCode(recoveryCode),
+					})).
+					Times(1).
+					Return(&db.AdminRecoveryCode{AdminID: admin.ID, IsUsed: true}, nil)
+
+				store.EXPECT().
+					UseAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(adminSession, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "EnrolDuringLogin",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            pendingCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, pendingTotp)
+
+				store.EXPECT().
+					EnableAdminTotpTx(gomock.Any(), EqEnableAdminTotpTxParams(admin.ID)).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					UseAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(adminSession, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				login := requireBodyMatchLoginAdmin(t, rsp.Body)
+				require.Len(t, login.RecoveryCodes, adminRecoveryCodesCount)
+			},
+		},
+		{
+			name: "NotSetUp",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, nil)
+
+				store.EXPECT().
+					UseAdminLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            wrongTotpCode(code),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				store.EXPECT().
+					UpdateAdminTotpLastUsedStep(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				failed := *challenge
+				failed.Attempts = 1
+				store.EXPECT().
+					IncrementAdminLoginChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementAdminLoginChallengeAttemptsParams{
+						MaxAttempts: maxOTPAttempts,
+						ID:          challenge.ID,
+					})).
+					Times(1).
+					Return(&failed, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				store.EXPECT().
+					UpdateAdminTotpLastUsedStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				failed := *challenge
+				failed.Attempts = 1
+				store.EXPECT().
+					IncrementAdminLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&failed, nil)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"recovery_code":   recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				store.EXPECT().
+					UseAdminRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				failed := *challenge
+				failed.Attempts = 1
+				store.EXPECT().
+					IncrementAdminLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&failed, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "LastAttempt",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            wrongTotpCode(code),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				failed := *challenge
+				failed.Attempts = maxOTPAttempts
+				store.EXPECT().
+					IncrementAdminLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&failed, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
+		{
+			name: "AttemptsExceeded",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				usedUp := *challenge
+				usedUp.Attempts = maxOTPAttempts
+				store.EXPECT().
+					GetAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(&usedUp, nil)
+
+				store.EXPECT().
+					GetAdmin(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
+		{
+			name: "ChallengeNotFound",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					GetAdmin(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ChallengeUsedConcurrently",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAdminLoginChallengeStubs(store, challenge, admin, adminTotp)
+
+				store.EXPECT().
+					UpdateAdminTotpLastUsedStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					UseAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					CreateAdminSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InactiveAdmin",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				inactive := *admin
+				inactive.Active = false
+
+				store.EXPECT().
+					GetAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetAdmin(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(&inactive, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "CodeAndRecoveryCode",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+				"code":            code,
+				"recovery_code":   recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NoCode",
+			body: fiber.Map{
+				"challenge_token": challengeToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			rsp := postJSONForTest(t, server, "/api/v1/admins/login/2fa", tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestSetupAdminTwoFactorAPI(t *testing.T) {
+	admin, _ := randomAdminLogin(t)
+	pendingTotp := randomAdminTotp(admin, false)
+
+	testCases := []struct {
+		name          string
+		AdminID       int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			AdminID: admin.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdmin(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					UpsertAdminTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pendingTotp, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var setup adminTwoFactorSetupResponse
+				err = json.Unmarshal(data, &setup)
+				require.NoError(t, err)
+				require.Equal(t, pendingTotp.Secret, setup.Secret)
+				require.Equal(t, totp.URI(twoFactorIssuer, admin.Email, pendingTotp.Secret), setup.OtpauthURI)
+			},
+		},
+		{
+			name:    "AlreadyEnabled",
+			AdminID: admin.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdmin(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					UpsertAdminTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "UnauthorizedAdmin",
+			AdminID: admin.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID+1, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "NoAuthorization",
+			AdminID: admin.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/2fa/setup", tc.AdminID)
+			request, err := http.NewRequest(fiber.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestConfirmAdminTwoFactorAPI(t *testing.T) {
+	admin, _ := randomAdminLogin(t)
+	adminTotp := randomAdminTotp(admin, true)
+	pendingTotp := randomAdminTotp(admin, false)
+	pendingCode := currentTotpCode(t, pendingTotp.Secret)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{"code": pendingCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					EnableAdminTotpTx(gomock.Any(), EqEnableAdminTotpTxParams(admin.ID)).
+					Times(1).
+					Return(adminTotp, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var codes adminRecoveryCodesResponse
+				err = json.Unmarshal(data, &codes)
+				require.NoError(t, err)
+				require.Len(t, codes.RecoveryCodes, adminRecoveryCodesCount)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: fiber.Map{"code": wrongTotpCode(pendingCode)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					EnableAdminTotpTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			body: fiber.Map{"code": pendingCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					EnableAdminTotpTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NotStarted",
+			body: fiber.Map{"code": pendingCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					EnableAdminTotpTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: fiber.Map{"code": "12ab56"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			rsp := adminJSONRequestForTest(t, server, admin, fiber.MethodPost, fmt.Sprintf("/admin/v1/admins/%d/2fa/confirm", admin.ID), tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestDisableAdminTwoFactorAPI(t *testing.T) {
+	admin, _ := randomAdminLogin(t)
+	adminTotp := randomAdminTotp(admin, true)
+	code := currentTotpCode(t, adminTotp.Secret)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true}, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					UpdateAdminTotpLastUsedStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					DeleteAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "Required",
+			body: fiber.Map{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true, RequireTwoFactor: true}, nil)
+
+				store.EXPECT().
+					DeleteAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: fiber.Map{"code": wrongTotpCode(code)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true}, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(adminTotp, nil)
+
+				store.EXPECT().
+					DeleteAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NotEnabled",
+			body: fiber.Map{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAdminSecuritySetting(gomock.Any()).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true}, nil)
+
+				store.EXPECT().
+					GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					DeleteAdminTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			rsp := adminJSONRequestForTest(t, server, admin, fiber.MethodDelete, fmt.Sprintf("/admin/v1/admins/%d/2fa", admin.ID), tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestUpdateAdminSecuritySettingAPI(t *testing.T) {
+	admin, _ := randomAdminLogin(t)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{"require_two_factor": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAdminSecuritySetting(gomock.Any(), gomock.Eq(true)).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true, RequireTwoFactor: true, UpdatedAt: time.Now()}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "TurnOff",
+			body: fiber.Map{"require_two_factor": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAdminSecuritySetting(gomock.Any(), gomock.Eq(false)).
+					Times(1).
+					Return(&db.AdminSecuritySetting{ID: true, UpdatedAt: time.Now()}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "Forbidden",
+			body: fiber.Map{"require_two_factor": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionSecurityManage})).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					UpdateAdminSecuritySetting(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name: "MissingSetting",
+			body: fiber.Map{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAdminSecuritySetting(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			rsp := adminJSONRequestForTest(t, server, admin, fiber.MethodPut, fmt.Sprintf("/admin/v1/admins/%d/security-settings", admin.ID), tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func buildAdminLoginChallengeStubs(store *mockdb.MockStore, challenge *db.AdminLoginChallenge, admin *db.Admin, adminTotp *db.AdminTotp) {
+	store.EXPECT().
+		GetAdminLoginChallenge(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+		Times(1).
+		Return(challenge, nil)
+
+	store.EXPECT().
+		GetAdmin(gomock.Any(), gomock.Eq(admin.ID)).
+		Times(1).
+		Return(admin, nil)
+
+	if adminTotp == nil {
+		store.EXPECT().
+			GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+			Times(1).
+			Return(nil, pgx.ErrNoRows)
+		return
+	}
+
+	store.EXPECT().
+		GetAdminTotp(gomock.Any(), gomock.Eq(admin.ID)).
+		Times(1).
+		Return(adminTotp, nil)
+}
+
+func adminJSONRequestForTest(t *testing.T, server *Server, admin *db.Admin, method, url string, body fiber.Map) *http.Response {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+
+	addAuthorizationForAdmin(t, request, server.adminTokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+
+	rsp, err := server.router.Test(request)
+	require.NoError(t, err)
+	return rsp
+}
+
+func requireBodyMatchAdminLoginChallenge(t *testing.T, body io.Reader) adminLoginChallengeResponse {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var challenge adminLoginChallengeResponse
+	err = json.Unmarshal(data, &challenge)
+	require.NoError(t, err)
+	return challenge
+}
+
+func requireBodyMatchLoginAdmin(t *testing.T, body io.Reader) loginAdminResponse {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var login loginAdminResponse
+	err = json.Unmarshal(data, &login)
+	require.NoError(t, err)
+	return login
+}
+
+func randomAdminTotp(admin *db.Admin, enabled bool) *db.AdminTotp {
+	return &db.AdminTotp{
+		AdminID:   admin.ID,
+		Secret:    totp.GenerateSecret(),
+		Enabled:   enabled,
+		CreatedAt: time.Now(),
+	}
+}
+
+func currentTotpCode(t *testing.T, secret string) string {
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+// wrongTotpCode returns a code that differs from the code of every accepted step with a high probability
+func wrongTotpCode(code string) string {
+	if code == "000000" {
+		return "999999"
+	}
+	return "000000"
+}
+
+type eqCreateAdminLoginChallengeParamsMatcher struct {
+	adminID int64
+}
+
+func (e eqCreateAdminLoginChallengeParamsMatcher) Matches(x any) bool {
+	arg, ok := x.(db.CreateAdminLoginChallengeParams)
+	if !ok {
+		return false
+	}
+	return arg.AdminID == e.adminID && len(arg.TokenHash) == 64 && arg.ExpiredAt.After(time.Now())
+}
+
+func (e eqCreateAdminLoginChallengeParamsMatcher) String() string {
+	return fmt.Sprintf("creates a login challenge for admin %d", e.adminID)
+}
+
+func EqCreateAdminLoginChallengeParams(adminID int64) gomock.Matcher {
+	return eqCreateAdminLoginChallengeParamsMatcher{adminID}
+}
+
+type eqEnableAdminTotpTxParamsMatcher struct {
+	adminID int64
+}
+
+func (e eqEnableAdminTotpTxParamsMatcher) Matches(x any) bool {
+	arg, ok := x.(db.EnableAdminTotpTxParams)
+	if !ok {
+		return false
+	}
+	return arg.AdminID == e.adminID && arg.LastUsedStep > 0 && len(arg.RecoveryCodeHashes) == adminRecoveryCodesCount
+}
+
+func (e eqEnableAdminTotpTxParamsMatcher) String() string {
+	return fmt.Sprintf("enables the totp of admin %d with fresh recovery codes", e.adminID)
+}
+
+func EqEnableAdminTotpTxParams(adminID int64) gomock.Matcher {
+	return eqEnableAdminTotpTxParamsMatcher{adminID}
+}
@@ -35,7 +35,7 @@ func (server *Server) createCategoryPromotion(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -183,7 +183,7 @@ func (server *Server) listCategoryPromotionsForAdmins(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -232,7 +232,7 @@ func (server *Server) updateCategoryPromotion(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -279,7 +279,7 @@ func (server *Server) deleteCategoryPromotion(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -0,0 +1,416 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/quagmt/udecimal"
+)
+
+// couponDateRangeCheck is the constraint keeping the end date of a coupon after its start date
+const couponDateRangeCheck = "coupon_date_range_check"
+
+// couponPercentageCheck is the constraint keeping a percentage coupon at 100 or below
+const couponPercentageCheck = "coupon_percentage_check"
+
+var errCouponDateRange = errors.New("end_date has to be after start_date")
+
+var errCouponPercentage = errors.New("percentage discount can't be more than 100")
+
+var errCouponRedeemed = errors.New("coupon has been redeemed, deactivate it instead")
+
+// validateCouponDiscount makes sure a percentage coupon doesn't go above 100
+func validateCouponDiscount(discountType, discountValue string) error {
+	if discountType != db.CouponDiscountPercentage {
+		return nil
+	}
+
+	value, err := udecimal.Parse(discountValue)
+	if err != nil {
+		return err
+	}
+
+	if value.GreaterThan(udecimal.MustFromInt64(100, 0)) {
+		return errCouponPercentage
+	}
+	return nil
+}
+
+//////////////* Create API //////////////
+
+type createCouponParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+type createCouponJsonRequest struct {
+	Code           string `json:"code" validate:"required,alphanum,max=32"`
+	Description    string `json:"description" validate:"omitempty"`
+	DiscountType   string `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue  string `json:"discount_value" validate:"required,numeric"`
+	MinOrderValue  string `json:"min_order_value" validate:"omitempty,numeric"`
+	MaxUses        *int64 `json:"max_uses" validate:"omitempty,min=1"`
+	MaxUsesPerUser *int64 `json:"max_uses_per_user" validate:"omitempty,min=1"`
+	CategoryID     *int64 `json:"category_id" validate:"omitempty,min=1"`
+	BrandID        *int64 `json:"brand_id" validate:"omitempty,min=1"`
+	ProductID      *int64 `json:"product_id" validate:"omitempty,min=1"`
+	StartDate      string `json:"start_date" validate:"required"`
+	EndDate        string `json:"end_date" validate:"required"`
+	Active         bool   `json:"active" validate:"boolean"`
+}
+
+func (server *Server) createCoupon(ctx fiber.Ctx) error {
+	params := &createCouponParamsRequest{}
+	req := &createCouponJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	if err := validateCouponDiscount(req.DiscountType, req.DiscountValue); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	startDate, err := time.Parse(timeLayout, req.StartDate)
+	if err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+	endDate, err := time.Parse(timeLayout, req.EndDate)
+	if err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+	if !endDate.After(startDate) {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errCouponDateRange))
+		return nil
+	}
+
+	minOrderValue := req.MinOrderValue
+	if minOrderValue == "" {
+		minOrderValue = "0"
+	}
+
+	arg := db.AdminCreateCouponParams{
+		AdminID:        authPayload.AdminID,
+		Code:           db.NormalizeCouponCode(req.Code),
+		Description:    req.Description,
+		DiscountType:   req.DiscountType,
+		DiscountValue:  req.DiscountValue,
+		MinOrderValue:  minOrderValue,
+		MaxUses:        null.IntFromPtr(req.MaxUses),
+		MaxUsesPerUser: null.IntFromPtr(req.MaxUsesPerUser),
+		CategoryID:     null.IntFromPtr(req.CategoryID),
+		BrandID:        null.IntFromPtr(req.BrandID),
+		ProductID:      null.IntFromPtr(req.ProductID),
+		StartDate:      startDate,
+		EndDate:        endDate,
+		Active:         req.Active,
+	}
+
+	coupon, err := server.store.AdminCreateCoupon(ctx.Context(), arg)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Code {
+			case util.ForeignKeyViolationCode, util.UniqueViolationCode:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(coupon)
+	return nil
+}
+
+//////////////* Get API //////////////
+
+type getCouponParamsRequest struct {
+	AdminID  int64 `uri:"adminId" validate:"required,min=1"`
+	CouponID int64 `uri:"couponId" validate:"required,min=1"`
+}
+
+func (server *Server) getCoupon(ctx fiber.Ctx) error {
+	params := &getCouponParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.AdminGetCouponParams{
+		AdminID: authPayload.AdminID,
+		ID:      params.CouponID,
+	}
+
+	coupon, err := server.store.AdminGetCoupon(ctx.Context(), arg)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(coupon)
+	return nil
+}
+
+//////////////* List API //////////////
+
+type listCouponsParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type listCouponsQueryRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=10"`
+}
+
+func (server *Server) listCoupons(ctx fiber.Ctx) error {
+	params := &listCouponsParamsRequest{}
+	query := &listCouponsQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.AdminListCouponsParams{
+		AdminID: authPayload.AdminID,
+		Limit:   query.PageSize,
+		Offset:  (query.PageID - 1) * query.PageSize,
+	}
+
+	coupons, err := server.store.AdminListCoupons(ctx.Context(), arg)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(coupons)
+	return nil
+}
+
+//////////////* Update API //////////////
+
+type updateCouponParamsRequest struct {
+	AdminID  int64 `uri:"adminId" validate:"required,min=1"`
+	CouponID int64 `uri:"couponId" validate:"required,min=1"`
+}
+
+type updateCouponJsonRequest struct {
+	Code           *string `json:"code" validate:"omitempty,alphanum,max=32"`
+	Description    *string `json:"description" validate:"omitempty"`
+	DiscountType   *string `json:"discount_type" validate:"omitempty,oneof=percentage fixed"`
+	DiscountValue  *string `json:"discount_value" validate:"omitempty,numeric"`
+	MinOrderValue  *string `json:"min_order_value" validate:"omitempty,numeric"`
+	MaxUses        *int64  `json:"max_uses" validate:"omitempty,min=1"`
+	MaxUsesPerUser *int64  `json:"max_uses_per_user" validate:"omitempty,min=1"`
+	CategoryID     *int64  `json:"category_id" validate:"omitempty,min=1"`
+	BrandID        *int64  `json:"brand_id" validate:"omitempty,min=1"`
+	ProductID      *int64  `json:"product_id" validate:"omitempty,min=1"`
+	StartDate      *string `json:"start_date" validate:"omitempty"`
+	EndDate        *string `json:"end_date" validate:"omitempty"`
+	Active         *bool   `json:"active" validate:"omitempty,boolean"`
+}
+
+func (server *Server) updateCoupon(ctx fiber.Ctx) error {
+	params := &updateCouponParamsRequest{}
+	req := &updateCouponJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	// the cap depends on both fields, the one that isn't sent is taken from the stored coupon
+	if req.DiscountType != nil || req.DiscountValue != nil {
+		storedCoupon, err := server.store.AdminGetCoupon(ctx.Context(), db.AdminGetCouponParams{
+			AdminID: authPayload.AdminID,
+			ID:      params.CouponID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+				return nil
+			}
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+
+		discountType, discountValue := storedCoupon.DiscountType, storedCoupon.DiscountValue
+		if req.DiscountType != nil {
+			discountType = *req.DiscountType
+		}
+		if req.DiscountValue != nil {
+			discountValue = *req.DiscountValue
+		}
+
+		if err := validateCouponDiscount(discountType, discountValue); err != nil {
+			ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+			return nil
+		}
+	}
+
+	var err error
+	var startDate, endDate *time.Time
+	if req.StartDate != nil {
+		startDate, err = parseTimeOrNil(timeLayout, *req.StartDate)
+		if err != nil {
+			ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+			return nil
+		}
+	}
+	if req.EndDate != nil {
+		endDate, err = parseTimeOrNil(timeLayout, *req.EndDate)
+		if err != nil {
+			ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+			return nil
+		}
+	}
+	// when only one of the dates is sent the constraint checks it against the stored one
+	if startDate != nil && endDate != nil && !endDate.After(*startDate) {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errCouponDateRange))
+		return nil
+	}
+
+	var code null.String
+	if req.Code != nil {
+		code = null.StringFrom(db.NormalizeCouponCode(*req.Code))
+	}
+
+	arg := db.AdminUpdateCouponParams{
+		AdminID:        authPayload.AdminID,
+		ID:             params.CouponID,
+		Code:           code,
+		Description:    null.StringFromPtr(req.Description),
+		DiscountType:   null.StringFromPtr(req.DiscountType),
+		DiscountValue:  null.StringFromPtr(req.DiscountValue),
+		MinOrderValue:  null.StringFromPtr(req.MinOrderValue),
+		MaxUses:        null.IntFromPtr(req.MaxUses),
+		MaxUsesPerUser: null.IntFromPtr(req.MaxUsesPerUser),
+		CategoryID:     null.IntFromPtr(req.CategoryID),
+		BrandID:        null.IntFromPtr(req.BrandID),
+		ProductID:      null.IntFromPtr(req.ProductID),
+		StartDate:      null.TimeFromPtr(startDate),
+		EndDate:        null.TimeFromPtr(endDate),
+		Active:         null.BoolFromPtr(req.Active),
+	}
+
+	coupon, err := server.store.AdminUpdateCoupon(ctx.Context(), arg)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.ConstraintName {
+			case couponDateRangeCheck:
+				ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errCouponDateRange))
+				return nil
+			case couponPercentageCheck:
+				ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errCouponPercentage))
+				return nil
+			}
+			switch pqErr.Code {
+			case util.ForeignKeyViolationCode, util.UniqueViolationCode:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		} else if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(coupon)
+	return nil
+}
+
+//////////////* Delete API //////////////
+
+type deleteCouponParamsRequest struct {
+	AdminID  int64 `uri:"adminId" validate:"required,min=1"`
+	CouponID int64 `uri:"couponId" validate:"required,min=1"`
+}
+
+func (server *Server) deleteCoupon(ctx fiber.Ctx) error {
+	params := &deleteCouponParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.AdminDeleteCouponParams{
+		AdminID: authPayload.AdminID,
+		ID:      params.CouponID,
+	}
+
+	_, err := server.store.AdminDeleteCoupon(ctx.Context(), arg)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			// the redemptions of the orders keep the coupon, it can only be deactivated
+			if pqErr.Code == util.ForeignKeyViolationCode {
+				ctx.Status(fiber.StatusConflict).JSON(errorResponse(errCouponRedeemed))
+				return nil
+			}
+		} else if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
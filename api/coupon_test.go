@@ -0,0 +1,943 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	mockik "github.com/cshop/v3/image/mock"
+	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	mockwk "github.com/cshop/v3/worker/mock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateCouponAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	coupon := randomCoupon()
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		AdminID       int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":              coupon.Code,
+				"description":       coupon.Description,
+				"discount_type":     coupon.DiscountType,
+				"discount_value":    coupon.DiscountValue,
+				"min_order_value":   coupon.MinOrderValue,
+				"max_uses":          coupon.MaxUses.Int64,
+				"max_uses_per_user": coupon.MaxUsesPerUser.Int64,
+				"category_id":       coupon.CategoryID.Int64,
+				"active":            coupon.Active,
+				"start_date":        coupon.StartDate.Format(timeLayout),
+				"end_date":          coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminCreateCouponParams{
+					AdminID:        admin.ID,
+					Code:           coupon.Code,
+					Description:    coupon.Description,
+					DiscountType:   coupon.DiscountType,
+					DiscountValue:  coupon.DiscountValue,
+					MinOrderValue:  coupon.MinOrderValue,
+					MaxUses:        coupon.MaxUses,
+					MaxUsesPerUser: coupon.MaxUsesPerUser,
+					CategoryID:     coupon.CategoryID,
+					StartDate:      coupon.StartDate,
+					EndDate:        coupon.EndDate,
+					Active:         coupon.Active,
+				}
+
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupon, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCoupon(t, rsp.Body, coupon)
+			},
+		},
+		{
+			name:    "NoAuthorization",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  coupon.DiscountType,
+				"discount_value": coupon.DiscountValue,
+				"start_date":     coupon.StartDate.Format(timeLayout),
+				"end_date":       coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Unauthorized",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  coupon.DiscountType,
+				"discount_value": coupon.DiscountValue,
+				"start_date":     coupon.StartDate.Format(timeLayout),
+				"end_date":       coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, false, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "PercentageAbove100",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  db.CouponDiscountPercentage,
+				"discount_value": "101",
+				"start_date":     coupon.StartDate.Format(timeLayout),
+				"end_date":       coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "EndBeforeStart",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  db.CouponDiscountPercentage,
+				"discount_value": coupon.DiscountValue,
+				"start_date":     coupon.EndDate.Format(timeLayout),
+				"end_date":       coupon.StartDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidDiscountType",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  "free",
+				"discount_value": coupon.DiscountValue,
+				"start_date":     coupon.StartDate.Format(timeLayout),
+				"end_date":       coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "DuplicateCode",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  coupon.DiscountType,
+				"discount_value": coupon.DiscountValue,
+				"start_date":     coupon.StartDate.Format(timeLayout),
+				"end_date":       coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Code: util.UniqueViolationCode, Message: "duplicate key value violates unique constraint"})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InternalError",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"code":           coupon.Code,
+				"discount_type":  coupon.DiscountType,
+				"discount_value": coupon.DiscountValue,
+				"start_date":     coupon.StartDate.Format(timeLayout),
+				"end_date":       coupon.EndDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreateCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/coupons", tc.AdminID)
+			request, err := http.NewRequest(fiber.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestGetCouponAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	coupon := randomCoupon()
+
+	testCases := []struct {
+		name          string
+		AdminID       int64
+		CouponID      int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:     "OK",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminGetCouponParams{
+					AdminID: admin.ID,
+					ID:      coupon.ID,
+				}
+
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupon, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCoupon(t, rsp.Body, coupon)
+			},
+		},
+		{
+			name:     "NotFound",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "Forbidden",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionPromotionsManage})).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InvalidID",
+			AdminID:  admin.ID,
+			CouponID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/coupons/%d", tc.AdminID, tc.CouponID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListCouponsAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	n := 5
+	coupons := make([]*db.Coupon, n)
+	for i := 0; i < n; i++ {
+		coupons[i] = randomCoupon()
+	}
+
+	type Query struct {
+		pageID   int
+		pageSize int
+	}
+
+	testCases := []struct {
+		name          string
+		AdminID       int64
+		query         Query
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminListCouponsParams{
+					AdminID: admin.ID,
+					Limit:   int32(n),
+					Offset:  0,
+				}
+
+				store.EXPECT().
+					AdminListCoupons(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupons, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCoupons(t, rsp.Body, coupons)
+			},
+		},
+		{
+			name:    "InternalError",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminListCoupons(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidPageSize",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: 100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminListCoupons(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/coupons", tc.AdminID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("page_id", strconv.Itoa(tc.query.pageID))
+			q.Add("page_size", strconv.Itoa(tc.query.pageSize))
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestUpdateCouponAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	coupon := randomCoupon()
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		AdminID       int64
+		CouponID      int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:     "OK",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"code":   "summer10",
+				"active": false,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminUpdateCouponParams{
+					AdminID: admin.ID,
+					ID:      coupon.ID,
+					Code:    null.StringFrom("SUMMER10"),
+					Active:  null.BoolFrom(false),
+				}
+
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupon, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCoupon(t, rsp.Body, coupon)
+			},
+		},
+		{
+			name:     "NotFound",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"active": false,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InvalidStartDate",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"start_date": "tomorrow",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "EndBeforeStart",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"start_date": coupon.EndDate.Format(timeLayout),
+				"end_date":   coupon.StartDate.Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "EndBeforeStoredStart",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"end_date": coupon.StartDate.Add(-time.Hour).Format(timeLayout),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Code: "23514", ConstraintName: couponDateRangeCheck})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "PercentageValueAboveStoredCap",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"discount_value": "500",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminGetCouponParams{
+					AdminID: admin.ID,
+					ID:      coupon.ID,
+				}
+
+				// the stored coupon is a percentage one
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupon, nil)
+
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "PercentageTypeOverStoredValue",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"discount_type": db.CouponDiscountPercentage,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				fixedCoupon := *coupon
+				fixedCoupon.DiscountType = db.CouponDiscountFixed
+				fixedCoupon.DiscountValue = "250"
+
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&fixedCoupon, nil)
+
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "OKDiscountValue",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"discount_value": "50",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminGetCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(coupon, nil)
+
+				arg := db.AdminUpdateCouponParams{
+					AdminID:       admin.ID,
+					ID:            coupon.ID,
+					DiscountValue: null.StringFrom("50"),
+				}
+
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupon, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "DuplicateCode",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"code": coupon.Code,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Code: util.UniqueViolationCode, Message: "duplicate key value violates unique constraint"})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			body: fiber.Map{
+				"active": false,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdateCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/coupons/%d", tc.AdminID, tc.CouponID)
+			request, err := http.NewRequest(fiber.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestDeleteCouponAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	coupon := randomCoupon()
+
+	testCases := []struct {
+		name          string
+		AdminID       int64
+		CouponID      int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:     "OK",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminDeleteCouponParams{
+					AdminID: admin.ID,
+					ID:      coupon.ID,
+				}
+
+				store.EXPECT().
+					AdminDeleteCoupon(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(coupon, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "AlreadyRedeemed",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminDeleteCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Code: util.ForeignKeyViolationCode, Message: "update or delete on table \"coupon\" violates foreign key constraint"})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "NotFound",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminDeleteCoupon(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "Unauthorized",
+			AdminID:  admin.ID,
+			CouponID: coupon.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID+1, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminDeleteCoupon(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/coupons/%d", tc.AdminID, tc.CouponID)
+			request, err := http.NewRequest(fiber.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func randomCoupon() *db.Coupon {
+	startTime, _ := time.Parse(timeLayout, time.Now().Truncate(0).Local().UTC().Format(timeLayout))
+	endTime, _ := time.Parse(timeLayout, time.Now().Add(time.Hour).Truncate(0).Local().UTC().Format(timeLayout))
+	return &db.Coupon{
+		ID:             util.RandomInt(1, 1000),
+		Code:           db.NormalizeCouponCode(util.RandomString(8)),
+		Description:    util.RandomUser(),
+		DiscountType:   db.CouponDiscountPercentage,
+		DiscountValue:  strconv.FormatInt(util.RandomInt(1, 50), 10),
+		MinOrderValue:  "0",
+		MaxUses:        null.IntFrom(util.RandomInt(10, 100)),
+		MaxUsesPerUser: null.IntFrom(1),
+		CategoryID:     null.IntFrom(util.RandomInt(1, 1000)),
+		StartDate:      startTime,
+		EndDate:        endTime,
+		Active:         true,
+	}
+}
+
+func requireBodyMatchCoupon(t *testing.T, body io.ReadCloser, coupon *db.Coupon) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotCoupon *db.Coupon
+	err = json.Unmarshal(data, &gotCoupon)
+	require.NoError(t, err)
+	require.Equal(t, coupon, gotCoupon)
+}
+
+func requireBodyMatchCoupons(t *testing.T, body io.ReadCloser, coupons []*db.Coupon) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotCoupons []*db.Coupon
+	err = json.Unmarshal(data, &gotCoupons)
+	require.NoError(t, err)
+	require.Equal(t, coupons, gotCoupons)
+}
@@ -6,7 +6,6 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/token"
 	"github.com/gofiber/fiber/v3"
-	"github.com/guregu/null/v6"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -34,7 +33,7 @@ func (server *Server) getDashboardInfo(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -81,11 +80,11 @@ func (server *Server) getDashboardInfo(ctx fiber.Ctx) error {
 	}
 
 	// active orders query
-	arg := db.GetShopOrdersCountByStatusIdParams{
-		OrderStatusID: null.IntFrom(2),
-		AdminID:       params.AdminID,
+	arg := db.GetShopOrdersCountByStatusCodesParams{
+		Codes:   db.ActiveOrderStatuses,
+		AdminID: params.AdminID,
 	}
-	activeOrders, err := server.store.GetShopOrdersCountByStatusId(ctx.Context(), arg)
+	activeOrders, err := server.store.GetShopOrdersCountByStatusCodes(ctx.Context(), arg)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
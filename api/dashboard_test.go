@@ -16,7 +16,6 @@ import (
 	"github.com/cshop/v3/util"
 	mockwk "github.com/cshop/v3/worker/mock"
 	"github.com/gofiber/fiber/v3"
-	"github.com/guregu/null/v6"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -57,12 +56,12 @@ func TestGetDashboardInfoAPI(t *testing.T) {
 					GetTotalUsersCount(gomock.Any(), gomock.Eq(admin.ID)).
 					Times(1).
 					Return(int64(dashboardInfo.TotalUsers), nil)
-				arg := db.GetShopOrdersCountByStatusIdParams{
-					OrderStatusID: null.IntFrom(2),
-					AdminID:       admin.ID,
+				arg := db.GetShopOrdersCountByStatusCodesParams{
+					Codes:   db.ActiveOrderStatuses,
+					AdminID: admin.ID,
 				}
 				store.EXPECT().
-					GetShopOrdersCountByStatusId(gomock.Any(), gomock.Eq(arg)).
+					GetShopOrdersCountByStatusCodes(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(int64(dashboardInfo.ActiveOrders), nil)
 				store.EXPECT().
@@ -32,7 +32,7 @@ func (server *Server) createHomePageTextBanner(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -155,7 +155,7 @@ func (server *Server) updateHomePageTextBanner(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -200,7 +200,7 @@ func (server *Server) deleteHomePageTextBanner(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
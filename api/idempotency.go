@@ -0,0 +1,161 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyKeyTTL         = 24 * time.Hour
+)
+
+var (
+	errIdempotencyKeyTooLong    = errors.New("idempotency key is too long")
+	errIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	errIdempotencyKeyInProgress = errors.New("a request with the same idempotency key is still in progress")
+)
+
+// idempotencyMiddleware makes a user route safe to retry, the first response
+// for an Idempotency-Key is stored and replayed for every retry with the same body
+func idempotencyMiddleware(store db.Store) fiber.Handler {
+	return func(ctx fiber.Ctx) error {
+		key := ctx.Get(idempotencyKeyHeader)
+		if len(key) == 0 {
+			return ctx.Next()
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errIdempotencyKeyTooLong))
+			return nil
+		}
+
+		authPayload, ok := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+		if !ok {
+			return ctx.Next()
+		}
+
+		requestHash := idempotencyRequestHash(ctx)
+
+		arg := db.CreateIdempotencyKeyParams{
+			UserID:        authPayload.UserID,
+			Key:           key,
+			RequestMethod: ctx.Method(),
+			RequestPath:   ctx.Path(),
+			RequestHash:   requestHash,
+			ExpiresAt:     time.Now().Add(idempotencyKeyTTL),
+		}
+
+		_, err := store.CreateIdempotencyKey(ctx, arg)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+				return nil
+			}
+
+			//? the key is already taken, replay the stored response if it matches
+			done, err := replayIdempotentResponse(ctx, store, arg)
+			if done || err != nil {
+				return err
+			}
+		}
+
+		deleteArg := db.DeleteIdempotencyKeyParams{
+			UserID: arg.UserID,
+			Key:    arg.Key,
+		}
+
+		if err := ctx.Next(); err != nil {
+			//? the handler didn't write a response to store, let the client retry with the same key
+			if deleteErr := store.DeleteIdempotencyKey(ctx, deleteArg); deleteErr != nil {
+				return errors.Join(err, deleteErr)
+			}
+			return err
+		}
+
+		status := ctx.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			//? let the client retry the request with the same key
+			return store.DeleteIdempotencyKey(ctx, deleteArg)
+		}
+
+		_, err = store.UpdateIdempotencyKeyResponse(ctx, db.UpdateIdempotencyKeyResponseParams{
+			UserID:         arg.UserID,
+			Key:            arg.Key,
+			ResponseStatus: null.IntFrom(int64(status)),
+			ResponseBody:   null.StringFrom(string(ctx.Response().Body())),
+		})
+		return err
+	}
+}
+
+// replayIdempotentResponse writes the stored response for an existing key,
+// it returns false when the stored key has expired and the request should run again
+func replayIdempotentResponse(ctx fiber.Ctx, store db.Store, arg db.CreateIdempotencyKeyParams) (bool, error) {
+	idempotencyKey, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		UserID: arg.UserID,
+		Key:    arg.Key,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return true, nil
+	}
+
+	if time.Now().After(idempotencyKey.ExpiresAt) {
+		err = store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{
+			UserID: arg.UserID,
+			Key:    arg.Key,
+		})
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return true, nil
+		}
+
+		_, err = store.CreateIdempotencyKey(ctx, arg)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				ctx.Status(fiber.StatusConflict).JSON(errorResponse(errIdempotencyKeyInProgress))
+				return true, nil
+			}
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return true, nil
+		}
+		return false, nil
+	}
+
+	if idempotencyKey.RequestHash != arg.RequestHash {
+		ctx.Status(fiber.StatusConflict).JSON(errorResponse(errIdempotencyKeyReused))
+		return true, nil
+	}
+
+	if !idempotencyKey.ResponseStatus.Valid {
+		ctx.Status(fiber.StatusConflict).JSON(errorResponse(errIdempotencyKeyInProgress))
+		return true, nil
+	}
+
+	ctx.Set(idempotencyReplayedHeader, "true")
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	ctx.Status(int(idempotencyKey.ResponseStatus.Int64))
+	return true, ctx.SendString(idempotencyKey.ResponseBody.String)
+}
+
+// idempotencyRequestHash fingerprints the request so a reused key with another body is rejected
+func idempotencyRequestHash(ctx fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Path()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	userID := util.RandomMoney()
+	key := util.RandomString(16)
+	body := []byte(`{"qty":1}`)
+	handlerBody := `{"id":1}`
+
+	// storedKey returns the row the first request left behind,
+	// hash is taken from the CreateIdempotencyKey call of the retry
+	storedKey := func(hash string, status null.Int, expiresAt time.Time) *db.IdempotencyKey {
+		return &db.IdempotencyKey{
+			ID:             1,
+			UserID:         userID,
+			Key:            key,
+			RequestMethod:  fiber.MethodPost,
+			RequestHash:    hash,
+			ResponseStatus: status,
+			ResponseBody:   null.StringFrom(handlerBody),
+			ExpiresAt:      expiresAt,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		handlerStatus int
+		handlerErr    error
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *http.Response, handlerCalls int)
+	}{
+		{
+			name:          "NoKey",
+			key:           "",
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				require.Equal(t, 1, handlerCalls)
+			},
+		},
+		{
+			name:          "FirstRequest",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateIdempotencyKeyParams) (*db.IdempotencyKey, error) {
+						require.Equal(t, userID, arg.UserID)
+						require.Equal(t, key, arg.Key)
+						require.NotEmpty(t, arg.RequestHash)
+						return storedKey(arg.RequestHash, null.Int{}, arg.ExpiresAt), nil
+					})
+
+				arg := db.UpdateIdempotencyKeyResponseParams{
+					UserID:         userID,
+					Key:            key,
+					ResponseStatus: null.IntFrom(fiber.StatusOK),
+					ResponseBody:   null.StringFrom(handlerBody),
+				}
+
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.IdempotencyKey{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				require.Equal(t, 1, handlerCalls)
+				require.Empty(t, rsp.Header.Get(idempotencyReplayedHeader))
+			},
+		},
+		{
+			name:          "Replay",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				var hash string
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateIdempotencyKeyParams) (*db.IdempotencyKey, error) {
+						hash = arg.RequestHash
+						return nil, pgx.ErrNoRows
+					})
+
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{UserID: userID, Key: key})).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ db.GetIdempotencyKeyParams) (*db.IdempotencyKey, error) {
+						return storedKey(hash, null.IntFrom(fiber.StatusCreated), time.Now().Add(time.Hour)), nil
+					})
+
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusCreated, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+				require.Equal(t, "true", rsp.Header.Get(idempotencyReplayedHeader))
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+				require.Equal(t, handlerBody, string(data))
+			},
+		},
+		{
+			name:          "DifferentBody",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(storedKey("other-hash", null.IntFrom(fiber.StatusOK), time.Now().Add(time.Hour)), nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+			},
+		},
+		{
+			name:          "InProgress",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				var hash string
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateIdempotencyKeyParams) (*db.IdempotencyKey, error) {
+						hash = arg.RequestHash
+						return nil, pgx.ErrNoRows
+					})
+
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ db.GetIdempotencyKeyParams) (*db.IdempotencyKey, error) {
+						return storedKey(hash, null.Int{}, time.Now().Add(time.Hour)), nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+			},
+		},
+		{
+			name:          "ExpiredKey",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(nil, pgx.ErrNoRows),
+					store.EXPECT().
+						GetIdempotencyKey(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(storedKey("other-hash", null.IntFrom(fiber.StatusOK), time.Now().Add(-time.Minute)), nil),
+					store.EXPECT().
+						DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserID: userID, Key: key})).
+						Times(1).
+						Return(nil),
+					store.EXPECT().
+						CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(&db.IdempotencyKey{}, nil),
+					store.EXPECT().
+						UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(&db.IdempotencyKey{}, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				require.Equal(t, 1, handlerCalls)
+			},
+		},
+		{
+			name:          "HandlerInternalError",
+			key:           key,
+			handlerStatus: fiber.StatusInternalServerError,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.IdempotencyKey{}, nil)
+
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserID: userID, Key: key})).
+					Times(1).
+					Return(nil)
+
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+				require.Equal(t, 1, handlerCalls)
+			},
+		},
+		{
+			name:          "HandlerReturnsError",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			handlerErr:    fiber.ErrServiceUnavailable,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.IdempotencyKey{}, nil)
+
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{UserID: userID, Key: key})).
+					Times(1).
+					Return(nil)
+
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				// the key is deleted, authMiddleware drops the handler error itself
+				require.Equal(t, 1, handlerCalls)
+			},
+		},
+		{
+			name:          "InternalError",
+			key:           key,
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+			},
+		},
+		{
+			name:          "KeyTooLong",
+			key:           util.RandomString(idempotencyKeyMaxLength + 1),
+			handlerStatus: fiber.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			handlerCalls := 0
+			idempotentPath := "/idempotent"
+			server.router.Use(authMiddleware(server.store, server.userTokenMaker, false))
+			server.router.Post(
+				idempotentPath,
+				idempotencyMiddleware(store),
+				func(ctx fiber.Ctx) error {
+					handlerCalls++
+					if tc.handlerErr != nil {
+						return tc.handlerErr
+					}
+					ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+					return ctx.Status(tc.handlerStatus).SendString(handlerBody)
+				},
+			)
+
+			request, err := http.NewRequest(fiber.MethodPost, idempotentPath, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.userTokenMaker, authorizationTypeBearer, userID, "user", time.Minute)
+			request.Header.Set("Content-Type", "application/json")
+			if len(tc.key) != 0 {
+				request.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, rsp, handlerCalls)
+		})
+	}
+}
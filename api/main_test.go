@@ -8,12 +8,15 @@ import (
 	"time"
 
 	firebase "firebase.google.com/go/v4"
+	mockdb "github.com/cshop/v3/db/mock"
 	db "github.com/cshop/v3/db/sqlc"
 	image "github.com/cshop/v3/image"
 	"github.com/cshop/v3/mail"
+	"github.com/cshop/v3/payment"
 	"github.com/cshop/v3/util"
 	"github.com/cshop/v3/worker"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 	"google.golang.org/api/option"
 )
 
@@ -25,9 +28,13 @@ func newTestServer(
 	sender mail.EmailSender,
 ) *Server {
 	config := util.Config{
-		UserTokenSymmetricKey:  util.RandomString(32),
-		AdminTokenSymmetricKey: util.RandomString(32),
-		AccessTokenDuration:    time.Minute,
+		UserTokenSymmetricKey:    util.RandomString(32),
+		AdminTokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration:      time.Minute,
+		FakePaymentWebhookSecret: util.RandomString(32),
+		DeletionGracePeriod:      util.DefaultDeletionGracePeriod,
+		// the requests of the tests come from 0.0.0.0 like they come from the reverse proxy
+		TrustedProxies: []string{"0.0.0.0"},
 	}
 
 	opt := option.WithCredentialsFile("serviceAccountKey_test.json")
@@ -38,7 +45,26 @@ func newTestServer(
 		log.Fatal("error initializing firebase:", err)
 	}
 
-	server, err := NewServer(config, store, fb, taskDistributor, ik, sender)
+	//? the admins of the tests hold every permission and the sessions of the tests are active
+	//? unless a test case expects the check first
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			AdminHasPermission(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(true, nil)
+
+		mockStore.EXPECT().
+			IsUserSessionActive(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(true, nil)
+
+		mockStore.EXPECT().
+			IsAdminSessionActive(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(true, nil)
+	}
+
+	server, err := NewServer(config, store, fb, taskDistributor, ik, sender, payment.NewDefaultProviders(config.FakePaymentWebhookSecret))
 	require.NoError(t, err)
 
 	return server
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	"github.com/gofiber/fiber/v3"
@@ -18,7 +19,10 @@ const (
 	accessTokenHasExpired        = "access token has expired"
 )
 
-func authMiddleware(tokenMaker token.Maker, admin bool) fiber.Handler {
+var errSessionRevoked = errors.New("session has been revoked, please login again")
+
+// authMiddleware verifies the access token and rejects it once the session it was issued for is revoked or expired
+func authMiddleware(store db.Store, tokenMaker token.Maker, admin bool) fiber.Handler {
 	return func(ctx fiber.Ctx) error {
 		authorizationHeader := ctx.Get(authorizationHeaderKey)
 
@@ -56,8 +60,22 @@ func authMiddleware(tokenMaker token.Maker, admin bool) fiber.Handler {
 				return nil
 			}
 
+			active, err := store.IsAdminSessionActive(ctx.Context(), db.IsAdminSessionActiveParams{
+				ID:      adminPayload.SessionID,
+				AdminID: adminPayload.AdminID,
+			})
+			if err != nil {
+				ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+				return nil
+			}
+
+			if !active {
+				ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errSessionRevoked))
+				return nil
+			}
+
 			ctx.Locals(authorizationAdminPayloadKey, adminPayload)
-			ctx.Next()
+			return ctx.Next()
 		}
 
 		userPayload, err = tokenMaker.VerifyTokenForUser(accessToken)
@@ -69,9 +87,63 @@ func authMiddleware(tokenMaker token.Maker, admin bool) fiber.Handler {
 			return nil
 		}
 
+		active, err := store.IsUserSessionActive(ctx.Context(), db.IsUserSessionActiveParams{
+			ID:     userPayload.SessionID,
+			UserID: userPayload.UserID,
+		})
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+
+		if !active {
+			ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errSessionRevoked))
+			return nil
+		}
+
 		ctx.Locals(authorizationUserPayloadKey, userPayload)
 		ctx.Next()
 
 		return nil
 	}
 }
+
+// permissionMiddleware lets the authenticated admin through when one of their roles grants the permission,
+// it's checked on every request so a role change or a blocked admin applies without waiting for a new token
+func permissionMiddleware(store db.Store, permission string) fiber.Handler {
+	return func(ctx fiber.Ctx) error {
+		authPayload, ok := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+		if !ok {
+			err := errors.New("account unauthorized")
+			ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+			return nil
+		}
+
+		if !checkAdminPermission(ctx, store, authPayload.AdminID, permission) {
+			return nil
+		}
+
+		return ctx.Next()
+	}
+}
+
+// checkAdminPermission writes the error response and returns false when the admin lacks the permission,
+// the handlers call it for the fields that need more than the permission of their route
+func checkAdminPermission(ctx fiber.Ctx, store db.Store, adminID int64, permission string) bool {
+	allowed, err := store.AdminHasPermission(ctx.Context(), db.AdminHasPermissionParams{
+		AdminID:    adminID,
+		Permission: permission,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return false
+	}
+
+	if !allowed {
+		err := fmt.Errorf("account doesn't have the %s permission", permission)
+		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+		return false
+	}
+
+	return true
+}
@@ -6,9 +6,15 @@ import (
 	"testing"
 	"time"
 
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func addAuthorization(
@@ -20,7 +26,7 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateTokenForUser(userID, username, duration)
+	token, payload, err := tokenMaker.CreateTokenForUser(userID, username, uuid.New(), duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -39,7 +45,7 @@ func addAuthorizationForAdmin(
 	active bool,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateTokenForAdmin(adminID, username, typeID, active, duration)
+	token, payload, err := tokenMaker.CreateTokenForAdmin(adminID, username, typeID, active, uuid.New(), duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -51,6 +57,7 @@ func TestAuthMiddlewareForUser(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, rsp *http.Response)
 	}{
 		{
@@ -62,6 +69,36 @@ func TestAuthMiddlewareForUser(t *testing.T) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name: "RevokedSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 1, "user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IsUserSessionActive(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 1, "user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IsUserSessionActive(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
 		{
 			name: "NoAuthorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -103,10 +140,17 @@ func TestAuthMiddlewareForUser(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := newTestServer(t, nil, nil, nil, nil)
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server := newTestServer(t, store, nil, nil, nil)
 
 			authPath := "/auth"
-			server.router.Use(authMiddleware(server.userTokenMaker, false))
+			server.router.Use(authMiddleware(server.store, server.userTokenMaker, false))
 			server.router.Get(
 				authPath,
 				func(ctx fiber.Ctx) error {
@@ -129,3 +173,92 @@ func TestAuthMiddlewareForUser(t *testing.T) {
 		})
 	}
 }
+
+func TestPermissionMiddleware(t *testing.T) {
+	adminID := util.RandomMoney()
+	arg := db.AdminHasPermissionParams{
+		AdminID:    adminID,
+		Permission: db.PermissionOrdersManage,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *http.Response, handlerCalls int)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				require.Equal(t, 1, handlerCalls)
+			},
+		},
+		{
+			name: "Forbidden",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(false, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response, handlerCalls int) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+				require.Equal(t, 0, handlerCalls)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			handlerCalls := 0
+			permissionPath := "/permission"
+			server.router.Use(authMiddleware(server.store, server.adminTokenMaker, true))
+			server.router.Get(
+				permissionPath,
+				permissionMiddleware(store, db.PermissionOrdersManage),
+				func(ctx fiber.Ctx) error {
+					handlerCalls++
+					ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+					return nil
+				},
+			)
+
+			request, err := http.NewRequest(fiber.MethodGet, permissionPath, nil)
+			require.NoError(t, err)
+
+			addAuthorizationForAdmin(t, request, server.adminTokenMaker, authorizationTypeBearer, adminID, "admin", 2, true, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, rsp, handlerCalls)
+		})
+	}
+}
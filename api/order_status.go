@@ -31,7 +31,7 @@ func (server *Server) createOrderStatus(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -171,7 +171,7 @@ func (server *Server) updateOrderStatus(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -215,7 +215,7 @@ func (server *Server) deleteOrderStatus(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
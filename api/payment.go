@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/payment"
+	"github.com/cshop/v3/worker"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/quagmt/udecimal"
+)
+
+// authorizePayment asks the provider of the payment to authorize it and records the answer,
+// when that fails the order is already placed so the authorization is retried by a task
+func (server *Server) authorizePayment(ctx context.Context, paymentTransaction *db.PaymentTransaction) (*db.UpdatePaymentTransactionTxResult, error) {
+	result, err := server.payments.AuthorizeTransaction(ctx, server.store, paymentTransaction)
+	if err == nil {
+		return result, nil
+	}
+
+	taskPayload := &worker.PayloadAuthorizePayment{
+		PaymentTransactionID: paymentTransaction.ID,
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	if taskErr := server.taskDistributor.DistributeTaskAuthorizePayment(ctx, taskPayload, opts...); taskErr != nil {
+		log.Println(taskErr)
+	}
+	return nil, err
+}
+
+// orderPayment returns the latest payment of the order, the orders placed before the payments have none
+func (server *Server) orderPayment(ctx context.Context, shopOrderID int64) (*db.PaymentTransaction, error) {
+	paymentTransactions, err := server.store.ListPaymentTransactionsByShopOrderID(ctx, shopOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(paymentTransactions) == 0 {
+		return nil, nil
+	}
+	return paymentTransactions[len(paymentTransactions)-1], nil
+}
+
+/*
+settleOrderPayment captures or refunds the payment of an order that moved to the order status,
+
+a payment still authorized is captured when the order ships, the cash of a cash on delivery
+payment is only collected when the order is delivered. what's left of a captured payment
+is recorded as a pending refund when the order is cancelled or returned and it's made here.
+*/
+func (server *Server) settleOrderPayment(ctx context.Context, shopOrderID int64, orderStatus string) error {
+	switch orderStatus {
+	case db.OrderStatusShipped, db.OrderStatusDelivered:
+	case db.OrderStatusCancelled, db.OrderStatusReturned:
+		return server.refundOrderPayment(ctx, shopOrderID)
+	default:
+		return nil
+	}
+
+	paymentTransaction, err := server.orderPayment(ctx, shopOrderID)
+	if err != nil || paymentTransaction == nil {
+		return err
+	}
+
+	if paymentTransaction.Status != db.PaymentStatusAuthorized {
+		return nil
+	}
+	if orderStatus == db.OrderStatusShipped && paymentTransaction.Provider == payment.ProviderCOD {
+		return nil
+	}
+	return server.capturePayment(ctx, paymentTransaction)
+}
+
+// refundReturnRequest makes the refund recorded on the payment of the order when the return request was refunded
+func (server *Server) refundReturnRequest(ctx context.Context, returnRequest *db.ReturnRequest) error {
+	if returnRequest.Status != db.ReturnStatusRefunded {
+		return nil
+	}
+	return server.refundOrderPayment(ctx, returnRequest.ShopOrderID)
+}
+
+// refundOrderPayment makes the pending refund of the order's payment, a refund the provider
+// didn't make stays in flight and is retried by a task
+func (server *Server) refundOrderPayment(ctx context.Context, shopOrderID int64) error {
+	paymentTransaction, err := server.orderPayment(ctx, shopOrderID)
+	if err != nil || paymentTransaction == nil {
+		return err
+	}
+
+	pending, err := udecimal.Parse(paymentTransaction.PendingRefundAmount)
+	if err != nil {
+		return err
+	}
+
+	inFlight, err := udecimal.Parse(paymentTransaction.RefundInFlightAmount)
+	if err != nil {
+		return err
+	}
+
+	if !pending.IsPos() && !inFlight.IsPos() {
+		return nil
+	}
+
+	_, err = server.payments.RefundTransaction(ctx, server.store, paymentTransaction.ID)
+	if err == nil {
+		return nil
+	}
+	log.Println(err)
+
+	taskPayload := &worker.PayloadRefundPayment{
+		PaymentTransactionID: paymentTransaction.ID,
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	return server.taskDistributor.DistributeTaskRefundPayment(ctx, taskPayload, opts...)
+}
+
+// capturePayment asks the provider to collect the authorized payment, a capture the provider
+// didn't make is retried by a task
+func (server *Server) capturePayment(ctx context.Context, paymentTransaction *db.PaymentTransaction) error {
+	_, err := server.payments.CaptureTransaction(ctx, server.store, paymentTransaction)
+	if err == nil {
+		return nil
+	}
+	log.Println(err)
+
+	taskPayload := &worker.PayloadCapturePayment{
+		PaymentTransactionID: paymentTransaction.ID,
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	return server.taskDistributor.DistributeTaskCapturePayment(ctx, taskPayload, opts...)
+}
+
+// confirmPayment captures a payment right after the provider authorized it, the order waits for
+// the capture to leave awaiting payment. cash on delivery is only collected on delivery.
+func (server *Server) confirmPayment(ctx context.Context, paymentTransaction *db.PaymentTransaction) error {
+	if paymentTransaction.Status != db.PaymentStatusAuthorized || paymentTransaction.Provider == payment.ProviderCOD {
+		return nil
+	}
+	return server.capturePayment(ctx, paymentTransaction)
+}
+
+//////////////* Webhook API //////////////
+
+type paymentWebhookParamsRequest struct {
+	Provider string `uri:"provider" validate:"required,alphanum,max=32"`
+}
+
+func (server *Server) paymentWebhook(ctx fiber.Ctx) error {
+	params := &paymentWebhookParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	provider, err := server.payments.Get(params.Provider)
+	if err != nil {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+		return nil
+	}
+
+	event, err := provider.VerifyWebhook(ctx.Body(), ctx.Get(payment.SignatureHeader))
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	paymentTransaction, err := server.store.GetPaymentTransactionByProviderReference(ctx.Context(), db.GetPaymentTransactionByProviderReferenceParams{
+		Provider:          provider.Name(),
+		ProviderReference: null.StringFrom(event.ProviderReference),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.UpdatePaymentTransactionTxParams{
+		ID:     paymentTransaction.ID,
+		Status: event.Status,
+		Amount: event.Amount,
+	}
+
+	result, err := server.store.UpdatePaymentTransactionTx(ctx.Context(), arg)
+	if err != nil {
+		var transitionErr *db.InvalidPaymentStatusTransitionError
+		var amountErr *db.PaymentAmountMismatchError
+		if errors.As(err, &transitionErr) || errors.As(err, &amountErr) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	//? the payment is recorded, a capture the provider didn't make is retried by a task
+	if err := server.confirmPayment(ctx.Context(), result.PaymentTransaction); err != nil {
+		log.Println(err)
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(result)
+	return nil
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/payment"
+	"github.com/cshop/v3/util"
+	wk "github.com/cshop/v3/worker"
+	mockwk "github.com/cshop/v3/worker/mock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPaymentWebhookAPI(t *testing.T) {
+	paymentTransaction := randomPaymentTransaction(payment.ProviderFake)
+
+	event := payment.WebhookEvent{
+		ProviderReference: paymentTransaction.ProviderReference.String,
+		Status:            payment.StatusCaptured,
+		Amount:            paymentTransaction.Amount,
+	}
+
+	testCases := []struct {
+		name          string
+		Provider      string
+		event         payment.WebhookEvent
+		signature     func(signature string) string
+		buildStubs    func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:     "OK",
+			Provider: payment.ProviderFake,
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Eq(db.GetPaymentTransactionByProviderReferenceParams{
+						Provider:          payment.ProviderFake,
+						ProviderReference: paymentTransaction.ProviderReference,
+					})).
+					Times(1).
+					Return(paymentTransaction, nil)
+
+				arg := db.UpdatePaymentTransactionTxParams{
+					ID:     paymentTransaction.ID,
+					Status: db.PaymentStatusCaptured,
+					Amount: paymentTransaction.Amount,
+				}
+
+				capturedPayment := *paymentTransaction
+				capturedPayment.Status = db.PaymentStatusCaptured
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.UpdatePaymentTransactionTxResult{PaymentTransaction: &capturedPayment}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotResult db.UpdatePaymentTransactionTxResult
+				err = json.Unmarshal(data, &gotResult)
+				require.NoError(t, err)
+				require.Equal(t, db.PaymentStatusCaptured, gotResult.PaymentTransaction.Status)
+			},
+		},
+		{
+			name:     "AuthorizedCaptureRetried",
+			Provider: payment.ProviderFake,
+			event: payment.WebhookEvent{
+				ProviderReference: paymentTransaction.ProviderReference.String,
+				Status:            payment.StatusAuthorized,
+				Amount:            paymentTransaction.Amount,
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(paymentTransaction, nil)
+
+				authorizedPayment := *paymentTransaction
+				authorizedPayment.Status = db.PaymentStatusAuthorized
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.UpdatePaymentTransactionTxResult{PaymentTransaction: &authorizedPayment}, nil)
+
+				//? the fake provider doesn't know the payment so the capture fails
+				worker.EXPECT().
+					DistributeTaskCapturePayment(gomock.Any(), gomock.Eq(&wk.PayloadCapturePayment{PaymentTransactionID: paymentTransaction.ID}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InvalidSignature",
+			Provider: payment.ProviderFake,
+			event:    event,
+			signature: func(signature string) string {
+				return util.RandomString(len(signature))
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "ProviderNotFound",
+			Provider: "unknown",
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "WebhookNotSupported",
+			Provider: payment.ProviderCOD,
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InvalidProvider",
+			Provider: "fake-provider",
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "NotFound",
+			Provider: payment.ProviderFake,
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InvalidStatusTransition",
+			Provider: payment.ProviderFake,
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(paymentTransaction, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.InvalidPaymentStatusTransitionError{From: db.PaymentStatusRefunded, To: db.PaymentStatusCaptured})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "AmountMismatch",
+			Provider: payment.ProviderFake,
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(paymentTransaction, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.PaymentAmountMismatchError{Expected: paymentTransaction.Amount, Got: "1"})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:     "InternalError",
+			Provider: payment.ProviderFake,
+			event:    event,
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetPaymentTransactionByProviderReference(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			taskDistributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, taskDistributor)
+
+			server := newTestServer(t, store, taskDistributor, nil, nil)
+
+			provider, err := server.payments.Get(payment.ProviderFake)
+			require.NoError(t, err)
+
+			fakeProvider, ok := provider.(*payment.FakeProvider)
+			require.True(t, ok)
+
+			data, signature, err := fakeProvider.SignWebhook(tc.event)
+			require.NoError(t, err)
+
+			if tc.signature != nil {
+				signature = tc.signature(signature)
+			}
+
+			url := fmt.Sprintf("/api/v1/payments/%s/webhook", tc.Provider)
+			request, err := http.NewRequest(fiber.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(payment.SignatureHeader, signature)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func randomPaymentTransaction(provider string) *db.PaymentTransaction {
+	return &db.PaymentTransaction{
+		ID:                   util.RandomMoney(),
+		ShopOrderID:          util.RandomMoney(),
+		Provider:             provider,
+		ProviderReference:    null.StringFrom(fmt.Sprintf("%s_%s", provider, util.RandomString(16))),
+		Status:               db.PaymentStatusPending,
+		Amount:               util.RandomDecimalString(1, 100),
+		RefundedAmount:       "0",
+		PendingRefundAmount:  "0",
+		RefundInFlightAmount: "0",
+	}
+}
+
+// expectRefundPaymentTransaction expects the pending refund of the payment to be put in flight and recorded
+func expectRefundPaymentTransaction(store *mockdb.MockStore, paymentTransaction *db.PaymentTransaction) {
+	inFlight := *paymentTransaction
+	inFlight.RefundInFlightAmount = paymentTransaction.PendingRefundAmount
+	inFlight.PendingRefundAmount = "0"
+	inFlight.RefundSequence++
+
+	gomock.InOrder(
+		store.EXPECT().
+			StartRefundPaymentTransactionTx(gomock.Any(), gomock.Eq(db.StartRefundPaymentTransactionTxParams{ID: paymentTransaction.ID})).
+			Times(1).
+			Return(&db.StartRefundPaymentTransactionTxResult{PaymentTransaction: &inFlight, Amount: inFlight.RefundInFlightAmount}, nil),
+		store.EXPECT().
+			CompleteRefundPaymentTransactionTx(gomock.Any(), gomock.Eq(db.CompleteRefundPaymentTransactionTxParams{
+				ID:             paymentTransaction.ID,
+				RefundSequence: inFlight.RefundSequence,
+			})).
+			Times(1).
+			Return(&db.CompleteRefundPaymentTransactionTxResult{PaymentTransaction: &inFlight, Refunded: inFlight.RefundInFlightAmount}, nil),
+	)
+}
+
+// declinedProviderName is the provider of the tests that declines every authorization
+const declinedProviderName = "declined"
+
+type declinedProvider struct {
+	payment.PaymentProvider
+}
+
+func (provider declinedProvider) Name() string {
+	return declinedProviderName
+}
+
+func (provider declinedProvider) Authorize(ctx context.Context, req payment.AuthorizeRequest) (*payment.Result, error) {
+	return &payment.Result{
+		ProviderReference: fmt.Sprintf("%s_%d", declinedProviderName, req.TransactionID),
+		Status:            payment.StatusFailed,
+		Amount:            req.Amount,
+	}, nil
+}
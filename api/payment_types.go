@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/payment"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	"github.com/gofiber/fiber/v3"
@@ -21,6 +22,7 @@ type createPaymentTypeParamsRequest struct {
 type createPaymentTypeJsonRequest struct {
 	Value    string `json:"value" validate:"required,alphanumunicode_space"`
 	IsActive bool   `json:"is_active" validate:"boolean"`
+	Provider string `json:"provider" validate:"omitempty,alphanum,max=32"`
 }
 
 func (server *Server) createPaymentType(ctx fiber.Ctx) error {
@@ -33,16 +35,26 @@ func (server *Server) createPaymentType(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
+	if req.Provider == "" {
+		req.Provider = payment.ProviderCOD
+	}
+
+	if _, err := server.payments.Get(req.Provider); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
 	arg := db.AdminCreatePaymentTypeParams{
 		AdminID:  authPayload.AdminID,
 		Value:    req.Value,
 		IsActive: req.IsActive,
+		Provider: req.Provider,
 	}
 
 	product, err := server.store.AdminCreatePaymentType(ctx.Context(), arg)
@@ -77,7 +89,7 @@ func (server *Server) adminListPaymentTypes(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -112,6 +124,7 @@ type updatePaymentTypeParamsRequest struct {
 type updatePaymentTypeJsonRequest struct {
 	Value    *string `json:"value" validate:"omitempty,required,alphanumunicode_space"`
 	IsActive *bool   `json:"is_active" validate:"omitempty,required,boolean"`
+	Provider *string `json:"provider" validate:"omitempty,required,alphanum,max=32"`
 }
 
 func (server *Server) updatePaymentType(ctx fiber.Ctx) error {
@@ -124,17 +137,25 @@ func (server *Server) updatePaymentType(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
+	if req.Provider != nil {
+		if _, err := server.payments.Get(*req.Provider); err != nil {
+			ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+			return nil
+		}
+	}
+
 	arg := db.AdminUpdatePaymentTypeParams{
 		AdminID:  authPayload.AdminID,
 		ID:       params.PaymentTypeID,
 		Value:    null.StringFromPtr(req.Value),
 		IsActive: null.BoolFromPtr(req.IsActive),
+		Provider: null.StringFromPtr(req.Provider),
 	}
 
 	product, err := server.store.AdminUpdatePaymentType(ctx.Context(), arg)
@@ -169,7 +190,7 @@ func (server *Server) deletePaymentType(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
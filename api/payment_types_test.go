@@ -13,6 +13,7 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	mockik "github.com/cshop/v3/image/mock"
 	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/payment"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	mockwk "github.com/cshop/v3/worker/mock"
@@ -50,6 +51,7 @@ func TestAdminCreatePaymentTypeAPI(t *testing.T) {
 					AdminID:  admin.ID,
 					Value:    paymentType.Value,
 					IsActive: paymentType.IsActive,
+					Provider: payment.ProviderCOD,
 				}
 
 				store.EXPECT().
@@ -62,6 +64,54 @@ func TestAdminCreatePaymentTypeAPI(t *testing.T) {
 				requireBodyMatchPaymentType(t, rsp.Body, paymentType)
 			},
 		},
+		{
+			name:    "OKWithProvider",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"value":     paymentType.Value,
+				"is_active": paymentType.IsActive,
+				"provider":  payment.ProviderFake,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminCreatePaymentTypeParams{
+					AdminID:  admin.ID,
+					Value:    paymentType.Value,
+					IsActive: paymentType.IsActive,
+					Provider: payment.ProviderFake,
+				}
+
+				store.EXPECT().
+					AdminCreatePaymentType(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(paymentType, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "UnknownProvider",
+			AdminID: admin.ID,
+			body: fiber.Map{
+				"value":     paymentType.Value,
+				"is_active": paymentType.IsActive,
+				"provider":  "unknown",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminCreatePaymentType(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
 		{
 			name:    "NoAuthorization",
 			AdminID: admin.ID,
@@ -72,6 +122,7 @@ func TestAdminCreatePaymentTypeAPI(t *testing.T) {
 					AdminID:  admin.ID,
 					Value:    paymentType.Value,
 					IsActive: paymentType.IsActive,
+					Provider: payment.ProviderCOD,
 				}
 
 				store.EXPECT().
@@ -98,6 +149,7 @@ func TestAdminCreatePaymentTypeAPI(t *testing.T) {
 					AdminID:  admin.ID,
 					Value:    paymentType.Value,
 					IsActive: paymentType.IsActive,
+					Provider: payment.ProviderCOD,
 				}
 
 				store.EXPECT().
@@ -384,6 +436,25 @@ func TestUpdatePaymentTypeAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name:          "UnknownProvider",
+			paymentTypeID: paymentType.ID,
+			AdminID:       admin.ID,
+			body: fiber.Map{
+				"provider": "unknown",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUpdatePaymentType(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
 		{
 			name:          "Unauthorized",
 			paymentTypeID: paymentType.ID,
@@ -703,6 +774,7 @@ func randomPaymentType() *db.PaymentType {
 		ID:       util.RandomInt(1, 1000),
 		Value:    util.RandomUser(),
 		IsActive: util.RandomBool(),
+		Provider: payment.ProviderCOD,
 	}
 }
 
@@ -39,7 +39,7 @@ func (server *Server) createProduct(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -186,7 +186,7 @@ func (server *Server) updateProduct(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -234,7 +234,7 @@ func (server *Server) deleteProduct(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -358,7 +358,7 @@ func (server *Server) listProductsNextPage(ctx fiber.Ctx) error {
 //////////////* Paginated Search API //////////////
 
 type searchProductsQueryRequest struct {
-	Query string `query:"query" validate:"omitempty,required,alphanumunicode_space"`
+	Query string `query:"query" validate:"omitempty,required,search_query,max=100"`
 	Limit int32  `query:"limit" validate:"required,min=5,max=10"`
 }
 
@@ -410,7 +410,7 @@ func (server *Server) searchProducts(ctx fiber.Ctx) error {
 
 type searchProductsNextPageQueryRequest struct {
 	ProductCursor int64  `query:"product_cursor" validate:"required,min=1"`
-	Query         string `query:"query" validate:"omitempty,required,alphanumunicode_space"`
+	Query         string `query:"query" validate:"omitempty,required,search_query,max=100"`
 	Limit         int32  `query:"limit" validate:"required,min=5,max=10"`
 }
 
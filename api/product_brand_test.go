@@ -584,20 +584,24 @@ func TestDeleteProductBrandAPI(t *testing.T) {
 			},
 		},
 		{
-			name:           "Unauthorized",
+			name:           "Forbidden",
 			productBrandID: productBrand.ID,
 			AdminID:        admin.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, 2, admin.Active, time.Minute)
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionCatalogManage})).
+					Times(1).
+					Return(false, nil)
 
 				store.EXPECT().
 					DeleteProductBrand(gomock.Any(), gomock.Eq(productBrand.ID)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
-				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
 			},
 		},
 		{
@@ -765,16 +765,21 @@ func TestDeleteProductCategoryAPI(t *testing.T) {
 			},
 		},
 		{
-			name:              "Unauthorized",
+			name:              "Forbidden",
 			productCategoryID: productCategory.ID,
 			AdminID:           admin.ID,
 			body: fiber.Map{
 				"parent_category_id": productCategory.ParentCategoryID, "id": productCategory.ID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, 2, admin.Active, time.Minute)
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionCatalogManage})).
+					Times(1).
+					Return(false, nil)
+
 				arg := db.DeleteProductCategoryParams{
 					ID:               productCategory.ID,
 					ParentCategoryID: null.IntFromPtr(&productCategory.ParentCategoryID.Int64),
@@ -784,7 +789,7 @@ func TestDeleteProductCategoryAPI(t *testing.T) {
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
-				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
 			},
 		},
 		{
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"errors"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type productCategoryTreeNode struct {
+	ID               int64    `json:"id"`
+	ParentCategoryID null.Int `json:"parent_category_id"`
+	CategoryName     string   `json:"category_name"`
+	CategoryImage    string   `json:"category_image"`
+	// ProductCount is the number of active products of the category itself
+	ProductCount int64 `json:"product_count"`
+	// TotalProductCount adds the products of all the descendant categories
+	TotalProductCount int64                      `json:"total_product_count"`
+	Children          []*productCategoryTreeNode `json:"children"`
+}
+
+/*
+newProductCategoryTree nests the categories under their parents and returns the roots,
+
+a category whose parent is missing is a root, and a category that is
+only reachable through a cycle is left out instead of looping forever.
+It returns the nodes by id as well so a subtree can be picked.
+*/
+func newProductCategoryTree(rows []*db.ListProductCategoryTreeRow) ([]*productCategoryTreeNode, map[int64]*productCategoryTreeNode) {
+	nodes := make(map[int64]*productCategoryTreeNode, len(rows))
+	for _, row := range rows {
+		nodes[row.ID] = &productCategoryTreeNode{
+			ID:               row.ID,
+			ParentCategoryID: row.ParentCategoryID,
+			CategoryName:     row.CategoryName,
+			CategoryImage:    row.CategoryImage,
+			ProductCount:     row.ProductCount,
+			Children:         []*productCategoryTreeNode{},
+		}
+	}
+
+	roots := []*productCategoryTreeNode{}
+	// rows are ordered by id so the children keep a stable order
+	for _, row := range rows {
+		node := nodes[row.ID]
+		parent, ok := nodes[row.ParentCategoryID.Int64]
+		if !row.ParentCategoryID.Valid || !ok {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	visited := make(map[int64]bool, len(rows))
+	var countProducts func(node *productCategoryTreeNode) int64
+	countProducts = func(node *productCategoryTreeNode) int64 {
+		visited[node.ID] = true
+		node.TotalProductCount = node.ProductCount
+		for _, child := range node.Children {
+			node.TotalProductCount += countProducts(child)
+		}
+		return node.TotalProductCount
+	}
+	for _, root := range roots {
+		countProducts(root)
+	}
+
+	for id := range nodes {
+		if !visited[id] {
+			delete(nodes, id)
+		}
+	}
+
+	return roots, nodes
+}
+
+// //////////////* Tree API //////////////
+
+type getProductCategoryTreeQueryRequest struct {
+	CategoryID int64 `query:"category_id" validate:"omitempty,min=1"`
+}
+
+type productCategoryTreeResponse struct {
+	Categories []*productCategoryTreeNode `json:"categories"`
+	// Breadcrumbs are the ancestors of category_id from the root down to the category itself
+	Breadcrumbs []*db.ListProductCategoryBreadcrumbsRow `json:"breadcrumbs"`
+}
+
+/*
+getProductCategoryTree returns the categories nested under their parents with their product counts,
+
+with ?category_id= only the subtree of that category is returned along with its breadcrumbs.
+*/
+func (server *Server) getProductCategoryTree(ctx fiber.Ctx) error {
+	query := &getProductCategoryTreeQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	rows, err := server.store.ListProductCategoryTree(ctx.Context())
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	roots, nodes := newProductCategoryTree(rows)
+
+	rsp := productCategoryTreeResponse{
+		Categories:  roots,
+		Breadcrumbs: []*db.ListProductCategoryBreadcrumbsRow{},
+	}
+
+	if query.CategoryID != 0 {
+		node, ok := nodes[query.CategoryID]
+		if !ok {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+			return nil
+		}
+
+		breadcrumbs, err := server.store.ListProductCategoryBreadcrumbs(ctx.Context(), query.CategoryID)
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+
+		rsp.Categories = []*productCategoryTreeNode{node}
+		rsp.Breadcrumbs = breadcrumbs
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// //////////////* Move API //////////////
+
+type moveProductCategoryParamsRequest struct {
+	AdminID    int64 `uri:"adminId" validate:"required,min=1"`
+	CategoryID int64 `uri:"categoryId" validate:"required,min=1"`
+}
+
+type moveProductCategoryJsonRequest struct {
+	// ParentCategoryID is the new parent, null moves the category to the root
+	ParentCategoryID *int64 `json:"parent_category_id" validate:"omitempty,min=1"`
+}
+
+// moveProductCategory sets the parent of a category, a move under the category itself or one of its subcategories is refused
+func (server *Server) moveProductCategory(ctx fiber.Ctx) error {
+	params := &moveProductCategoryParamsRequest{}
+	req := &moveProductCategoryJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.MoveProductCategoryTxParams{
+		ID:               params.CategoryID,
+		ParentCategoryID: null.IntFromPtr(req.ParentCategoryID),
+	}
+
+	productCategory, err := server.store.MoveProductCategoryTx(ctx.Context(), arg)
+	if err != nil {
+		if errors.Is(err, db.ErrProductCategoryCycle) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Message {
+			case util.ForeignKeyViolation, util.UniqueViolation:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		} else if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(productCategory)
+	return nil
+}
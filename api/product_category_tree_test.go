@@ -0,0 +1,398 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	mockik "github.com/cshop/v3/image/mock"
+	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	mockwk "github.com/cshop/v3/worker/mock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewProductCategoryTree(t *testing.T) {
+	rows := []*db.ListProductCategoryTreeRow{
+		{ID: 1, CategoryName: "root", ProductCount: 1},
+		{ID: 2, ParentCategoryID: null.IntFrom(1), CategoryName: "child", ProductCount: 2},
+		{ID: 3, ParentCategoryID: null.IntFrom(2), CategoryName: "grandchild", ProductCount: 4},
+		{ID: 4, ParentCategoryID: null.IntFrom(1), CategoryName: "empty child"},
+		// the parent of an orphan is gone so it's shown as a root
+		{ID: 5, ParentCategoryID: null.IntFrom(99), CategoryName: "orphan", ProductCount: 8},
+		// a cycle isn't reachable from any root
+		{ID: 6, ParentCategoryID: null.IntFrom(7), CategoryName: "cycle a"},
+		{ID: 7, ParentCategoryID: null.IntFrom(6), CategoryName: "cycle b"},
+	}
+
+	roots, nodes := newProductCategoryTree(rows)
+	require.Len(t, roots, 2)
+	require.Len(t, nodes, 5)
+
+	root := roots[0]
+	require.Equal(t, int64(1), root.ID)
+	require.Equal(t, int64(7), root.TotalProductCount)
+	require.Len(t, root.Children, 2)
+	require.Equal(t, int64(2), root.Children[0].ID)
+	require.Equal(t, int64(6), root.Children[0].TotalProductCount)
+	require.Equal(t, int64(3), root.Children[0].Children[0].ID)
+	require.Equal(t, int64(4), root.Children[1].ID)
+	require.Empty(t, root.Children[1].Children)
+
+	require.Equal(t, int64(5), roots[1].ID)
+	require.Equal(t, int64(8), roots[1].TotalProductCount)
+	require.NotContains(t, nodes, int64(6))
+	require.NotContains(t, nodes, int64(7))
+}
+
+func TestGetProductCategoryTreeAPI(t *testing.T) {
+	rows := []*db.ListProductCategoryTreeRow{
+		{ID: 1, CategoryName: util.RandomString(5), CategoryImage: util.RandomURL(), ProductCount: 3},
+		{ID: 2, ParentCategoryID: null.IntFrom(1), CategoryName: util.RandomString(5), CategoryImage: util.RandomURL(), ProductCount: 2},
+		{ID: 3, CategoryName: util.RandomString(5), CategoryImage: util.RandomURL()},
+	}
+	breadcrumbs := []*db.ListProductCategoryBreadcrumbsRow{
+		{ID: 1, CategoryName: rows[0].CategoryName},
+		{ID: 2, ParentCategoryID: null.IntFrom(1), CategoryName: rows[1].CategoryName},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *http.Response)
+	}{
+		{
+			name:  "OK",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListProductCategoryTree(gomock.Any()).
+					Times(1).
+					Return(rows, nil)
+				store.EXPECT().
+					ListProductCategoryBreadcrumbs(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				got := requireBodyProductCategoryTree(t, rsp.Body)
+				require.Len(t, got.Categories, 2)
+				require.Equal(t, int64(1), got.Categories[0].ID)
+				require.Equal(t, int64(5), got.Categories[0].TotalProductCount)
+				require.Len(t, got.Categories[0].Children, 1)
+				require.Equal(t, int64(2), got.Categories[0].Children[0].ID)
+				require.Equal(t, int64(3), got.Categories[1].ID)
+				require.Empty(t, got.Breadcrumbs)
+			},
+		},
+		{
+			name:  "OKWithCategory",
+			query: "?category_id=2",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListProductCategoryTree(gomock.Any()).
+					Times(1).
+					Return(rows, nil)
+				store.EXPECT().
+					ListProductCategoryBreadcrumbs(gomock.Any(), gomock.Eq(int64(2))).
+					Times(1).
+					Return(breadcrumbs, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				got := requireBodyProductCategoryTree(t, rsp.Body)
+				require.Len(t, got.Categories, 1)
+				require.Equal(t, int64(2), got.Categories[0].ID)
+				require.Equal(t, int64(2), got.Categories[0].TotalProductCount)
+				require.Equal(t, breadcrumbs, got.Breadcrumbs)
+			},
+		},
+		{
+			name:  "NotFound",
+			query: "?category_id=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListProductCategoryTree(gomock.Any()).
+					Times(1).
+					Return(rows, nil)
+				store.EXPECT().
+					ListProductCategoryBreadcrumbs(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListProductCategoryTree(gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:  "InvalidCategoryID",
+			query: "?category_id=-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListProductCategoryTree(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := "/api/v1/categories/tree" + tc.query
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, rsp)
+		})
+	}
+}
+
+func TestMoveProductCategoryAPI(t *testing.T) {
+	admin, _ := randomPCategorieSuperAdmin(t)
+	productCategory := randomProductCategory()
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		AdminID       int64
+		categoryID    int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *http.Response)
+	}{
+		{
+			name:       "OK",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": productCategory.ParentCategoryID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.MoveProductCategoryTxParams{
+					ID:               productCategory.ID,
+					ParentCategoryID: productCategory.ParentCategoryID,
+				}
+
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(productCategory, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchProductCategory(t, rsp.Body, productCategory)
+			},
+		},
+		{
+			name:       "MoveToRoot",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": nil,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.MoveProductCategoryTxParams{
+					ID: productCategory.ID,
+				}
+
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.ProductCategory{
+						ID:            productCategory.ID,
+						CategoryName:  productCategory.CategoryName,
+						CategoryImage: productCategory.CategoryImage,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:       "Cycle",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": productCategory.ParentCategoryID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrProductCategoryCycle)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:       "NotFound",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": productCategory.ParentCategoryID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:       "Unauthorized",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": productCategory.ParentCategoryID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, false, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:       "InternalError",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": productCategory.ParentCategoryID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:       "InvalidParentID",
+			AdminID:    admin.ID,
+			categoryID: productCategory.ID,
+			body: fiber.Map{
+				"parent_category_id": -1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MoveProductCategoryTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/categories/%d/parent", tc.AdminID, tc.categoryID)
+			request, err := http.NewRequest(fiber.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, rsp)
+		})
+	}
+}
+
+func requireBodyProductCategoryTree(t *testing.T, body io.ReadCloser) productCategoryTreeResponse {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var got productCategoryTreeResponse
+	err = json.Unmarshal(data, &got)
+	require.NoError(t, err)
+	return got
+}
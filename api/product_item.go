@@ -45,12 +45,17 @@ func (server *Server) createProductItem(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
+	//? the price of a new item needs the pricing permission on top of the catalog one
+	if !checkAdminPermission(ctx, server.store, authPayload.AdminID, db.PermissionPricingManage) {
+		return nil
+	}
+
 	arg := db.AdminCreateProductItemParams{
 		AdminID:    authPayload.AdminID,
 		ProductID:  req.ProductID,
@@ -182,12 +187,16 @@ func (server *Server) updateProductItem(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
+	if req.Price != nil && !checkAdminPermission(ctx, server.store, authPayload.AdminID, db.PermissionPricingManage) {
+		return nil
+	}
+
 	arg := db.AdminUpdateProductItemParams{
 		AdminID:    authPayload.AdminID,
 		ID:         params.ProductItemID,
@@ -231,7 +240,7 @@ func (server *Server) deleteProductItem(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -410,7 +419,7 @@ func (server *Server) listProductItemsNextPage(ctx fiber.Ctx) error {
 //////////////* Paginated Search API //////////////
 
 type searchProductItemsQueryRequest struct {
-	Query string `query:"query" validate:"omitempty,required,alphanumunicode_space"`
+	Query string `query:"query" validate:"omitempty,required,search_query,max=100"`
 	Limit int32  `query:"limit" validate:"required,min=5,max=10"`
 }
 
@@ -436,6 +445,9 @@ func (server *Server) searchProductItems(ctx fiber.Ctx) error {
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
 		return nil
 	}
+	// only the first page is counted, the next pages repeat the search
+	server.logSearchQuery(ctx, query.Query, int64(len(productItems)))
+
 	if len(productItems) == 0 {
 		ctx.Set("Next-Available", strconv.FormatBool(false))
 		ctx.Status(fiber.StatusOK).JSON([]db.SearchProductItemsRow{})
@@ -463,7 +475,7 @@ func (server *Server) searchProductItems(ctx fiber.Ctx) error {
 type searchProductItemsNextPageQueryRequest struct {
 	ProductItemCursor int64  `query:"product_item_cursor" validate:"required,min=1"`
 	ProductCursor     int64  `query:"product_cursor" validate:"required,min=1"`
-	Query             string `query:"query" validate:"omitempty,required,alphanumunicode_space"`
+	Query             string `query:"query" validate:"omitempty,required,search_query,max=100"`
 	Limit             int32  `query:"limit" validate:"required,min=5,max=10"`
 }
 
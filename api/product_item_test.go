@@ -823,6 +823,11 @@ func TestSearchProductItemsAPI(t *testing.T) {
 					SearchProductItems(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(productItems, nil)
+
+				store.EXPECT().
+					CreateSearchQueryLog(gomock.Any(), gomock.Eq(db.CreateSearchQueryLogParams{Query: q, ResultCount: int64(n)})).
+					Times(1).
+					Return(nil)
 			},
 			checkResponse: func(rsp *http.Response) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
@@ -1761,6 +1766,31 @@ func TestUpdateProductItemAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name:          "PricingForbidden",
+			productItemID: productItem.ID,
+			AdminID:       admin.ID,
+			body: fiber.Map{
+				"product_id": productItem.ProductID,
+				"price":      "1000",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionPricingManage})).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					AdminUpdateProductItem(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
 		{
 			name:          "Unauthorized",
 			productItemID: productItem.ID,
@@ -1955,19 +1985,24 @@ func TestDeleteProductItemAPI(t *testing.T) {
 			},
 		},
 		{
-			name:          "Unauthorized",
+			name:          "Forbidden",
 			productItemID: productItem.ID,
 			AdminID:       admin.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, 2, admin.Active, time.Minute)
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionCatalogManage})).
+					Times(1).
+					Return(false, nil)
+
 				store.EXPECT().
 					DeleteProductItem(gomock.Any(), gomock.Eq(productItem.ID)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
-				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
 			},
 		},
 		{
@@ -779,14 +779,19 @@ func TestDeleteProductPromotionAPI(t *testing.T) {
 			},
 		},
 		{
-			name:        "Unauthorized",
+			name:        "Forbidden",
 			PromotionID: productPromotion.PromotionID,
 			ProductID:   productPromotion.ProductID,
 			AdminID:     admin.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, 2, admin.Active, time.Minute)
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionPromotionsManage})).
+					Times(1).
+					Return(false, nil)
+
 				arg := db.DeleteProductPromotionParams{
 					ProductID:   productPromotion.ProductID,
 					PromotionID: productPromotion.PromotionID,
@@ -796,7 +801,7 @@ func TestDeleteProductPromotionAPI(t *testing.T) {
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
-				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
 			},
 		},
 		{
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// reviewPending is the status of the reviews waiting for the moderation
+	reviewPending = "pending"
+	// maxReviewImages is the number of images a review can hold
+	maxReviewImages = 5
+	// maxReviewImageSize stays under the body limit of the app so the error is a clear one
+	maxReviewImageSize = 3 << 20
+	reviewImagesFolder = "/reviews"
+)
+
+// reviewImageExtensions are the accepted image types, detected from the content and not from the file name
+var reviewImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+var (
+	errReviewImagesLimit   = fmt.Errorf("a review can't have more than %d images", maxReviewImages)
+	errReviewImageTooLarge = fmt.Errorf("the image can't be larger than %d MB", maxReviewImageSize>>20)
+	errReviewImageType     = errors.New("the image has to be a jpeg, png or webp")
+	errModerationEmpty     = errors.New("either status or admin_reply is required")
+)
+
+// //////////////* Product Reviews API //////////////
+
+type listProductReviewsParamsRequest struct {
+	ProductID int64 `uri:"productId" validate:"required,min=1"`
+}
+
+type listProductReviewsQueryRequest struct {
+	Sort     string `query:"sort" validate:"omitempty,oneof=newest oldest highest_rating lowest_rating"`
+	PageID   int32  `query:"page_id" validate:"required,min=1"`
+	PageSize int32  `query:"page_size" validate:"required,min=5,max=20"`
+}
+
+type listProductReviewsResponse struct {
+	RatingAverage float64                     `json:"rating_average"`
+	RatingCount   int64                       `json:"rating_count"`
+	Reviews       []*db.ListProductReviewsRow `json:"reviews"`
+}
+
+/*
+listProductReviews returns the approved reviews of a product with its average rating,
+
+a review is marked as a verified purchase when the reviewer received the product it was written for.
+*/
+func (server *Server) listProductReviews(ctx fiber.Ctx) error {
+	params := &listProductReviewsParamsRequest{}
+	query := &listProductReviewsQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	product, err := server.store.GetProduct(ctx.Context(), params.ProductID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.ListProductReviewsParams{
+		Limit:     query.PageSize,
+		Offset:    (query.PageID - 1) * query.PageSize,
+		ProductID: params.ProductID,
+		Sort:      query.Sort,
+	}
+
+	reviews, err := server.store.ListProductReviews(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := listProductReviewsResponse{
+		RatingAverage: product.RatingAverage,
+		RatingCount:   product.RatingCount,
+		Reviews:       reviews,
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// //////////////* Review Images API //////////////
+
+type uploadUserReviewImageParamsRequest struct {
+	UserID   int64 `uri:"id" validate:"required,min=1"`
+	ReviewID int64 `uri:"reviewId" validate:"required,min=1"`
+}
+
+/*
+uploadUserReviewImage adds the image of the "image" form field to a review of the user,
+
+the image is uploaded to imagekit and an approved review goes back to the moderation queue.
+*/
+func (server *Server) uploadUserReviewImage(ctx fiber.Ctx) error {
+	params := &uploadUserReviewImageParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	fileHeader, err := ctx.FormFile("image")
+	if err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if fileHeader.Size > maxReviewImageSize {
+		ctx.Status(fiber.StatusRequestEntityTooLarge).JSON(errorResponse(errReviewImageTooLarge))
+		return nil
+	}
+
+	_, err = server.store.GetUserReview(ctx.Context(), db.GetUserReviewParams{
+		ID:     params.ReviewID,
+		UserID: authPayload.UserID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	imagesCount, err := server.store.CountUserReviewImages(ctx.Context(), params.ReviewID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	if imagesCount >= maxReviewImages {
+		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errReviewImagesLimit))
+		return nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+	defer file.Close()
+
+	image, err := io.ReadAll(io.LimitReader(file, maxReviewImageSize))
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	extension, ok := reviewImageExtensions[http.DetectContentType(image)]
+	if !ok {
+		ctx.Status(fiber.StatusUnsupportedMediaType).JSON(errorResponse(errReviewImageType))
+		return nil
+	}
+
+	fileName := fmt.Sprintf("review-%d%s", params.ReviewID, extension)
+	imageURL, err := server.ik.UploadImage(ctx.Context(), bytes.NewReader(image), fileName, reviewImagesFolder)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	reviewImage, err := server.store.CreateUserReviewImage(ctx.Context(), db.CreateUserReviewImageParams{
+		ReviewID: params.ReviewID,
+		UserID:   authPayload.UserID,
+		ImageUrl: *imageURL,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(reviewImage)
+	return nil
+}
+
+// //////////////* Reviews Moderation API //////////////
+
+type listUserReviewsForModerationParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type listUserReviewsForModerationQueryRequest struct {
+	Status   string `query:"status" validate:"omitempty,oneof=pending approved hidden"`
+	PageID   int32  `query:"page_id" validate:"required,min=1"`
+	PageSize int32  `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listUserReviewsForModeration is the moderation queue, the pending reviews are listed unless another status is asked for
+func (server *Server) listUserReviewsForModeration(ctx fiber.Ctx) error {
+	params := &listUserReviewsForModerationParamsRequest{}
+	query := &listUserReviewsForModerationQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	status := query.Status
+	if status == "" {
+		status = reviewPending
+	}
+
+	arg := db.ListUserReviewsForModerationParams{
+		Limit:  query.PageSize,
+		Offset: (query.PageID - 1) * query.PageSize,
+		Status: status,
+	}
+
+	reviews, err := server.store.ListUserReviewsForModeration(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(reviews)
+	return nil
+}
+
+type moderateUserReviewParamsRequest struct {
+	AdminID  int64 `uri:"adminId" validate:"required,min=1"`
+	ReviewID int64 `uri:"reviewId" validate:"required,min=1"`
+}
+
+type moderateUserReviewJsonRequest struct {
+	Status *string `json:"status" validate:"omitempty,oneof=approved hidden"`
+	// AdminReply is shown under the review, an empty reply removes it
+	AdminReply *string `json:"admin_reply" validate:"omitempty,max=1000"`
+}
+
+// moderateUserReview approves or hides a review and replies to it, the product rating only counts the approved reviews
+func (server *Server) moderateUserReview(ctx fiber.Ctx) error {
+	params := &moderateUserReviewParamsRequest{}
+	req := &moderateUserReviewJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if req.Status == nil && req.AdminReply == nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errModerationEmpty))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.ModerateUserReviewParams{
+		Status:     null.StringFromPtr(req.Status),
+		AdminReply: null.StringFromPtr(req.AdminReply),
+		ID:         params.ReviewID,
+	}
+
+	review, err := server.store.ModerateUserReview(ctx.Context(), arg)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(review)
+	return nil
+}
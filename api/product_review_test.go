@@ -0,0 +1,655 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	mockik "github.com/cshop/v3/image/mock"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListProductReviewsAPI(t *testing.T) {
+	product := randomProduct()
+	product.RatingAverage = 4.5
+	product.RatingCount = 2
+
+	reviews := []*db.ListProductReviewsRow{
+		{ID: 2, ProductID: product.ID, RatingValue: 5, Title: "great", Username: util.RandomUser(), VerifiedPurchase: true, Images: []string{util.RandomURL()}, TotalCount: 2},
+		{ID: 1, ProductID: product.ID, RatingValue: 4, Username: util.RandomUser(), Images: []string{}, TotalCount: 2},
+	}
+
+	testCases := []struct {
+		name          string
+		productID     int64
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:      "OK",
+			productID: product.ID,
+			query:     url.Values{"sort": {"highest_rating"}, "page_id": {"2"}, "page_size": {"5"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(product, nil)
+
+				arg := db.ListProductReviewsParams{
+					Limit:     5,
+					Offset:    5,
+					ProductID: product.ID,
+					Sort:      "highest_rating",
+				}
+				store.EXPECT().
+					ListProductReviews(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reviews, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotRsp listProductReviewsResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, product.RatingAverage, gotRsp.RatingAverage)
+				require.Equal(t, product.RatingCount, gotRsp.RatingCount)
+				require.Len(t, gotRsp.Reviews, 2)
+				require.True(t, gotRsp.Reviews[0].VerifiedPurchase)
+				require.Equal(t, reviews[0].Images, gotRsp.Reviews[0].Images)
+			},
+		},
+		{
+			name:      "NotFound",
+			productID: product.ID,
+			query:     url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					ListProductReviews(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:      "InvalidSort",
+			productID: product.ID,
+			query:     url.Values{"sort": {"helpful"}, "page_id": {"1"}, "page_size": {"5"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:      "InternalError",
+			productID: product.ID,
+			query:     url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(product, nil)
+
+				store.EXPECT().
+					ListProductReviews(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/api/v1/products/%d/reviews?%s", tc.productID, tc.query.Encode())
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestUploadUserReviewImageAPI(t *testing.T) {
+	user, _ := randomURUser(t)
+	userReview := createRandomUserReview(user)
+	imageURL := util.RandomURL()
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	text := []byte("not an image")
+
+	testCases := []struct {
+		name          string
+		userID        int64
+		image         []byte
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			userID: user.ID,
+			image:  png,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement) {
+				store.EXPECT().
+					GetUserReview(gomock.Any(), gomock.Eq(db.GetUserReviewParams{ID: userReview.ID, UserID: user.ID})).
+					Times(1).
+					Return(userReview, nil)
+
+				store.EXPECT().
+					CountUserReviewImages(gomock.Any(), gomock.Eq(userReview.ID)).
+					Times(1).
+					Return(int64(1), nil)
+
+				ik.EXPECT().
+					UploadImage(gomock.Any(), gomock.Any(), gomock.Eq(fmt.Sprintf("review-%d.png", userReview.ID)), gomock.Eq(reviewImagesFolder)).
+					Times(1).
+					Return(&imageURL, nil)
+
+				arg := db.CreateUserReviewImageParams{
+					ReviewID: userReview.ID,
+					UserID:   user.ID,
+					ImageUrl: imageURL,
+				}
+				store.EXPECT().
+					CreateUserReviewImage(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.UserReviewImage{ID: 1, ReviewID: userReview.ID, ImageUrl: imageURL}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotImage db.UserReviewImage
+				err := json.NewDecoder(rsp.Body).Decode(&gotImage)
+				require.NoError(t, err)
+				require.Equal(t, imageURL, gotImage.ImageUrl)
+			},
+		},
+		{
+			name:   "TooManyImages",
+			userID: user.ID,
+			image:  png,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement) {
+				store.EXPECT().
+					GetUserReview(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userReview, nil)
+
+				store.EXPECT().
+					CountUserReviewImages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(maxReviewImages), nil)
+
+				ik.EXPECT().
+					UploadImage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "UnsupportedType",
+			userID: user.ID,
+			image:  text,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement) {
+				store.EXPECT().
+					GetUserReview(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userReview, nil)
+
+				store.EXPECT().
+					CountUserReviewImages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				ik.EXPECT().
+					UploadImage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnsupportedMediaType, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "NotFound",
+			userID: user.ID,
+			image:  png,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement) {
+				store.EXPECT().
+					GetUserReview(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					CountUserReviewImages(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "NoImage",
+			userID: user.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement) {
+				store.EXPECT().
+					GetUserReview(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "AnotherUser",
+			userID: user.ID + 1,
+			image:  png,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, ik *mockik.MockImageKitManagement) {
+				store.EXPECT().
+					GetUserReview(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			tc.buildStubs(store, ik)
+
+			server := newTestServer(t, store, nil, ik, nil)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			if tc.image != nil {
+				part, err := writer.CreateFormFile("image", "review.png")
+				require.NoError(t, err)
+				_, err = part.Write(tc.image)
+				require.NoError(t, err)
+			}
+			require.NoError(t, writer.Close())
+
+			url := fmt.Sprintf("/usr/v1/users/%d/reviews/%d/images", tc.userID, userReview.ID)
+			request, err := http.NewRequest(fiber.MethodPost, url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListUserReviewsForModerationAPI(t *testing.T) {
+	admin, _ := randomSuperAdmin(t)
+
+	reviews := []*db.ListUserReviewsForModerationRow{
+		{ID: 1, ProductID: 3, RatingValue: 1, Status: reviewPending, Username: util.RandomUser(), TotalCount: 1},
+	}
+
+	testCases := []struct {
+		name          string
+		adminID       int64
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			adminID: admin.ID,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"10"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUserReviewsForModerationParams{
+					Limit:  10,
+					Offset: 0,
+					Status: reviewPending,
+				}
+				store.EXPECT().
+					ListUserReviewsForModeration(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reviews, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotReviews []*db.ListUserReviewsForModerationRow
+				err := json.NewDecoder(rsp.Body).Decode(&gotReviews)
+				require.NoError(t, err)
+				require.Len(t, gotReviews, 1)
+				require.Equal(t, reviews[0].ID, gotReviews[0].ID)
+			},
+		},
+		{
+			name:    "HiddenReviews",
+			adminID: admin.ID,
+			query:   url.Values{"status": {"hidden"}, "page_id": {"1"}, "page_size": {"10"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUserReviewsForModerationParams{
+					Limit:  10,
+					Offset: 0,
+					Status: "hidden",
+				}
+				store.EXPECT().
+					ListUserReviewsForModeration(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]*db.ListUserReviewsForModerationRow{}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidStatus",
+			adminID: admin.ID,
+			query:   url.Values{"status": {"deleted"}, "page_id": {"1"}, "page_size": {"10"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserReviewsForModeration(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "AnotherAdmin",
+			adminID: admin.ID + 1,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"10"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserReviewsForModeration(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InternalError",
+			adminID: admin.ID,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"10"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserReviewsForModeration(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/reviews?%s", tc.adminID, tc.query.Encode())
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestModerateUserReviewAPI(t *testing.T) {
+	admin, _ := randomSuperAdmin(t)
+	user, _ := randomURUser(t)
+	userReview := createRandomUserReview(user)
+
+	testCases := []struct {
+		name          string
+		adminID       int64
+		body          fiber.Map
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			adminID: admin.ID,
+			body: fiber.Map{
+				"status":      "approved",
+				"admin_reply": "thank you for the review",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ModerateUserReviewParams{
+					Status:     null.StringFrom("approved"),
+					AdminReply: null.StringFrom("thank you for the review"),
+					ID:         userReview.ID,
+				}
+				store.EXPECT().
+					ModerateUserReview(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(userReview, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchUserReview(t, rsp.Body, userReview)
+			},
+		},
+		{
+			name:    "ReplyOnly",
+			adminID: admin.ID,
+			body: fiber.Map{
+				"admin_reply": "",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ModerateUserReviewParams{
+					AdminReply: null.StringFrom(""),
+					ID:         userReview.ID,
+				}
+				store.EXPECT().
+					ModerateUserReview(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(userReview, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "EmptyBody",
+			adminID: admin.ID,
+			body:    fiber.Map{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ModerateUserReview(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidStatus",
+			adminID: admin.ID,
+			body: fiber.Map{
+				"status": "pending",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ModerateUserReview(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "NotFound",
+			adminID: admin.ID,
+			body: fiber.Map{
+				"status": "hidden",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ModerateUserReview(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Unauthorized",
+			adminID: admin.ID,
+			body: fiber.Map{
+				"status": "hidden",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ModerateUserReview(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/reviews/%d", tc.adminID, userReview.ID)
+			request, err := http.NewRequest(fiber.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
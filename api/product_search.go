@@ -0,0 +1,188 @@
+package api
+
+import (
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+)
+
+// facet names returned by ListProductItemFacets
+const (
+	facetBrand    = "brand"
+	facetCategory = "category"
+	facetColor    = "color"
+	facetSize     = "size"
+	facetPrice    = "price"
+)
+
+// priceFacetBounds split the price facet in buckets, the first bucket is below the first bound
+// and the last one is from the last bound up
+var priceFacetBounds = []string{"50", "100", "200", "500", "1000"}
+
+type facetValueResponse struct {
+	ID    int64  `json:"id,omitempty"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type priceFacetResponse struct {
+	// Min and Max are empty for the open ended buckets
+	Min   string `json:"min"`
+	Max   string `json:"max"`
+	Count int64  `json:"count"`
+}
+
+type productItemFacetsResponse struct {
+	Brands     []facetValueResponse `json:"brands"`
+	Categories []facetValueResponse `json:"categories"`
+	Colors     []facetValueResponse `json:"colors"`
+	Sizes      []facetValueResponse `json:"sizes"`
+	Prices     []priceFacetResponse `json:"prices"`
+}
+
+/*
+newProductItemFacetsResponse groups the facet rows by facet,
+
+every price bucket is listed so the app can show the empty ones, the other facets only list the values with items.
+*/
+func newProductItemFacetsResponse(rows []*db.ListProductItemFacetsRow) productItemFacetsResponse {
+	rsp := productItemFacetsResponse{
+		Brands:     []facetValueResponse{},
+		Categories: []facetValueResponse{},
+		Colors:     []facetValueResponse{},
+		Sizes:      []facetValueResponse{},
+		Prices:     make([]priceFacetResponse, len(priceFacetBounds)+1),
+	}
+
+	for i := range rsp.Prices {
+		if i > 0 {
+			rsp.Prices[i].Min = priceFacetBounds[i-1]
+		}
+		if i < len(priceFacetBounds) {
+			rsp.Prices[i].Max = priceFacetBounds[i]
+		}
+	}
+
+	for _, row := range rows {
+		value := facetValueResponse{ID: row.ValueID, Value: row.Value, Count: row.ItemCount}
+
+		switch row.Facet {
+		case facetBrand:
+			rsp.Brands = append(rsp.Brands, value)
+		case facetCategory:
+			rsp.Categories = append(rsp.Categories, value)
+		case facetColor:
+			rsp.Colors = append(rsp.Colors, value)
+		case facetSize:
+			rsp.Sizes = append(rsp.Sizes, value)
+		case facetPrice:
+			// the value id is the width_bucket of the price
+			if row.ValueID >= 0 && row.ValueID < int64(len(rsp.Prices)) {
+				rsp.Prices[row.ValueID].Count = row.ItemCount
+			}
+		}
+	}
+
+	return rsp
+}
+
+// //////////////* Faceted Search API //////////////
+
+type searchProductItemsFacetedQueryRequest struct {
+	Query       string   `query:"query" validate:"omitempty,search_query,max=100"`
+	BrandIDs    []int64  `query:"brand_id" validate:"max=20,dive,min=1"`
+	CategoryIDs []int64  `query:"category_id" validate:"max=20,dive,min=1"`
+	ColorIDs    []int64  `query:"color_id" validate:"max=20,dive,min=1"`
+	Sizes       []string `query:"size" validate:"max=20,dive,required,max=20"`
+	MinPrice    string   `query:"min_price" validate:"omitempty,numeric"`
+	MaxPrice    string   `query:"max_price" validate:"omitempty,numeric"`
+	PageID      int32    `query:"page_id" validate:"required,min=1"`
+	PageSize    int32    `query:"page_size" validate:"required,min=5,max=10"`
+}
+
+type searchProductItemsFacetedResponse struct {
+	Items      []*db.SearchProductItemsFacetedRow `json:"items"`
+	TotalCount int64                              `json:"total_count"`
+	Facets     productItemFacetsResponse          `json:"facets"`
+}
+
+/*
+searchProductItemsFaceted returns a page of the items matching the search query and the filters,
+
+a filter given several times matches any of its values, e.g. ?brand_id=1&brand_id=2&color_id=3.
+The facets count the items per brand, category, color, size and price bucket under the current filters.
+*/
+func (server *Server) searchProductItemsFaceted(ctx fiber.Ctx) error {
+	query := &searchProductItemsFacetedQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	minPrice := null.NewString(query.MinPrice, query.MinPrice != "")
+	maxPrice := null.NewString(query.MaxPrice, query.MaxPrice != "")
+
+	arg := db.SearchProductItemsFacetedParams{
+		Limit:       query.PageSize,
+		Offset:      (query.PageID - 1) * query.PageSize,
+		Query:       query.Query,
+		BrandIds:    nonNilIDs(query.BrandIDs),
+		CategoryIds: nonNilIDs(query.CategoryIDs),
+		ColorIds:    nonNilIDs(query.ColorIDs),
+		Sizes:       nonNilStrings(query.Sizes),
+		MinPrice:    minPrice,
+		MaxPrice:    maxPrice,
+	}
+
+	productItems, err := server.store.SearchProductItemsFaceted(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	facets, err := server.store.ListProductItemFacets(ctx.Context(), db.ListProductItemFacetsParams{
+		Query:       arg.Query,
+		BrandIds:    arg.BrandIds,
+		CategoryIds: arg.CategoryIds,
+		ColorIds:    arg.ColorIds,
+		Sizes:       arg.Sizes,
+		MinPrice:    arg.MinPrice,
+		MaxPrice:    arg.MaxPrice,
+		PriceBounds: priceFacetBounds,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := searchProductItemsFacetedResponse{
+		Items:  productItems,
+		Facets: newProductItemFacetsResponse(facets),
+	}
+	if len(productItems) > 0 {
+		rsp.TotalCount = productItems[0].TotalCount
+	}
+
+	if query.PageID == 1 {
+		server.logSearchQuery(ctx, query.Query, rsp.TotalCount)
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// nonNilIDs sends an empty array instead of NULL so cardinality() is 0 for a missing filter
+func nonNilIDs(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
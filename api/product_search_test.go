@@ -0,0 +1,285 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSearchProductItemsFacetedAPI(t *testing.T) {
+	productItem := &db.SearchProductItemsFacetedRow{
+		ID:         util.RandomMoney(),
+		ProductID:  util.RandomMoney(),
+		Price:      "120",
+		TotalCount: 7,
+	}
+
+	facets := []*db.ListProductItemFacetsRow{
+		{Facet: facetBrand, ValueID: 1, Value: "brand 1", ItemCount: 4},
+		{Facet: facetBrand, ValueID: 2, Value: "brand 2", ItemCount: 3},
+		{Facet: facetCategory, ValueID: 5, Value: "shoes", ItemCount: 7},
+		{Facet: facetColor, ValueID: 3, Value: "red", ItemCount: 2},
+		{Facet: facetSize, Value: "XL", ItemCount: 6},
+		{Facet: facetPrice, ValueID: 2, ItemCount: 7},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			query: url.Values{
+				"query":     {"running shoes"},
+				"brand_id":  {"1", "2"},
+				"color_id":  {"3"},
+				"size":      {"XL"},
+				"min_price": {"100"},
+				"page_id":   {"2"},
+				"page_size": {"5"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchProductItemsFacetedParams{
+					Limit:       5,
+					Offset:      5,
+					Query:       "running shoes",
+					BrandIds:    []int64{1, 2},
+					CategoryIds: []int64{},
+					ColorIds:    []int64{3},
+					Sizes:       []string{"XL"},
+					MinPrice:    null.StringFrom("100"),
+				}
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]*db.SearchProductItemsFacetedRow{productItem}, nil)
+
+				facetsArg := db.ListProductItemFacetsParams{
+					Query:       arg.Query,
+					BrandIds:    arg.BrandIds,
+					CategoryIds: arg.CategoryIds,
+					ColorIds:    arg.ColorIds,
+					Sizes:       arg.Sizes,
+					MinPrice:    arg.MinPrice,
+					PriceBounds: priceFacetBounds,
+				}
+				store.EXPECT().
+					ListProductItemFacets(gomock.Any(), gomock.Eq(facetsArg)).
+					Times(1).
+					Return(facets, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotRsp searchProductItemsFacetedResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotRsp)
+				require.NoError(t, err)
+
+				require.Len(t, gotRsp.Items, 1)
+				require.Equal(t, productItem.ID, gotRsp.Items[0].ID)
+				require.Equal(t, int64(7), gotRsp.TotalCount)
+
+				require.Len(t, gotRsp.Facets.Brands, 2)
+				require.Equal(t, facetValueResponse{ID: 1, Value: "brand 1", Count: 4}, gotRsp.Facets.Brands[0])
+				require.Len(t, gotRsp.Facets.Categories, 1)
+				require.Len(t, gotRsp.Facets.Colors, 1)
+				require.Equal(t, []facetValueResponse{{Value: "XL", Count: 6}}, gotRsp.Facets.Sizes)
+
+				// every price bucket is listed, the empty ones included
+				require.Len(t, gotRsp.Facets.Prices, len(priceFacetBounds)+1)
+				require.Equal(t, priceFacetResponse{Max: priceFacetBounds[0]}, gotRsp.Facets.Prices[0])
+				require.Equal(t, priceFacetResponse{Min: priceFacetBounds[1], Max: priceFacetBounds[2], Count: 7}, gotRsp.Facets.Prices[2])
+				require.Equal(t, priceFacetBounds[len(priceFacetBounds)-1], gotRsp.Facets.Prices[len(priceFacetBounds)].Min)
+				require.Empty(t, gotRsp.Facets.Prices[len(priceFacetBounds)].Max)
+			},
+		},
+		{
+			name: "NoFilters",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchProductItemsFacetedParams{
+					Limit:       10,
+					Offset:      0,
+					BrandIds:    []int64{},
+					CategoryIds: []int64{},
+					ColorIds:    []int64{},
+					Sizes:       []string{},
+				}
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]*db.SearchProductItemsFacetedRow{}, nil)
+
+				store.EXPECT().
+					ListProductItemFacets(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*db.ListProductItemFacetsRow{}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotRsp searchProductItemsFacetedResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotRsp)
+				require.NoError(t, err)
+				require.Empty(t, gotRsp.Items)
+				require.Zero(t, gotRsp.TotalCount)
+				require.NotNil(t, gotRsp.Facets.Brands)
+				require.Len(t, gotRsp.Facets.Prices, len(priceFacetBounds)+1)
+			},
+		},
+		{
+			name: "InternalError",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+
+				store.EXPECT().
+					ListProductItemFacets(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ArabicQueryWithOperators",
+			query: url.Values{
+				"query":     {"\"قميص أَحمر\" -أزرق | shirt & red"},
+				"page_id":   {"1"},
+				"page_size": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.SearchProductItemsFacetedParams) ([]*db.SearchProductItemsFacetedRow, error) {
+						// the query is parsed by websearch_to_tsquery in the database
+						require.Equal(t, "\"قميص أَحمر\" -أزرق | shirt & red", arg.Query)
+						return []*db.SearchProductItemsFacetedRow{}, nil
+					})
+
+				store.EXPECT().
+					ListProductItemFacets(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*db.ListProductItemFacetsRow{}, nil)
+
+				// the first page of a search is logged with its total count
+				store.EXPECT().
+					CreateSearchQueryLog(gomock.Any(), gomock.Eq(db.CreateSearchQueryLogParams{
+						Query:       "\"قميص أَحمر\" -أزرق | shirt & red",
+						ResultCount: 0,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidQuery",
+			query: url.Values{
+				"query":     {"shoes; DROP TABLE"},
+				"page_id":   {"1"},
+				"page_size": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidBrandID",
+			query: url.Values{
+				"brand_id":  {"0"},
+				"page_id":   {"1"},
+				"page_size": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidPrice",
+			query: url.Values{
+				"max_price": {"cheap"},
+				"page_id":   {"1"},
+				"page_size": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {"11"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProductItemsFaceted(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			request, err := http.NewRequest(fiber.MethodGet, "/api/v1/search-product-items-faceted?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
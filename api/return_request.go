@@ -0,0 +1,287 @@
+package api
+
+import (
+	"errors"
+	"log"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//////////////* Create API //////////////
+
+type createReturnRequestParamsRequest struct {
+	UserID      int64 `uri:"id" validate:"required,min=1"`
+	ShopOrderID int64 `uri:"orderId" validate:"required,min=1"`
+}
+
+type returnRequestItemRequest struct {
+	ShopOrderItemID int64 `json:"shop_order_item_id" validate:"required,min=1"`
+	Qty             int32 `json:"qty" validate:"required,min=1"`
+}
+
+type createReturnRequestJsonRequest struct {
+	Reason string                     `json:"reason" validate:"required,max=500"`
+	Items  []returnRequestItemRequest `json:"items" validate:"required,min=1,max=50,unique=ShopOrderItemID,dive"`
+}
+
+func (server *Server) createReturnRequest(ctx fiber.Ctx) error {
+	params := &createReturnRequestParamsRequest{}
+	req := &createReturnRequestJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	items := make([]db.ReturnRequestItemParams, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = db.ReturnRequestItemParams{
+			ShopOrderItemID: item.ShopOrderItemID,
+			Qty:             item.Qty,
+		}
+	}
+
+	arg := db.CreateReturnRequestTxParams{
+		UserID:      authPayload.UserID,
+		ShopOrderID: params.ShopOrderID,
+		Reason:      req.Reason,
+		Items:       items,
+	}
+
+	result, err := server.store.CreateReturnRequestTx(ctx.Context(), arg)
+	if err != nil {
+		var returnErr *db.ReturnNotAllowedError
+		if errors.As(err, &returnErr) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Message {
+			case util.ForeignKeyViolation, util.UniqueViolation:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(result)
+	return nil
+}
+
+//////////////* Get API //////////////
+
+type getReturnRequestParamsRequest struct {
+	UserID          int64 `uri:"id" validate:"required,min=1"`
+	ReturnRequestID int64 `uri:"returnId" validate:"required,min=1"`
+}
+
+type returnRequestResponse struct {
+	ReturnRequest *db.ReturnRequest                          `json:"return_request"`
+	Items         []*db.ListReturnRequestItemsByRequestIDRow `json:"items"`
+}
+
+func (server *Server) getReturnRequest(ctx fiber.Ctx) error {
+	params := &getReturnRequestParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	returnRequest, err := server.store.GetReturnRequestByUserID(ctx.Context(), db.GetReturnRequestByUserIDParams{
+		ID:     params.ReturnRequestID,
+		UserID: authPayload.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	items, err := server.store.ListReturnRequestItemsByRequestID(ctx.Context(), returnRequest.ID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := returnRequestResponse{
+		ReturnRequest: returnRequest,
+		Items:         items,
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+//////////////* List API //////////////
+
+type listReturnRequestsParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+type listReturnRequestsQueryRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=10"`
+}
+
+func (server *Server) listReturnRequests(ctx fiber.Ctx) error {
+	params := &listReturnRequestsParamsRequest{}
+	query := &listReturnRequestsQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.ListReturnRequestsByUserIDParams{
+		UserID: authPayload.UserID,
+		Limit:  query.PageSize,
+		Offset: (query.PageID - 1) * query.PageSize,
+	}
+
+	returnRequests, err := server.store.ListReturnRequestsByUserID(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(returnRequests)
+	return nil
+}
+
+type listReturnRequestsForAdminParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type listReturnRequestsForAdminQueryRequest struct {
+	PageID   int32  `query:"page_id" validate:"required,min=1"`
+	PageSize int32  `query:"page_size" validate:"required,min=5,max=10"`
+	Status   string `query:"status" validate:"omitempty,oneof=requested approved rejected received refunded"`
+}
+
+func (server *Server) listReturnRequestsForAdmin(ctx fiber.Ctx) error {
+	params := &listReturnRequestsForAdminParamsRequest{}
+	query := &listReturnRequestsForAdminQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.AdminListReturnRequestsParams{
+		AdminID: authPayload.AdminID,
+		Status:  null.NewString(query.Status, query.Status != ""),
+		Limit:   query.PageSize,
+		Offset:  (query.PageID - 1) * query.PageSize,
+	}
+
+	returnRequests, err := server.store.AdminListReturnRequests(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(returnRequests)
+	return nil
+}
+
+//////////////* Update API //////////////
+
+type updateReturnRequestParamsRequest struct {
+	AdminID         int64 `uri:"adminId" validate:"required,min=1"`
+	ReturnRequestID int64 `uri:"returnId" validate:"required,min=1"`
+}
+
+type updateReturnRequestJsonRequest struct {
+	Status    string  `json:"status" validate:"required,oneof=approved rejected received refunded"`
+	AdminNote *string `json:"admin_note" validate:"omitempty,max=500"`
+}
+
+func (server *Server) updateReturnRequest(ctx fiber.Ctx) error {
+	params := &updateReturnRequestParamsRequest{}
+	req := &updateReturnRequestJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.UpdateReturnRequestTxParams{
+		AdminID:         authPayload.AdminID,
+		ReturnRequestID: params.ReturnRequestID,
+		Status:          req.Status,
+		AdminNote:       null.StringFromPtr(req.AdminNote),
+	}
+
+	result, err := server.store.UpdateReturnRequestTx(ctx.Context(), arg)
+	if err != nil {
+		var transitionErr *db.InvalidReturnStatusTransitionError
+		if errors.As(err, &transitionErr) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	//? the refund is recorded as pending with the return request, one the provider didn't make is retried by a task
+	if err := server.refundReturnRequest(ctx.Context(), result.ReturnRequest); err != nil {
+		log.Println(err)
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(result.ReturnRequest)
+	return nil
+}
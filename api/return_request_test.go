@@ -0,0 +1,1024 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	mockik "github.com/cshop/v3/image/mock"
+	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/payment"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	mockwk "github.com/cshop/v3/worker/mock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateReturnRequestAPI(t *testing.T) {
+	user, _ := randomSOUser(t)
+	returnRequest := randomReturnRequest(user.ID)
+	item := randomReturnRequestItem(returnRequest.ID)
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		ShopOrderID   int64
+		body          fiber.Map
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:        "OK",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": item.Qty},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateReturnRequestTxParams{
+					UserID:      user.ID,
+					ShopOrderID: returnRequest.ShopOrderID,
+					Reason:      returnRequest.Reason,
+					Items: []db.ReturnRequestItemParams{
+						{ShopOrderItemID: item.ShopOrderItemID, Qty: item.Qty},
+					},
+				}
+
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.CreateReturnRequestTxResult{
+						ReturnRequest: returnRequest,
+						Items:         []*db.ReturnRequestItem{item},
+					}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCreateReturnRequest(t, rsp.Body, returnRequest, item)
+			},
+		},
+		{
+			name:        "NotReturnable",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": item.Qty},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.ReturnNotAllowedError{Reason: "Order is not delivered"})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NotFound",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": item.Qty},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "DuplicateItems",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": 1},
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": 1},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NoItems",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items":  []fiber.Map{},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "Unauthorized",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": item.Qty},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NoAuthorization",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": item.Qty},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "InternalError",
+			UserID:      user.ID,
+			ShopOrderID: returnRequest.ShopOrderID,
+			body: fiber.Map{
+				"reason": returnRequest.Reason,
+				"items": []fiber.Map{
+					{"shop_order_item_id": item.ShopOrderItemID, "qty": item.Qty},
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/shop-orders/%d/returns", tc.UserID, tc.ShopOrderID)
+			request, err := http.NewRequest(fiber.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestGetReturnRequestAPI(t *testing.T) {
+	user, _ := randomSOUser(t)
+	returnRequest := randomReturnRequest(user.ID)
+	items := []*db.ListReturnRequestItemsByRequestIDRow{
+		{
+			ID:              util.RandomMoney(),
+			ReturnRequestID: returnRequest.ID,
+			ShopOrderItemID: util.RandomMoney(),
+			Qty:             1,
+			Price:           util.RandomDecimalString(1, 100),
+			ProductName:     null.StringFrom(util.RandomString(6)),
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		UserID          int64
+		ReturnRequestID int64
+		setupAuth       func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs      func(store *mockdb.MockStore)
+		checkResponse   func(rsp *http.Response)
+	}{
+		{
+			name:            "OK",
+			UserID:          user.ID,
+			ReturnRequestID: returnRequest.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetReturnRequestByUserIDParams{
+					ID:     returnRequest.ID,
+					UserID: user.ID,
+				}
+
+				store.EXPECT().
+					GetReturnRequestByUserID(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(returnRequest, nil)
+
+				store.EXPECT().
+					ListReturnRequestItemsByRequestID(gomock.Any(), gomock.Eq(returnRequest.ID)).
+					Times(1).
+					Return(items, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchReturnRequest(t, rsp.Body, returnRequest, items)
+			},
+		},
+		{
+			name:            "NotFound",
+			UserID:          user.ID,
+			ReturnRequestID: returnRequest.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetReturnRequestByUserID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					ListReturnRequestItemsByRequestID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "Unauthorized",
+			UserID:          user.ID,
+			ReturnRequestID: returnRequest.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetReturnRequestByUserID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "InternalError",
+			UserID:          user.ID,
+			ReturnRequestID: returnRequest.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetReturnRequestByUserID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(returnRequest, nil)
+
+				store.EXPECT().
+					ListReturnRequestItemsByRequestID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "InvalidID",
+			UserID:          user.ID,
+			ReturnRequestID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetReturnRequestByUserID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/returns/%d", tc.UserID, tc.ReturnRequestID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListReturnRequestsAPI(t *testing.T) {
+	user, _ := randomSOUser(t)
+	n := 5
+	returnRequests := make([]*db.ReturnRequest, n)
+	for i := 0; i < n; i++ {
+		returnRequests[i] = randomReturnRequest(user.ID)
+	}
+
+	type Query struct {
+		pageID   int
+		pageSize int
+	}
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		query         Query
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			UserID: user.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListReturnRequestsByUserIDParams{
+					UserID: user.ID,
+					Limit:  int32(n),
+					Offset: 0,
+				}
+
+				store.EXPECT().
+					ListReturnRequestsByUserID(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(returnRequests, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchReturnRequests(t, rsp.Body, returnRequests)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListReturnRequestsByUserID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "InternalError",
+			UserID: user.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListReturnRequestsByUserID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "InvalidPageSize",
+			UserID: user.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: 100000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListReturnRequestsByUserID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/returns", tc.UserID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListReturnRequestsForAdminAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	n := 5
+	returnRequests := make([]*db.ReturnRequest, n)
+	for i := 0; i < n; i++ {
+		returnRequests[i] = randomReturnRequest(util.RandomMoney())
+	}
+
+	type Query struct {
+		pageID   int
+		pageSize int
+		status   string
+	}
+
+	testCases := []struct {
+		name          string
+		AdminID       int64
+		query         Query
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminListReturnRequestsParams{
+					AdminID: admin.ID,
+					Limit:   int32(n),
+					Offset:  0,
+				}
+
+				store.EXPECT().
+					AdminListReturnRequests(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(returnRequests, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchReturnRequests(t, rsp.Body, returnRequests)
+			},
+		},
+		{
+			name:    "OKWithStatus",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+				status:   db.ReturnStatusRequested,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdminListReturnRequestsParams{
+					AdminID: admin.ID,
+					Status:  null.StringFrom(db.ReturnStatusRequested),
+					Limit:   int32(n),
+					Offset:  0,
+				}
+
+				store.EXPECT().
+					AdminListReturnRequests(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(returnRequests, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidStatus",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+				status:   "lost",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminListReturnRequests(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Forbidden",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionOrdersRead})).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					AdminListReturnRequests(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InternalError",
+			AdminID: admin.ID,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminListReturnRequests(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/returns", tc.AdminID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			if len(tc.query.status) != 0 {
+				q.Add("status", tc.query.status)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestUpdateReturnRequestAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+	returnRequest := randomReturnRequest(util.RandomMoney())
+	adminNote := util.RandomString(10)
+
+	refundedRequest := *returnRequest
+	refundedRequest.Status = db.ReturnStatusRefunded
+	refundedRequest.RefundAmount = null.StringFrom(util.RandomDecimalString(1, 100))
+
+	capturedPayment := &db.PaymentTransaction{
+		ID:                   util.RandomMoney(),
+		ShopOrderID:          returnRequest.ShopOrderID,
+		Provider:             payment.ProviderCOD,
+		ProviderReference:    null.StringFrom(util.RandomString(10)),
+		Status:               db.PaymentStatusCaptured,
+		Amount:               util.RandomDecimalString(100, 200),
+		RefundedAmount:       "0",
+		RefundInFlightAmount: "0",
+		//? the return request recorded its refund
+		PendingRefundAmount: refundedRequest.RefundAmount.String,
+	}
+
+	testCases := []struct {
+		name            string
+		AdminID         int64
+		ReturnRequestID int64
+		body            fiber.Map
+		setupAuth       func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs      func(store *mockdb.MockStore)
+		checkResponse   func(rsp *http.Response)
+	}{
+		{
+			name:            "OK",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status":     db.ReturnStatusApproved,
+				"admin_note": adminNote,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateReturnRequestTxParams{
+					AdminID:         admin.ID,
+					ReturnRequestID: returnRequest.ID,
+					Status:          db.ReturnStatusApproved,
+					AdminNote:       null.StringFrom(adminNote),
+				}
+
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.UpdateReturnRequestTxResult{ReturnRequest: returnRequest}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "RefundedRefundsPayment",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status": db.ReturnStatusRefunded,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.UpdateReturnRequestTxResult{ReturnRequest: &refundedRequest}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(returnRequest.ShopOrderID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{capturedPayment}, nil)
+
+				expectRefundPaymentTransaction(store, capturedPayment)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "InvalidStatusTransition",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status": db.ReturnStatusRefunded,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.InvalidReturnStatusTransitionError{
+						From: db.ReturnStatusRequested,
+						To:   db.ReturnStatusRefunded,
+					})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "NotFound",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status": db.ReturnStatusApproved,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "InvalidStatus",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status": db.ReturnStatusRequested,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "Unauthorized",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status": db.ReturnStatusApproved,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, false, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "InternalError",
+			AdminID:         admin.ID,
+			ReturnRequestID: returnRequest.ID,
+			body: fiber.Map{
+				"status": db.ReturnStatusApproved,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateReturnRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/returns/%d", tc.AdminID, tc.ReturnRequestID)
+			request, err := http.NewRequest(fiber.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func randomReturnRequest(userID int64) *db.ReturnRequest {
+	return &db.ReturnRequest{
+		ID:          util.RandomMoney(),
+		UserID:      userID,
+		ShopOrderID: util.RandomMoney(),
+		Status:      db.ReturnStatusRequested,
+		Reason:      util.RandomString(20),
+	}
+}
+
+func randomReturnRequestItem(returnRequestID int64) *db.ReturnRequestItem {
+	return &db.ReturnRequestItem{
+		ID:              util.RandomMoney(),
+		ReturnRequestID: returnRequestID,
+		ShopOrderItemID: util.RandomMoney(),
+		Qty:             int32(util.RandomInt(1, 5)),
+	}
+}
+
+func requireBodyMatchCreateReturnRequest(t *testing.T, body io.ReadCloser, returnRequest *db.ReturnRequest, item *db.ReturnRequestItem) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotResult db.CreateReturnRequestTxResult
+	err = json.Unmarshal(data, &gotResult)
+	require.NoError(t, err)
+
+	require.Equal(t, returnRequest.ID, gotResult.ReturnRequest.ID)
+	require.Equal(t, returnRequest.Status, gotResult.ReturnRequest.Status)
+	require.Len(t, gotResult.Items, 1)
+	require.Equal(t, item.ShopOrderItemID, gotResult.Items[0].ShopOrderItemID)
+	require.Equal(t, item.Qty, gotResult.Items[0].Qty)
+}
+
+func requireBodyMatchReturnRequest(t *testing.T, body io.ReadCloser, returnRequest *db.ReturnRequest, items []*db.ListReturnRequestItemsByRequestIDRow) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotResponse returnRequestResponse
+	err = json.Unmarshal(data, &gotResponse)
+	require.NoError(t, err)
+
+	require.Equal(t, returnRequest.ID, gotResponse.ReturnRequest.ID)
+	require.Equal(t, returnRequest.Reason, gotResponse.ReturnRequest.Reason)
+	require.Len(t, gotResponse.Items, len(items))
+	for i, item := range gotResponse.Items {
+		require.Equal(t, items[i].ShopOrderItemID, item.ShopOrderItemID)
+		require.Equal(t, items[i].ProductName, item.ProductName)
+	}
+}
+
+func requireBodyMatchReturnRequests(t *testing.T, body io.ReadCloser, returnRequests []*db.ReturnRequest) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotReturnRequests []*db.ReturnRequest
+	err = json.Unmarshal(data, &gotReturnRequests)
+	require.NoError(t, err)
+
+	require.Len(t, gotReturnRequests, len(returnRequests))
+	for i, returnRequest := range gotReturnRequests {
+		require.Equal(t, returnRequests[i].ID, returnRequest.ID)
+		require.Equal(t, returnRequests[i].Status, returnRequest.Status)
+	}
+}
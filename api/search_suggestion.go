@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// suggestion kinds returned by ListSearchSuggestions
+const (
+	suggestionProduct  = "product"
+	suggestionBrand    = "brand"
+	suggestionCategory = "category"
+	suggestionQuery    = "query"
+)
+
+const (
+	// searchSuggestionsLimit is the number of suggestions of each kind
+	searchSuggestionsLimit = 5
+	// searchSuggestionsTimeout keeps the autocomplete responsive while the customer types
+	searchSuggestionsTimeout = 300 * time.Millisecond
+)
+
+type searchSuggestionResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type searchSuggestionsResponse struct {
+	Query      string                     `json:"query"`
+	Products   []searchSuggestionResponse `json:"products"`
+	Brands     []searchSuggestionResponse `json:"brands"`
+	Categories []searchSuggestionResponse `json:"categories"`
+	// Queries are popular past queries starting with the query
+	Queries []string `json:"queries"`
+	// DidYouMean is a corrected spelling of the query, it's only looked up when no product matches the query
+	DidYouMean null.String `json:"did_you_mean"`
+}
+
+func newSearchSuggestionsResponse(query string, rows []*db.ListSearchSuggestionsRow) searchSuggestionsResponse {
+	rsp := searchSuggestionsResponse{
+		Query:      query,
+		Products:   []searchSuggestionResponse{},
+		Brands:     []searchSuggestionResponse{},
+		Categories: []searchSuggestionResponse{},
+		Queries:    []string{},
+	}
+
+	for _, row := range rows {
+		suggestion := searchSuggestionResponse{ID: row.ValueID, Name: row.Value}
+
+		switch row.Kind {
+		case suggestionProduct:
+			rsp.Products = append(rsp.Products, suggestion)
+		case suggestionBrand:
+			rsp.Brands = append(rsp.Brands, suggestion)
+		case suggestionCategory:
+			rsp.Categories = append(rsp.Categories, suggestion)
+		case suggestionQuery:
+			rsp.Queries = append(rsp.Queries, row.Value)
+		}
+	}
+
+	return rsp
+}
+
+// logSearchQuery records a customer search for the suggestions and the search report, a failure is only logged
+func (server *Server) logSearchQuery(ctx fiber.Ctx, query string, resultCount int64) {
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+
+	err := server.store.CreateSearchQueryLog(ctx.Context(), db.CreateSearchQueryLogParams{
+		Query:       query,
+		ResultCount: resultCount,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to log the search query")
+	}
+}
+
+// //////////////* Search Suggestions API //////////////
+
+type searchSuggestionsQueryRequest struct {
+	Query string `query:"q" validate:"required,search_query,max=100"`
+}
+
+/*
+searchSuggestions autocompletes the query with product names, brands, categories and popular past queries,
+
+when no product matches the query the closest known spelling is returned in did_you_mean.
+The suggestions aren't logged as searches, only the searches the customer runs are.
+*/
+func (server *Server) searchSuggestions(ctx fiber.Ctx) error {
+	query := &searchSuggestionsQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	c, cancel := context.WithTimeout(ctx.Context(), searchSuggestionsTimeout)
+	defer cancel()
+
+	suggestions, err := server.store.ListSearchSuggestions(c, db.ListSearchSuggestionsParams{
+		Query:     query.Query,
+		KindLimit: searchSuggestionsLimit,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := newSearchSuggestionsResponse(query.Query, suggestions)
+
+	// the correction is a hint, the suggestions are returned without it when it fails
+	if len(rsp.Products) == 0 {
+		correction, err := server.store.GetSearchCorrection(c, query.Query)
+		if err == nil {
+			rsp.DidYouMean = null.StringFrom(correction)
+		} else if err != pgx.ErrNoRows {
+			log.Error().Err(err).Msg("failed to get the search correction")
+		}
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// //////////////* Search Queries Report API //////////////
+
+type listSearchQueryStatsParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+type listSearchQueryStatsQueryRequest struct {
+	// Days is how far back the queries are counted
+	Days        int32 `query:"days" validate:"omitempty,min=1,max=365"`
+	ZeroResults bool  `query:"zero_results"`
+	PageID      int32 `query:"page_id" validate:"required,min=1"`
+	PageSize    int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listSearchQueryStats shows the merchandisers what customers search for, with ?zero_results=true what they don't find
+func (server *Server) listSearchQueryStats(ctx fiber.Ctx) error {
+	params := &listSearchQueryStatsParamsRequest{}
+	query := &listSearchQueryStatsQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	days := query.Days
+	if days == 0 {
+		days = 30
+	}
+
+	arg := db.ListSearchQueryStatsParams{
+		Limit:       query.PageSize,
+		Offset:      (query.PageID - 1) * query.PageSize,
+		Since:       time.Now().AddDate(0, 0, -int(days)),
+		ZeroResults: query.ZeroResults,
+	}
+
+	stats, err := server.store.ListSearchQueryStats(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(stats)
+	return nil
+}
@@ -0,0 +1,359 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSearchSuggestionsAPI(t *testing.T) {
+	suggestions := []*db.ListSearchSuggestionsRow{
+		{Kind: suggestionBrand, ValueID: 3, Value: "zara", Rank: 1},
+		{Kind: suggestionCategory, ValueID: 7, Value: "dresses", Rank: 0.5},
+		{Kind: suggestionProduct, ValueID: 12, Value: "zara summer dress", Rank: 0.9},
+		{Kind: suggestionQuery, Value: "zara dress", Rank: 14},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:  "OK",
+			query: url.Values{"q": {"zara"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListSearchSuggestionsParams{
+					Query:     "zara",
+					KindLimit: searchSuggestionsLimit,
+				}
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(suggestions, nil)
+
+				// a product matches so there's nothing to correct
+				store.EXPECT().
+					GetSearchCorrection(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateSearchQueryLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotRsp searchSuggestionsResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, "zara", gotRsp.Query)
+				require.Equal(t, []searchSuggestionResponse{{ID: 12, Name: "zara summer dress"}}, gotRsp.Products)
+				require.Equal(t, []searchSuggestionResponse{{ID: 3, Name: "zara"}}, gotRsp.Brands)
+				require.Equal(t, []searchSuggestionResponse{{ID: 7, Name: "dresses"}}, gotRsp.Categories)
+				require.Equal(t, []string{"zara dress"}, gotRsp.Queries)
+				require.False(t, gotRsp.DidYouMean.Valid)
+			},
+		},
+		{
+			name:  "DidYouMean",
+			query: url.Values{"q": {"zraa"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*db.ListSearchSuggestionsRow{}, nil)
+
+				store.EXPECT().
+					GetSearchCorrection(gomock.Any(), gomock.Eq("zraa")).
+					Times(1).
+					Return("zara", nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotRsp searchSuggestionsResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotRsp)
+				require.NoError(t, err)
+
+				require.Empty(t, gotRsp.Products)
+				require.NotNil(t, gotRsp.Queries)
+				require.Equal(t, "zara", gotRsp.DidYouMean.String)
+			},
+		},
+		{
+			name:  "NoCorrection",
+			query: url.Values{"q": {"qwxz"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*db.ListSearchSuggestionsRow{}, nil)
+
+				store.EXPECT().
+					GetSearchCorrection(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return("", pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotRsp searchSuggestionsResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotRsp)
+				require.NoError(t, err)
+				require.False(t, gotRsp.DidYouMean.Valid)
+			},
+		},
+		{
+			name:  "CorrectionError",
+			query: url.Values{"q": {"qwxz"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*db.ListSearchSuggestionsRow{}, nil)
+
+				store.EXPECT().
+					GetSearchCorrection(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return("", pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				// the suggestions are still returned
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: url.Values{"q": {"zara"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+
+				store.EXPECT().
+					GetSearchCorrection(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:  "MissingQuery",
+			query: url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:  "InvalidQuery",
+			query: url.Values{"q": {"zara%"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchSuggestions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			request, err := http.NewRequest(fiber.MethodGet, "/api/v1/search-suggestions?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListSearchQueryStatsAPI(t *testing.T) {
+	admin, _ := randomSuperAdmin(t)
+
+	stats := []*db.ListSearchQueryStatsRow{
+		{Query: "linen shirt", SearchCount: 9, ZeroResultCount: 9, LastSearchedAt: time.Now().UTC().Truncate(time.Second)},
+	}
+
+	testCases := []struct {
+		name          string
+		adminID       int64
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			adminID: admin.ID,
+			query:   url.Values{"zero_results": {"true"}, "days": {"7"}, "page_id": {"2"}, "page_size": {"10"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchQueryStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ListSearchQueryStatsParams) ([]*db.ListSearchQueryStatsRow, error) {
+						require.Equal(t, int32(10), arg.Limit)
+						require.Equal(t, int32(10), arg.Offset)
+						require.True(t, arg.ZeroResults)
+						require.WithinDuration(t, time.Now().AddDate(0, 0, -7), arg.Since, time.Minute)
+						return stats, nil
+					})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotStats []*db.ListSearchQueryStatsRow
+				err := json.NewDecoder(rsp.Body).Decode(&gotStats)
+				require.NoError(t, err)
+				require.Len(t, gotStats, 1)
+				require.Equal(t, stats[0].Query, gotStats[0].Query)
+				require.Equal(t, stats[0].ZeroResultCount, gotStats[0].ZeroResultCount)
+			},
+		},
+		{
+			name:    "DefaultDays",
+			adminID: admin.ID,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchQueryStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ListSearchQueryStatsParams) ([]*db.ListSearchQueryStatsRow, error) {
+						require.False(t, arg.ZeroResults)
+						require.WithinDuration(t, time.Now().AddDate(0, 0, -30), arg.Since, time.Minute)
+						return []*db.ListSearchQueryStatsRow{}, nil
+					})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Unauthorized",
+			adminID: admin.ID,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchQueryStats(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "AnotherAdmin",
+			adminID: admin.ID + 1,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchQueryStats(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InternalError",
+			adminID: admin.ID,
+			query:   url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchQueryStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidDays",
+			adminID: admin.ID,
+			query:   url.Values{"days": {"400"}, "page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSearchQueryStats(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/search-queries?%s", tc.adminID, tc.query.Encode())
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
@@ -12,12 +12,15 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	image "github.com/cshop/v3/image"
 	"github.com/cshop/v3/mail"
+	"github.com/cshop/v3/payment"
+	"github.com/cshop/v3/throttle"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	"github.com/cshop/v3/worker"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/etag"
+	"github.com/redis/go-redis/v9"
 )
 
 type Server struct {
@@ -25,12 +28,30 @@ type Server struct {
 	store           db.Store
 	validate        *validator.Validate
 	fb              *firebase.App
+	firebaseAuth    FirebaseTokenVerifier
 	userTokenMaker  token.Maker
 	adminTokenMaker token.Maker
 	router          *fiber.App
 	taskDistributor worker.TaskDistributor
 	ik              image.ImageKitManagement
 	sender          mail.EmailSender
+	payments        payment.Providers
+	// accountLimiter and ipLimiter count the failed logins and otp guesses
+	accountLimiter throttle.Limiter
+	ipLimiter      throttle.Limiter
+}
+
+// newTokenMaker signs with the v4.public keyring when it's configured and falls back to the symmetric key
+func newTokenMaker(keys string, symmetricKey string) (token.Maker, error) {
+	if keys == "" {
+		return token.NewPasetoMaker(symmetricKey)
+	}
+
+	keyring, err := token.ParseKeyring(keys)
+	if err != nil {
+		return nil, err
+	}
+	return token.NewPasetoPublicMaker(keyring)
 }
 
 // NewServer creates a new HTTP server and setup routing.
@@ -41,12 +62,13 @@ func NewServer(
 	taskDistributor worker.TaskDistributor,
 	ik image.ImageKitManagement,
 	sender mail.EmailSender,
+	payments payment.Providers,
 ) (*Server, error) {
-	userTokenMaker, err := token.NewPasetoMaker(config.UserTokenSymmetricKey)
+	userTokenMaker, err := newTokenMaker(config.UserTokenKeys, config.UserTokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
-	adminTokenMaker, err := token.NewPasetoMaker(config.AdminTokenSymmetricKey)
+	adminTokenMaker, err := newTokenMaker(config.AdminTokenKeys, config.AdminTokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
@@ -54,17 +76,29 @@ func NewServer(
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	validate.RegisterValidation("alphanumunicode_space", IsAlphanumUnicodeWithSpace)
 	validate.RegisterValidation("custom_phone_number", validatePhoneNumber)
+	validate.RegisterValidation("admin_permission", validateAdminPermission)
+	validate.RegisterValidation("search_query", validateSearchQuery)
+
+	// without redis the failed attempts are only counted in this process
+	var redisClient redis.UniversalClient
+	if config.RedisAddress != "" {
+		redisClient = redis.NewClient(&redis.Options{Addr: config.RedisAddress})
+	}
 
 	server := &Server{
 		config:          config,
 		store:           store,
 		validate:        validate,
 		fb:              fb,
+		firebaseAuth:    newFirebaseTokenVerifier(fb),
 		userTokenMaker:  userTokenMaker,
 		adminTokenMaker: adminTokenMaker,
 		taskDistributor: taskDistributor,
 		ik:              ik,
 		sender:          sender,
+		payments:        payments,
+		accountLimiter:  throttle.New(redisClient, "throttle:account", throttle.AccountPolicy),
+		ipLimiter:       throttle.New(redisClient, "throttle:ip", throttle.IPPolicy),
 	}
 
 	server.setupRouter()
@@ -72,12 +106,25 @@ func NewServer(
 	return server, nil
 }
 
+// headerRealIP carries the client ip, the reverse proxies of the deployment overwrite it with the peer address
+const headerRealIP = "X-Real-IP"
+
 func (server *Server) setupRouter() {
 	app := fiber.New(
 		fiber.Config{
 			AppName:     "CShop",
 			JSONEncoder: sonic.ConfigFastest.Marshal,
 			JSONDecoder: sonic.ConfigFastest.Unmarshal,
+			// the reverse proxy sets X-Real-IP to the client, ctx.IP() only reads it from a trusted proxy
+			// so the throttle counts every client apart and a direct request can't pick its ip
+			ProxyHeader: headerRealIP,
+			TrustProxy:  true,
+			TrustProxyConfig: fiber.TrustProxyConfig{
+				Proxies:  server.config.TrustedProxies,
+				Loopback: len(server.config.TrustedProxies) == 0,
+				Private:  len(server.config.TrustedProxies) == 0,
+			},
+			EnableIPValidation: true,
 			// DisableStartupMessage: true,
 		},
 	)
@@ -108,6 +155,7 @@ func (server *Server) setupRouter() {
 	//* Users
 	app.Post("/api/v1/users", server.createUser)
 	app.Post("/api/v1/users/login", server.loginUser)
+	app.Post("/api/v1/users/login/firebase", server.loginUserWithFirebase)
 
 	app.Post("/api/v1/users/signup", server.signUp)
 	app.Post("/api/v1/users/verify-otp", server.verifyOTP)
@@ -120,16 +168,22 @@ func (server *Server) setupRouter() {
 	app.Put("/api/v1/users/reset-password-approved", server.resetPasswordApproved)
 
 	//* Admins
-	app.Post("/api/v1/admins/login", server.loginAdmin) //! For Admin Only
+	app.Post("/api/v1/admins/login", server.loginAdmin)                         //! For Admin Only
+	app.Post("/api/v1/admins/login/2fa", server.verifyAdminLoginChallenge)      //! For Admin Only
+	app.Post("/api/v1/admins/login/2fa/setup", server.setupAdminLoginTwoFactor) //! For Admin Only
 
 	//* Tokens
 	app.Post("/api/v1/auth/access-token", server.renewAccessToken)
 	app.Post("/api/v1/auth/refresh-token", server.renewRefreshToken)
+	app.Get("/api/v1/auth/user-token-keys", server.listUserTokenKeys) //? no auth required
 
 	//* Tokens for Admins
 	app.Post("/api/v1/auth/access-token-for-admin", server.renewAccessTokenForAdmin)   //! For Admin Only
 	app.Post("/api/v1/auth/refresh-token-for-admin", server.renewRefreshTokenForAdmin) //! For Admin Only
 
+	//* Payments
+	app.Post("/api/v1/payments/:provider/webhook", server.paymentWebhook) //? signed by the payment provider
+
 	//*HomePageTextBanner
 	app.Get("/api/v1/text-banners/:textBannerId", server.getHomePageTextBanner) //? no auth required
 	app.Get("/api/v1/text-banners", server.listHomePageTextBanners)             //? no auth required
@@ -138,6 +192,7 @@ func (server *Server) setupRouter() {
 
 	//*Products
 	app.Get("/api/v1/products/:productId", server.getProduct)                   //? no auth required
+	app.Get("/api/v1/products/:productId/reviews", server.listProductReviews)   //? no auth required
 	app.Get("/api/v1/products", server.listProducts)                            //? no auth required
 	app.Get("/api/v1/products-v2", server.listProductsV2)                       //? no auth required                                                       //? no auth required
 	app.Get("/api/v1/products-next-page", server.listProductsNextPage)          //? no auth required
@@ -149,6 +204,7 @@ func (server *Server) setupRouter() {
 	app.Get("/api/v1/promotions", server.listPromotions)            //? no auth required
 
 	//* Product-Categories
+	app.Get("/api/v1/categories/tree", server.getProductCategoryTree)    //? no auth required
 	app.Get("/api/v1/categories/:categoryId", server.getProductCategory) //? no auth required
 	app.Get("/api/v1/categories", server.listProductCategories)          //? no auth required
 
@@ -196,6 +252,8 @@ func (server *Server) setupRouter() {
 	app.Get("/api/v1/product-items-next-page", server.listProductItemsNextPage)                                                //? no auth required
 	app.Get("/api/v1/search-product-items", server.searchProductItems)                                                         //? no auth required
 	app.Get("/api/v1/search-product-items-next-page", server.searchProductItemsNextPage)                                       //? no auth required
+	app.Get("/api/v1/search-product-items-faceted", server.searchProductItemsFaceted)                                          //? no auth required
+	app.Get("/api/v1/search-suggestions", server.searchSuggestions)                                                            //? no auth required
 	app.Get("/api/v1/product-items-with-promotions", server.listProductItemsWithPromotions)                                    //? no auth required
 	app.Get("/api/v1/product-items-with-promotions-next-page", server.listProductItemsWithPromotionsNextPage)                  //? no auth required
 	app.Get("/api/v1/product-items-with-brand-promotions", server.listProductItemsWithBrandPromotions)                         //? no auth required
@@ -208,8 +266,8 @@ func (server *Server) setupRouter() {
 	app.Get("/api/v1/product-configurations/:itemId/variation-options/:variationId", server.getProductConfiguration) //? no auth required
 	app.Get("/api/v1/product-configurations/:itemId", server.listProductConfigurations)                              //? no auth required
 
-	userRouter := app.Group("/usr/v1").Use(authMiddleware(server.userTokenMaker, false))
-	adminRouter := app.Group("/admin/v1").Use(authMiddleware(server.adminTokenMaker, true)) //! For Admin Only
+	userRouter := app.Group("/usr/v1").Use(authMiddleware(server.store, server.userTokenMaker, false))
+	adminRouter := app.Group("/admin/v1").Use(authMiddleware(server.store, server.adminTokenMaker, true)) //! For Admin Only
 
 	userRouter.Get("/users/:id", server.getUser)
 
@@ -218,33 +276,67 @@ func (server *Server) setupRouter() {
 	// 		FirebaseApp: fireApp,
 	// 	}))
 
-	adminRouter.Post("/admins/:adminId/product-images", server.createProductImages)    //! Admin Only
-	adminRouter.Get("/admins/:adminId/product-images/kit", server.listproductImages)   //! Admin Only
-	adminRouter.Put("/admins/:adminId/product-images/:id", server.updateProductImages) //! Admin Only
+	adminRouter.Post("/admins/:adminId/product-images", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductImages)    //! Admin Only
+	adminRouter.Get("/admins/:adminId/product-images/kit", permissionMiddleware(server.store, db.PermissionCatalogManage), server.listproductImages)   //! Admin Only
+	adminRouter.Put("/admins/:adminId/product-images/:id", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductImages) //! Admin Only
 
 	//* dashboard
-	adminRouter.Get("/admins/:adminId/dashboard", server.getDashboardInfo) //! Admin Only
+	adminRouter.Get("/admins/:adminId/dashboard", permissionMiddleware(server.store, db.PermissionDashboardRead), server.getDashboardInfo)          //! Admin Only
+	adminRouter.Get("/admins/:adminId/search-queries", permissionMiddleware(server.store, db.PermissionDashboardRead), server.listSearchQueryStats) //! Admin Only
 
 	userRouter.Post("/users/:id/notification", server.createNotification)
 	userRouter.Get("/users/:id/notification/:deviceId", server.getNotification)
 	userRouter.Put("/users/:id/notification/:deviceId", server.updateNotification)
 	userRouter.Delete("/users/:id/notification/:deviceId", server.deleteNotification)
 
-	adminRouter.Post("/admins/:adminId/app-policy", server.createAppPolicy)       //! Admin Only
-	adminRouter.Put("/admins/:adminId/app-policy/:id", server.updateAppPolicy)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/app-policy/:id", server.deleteAppPolicy) //! Admin Only
+	adminRouter.Post("/admins/:adminId/app-policy", permissionMiddleware(server.store, db.PermissionSettingsManage), server.createAppPolicy)       //! Admin Only
+	adminRouter.Put("/admins/:adminId/app-policy/:id", permissionMiddleware(server.store, db.PermissionSettingsManage), server.updateAppPolicy)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/app-policy/:id", permissionMiddleware(server.store, db.PermissionSettingsManage), server.deleteAppPolicy) //! Admin Only
 
-	adminRouter.Get("/admins/:adminId/users", server.listUsers)                                //! Admin Only
-	adminRouter.Get("/admins/:adminId/search-user-by-email", server.searchUserByEmailForAdmin) //! Admin Only
+	adminRouter.Get("/admins/:adminId/users", permissionMiddleware(server.store, db.PermissionUsersRead), server.listUsers)                                //! Admin Only
+	adminRouter.Get("/admins/:adminId/search-user-by-email", permissionMiddleware(server.store, db.PermissionUsersRead), server.searchUserByEmailForAdmin) //! Admin Only
 	userRouter.Put("/users/:id", server.updateUser)
-	adminRouter.Put("/admins/:adminId/users/:id", server.adminUpdateUser) //! Admin Only
+	adminRouter.Put("/admins/:adminId/users/:id", permissionMiddleware(server.store, db.PermissionUsersManage), server.adminUpdateUser) //! Admin Only
 	userRouter.Put("/users/:id/change-password", server.changePassword)
-	adminRouter.Delete("/admins/:adminId/users/:id", server.deleteUser) //! Admin Only
+	userRouter.Post("/users/:id/email-change", server.requestEmailChange)
+	userRouter.Post("/users/:id/email-change/confirm", server.confirmEmailChange)
+	adminRouter.Delete("/admins/:adminId/users/:id", permissionMiddleware(server.store, db.PermissionUsersDelete), server.deleteUser) //! Admin Only
 	userRouter.Delete("/users/:id/logout", server.logoutUser)
+	userRouter.Delete("/users/:id", server.requestUserDeletion)
+	userRouter.Delete("/users/:id/deletion", server.cancelUserDeletion)
+	userRouter.Get("/users/:id/export", server.exportUserData)
 
 	adminRouter.Delete("/admins/:id/logout", server.logoutAdmin) //! Admin Only
 
-	userRouter.Post("/users/:id/addresses", server.createUserAddress)
+	//? Sessions
+	userRouter.Get("/users/:id/sessions", server.listUserSessions)
+	userRouter.Delete("/users/:id/sessions", server.revokeUserSessions)
+	userRouter.Delete("/users/:id/sessions/:sessionId", server.revokeUserSession)
+
+	adminRouter.Get("/admins/:adminId/sessions", server.listAdminSessions)                //! Admin Only
+	adminRouter.Delete("/admins/:adminId/sessions", server.revokeAdminSessions)           //! Admin Only
+	adminRouter.Delete("/admins/:adminId/sessions/:sessionId", server.revokeAdminSession) //! Admin Only
+
+	//? Two-Factor Authentication
+	adminRouter.Get("/admins/:adminId/2fa", server.getAdminTwoFactor)                                                                                         //! Admin Only
+	adminRouter.Post("/admins/:adminId/2fa/setup", server.setupAdminTwoFactor)                                                                                //! Admin Only
+	adminRouter.Post("/admins/:adminId/2fa/confirm", server.confirmAdminTwoFactor)                                                                            //! Admin Only
+	adminRouter.Post("/admins/:adminId/2fa/recovery-codes", server.regenerateAdminRecoveryCodes)                                                              //! Admin Only
+	adminRouter.Delete("/admins/:adminId/2fa", server.disableAdminTwoFactor)                                                                                  //! Admin Only
+	adminRouter.Get("/admins/:adminId/security-settings", permissionMiddleware(server.store, db.PermissionSecurityManage), server.getAdminSecuritySetting)    //! Admin Only
+	adminRouter.Put("/admins/:adminId/security-settings", permissionMiddleware(server.store, db.PermissionSecurityManage), server.updateAdminSecuritySetting) //! Admin Only
+
+	//? Admin Roles
+	adminRouter.Get("/admins/:adminId/permissions", permissionMiddleware(server.store, db.PermissionRolesManage), server.listAdminPermissions)  //! Admin Only
+	adminRouter.Post("/admins/:adminId/roles", permissionMiddleware(server.store, db.PermissionRolesManage), server.createAdminRole)            //! Admin Only
+	adminRouter.Get("/admins/:adminId/roles", permissionMiddleware(server.store, db.PermissionRolesManage), server.listAdminRoles)              //! Admin Only
+	adminRouter.Get("/admins/:adminId/roles/:roleId", permissionMiddleware(server.store, db.PermissionRolesManage), server.getAdminRole)        //! Admin Only
+	adminRouter.Put("/admins/:adminId/roles/:roleId", permissionMiddleware(server.store, db.PermissionRolesManage), server.updateAdminRole)     //! Admin Only
+	adminRouter.Delete("/admins/:adminId/roles/:roleId", permissionMiddleware(server.store, db.PermissionRolesManage), server.deleteAdminRole)  //! Admin Only
+	adminRouter.Get("/admins/:adminId/admins/:id/roles", permissionMiddleware(server.store, db.PermissionRolesManage), server.listRolesOfAdmin) //! Admin Only
+	adminRouter.Put("/admins/:adminId/admins/:id/roles", permissionMiddleware(server.store, db.PermissionRolesManage), server.setAdminRoles)    //! Admin Only
+
+	userRouter.Post("/users/:id/addresses", idempotencyMiddleware(server.store), server.createUserAddress)
 	userRouter.Get("/users/:id/addresses/:addressId", server.getUserAddress)
 	userRouter.Get("/users/:id/addresses", server.listUserAddresses)
 	userRouter.Put("/users/:id/addresses/:addressId", server.updateUserAddress)
@@ -255,15 +347,18 @@ func (server *Server) setupRouter() {
 	userRouter.Get("/users/:id/reviews", server.listUserReviews)
 	userRouter.Put("/users/:id/reviews/:reviewId", server.updateUserReview)
 	userRouter.Delete("/users/:id/reviews/:reviewId", server.deleteUserReview)
+	userRouter.Post("/users/:id/reviews/:reviewId/images", server.uploadUserReviewImage)
 
 	//? /items is shoppingCartItems ID in the Table
-	userRouter.Post("/users/:id/carts/:cartId/items", server.createShoppingCartItem)
+	userRouter.Post("/users/:id/carts/:cartId/items", idempotencyMiddleware(server.store), server.createShoppingCartItem)
 	userRouter.Get("/users/:id/carts/:cartId/items", server.getShoppingCartItem)
 	userRouter.Get("/users/:id/carts/items", server.listShoppingCartItems)
 	userRouter.Put("/users/:id/carts/:cartId/items/:itemId", server.updateShoppingCartItem)
 	userRouter.Delete("/users/:id/carts/:cartId/items/:itemId", server.deleteShoppingCartItem)
 	userRouter.Delete("/users/:id/carts/:cartId", server.deleteShoppingCartItemAllByUser)
-	userRouter.Put("/users/:id/carts/:cartId/purchase", server.finishPurchase)
+	userRouter.Get("/users/:id/carts/:cartId/quote", server.quoteShoppingCart)
+	userRouter.Put("/users/:id/carts/:cartId/reserve", server.reserveShoppingCart)
+	userRouter.Put("/users/:id/carts/:cartId/purchase", idempotencyMiddleware(server.store), server.finishPurchase)
 
 	//? /items is WishListItems ID in the Table
 	userRouter.Post("/users/:id/wish-lists/:wishId/items", server.createWishListItem)
@@ -279,98 +374,118 @@ func (server *Server) setupRouter() {
 	userRouter.Put("/users/:id/payment-methods/:paymentId", server.updatePaymentMethod)
 	userRouter.Delete("/users/:id/payment-methods/:paymentId", server.deletePaymentMethod)
 
-	adminRouter.Post("/admins/:adminId/payment-types", server.createPaymentType)               //! Admin Only
-	adminRouter.Get("/admins/:adminId/payment-types", server.adminListPaymentTypes)            //! Admin Only
-	adminRouter.Put("/admins/:adminId/payment-types/:paymentTypeId", server.updatePaymentType) //! Admin Only
+	adminRouter.Post("/admins/:adminId/payment-types", permissionMiddleware(server.store, db.PermissionSettingsManage), server.createPaymentType)               //! Admin Only
+	adminRouter.Get("/admins/:adminId/payment-types", permissionMiddleware(server.store, db.PermissionSettingsManage), server.adminListPaymentTypes)            //! Admin Only
+	adminRouter.Put("/admins/:adminId/payment-types/:paymentTypeId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.updatePaymentType) //! Admin Only
 	// adminRouter.Delete("/admins/:adminId/payment-types/:paymentTypeId", server.deletePaymentType) //! Admin Only
 	userRouter.Get("/users/:id/payment-types", server.listPaymentTypes)
 
-	adminRouter.Post("/admins/:adminId/text-banners", server.createHomePageTextBanner)                 //! Admin Only
-	adminRouter.Put("/admins/:adminId/text-banners/:textBannerId", server.updateHomePageTextBanner)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/text-banners/:textBannerId", server.deleteHomePageTextBanner) //! Admin Only
+	adminRouter.Post("/admins/:adminId/text-banners", permissionMiddleware(server.store, db.PermissionSettingsManage), server.createHomePageTextBanner)                 //! Admin Only
+	adminRouter.Put("/admins/:adminId/text-banners/:textBannerId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.updateHomePageTextBanner)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/text-banners/:textBannerId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.deleteHomePageTextBanner) //! Admin Only
+
+	adminRouter.Post("/admins/:adminId/products", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProduct)              //! Admin Only
+	adminRouter.Put("/admins/:adminId/products/:productId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProduct)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/products/:productId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteProduct) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/products", server.createProduct)              //! Admin Only
-	adminRouter.Put("/admins/:adminId/products/:productId", server.updateProduct)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/products/:productId", server.deleteProduct) //! Admin Only
+	adminRouter.Post("/admins/:adminId/promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.createPromotion)                //! Admin Only
+	adminRouter.Put("/admins/:adminId/promotions/:promotionId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.updatePromotion)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/promotions/:promotionId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.deletePromotion) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/promotions", server.createPromotion)                //! Admin Only
-	adminRouter.Put("/admins/:adminId/promotions/:promotionId", server.updatePromotion)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/promotions/:promotionId", server.deletePromotion) //! Admin Only
+	adminRouter.Post("/admins/:adminId/coupons", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.createCoupon)             //! Admin Only
+	adminRouter.Get("/admins/:adminId/coupons", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.listCoupons)               //! Admin Only
+	adminRouter.Get("/admins/:adminId/coupons/:couponId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.getCoupon)       //! Admin Only
+	adminRouter.Put("/admins/:adminId/coupons/:couponId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.updateCoupon)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/coupons/:couponId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.deleteCoupon) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/categories", server.createProductCategory)               //! Admin Only
-	adminRouter.Put("/admins/:adminId/categories/:categoryId", server.updateProductCategory)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/categories/:categoryId", server.deleteProductCategory) //! Admin Only
+	adminRouter.Post("/admins/:adminId/categories", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductCategory)                 //! Admin Only
+	adminRouter.Put("/admins/:adminId/categories/:categoryId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductCategory)      //! Admin Only
+	adminRouter.Delete("/admins/:adminId/categories/:categoryId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteProductCategory)   //! Admin Only
+	adminRouter.Put("/admins/:adminId/categories/:categoryId/parent", permissionMiddleware(server.store, db.PermissionCatalogManage), server.moveProductCategory) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/colors", server.createProductColor)    //! Admin Only
-	adminRouter.Put("/admins/:adminId/colors/:id", server.updateProductColor) //! Admin Only
+	adminRouter.Get("/admins/:adminId/reviews", permissionMiddleware(server.store, db.PermissionCatalogManage), server.listUserReviewsForModeration) //! Admin Only
+	adminRouter.Put("/admins/:adminId/reviews/:reviewId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.moderateUserReview) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/sizes", server.createProductSize)    //! Admin Only
-	adminRouter.Put("/admins/:adminId/sizes/:id", server.updateProductSize) //! Admin Only
+	adminRouter.Post("/admins/:adminId/colors", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductColor)    //! Admin Only
+	adminRouter.Put("/admins/:adminId/colors/:id", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductColor) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/brands", server.createProductBrand)            //! Admin Only
-	adminRouter.Put("/admins/:adminId/brands/:brandId", server.updateProductBrand)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/brands/:brandId", server.deleteProductBrand) //! Admin Only
+	adminRouter.Post("/admins/:adminId/sizes", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductSize)    //! Admin Only
+	adminRouter.Put("/admins/:adminId/sizes/:id", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductSize) //! Admin Only
 
-	adminRouter.Get("/admins/:adminId/product-promotions", server.listProductPromotionsForAdmins)                             //! Admin Only
-	adminRouter.Post("/admins/:adminId/product-promotions", server.createProductPromotion)                                    //! Admin Only
-	adminRouter.Put("/admins/:adminId/product-promotions/:promotionId/products/:productId", server.updateProductPromotion)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/product-promotions/:promotionId/products/:productId", server.deleteProductPromotion) //! Admin Only
+	adminRouter.Post("/admins/:adminId/brands", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductBrand)            //! Admin Only
+	adminRouter.Put("/admins/:adminId/brands/:brandId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductBrand)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/brands/:brandId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteProductBrand) //! Admin Only
 
-	adminRouter.Get("/admins/:adminId/category-promotions", server.listCategoryPromotionsForAdmins)                                //! Admin Only
-	adminRouter.Post("/admins/:adminId/category-promotions", server.createCategoryPromotion)                                       //! Admin Only
-	adminRouter.Put("/admins/:adminId/category-promotions/:promotionId/categories/:categoryId", server.updateCategoryPromotion)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/category-promotions/:promotionId/categories/:categoryId", server.deleteCategoryPromotion) //! Admin Only
+	adminRouter.Get("/admins/:adminId/product-promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.listProductPromotionsForAdmins)                             //! Admin Only
+	adminRouter.Post("/admins/:adminId/product-promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.createProductPromotion)                                    //! Admin Only
+	adminRouter.Put("/admins/:adminId/product-promotions/:promotionId/products/:productId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.updateProductPromotion)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/product-promotions/:promotionId/products/:productId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.deleteProductPromotion) //! Admin Only
 
-	adminRouter.Get("/admins/:adminId/brand-promotions", server.listBrandPromotionsForAdmins)                         //! Admin Only
-	adminRouter.Post("/admins/:adminId/brand-promotions", server.createBrandPromotion)                                //! Admin Only
-	adminRouter.Put("/admins/:adminId/brand-promotions/:promotionId/brands/:brandId", server.updateBrandPromotion)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/brand-promotions/:promotionId/brands/:brandId", server.deleteBrandPromotion) //! Admin Only
+	adminRouter.Get("/admins/:adminId/category-promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.listCategoryPromotionsForAdmins)                                //! Admin Only
+	adminRouter.Post("/admins/:adminId/category-promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.createCategoryPromotion)                                       //! Admin Only
+	adminRouter.Put("/admins/:adminId/category-promotions/:promotionId/categories/:categoryId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.updateCategoryPromotion)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/category-promotions/:promotionId/categories/:categoryId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.deleteCategoryPromotion) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/variations", server.createVariation)                //! Admin Only
-	adminRouter.Put("/admins/:adminId/variations/:variationId", server.updateVariation)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/variations/:variationId", server.deleteVariation) //! Admin Only
+	adminRouter.Get("/admins/:adminId/brand-promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.listBrandPromotionsForAdmins)                         //! Admin Only
+	adminRouter.Post("/admins/:adminId/brand-promotions", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.createBrandPromotion)                                //! Admin Only
+	adminRouter.Put("/admins/:adminId/brand-promotions/:promotionId/brands/:brandId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.updateBrandPromotion)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/brand-promotions/:promotionId/brands/:brandId", permissionMiddleware(server.store, db.PermissionPromotionsManage), server.deleteBrandPromotion) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/variation-options", server.createVariationOption)       //! Admin Only
-	adminRouter.Put("/admins/:adminId/variation-options/:id", server.updateVariationOption)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/variation-options/:id", server.deleteVariationOption) //! Admin Only
+	adminRouter.Post("/admins/:adminId/variations", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createVariation)                //! Admin Only
+	adminRouter.Put("/admins/:adminId/variations/:variationId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateVariation)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/variations/:variationId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteVariation) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/product-items", server.createProductItem)           //! Admin Only
-	adminRouter.Put("/admins/:adminId/product-items/:itemId", server.updateProductItem)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/product-items/:itemId", server.deleteProductItem) //! Admin Only
+	adminRouter.Post("/admins/:adminId/variation-options", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createVariationOption)       //! Admin Only
+	adminRouter.Put("/admins/:adminId/variation-options/:id", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateVariationOption)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/variation-options/:id", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteVariationOption) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/product-configurations/:itemId", server.createProductConfiguration)                                  //! Admin Only
-	adminRouter.Put("/admins/:adminId/product-configurations/:itemId", server.updateProductConfiguration)                                   //! Admin Only
-	adminRouter.Delete("/admins/:adminId/product-configurations/:itemId/variation-options/:variationId", server.deleteProductConfiguration) //! Admin Only
+	adminRouter.Post("/admins/:adminId/product-items", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductItem)           //! Admin Only
+	adminRouter.Put("/admins/:adminId/product-items/:itemId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductItem)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/product-items/:itemId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteProductItem) //! Admin Only
+
+	adminRouter.Post("/admins/:adminId/product-configurations/:itemId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.createProductConfiguration)                                  //! Admin Only
+	adminRouter.Put("/admins/:adminId/product-configurations/:itemId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.updateProductConfiguration)                                   //! Admin Only
+	adminRouter.Delete("/admins/:adminId/product-configurations/:itemId/variation-options/:variationId", permissionMiddleware(server.store, db.PermissionCatalogManage), server.deleteProductConfiguration) //! Admin Only
 
 	//? ShopOrderItems
 	userRouter.Get("/users/:id/shop-order-items/:orderId", server.getShopOrderItems)
 	userRouter.Get("/users/:id/shop-order-items", server.listShopOrderItems)
 
-	adminRouter.Get("/admins/:adminId/shop-order-items/:orderId", server.getShopOrderItemsForAdmin) //! Admin Only
-	adminRouter.Delete("/admins/:adminId/shop-order-items/:id", server.deleteShopOrderItem)         //! Admin Only
+	adminRouter.Get("/admins/:adminId/shop-order-items/:orderId", permissionMiddleware(server.store, db.PermissionOrdersRead), server.getShopOrderItemsForAdmin) //! Admin Only
+	adminRouter.Delete("/admins/:adminId/shop-order-items/:id", permissionMiddleware(server.store, db.PermissionOrdersManage), server.deleteShopOrderItem)       //! Admin Only
 
 	//? ShopOrders
 	userRouter.Get("/users/:id/shop-orders", server.listShopOrders)
+	userRouter.Get("/users/:id/shop-orders/:orderId", server.getShopOrder)
+	userRouter.Post("/users/:id/shop-orders/:orderId/cancel", server.cancelShopOrder)
+	userRouter.Post("/users/:id/shop-orders/:orderId/returns", server.createReturnRequest)
 	userRouter.Get("/users/:id/shop-orders-v2", server.listShopOrdersV2)
 	userRouter.Get("/users/:id/shop-orders-next-page", server.listShopOrdersNextPage)
 
-	adminRouter.Get("/admins/:adminId/shop-orders-v2", server.listShopOrdersV2ForAdmin)              //! Admin Only
-	adminRouter.Get("/admins/:adminId/shop-orders-next-page", server.listShopOrdersNextPageForAdmin) //! Admin Only
-	adminRouter.Put("/admins/:adminId/shop-orders/:shopOrderId", server.updateShopOrder)             //! Admin Only
+	adminRouter.Get("/admins/:adminId/shop-orders-v2", permissionMiddleware(server.store, db.PermissionOrdersRead), server.listShopOrdersV2ForAdmin)              //! Admin Only
+	adminRouter.Get("/admins/:adminId/shop-orders-next-page", permissionMiddleware(server.store, db.PermissionOrdersRead), server.listShopOrdersNextPageForAdmin) //! Admin Only
+	adminRouter.Put("/admins/:adminId/shop-orders/:shopOrderId", permissionMiddleware(server.store, db.PermissionOrdersManage), server.updateShopOrder)           //! Admin Only
+
+	//? ReturnRequests
+	userRouter.Get("/users/:id/returns", server.listReturnRequests)
+	userRouter.Get("/users/:id/returns/:returnId", server.getReturnRequest)
+
+	adminRouter.Get("/admins/:adminId/returns", permissionMiddleware(server.store, db.PermissionOrdersRead), server.listReturnRequestsForAdmin)      //! Admin Only
+	adminRouter.Put("/admins/:adminId/returns/:returnId", permissionMiddleware(server.store, db.PermissionOrdersManage), server.updateReturnRequest) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/shipping-method", server.createShippingMethod) //! Admin Only
+	adminRouter.Post("/admins/:adminId/shipping-method", permissionMiddleware(server.store, db.PermissionSettingsManage), server.createShippingMethod) //! Admin Only
 	userRouter.Get("/users/:id/shipping-method/:methodId", server.getShippingMethod)
 	userRouter.Get("/users/:id/shipping-method", server.listShippingMethods)
-	adminRouter.Get("/admins/:adminId/shipping-method", server.adminListShippingMethods)          //! Admin Only
-	adminRouter.Put("/admins/:adminId/shipping-method/:methodId", server.updateShippingMethod)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/shipping-method/:methodId", server.deleteShippingMethod) //! Admin Only
+	adminRouter.Get("/admins/:adminId/shipping-method", permissionMiddleware(server.store, db.PermissionSettingsManage), server.adminListShippingMethods)          //! Admin Only
+	adminRouter.Put("/admins/:adminId/shipping-method/:methodId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.updateShippingMethod)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/shipping-method/:methodId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.deleteShippingMethod) //! Admin Only
 
-	adminRouter.Post("/admins/:adminId/order-status", server.createOrderStatus) //! Admin Only
+	adminRouter.Post("/admins/:adminId/order-status", permissionMiddleware(server.store, db.PermissionSettingsManage), server.createOrderStatus) //! Admin Only
 	userRouter.Get("/users/:id/order-status/:statusId", server.getOrderStatus)
 	userRouter.Get("/users/:id/order-status", server.listOrderStatuses)
-	adminRouter.Get("/admins/:adminId/order-status", server.listOrderStatusesForAdmin)      //! Admin Only
-	adminRouter.Put("/admins/:adminId/order-status/:statusId", server.updateOrderStatus)    //! Admin Only
-	adminRouter.Delete("/admins/:adminId/order-status/:statusId", server.deleteOrderStatus) //! Admin Only
+	adminRouter.Get("/admins/:adminId/order-status", permissionMiddleware(server.store, db.PermissionOrdersRead), server.listOrderStatusesForAdmin)          //! Admin Only
+	adminRouter.Put("/admins/:adminId/order-status/:statusId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.updateOrderStatus)    //! Admin Only
+	adminRouter.Delete("/admins/:adminId/order-status/:statusId", permissionMiddleware(server.store, db.PermissionSettingsManage), server.deleteOrderStatus) //! Admin Only
 
 	server.router = app
 
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Current is true for the session of the access token making the request
+	Current bool `json:"current"`
+}
+
+func newUserSessionResponses(sessions []*db.UserSession, currentSessionID uuid.UUID) []sessionResponse {
+	rsp := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		rsp = append(rsp, sessionResponse{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			ClientIp:  session.ClientIp,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Current:   session.ID == currentSessionID,
+		})
+	}
+	return rsp
+}
+
+func newAdminSessionResponses(sessions []*db.AdminSession, currentSessionID uuid.UUID) []sessionResponse {
+	rsp := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		rsp = append(rsp, sessionResponse{
+			ID:        session.ID,
+			UserAgent: session.AdminAgent,
+			ClientIp:  session.ClientIp,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Current:   session.ID == currentSessionID,
+		})
+	}
+	return rsp
+}
+
+// //////////////* List API //////////////
+
+type listUserSessionsParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+func (server *Server) listUserSessions(ctx fiber.Ctx) error {
+	params := &listUserSessionsParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	sessions, err := server.store.ListActiveUserSessions(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(newUserSessionResponses(sessions, authPayload.SessionID))
+	return nil
+}
+
+// //////////////* Delete API //////////////
+
+type revokeUserSessionParamsRequest struct {
+	UserID    int64  `uri:"id" validate:"required,min=1"`
+	SessionID string `uri:"sessionId" validate:"required"`
+}
+
+// revokeUserSession logs out a single device, the whole family is revoked so its rotated tokens die with it
+func (server *Server) revokeUserSession(ctx fiber.Ctx) error {
+	params := &revokeUserSessionParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	sessionID, err := uuid.Parse(params.SessionID)
+	if err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	userSession, err := server.store.GetUserSession(ctx.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	// other users' sessions are reported as missing so their ids can't be probed
+	if userSession.UserID != authPayload.UserID {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+		return nil
+	}
+
+	_, err = server.store.RevokeUserSessionFamily(ctx.Context(), userSession.FamilyID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+type revokeUserSessionsParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+// revokeUserSessions logs the user out everywhere, including the session making the request
+func (server *Server) revokeUserSessions(ctx fiber.Ctx) error {
+	params := &revokeUserSessionsParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	_, err := server.store.RevokeUserSessions(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+//* Admin Sessions
+
+type listAdminSessionsParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+func (server *Server) listAdminSessions(ctx fiber.Ctx) error {
+	params := &listAdminSessionsParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	sessions, err := server.store.ListActiveAdminSessions(ctx.Context(), authPayload.AdminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(newAdminSessionResponses(sessions, authPayload.SessionID))
+	return nil
+}
+
+type revokeAdminSessionParamsRequest struct {
+	AdminID   int64  `uri:"adminId" validate:"required,min=1"`
+	SessionID string `uri:"sessionId" validate:"required"`
+}
+
+func (server *Server) revokeAdminSession(ctx fiber.Ctx) error {
+	params := &revokeAdminSessionParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	sessionID, err := uuid.Parse(params.SessionID)
+	if err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	adminSession, err := server.store.GetAdminSession(ctx.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if adminSession.AdminID != authPayload.AdminID {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+		return nil
+	}
+
+	_, err = server.store.RevokeAdminSessionFamily(ctx.Context(), adminSession.FamilyID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+type revokeAdminSessionsParamsRequest struct {
+	AdminID int64 `uri:"adminId" validate:"required,min=1"`
+}
+
+func (server *Server) revokeAdminSessions(ctx fiber.Ctx) error {
+	params := &revokeAdminSessionsParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
+	if authPayload.AdminID != params.AdminID {
+		err := errors.New("account unauthorized")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	_, err := server.store.RevokeAdminSessions(ctx.Context(), authPayload.AdminID)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
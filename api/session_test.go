@@ -0,0 +1,350 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListUserSessionsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	sessions := []*db.UserSession{randomActiveUserSession(user.ID), randomActiveUserSession(user.ID)}
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			UserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListActiveUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(sessions, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotSessions []map[string]any
+				err = json.Unmarshal(data, &gotSessions)
+				require.NoError(t, err)
+				require.Len(t, gotSessions, len(sessions))
+
+				for i, gotSession := range gotSessions {
+					require.Equal(t, sessions[i].ID.String(), gotSession["id"])
+					require.Equal(t, sessions[i].UserAgent, gotSession["user_agent"])
+					require.NotContains(t, gotSession, "refresh_token")
+				}
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListActiveUserSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "InternalError",
+			UserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListActiveUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/sessions", tc.UserID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.userTokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestRevokeUserSessionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	session := randomActiveUserSession(user.ID)
+
+	testCases := []struct {
+		name          string
+		SessionID     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:      "OK",
+			SessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:      "OtherUserSession",
+			SessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				otherSession := *session
+				otherSession.UserID = user.ID + 1
+
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(&otherSession, nil)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:      "NotFound",
+			SessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:      "InvalidSessionID",
+			SessionID: "not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/sessions/%s", user.ID, tc.SessionID)
+			request, err := http.NewRequest(fiber.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.userTokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestRevokeUserSessionsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			UserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(3), nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeUserSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/sessions", tc.UserID)
+			request, err := http.NewRequest(fiber.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.userTokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListAdminSessionsAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListActiveAdminSessions(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return([]*db.AdminSession{{ID: uuid.New(), AdminID: admin.ID}}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "RevokedSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IsAdminSessionActive(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					ListActiveAdminSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/admin/v1/admins/%d/sessions", admin.ID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.adminTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func randomActiveUserSession(userID int64) *db.UserSession {
+	sessionID := uuid.New()
+	return &db.UserSession{
+		ID:           sessionID,
+		UserID:       userID,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     util.RandomString(10),
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     sessionID,
+	}
+}
@@ -11,8 +11,10 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
+	"github.com/cshop/v3/worker"
 	"github.com/gofiber/fiber/v3"
 	"github.com/guregu/null/v6"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -31,6 +33,7 @@ type updateShopOrderJsonRequest struct {
 	OrderStatusID     *int64  `json:"order_status_id" validate:"omitempty,required,min=1"`
 	TrackNumber       *string `json:"track_number" validate:"omitempty,required"`
 	OrderTotal        *string `json:"order_total" validate:"omitempty,required"`
+	Note              *string `json:"note" validate:"omitempty,max=500"`
 	// DeviceID          string  `json:"device_id" validate:"required"`
 }
 
@@ -44,37 +47,68 @@ func (server *Server) updateShopOrder(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
-	arg := db.UpdateShopOrderParams{
-		AdminID:           authPayload.AdminID,
-		ID:                params.ShopOrderID,
-		TrackNumber:       null.StringFromPtr(req.TrackNumber),
-		UserID:            null.IntFromPtr(req.UserID),
-		PaymentTypeID:     null.IntFromPtr(req.PaymentTypeID),
-		ShippingAddressID: null.IntFromPtr(req.ShippingAddressID),
-		OrderTotal:        null.StringFromPtr(req.OrderTotal),
-		ShippingMethodID:  null.IntFromPtr(req.ShippingMethodID),
-		OrderStatusID:     null.IntFromPtr(req.OrderStatusID),
+	//? the order fulfilment staff can move the order on without changing what the user pays
+	if req.OrderTotal != nil && !checkAdminPermission(ctx, server.store, authPayload.AdminID, db.PermissionPricingManage) {
+		return nil
+	}
+
+	arg := db.UpdateShopOrderTxParams{
+		UpdateShopOrderParams: db.UpdateShopOrderParams{
+			AdminID:           authPayload.AdminID,
+			ID:                params.ShopOrderID,
+			TrackNumber:       null.StringFromPtr(req.TrackNumber),
+			UserID:            null.IntFromPtr(req.UserID),
+			PaymentTypeID:     null.IntFromPtr(req.PaymentTypeID),
+			ShippingAddressID: null.IntFromPtr(req.ShippingAddressID),
+			OrderTotal:        null.StringFromPtr(req.OrderTotal),
+			ShippingMethodID:  null.IntFromPtr(req.ShippingMethodID),
+			OrderStatusID:     null.IntFromPtr(req.OrderStatusID),
+		},
+		Note: null.StringFromPtr(req.Note).String,
 	}
 
-	shopOrder, err := server.store.UpdateShopOrder(ctx.Context(), arg)
+	result, err := server.store.UpdateShopOrderTx(ctx.Context(), arg)
 	if err != nil {
+		var transitionErr *db.InvalidOrderStatusTransitionError
+		if errors.As(err, &transitionErr) || errors.Is(err, db.ErrOrderTotalAbovePayment) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
 		if pqErr, ok := err.(*pgconn.PgError); ok {
 			switch pqErr.Message {
 			case util.ForeignKeyViolation, util.UniqueViolation:
 				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
 				return nil
 			}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
 		}
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
 		return nil
 	}
 
+	//? the order already moved on, a refund the provider didn't make is retried by a task
+	if result.OrderStatus != nil {
+		err = server.settleOrderPayment(ctx.Context(), result.ShopOrder.ID, result.OrderStatus.Code.String)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	if req.OrderTotal != nil {
+		err = server.refundOrderPayment(ctx.Context(), result.ShopOrder.ID)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	shopOrder := result.ShopOrder
 	ctx.Status(fiber.StatusOK).JSON(shopOrder)
 
 	//? new code fcm
@@ -100,7 +134,9 @@ func (server *Server) updateShopOrder(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	if notification.DeliveryUpdates && shopOrder.OrderStatusID.Int64 == 2 {
+	//? only notify when the order has just been shipped
+	if notification.DeliveryUpdates && result.OrderStatus != nil &&
+		result.OrderStatus.Code.String == db.OrderStatusShipped {
 		fcmClient, err := server.fb.Messaging(contextBG)
 		if err != nil {
 			log.Printf("error getting Messaging client: %v\n", err)
@@ -154,6 +190,133 @@ func (server *Server) updateShopOrder(ctx fiber.Ctx) error {
 	return nil
 }
 
+//////////////* Cancel API //////////////
+
+type cancelShopOrderParamsRequest struct {
+	UserID      int64 `uri:"id" validate:"required,min=1"`
+	ShopOrderID int64 `uri:"orderId" validate:"required,min=1"`
+}
+
+func (server *Server) cancelShopOrder(ctx fiber.Ctx) error {
+	params := &cancelShopOrderParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	result, err := server.store.CancelShopOrderTx(ctx.Context(), db.CancelShopOrderTxParams{
+		UserID:      authPayload.UserID,
+		ShopOrderID: params.ShopOrderID,
+	})
+	if err != nil {
+		var transitionErr *db.InvalidOrderStatusTransitionError
+		if errors.As(err, &transitionErr) {
+			ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+			return nil
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	//? the order is already cancelled, a refund the provider didn't make is retried by a task
+	err = server.settleOrderPayment(ctx.Context(), result.ShopOrder.ID, db.OrderStatusCancelled)
+	if err != nil {
+		log.Println(err)
+	}
+
+	taskPayload := &worker.PayloadSendOrderNotification{
+		UserID:      result.ShopOrder.UserID,
+		ShopOrderID: result.ShopOrder.ID,
+		Title:       "❌ تم إلغاء طلبك",
+		Body:        "تم إلغاء طلبك بنجاح. نتمنى أن نراك مجدداً قريباً! ❤️",
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueDefault),
+	}
+
+	//? the order is already cancelled, a failed notification shouldn't fail the request
+	err = server.taskDistributor.DistributeTaskSendOrderNotification(ctx.Context(), taskPayload, opts...)
+	if err != nil {
+		log.Println(err)
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(result.ShopOrder)
+	return nil
+}
+
+//////////////* Get API //////////////
+
+type getShopOrderParamsRequest struct {
+	UserID      int64 `uri:"id" validate:"required,min=1"`
+	ShopOrderID int64 `uri:"orderId" validate:"required,min=1"`
+}
+
+type shopOrderDetailResponse struct {
+	Items         []*db.ListShopOrderItemsByUserIDOrderIDRow         `json:"items"`
+	StatusHistory []*db.ListShopOrderStatusHistoryByUserIDOrderIDRow `json:"status_history"`
+}
+
+func (server *Server) getShopOrder(ctx fiber.Ctx) error {
+	params := &getShopOrderParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	shopOrderItems, err := server.store.ListShopOrderItemsByUserIDOrderID(ctx.Context(), db.ListShopOrderItemsByUserIDOrderIDParams{
+		UserID:  authPayload.UserID,
+		OrderID: params.ShopOrderID,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if len(shopOrderItems) == 0 {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+		return nil
+	}
+
+	statusHistory, err := server.store.ListShopOrderStatusHistoryByUserIDOrderID(ctx.Context(), db.ListShopOrderStatusHistoryByUserIDOrderIDParams{
+		UserID:      authPayload.UserID,
+		ShopOrderID: params.ShopOrderID,
+	})
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := shopOrderDetailResponse{
+		Items:         shopOrderItems,
+		StatusHistory: statusHistory,
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
 //////////////* List API //////////////
 
 type listShopOrdersParamsRequest struct {
@@ -334,7 +497,7 @@ func (server *Server) listShopOrdersV2ForAdmin(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -383,7 +546,7 @@ func (server *Server) listShopOrdersNextPageForAdmin(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -480,20 +480,30 @@ func TestDeleteShopOrderItemTxAPI(t *testing.T) {
 				store.EXPECT().
 					DeleteShopOrderItemTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(nil)
+					Return(&db.DeleteShopOrderItemTxResult{ShopOrder: &db.ShopOrder{ID: shopOrderItem.OrderID}}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrderItem.OrderID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{}, nil)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
 		{
-			name:            "Unauthorized",
+			name:            "Forbidden",
 			shopOrderItemID: shopOrderItem.ID,
 			AdminID:         admin.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, 2, admin.Active, time.Minute)
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionOrdersManage})).
+					Times(1).
+					Return(false, nil)
+
 				arg := db.DeleteShopOrderItemTxParams{
 					AdminID:         admin.ID,
 					ShopOrderItemID: shopOrderItem.ID,
@@ -503,7 +513,24 @@ func TestDeleteShopOrderItemTxAPI(t *testing.T) {
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
-				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:            "ReturnedItem",
+			shopOrderItemID: shopOrderItem.ID,
+			AdminID:         admin.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteShopOrderItemTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrShopOrderItemReturned)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
 			},
 		},
 		{
@@ -540,7 +567,7 @@ func TestDeleteShopOrderItemTxAPI(t *testing.T) {
 				store.EXPECT().
 					DeleteShopOrderItemTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(pgx.ErrNoRows)
+					Return(nil, pgx.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
 				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
@@ -561,7 +588,7 @@ func TestDeleteShopOrderItemTxAPI(t *testing.T) {
 				store.EXPECT().
 					DeleteShopOrderItemTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(pgx.ErrTxClosed)
+					Return(nil, pgx.ErrTxClosed)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
 				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
@@ -672,6 +699,9 @@ func createRandomShopOrderItem() (shopOrderItems *db.ListShopOrderItemsByUserIDO
 		OrderID:       util.RandomMoney(),
 		Quantity:      int32(util.RandomMoney()),
 		Price:         fmt.Sprint(int32(util.RandomMoney())),
+		SizeID:        null.IntFrom(util.RandomMoney()),
+		SizeValue:     null.StringFrom(util.RandomSize()),
+		ColorValue:    null.StringFrom(util.RandomColor()),
 		ProductName:   null.StringFrom(util.RandomUser()),
 		ProductImage:  null.StringFrom(util.RandomURL()),
 		ProductActive: null.BoolFrom(util.RandomBool()),
@@ -722,6 +752,10 @@ func requireBodyMatchShopOrderItemForList(t *testing.T, body io.ReadCloser, shop
 		require.Equal(t, shopOrderItem[i].Quantity, gotShopOrderItem[i].Quantity)
 		require.Equal(t, shopOrderItem[i].Price, gotShopOrderItem[i].Price)
 		require.Equal(t, shopOrderItem[i].CreatedAt, gotShopOrderItem[i].CreatedAt)
+		require.Equal(t, shopOrderItem[i].SizeID, gotShopOrderItem[i].SizeID)
+		require.Equal(t, shopOrderItem[i].SizeValue, gotShopOrderItem[i].SizeValue)
+		require.Equal(t, shopOrderItem[i].ColorValue, gotShopOrderItem[i].ColorValue)
+		require.Equal(t, shopOrderItem[i].ProductName, gotShopOrderItem[i].ProductName)
 	}
 	// require.Equal(t, ShopOrderItem.UserID, gotShopOrderItem.UserID)
 }
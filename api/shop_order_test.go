@@ -2,7 +2,9 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,11 +16,15 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	mockik "github.com/cshop/v3/image/mock"
 	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/payment"
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
+	wk "github.com/cshop/v3/worker"
 	mockwk "github.com/cshop/v3/worker/mock"
 	"github.com/gofiber/fiber/v3"
 	"github.com/guregu/null/v6"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -31,6 +37,20 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 	user, _ := randomNotificationUser(t)
 	notification := createRandomNotification(user)
 
+	deliveredStatus := &db.OrderStatus{
+		ID:     util.RandomMoney(),
+		Status: db.OrderStatusDelivered,
+		Code:   null.StringFrom(db.OrderStatusDelivered),
+	}
+	authorizedPayment := &db.PaymentTransaction{
+		ID:                util.RandomMoney(),
+		ShopOrderID:       shopOrder.ID,
+		Provider:          payment.ProviderCOD,
+		ProviderReference: null.StringFrom(util.RandomString(10)),
+		Status:            db.PaymentStatusAuthorized,
+		Amount:            shopOrder.OrderTotal,
+	}
+
 	testCases := []struct {
 		name          string
 		ShopOrderID   int64
@@ -58,21 +78,138 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
-				arg := db.UpdateShopOrderParams{
-					AdminID:           admin.ID,
-					TrackNumber:       null.StringFrom(shopOrder.TrackNumber),
-					UserID:            null.IntFrom(shopOrder.UserID),
-					ShippingAddressID: shopOrder.ShippingAddressID,
-					OrderTotal:        null.StringFrom(shopOrder.OrderTotal),
-					ShippingMethodID:  null.IntFrom(shopOrder.ShippingMethodID),
-					OrderStatusID:     shopOrder.OrderStatusID,
-					ID:                shopOrder.ID,
+				arg := db.UpdateShopOrderTxParams{
+					UpdateShopOrderParams: db.UpdateShopOrderParams{
+						AdminID:           admin.ID,
+						TrackNumber:       null.StringFrom(shopOrder.TrackNumber),
+						UserID:            null.IntFrom(shopOrder.UserID),
+						ShippingAddressID: shopOrder.ShippingAddressID,
+						OrderTotal:        null.StringFrom(shopOrder.OrderTotal),
+						ShippingMethodID:  null.IntFrom(shopOrder.ShippingMethodID),
+						OrderStatusID:     shopOrder.OrderStatusID,
+						ID:                shopOrder.ID,
+					},
+				}
+
+				store.EXPECT().
+					UpdateShopOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.UpdateShopOrderTxResult{ShopOrder: shopOrder}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrder.ID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{}, nil)
+
+				store.EXPECT().
+					GetNotificationV2(gomock.Any(), gomock.Any()).
+					Times(1).Return(notification, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "OrderTotalLoweredRefundsPayment",
+			ShopOrderID: shopOrder.ID,
+			AdminID:     admin.ID,
+			body: fiber.Map{
+				"order_total": "10.00",
+				"device_id":   deviceId,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.UpdateShopOrderTxResult{ShopOrder: shopOrder}, nil)
+
+				//? the order total change recorded the difference as a pending refund
+				capturedPayment := *authorizedPayment
+				capturedPayment.Status = db.PaymentStatusCaptured
+				capturedPayment.RefundedAmount = "0"
+				capturedPayment.PendingRefundAmount = "5.00"
+				capturedPayment.RefundInFlightAmount = "0"
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrder.ID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{&capturedPayment}, nil)
+
+				expectRefundPaymentTransaction(store, &capturedPayment)
+
+				store.EXPECT().
+					GetNotificationV2(gomock.Any(), gomock.Any()).
+					Times(1).Return(notification, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "OrderTotalAbovePayment",
+			ShopOrderID: shopOrder.ID,
+			AdminID:     admin.ID,
+			body: fiber.Map{
+				"order_total": "100000.00",
+				"device_id":   deviceId,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrOrderTotalAbovePayment)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "DeliveredCapturesPayment",
+			ShopOrderID: shopOrder.ID,
+			AdminID:     admin.ID,
+			body: fiber.Map{
+				"order_status_id": deliveredStatus.ID,
+				"device_id":       deviceId,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateShopOrderTxParams{
+					UpdateShopOrderParams: db.UpdateShopOrderParams{
+						AdminID:       admin.ID,
+						OrderStatusID: null.IntFrom(deliveredStatus.ID),
+						ID:            shopOrder.ID,
+					},
 				}
 
 				store.EXPECT().
-					UpdateShopOrder(gomock.Any(), gomock.Eq(arg)).
+					UpdateShopOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.UpdateShopOrderTxResult{ShopOrder: shopOrder, OrderStatus: deliveredStatus}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrder.ID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{authorizedPayment}, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Eq(db.UpdatePaymentTransactionTxParams{
+						ID:     authorizedPayment.ID,
+						Status: db.PaymentStatusCaptured,
+					})).
 					Times(1).
-					Return(shopOrder, nil)
+					Return(&db.UpdatePaymentTransactionTxResult{}, nil)
 
 				store.EXPECT().
 					GetNotificationV2(gomock.Any(), gomock.Any()).
@@ -82,6 +219,31 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name:        "PricingForbidden",
+			ShopOrderID: shopOrder.ID,
+			AdminID:     admin.ID,
+			body: fiber.Map{
+				"order_total": shopOrder.OrderTotal,
+				"device_id":   deviceId,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminHasPermission(gomock.Any(), gomock.Eq(db.AdminHasPermissionParams{AdminID: admin.ID, Permission: db.PermissionPricingManage})).
+					Times(1).
+					Return(false, nil)
+
+				store.EXPECT().
+					UpdateShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
 		{
 			name:        "NoAuthorization",
 			ShopOrderID: shopOrder.ID,
@@ -99,7 +261,7 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateShopOrder(gomock.Any(), gomock.Any()).
+					UpdateShopOrderTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
@@ -123,19 +285,21 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateShopOrderParams{
-					AdminID:           admin.ID,
-					TrackNumber:       null.StringFrom(shopOrder.TrackNumber),
-					UserID:            null.IntFrom(shopOrder.UserID),
-					ShippingAddressID: shopOrder.ShippingAddressID,
-					OrderTotal:        null.StringFrom(shopOrder.OrderTotal),
-					ShippingMethodID:  null.IntFrom(shopOrder.ShippingMethodID),
-					OrderStatusID:     shopOrder.OrderStatusID,
-					ID:                shopOrder.ID,
+				arg := db.UpdateShopOrderTxParams{
+					UpdateShopOrderParams: db.UpdateShopOrderParams{
+						AdminID:           admin.ID,
+						TrackNumber:       null.StringFrom(shopOrder.TrackNumber),
+						UserID:            null.IntFrom(shopOrder.UserID),
+						ShippingAddressID: shopOrder.ShippingAddressID,
+						OrderTotal:        null.StringFrom(shopOrder.OrderTotal),
+						ShippingMethodID:  null.IntFrom(shopOrder.ShippingMethodID),
+						OrderStatusID:     shopOrder.OrderStatusID,
+						ID:                shopOrder.ID,
+					},
 				}
 
 				store.EXPECT().
-					UpdateShopOrder(gomock.Any(), gomock.Eq(arg)).
+					UpdateShopOrderTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(nil, pgx.ErrTxClosed)
 			},
@@ -143,6 +307,63 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
 			},
 		},
+		{
+			name:        "InvalidStatusTransition",
+			ShopOrderID: shopOrder.ID,
+			AdminID:     admin.ID,
+			body: fiber.Map{
+				"order_status_id": shopOrder.OrderStatusID,
+				"note":            "customer asked to ship again",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateShopOrderTxParams{
+					UpdateShopOrderParams: db.UpdateShopOrderParams{
+						AdminID:       admin.ID,
+						OrderStatusID: shopOrder.OrderStatusID,
+						ID:            shopOrder.ID,
+					},
+					Note: "customer asked to ship again",
+				}
+
+				store.EXPECT().
+					UpdateShopOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil, &db.InvalidOrderStatusTransitionError{
+						From: db.OrderStatusDelivered,
+						To:   db.OrderStatusShipped,
+					})
+
+				store.EXPECT().
+					GetNotificationV2(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NotFound",
+			ShopOrderID: shopOrder.ID,
+			AdminID:     admin.ID,
+			body: fiber.Map{
+				"order_status_id": shopOrder.OrderStatusID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
 		{
 			name:        "InvalidUserID",
 			ShopOrderID: shopOrder.ID,
@@ -161,7 +382,7 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateShopOrder(gomock.Any(), gomock.Any()).
+					UpdateShopOrderTx(gomock.Any(), gomock.Any()).
 					Times(0)
 
 			},
@@ -204,6 +425,487 @@ func TestUpdateShopOrderAPI(t *testing.T) {
 
 }
 
+func TestCancelShopOrderAPI(t *testing.T) {
+	user, _ := randomSOUser(t)
+	shopOrder := createRandomShopOrderForUpdate()
+	shopOrder.UserID = user.ID
+
+	cancelledStatus := &db.OrderStatus{
+		ID:     util.RandomMoney(),
+		Status: db.OrderStatusCancelled,
+		Code:   null.StringFrom(db.OrderStatusCancelled),
+	}
+
+	capturedPayment := &db.PaymentTransaction{
+		ID:                   util.RandomMoney(),
+		ShopOrderID:          shopOrder.ID,
+		Provider:             payment.ProviderCOD,
+		ProviderReference:    null.StringFrom(util.RandomString(10)),
+		Status:               db.PaymentStatusCaptured,
+		Amount:               shopOrder.OrderTotal,
+		RefundedAmount:       "0",
+		RefundInFlightAmount: "0",
+		//? the cancellation recorded the refund of the whole payment
+		PendingRefundAmount: shopOrder.OrderTotal,
+	}
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		ShopOrderID   int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:        "OK",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				arg := db.CancelShopOrderTxParams{
+					UserID:      user.ID,
+					ShopOrderID: shopOrder.ID,
+				}
+
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.CancelShopOrderTxResult{
+						ShopOrder:   shopOrder,
+						OrderStatus: cancelledStatus,
+					}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrder.ID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{capturedPayment}, nil)
+
+				expectRefundPaymentTransaction(store, capturedPayment)
+
+				worker.EXPECT().
+					DistributeTaskSendOrderNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, payload *wk.PayloadSendOrderNotification, _ ...asynq.Option) error {
+						require.Equal(t, user.ID, payload.UserID)
+						require.Equal(t, shopOrder.ID, payload.ShopOrderID)
+						return nil
+					})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NotificationFailed",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.CancelShopOrderTxResult{
+						ShopOrder:   shopOrder,
+						OrderStatus: cancelledStatus,
+					}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrder.ID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{}, nil)
+
+				worker.EXPECT().
+					DistributeTaskSendOrderNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("redis is down"))
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "RefundFailedIsRetried",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.CancelShopOrderTxResult{
+						ShopOrder:   shopOrder,
+						OrderStatus: cancelledStatus,
+					}, nil)
+
+				store.EXPECT().
+					ListPaymentTransactionsByShopOrderID(gomock.Any(), gomock.Eq(shopOrder.ID)).
+					Times(1).
+					Return([]*db.PaymentTransaction{capturedPayment}, nil)
+
+				store.EXPECT().
+					StartRefundPaymentTransactionTx(gomock.Any(), gomock.Eq(db.StartRefundPaymentTransactionTxParams{ID: capturedPayment.ID})).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+
+				worker.EXPECT().
+					DistributeTaskRefundPayment(gomock.Any(), gomock.Eq(&wk.PayloadRefundPayment{PaymentTransactionID: capturedPayment.ID}), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				worker.EXPECT().
+					DistributeTaskSendOrderNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NotCancellable",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.InvalidOrderStatusTransitionError{
+						From: db.OrderStatusShipped,
+						To:   db.OrderStatusCancelled,
+					})
+
+				worker.EXPECT().
+					DistributeTaskSendOrderNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NotFound",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				worker.EXPECT().
+					DistributeTaskSendOrderNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "Unauthorized",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "NoAuthorization",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "InternalError",
+			UserID:      user.ID,
+			ShopOrderID: shopOrder.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:        "InvalidOrderID",
+			UserID:      user.ID,
+			ShopOrderID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CancelShopOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store, worker)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/shop-orders/%d/cancel", tc.UserID, tc.ShopOrderID)
+			request, err := http.NewRequest(fiber.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestGetShopOrderAPI(t *testing.T) {
+	user, _ := randomSOUser(t)
+	orderID := util.RandomMoney()
+
+	shopOrderItems := []*db.ListShopOrderItemsByUserIDOrderIDRow{
+		{
+			ID:            util.RandomMoney(),
+			OrderID:       orderID,
+			ProductItemID: util.RandomMoney(),
+			Price:         util.RandomDecimalString(1, 100),
+			Quantity:      int32(util.RandomInt(1, 10)),
+			Status:        null.StringFrom(db.OrderStatusProcessing),
+		},
+	}
+
+	statusHistory := []*db.ListShopOrderStatusHistoryByUserIDOrderIDRow{
+		{
+			ID:           util.RandomMoney(),
+			ShopOrderID:  orderID,
+			ToStatus:     null.StringFrom(db.OrderStatusPending),
+			ToStatusCode: null.StringFrom(db.OrderStatusPending),
+			Note:         "order placed",
+		},
+		{
+			ID:           util.RandomMoney(),
+			ShopOrderID:  orderID,
+			FromStatus:   null.StringFrom(db.OrderStatusPending),
+			ToStatus:     null.StringFrom(db.OrderStatusProcessing),
+			ToStatusCode: null.StringFrom(db.OrderStatusProcessing),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		OrderID       int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:    "OK",
+			UserID:  user.ID,
+			OrderID: orderID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListShopOrderItemsByUserIDOrderID(gomock.Any(), gomock.Eq(db.ListShopOrderItemsByUserIDOrderIDParams{
+						UserID:  user.ID,
+						OrderID: orderID,
+					})).
+					Times(1).
+					Return(shopOrderItems, nil)
+
+				store.EXPECT().
+					ListShopOrderStatusHistoryByUserIDOrderID(gomock.Any(), gomock.Eq(db.ListShopOrderStatusHistoryByUserIDOrderIDParams{
+						UserID:      user.ID,
+						ShopOrderID: orderID,
+					})).
+					Times(1).
+					Return(statusHistory, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotDetail shopOrderDetailResponse
+				err = json.Unmarshal(data, &gotDetail)
+				require.NoError(t, err)
+				require.Len(t, gotDetail.Items, len(shopOrderItems))
+				require.Equal(t, shopOrderItems[0].ID, gotDetail.Items[0].ID)
+				require.Len(t, gotDetail.StatusHistory, len(statusHistory))
+				require.Equal(t, statusHistory[1].ToStatusCode, gotDetail.StatusHistory[1].ToStatusCode)
+			},
+		},
+		{
+			name:    "NotFound",
+			UserID:  user.ID,
+			OrderID: orderID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListShopOrderItemsByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*db.ListShopOrderItemsByUserIDOrderIDRow{}, nil)
+
+				store.EXPECT().
+					ListShopOrderStatusHistoryByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "Unauthorized",
+			UserID:  user.ID,
+			OrderID: orderID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListShopOrderItemsByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "NoAuthorization",
+			UserID:  user.ID,
+			OrderID: orderID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListShopOrderItemsByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InternalError",
+			UserID:  user.ID,
+			OrderID: orderID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListShopOrderItemsByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(shopOrderItems, nil)
+
+				store.EXPECT().
+					ListShopOrderStatusHistoryByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "InvalidOrderID",
+			UserID:  user.ID,
+			OrderID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListShopOrderItemsByUserIDOrderID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/shop-orders/%d", tc.UserID, tc.OrderID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
 func TestListShopOrderAPI(t *testing.T) {
 	user, _ := randomSOUser(t)
 	orderStatus := createRandomOrderStatus()
@@ -939,7 +1641,7 @@ func TestListShopOrdersByUserIDNextPageAPI(t *testing.T) {
 func createRandomShopOrderForUpdate() (ShopOrder *db.ShopOrder) {
 	ShopOrder = &db.ShopOrder{
 		ID:                util.RandomMoney(),
-		TrackNumber:       util.GenerateTrackNumber(),
+		TrackNumber:       secure.TrackNumber(),
 		OrderNumber:       int32(util.RandomMoney()),
 		UserID:            util.RandomMoney(),
 		ShippingAddressID: null.IntFrom(util.RandomMoney()),
@@ -956,7 +1658,7 @@ func randomListShopOrdersV2(user db.User, orderStatus *db.OrderStatus) *db.ListS
 		OrderNumber: int32(util.RandomMoney()),
 		ItemCount:   util.RandomMoney(),
 		ID:          util.RandomMoney(),
-		TrackNumber: util.GenerateTrackNumber(),
+		TrackNumber: secure.TrackNumber(),
 		UserID:      user.ID,
 		// PaymentMethodID:   util.RandomMoney(),
 		ShippingAddressID: null.IntFrom(util.RandomMoney()),
@@ -972,7 +1674,7 @@ func randomListShopOrdersV2ForAdmin(user db.User, orderStatus *db.OrderStatus) *
 		OrderNumber: int32(util.RandomMoney()),
 		ItemCount:   util.RandomMoney(),
 		ID:          util.RandomMoney(),
-		TrackNumber: util.GenerateTrackNumber(),
+		TrackNumber: secure.TrackNumber(),
 		UserID:      user.ID,
 		// PaymentMethodID:   util.RandomMoney(),
 		ShippingAddressID: null.IntFrom(util.RandomMoney()),
@@ -989,7 +1691,7 @@ func randomListShopOrdersByUserIDNextPage(user db.User, orderStatus *db.OrderSta
 		OrderNumber: int32(util.RandomMoney()),
 		ItemCount:   util.RandomMoney(),
 		ID:          util.RandomMoney(),
-		TrackNumber: util.GenerateTrackNumber(),
+		TrackNumber: secure.TrackNumber(),
 		UserID:      user.ID,
 		// PaymentMethodID:   util.RandomMoney(),
 		ShippingAddressID: null.IntFrom(util.RandomMoney()),
@@ -1006,7 +1708,7 @@ func randomListShopOrdersByUserIDNextPageForAdmin(user db.User, orderStatus *db.
 		OrderNumber: int32(util.RandomMoney()),
 		ItemCount:   util.RandomMoney(),
 		ID:          util.RandomMoney(),
-		TrackNumber: util.GenerateTrackNumber(),
+		TrackNumber: secure.TrackNumber(),
 		UserID:      user.ID,
 		// PaymentMethodID:   util.RandomMoney(),
 		ShippingAddressID: null.IntFrom(util.RandomMoney()),
@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"log"
 
 	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/token"
@@ -544,6 +545,106 @@ func (server *Server) deleteShoppingCartItemAllByUser(ctx fiber.Ctx) error {
 	return nil
 }
 
+// ////////////* Quote API //////////////
+type quoteShoppingCartParamsRequest struct {
+	UserID         int64 `uri:"id" validate:"required,min=1"`
+	ShoppingCartID int64 `uri:"cartId" validate:"required,min=1"`
+}
+type quoteShoppingCartQueryRequest struct {
+	ShippingMethodID int64 `query:"shipping_method_id" validate:"required,min=1"`
+}
+
+func (server *Server) quoteShoppingCart(ctx fiber.Ctx) error {
+	params := &quoteShoppingCartParamsRequest{}
+	query := &quoteShoppingCartQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.QuoteCartTxParams{
+		UserID:           authPayload.UserID,
+		ShoppingCartID:   params.ShoppingCartID,
+		ShippingMethodID: query.ShippingMethodID,
+	}
+
+	quote, err := server.store.QuoteCartTx(ctx.Context(), arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(quote)
+	return nil
+}
+
+// ////////////* Reserve API //////////////
+type reserveShoppingCartParamsRequest struct {
+	UserID         int64 `uri:"id" validate:"required,min=1"`
+	ShoppingCartID int64 `uri:"cartId" validate:"required,min=1"`
+}
+
+func (server *Server) reserveShoppingCart(ctx fiber.Ctx) error {
+	params := &reserveShoppingCartParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if authPayload.UserID != params.UserID {
+		err := errors.New("account deosn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	duration := server.config.StockReservationDuration
+	if duration <= 0 {
+		duration = util.DefaultStockReservationDuration
+	}
+
+	arg := db.ReserveCartTxParams{
+		UserID:         authPayload.UserID,
+		ShoppingCartID: params.ShoppingCartID,
+		Duration:       duration,
+	}
+
+	reservation, err := server.store.ReserveCartTx(ctx.Context(), arg)
+	if err != nil {
+		var stockErr *db.StockNotAvailableError
+		if errors.As(err, &stockErr) {
+			ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":     stockErr.Error(),
+				"size_id":   stockErr.SizeID,
+				"available": stockErr.Available,
+			})
+			return nil
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(reservation)
+	return nil
+}
+
 // ////////////* Finish Purshase API //////////////
 type finishPurshaseParamsRequest struct {
 	UserID         int64 `uri:"id" validate:"required,min=1"`
@@ -553,8 +654,8 @@ type finishPurshaseJsonRequest struct {
 	UserAddressID    int64  `json:"user_address_id" validate:"required,min=1"`
 	PaymentTypeID    int64  `json:"payment_type_id" validate:"required,min=1"`
 	ShippingMethodID int64  `json:"shipping_method_id" validate:"required,min=1"`
-	OrderStatusID    int64  `json:"order_status_id" validate:"required,min=1"`
-	OrderTotal       string `json:"order_total" validate:"required"`
+	OrderTotal       string `json:"order_total" validate:"omitempty,numeric"`
+	CouponCode       string `json:"coupon_code" validate:"omitempty,alphanum,max=32"`
 }
 
 func (server *Server) finishPurchase(ctx fiber.Ctx) error {
@@ -579,12 +680,29 @@ func (server *Server) finishPurchase(ctx fiber.Ctx) error {
 		PaymentTypeID:    req.PaymentTypeID,
 		ShoppingCartID:   params.ShoppingCartID,
 		ShippingMethodID: req.ShippingMethodID,
-		OrderStatusID:    req.OrderStatusID,
 		OrderTotal:       req.OrderTotal,
+		CouponCode:       req.CouponCode,
 	}
 
 	finishedPurchase, err := server.store.FinishedPurchaseTx(ctx.Context(), arg)
 	if err != nil {
+		var totalErr *db.OrderTotalMismatchError
+		if errors.As(err, &totalErr) {
+			ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    totalErr.Error(),
+				"expected": totalErr.Expected,
+			})
+			return nil
+		}
+		var couponErr *db.CouponNotApplicableError
+		if errors.As(err, &couponErr) {
+			ctx.Status(fiber.StatusUnprocessableEntity).JSON(errorResponse(err))
+			return nil
+		}
+		if errors.Is(err, db.ErrEmptyShoppingCart) {
+			ctx.Status(fiber.StatusUnprocessableEntity).JSON(errorResponse(err))
+			return nil
+		}
 		if pqErr, ok := err.(*pgconn.PgError); ok {
 			switch pqErr.Message {
 			case util.ForeignKeyViolation, util.UniqueViolation:
@@ -599,6 +717,25 @@ func (server *Server) finishPurchase(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	//? the order is placed, a payment that couldn't be authorized stays pending and is retried by a task
+	paymentResult, err := server.authorizePayment(ctx.Context(), finishedPurchase.PaymentTransaction)
+	if err != nil {
+		log.Println(err)
+		ctx.Status(fiber.StatusAccepted).JSON(finishedPurchase)
+		return nil
+	}
+	finishedPurchase.PaymentTransaction = paymentResult.PaymentTransaction
+
+	//? the purchase is done, a capture the provider didn't make is retried by a task
+	if err := server.confirmPayment(ctx.Context(), paymentResult.PaymentTransaction); err != nil {
+		log.Println(err)
+	}
+
+	if paymentResult.PaymentTransaction.Status == db.PaymentStatusFailed {
+		ctx.Status(fiber.StatusPaymentRequired).JSON(finishedPurchase)
+		return nil
+	}
+
 	ctx.Status(fiber.StatusOK).JSON(finishedPurchase)
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
@@ -13,8 +14,10 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	mockik "github.com/cshop/v3/image/mock"
 	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/payment"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
+	wk "github.com/cshop/v3/worker"
 	mockwk "github.com/cshop/v3/worker/mock"
 	"github.com/gofiber/fiber/v3"
 	"github.com/guregu/null/v6"
@@ -876,17 +879,32 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 	shoppingCart := createRandomShoppingCart(user)
 	shippingMethod := createRandomShippingMethod()
 	paymentMethod := createRandomPaymentMethodUA(user)
-	orderStatus := createRandomOrderStatus()
 	orderTotal := util.RandomDecimalString(1, 100)
 	finishedPurchase := createRandomFinishedPurchase()
 
+	authorizedPaymentArg := db.UpdatePaymentTransactionTxParams{
+		ID:                finishedPurchase.PaymentTransaction.ID,
+		ProviderReference: null.StringFrom(fmt.Sprintf("%s_%d", payment.ProviderCOD, finishedPurchase.PaymentTransaction.ID)),
+		Status:            db.PaymentStatusAuthorized,
+	}
+	authorizedPayment := &db.UpdatePaymentTransactionTxResult{
+		PaymentTransaction: &db.PaymentTransaction{
+			ID:                finishedPurchase.PaymentTransaction.ID,
+			ShopOrderID:       finishedPurchase.ShopOrderID,
+			Provider:          payment.ProviderCOD,
+			ProviderReference: authorizedPaymentArg.ProviderReference,
+			Status:            db.PaymentStatusAuthorized,
+			Amount:            finishedPurchase.OrderTotal,
+		},
+	}
+
 	testCases := []struct {
 		name           string
 		body           fiber.Map
 		UserID         int64
 		ShoppingCartID int64
 		setupAuth      func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs     func(store *mockdb.MockStore)
+		buildStubs     func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor)
 		checkResponse  func(t *testing.T, rsp *http.Response)
 	}{
 		{
@@ -899,13 +917,12 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 				"payment_type_id": paymentMethod.PaymentTypeID,
 
 				"shipping_method_id": shippingMethod.ID,
-				"order_status_id":    orderStatus.ID,
 				"order_total":        orderTotal,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
 
 				arg := db.FinishedPurchaseTxParams{
 					UserID:           user.ID,
@@ -913,7 +930,6 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 					PaymentTypeID:    paymentMethod.PaymentTypeID,
 					ShoppingCartID:   shoppingCart.ID,
 					ShippingMethodID: shippingMethod.ID,
-					OrderStatusID:    orderStatus.ID,
 					OrderTotal:       orderTotal,
 				}
 
@@ -921,11 +937,298 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 					FinishedPurchaseTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(finishedPurchase, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Eq(authorizedPaymentArg)).
+					Times(1).
+					Return(authorizedPayment, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "OKWithoutOrderTotal",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				arg := db.FinishedPurchaseTxParams{
+					UserID:           user.ID,
+					AddressID:        address.ID,
+					PaymentTypeID:    paymentMethod.PaymentTypeID,
+					ShoppingCartID:   shoppingCart.ID,
+					ShippingMethodID: shippingMethod.ID,
+				}
+
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(finishedPurchase, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Eq(authorizedPaymentArg)).
+					Times(1).
+					Return(authorizedPayment, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "OrderTotalMismatch",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+				"order_total":        orderTotal,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.OrderTotalMismatchError{Expected: "1.00", Got: orderTotal})
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "InvalidOrderTotal",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+				"order_total":        "ten",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "EmptyCart",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrEmptyShoppingCart)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnprocessableEntity, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "OKWithCoupon",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+				"coupon_code":        "SUMMER10",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				arg := db.FinishedPurchaseTxParams{
+					UserID:           user.ID,
+					AddressID:        address.ID,
+					PaymentTypeID:    paymentMethod.PaymentTypeID,
+					ShoppingCartID:   shoppingCart.ID,
+					ShippingMethodID: shippingMethod.ID,
+					CouponCode:       "SUMMER10",
+				}
+
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(finishedPurchase, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Eq(authorizedPaymentArg)).
+					Times(1).
+					Return(authorizedPayment, nil)
 			},
 			checkResponse: func(t *testing.T, rsp *http.Response) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name:           "CouponNotApplicable",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+				"coupon_code":        "SUMMER10",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.CouponNotApplicableError{Code: "SUMMER10", Reason: "usage limit reached"})
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnprocessableEntity, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "PaymentFailed",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				declinedPurchase := createRandomFinishedPurchase()
+				declinedPurchase.PaymentTransaction.Provider = declinedProviderName
+
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(declinedPurchase, nil)
+
+				arg := db.UpdatePaymentTransactionTxParams{
+					ID:                declinedPurchase.PaymentTransaction.ID,
+					Status:            db.PaymentStatusFailed,
+					ProviderReference: null.StringFrom(fmt.Sprintf("%s_%d", declinedProviderName, declinedPurchase.PaymentTransaction.ID)),
+				}
+
+				failedPayment := *declinedPurchase.PaymentTransaction
+				failedPayment.Status = db.PaymentStatusFailed
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&db.UpdatePaymentTransactionTxResult{PaymentTransaction: &failedPayment}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusPaymentRequired, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "PaymentProviderUnavailable",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				// the fake provider answers an error for an amount that is not positive
+				unavailablePurchase := createRandomFinishedPurchase()
+				unavailablePurchase.PaymentTransaction.Provider = payment.ProviderFake
+				unavailablePurchase.PaymentTransaction.Amount = "0"
+
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(unavailablePurchase, nil)
+
+				//? the payment isn't recorded as failed so the order isn't cancelled
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				worker.EXPECT().
+					DistributeTaskAuthorizePayment(gomock.Any(), gomock.Eq(&wk.PayloadAuthorizePayment{PaymentTransactionID: unavailablePurchase.PaymentTransaction.ID}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusAccepted, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "PaymentProviderNotFound",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			body: fiber.Map{
+				"user_address_id":    address.ID,
+				"payment_type_id":    paymentMethod.PaymentTypeID,
+				"shipping_method_id": shippingMethod.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
+				unknownPurchase := createRandomFinishedPurchase()
+				unknownPurchase.PaymentTransaction.Provider = "unknown"
+
+				store.EXPECT().
+					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(unknownPurchase, nil)
+
+				store.EXPECT().
+					UpdatePaymentTransactionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				worker.EXPECT().
+					DistributeTaskAuthorizePayment(gomock.Any(), gomock.Eq(&wk.PayloadAuthorizePayment{PaymentTransactionID: unknownPurchase.PaymentTransaction.ID}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				//? the order is placed so the idempotency key is kept and the payment stays pending
+				require.Equal(t, http.StatusAccepted, rsp.StatusCode)
+
+				var gotPurchase db.FinishedPurchaseTxResult
+				err := json.NewDecoder(rsp.Body).Decode(&gotPurchase)
+				require.NoError(t, err)
+				require.Equal(t, db.PaymentStatusPending, gotPurchase.PaymentTransaction.Status)
+			},
+		},
 		{
 			name:           "NoAuthorization",
 			UserID:         user.ID,
@@ -936,12 +1239,11 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 				"payment_type_id": paymentMethod.ID,
 
 				"shipping_method_id": shippingMethod.ID,
-				"order_status_id":    orderStatus.ID,
 				"order_total":        orderTotal,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
 				store.EXPECT().
 					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -960,20 +1262,18 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 				"payment_type_id": paymentMethod.PaymentTypeID,
 
 				"shipping_method_id": shippingMethod.ID,
-				"order_status_id":    orderStatus.ID,
 				"order_total":        orderTotal,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
 				arg := db.FinishedPurchaseTxParams{
 					UserID:           user.ID,
 					AddressID:        address.ID,
 					PaymentTypeID:    paymentMethod.PaymentTypeID,
 					ShoppingCartID:   shoppingCart.ID,
 					ShippingMethodID: shippingMethod.ID,
-					OrderStatusID:    orderStatus.ID,
 					OrderTotal:       orderTotal,
 				}
 
@@ -992,7 +1292,7 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 0, user.Username, time.Minute)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, worker *mockwk.MockTaskDistributor) {
 				store.EXPECT().
 					FinishedPurchaseTx(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -1014,9 +1314,10 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 			worker := mockwk.NewMockTaskDistributor(ctrl)
 			ik := mockik.NewMockImageKitManagement(ctrl)
 			mailSender := mockemail.NewMockEmailSender(ctrl)
-			tc.buildStubs(store)
+			tc.buildStubs(store, worker)
 
 			server := newTestServer(t, store, worker, ik, mailSender)
+			server.payments[declinedProviderName] = declinedProvider{}
 			//recorder := httptest.NewRecorder()
 
 			// Marshal body data to JSON
@@ -1037,6 +1338,342 @@ func TestUpdateFinishPurchaseItemAPI(t *testing.T) {
 	}
 }
 
+func TestQuoteShoppingCartAPI(t *testing.T) {
+	user, _ := randomSCIUser(t)
+	shoppingCart := createRandomShoppingCart(user)
+	shippingMethod := createRandomShippingMethod()
+	quote := createRandomCartQuote(shippingMethod)
+
+	testCases := []struct {
+		name             string
+		UserID           int64
+		ShoppingCartID   int64
+		ShippingMethodID int64
+		setupAuth        func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs       func(store *mockdb.MockStore)
+		checkResponse    func(t *testing.T, rsp *http.Response)
+	}{
+		{
+			name:             "OK",
+			UserID:           user.ID,
+			ShoppingCartID:   shoppingCart.ID,
+			ShippingMethodID: shippingMethod.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.QuoteCartTxParams{
+					UserID:           user.ID,
+					ShoppingCartID:   shoppingCart.ID,
+					ShippingMethodID: shippingMethod.ID,
+				}
+
+				store.EXPECT().
+					QuoteCartTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(quote, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCartQuote(t, rsp.Body, quote)
+			},
+		},
+		{
+			name:             "NotFound",
+			UserID:           user.ID,
+			ShoppingCartID:   shoppingCart.ID,
+			ShippingMethodID: shippingMethod.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					QuoteCartTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:             "Unauthorized",
+			UserID:           user.ID,
+			ShoppingCartID:   shoppingCart.ID,
+			ShippingMethodID: shippingMethod.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					QuoteCartTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:             "NoAuthorization",
+			UserID:           user.ID,
+			ShoppingCartID:   shoppingCart.ID,
+			ShippingMethodID: shippingMethod.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					QuoteCartTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:             "InternalError",
+			UserID:           user.ID,
+			ShoppingCartID:   shoppingCart.ID,
+			ShippingMethodID: shippingMethod.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					QuoteCartTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:             "MissingShippingMethod",
+			UserID:           user.ID,
+			ShoppingCartID:   shoppingCart.ID,
+			ShippingMethodID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					QuoteCartTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/carts/%d/quote", tc.UserID, tc.ShoppingCartID)
+			request, err := http.NewRequest(fiber.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("shipping_method_id", strconv.FormatInt(tc.ShippingMethodID, 10))
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, rsp)
+		})
+	}
+}
+
+func TestReserveShoppingCartAPI(t *testing.T) {
+	user, _ := randomSCIUser(t)
+	shoppingCart := createRandomShoppingCart(user)
+	reservation := createRandomCartReservation(shoppingCart)
+
+	testCases := []struct {
+		name           string
+		UserID         int64
+		ShoppingCartID int64
+		setupAuth      func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, rsp *http.Response)
+	}{
+		{
+			name:           "OK",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReserveCartTxParams{
+					UserID:         user.ID,
+					ShoppingCartID: shoppingCart.ID,
+					Duration:       util.DefaultStockReservationDuration,
+				}
+
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reservation, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchCartReservation(t, rsp.Body, reservation)
+			},
+		},
+		{
+			name:           "NotFound",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "StockNotAvailable",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.StockNotAvailableError{
+						SizeID:    reservation.Reservations[0].SizeID,
+						Available: 0,
+						Reason:    "Stock is Empty",
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var got map[string]any
+				err = json.Unmarshal(data, &got)
+				require.NoError(t, err)
+				require.EqualValues(t, reservation.Reservations[0].SizeID, got["size_id"])
+				require.EqualValues(t, 0, got["available"])
+			},
+		},
+		{
+			name:           "Unauthorized",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID+1, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "NoAuthorization",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "InternalError",
+			UserID:         user.ID,
+			ShoppingCartID: shoppingCart.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name:           "InvalidCartID",
+			UserID:         user.ID,
+			ShoppingCartID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveCartTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+			worker := mockwk.NewMockTaskDistributor(ctrl)
+			ik := mockik.NewMockImageKitManagement(ctrl)
+			mailSender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, worker, ik, mailSender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/carts/%d/reserve", tc.UserID, tc.ShoppingCartID)
+			request, err := http.NewRequest(fiber.MethodPut, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.userTokenMaker)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, rsp)
+		})
+	}
+}
+
 func randomSCIUser(t *testing.T) (user *db.User, password string) {
 	password = util.RandomString(6)
 	hashedPassword, err := util.HashPassword(password)
@@ -1193,6 +1830,14 @@ func createRandomFinishedPurchase() (finishedPurchase *db.FinishedPurchaseTxResu
 		UpdatedProductSizeID: util.RandomMoney(),
 		ShopOrderID:          util.RandomMoney(),
 		ShopOrderItemID:      util.RandomMoney(),
+		OrderTotal:           util.RandomDecimalString(1, 100),
+	}
+	finishedPurchase.PaymentTransaction = &db.PaymentTransaction{
+		ID:          util.RandomMoney(),
+		ShopOrderID: finishedPurchase.ShopOrderID,
+		Provider:    payment.ProviderCOD,
+		Status:      db.PaymentStatusPending,
+		Amount:      finishedPurchase.OrderTotal,
 	}
 	return
 }
@@ -1313,3 +1958,68 @@ func requireBodyMatchFinalListShoppingCartItem(t *testing.T, body io.ReadCloser,
 		require.Equal(t, finalRsp[i].ProductItemID, gotCartItem.ProductItemID)
 	}
 }
+
+func createRandomCartQuote(shippingMethod *db.ShippingMethod) (quote *db.QuoteCartTxResult) {
+	quote = &db.QuoteCartTxResult{
+		Lines: []*db.QuoteCartLine{
+			{
+				ShoppingCartItemID: util.RandomMoney(),
+				ProductItemID:      util.RandomMoney(),
+				SizeID:             util.RandomMoney(),
+				Qty:                1,
+				QtyInStock:         int32(util.RandomInt(2, 10)),
+				UnitPrice:          "10.00",
+				Promotion: &db.AppliedPromotion{
+					ID:           util.RandomMoney(),
+					Name:         util.RandomUser(),
+					Source:       "category",
+					DiscountRate: 10,
+				},
+				LineTotal: "9.00",
+			},
+		},
+		Subtotal:         "9.00",
+		ShippingMethodID: shippingMethod.ID,
+		ShippingPrice:    shippingMethod.Price,
+	}
+	return
+}
+
+func requireBodyMatchCartQuote(t *testing.T, body io.Reader, quote *db.QuoteCartTxResult) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotQuote *db.QuoteCartTxResult
+	err = json.Unmarshal(data, &gotQuote)
+	require.NoError(t, err)
+	require.Equal(t, quote, gotQuote)
+}
+
+func createRandomCartReservation(shoppingCart *db.ShoppingCart) (reservation *db.ReserveCartTxResult) {
+	expiresAt := time.Now().Add(util.DefaultStockReservationDuration).UTC().Truncate(time.Second)
+	reservation = &db.ReserveCartTxResult{
+		Reservations: []*db.StockReservation{
+			{
+				ID:             util.RandomMoney(),
+				ShoppingCartID: shoppingCart.ID,
+				ProductItemID:  util.RandomMoney(),
+				SizeID:         util.RandomMoney(),
+				Qty:            1,
+				ExpiresAt:      expiresAt,
+				CreatedAt:      time.Now().UTC().Truncate(time.Second),
+			},
+		},
+		ExpiresAt: expiresAt,
+	}
+	return
+}
+
+func requireBodyMatchCartReservation(t *testing.T, body io.Reader, reservation *db.ReserveCartTxResult) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotReservation *db.ReserveCartTxResult
+	err = json.Unmarshal(data, &gotReservation)
+	require.NoError(t, err)
+	require.Equal(t, reservation, gotReservation)
+}
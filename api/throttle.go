@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// scopes of the failed attempts counted per account, an account locked out of one flow can still use the others
+const (
+	throttleScopeUserLogin      = "user_login"
+	throttleScopeAdminLogin     = "admin_login"
+	throttleScopeVerifyEmail    = "verify_email"
+	throttleScopeResetPassword  = "reset_password"
+	throttleScopeAdminTwoFactor = "admin_two_factor"
+	throttleScopeEmailChange    = "email_change"
+	throttleScopeUserDeletion   = "user_deletion"
+	// every otp email sent counts against the account, not only the failed attempts
+	throttleScopeEmailChangeRequest = "email_change_request"
+)
+
+// codes returned with a 429 so clients can tell a lockout from a wrong password or otp
+const (
+	errCodeAccountLocked       = "account_locked"
+	errCodeTooManyAttempts     = "too_many_attempts"
+	errCodeOTPAttemptsExceeded = "otp_attempts_exceeded"
+)
+
+// maxOTPAttempts wrong codes expire an otp, a new one has to be requested
+const maxOTPAttempts = 5
+
+var (
+	errAccountLocked       = errors.New("too many failed attempts for this account, try again later")
+	errTooManyAttempts     = errors.New("too many failed attempts from this address, try again later")
+	errOTPAttemptsExceeded = errors.New("too many wrong codes, request a new one")
+)
+
+func lockedResponse(err error, code string, retryAfter time.Duration) fiber.Map {
+	rsp := fiber.Map{"error": err.Error(), "code": code}
+	if retryAfter > 0 {
+		rsp["retry_after"] = int64(math.Ceil(retryAfter.Seconds()))
+	}
+	return rsp
+}
+
+func accountThrottleKey(scope, account string) string {
+	return scope + ":" + strings.ToLower(account)
+}
+
+// throttled writes a 429 when the account or the client ip is still blocked by earlier failed attempts
+func (server *Server) throttled(ctx fiber.Ctx, scope, account string) bool {
+	wait, err := server.accountLimiter.Wait(ctx.Context(), accountThrottleKey(scope, account))
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return true
+	}
+	if wait > 0 {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+		ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errAccountLocked, errCodeAccountLocked, wait))
+		return true
+	}
+
+	wait, err = server.ipLimiter.Wait(ctx.Context(), ctx.IP())
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return true
+	}
+	if wait > 0 {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+		ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errTooManyAttempts, errCodeTooManyAttempts, wait))
+		return true
+	}
+
+	return false
+}
+
+// recordFailedAttempt counts a wrong password or otp against the account and the client ip,
+// the request already failed so a limiter error is only logged
+func (server *Server) recordFailedAttempt(ctx fiber.Ctx, scope, account string) {
+	key := accountThrottleKey(scope, account)
+	if _, err := server.accountLimiter.Fail(ctx.Context(), key); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("failed to record failed attempt")
+	}
+	if _, err := server.ipLimiter.Fail(ctx.Context(), ctx.IP()); err != nil {
+		log.Error().Err(err).Str("ip", ctx.IP()).Msg("failed to record failed attempt")
+	}
+}
+
+// resetFailedAttempts clears the account after a successful attempt, the ip keeps its count
+// so one valid account can't be used to reset guesses spread over others
+func (server *Server) resetFailedAttempts(ctx fiber.Ctx, scope, account string) {
+	key := accountThrottleKey(scope, account)
+	if err := server.accountLimiter.Reset(ctx.Context(), key); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("failed to reset failed attempts")
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	"github.com/cshop/v3/throttle"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoginUserLockoutAPI(t *testing.T) {
+	user, password := randomUserLogin(t)
+	userSession := randomUserSession(user)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store, nil, nil, nil)
+
+	// every wrong password up to the first delayed one reaches the store
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(int(throttle.AccountPolicy.FreeAttempts)+1).
+		Return(user, nil)
+
+	for range throttle.AccountPolicy.FreeAttempts + 1 {
+		rsp := loginUserRequestForTest(t, server, user.Email, password+"wrong")
+		require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+	}
+
+	// the right password is refused too until the delay is over
+	rsp := loginUserRequestForTest(t, server, user.Email, password)
+	require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	require.NotEmpty(t, rsp.Header.Get(fiber.HeaderRetryAfter))
+	requireErrorCode(t, rsp.Body, errCodeAccountLocked)
+
+	// other accounts can still login
+	user2, password2 := randomUserLogin(t)
+
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user2.Email)).
+		Times(1).
+		Return(user2, nil)
+
+	store.EXPECT().
+		CreateUserSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(userSession, nil)
+
+	rsp = loginUserRequestForTest(t, server, user2.Email, password2)
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+}
+
+func TestLoginUserIPLockoutAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store, nil, nil, nil)
+
+	// guesses spread over many accounts are counted against the client ip
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Any()).
+		Times(int(throttle.IPPolicy.FreeAttempts)+1).
+		Return(nil, pgx.ErrNoRows)
+
+	for range throttle.IPPolicy.FreeAttempts + 1 {
+		rsp := loginUserRequestForTest(t, server, util.RandomEmail(), util.RandomString(6))
+		require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+	}
+
+	rsp := loginUserRequestForTest(t, server, util.RandomEmail(), util.RandomString(6))
+	require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	requireErrorCode(t, rsp.Body, errCodeTooManyAttempts)
+}
+
+func TestLoginUserIPLockoutBehindProxyAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store, nil, nil, nil)
+
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Any()).
+		Times(int(throttle.IPPolicy.FreeAttempts)+2).
+		Return(nil, pgx.ErrNoRows)
+
+	// both clients reach the server through the same reverse proxy
+	for range throttle.IPPolicy.FreeAttempts + 1 {
+		rsp := loginUserFromIPForTest(t, server, "203.0.113.10", util.RandomEmail(), util.RandomString(6))
+		require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+	}
+
+	rsp := loginUserFromIPForTest(t, server, "203.0.113.10", util.RandomEmail(), util.RandomString(6))
+	require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	requireErrorCode(t, rsp.Body, errCodeTooManyAttempts)
+
+	// the other client isn't locked out by the guesses of the first one
+	rsp = loginUserFromIPForTest(t, server, "198.51.100.20", util.RandomEmail(), util.RandomString(6))
+	require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+}
+
+func TestLoginAdminLockoutAPI(t *testing.T) {
+	admin, password := randomAdminLogin(t)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store, nil, nil, nil)
+
+	store.EXPECT().
+		GetAdminByEmail(gomock.Any(), gomock.Eq(admin.Email)).
+		Times(int(throttle.AccountPolicy.FreeAttempts)+1).
+		Return(admin, nil)
+
+	store.EXPECT().
+		CreateAdminSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	for range throttle.AccountPolicy.FreeAttempts + 1 {
+		rsp := loginAdminRequestForTest(t, server, admin.Email, password+"wrong")
+		require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+	}
+
+	rsp := loginAdminRequestForTest(t, server, admin.Email, password)
+	require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	requireErrorCode(t, rsp.Body, errCodeAccountLocked)
+}
+
+func loginUserRequestForTest(t *testing.T, server *Server, email, password string) *http.Response {
+	return postJSONForTest(t, server, "/api/v1/users/login", fiber.Map{"email": email, "password": password})
+}
+
+// loginUserFromIPForTest logs in through the reverse proxy, which sets the client ip in X-Real-IP
+func loginUserFromIPForTest(t *testing.T, server *Server, ip, email, password string) *http.Response {
+	data, err := json.Marshal(fiber.Map{"email": email, "password": password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(fiber.MethodPost, "/api/v1/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(headerRealIP, ip)
+
+	rsp, err := server.router.Test(request)
+	require.NoError(t, err)
+	return rsp
+}
+
+func loginAdminRequestForTest(t *testing.T, server *Server, email, password string) *http.Response {
+	return postJSONForTest(t, server, "/api/v1/admins/login", fiber.Map{"email": email, "password": password})
+}
+
+func postJSONForTest(t *testing.T, server *Server, url string, body fiber.Map) *http.Response {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(fiber.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+
+	rsp, err := server.router.Test(request)
+	require.NoError(t, err)
+	return rsp
+}
+
+func requireErrorCode(t *testing.T, body io.Reader, code string) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotError map[string]any
+	err = json.Unmarshal(data, &gotError)
+	require.NoError(t, err)
+	require.Equal(t, code, gotError["code"])
+}
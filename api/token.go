@@ -5,12 +5,49 @@ import (
 	"time"
 
 	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	"github.com/gofiber/fiber/v3"
-	"github.com/guregu/null/v6"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
 )
 
+var errRefreshTokenReused = errors.New("refresh token was already used, all sessions of this login were revoked")
+
+/*
+revokeUserSessionFamily blocks every session rotated from the same login,
+
+a rotated refresh token showing up again means it was copied,
+so the legit holder and the attacker both have to login again.
+*/
+func (server *Server) revokeUserSessionFamily(ctx fiber.Ctx, sessionID, familyID uuid.UUID) {
+	revoked, err := server.store.RevokeUserSessionFamily(ctx.Context(), familyID)
+	if err != nil {
+		log.Error().Err(err).Str("session_id", sessionID.String()).Str("family_id", familyID.String()).
+			Msg("failed to revoke user session family")
+		return
+	}
+
+	log.Warn().Str("session_id", sessionID.String()).Str("family_id", familyID.String()).
+		Str("client_ip", ctx.IP()).Str("user_agent", string(ctx.UserAgent())).Int64("revoked_sessions", revoked).
+		Msg("refresh token reuse detected, suspected token theft")
+}
+
+// revokeAdminSessionFamily blocks every admin session rotated from the same login
+func (server *Server) revokeAdminSessionFamily(ctx fiber.Ctx, sessionID, familyID uuid.UUID) {
+	revoked, err := server.store.RevokeAdminSessionFamily(ctx.Context(), familyID)
+	if err != nil {
+		log.Error().Err(err).Str("session_id", sessionID.String()).Str("family_id", familyID.String()).
+			Msg("failed to revoke admin session family")
+		return
+	}
+
+	log.Warn().Str("session_id", sessionID.String()).Str("family_id", familyID.String()).
+		Str("client_ip", ctx.IP()).Str("admin_agent", string(ctx.UserAgent())).Int64("revoked_sessions", revoked).
+		Msg("admin refresh token reuse detected, suspected token theft")
+}
+
 type renewAccessTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
@@ -33,24 +70,12 @@ func (server *Server) renewAccessToken(ctx fiber.Ctx) error {
 	if err != nil {
 		if err.Error() == util.TokenHasExpired {
 			err = errors.New("refresh token has expired")
-
-			userSession, _ := server.store.GetUserSession(ctx.Context(), refreshPayload.ID)
-
-			argUpdate := db.UpdateUserSessionParams{
-				IsBlocked:    null.BoolFrom(true),
-				ID:           userSession.ID,
-				UserID:       userSession.UserID,
-				RefreshToken: userSession.RefreshToken,
-			}
-
-			server.store.UpdateUserSession(ctx.Context(), argUpdate)
-
 		}
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
-	userSession, err := server.store.GetUserSession(ctx.Context(), refreshPayload.ID)
+	userSession, err := server.store.GetUserSession(ctx.Context(), refreshPayload.SessionID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
@@ -60,6 +85,12 @@ func (server *Server) renewAccessToken(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if userSession.ReplacedBy.Valid {
+		server.revokeUserSessionFamily(ctx, userSession.ID, userSession.FamilyID)
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errRefreshTokenReused))
+		return nil
+	}
+
 	if userSession.IsBlocked {
 		err := errors.New("blocked session")
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
@@ -87,6 +118,7 @@ func (server *Server) renewAccessToken(ctx fiber.Ctx) error {
 	accessToken, accessPayload, err := server.userTokenMaker.CreateTokenForUser(
 		refreshPayload.UserID,
 		refreshPayload.Username,
+		userSession.ID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -129,23 +161,12 @@ func (server *Server) renewRefreshToken(ctx fiber.Ctx) error {
 	if err != nil {
 		if err.Error() == util.TokenHasExpired {
 			err = errors.New("refresh token has expired")
-
-			userSession, _ := server.store.GetUserSession(ctx.Context(), refreshPayload.ID)
-
-			argUpdate := db.UpdateUserSessionParams{
-				IsBlocked:    null.BoolFrom(true),
-				ID:           userSession.ID,
-				UserID:       userSession.UserID,
-				RefreshToken: userSession.RefreshToken,
-			}
-
-			server.store.UpdateUserSession(ctx.Context(), argUpdate)
 		}
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
-	userSession, err := server.store.GetUserSession(ctx.Context(), refreshPayload.ID)
+	userSession, err := server.store.GetUserSession(ctx.Context(), refreshPayload.SessionID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
@@ -155,6 +176,12 @@ func (server *Server) renewRefreshToken(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if userSession.ReplacedBy.Valid {
+		server.revokeUserSessionFamily(ctx, userSession.ID, userSession.FamilyID)
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errRefreshTokenReused))
+		return nil
+	}
+
 	if userSession.IsBlocked {
 		err := errors.New("blocked session")
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
@@ -179,22 +206,12 @@ func (server *Server) renewRefreshToken(ctx fiber.Ctx) error {
 	// 	return nil
 	// }
 
-	argUpdate := db.UpdateUserSessionParams{
-		IsBlocked:    null.BoolFrom(true),
-		ID:           userSession.ID,
-		UserID:       userSession.UserID,
-		RefreshToken: userSession.RefreshToken,
-	}
-
-	_, err = server.store.UpdateUserSession(ctx.Context(), argUpdate)
-	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
-	}
+	newSessionID := uuid.New()
 
 	accessToken, accessPayload, err := server.userTokenMaker.CreateTokenForUser(
 		refreshPayload.UserID,
 		refreshPayload.Username,
+		newSessionID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -205,6 +222,7 @@ func (server *Server) renewRefreshToken(ctx fiber.Ctx) error {
 	newRefreshToken, newRefreshPayload, err := server.userTokenMaker.CreateTokenForUser(
 		refreshPayload.UserID,
 		refreshPayload.Username,
+		newSessionID,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
@@ -212,17 +230,27 @@ func (server *Server) renewRefreshToken(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	argCreate := db.CreateUserSessionParams{
-		ID:           newRefreshPayload.ID,
-		UserID:       userSession.UserID,
-		RefreshToken: newRefreshToken,
-		UserAgent:    string(ctx.UserAgent()),
-		ClientIp:     ctx.IP(),
-		ExpiresAt:    newRefreshPayload.ExpiredAt,
+	argRotate := db.RotateUserSessionTxParams{
+		ID: userSession.ID,
+		NewSession: db.CreateUserSessionParams{
+			ID:           newSessionID,
+			UserID:       userSession.UserID,
+			RefreshToken: newRefreshToken,
+			UserAgent:    string(ctx.UserAgent()),
+			ClientIp:     ctx.IP(),
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+			FamilyID:     userSession.FamilyID,
+		},
 	}
 
-	newUserSession, err := server.store.CreateUserSession(ctx.Context(), argCreate)
+	newUserSession, err := server.store.RotateUserSessionTx(ctx.Context(), argRotate)
 	if err != nil {
+		var reusedErr *db.RefreshTokenReusedError
+		if errors.As(err, &reusedErr) {
+			server.revokeUserSessionFamily(ctx, reusedErr.SessionID, reusedErr.FamilyID)
+			ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errRefreshTokenReused))
+			return nil
+		}
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
 		return nil
 	}
@@ -232,7 +260,7 @@ func (server *Server) renewRefreshToken(ctx fiber.Ctx) error {
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          newRefreshToken,
-		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiredAt,
 	}
 	ctx.Status(fiber.StatusOK).JSON(rsp)
 	return nil
@@ -267,7 +295,7 @@ func (server *Server) renewAccessTokenForAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	userSession, err := server.store.GetAdminSession(ctx.Context(), refreshPayload.ID)
+	userSession, err := server.store.GetAdminSession(ctx.Context(), refreshPayload.SessionID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
@@ -277,6 +305,12 @@ func (server *Server) renewAccessTokenForAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if userSession.ReplacedBy.Valid {
+		server.revokeAdminSessionFamily(ctx, userSession.ID, userSession.FamilyID)
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errRefreshTokenReused))
+		return nil
+	}
+
 	if userSession.IsBlocked {
 		err := errors.New("blocked session")
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
@@ -306,6 +340,7 @@ func (server *Server) renewAccessTokenForAdmin(ctx fiber.Ctx) error {
 		refreshPayload.Username,
 		refreshPayload.TypeID,
 		refreshPayload.Active,
+		userSession.ID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -351,7 +386,7 @@ func (server *Server) renewRefreshTokenForAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	userSession, err := server.store.GetAdminSession(ctx.Context(), refreshPayload.ID)
+	userSession, err := server.store.GetAdminSession(ctx.Context(), refreshPayload.SessionID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
@@ -361,6 +396,12 @@ func (server *Server) renewRefreshTokenForAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if userSession.ReplacedBy.Valid {
+		server.revokeAdminSessionFamily(ctx, userSession.ID, userSession.FamilyID)
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errRefreshTokenReused))
+		return nil
+	}
+
 	if userSession.IsBlocked {
 		err := errors.New("blocked session")
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
@@ -385,25 +426,15 @@ func (server *Server) renewRefreshTokenForAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	argUpdate := db.UpdateAdminSessionParams{
-		IsBlocked:    null.BoolFrom(true),
-		ID:           userSession.ID,
-		AdminID:      userSession.AdminID,
-		RefreshToken: userSession.RefreshToken,
-	}
-
-	_, err = server.store.UpdateAdminSession(ctx.Context(), argUpdate)
-	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
-	}
+	newSessionID := uuid.New()
 
 	accessToken, accessPayload, err := server.adminTokenMaker.CreateTokenForAdmin(
 		refreshPayload.AdminID,
 		refreshPayload.Username,
 		refreshPayload.TypeID,
 		refreshPayload.Active,
-		server.config.RefreshTokenDuration,
+		newSessionID,
+		server.config.AccessTokenDuration,
 	)
 	if err != nil {
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
@@ -415,6 +446,7 @@ func (server *Server) renewRefreshTokenForAdmin(ctx fiber.Ctx) error {
 		refreshPayload.Username,
 		refreshPayload.TypeID,
 		refreshPayload.Active,
+		newSessionID,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
@@ -422,17 +454,27 @@ func (server *Server) renewRefreshTokenForAdmin(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	argCreate := db.CreateAdminSessionParams{
-		ID:           newRefreshPayload.ID,
-		AdminID:      userSession.AdminID,
-		RefreshToken: newRefreshToken,
-		AdminAgent:   string(ctx.UserAgent()),
-		ClientIp:     ctx.IP(),
-		ExpiresAt:    newRefreshPayload.ExpiredAt,
+	argRotate := db.RotateAdminSessionTxParams{
+		ID: userSession.ID,
+		NewSession: db.CreateAdminSessionParams{
+			ID:           newSessionID,
+			AdminID:      userSession.AdminID,
+			RefreshToken: newRefreshToken,
+			AdminAgent:   string(ctx.UserAgent()),
+			ClientIp:     ctx.IP(),
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+			FamilyID:     userSession.FamilyID,
+		},
 	}
 
-	newUserSession, err := server.store.CreateAdminSession(ctx.Context(), argCreate)
+	newUserSession, err := server.store.RotateAdminSessionTx(ctx.Context(), argRotate)
 	if err != nil {
+		var reusedErr *db.RefreshTokenReusedError
+		if errors.As(err, &reusedErr) {
+			server.revokeAdminSessionFamily(ctx, reusedErr.SessionID, reusedErr.FamilyID)
+			ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errRefreshTokenReused))
+			return nil
+		}
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
 		return nil
 	}
@@ -442,8 +484,26 @@ func (server *Server) renewRefreshTokenForAdmin(ctx fiber.Ctx) error {
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          newRefreshToken,
-		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiredAt,
 	}
 	ctx.Status(fiber.StatusOK).JSON(rsp)
 	return nil
 }
+
+type listUserTokenKeysResponse struct {
+	Keys []token.PublicKey `json:"keys"`
+}
+
+// listUserTokenKeys publishes the public keys verifying the user tokens, so other services can check them
+// without the secret key, there is nothing to publish when the tokens are symmetric
+func (server *Server) listUserTokenKeys(ctx fiber.Ctx) error {
+	maker, ok := server.userTokenMaker.(*token.PasetoPublicMaker)
+	if !ok {
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(errors.New("user tokens aren't signed with public keys")))
+		return nil
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	ctx.Status(fiber.StatusOK).JSON(listUserTokenKeysResponse{Keys: maker.Keyring().PublicKeys()})
+	return nil
+}
@@ -0,0 +1,516 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRenewRefreshTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		updateSession func(session *db.UserSession)
+		buildStubs    func(store *mockdb.MockStore, session *db.UserSession)
+		checkResponse func(rsp *http.Response, session *db.UserSession)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateUserSessionTxParams) (*db.UserSession, error) {
+						require.Equal(t, session.ID, arg.ID)
+						require.Equal(t, session.FamilyID, arg.NewSession.FamilyID)
+						require.NotEqual(t, session.ID, arg.NewSession.ID)
+
+						return &db.UserSession{
+							ID:           arg.NewSession.ID,
+							UserID:       arg.NewSession.UserID,
+							RefreshToken: arg.NewSession.RefreshToken,
+							FamilyID:     arg.NewSession.FamilyID,
+						}, nil
+					})
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotResponse renewRefreshTokenResponse
+				err = json.Unmarshal(data, &gotResponse)
+				require.NoError(t, err)
+				require.NotEqual(t, session.ID.String(), gotResponse.UserSessionID)
+				require.NotEqual(t, session.RefreshToken, gotResponse.RefreshToken)
+			},
+		},
+		{
+			name: "ReusedToken",
+			updateSession: func(session *db.UserSession) {
+				session.IsBlocked = true
+				session.ReplacedBy = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+			},
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(2), nil)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ConcurrentReuse",
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.RefreshTokenReusedError{SessionID: session.ID, FamilyID: session.FamilyID})
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "BlockedSession",
+			updateSession: func(session *db.UserSession) {
+				session.IsBlocked = true
+			},
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			updateSession: func(session *db.UserSession) {
+				session.RefreshToken = util.RandomString(32)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RotateUserSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response, session *db.UserSession) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+
+			// the refresh token is signed by the key of the test server
+			server := newTestServer(t, store, nil, nil, nil)
+
+			refreshToken, refreshPayload, err := server.userTokenMaker.CreateTokenForUser(user.ID, user.Username, uuid.New(), time.Minute)
+			require.NoError(t, err)
+
+			session := &db.UserSession{
+				ID:           refreshPayload.SessionID,
+				UserID:       user.ID,
+				RefreshToken: refreshToken,
+				ExpiresAt:    refreshPayload.ExpiredAt,
+				FamilyID:     uuid.New(),
+			}
+			if tc.updateSession != nil {
+				tc.updateSession(session)
+			}
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(fiber.Map{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(fiber.MethodPost, "/api/v1/auth/refresh-token", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp, session)
+		})
+	}
+}
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		updateSession func(session *db.UserSession)
+		buildStubs    func(store *mockdb.MockStore, session *db.UserSession)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ReusedToken",
+			updateSession: func(session *db.UserSession) {
+				session.IsBlocked = true
+				session.ReplacedBy = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+			},
+			buildStubs: func(store *mockdb.MockStore, session *db.UserSession) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RevokeUserSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			refreshToken, refreshPayload, err := server.userTokenMaker.CreateTokenForUser(user.ID, user.Username, uuid.New(), time.Minute)
+			require.NoError(t, err)
+
+			session := &db.UserSession{
+				ID:           refreshPayload.SessionID,
+				UserID:       user.ID,
+				RefreshToken: refreshToken,
+				ExpiresAt:    refreshPayload.ExpiredAt,
+				FamilyID:     uuid.New(),
+			}
+			if tc.updateSession != nil {
+				tc.updateSession(session)
+			}
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(fiber.Map{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(fiber.MethodPost, "/api/v1/auth/access-token", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestRenewRefreshTokenForAdminAPI(t *testing.T) {
+	admin, _ := randomPSuperAdmin(t)
+
+	testCases := []struct {
+		name          string
+		updateSession func(session *db.AdminSession)
+		buildStubs    func(store *mockdb.MockStore, session *db.AdminSession)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session *db.AdminSession) {
+				store.EXPECT().
+					GetAdminSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RotateAdminSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateAdminSessionTxParams) (*db.AdminSession, error) {
+						require.Equal(t, session.ID, arg.ID)
+						require.Equal(t, session.FamilyID, arg.NewSession.FamilyID)
+
+						return &db.AdminSession{
+							ID:           arg.NewSession.ID,
+							AdminID:      arg.NewSession.AdminID,
+							RefreshToken: arg.NewSession.RefreshToken,
+							FamilyID:     arg.NewSession.FamilyID,
+						}, nil
+					})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ReusedToken",
+			updateSession: func(session *db.AdminSession) {
+				session.IsBlocked = true
+				session.ReplacedBy = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+			},
+			buildStubs: func(store *mockdb.MockStore, session *db.AdminSession) {
+				store.EXPECT().
+					GetAdminSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RevokeAdminSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(3), nil)
+
+				store.EXPECT().
+					RotateAdminSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ConcurrentReuse",
+			buildStubs: func(store *mockdb.MockStore, session *db.AdminSession) {
+				store.EXPECT().
+					GetAdminSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					RotateAdminSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &db.RefreshTokenReusedError{SessionID: session.ID, FamilyID: session.FamilyID})
+
+				store.EXPECT().
+					RevokeAdminSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			refreshToken, refreshPayload, err := server.adminTokenMaker.CreateTokenForAdmin(admin.ID, admin.Username, admin.TypeID, admin.Active, uuid.New(), time.Minute)
+			require.NoError(t, err)
+
+			session := &db.AdminSession{
+				ID:           refreshPayload.SessionID,
+				AdminID:      admin.ID,
+				RefreshToken: refreshToken,
+				ExpiresAt:    refreshPayload.ExpiredAt,
+				FamilyID:     uuid.New(),
+			}
+			if tc.updateSession != nil {
+				tc.updateSession(session)
+			}
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(fiber.Map{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(fiber.MethodPost, "/api/v1/auth/refresh-token-for-admin", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestListUserTokenKeysAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		keys          string
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			keys: fmt.Sprintf("k2:%s,k1:%s", paseto.NewV4AsymmetricSecretKey().ExportHex(), paseto.NewV4AsymmetricSecretKey().ExportHex()),
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotKeys listUserTokenKeysResponse
+				err = json.Unmarshal(data, &gotKeys)
+				require.NoError(t, err)
+				require.Len(t, gotKeys.Keys, 2)
+				require.Equal(t, "k2", gotKeys.Keys[0].ID)
+				require.True(t, gotKeys.Keys[0].Current)
+				require.Equal(t, "k1", gotKeys.Keys[1].ID)
+				require.False(t, gotKeys.Keys[1].Current)
+
+				for _, key := range gotKeys.Keys {
+					_, err := paseto.NewV4AsymmetricPublicKeyFromHex(key.Key)
+					require.NoError(t, err)
+				}
+			},
+		},
+		{
+			name: "SymmetricTokens",
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store, nil, nil, nil)
+			if tc.keys != "" {
+				maker, err := newTokenMaker(tc.keys, "")
+				require.NoError(t, err)
+				server.userTokenMaker = maker
+			}
+
+			request, err := http.NewRequest(fiber.MethodGet, "/api/v1/auth/user-token-keys", nil)
+			require.NoError(t, err)
+
+			rsp, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(rsp)
+		})
+	}
+}
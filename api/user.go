@@ -5,6 +5,7 @@ import (
 	"time"
 
 	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	"github.com/gofiber/fiber/v3"
@@ -16,6 +17,13 @@ import (
 
 const veifyYourEmailSubject = "Verify your email"
 
+// newOTP returns an otp to email and its hash to store, otps are checked like passwords
+func newOTP() (otp string, hashedOTP string, err error) {
+	otp = secure.OTP()
+	hashedOTP, err = util.HashPassword(otp)
+	return otp, hashedOTP, err
+}
+
 //////////////* Create API //////////////
 
 type createUserRequest struct {
@@ -102,9 +110,12 @@ func (server *Server) createUser(ctx fiber.Ctx) error {
 		return err
 	}
 
+	sessionID := uuid.New()
+
 	accessToken, accessPayload, err := server.userTokenMaker.CreateTokenForUser(
 		user.ID,
 		user.Username,
+		sessionID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -115,6 +126,7 @@ func (server *Server) createUser(ctx fiber.Ctx) error {
 	refreshToken, refreshPayload, err := server.userTokenMaker.CreateTokenForUser(
 		user.ID,
 		user.Username,
+		sessionID,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
@@ -123,12 +135,14 @@ func (server *Server) createUser(ctx fiber.Ctx) error {
 	}
 
 	arg1 := db.CreateUserSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		UserID:       user.ID,
 		RefreshToken: refreshToken,
 		UserAgent:    string(ctx.UserAgent()),
 		ClientIp:     ctx.IP(),
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		// a login starts a new session family
+		FamilyID: sessionID,
 	}
 
 	userSession, err := server.store.CreateUserSession(ctx.Context(), arg1)
@@ -283,11 +297,58 @@ func (server *Server) verifyOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if server.throttled(ctx, throttleScopeVerifyEmail, req.Email) {
+		return nil
+	}
+
+	verifyEmail, err := server.store.GetVerifyEmailByEmail(ctx.Context(), req.Email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			server.recordFailedAttempt(ctx, throttleScopeVerifyEmail, req.Email)
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if verifyEmail.IsEmailVerified {
+		ctx.Status(fiber.StatusConflict).JSON(errorResponse(errors.New("email already verified")))
+		return nil
+	}
+
+	if verifyEmail.Attempts >= maxOTPAttempts {
+		ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+		return nil
+	}
+
+	if err := util.CheckPassword(req.OTP, verifyEmail.SecretCode); err != nil {
+		server.recordFailedAttempt(ctx, throttleScopeVerifyEmail, req.Email)
+
+		// the wrong code counts against the latest otp of the email, it expires after maxOTPAttempts
+		verifyEmail, err := server.store.IncrementVerifyEmailAttempts(ctx.Context(), db.IncrementVerifyEmailAttemptsParams{
+			MaxAttempts: maxOTPAttempts,
+			Email:       req.Email,
+		})
+		if err != nil && err != pgx.ErrNoRows {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+		if err == nil && verifyEmail.Attempts >= maxOTPAttempts {
+			ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+			return nil
+		}
+
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+		return nil
+	}
+
 	arg := db.UpdateVerifyEmailParams{
-		Email:      req.Email,
-		SecretCode: req.OTP,
+		Email: req.Email,
+		ID:    verifyEmail.ID,
 	}
 
+	// the otp of the row can still be used or expired
 	user, err := server.store.UpdateVerifyEmail(ctx.Context(), arg)
 	if err != nil {
 		if pqErr, ok := err.(*pgconn.PgError); ok {
@@ -310,9 +371,14 @@ func (server *Server) verifyOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	server.resetFailedAttempts(ctx, throttleScopeVerifyEmail, req.Email)
+
+	sessionID := uuid.New()
+
 	accessToken, accessPayload, err := server.userTokenMaker.CreateTokenForUser(
 		user.ID,
 		user.Username,
+		sessionID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -323,6 +389,7 @@ func (server *Server) verifyOTP(ctx fiber.Ctx) error {
 	refreshToken, refreshPayload, err := server.userTokenMaker.CreateTokenForUser(
 		user.ID,
 		user.Username,
+		sessionID,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
@@ -331,12 +398,14 @@ func (server *Server) verifyOTP(ctx fiber.Ctx) error {
 	}
 
 	arg1 := db.CreateUserSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		UserID:       user.ID,
 		RefreshToken: refreshToken,
 		UserAgent:    string(ctx.UserAgent()),
 		ClientIp:     ctx.IP(),
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		// a login starts a new session family
+		FamilyID: sessionID,
 	}
 
 	userSession, err := server.store.CreateUserSession(ctx.Context(), arg1)
@@ -381,8 +450,6 @@ func (server *Server) resendOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	var secretCode string
-
 	// check if user already exists
 	checkUser, err := server.store.GetVerifyEmailByEmail(ctx.Context(), req.Email)
 	if err != nil {
@@ -402,20 +469,21 @@ func (server *Server) resendOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	if !checkUser.IsUsed && time.Now().Before(checkUser.ExpiredAt) {
-		secretCode = checkUser.SecretCode
-	} else {
-		secretCode = util.GenerateOTP()
+	// only the hash of the last otp is stored so a new one is sent every time
+	secretCode, hashedSecretCode, err := newOTP()
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
 
-		arg := db.CreateVerifyEmailParams{
-			UserID:     checkUser.UserID,
-			SecretCode: secretCode,
-		}
-		_, err := server.store.CreateVerifyEmail(ctx.Context(), arg)
-		if err != nil {
-			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-			return nil
-		}
+	arg := db.CreateVerifyEmailParams{
+		UserID:     checkUser.UserID,
+		SecretCode: hashedSecretCode,
+	}
+	_, err = server.store.CreateVerifyEmail(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
 	}
 
 	// send email
@@ -448,7 +516,6 @@ type resetPasswordRequestJsonRequest struct {
 func (server *Server) resetPasswordRequest(ctx fiber.Ctx) error {
 	req := &resetPasswordRequestJsonRequest{}
 
-	var secretCode string
 	if err := server.parseAndValidate(ctx, Input{req: req}); err != nil {
 		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
 		return nil
@@ -472,18 +539,22 @@ func (server *Server) resetPasswordRequest(ctx fiber.Ctx) error {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
 		return nil
-	} else {
-		secretCode = util.GenerateOTP()
+	}
 
-		arg := db.CreateResetPasswordParams{
-			UserID:     user.ID,
-			SecretCode: secretCode,
-		}
-		_, err := server.store.CreateResetPassword(ctx.Context(), arg)
-		if err != nil {
-			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-			return nil
-		}
+	secretCode, hashedSecretCode, err := newOTP()
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.CreateResetPasswordParams{
+		UserID:     user.ID,
+		SecretCode: hashedSecretCode,
+	}
+	_, err = server.store.CreateResetPassword(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
 	}
 
 	// send email
@@ -534,9 +605,14 @@ func (server *Server) verifyResetPasswordOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if server.throttled(ctx, throttleScopeResetPassword, req.Email) {
+		return nil
+	}
+
 	resetPassword, err := server.store.GetResetPasswordsByEmail(ctx.Context(), req.Email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
+			server.recordFailedAttempt(ctx, throttleScopeResetPassword, req.Email)
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
 			return nil
 		}
@@ -555,12 +631,34 @@ func (server *Server) verifyResetPasswordOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	arg := db.UpdateResetPasswordParams{
-		ID:         resetPassword.ID,
-		SecretCode: req.OTP,
+	if resetPassword.Attempts >= maxOTPAttempts {
+		ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+		return nil
+	}
+
+	if err := util.CheckPassword(req.OTP, resetPassword.SecretCode); err != nil {
+		server.recordFailedAttempt(ctx, throttleScopeResetPassword, req.Email)
+
+		// the otp expires after maxOTPAttempts wrong codes
+		resetPassword, err := server.store.IncrementResetPasswordAttempts(ctx.Context(), db.IncrementResetPasswordAttemptsParams{
+			MaxAttempts: maxOTPAttempts,
+			ID:          resetPassword.ID,
+		})
+		if err != nil && err != pgx.ErrNoRows {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+		if err == nil && resetPassword.Attempts >= maxOTPAttempts {
+			ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+			return nil
+		}
+
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+		return nil
 	}
 
-	_, err = server.store.UpdateResetPassword(ctx.Context(), arg)
+	// the otp of the row can still be used or expired
+	_, err = server.store.UpdateResetPassword(ctx.Context(), resetPassword.ID)
 	if err != nil {
 		if pqErr, ok := err.(*pgconn.PgError); ok {
 			switch pqErr.Message {
@@ -577,6 +675,8 @@ func (server *Server) verifyResetPasswordOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	server.resetFailedAttempts(ctx, throttleScopeResetPassword, req.Email)
+
 	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
 	return nil
 }
@@ -595,7 +695,6 @@ func (server *Server) resendResetPasswordOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	var secretCode string
 	// check if user already exists
 	checkUser, err := server.store.GetResetPasswordsByEmail(ctx.Context(), req.Email)
 	if err != nil {
@@ -616,20 +715,21 @@ func (server *Server) resendResetPasswordOTP(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	if !checkUser.IsUsed && time.Now().Before(checkUser.ExpiredAt) {
-		secretCode = checkUser.SecretCode
-	} else {
-		secretCode = util.GenerateOTP()
+	// only the hash of the last otp is stored so a new one is sent every time
+	secretCode, hashedSecretCode, err := newOTP()
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
 
-		arg := db.CreateResetPasswordParams{
-			UserID:     checkUser.UserID,
-			SecretCode: secretCode,
-		}
-		_, err := server.store.CreateResetPassword(ctx.Context(), arg)
-		if err != nil {
-			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-			return nil
-		}
+	arg := db.CreateResetPasswordParams{
+		UserID:     checkUser.UserID,
+		SecretCode: hashedSecretCode,
+	}
+	_, err = server.store.CreateResetPassword(ctx.Context(), arg)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
 	}
 
 	// send email
@@ -696,7 +796,7 @@ func (server *Server) resetPasswordApproved(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	if lastUsedPasswordReset.SecretCode == req.OTP {
+	if util.CheckPassword(req.OTP, lastUsedPasswordReset.SecretCode) == nil {
 
 		getUser, err := server.store.GetUserByEmail(ctx.Context(), req.Email)
 		if err != nil {
@@ -925,7 +1025,7 @@ func (server *Server) searchUserByEmailForAdmin(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
 		return nil
@@ -974,7 +1074,7 @@ func (server *Server) listUsers(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
 		return nil
@@ -1075,7 +1175,7 @@ func (server *Server) adminUpdateUser(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
@@ -1100,6 +1200,15 @@ func (server *Server) adminUpdateUser(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	// a blocked user is logged out of every device right away instead of when the access token expires
+	if user.IsBlocked {
+		_, err = server.store.RevokeUserSessions(ctx.Context(), user.ID)
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+	}
+
 	rsp := newUserResponse(*user)
 	ctx.Status(fiber.StatusOK).JSON(rsp)
 	return nil
@@ -1121,7 +1230,7 @@ func (server *Server) deleteUser(ctx fiber.Ctx) error {
 	}
 
 	authPayload := ctx.Locals(authorizationAdminPayloadKey).(*token.AdminPayload)
-	if authPayload.AdminID != params.AdminID || authPayload.TypeID != 1 || !authPayload.Active {
+	if authPayload.AdminID != params.AdminID || !authPayload.Active {
 		err := errors.New("account unauthorized")
 		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
 		return nil
@@ -1175,6 +1284,56 @@ func newUserLoginResponse(user db.GetUserByEmailRow) userResponse {
 	}
 }
 
+// startUserSession starts a new session family for the user and issues its access and refresh tokens
+func (server *Server) startUserSession(ctx fiber.Ctx, user userResponse) (*createUserResponse, error) {
+	sessionID := uuid.New()
+
+	accessToken, accessPayload, err := server.userTokenMaker.CreateTokenForUser(
+		user.UserID,
+		user.Username,
+		sessionID,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshPayload, err := server.userTokenMaker.CreateTokenForUser(
+		user.UserID,
+		user.Username,
+		sessionID,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.CreateUserSessionParams{
+		ID:           sessionID,
+		UserID:       user.UserID,
+		RefreshToken: refreshToken,
+		UserAgent:    string(ctx.UserAgent()),
+		ClientIp:     ctx.IP(),
+		ExpiresAt:    refreshPayload.ExpiredAt,
+		// a login starts a new session family
+		FamilyID: sessionID,
+	}
+
+	userSession, err := server.store.CreateUserSession(ctx.Context(), arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createUserResponse{
+		UserSessionID:         userSession.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  user,
+	}, nil
+}
+
 func (server *Server) loginUser(ctx fiber.Ctx) error {
 	req := &loginUserRequest{}
 
@@ -1183,9 +1342,14 @@ func (server *Server) loginUser(ctx fiber.Ctx) error {
 		return nil
 	}
 
+	if server.throttled(ctx, throttleScopeUserLogin, req.Email) {
+		return nil
+	}
+
 	user, err := server.store.GetUserByEmail(ctx.Context(), req.Email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
+			server.recordFailedAttempt(ctx, throttleScopeUserLogin, req.Email)
 			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
 			return nil
 		}
@@ -1206,13 +1370,14 @@ func (server *Server) loginUser(ctx fiber.Ctx) error {
 
 	err = util.CheckPassword(req.Password, user.Password)
 	if err != nil {
+		server.recordFailedAttempt(ctx, throttleScopeUserLogin, req.Email)
 		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
 		return nil
 	}
 
-	if !user.IsEmailVerified {
-		var secretCode string
+	server.resetFailedAttempts(ctx, throttleScopeUserLogin, req.Email)
 
+	if !user.IsEmailVerified {
 		// check if user already exists
 		checkUser, err := server.store.GetVerifyEmailByEmail(ctx.Context(), req.Email)
 		if err != nil {
@@ -1228,20 +1393,21 @@ func (server *Server) loginUser(ctx fiber.Ctx) error {
 			return nil
 		}
 
-		if !checkUser.IsUsed && time.Now().Before(checkUser.ExpiredAt) {
-			secretCode = checkUser.SecretCode
-		} else {
-			secretCode = util.GenerateOTP()
+		// only the hash of the last otp is stored so a new one is sent every time
+		secretCode, hashedSecretCode, err := newOTP()
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
 
-			arg := db.CreateVerifyEmailParams{
-				UserID:     checkUser.UserID,
-				SecretCode: secretCode,
-			}
-			_, err := server.store.CreateVerifyEmail(ctx.Context(), arg)
-			if err != nil {
-				ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-				return nil
-			}
+		arg := db.CreateVerifyEmailParams{
+			UserID:     checkUser.UserID,
+			SecretCode: hashedSecretCode,
+		}
+		_, err = server.store.CreateVerifyEmail(ctx.Context(), arg)
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
 		}
 
 		// send email
@@ -1275,49 +1441,11 @@ func (server *Server) loginUser(ctx fiber.Ctx) error {
 		return nil
 	}
 
-	accessToken, accessPayload, err := server.userTokenMaker.CreateTokenForUser(
-		user.ID,
-		user.Username,
-		server.config.AccessTokenDuration,
-	)
-	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
-	}
-
-	refreshToken, refreshPayload, err := server.userTokenMaker.CreateTokenForUser(
-		user.ID,
-		user.Username,
-		server.config.RefreshTokenDuration,
-	)
-	if err != nil {
-		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
-		return nil
-	}
-
-	arg := db.CreateUserSessionParams{
-		ID:           refreshPayload.ID,
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-		UserAgent:    string(ctx.UserAgent()),
-		ClientIp:     ctx.IP(),
-		ExpiresAt:    refreshPayload.ExpiredAt,
-	}
-
-	userSession, err := server.store.CreateUserSession(ctx.Context(), arg)
+	rsp, err := server.startUserSession(ctx, newUserLoginResponse(*user))
 	if err != nil {
 		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
 		return nil
 	}
-
-	rsp := createUserResponse{
-		UserSessionID:         userSession.ID.String(),
-		AccessToken:           accessToken,
-		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
-		RefreshToken:          refreshToken,
-		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
-		User:                  newUserLoginResponse(*user),
-	}
 	ctx.Status(fiber.StatusOK).JSON(rsp)
 	return nil
 }
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const userExportFileName = "cshop-export.json"
+
+var errUserDeleted = errors.New("the account was already deleted")
+
+type userDeletionResponse struct {
+	UserID      int64     `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+	// DeleteAfter is when the personal data gets anonymized, the deletion can be cancelled until then
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+func newUserDeletionResponse(userDeletion *db.UserDeletion) userDeletionResponse {
+	return userDeletionResponse{
+		UserID:      userDeletion.UserID,
+		RequestedAt: userDeletion.RequestedAt,
+		DeleteAfter: userDeletion.DeleteAfter,
+	}
+}
+
+// //////////////* Request User Deletion API //////////////
+
+type requestUserDeletionParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+type requestUserDeletionJsonRequest struct {
+	Password string `json:"password" validate:"required_without=IDToken"`
+	// IDToken of a fresh Firebase sign-in confirms the accounts made by a social sign-in
+	IDToken string `json:"id_token" validate:"required_without=Password"`
+}
+
+/*
+requestUserDeletion schedules the deletion of the account once the password is confirmed,
+or the ID token of a Firebase sign-in made moments ago for the accounts without a password,
+
+the personal data is anonymized by the worker after the grace period and the shop orders are kept for accounting.
+A new request of a pending deletion restarts its grace period.
+*/
+func (server *Server) requestUserDeletion(ctx fiber.Ctx) error {
+	params := &requestUserDeletionParamsRequest{}
+	req := &requestUserDeletionJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	throttleAccount := strconv.FormatInt(authPayload.UserID, 10)
+	if server.throttled(ctx, throttleScopeUserDeletion, throttleAccount) {
+		return nil
+	}
+
+	user, err := server.store.GetUser(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if req.Password != "" {
+		err = util.CheckPassword(req.Password, user.Password)
+	} else {
+		if server.firebaseAuth == nil {
+			ctx.Status(fiber.StatusServiceUnavailable).JSON(errorResponse(errFirebaseLoginDisabled))
+			return nil
+		}
+		err = server.checkFirebaseReauth(ctx.Context(), req.IDToken, user.Email)
+	}
+	if err != nil {
+		server.recordFailedAttempt(ctx, throttleScopeUserDeletion, throttleAccount)
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	server.resetFailedAttempts(ctx, throttleScopeUserDeletion, throttleAccount)
+
+	arg := db.UpsertUserDeletionParams{
+		UserID:      user.ID,
+		DeleteAfter: time.Now().Add(server.config.DeletionGracePeriod),
+	}
+
+	userDeletion, err := server.store.UpsertUserDeletion(ctx.Context(), arg)
+	if err != nil {
+		// the upsert skips a deletion that was already carried out
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errUserDeleted))
+			return nil
+		}
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Message {
+			case util.ForeignKeyViolation, util.UniqueViolation:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp := newUserDeletionResponse(userDeletion)
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+// //////////////* Cancel User Deletion API //////////////
+
+type cancelUserDeletionParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+// cancelUserDeletion keeps the account when it's called during the grace period
+func (server *Server) cancelUserDeletion(ctx fiber.Ctx) error {
+	params := &cancelUserDeletionParamsRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	_, err := server.store.CancelUserDeletion(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+// //////////////* Export User Data API //////////////
+
+type exportUserDataParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+type exportUserDataQueryRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=json zip"`
+}
+
+type userProfileExport struct {
+	ID               int64     `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	DefaultPayment   null.Int  `json:"default_payment"`
+	DefaultAddressID null.Int  `json:"default_address_id"`
+	IsEmailVerified  bool      `json:"is_email_verified"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type shopOrderExport struct {
+	*db.ShopOrder
+	Items []*db.ShopOrderItem `json:"items"`
+}
+
+// userDataExport holds the personal data of a user, the password hash and the refresh tokens are left out
+type userDataExport struct {
+	ExportedAt time.Time                          `json:"exported_at"`
+	Profile    userProfileExport                  `json:"profile"`
+	Deletion   *userDeletionResponse              `json:"deletion"`
+	Addresses  []*db.ListAddressesByUserIDRow     `json:"addresses"`
+	Orders     []shopOrderExport                  `json:"orders"`
+	Reviews    []*db.UserReview                   `json:"reviews"`
+	WishList   []*db.ListWishListItemsByUserIDRow `json:"wish_list"`
+	Sessions   []sessionResponse                  `json:"sessions"`
+}
+
+// exportUserData returns the personal data of the user as json, or as a zip archive with ?format=zip
+func (server *Server) exportUserData(ctx fiber.Ctx) error {
+	params := &exportUserDataParamsRequest{}
+	query := &exportUserDataQueryRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, query: query}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	export, err := server.collectUserData(ctx, authPayload)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if query.Format != "zip" {
+		ctx.Status(fiber.StatusOK).JSON(export)
+		return nil
+	}
+
+	archive, err := newUserExportArchive(export)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Attachment("cshop-export-" + strconv.FormatInt(authPayload.UserID, 10) + ".zip")
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Status(fiber.StatusOK).Send(archive)
+	return nil
+}
+
+func (server *Server) collectUserData(ctx fiber.Ctx, authPayload *token.UserPayload) (*userDataExport, error) {
+	user, err := server.store.GetUser(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &userDataExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: userProfileExport{
+			ID:               user.ID,
+			Username:         user.Username,
+			Email:            user.Email,
+			DefaultPayment:   user.DefaultPayment,
+			DefaultAddressID: user.DefaultAddressID,
+			IsEmailVerified:  user.IsEmailVerified,
+			CreatedAt:        user.CreatedAt,
+			UpdatedAt:        user.UpdatedAt,
+		},
+	}
+
+	userDeletion, err := server.store.GetUserDeletion(ctx.Context(), user.ID)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+	if err == nil {
+		deletion := newUserDeletionResponse(userDeletion)
+		export.Deletion = &deletion
+	}
+
+	export.Addresses, err = server.store.ListAddressesByUserID(ctx.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := server.store.ListShopOrdersForExport(ctx.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderItems, err := server.store.ListShopOrderItemsForExport(ctx.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByOrder := make(map[int64][]*db.ShopOrderItem, len(orders))
+	for _, item := range orderItems {
+		itemsByOrder[item.OrderID] = append(itemsByOrder[item.OrderID], item)
+	}
+
+	export.Orders = make([]shopOrderExport, 0, len(orders))
+	for _, order := range orders {
+		items := itemsByOrder[order.ID]
+		if items == nil {
+			items = []*db.ShopOrderItem{}
+		}
+		export.Orders = append(export.Orders, shopOrderExport{ShopOrder: order, Items: items})
+	}
+
+	export.Reviews, err = server.store.ListUserReviewsForExport(ctx.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.WishList, err = server.store.ListWishListItemsByUserID(ctx.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := server.store.ListUserSessionsForExport(ctx.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Sessions = newUserSessionResponses(sessions, authPayload.SessionID)
+
+	return export, nil
+}
+
+func newUserExportArchive(export *userDataExport) ([]byte, error) {
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+
+	file, err := archive.Create(userExportFileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(content); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,461 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestUserDeletionAPI(t *testing.T) {
+	user, password := randomUser(t)
+	idToken := util.RandomString(32)
+	claims := map[string]any{"email": user.Email, "email_verified": true}
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		body          fiber.Map
+		firebaseAuth  *fakeFirebaseVerifier
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			UserID: user.ID,
+			body:   fiber.Map{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.UpsertUserDeletionParams) (*db.UserDeletion, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.WithinDuration(t, time.Now().Add(util.DefaultDeletionGracePeriod), arg.DeleteAfter, time.Minute)
+						return &db.UserDeletion{UserID: arg.UserID, RequestedAt: time.Now(), DeleteAfter: arg.DeleteAfter}, nil
+					})
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				var gotDeletion userDeletionResponse
+				err := json.NewDecoder(rsp.Body).Decode(&gotDeletion)
+				require.NoError(t, err)
+				require.Equal(t, user.ID, gotDeletion.UserID)
+			},
+		},
+		{
+			name:   "WrongPassword",
+			UserID: user.ID,
+			body:   fiber.Map{"password": "wrong-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:         "FirebaseReauth",
+			UserID:       user.ID,
+			body:         fiber.Map{"id_token": idToken},
+			firebaseAuth: &fakeFirebaseVerifier{idToken: idToken, claims: claims, authTime: time.Now()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&db.UserDeletion{UserID: user.ID, RequestedAt: time.Now(), DeleteAfter: time.Now()}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:         "FirebaseSignInTooOld",
+			UserID:       user.ID,
+			body:         fiber.Map{"id_token": idToken},
+			firebaseAuth: &fakeFirebaseVerifier{idToken: idToken, claims: claims, authTime: time.Now().Add(-time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "FirebaseOtherAccount",
+			UserID: user.ID,
+			body:   fiber.Map{"id_token": idToken},
+			firebaseAuth: &fakeFirebaseVerifier{
+				idToken:  idToken,
+				claims:   map[string]any{"email": util.RandomEmail(), "email_verified": true},
+				authTime: time.Now(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "FirebaseDisabled",
+			UserID: user.ID,
+			body:   fiber.Map{"id_token": idToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusServiceUnavailable, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "AlreadyDeleted",
+			UserID: user.ID,
+			body:   fiber.Map{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpsertUserDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "MissingPassword",
+			UserID: user.ID,
+			body:   fiber.Map{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID + 1,
+			body:   fiber.Map{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+			// only the cases with a verifier have firebase sign-in configured
+			server.firebaseAuth = nil
+			if tc.firebaseAuth != nil {
+				server.firebaseAuth = tc.firebaseAuth
+			}
+
+			url := fmt.Sprintf("/usr/v1/users/%d", tc.UserID)
+			rsp := userJSONRequestForTest(t, server, user, uuid.New(), fiber.MethodDelete, url, tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestCancelUserDeletionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			UserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CancelUserDeletion(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(&db.UserDeletion{UserID: user.ID}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "NotFound",
+			UserID: user.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CancelUserDeletion(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CancelUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/deletion", tc.UserID)
+			rsp := userJSONRequestForTest(t, server, user, uuid.New(), fiber.MethodDelete, url, nil)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestExportUserDataAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	sessionID := uuid.New()
+
+	order := &db.ShopOrder{ID: util.RandomMoney(), UserID: user.ID, AddressLine: util.RandomString(10)}
+	orderItem := &db.ShopOrderItem{ID: util.RandomMoney(), OrderID: order.ID}
+	session := &db.UserSession{ID: sessionID, UserID: user.ID, RefreshToken: util.RandomString(32)}
+
+	buildStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return(user, nil)
+
+		store.EXPECT().
+			GetUserDeletion(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return(nil, pgx.ErrNoRows)
+
+		store.EXPECT().
+			ListAddressesByUserID(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return([]*db.ListAddressesByUserIDRow{}, nil)
+
+		store.EXPECT().
+			ListShopOrdersForExport(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return([]*db.ShopOrder{order}, nil)
+
+		store.EXPECT().
+			ListShopOrderItemsForExport(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return([]*db.ShopOrderItem{orderItem}, nil)
+
+		store.EXPECT().
+			ListUserReviewsForExport(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return([]*db.UserReview{}, nil)
+
+		store.EXPECT().
+			ListWishListItemsByUserID(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return([]*db.ListWishListItemsByUserIDRow{}, nil)
+
+		store.EXPECT().
+			ListUserSessionsForExport(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return([]*db.UserSession{session}, nil)
+	}
+
+	// requireExport checks the export holds the data of the user without the secrets
+	requireExport := func(t *testing.T, data []byte) {
+		require.NotContains(t, string(data), user.Password)
+		require.NotContains(t, string(data), session.RefreshToken)
+
+		var export userDataExport
+		err := json.Unmarshal(data, &export)
+		require.NoError(t, err)
+		require.Equal(t, user.Email, export.Profile.Email)
+		require.Nil(t, export.Deletion)
+		require.Len(t, export.Orders, 1)
+		require.Equal(t, order.AddressLine, export.Orders[0].AddressLine)
+		require.Len(t, export.Orders[0].Items, 1)
+		require.Len(t, export.Sessions, 1)
+		require.True(t, export.Sessions[0].Current)
+	}
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		format        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:       "JSON",
+			UserID:     user.ID,
+			buildStubs: buildStubs,
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+				requireExport(t, data)
+			},
+		},
+		{
+			name:       "ZIP",
+			UserID:     user.ID,
+			format:     "zip",
+			buildStubs: buildStubs,
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				require.Equal(t, "application/zip", rsp.Header.Get(fiber.HeaderContentType))
+				require.Contains(t, rsp.Header.Get(fiber.HeaderContentDisposition), "attachment")
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+				require.NoError(t, err)
+				require.Len(t, archive.File, 1)
+				require.Equal(t, userExportFileName, archive.File[0].Name)
+
+				file, err := archive.File[0].Open()
+				require.NoError(t, err)
+				defer file.Close()
+
+				content, err := io.ReadAll(file)
+				require.NoError(t, err)
+				requireExport(t, content)
+			},
+		},
+		{
+			name:   "InvalidFormat",
+			UserID: user.ID,
+			format: "xml",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/export", tc.UserID)
+			if tc.format != "" {
+				url += "?format=" + tc.format
+			}
+			rsp := userJSONRequestForTest(t, server, user, sessionID, fiber.MethodGet, url, nil)
+			tc.checkResponse(rsp)
+		})
+	}
+}
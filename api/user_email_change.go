@@ -0,0 +1,254 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/token"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	verifyYourNewEmailSubject = "Verify your new email"
+	emailChangedSubject       = "Your email was changed"
+)
+
+var (
+	errSameEmail  = errors.New("the new email is the current email of the account")
+	errEmailTaken = errors.New("the email is already used by another account")
+)
+
+// //////////////* Request Email Change API //////////////
+
+type requestEmailChangeParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+type requestEmailChangeJsonRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+}
+
+// requestEmailChange sends an otp to the new email, the login email only changes once the otp is confirmed
+func (server *Server) requestEmailChange(ctx fiber.Ctx) error {
+	params := &requestEmailChangeParamsRequest{}
+	req := &requestEmailChangeJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	throttleAccount := strconv.FormatInt(authPayload.UserID, 10)
+	if server.throttled(ctx, throttleScopeEmailChangeRequest, throttleAccount) {
+		return nil
+	}
+
+	user, err := server.store.GetUser(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if strings.EqualFold(user.Email, req.NewEmail) {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errSameEmail))
+		return nil
+	}
+
+	if taken, err := server.emailTaken(ctx, req.NewEmail); err != nil || taken {
+		return nil
+	}
+
+	// the otp emails back off like failed attempts so the endpoint can't be used to flood an inbox
+	server.recordFailedAttempt(ctx, throttleScopeEmailChangeRequest, throttleAccount)
+
+	// only the hash of the otp is stored
+	secretCode, hashedSecretCode, err := newOTP()
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	arg := db.CreateEmailChangeParams{
+		UserID:     user.ID,
+		NewEmail:   req.NewEmail,
+		SecretCode: hashedSecretCode,
+	}
+
+	_, err = server.store.CreateEmailChange(ctx.Context(), arg)
+	if err != nil {
+		if pqErr, ok := err.(*pgconn.PgError); ok {
+			switch pqErr.Code {
+			case util.ForeignKeyViolationCode, util.UniqueViolationCode:
+				ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
+				return nil
+			}
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	content := "Please confirm your new email by entering the following code in the mobile app: " + secretCode
+
+	err = server.sender.SendEmail(
+		verifyYourNewEmailSubject,
+		content,
+		[]string{req.NewEmail},
+		nil, nil, nil,
+	)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(fiber.Map{})
+	return nil
+}
+
+// emailTaken writes a 403 when another account already uses the email
+func (server *Server) emailTaken(ctx fiber.Ctx, email string) (bool, error) {
+	_, err := server.store.GetUserByEmail(ctx.Context(), email)
+	if err == nil {
+		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errEmailTaken))
+		return true, nil
+	}
+	if err != pgx.ErrNoRows {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return false, err
+	}
+	return false, nil
+}
+
+// //////////////* Confirm Email Change API //////////////
+
+type confirmEmailChangeParamsRequest struct {
+	UserID int64 `uri:"id" validate:"required,min=1"`
+}
+
+type confirmEmailChangeJsonRequest struct {
+	OTP string `json:"otp" validate:"required,numeric,len=6"`
+}
+
+/*
+confirmEmailChange commits the new email once its otp is confirmed,
+
+the old email gets a notice of the change and every other session of the user is revoked,
+the session confirming the change stays logged in.
+*/
+func (server *Server) confirmEmailChange(ctx fiber.Ctx) error {
+	params := &confirmEmailChangeParamsRequest{}
+	req := &confirmEmailChangeJsonRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{params: params, req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	authPayload := ctx.Locals(authorizationUserPayloadKey).(*token.UserPayload)
+	if params.UserID != authPayload.UserID {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	throttleAccount := strconv.FormatInt(authPayload.UserID, 10)
+	if server.throttled(ctx, throttleScopeEmailChange, throttleAccount) {
+		return nil
+	}
+
+	emailChange, err := server.store.GetLastEmailChange(ctx.Context(), authPayload.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	if emailChange.Attempts >= maxOTPAttempts {
+		ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+		return nil
+	}
+
+	if err := util.CheckPassword(req.OTP, emailChange.SecretCode); err != nil {
+		server.recordFailedAttempt(ctx, throttleScopeEmailChange, throttleAccount)
+
+		// the otp expires after maxOTPAttempts wrong codes
+		emailChange, err := server.store.IncrementEmailChangeAttempts(ctx.Context(), db.IncrementEmailChangeAttemptsParams{
+			MaxAttempts: maxOTPAttempts,
+			ID:          emailChange.ID,
+		})
+		if err != nil && err != pgx.ErrNoRows {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+		if err == nil && emailChange.Attempts >= maxOTPAttempts {
+			ctx.Status(fiber.StatusTooManyRequests).JSON(lockedResponse(errOTPAttemptsExceeded, errCodeOTPAttemptsExceeded, 0))
+			return nil
+		}
+
+		ctx.Status(fiber.StatusNotFound).JSON(errorResponse(pgx.ErrNoRows))
+		return nil
+	}
+
+	// the email could have been taken since the otp was sent
+	if taken, err := server.emailTaken(ctx, emailChange.NewEmail); err != nil || taken {
+		return nil
+	}
+
+	result, err := server.store.ChangeUserEmailTx(ctx.Context(), db.ChangeUserEmailTxParams{
+		EmailChangeID:    emailChange.ID,
+		UserID:           authPayload.UserID,
+		CurrentSessionID: authPayload.SessionID,
+	})
+	if err != nil {
+		// the email was taken between the check and the update
+		if pqErr, ok := err.(*pgconn.PgError); ok && pqErr.Code == util.UniqueViolationCode {
+			ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errEmailTaken))
+			return nil
+		}
+		if err == pgx.ErrNoRows {
+			ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return nil
+		}
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	server.resetFailedAttempts(ctx, throttleScopeEmailChange, throttleAccount)
+
+	// the email is already changed, a failed notice is only logged
+	content := "The email of your CShop account was changed to " + result.User.Email +
+		". If you didn't make this change, please contact us right away."
+
+	err = server.sender.SendEmail(
+		emailChangedSubject,
+		content,
+		[]string{result.OldEmail},
+		nil, nil, nil,
+	)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", result.User.ID).Msg("failed to send the email changed notice")
+	}
+
+	rsp := newUserResponse(*result.User)
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
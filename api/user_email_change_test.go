@@ -0,0 +1,432 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/throttle"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// userJSONRequestForTest sends a request authorized by a token of the given user session
+func userJSONRequestForTest(t *testing.T, server *Server, user *db.User, sessionID uuid.UUID, method, url string, body fiber.Map) *http.Response {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+
+	accessToken, _, err := server.userTokenMaker.CreateTokenForUser(user.ID, user.Username, sessionID, time.Minute)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	rsp, err := server.router.Test(request)
+	require.NoError(t, err)
+	return rsp
+}
+
+func TestRequestEmailChangeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	newEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		UserID        int64
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore, sender *mockemail.MockEmailSender)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "OK",
+			UserID: user.ID,
+			body:   fiber.Map{"new_email": newEmail},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					CreateEmailChange(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateEmailChangeParams) (*db.EmailChange, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, newEmail, arg.NewEmail)
+						// the otp is stored hashed
+						require.Greater(t, len(arg.SecretCode), 6)
+						return &db.EmailChange{ID: 1, UserID: arg.UserID, NewEmail: arg.NewEmail}, nil
+					})
+
+				sender.EXPECT().
+					SendEmail(gomock.Eq(verifyYourNewEmailSubject), gomock.Any(), gomock.Eq([]string{newEmail}), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "EmailTaken",
+			UserID: user.ID,
+			body:   fiber.Map{"new_email": newEmail},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(&db.GetUserByEmailRow{ID: user.ID + 1, Email: newEmail}, nil)
+
+				store.EXPECT().
+					CreateEmailChange(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				sender.EXPECT().
+					SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "SameEmail",
+			UserID: user.ID,
+			body:   fiber.Map{"new_email": user.Email},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					CreateEmailChange(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "InvalidEmail",
+			UserID: user.ID,
+			body:   fiber.Map{"new_email": "not-an-email"},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			UserID: user.ID + 1,
+			body:   fiber.Map{"new_email": newEmail},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			sender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store, sender)
+
+			server := newTestServer(t, store, nil, nil, sender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/email-change", tc.UserID)
+			rsp := userJSONRequestForTest(t, server, user, uuid.New(), fiber.MethodPost, url, tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestRequestEmailChangeThrottleAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sender := mockemail.NewMockEmailSender(ctrl)
+	server := newTestServer(t, store, nil, nil, sender)
+
+	// every otp email counts, up to the first delayed one
+	requests := int(throttle.AccountPolicy.FreeAttempts) + 1
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.ID)).
+		Times(requests).
+		Return(user, nil)
+
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Any()).
+		Times(requests).
+		Return(nil, pgx.ErrNoRows)
+
+	store.EXPECT().
+		CreateEmailChange(gomock.Any(), gomock.Any()).
+		Times(requests).
+		Return(&db.EmailChange{ID: 1, UserID: user.ID}, nil)
+
+	sender.EXPECT().
+		SendEmail(gomock.Eq(verifyYourNewEmailSubject), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(requests)
+
+	url := fmt.Sprintf("/usr/v1/users/%d/email-change", user.ID)
+	for range requests {
+		rsp := userJSONRequestForTest(t, server, user, uuid.New(), fiber.MethodPost, url, fiber.Map{"new_email": util.RandomEmail()})
+		require.Equal(t, http.StatusOK, rsp.StatusCode)
+	}
+
+	rsp := userJSONRequestForTest(t, server, user, uuid.New(), fiber.MethodPost, url, fiber.Map{"new_email": util.RandomEmail()})
+	require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+	require.NotEmpty(t, rsp.Header.Get(fiber.HeaderRetryAfter))
+	requireErrorCode(t, rsp.Body, errCodeAccountLocked)
+}
+
+func TestConfirmEmailChangeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	sessionID := uuid.New()
+
+	otp := "123456"
+	hashedOTP, err := util.HashPassword(otp)
+	require.NoError(t, err)
+
+	emailChange := &db.EmailChange{
+		ID:         util.RandomMoney(),
+		UserID:     user.ID,
+		NewEmail:   util.RandomEmail(),
+		SecretCode: hashedOTP,
+	}
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore, sender *mockemail.MockEmailSender)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{"otp": otp},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(emailChange, nil)
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(emailChange.NewEmail)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				changedUser := *user
+				changedUser.Email = emailChange.NewEmail
+				changedUser.IsEmailVerified = true
+
+				store.EXPECT().
+					ChangeUserEmailTx(gomock.Any(), gomock.Eq(db.ChangeUserEmailTxParams{
+						EmailChangeID:    emailChange.ID,
+						UserID:           user.ID,
+						CurrentSessionID: sessionID,
+					})).
+					Times(1).
+					Return(&db.ChangeUserEmailTxResult{User: &changedUser, OldEmail: user.Email, RevokedSessions: 2}, nil)
+
+				// the notice goes to the old email
+				sender.EXPECT().
+					SendEmail(gomock.Eq(emailChangedSubject), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+				data, err := io.ReadAll(rsp.Body)
+				require.NoError(t, err)
+
+				var gotUser userResponse
+				err = json.Unmarshal(data, &gotUser)
+				require.NoError(t, err)
+				require.Equal(t, emailChange.NewEmail, gotUser.Email)
+			},
+		},
+		{
+			name: "WrongOTP",
+			body: fiber.Map{"otp": "654321"},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(emailChange, nil)
+
+				store.EXPECT().
+					IncrementEmailChangeAttempts(gomock.Any(), gomock.Eq(db.IncrementEmailChangeAttemptsParams{
+						MaxAttempts: maxOTPAttempts,
+						ID:          emailChange.ID,
+					})).
+					Times(1).
+					Return(&db.EmailChange{ID: emailChange.ID, Attempts: 1}, nil)
+
+				store.EXPECT().
+					ChangeUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "OTPAttemptsExceeded",
+			body: fiber.Map{"otp": otp},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				lockedEmailChange := *emailChange
+				lockedEmailChange.Attempts = maxOTPAttempts
+
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(&lockedEmailChange, nil)
+
+				store.EXPECT().
+					ChangeUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
+		{
+			name: "EmailTakenSinceRequest",
+			body: fiber.Map{"otp": otp},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(emailChange, nil)
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(emailChange.NewEmail)).
+					Times(1).
+					Return(&db.GetUserByEmailRow{ID: user.ID + 1, Email: emailChange.NewEmail}, nil)
+
+				store.EXPECT().
+					ChangeUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				sender.EXPECT().
+					SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name: "EmailTakenDuringChange",
+			body: fiber.Map{"otp": otp},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(emailChange, nil)
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(emailChange.NewEmail)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					ChangeUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Code: util.UniqueViolationCode, Message: "duplicate key value violates unique constraint"})
+
+				sender.EXPECT().
+					SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name: "NoPendingRequest",
+			body: fiber.Map{"otp": otp},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidOTP",
+			body: fiber.Map{"otp": "12ab"},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender) {
+				store.EXPECT().
+					GetLastEmailChange(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			sender := mockemail.NewMockEmailSender(ctrl)
+			tc.buildStubs(store, sender)
+
+			server := newTestServer(t, store, nil, nil, sender)
+
+			url := fmt.Sprintf("/usr/v1/users/%d/email-change/confirm", user.ID)
+			rsp := userJSONRequestForTest(t, server, user, sessionID, fiber.MethodPost, url, tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
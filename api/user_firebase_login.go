@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/secure"
+	"github.com/cshop/v3/util"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// firebaseReauthMaxAge is how long after the sign-in an ID token can confirm a sensitive action
+const firebaseReauthMaxAge = 5 * time.Minute
+
+const (
+	// firebaseUsernameAttempts is how many usernames are tried before the sign-in gives up
+	firebaseUsernameAttempts     = 5
+	firebaseUsernameSuffixLength = 4
+	// firebaseUsernameMaxLength is the length a display name is cut to before the suffix is added
+	firebaseUsernameMaxLength = 32
+	// firebaseDefaultUsername is used when neither the display name nor the email has a letter or a number
+	firebaseDefaultUsername = "user"
+	// userEmailKey is the unique constraint of the user emails
+	userEmailKey = "user_email_key"
+)
+
+var (
+	errFirebaseLoginDisabled    = errors.New("firebase sign-in isn't configured")
+	errFirebaseEmailNotVerified = errors.New("the firebase account has no verified email")
+	errFirebaseAccountMismatch  = errors.New("the firebase account doesn't belong to the authenticated user")
+	errFirebaseReauthRequired   = errors.New("sign in again to confirm")
+	errFirebaseUserExists       = errors.New("the account was just created, sign in again")
+)
+
+// FirebaseTokenVerifier verifies the ID tokens of the Google and Apple sign-in made through Firebase,
+// *auth.Client implements it
+type FirebaseTokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error)
+}
+
+// firebaseTokenVerifier creates the Firebase Auth client on the first sign-in,
+// so the server starts without reaching Google
+type firebaseTokenVerifier struct {
+	app    *firebase.App
+	mu     sync.Mutex
+	client *auth.Client
+}
+
+func newFirebaseTokenVerifier(app *firebase.App) FirebaseTokenVerifier {
+	if app == nil {
+		return nil
+	}
+	return &firebaseTokenVerifier{app: app}
+}
+
+func (verifier *firebaseTokenVerifier) VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error) {
+	verifier.mu.Lock()
+	if verifier.client == nil {
+		client, err := verifier.app.Auth(ctx)
+		if err != nil {
+			verifier.mu.Unlock()
+			return nil, err
+		}
+		verifier.client = client
+	}
+	client := verifier.client
+	verifier.mu.Unlock()
+
+	return client.VerifyIDToken(ctx, idToken)
+}
+
+// firebaseUsername is the display name of the firebase account, or the local part of its email,
+// cleaned to what the sign-up accepts as a username,
+// after the first attempt a random suffix is added so a taken username isn't tried again
+func firebaseUsername(token *auth.Token, email string, attempt int) string {
+	name, _ := token.Claims["name"].(string)
+	username := sanitizeUsername(name)
+	if username == "" {
+		localPart, _, _ := strings.Cut(email, "@")
+		username = sanitizeUsername(localPart)
+	}
+	if username == "" {
+		username = firebaseDefaultUsername
+	}
+
+	if attempt == 0 {
+		return username
+	}
+	return username + " " + secure.String(firebaseUsernameSuffixLength, "abcdefghijklmnopqrstuvwxyz0123456789")
+}
+
+// sanitizeUsername keeps the letters, numbers and single spaces the alphanumunicode_space validation allows,
+// cut to firebaseUsernameMaxLength characters
+func sanitizeUsername(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsSpace(r) {
+			return r
+		}
+		return -1
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+
+	if runes := []rune(name); len(runes) > firebaseUsernameMaxLength {
+		name = strings.TrimSpace(string(runes[:firebaseUsernameMaxLength]))
+	}
+	return name
+}
+
+// isUsernameTaken reports whether the user couldn't be created because the username is taken,
+// a taken email means the same account signed in at the same time and isn't retried
+func isUsernameTaken(err error) bool {
+	var pqErr *pgconn.PgError
+	return errors.As(err, &pqErr) && pqErr.Code == util.UniqueViolationCode && pqErr.ConstraintName != userEmailKey
+}
+
+// unusablePassword is the password of the accounts made by a social sign-in,
+// nobody knows it so they can only login with a password after resetting it
+func unusablePassword() (string, error) {
+	return util.HashPassword(secure.Secret(32))
+}
+
+// checkFirebaseReauth confirms the user signed in again with the Firebase account of the email,
+// the accounts made by a social sign-in have no password to confirm sensitive actions with
+func (server *Server) checkFirebaseReauth(ctx context.Context, rawIDToken, email string) error {
+	idToken, err := server.firebaseAuth.VerifyIDToken(ctx, rawIDToken)
+	if err != nil {
+		return err
+	}
+
+	tokenEmail, _ := idToken.Claims["email"].(string)
+	emailVerified, _ := idToken.Claims["email_verified"].(bool)
+	if !emailVerified || !strings.EqualFold(tokenEmail, email) {
+		return errFirebaseAccountMismatch
+	}
+
+	// the ID tokens are refreshed without signing in again, only auth_time tells when the user did
+	if time.Since(time.Unix(idToken.AuthTime, 0)) > firebaseReauthMaxAge {
+		return errFirebaseReauthRequired
+	}
+	return nil
+}
+
+// //////////////* Firebase Login API //////////////
+
+type loginUserWithFirebaseRequest struct {
+	IDToken string `json:"id_token" validate:"required"`
+}
+
+/*
+loginUserWithFirebase signs in with the ID token of a Google or Apple account,
+
+the user with the same email is found or created with its cart and wish list,
+the email is verified by the provider so no otp is sent.
+*/
+func (server *Server) loginUserWithFirebase(ctx fiber.Ctx) error {
+	req := &loginUserWithFirebaseRequest{}
+
+	if err := server.parseAndValidate(ctx, Input{req: req}); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return nil
+	}
+
+	if server.firebaseAuth == nil {
+		ctx.Status(fiber.StatusServiceUnavailable).JSON(errorResponse(errFirebaseLoginDisabled))
+		return nil
+	}
+
+	idToken, err := server.firebaseAuth.VerifyIDToken(ctx.Context(), req.IDToken)
+	if err != nil {
+		ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return nil
+	}
+
+	email, _ := idToken.Claims["email"].(string)
+	emailVerified, _ := idToken.Claims["email_verified"].(bool)
+	if email == "" || !emailVerified {
+		ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errFirebaseEmailNotVerified))
+		return nil
+	}
+
+	var user userResponse
+
+	foundUser, err := server.store.GetUserByEmail(ctx.Context(), email)
+	switch {
+	case err == nil:
+		if foundUser.IsBlocked {
+			ctx.Status(fiber.StatusForbidden).JSON(errorResponse(errors.New("account unauthorized")))
+			return nil
+		}
+
+		if !foundUser.IsEmailVerified {
+			password, err := unusablePassword()
+			if err != nil {
+				ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+				return nil
+			}
+
+			arg := db.VerifyUserEmailForSocialLoginParams{
+				Password: password,
+				ID:       foundUser.ID,
+			}
+			if _, err := server.store.VerifyUserEmailForSocialLogin(ctx.Context(), arg); err != nil {
+				ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+				return nil
+			}
+			foundUser.IsEmailVerified = true
+		}
+
+		user = newUserLoginResponse(*foundUser)
+
+	case errors.Is(err, pgx.ErrNoRows):
+		password, err := unusablePassword()
+		if err != nil {
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+
+		arg := db.CreateVerifiedUserWithCartAndWishListParams{
+			Email:    email,
+			Password: password,
+		}
+
+		var createdUser *db.CreateVerifiedUserWithCartAndWishListRow
+		for attempt := 0; attempt < firebaseUsernameAttempts; attempt++ {
+			arg.Username = firebaseUsername(idToken, email, attempt)
+			createdUser, err = server.store.CreateVerifiedUserWithCartAndWishList(ctx.Context(), arg)
+			if !isUsernameTaken(err) {
+				break
+			}
+		}
+		if err != nil {
+			if pqErr, ok := err.(*pgconn.PgError); ok && pqErr.Code == util.UniqueViolationCode {
+				//? the same account signed in at the same time and created the user first
+				if pqErr.ConstraintName == userEmailKey {
+					ctx.Status(fiber.StatusConflict).JSON(errorResponse(errFirebaseUserExists))
+					return nil
+				}
+				ctx.Status(fiber.StatusConflict).JSON(errorResponse(err))
+				return nil
+			}
+			ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+			return nil
+		}
+
+		user = newVerifiedUserWithCartResponse(createdUser)
+
+	default:
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	rsp, err := server.startUserSession(ctx, user)
+	if err != nil {
+		ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return nil
+	}
+
+	ctx.Status(fiber.StatusOK).JSON(rsp)
+	return nil
+}
+
+func newVerifiedUserWithCartResponse(user *db.CreateVerifiedUserWithCartAndWishListRow) userResponse {
+	return userResponse{
+		UserID:          user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		IsBlocked:       user.IsBlocked,
+		IsEmailVerified: user.IsEmailVerified,
+		ShoppingCartID:  user.ShoppingCartID,
+		WishListID:      user.WishListID,
+	}
+}
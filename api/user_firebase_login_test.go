@@ -0,0 +1,411 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	mockdb "github.com/cshop/v3/db/mock"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeFirebaseVerifier accepts a single id token and returns its claims
+type fakeFirebaseVerifier struct {
+	idToken  string
+	claims   map[string]any
+	authTime time.Time
+}
+
+func (verifier *fakeFirebaseVerifier) VerifyIDToken(_ context.Context, idToken string) (*auth.Token, error) {
+	if idToken != verifier.idToken {
+		return nil, errors.New("ID token has invalid signature")
+	}
+	return &auth.Token{UID: "firebase-uid", AuthTime: verifier.authTime.Unix(), Claims: verifier.claims}, nil
+}
+
+func TestLoginUserWithFirebaseAPI(t *testing.T) {
+	idToken := util.RandomString(32)
+	email := util.RandomEmail()
+
+	foundUser := &db.GetUserByEmailRow{
+		ID:              util.RandomMoney(),
+		Username:        util.RandomUser(),
+		Email:           email,
+		IsEmailVerified: true,
+		ShopCartID:      null.IntFrom(util.RandomMoney()),
+		WishListID:      null.IntFrom(util.RandomMoney()),
+	}
+
+	verifiedClaims := map[string]any{"email": email, "email_verified": true, "name": "Social User"}
+
+	expectSession := func(store *mockdb.MockStore, userID int64) {
+		store.EXPECT().
+			CreateUserSession(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ any, arg db.CreateUserSessionParams) (*db.UserSession, error) {
+				require.Equal(t, userID, arg.UserID)
+				require.Equal(t, arg.ID, arg.FamilyID)
+				return &db.UserSession{ID: arg.ID, UserID: arg.UserID, FamilyID: arg.FamilyID}, nil
+			})
+	}
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		claims        map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(rsp *http.Response)
+	}{
+		{
+			name:   "ExistingUser",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(foundUser, nil)
+
+				store.EXPECT().
+					VerifyUserEmailForSocialLogin(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateVerifiedUserWithCartAndWishList(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				expectSession(store, foundUser.ID)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchFirebaseLogin(t, rsp.Body, foundUser.ID, foundUser.ShopCartID.Int64)
+			},
+		},
+		{
+			name:   "NewUser",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				createdUser := &db.CreateVerifiedUserWithCartAndWishListRow{
+					ID:              util.RandomMoney(),
+					Username:        "Social User",
+					Email:           email,
+					IsEmailVerified: true,
+					ShoppingCartID:  util.RandomMoney(),
+					WishListID:      util.RandomMoney(),
+				}
+
+				store.EXPECT().
+					CreateVerifiedUserWithCartAndWishList(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateVerifiedUserWithCartAndWishListParams) (*db.CreateVerifiedUserWithCartAndWishListRow, error) {
+						require.Equal(t, email, arg.Email)
+						require.Equal(t, "Social User", arg.Username)
+						require.NotEmpty(t, arg.Password)
+						return createdUser, nil
+					})
+
+				expectSession(store, createdUser.ID)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "UsernameTaken",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				createdUser := &db.CreateVerifiedUserWithCartAndWishListRow{
+					ID:              util.RandomMoney(),
+					Email:           email,
+					IsEmailVerified: true,
+					ShoppingCartID:  util.RandomMoney(),
+					WishListID:      util.RandomMoney(),
+				}
+
+				gomock.InOrder(
+					store.EXPECT().
+						CreateVerifiedUserWithCartAndWishList(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, arg db.CreateVerifiedUserWithCartAndWishListParams) (*db.CreateVerifiedUserWithCartAndWishListRow, error) {
+							require.Equal(t, "Social User", arg.Username)
+							return nil, &pgconn.PgError{Code: util.UniqueViolationCode, ConstraintName: "user_username_key"}
+						}),
+					store.EXPECT().
+						CreateVerifiedUserWithCartAndWishList(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, arg db.CreateVerifiedUserWithCartAndWishListParams) (*db.CreateVerifiedUserWithCartAndWishListRow, error) {
+							require.Regexp(t, `^Social User [a-z0-9]{4}$`, arg.Username)
+							require.Equal(t, email, arg.Email)
+							createdUser.Username = arg.Username
+							return createdUser, nil
+						}),
+				)
+
+				expectSession(store, createdUser.ID)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "EmailTakenConcurrently",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().
+					CreateVerifiedUserWithCartAndWishList(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pgconn.PgError{Code: util.UniqueViolationCode, ConstraintName: "user_email_key"})
+
+				store.EXPECT().
+					CreateUserSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "UnverifiedLocalAccount",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				unverifiedUser := *foundUser
+				unverifiedUser.IsEmailVerified = false
+				unverifiedUser.Password = util.RandomString(10)
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(&unverifiedUser, nil)
+
+				store.EXPECT().
+					VerifyUserEmailForSocialLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.VerifyUserEmailForSocialLoginParams) (*db.User, error) {
+						require.Equal(t, unverifiedUser.ID, arg.ID)
+						require.NotEqual(t, unverifiedUser.Password, arg.Password)
+						return &db.User{ID: arg.ID, IsEmailVerified: true}, nil
+					})
+
+				expectSession(store, unverifiedUser.ID)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+				requireBodyMatchFirebaseLogin(t, rsp.Body, foundUser.ID, foundUser.ShopCartID.Int64)
+			},
+		},
+		{
+			name:   "InvalidIDToken",
+			body:   fiber.Map{"id_token": "forged"},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "EmailNotVerified",
+			body:   fiber.Map{"id_token": idToken},
+			claims: map[string]any{"email": email, "email_verified": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "BlockedUser",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				blockedUser := *foundUser
+				blockedUser.IsBlocked = true
+
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(&blockedUser, nil)
+
+				store.EXPECT().
+					CreateUserSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusForbidden, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "MissingIDToken",
+			body:   fiber.Map{},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+			},
+		},
+		{
+			name:   "InternalError",
+			body:   fiber.Map{"id_token": idToken},
+			claims: verifiedClaims,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+
+				store.EXPECT().
+					CreateUserSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil, nil)
+			server.firebaseAuth = &fakeFirebaseVerifier{idToken: idToken, claims: tc.claims}
+
+			rsp := postJSONForTest(t, server, "/api/v1/users/login/firebase", tc.body)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestFirebaseUsername(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterValidation("alphanumunicode_space", IsAlphanumUnicodeWithSpace)
+
+	testCases := []struct {
+		name     string
+		claims   map[string]any
+		email    string
+		username string
+	}{
+		{
+			name:     "DisplayName",
+			claims:   map[string]any{"name": "  Social   User "},
+			email:    "social@example.com",
+			username: "Social User",
+		},
+		{
+			name:     "DisplayNameSanitized",
+			claims:   map[string]any{"name": "<b>O'Brien</b>\n😀"},
+			email:    "social@example.com",
+			username: "bOBrienb",
+		},
+		{
+			name:     "DisplayNameTruncated",
+			claims:   map[string]any{"name": strings.Repeat("a", 100)},
+			email:    "social@example.com",
+			username: strings.Repeat("a", firebaseUsernameMaxLength),
+		},
+		{
+			name:     "EmailLocalPart",
+			claims:   map[string]any{"name": "😀 !!"},
+			email:    "social.user+shop@example.com",
+			username: "socialusershop",
+		},
+		{
+			name:     "NoName",
+			claims:   map[string]any{},
+			email:    "social@example.com",
+			username: "social",
+		},
+		{
+			name:     "Default",
+			claims:   map[string]any{},
+			email:    "+.+@example.com",
+			username: firebaseDefaultUsername,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			token := &auth.Token{Claims: tc.claims}
+
+			username := firebaseUsername(token, tc.email, 0)
+			require.Equal(t, tc.username, username)
+			require.NoError(t, validate.Var(username, "required,alphanumunicode_space"))
+
+			retried := firebaseUsername(token, tc.email, 1)
+			require.Regexp(t, `^`+regexp.QuoteMeta(tc.username)+` [a-z0-9]{4}$`, retried)
+			require.NoError(t, validate.Var(retried, "required,alphanumunicode_space"))
+		})
+	}
+}
+
+func requireBodyMatchFirebaseLogin(t *testing.T, body io.Reader, userID int64, shoppingCartID int64) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotLogin createUserResponse
+	err = json.Unmarshal(data, &gotLogin)
+	require.NoError(t, err)
+
+	require.Equal(t, userID, gotLogin.User.UserID)
+	require.Equal(t, shoppingCartID, gotLogin.User.ShoppingCartID)
+	require.True(t, gotLogin.User.IsEmailVerified)
+	require.NotEmpty(t, gotLogin.AccessToken)
+	require.NotEmpty(t, gotLogin.RefreshToken)
+
+	_, err = uuid.Parse(gotLogin.UserSessionID)
+	require.NoError(t, err)
+}
@@ -18,8 +18,10 @@ type createUserReviewParamsRequest struct {
 	UserID int64 `uri:"id" validate:"required,min=1"`
 }
 type createUserReviewRequest struct {
-	OrderedProductID int64 `json:"ordered_product_id" validate:"required,min=1"`
-	RatingValue      int32 `json:"rating_value" validate:"required,min=0,max=5"`
+	OrderedProductID int64  `json:"ordered_product_id" validate:"required,min=1"`
+	RatingValue      int32  `json:"rating_value" validate:"required,min=0,max=5"`
+	Title            string `json:"title" validate:"omitempty,max=100"`
+	Body             string `json:"body" validate:"omitempty,max=2000"`
 }
 
 func (server *Server) createUserReview(ctx fiber.Ctx) error {
@@ -42,6 +44,8 @@ func (server *Server) createUserReview(ctx fiber.Ctx) error {
 		UserID:           authPayload.UserID,
 		OrderedProductID: req.OrderedProductID,
 		RatingValue:      req.RatingValue,
+		Title:            req.Title,
+		Body:             req.Body,
 	}
 
 	userReview, err := server.store.CreateUserReview(ctx.Context(), arg)
@@ -163,8 +167,10 @@ type updateUserReviewParamsRequest struct {
 }
 
 type updateUserReviewJsonRequest struct {
-	OrderedProductID *int64 `json:"ordered_product_id" validate:"omitempty,required,min=1"`
-	RatingValue      *int64 `json:"rating_value" validate:"omitempty,required,min=0,max=5"`
+	OrderedProductID *int64  `json:"ordered_product_id" validate:"omitempty,required,min=1"`
+	RatingValue      *int64  `json:"rating_value" validate:"omitempty,required,min=0,max=5"`
+	Title            *string `json:"title" validate:"omitempty,max=100"`
+	Body             *string `json:"body" validate:"omitempty,max=2000"`
 }
 
 func (server *Server) updateUserReview(ctx fiber.Ctx) error {
@@ -187,6 +193,8 @@ func (server *Server) updateUserReview(ctx fiber.Ctx) error {
 		UserID:           authPayload.UserID,
 		OrderedProductID: null.IntFromPtr(req.OrderedProductID),
 		RatingValue:      null.IntFromPtr(req.RatingValue),
+		Title:            null.StringFromPtr(req.Title),
+		Body:             null.StringFromPtr(req.Body),
 		ID:               params.ReviewID,
 	}
 
@@ -21,6 +21,7 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	mockik "github.com/cshop/v3/image/mock"
 	mockemail "github.com/cshop/v3/mail/mock"
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/token"
 	"github.com/cshop/v3/util"
 	mockwk "github.com/cshop/v3/worker/mock"
@@ -63,7 +64,7 @@ func addAccess(
 	username string,
 	duration time.Duration,
 ) (string, *token.UserPayload, error) {
-	token, payload, err := tokenMaker.CreateTokenForUser(userID, username, duration)
+	token, payload, err := tokenMaker.CreateTokenForUser(userID, username, uuid.New(), duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	return token, payload, err
@@ -606,6 +607,17 @@ func TestVerifyOTPAPI(t *testing.T) {
 	password := <-passwordChan
 	verifyEmail := <-verifyEmailChan
 	updateVerifyEmail := randomUpdateVerifyEmail(user, verifyEmail)
+
+	// only the hash of the otp is stored
+	storedVerifyEmail := *verifyEmail
+	hashedSecretCode, err := util.HashPassword(verifyEmail.SecretCode)
+	require.NoError(t, err)
+	storedVerifyEmail.SecretCode = hashedSecretCode
+
+	wrongOTP := "000000"
+	if verifyEmail.SecretCode == wrongOTP {
+		wrongOTP = "111111"
+	}
 	// user, password := randomUserWithCartAndWishList(t)
 	finalRsp := createUserResponse{
 		User: newUserWithCartResponse(&db.CreateUserWithCartAndWishListRow{
@@ -635,9 +647,12 @@ func TestVerifyOTPAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
 
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&storedVerifyEmail, nil)
+
 				arg := db.UpdateVerifyEmailParams{
-					Email:      user.Email,
-					SecretCode: verifyEmail.SecretCode,
+					Email: user.Email,
+					ID:    verifyEmail.ID,
 				}
 
 				store.EXPECT().UpdateVerifyEmail(gomock.Any(), arg).
@@ -688,19 +703,141 @@ func TestVerifyOTPAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InternalError",
+			name: "WrongOTP",
 			body: fiber.Map{
 				"email": user.Email,
-				"otp":   verifyEmail.SecretCode,
+				"otp":   wrongOTP,
 			},
 			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
-				arg := db.UpdateVerifyEmailParams{
-					Email:      user.Email,
-					SecretCode: verifyEmail.SecretCode,
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&storedVerifyEmail, nil)
+
+				arg := db.IncrementVerifyEmailAttemptsParams{
+					MaxAttempts: maxOTPAttempts,
+					Email:       user.Email,
 				}
 
-				store.EXPECT().UpdateVerifyEmail(gomock.Any(), arg).
+				store.EXPECT().IncrementVerifyEmailAttempts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(&db.VerifyEmail{ID: verifyEmail.ID, Attempts: 1}, nil)
+
+				store.EXPECT().UpdateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().CreateUserSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "LastOTPAttempt",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   wrongOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&storedVerifyEmail, nil)
+
+				store.EXPECT().IncrementVerifyEmailAttempts(gomock.Any(), gomock.Any()).
+					Times(1).Return(&db.VerifyEmail{ID: verifyEmail.ID, Attempts: maxOTPAttempts}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
+		{
+			name: "OTPAttemptsExceeded",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				usedUp := storedVerifyEmail
+				usedUp.Attempts = maxOTPAttempts
+
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&usedUp, nil)
+
+				store.EXPECT().UpdateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
+		{
+			name: "NoLiveOTP",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   wrongOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&storedVerifyEmail, nil)
+
+				store.EXPECT().IncrementVerifyEmailAttempts(gomock.Any(), gomock.Any()).
+					Times(1).Return(nil, pgx.ErrNoRows)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				verified := storedVerifyEmail
+				verified.IsEmailVerified = true
+
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&verified, nil)
+
+				store.EXPECT().UpdateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusConflict, rsp.StatusCode)
+			},
+		},
+		{
+			name: "ExpiredOTP",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).Return(&storedVerifyEmail, nil)
+
+				store.EXPECT().UpdateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(1).Return(nil, pgx.ErrNoRows)
+
+				store.EXPECT().IncrementVerifyEmailAttempts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				store.EXPECT().GetVerifyEmailByEmail(gomock.Any(), gomock.Eq(user.Email)).
 					Times(1).Return(nil, pgx.ErrTxClosed)
+
+				store.EXPECT().UpdateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(rsp *http.Response) {
 				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
@@ -994,6 +1131,17 @@ func TestVerifyResetPasswordOTPAPI(t *testing.T) {
 	// password := <-passwordChan
 	resetPassword := <-resetPasswordChan
 	updateResetPassword := randomUpdateResetPasswordOTP(resetPassword)
+
+	// only the hash of the otp is stored
+	storedResetPassword := *resetPassword
+	hashedSecretCode, err := util.HashPassword(resetPassword.SecretCode)
+	require.NoError(t, err)
+	storedResetPassword.SecretCode = hashedSecretCode
+
+	wrongOTP := "000000"
+	if resetPassword.SecretCode == wrongOTP {
+		wrongOTP = "111111"
+	}
 	// user, password := randomUserWithCartAndWishList(t)
 
 	testCases := []struct {
@@ -1010,14 +1158,9 @@ func TestVerifyResetPasswordOTPAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
 				store.EXPECT().GetResetPasswordsByEmail(gomock.Any(), user.Email).
-					Times(1).Return(resetPassword, nil)
+					Times(1).Return(&storedResetPassword, nil)
 
-				arg := db.UpdateResetPasswordParams{
-					ID:         resetPassword.ID,
-					SecretCode: resetPassword.SecretCode,
-				}
-
-				store.EXPECT().UpdateResetPassword(gomock.Any(), arg).
+				store.EXPECT().UpdateResetPassword(gomock.Any(), gomock.Eq(resetPassword.ID)).
 					Times(1).Return(updateResetPassword, nil)
 
 			},
@@ -1025,6 +1168,73 @@ func TestVerifyResetPasswordOTPAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name: "WrongOTP",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   wrongOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				store.EXPECT().GetResetPasswordsByEmail(gomock.Any(), user.Email).
+					Times(1).Return(&storedResetPassword, nil)
+
+				store.EXPECT().UpdateResetPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				arg := db.IncrementResetPasswordAttemptsParams{
+					MaxAttempts: maxOTPAttempts,
+					ID:          resetPassword.ID,
+				}
+
+				store.EXPECT().IncrementResetPasswordAttempts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(&db.ResetPassword{ID: resetPassword.ID, Attempts: 1}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusNotFound, rsp.StatusCode)
+			},
+		},
+		{
+			name: "LastOTPAttempt",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   wrongOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				store.EXPECT().GetResetPasswordsByEmail(gomock.Any(), user.Email).
+					Times(1).Return(&storedResetPassword, nil)
+
+				store.EXPECT().UpdateResetPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().IncrementResetPasswordAttempts(gomock.Any(), gomock.Any()).
+					Times(1).Return(&db.ResetPassword{ID: resetPassword.ID, Attempts: maxOTPAttempts}, nil)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
+		{
+			name: "OTPAttemptsExceeded",
+			body: fiber.Map{
+				"email": user.Email,
+				"otp":   resetPassword.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
+				usedUp := storedResetPassword
+				usedUp.Attempts = maxOTPAttempts
+
+				store.EXPECT().GetResetPasswordsByEmail(gomock.Any(), user.Email).
+					Times(1).Return(&usedUp, nil)
+
+				store.EXPECT().UpdateResetPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(rsp *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, rsp.StatusCode)
+				requireErrorCode(t, rsp.Body, errCodeOTPAttemptsExceeded)
+			},
+		},
 		{
 			name: "InternalError",
 			body: fiber.Map{
@@ -1237,6 +1447,12 @@ func TestResetPasswordApprovedAPI(t *testing.T) {
 	newPassword := "newpassword"
 	passwordReset := randomResetPassword(user)
 
+	// only the hash of the otp is stored
+	storedPasswordReset := *passwordReset
+	hashedSecretCode, err := util.HashPassword(passwordReset.SecretCode)
+	require.NoError(t, err)
+	storedPasswordReset.SecretCode = hashedSecretCode
+
 	testCases := []struct {
 		name          string
 		body          fiber.Map
@@ -1252,7 +1468,7 @@ func TestResetPasswordApprovedAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore, sender *mockemail.MockEmailSender, tokenMaker token.Maker) {
 
-				store.EXPECT().GetLastUsedResetPassword(gomock.Any(), user.Email).Times(1).Return(passwordReset, nil)
+				store.EXPECT().GetLastUsedResetPassword(gomock.Any(), user.Email).Times(1).Return(&storedPasswordReset, nil)
 
 				if true {
 					store.EXPECT().GetUserByEmail(gomock.Any(), user.Email).
@@ -1953,6 +2169,62 @@ func TestAdminUpdateUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, rsp.StatusCode)
 			},
 		},
+		{
+			name:    "BlockRevokesSessions",
+			AdminID: admin.ID,
+			UserID:  user.ID,
+			body: fiber.Map{
+				"is_blocked": true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				blockedUser := *user
+				blockedUser.IsBlocked = true
+
+				store.EXPECT().
+					AdminUpdateUser(gomock.Any(), gomock.Eq(db.AdminUpdateUserParams{ID: user.ID, IsBlocked: null.BoolFrom(true)})).
+					Times(1).
+					Return(&blockedUser, nil)
+
+				store.EXPECT().
+					RevokeUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(2), nil)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusOK, rsp.StatusCode)
+			},
+		},
+		{
+			name:    "RevokeSessionsInternalError",
+			AdminID: admin.ID,
+			UserID:  user.ID,
+			body: fiber.Map{
+				"is_blocked": true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationForAdmin(t, request, tokenMaker, authorizationTypeBearer, admin.ID, admin.Username, admin.TypeID, admin.Active, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				blockedUser := *user
+				blockedUser.IsBlocked = true
+
+				store.EXPECT().
+					AdminUpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&blockedUser, nil)
+
+				store.EXPECT().
+					RevokeUserSessions(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(0), pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, rsp *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+			},
+		},
 		{
 			name:    "NoAuthorization",
 			AdminID: admin.ID,
@@ -2601,7 +2873,7 @@ func randomSignUpV2User() (user *db.SignUpTxResult, password string) {
 		Email:           util.RandomEmail(),
 		IsBlocked:       false,
 		IsEmailVerified: false,
-		SecretCode:      util.GenerateOTP(),
+		SecretCode:      secure.OTP(),
 	}
 	return
 }
@@ -2615,7 +2887,7 @@ func randomVerifyEmailForNotVerifiedUser(signUpUser *db.GetUserByEmailRow) (user
 		Email:           signUpUser.Email,
 		IsBlocked:       signUpUser.IsBlocked,
 		IsEmailVerified: signUpUser.IsEmailVerified,
-		SecretCode:      util.GenerateOTP(),
+		SecretCode:      secure.OTP(),
 	}
 	return
 }
@@ -2630,7 +2902,7 @@ func randomVerifyEmail(signUpUser *db.SignUpTxResult) (user *db.GetVerifyEmailBy
 		Email:           signUpUser.Email,
 		IsBlocked:       signUpUser.IsBlocked,
 		IsEmailVerified: signUpUser.IsEmailVerified,
-		SecretCode:      util.GenerateOTP(),
+		SecretCode:      secure.OTP(),
 	}
 	return
 }
@@ -2644,7 +2916,7 @@ func randomVerifyPasswordResetOTP(signUpUser db.GetUserByEmailRow) (user db.GetV
 		Email:           signUpUser.Email,
 		IsBlocked:       signUpUser.IsBlocked,
 		IsEmailVerified: signUpUser.IsEmailVerified,
-		SecretCode:      util.GenerateOTP(),
+		SecretCode:      secure.OTP(),
 	}
 	return
 }
@@ -2658,7 +2930,7 @@ func randomResetPasswordOTP(signUpUser *db.GetUserByEmailRow) (user *db.GetReset
 		Email:           signUpUser.Email,
 		IsBlockedUser:   signUpUser.IsBlocked,
 		IsEmailVerified: signUpUser.IsEmailVerified,
-		SecretCode:      util.GenerateOTP(),
+		SecretCode:      secure.OTP(),
 	}
 	return
 }
@@ -2670,7 +2942,7 @@ func randomResetPassword(signUpUser *db.GetUserByEmailRow) (user *db.ResetPasswo
 		UserID:     signUpUser.ID,
 		IsUsed:     false,
 		ExpiredAt:  time.Now().Add(-time.Hour),
-		SecretCode: util.GenerateOTP(),
+		SecretCode: secure.OTP(),
 	}
 	return
 }
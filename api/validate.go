@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"unicode"
 
+	db "github.com/cshop/v3/db/sqlc"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 )
@@ -29,6 +30,29 @@ func validatePhoneNumber(fl validator.FieldLevel) bool {
 	return phoneRegex.MatchString(telephone)
 }
 
+// validateAdminPermission checks the value is one of the admin permission codes
+func validateAdminPermission(fl validator.FieldLevel) bool {
+	return db.IsAdminPermission(fl.Field().String())
+}
+
+/*
+validateSearchQuery allows letters, numbers and spaces, the arabic diacritics
+and the websearch_to_tsquery operators: quotes for phrases, - to exclude a word and or/| between words.
+*/
+func validateSearchQuery(fl validator.FieldLevel) bool {
+	for _, c := range fl.Field().String() {
+		if unicode.IsLetter(c) || unicode.IsNumber(c) || unicode.IsSpace(c) || unicode.Is(unicode.Mn, c) {
+			continue
+		}
+		switch c {
+		case '"', '\'', '-', '|', '&':
+			continue
+		}
+		return false
+	}
+	return true
+}
+
 type Input struct {
 	params any
 	req    any
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "coupon_redemption";
+
+DROP TABLE IF EXISTS "coupon";
+
+ALTER TABLE "shop_order_item" DROP COLUMN IF EXISTS "coupon_discount";
@@ -0,0 +1,49 @@
+CREATE TABLE "coupon" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "code" varchar UNIQUE NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "discount_type" varchar NOT NULL,
+  "discount_value" varchar NOT NULL,
+  "min_order_value" varchar NOT NULL DEFAULT '0',
+  "max_uses" bigint,
+  "max_uses_per_user" bigint,
+  "used_count" bigint NOT NULL DEFAULT 0,
+  "category_id" bigint,
+  "brand_id" bigint,
+  "product_id" bigint,
+  "start_date" timestamptz NOT NULL,
+  "end_date" timestamptz NOT NULL,
+  "active" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  CONSTRAINT coupon_discount_type_check CHECK ("discount_type" IN ('percentage', 'fixed')),
+  CONSTRAINT coupon_date_range_check CHECK ("end_date" > "start_date"),
+  CONSTRAINT coupon_percentage_check CHECK ("discount_type" <> 'percentage' OR "discount_value"::numeric <= 100)
+);
+
+CREATE TABLE "coupon_redemption" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "coupon_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "shop_order_id" bigint UNIQUE NOT NULL,
+  "discount_amount" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "shop_order_item" ADD COLUMN "coupon_discount" varchar NOT NULL DEFAULT '0';
+
+CREATE INDEX ON "coupon_redemption" ("coupon_id", "user_id");
+
+COMMENT ON COLUMN "shop_order_item"."coupon_discount" IS 'part of the order''s coupon discount taken off this line';
+
+ALTER TABLE "coupon" ADD FOREIGN KEY ("category_id") REFERENCES "product_category" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon" ADD FOREIGN KEY ("brand_id") REFERENCES "product_brand" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon" ADD FOREIGN KEY ("product_id") REFERENCES "product" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon_redemption" ADD FOREIGN KEY ("coupon_id") REFERENCES "coupon" ("id");
+
+ALTER TABLE "coupon_redemption" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id");
+
+ALTER TABLE "coupon_redemption" ADD FOREIGN KEY ("shop_order_id") REFERENCES "shop_order" ("id") ON DELETE CASCADE;
@@ -0,0 +1,18 @@
+CREATE TABLE "idempotency_key" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "user_id" bigint NOT NULL,
+  "key" varchar NOT NULL,
+  "request_method" varchar NOT NULL,
+  "request_path" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int,
+  "response_body" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX ON "idempotency_key" ("user_id", "key");
+
+CREATE INDEX ON "idempotency_key" ("expires_at");
+
+ALTER TABLE "idempotency_key" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
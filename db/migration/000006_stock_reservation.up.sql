@@ -0,0 +1,22 @@
+CREATE TABLE "stock_reservation" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "shopping_cart_id" bigint NOT NULL,
+  "product_item_id" bigint NOT NULL,
+  "size_id" bigint NOT NULL,
+  "qty" int NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT stock_reservation_qty_check CHECK ("qty" > 0)
+);
+
+CREATE INDEX ON "stock_reservation" ("shopping_cart_id");
+
+CREATE INDEX ON "stock_reservation" ("size_id", "expires_at");
+
+CREATE INDEX ON "stock_reservation" ("product_item_id", "expires_at");
+
+ALTER TABLE "stock_reservation" ADD FOREIGN KEY ("shopping_cart_id") REFERENCES "shopping_cart" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "stock_reservation" ADD FOREIGN KEY ("product_item_id") REFERENCES "product_item" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "stock_reservation" ADD FOREIGN KEY ("size_id") REFERENCES "product_size" ("id") ON DELETE CASCADE;
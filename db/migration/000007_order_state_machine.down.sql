@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "shop_order_status_history";
+
+-- the seeded statuses are removed unless an order still uses them
+DELETE FROM "order_status"
+WHERE "code" IN ('pending', 'processing', 'shipped', 'delivered', 'cancelled', 'returned')
+AND NOT EXISTS (
+  SELECT 1 FROM "shop_order" WHERE "shop_order"."order_status_id" = "order_status"."id"
+);
+
+ALTER TABLE "order_status" DROP COLUMN IF EXISTS "code";
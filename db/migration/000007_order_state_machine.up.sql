@@ -0,0 +1,32 @@
+ALTER TABLE "order_status" ADD COLUMN "code" varchar UNIQUE;
+
+COMMENT ON COLUMN "order_status"."code" IS 'stable key used by the order state machine, like pending, shipped and delivered';
+
+INSERT INTO "order_status" ("status", "code") VALUES
+  ('pending', 'pending'),
+  ('processing', 'processing'),
+  ('shipped', 'shipped'),
+  ('delivered', 'delivered'),
+  ('cancelled', 'cancelled'),
+  ('returned', 'returned')
+ON CONFLICT ("status") DO UPDATE SET "code" = EXCLUDED."code";
+
+CREATE TABLE "shop_order_status_history" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "shop_order_id" bigint NOT NULL,
+  "from_status_id" bigint,
+  "to_status_id" bigint NOT NULL,
+  "admin_id" bigint,
+  "note" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "shop_order_status_history" ("shop_order_id");
+
+ALTER TABLE "shop_order_status_history" ADD FOREIGN KEY ("shop_order_id") REFERENCES "shop_order" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "shop_order_status_history" ADD FOREIGN KEY ("from_status_id") REFERENCES "order_status" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "shop_order_status_history" ADD FOREIGN KEY ("to_status_id") REFERENCES "order_status" ("id");
+
+ALTER TABLE "shop_order_status_history" ADD FOREIGN KEY ("admin_id") REFERENCES "admin" ("id") ON DELETE SET NULL;
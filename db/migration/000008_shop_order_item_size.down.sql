@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "shop_order_item" DROP COLUMN IF EXISTS "size_id";
@@ -0,0 +1,5 @@
+ALTER TABLE "shop_order_item" ADD COLUMN "size_id" bigint;
+
+COMMENT ON COLUMN "shop_order_item"."size_id" IS 'product size the qty was taken from, used to restock it';
+
+ALTER TABLE "shop_order_item" ADD FOREIGN KEY ("size_id") REFERENCES "product_size" ("id") ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "shop_order_item" DROP COLUMN IF EXISTS "product_name";
+
+ALTER TABLE IF EXISTS "shop_order_item" DROP COLUMN IF EXISTS "color_value";
+
+ALTER TABLE IF EXISTS "shop_order_item" DROP COLUMN IF EXISTS "size_value";
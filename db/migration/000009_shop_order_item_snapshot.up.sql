@@ -0,0 +1,27 @@
+ALTER TABLE "shop_order_item" ADD COLUMN "size_value" varchar;
+
+ALTER TABLE "shop_order_item" ADD COLUMN "color_value" varchar;
+
+ALTER TABLE "shop_order_item" ADD COLUMN "product_name" varchar;
+
+COMMENT ON COLUMN "shop_order_item"."size_value" IS 'size of product when ordered';
+
+COMMENT ON COLUMN "shop_order_item"."color_value" IS 'color of product when ordered';
+
+COMMENT ON COLUMN "shop_order_item"."product_name" IS 'name of product when ordered';
+
+-- the size of the older items is unknown, their color and name are taken from the current product
+UPDATE "shop_order_item" AS soi
+SET
+size_value = psize.size_value
+FROM "product_size" AS psize
+WHERE psize.id = soi.size_id;
+
+UPDATE "shop_order_item" AS soi
+SET
+color_value = pcolor.color_value,
+product_name = p.name
+FROM "product_item" AS pi
+JOIN "product" AS p ON p.id = pi.product_id
+LEFT JOIN "product_color" AS pcolor ON pcolor.id = pi.color_id
+WHERE pi.id = soi.product_item_id;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "return_request_item";
+
+DROP TABLE IF EXISTS "return_request";
@@ -0,0 +1,46 @@
+CREATE TABLE "return_request" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "user_id" bigint NOT NULL,
+  "shop_order_id" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'requested',
+  "reason" varchar NOT NULL,
+  "admin_id" bigint,
+  "admin_note" varchar NOT NULL DEFAULT '',
+  "refund_amount" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  CONSTRAINT return_request_status_check CHECK ("status" IN ('requested', 'approved', 'rejected', 'received', 'refunded'))
+);
+
+CREATE TABLE "return_request_item" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "return_request_id" bigint NOT NULL,
+  "shop_order_item_id" bigint NOT NULL,
+  "qty" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT return_request_item_qty_check CHECK ("qty" > 0)
+);
+
+CREATE INDEX ON "return_request" ("user_id");
+
+CREATE INDEX ON "return_request" ("shop_order_id");
+
+CREATE INDEX ON "return_request" ("status");
+
+CREATE UNIQUE INDEX ON "return_request_item" ("return_request_id", "shop_order_item_id");
+
+CREATE INDEX ON "return_request_item" ("shop_order_item_id");
+
+COMMENT ON COLUMN "return_request"."status" IS 'requested, approved, rejected, received or refunded';
+
+COMMENT ON COLUMN "return_request"."refund_amount" IS 'computed from the price and discount of the returned order items';
+
+ALTER TABLE "return_request" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "return_request" ADD FOREIGN KEY ("shop_order_id") REFERENCES "shop_order" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "return_request" ADD FOREIGN KEY ("admin_id") REFERENCES "admin" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "return_request_item" ADD FOREIGN KEY ("return_request_id") REFERENCES "return_request" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "return_request_item" ADD FOREIGN KEY ("shop_order_item_id") REFERENCES "shop_order_item" ("id") ON DELETE CASCADE;
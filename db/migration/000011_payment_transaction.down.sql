@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "payment_transaction";
+
+DELETE FROM "order_status" WHERE "code" = 'awaiting_payment';
+
+ALTER TABLE "payment_type" DROP COLUMN IF EXISTS "provider";
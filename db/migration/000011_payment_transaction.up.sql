@@ -0,0 +1,41 @@
+ALTER TABLE "payment_type" ADD COLUMN "provider" varchar NOT NULL DEFAULT 'cod';
+
+COMMENT ON COLUMN "payment_type"."provider" IS 'name of the payment provider that charges this payment type, like cod';
+
+INSERT INTO "order_status" ("status", "code") VALUES
+  ('awaiting payment', 'awaiting_payment')
+ON CONFLICT ("status") DO UPDATE SET "code" = EXCLUDED."code";
+
+CREATE TABLE "payment_transaction" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "shop_order_id" bigint NOT NULL,
+  "provider" varchar NOT NULL,
+  "provider_reference" varchar,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "amount" varchar NOT NULL,
+  "refunded_amount" varchar NOT NULL DEFAULT '0',
+  "pending_refund_amount" varchar NOT NULL DEFAULT '0',
+  "refund_in_flight_amount" varchar NOT NULL DEFAULT '0',
+  "refund_sequence" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  CONSTRAINT payment_transaction_status_check CHECK ("status" IN ('pending', 'authorized', 'captured', 'partially_refunded', 'refunded', 'failed'))
+);
+
+CREATE INDEX ON "payment_transaction" ("shop_order_id");
+
+CREATE UNIQUE INDEX ON "payment_transaction" ("provider", "provider_reference");
+
+COMMENT ON COLUMN "payment_transaction"."provider_reference" IS 'id of the payment at the provider, set once the payment is authorized';
+
+COMMENT ON COLUMN "payment_transaction"."status" IS 'pending, authorized, captured, partially_refunded, refunded or failed';
+
+COMMENT ON COLUMN "payment_transaction"."refunded_amount" IS 'part of the amount the provider gave back';
+
+COMMENT ON COLUMN "payment_transaction"."pending_refund_amount" IS 'part of the amount to give back that the provider did not refund yet';
+
+COMMENT ON COLUMN "payment_transaction"."refund_in_flight_amount" IS 'part of the amount the provider was asked to give back and did not confirm yet';
+
+COMMENT ON COLUMN "payment_transaction"."refund_sequence" IS 'number of refunds asked to the provider, it keys the idempotency of the refund in flight';
+
+ALTER TABLE "payment_transaction" ADD FOREIGN KEY ("shop_order_id") REFERENCES "shop_order" ("id") ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "admin_role_assignment";
+
+DROP TABLE IF EXISTS "admin_role_permission";
+
+DROP TABLE IF EXISTS "admin_role";
+
+DROP TABLE IF EXISTS "admin_permission";
@@ -0,0 +1,85 @@
+CREATE TABLE "admin_permission" (
+  "code" varchar PRIMARY KEY NOT NULL,
+  "description" varchar NOT NULL DEFAULT ''
+);
+
+CREATE TABLE "admin_role" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "name" varchar UNIQUE NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
+
+CREATE TABLE "admin_role_permission" (
+  "role_id" bigint NOT NULL,
+  "permission" varchar NOT NULL,
+  PRIMARY KEY ("role_id", "permission")
+);
+
+CREATE TABLE "admin_role_assignment" (
+  "admin_id" bigint NOT NULL,
+  "role_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("admin_id", "role_id")
+);
+
+CREATE INDEX ON "admin_role_assignment" ("role_id");
+
+COMMENT ON COLUMN "admin_permission"."code" IS 'name checked by the admin routes, like orders:manage';
+
+ALTER TABLE "admin_role_permission" ADD FOREIGN KEY ("role_id") REFERENCES "admin_role" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "admin_role_permission" ADD FOREIGN KEY ("permission") REFERENCES "admin_permission" ("code") ON DELETE CASCADE;
+
+ALTER TABLE "admin_role_assignment" ADD FOREIGN KEY ("admin_id") REFERENCES "admin" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "admin_role_assignment" ADD FOREIGN KEY ("role_id") REFERENCES "admin_role" ("id") ON DELETE CASCADE;
+
+INSERT INTO "admin_permission" ("code", "description") VALUES
+  ('dashboard:read', 'view the dashboard'),
+  ('catalog:manage', 'manage products, product items, categories, brands, variations, images, colors and sizes'),
+  ('pricing:manage', 'change the prices of product items and the totals of shop orders'),
+  ('promotions:manage', 'manage promotions and coupons'),
+  ('orders:read', 'view shop orders and return requests'),
+  ('orders:manage', 'update shop orders and return requests'),
+  ('users:read', 'view and search users'),
+  ('users:manage', 'update and block users'),
+  ('users:delete', 'delete users'),
+  ('settings:manage', 'manage payment types, shipping methods, order statuses, text banners and the app policy'),
+  ('roles:manage', 'manage admin roles and assign them to admins');
+
+INSERT INTO "admin_role" ("name", "description") VALUES
+  ('super_admin', 'every permission'),
+  ('catalog_manager', 'manages the catalog, its prices and the promotions'),
+  ('order_fulfilment', 'prepares, ships and returns the orders'),
+  ('support', 'helps the users with their accounts and orders');
+
+INSERT INTO "admin_role_permission" ("role_id", "permission")
+SELECT "admin_role"."id", "admin_permission"."code"
+FROM "admin_role", "admin_permission"
+WHERE "admin_role"."name" = 'super_admin';
+
+INSERT INTO "admin_role_permission" ("role_id", "permission")
+SELECT "admin_role"."id", p.code
+FROM "admin_role", (VALUES
+  ('catalog_manager', 'dashboard:read'),
+  ('catalog_manager', 'catalog:manage'),
+  ('catalog_manager', 'pricing:manage'),
+  ('catalog_manager', 'promotions:manage'),
+  ('order_fulfilment', 'dashboard:read'),
+  ('order_fulfilment', 'orders:read'),
+  ('order_fulfilment', 'orders:manage'),
+  ('support', 'dashboard:read'),
+  ('support', 'orders:read'),
+  ('support', 'users:read'),
+  ('support', 'users:manage')
+) AS p(role, code)
+WHERE "admin_role"."name" = p.role;
+
+-- the admins of the first admin type were the only ones allowed in before the roles
+INSERT INTO "admin_role_assignment" ("admin_id", "role_id")
+SELECT "admin"."id", "admin_role"."id"
+FROM "admin", "admin_role"
+WHERE "admin"."type_id" = 1
+AND "admin_role"."name" = 'super_admin';
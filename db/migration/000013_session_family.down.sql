@@ -0,0 +1,5 @@
+ALTER TABLE "admin_session" DROP COLUMN IF EXISTS "replaced_by";
+ALTER TABLE "admin_session" DROP COLUMN IF EXISTS "family_id";
+
+ALTER TABLE "user_session" DROP COLUMN IF EXISTS "replaced_by";
+ALTER TABLE "user_session" DROP COLUMN IF EXISTS "family_id";
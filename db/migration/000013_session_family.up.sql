@@ -0,0 +1,19 @@
+ALTER TABLE "user_session" ADD COLUMN "family_id" uuid;
+UPDATE "user_session" SET "family_id" = "id";
+ALTER TABLE "user_session" ALTER COLUMN "family_id" SET NOT NULL;
+ALTER TABLE "user_session" ADD COLUMN "replaced_by" uuid;
+
+CREATE INDEX ON "user_session" ("family_id");
+
+COMMENT ON COLUMN "user_session"."family_id" IS 'id of the login session every rotated refresh token descends from';
+COMMENT ON COLUMN "user_session"."replaced_by" IS 'id of the session created when this refresh token was rotated, a rotated token must not be used again';
+
+ALTER TABLE "admin_session" ADD COLUMN "family_id" uuid;
+UPDATE "admin_session" SET "family_id" = "id";
+ALTER TABLE "admin_session" ALTER COLUMN "family_id" SET NOT NULL;
+ALTER TABLE "admin_session" ADD COLUMN "replaced_by" uuid;
+
+CREATE INDEX ON "admin_session" ("family_id");
+
+COMMENT ON COLUMN "admin_session"."family_id" IS 'id of the login session every rotated refresh token descends from';
+COMMENT ON COLUMN "admin_session"."replaced_by" IS 'id of the session created when this refresh token was rotated, a rotated token must not be used again';
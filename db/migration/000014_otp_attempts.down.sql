@@ -0,0 +1,2 @@
+ALTER TABLE "reset_passwords" DROP COLUMN IF EXISTS "attempts";
+ALTER TABLE "verify_email" DROP COLUMN IF EXISTS "attempts";
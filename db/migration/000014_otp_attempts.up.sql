@@ -0,0 +1,5 @@
+ALTER TABLE "verify_email" ADD COLUMN "attempts" int NOT NULL DEFAULT 0;
+ALTER TABLE "reset_passwords" ADD COLUMN "attempts" int NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "verify_email"."attempts" IS 'wrong codes entered for this otp, it expires once the limit is reached';
+COMMENT ON COLUMN "reset_passwords"."attempts" IS 'wrong codes entered for this otp, it expires once the limit is reached';
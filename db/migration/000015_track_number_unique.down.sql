@@ -0,0 +1 @@
+ALTER TABLE "shop_order" DROP CONSTRAINT IF EXISTS "shop_order_track_number_key";
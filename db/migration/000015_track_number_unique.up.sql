@@ -0,0 +1,10 @@
+-- track numbers were generated without checking for collisions, the later duplicates get a new suffix
+UPDATE "shop_order" AS so
+SET "track_number" = so."track_number" || '-' || so."id"
+WHERE EXISTS (
+  SELECT 1 FROM "shop_order" AS other
+  WHERE other."track_number" = so."track_number"
+  AND other."id" < so."id"
+);
+
+ALTER TABLE "shop_order" ADD CONSTRAINT "shop_order_track_number_key" UNIQUE ("track_number");
@@ -0,0 +1,9 @@
+DELETE FROM "admin_permission" WHERE "code" = 'security:manage';
+
+DROP TABLE IF EXISTS "admin_security_setting";
+
+DROP TABLE IF EXISTS "admin_login_challenge";
+
+DROP TABLE IF EXISTS "admin_recovery_code";
+
+DROP TABLE IF EXISTS "admin_totp";
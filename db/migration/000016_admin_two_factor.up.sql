@@ -0,0 +1,58 @@
+CREATE TABLE "admin_totp" (
+  "admin_id" bigint PRIMARY KEY NOT NULL,
+  "secret" varchar NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT false,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "enabled_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
+
+CREATE TABLE "admin_recovery_code" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "admin_id" bigint NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "admin_login_challenge" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "admin_id" bigint NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '5 minutes')
+);
+
+CREATE TABLE "admin_security_setting" (
+  "id" boolean PRIMARY KEY NOT NULL DEFAULT true CHECK ("id"),
+  "require_two_factor" boolean NOT NULL DEFAULT false,
+  "updated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
+
+CREATE INDEX ON "admin_recovery_code" ("admin_id");
+
+CREATE INDEX ON "admin_login_challenge" ("admin_id");
+
+COMMENT ON COLUMN "admin_totp"."secret" IS 'base32 totp secret, the admin can only login with it once enabled is true';
+COMMENT ON COLUMN "admin_totp"."last_used_step" IS 'time step of the last accepted code, a code can''t be used twice';
+COMMENT ON COLUMN "admin_recovery_code"."code_hash" IS 'sha256 of the one-time recovery code';
+COMMENT ON COLUMN "admin_login_challenge"."token_hash" IS 'sha256 of the challenge token returned by the password step of the login';
+COMMENT ON COLUMN "admin_security_setting"."id" IS 'the table holds a single row';
+
+ALTER TABLE "admin_totp" ADD FOREIGN KEY ("admin_id") REFERENCES "admin" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "admin_recovery_code" ADD FOREIGN KEY ("admin_id") REFERENCES "admin" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "admin_login_challenge" ADD FOREIGN KEY ("admin_id") REFERENCES "admin" ("id") ON DELETE CASCADE;
+
+INSERT INTO "admin_security_setting" DEFAULT VALUES;
+
+INSERT INTO "admin_permission" ("code", "description") VALUES
+  ('security:manage', 'manage the security settings of the admin accounts, like requiring two-factor authentication');
+
+INSERT INTO "admin_role_permission" ("role_id", "permission")
+SELECT "admin_role"."id", 'security:manage'
+FROM "admin_role"
+WHERE "admin_role"."name" = 'super_admin';
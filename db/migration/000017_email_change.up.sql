@@ -0,0 +1,18 @@
+CREATE TABLE "email_change" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "user_id" bigint NOT NULL,
+  "new_email" varchar NOT NULL,
+  "secret_code" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '15 minutes')
+);
+
+CREATE INDEX ON "email_change" ("user_id", "created_at");
+
+COMMENT ON COLUMN "email_change"."new_email" IS 'the login email of the user is only replaced once the otp sent to this address is confirmed';
+COMMENT ON COLUMN "email_change"."secret_code" IS 'hash of the otp sent to the new email';
+COMMENT ON COLUMN "email_change"."attempts" IS 'wrong codes entered for this otp, it expires once the limit is reached';
+
+ALTER TABLE "email_change" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
@@ -0,0 +1,14 @@
+CREATE TABLE "user_deletion" (
+  "user_id" bigint PRIMARY KEY NOT NULL,
+  "requested_at" timestamptz NOT NULL DEFAULT (now()),
+  "delete_after" timestamptz NOT NULL,
+  "anonymized_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
+
+CREATE INDEX ON "user_deletion" ("delete_after") WHERE "anonymized_at" = '0001-01-01 00:00:00Z';
+
+COMMENT ON TABLE "user_deletion" IS 'accounts their users asked to delete, the personal data is anonymized once the grace period is over';
+COMMENT ON COLUMN "user_deletion"."delete_after" IS 'end of the grace period, the user can cancel the deletion until then';
+COMMENT ON COLUMN "user_deletion"."anonymized_at" IS 'set once the personal data of the user was anonymized, the shop orders are kept for accounting';
+
+ALTER TABLE "user_deletion" ADD FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
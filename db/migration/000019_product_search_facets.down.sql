@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "product_size_product_item_id_size_value_idx";
+
+DROP INDEX IF EXISTS "product_item_color_id_idx";
+
+DROP INDEX IF EXISTS "product_item_product_id_idx";
+
+DROP INDEX IF EXISTS "product_category_id_idx";
+
+DROP INDEX IF EXISTS "product_brand_id_idx";
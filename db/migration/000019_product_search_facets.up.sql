@@ -0,0 +1,10 @@
+-- the faceted search filters and groups the active items by these columns
+CREATE INDEX "product_brand_id_idx" ON "product" ("brand_id");
+
+CREATE INDEX "product_category_id_idx" ON "product" ("category_id");
+
+CREATE INDEX "product_item_product_id_idx" ON "product_item" ("product_id");
+
+CREATE INDEX "product_item_color_id_idx" ON "product_item" ("color_id");
+
+CREATE INDEX "product_size_product_item_id_size_value_idx" ON "product_size" ("product_item_id", "size_value");
@@ -0,0 +1,37 @@
+DROP INDEX IF EXISTS "product_category_name_trgm_idx";
+
+DROP INDEX IF EXISTS "product_brand_name_trgm_idx";
+
+DROP INDEX IF EXISTS "product_name_trgm_idx";
+
+DROP TRIGGER IF EXISTS product_category_search_refresh ON "product_category";
+
+DROP TRIGGER IF EXISTS product_brand_search_refresh ON "product_brand";
+
+DROP TRIGGER IF EXISTS product_search_refresh ON "product";
+
+DROP FUNCTION IF EXISTS product_category_search_refresh();
+
+DROP FUNCTION IF EXISTS product_brand_search_refresh();
+
+DROP FUNCTION IF EXISTS product_search_refresh();
+
+ALTER TABLE "product" DROP COLUMN "search";
+
+ALTER TABLE "product"
+ADD COLUMN "search" tsvector
+GENERATED ALWAYS AS (
+setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+CREATE INDEX search_idx
+ON "product" USING GIN ("search");
+
+DROP FUNCTION IF EXISTS product_search_query(text);
+
+DROP FUNCTION IF EXISTS product_search_document(text, text, text, text);
+
+DROP FUNCTION IF EXISTS normalize_search_text(text);
+
+DROP EXTENSION IF EXISTS pg_trgm;
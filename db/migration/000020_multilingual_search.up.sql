@@ -0,0 +1,91 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- normalize_search_text strips the arabic diacritics and tatweel and folds the letters customers type interchangeably,
+-- e.g. أ إ آ to ا, ة to ه and ى to ي, the arabic-indic digits become ascii digits
+CREATE OR REPLACE FUNCTION normalize_search_text(value text) RETURNS text AS $$
+  SELECT lower(
+    translate(
+      regexp_replace(COALESCE(value, ''), '[\u064B-\u065F\u0670\u0640]', '', 'g'),
+      'أإآٱىةؤئی٠١٢٣٤٥٦٧٨٩',
+      'اااايهويي0123456789'
+    )
+  )
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+-- product_search_document indexes the words in english, for stemming, and in the simple config over the normalized text,
+-- which keeps the arabic words as they are typed
+CREATE OR REPLACE FUNCTION product_search_document(name text, description text, brand_name text, category_name text) RETURNS tsvector AS $$
+  SELECT
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple', normalize_search_text(name)), 'A') ||
+    setweight(to_tsvector('simple', normalize_search_text(brand_name)), 'B') ||
+    setweight(to_tsvector('simple', normalize_search_text(category_name)), 'B') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C') ||
+    setweight(to_tsvector('simple', normalize_search_text(description)), 'C')
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+-- product_search_query parses the customer input with websearch_to_tsquery, which never fails on operators like & or |,
+-- every word is matched as a prefix so the results follow the typing
+CREATE OR REPLACE FUNCTION product_search_query(query text) RETURNS tsquery AS $$
+  SELECT
+    regexp_replace(websearch_to_tsquery('english', COALESCE(query, ''))::text, '''((?:[^'']|'''')+)''', '''\1'':*', 'g')::tsquery ||
+    regexp_replace(websearch_to_tsquery('simple', normalize_search_text(query))::text, '''((?:[^'']|'''')+)''', '''\1'':*', 'g')::tsquery
+$$ LANGUAGE sql STABLE PARALLEL SAFE;
+
+-- the search document holds the brand and category names, they live in other tables so a generated column can't be used
+ALTER TABLE "product" DROP COLUMN "search";
+
+ALTER TABLE "product" ADD COLUMN "search" tsvector;
+
+CREATE OR REPLACE FUNCTION product_search_refresh() RETURNS trigger AS $$
+BEGIN
+  NEW.search := product_search_document(
+    NEW.name,
+    NEW.description,
+    (SELECT brand_name FROM "product_brand" WHERE id = NEW.brand_id),
+    (SELECT category_name FROM "product_category" WHERE id = NEW.category_id)
+  );
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_search_refresh
+BEFORE INSERT OR UPDATE OF name, description, brand_id, category_id ON "product"
+FOR EACH ROW EXECUTE FUNCTION product_search_refresh();
+
+-- a renamed brand or category refreshes the search document of its products
+CREATE OR REPLACE FUNCTION product_brand_search_refresh() RETURNS trigger AS $$
+BEGIN
+  UPDATE "product" SET brand_id = brand_id WHERE brand_id = NEW.id;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_brand_search_refresh
+AFTER UPDATE OF brand_name ON "product_brand"
+FOR EACH ROW WHEN (OLD.brand_name IS DISTINCT FROM NEW.brand_name)
+EXECUTE FUNCTION product_brand_search_refresh();
+
+CREATE OR REPLACE FUNCTION product_category_search_refresh() RETURNS trigger AS $$
+BEGIN
+  UPDATE "product" SET category_id = category_id WHERE category_id = NEW.id;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_category_search_refresh
+AFTER UPDATE OF category_name ON "product_category"
+FOR EACH ROW WHEN (OLD.category_name IS DISTINCT FROM NEW.category_name)
+EXECUTE FUNCTION product_category_search_refresh();
+
+UPDATE "product" SET name = name;
+
+CREATE INDEX search_idx
+ON "product" USING GIN ("search");
+
+-- the trigram indexes back the fuzzy fallback used when the full text search finds nothing
+CREATE INDEX "product_name_trgm_idx" ON "product" USING GIN (normalize_search_text("name") gin_trgm_ops);
+
+CREATE INDEX "product_brand_name_trgm_idx" ON "product_brand" USING GIN (normalize_search_text("brand_name") gin_trgm_ops);
+
+CREATE INDEX "product_category_name_trgm_idx" ON "product_category" USING GIN (normalize_search_text("category_name") gin_trgm_ops);
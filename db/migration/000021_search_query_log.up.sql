@@ -0,0 +1,18 @@
+CREATE TABLE "search_query_log" (
+  "id" bigserial PRIMARY KEY,
+  "query" varchar NOT NULL,
+  "normalized_query" varchar NOT NULL,
+  "result_count" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "search_query_log" ("created_at");
+
+-- the suggestions match the past queries by prefix and the corrections by trigram similarity
+CREATE INDEX "search_query_log_normalized_query_idx" ON "search_query_log" ("normalized_query" text_pattern_ops);
+
+CREATE INDEX "search_query_log_normalized_query_trgm_idx" ON "search_query_log" USING GIN ("normalized_query" gin_trgm_ops);
+
+COMMENT ON TABLE "search_query_log" IS 'the queries customers searched for, no user is recorded';
+COMMENT ON COLUMN "search_query_log"."normalized_query" IS 'the query after normalize_search_text, the queries are grouped by it';
+COMMENT ON COLUMN "search_query_log"."result_count" IS 'the number of results found for the query, the paginated searches count their first page, 0 when nothing was found';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "product_category_parent_category_id_idx";
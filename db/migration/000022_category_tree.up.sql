@@ -0,0 +1,2 @@
+-- the category tree is walked from the parents to their subcategories
+CREATE INDEX "product_category_parent_category_id_idx" ON "product_category" ("parent_category_id");
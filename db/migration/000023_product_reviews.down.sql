@@ -0,0 +1,24 @@
+DROP TRIGGER IF EXISTS user_review_rating_refresh ON "user_review";
+
+DROP FUNCTION IF EXISTS user_review_rating_refresh();
+
+DROP FUNCTION IF EXISTS product_rating_refresh(bigint);
+
+ALTER TABLE "product"
+  DROP COLUMN "rating_average",
+  DROP COLUMN "rating_count";
+
+DROP TABLE IF EXISTS "user_review_image";
+
+DROP TRIGGER IF EXISTS user_review_set_product ON "user_review";
+
+DROP FUNCTION IF EXISTS user_review_set_product();
+
+ALTER TABLE "user_review"
+  DROP COLUMN "product_id",
+  DROP COLUMN "title",
+  DROP COLUMN "body",
+  DROP COLUMN "status",
+  DROP COLUMN "admin_reply",
+  DROP COLUMN "replied_at",
+  DROP COLUMN "moderated_at";
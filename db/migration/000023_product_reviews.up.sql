@@ -0,0 +1,94 @@
+ALTER TABLE "user_review"
+  ADD COLUMN "product_id" bigint,
+  ADD COLUMN "title" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "body" varchar NOT NULL DEFAULT '',
+  -- the reviews written before the moderation only hold a rating, they stay visible
+  ADD COLUMN "status" varchar NOT NULL DEFAULT 'approved',
+  ADD COLUMN "admin_reply" varchar,
+  ADD COLUMN "replied_at" timestamptz,
+  ADD COLUMN "moderated_at" timestamptz;
+
+ALTER TABLE "user_review" ALTER COLUMN "status" SET DEFAULT 'pending';
+
+ALTER TABLE "user_review" ADD CONSTRAINT "user_review_status_check" CHECK ("status" IN ('pending', 'approved', 'hidden'));
+
+UPDATE "user_review" AS ur
+SET product_id = pi.product_id
+FROM "shop_order_item" AS soi
+INNER JOIN "product_item" AS pi ON pi.id = soi.product_item_id
+WHERE soi.id = ur.ordered_product_id;
+
+ALTER TABLE "user_review" ALTER COLUMN "product_id" SET NOT NULL;
+
+ALTER TABLE "user_review" ADD FOREIGN KEY ("product_id") REFERENCES "product" ("id");
+
+-- the product of a review is copied from its ordered product so the reviews of a product are listed without the joins
+CREATE OR REPLACE FUNCTION user_review_set_product() RETURNS trigger AS $$
+BEGIN
+  NEW.product_id := (
+    SELECT pi.product_id
+    FROM "shop_order_item" AS soi
+    INNER JOIN "product_item" AS pi ON pi.id = soi.product_item_id
+    WHERE soi.id = NEW.ordered_product_id
+  );
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_review_set_product
+BEFORE INSERT OR UPDATE OF ordered_product_id ON "user_review"
+FOR EACH ROW EXECUTE FUNCTION user_review_set_product();
+
+CREATE INDEX ON "user_review" ("product_id", "status");
+
+CREATE INDEX ON "user_review" ("status", "created_at");
+
+CREATE TABLE "user_review_image" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "review_id" bigint NOT NULL,
+  "image_url" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_review_image" ADD FOREIGN KEY ("review_id") REFERENCES "user_review" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "user_review_image" ("review_id");
+
+-- the ratings are kept on the product so the listings don't aggregate the reviews of every product they return
+ALTER TABLE "product"
+  ADD COLUMN "rating_average" double precision NOT NULL DEFAULT 0,
+  ADD COLUMN "rating_count" bigint NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION product_rating_refresh(target_product_id bigint) RETURNS void AS $$
+  UPDATE "product" AS p
+  SET rating_average = r.rating_average, rating_count = r.rating_count
+  FROM (
+    SELECT COALESCE(ROUND(AVG(rating_value), 2), 0)::float8 AS rating_average, COUNT(*) AS rating_count
+    FROM "user_review"
+    WHERE product_id = target_product_id
+    AND status = 'approved'
+  ) AS r
+  WHERE p.id = target_product_id;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION user_review_rating_refresh() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    PERFORM product_rating_refresh(OLD.product_id);
+  END IF;
+  IF TG_OP <> 'DELETE' AND (TG_OP = 'INSERT' OR NEW.product_id <> OLD.product_id) THEN
+    PERFORM product_rating_refresh(NEW.product_id);
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- only the approved reviews are counted, so a review changes the ratings when it's approved or hidden
+CREATE TRIGGER user_review_rating_refresh
+AFTER INSERT OR DELETE OR UPDATE OF ordered_product_id, rating_value, status ON "user_review"
+FOR EACH ROW EXECUTE FUNCTION user_review_rating_refresh();
+
+SELECT product_rating_refresh(id) FROM "product";
+
+COMMENT ON COLUMN "user_review"."status" IS 'pending until an admin approves or hides the review, only the approved reviews are shown and counted';
+COMMENT ON COLUMN "product"."rating_average" IS 'the average rating of the approved reviews, kept by the user_review_rating_refresh trigger';
@@ -73,6 +73,21 @@ func (mr *MockStoreMockRecorder) AdminCreateCategoryPromotion(ctx, arg any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminCreateCategoryPromotion", reflect.TypeOf((*MockStore)(nil).AdminCreateCategoryPromotion), ctx, arg)
 }
 
+// AdminCreateCoupon mocks base method.
+func (m *MockStore) AdminCreateCoupon(ctx context.Context, arg db.AdminCreateCouponParams) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminCreateCoupon", ctx, arg)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminCreateCoupon indicates an expected call of AdminCreateCoupon.
+func (mr *MockStoreMockRecorder) AdminCreateCoupon(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminCreateCoupon", reflect.TypeOf((*MockStore)(nil).AdminCreateCoupon), ctx, arg)
+}
+
 // AdminCreateFeaturedProductItem mocks base method.
 func (m *MockStore) AdminCreateFeaturedProductItem(ctx context.Context, arg db.AdminCreateFeaturedProductItemParams) (*db.FeaturedProductItem, error) {
 	m.ctrl.T.Helper()
@@ -253,6 +268,21 @@ func (mr *MockStoreMockRecorder) AdminCreateShippingMethod(ctx, arg any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminCreateShippingMethod", reflect.TypeOf((*MockStore)(nil).AdminCreateShippingMethod), ctx, arg)
 }
 
+// AdminDeleteCoupon mocks base method.
+func (m *MockStore) AdminDeleteCoupon(ctx context.Context, arg db.AdminDeleteCouponParams) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminDeleteCoupon", ctx, arg)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminDeleteCoupon indicates an expected call of AdminDeleteCoupon.
+func (mr *MockStoreMockRecorder) AdminDeleteCoupon(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminDeleteCoupon", reflect.TypeOf((*MockStore)(nil).AdminDeleteCoupon), ctx, arg)
+}
+
 // AdminDeletePaymentType mocks base method.
 func (m *MockStore) AdminDeletePaymentType(ctx context.Context, arg db.AdminDeletePaymentTypeParams) error {
 	m.ctrl.T.Helper()
@@ -281,6 +311,36 @@ func (mr *MockStoreMockRecorder) AdminDeleteProduct(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminDeleteProduct", reflect.TypeOf((*MockStore)(nil).AdminDeleteProduct), ctx, arg)
 }
 
+// AdminGetCoupon mocks base method.
+func (m *MockStore) AdminGetCoupon(ctx context.Context, arg db.AdminGetCouponParams) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminGetCoupon", ctx, arg)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminGetCoupon indicates an expected call of AdminGetCoupon.
+func (mr *MockStoreMockRecorder) AdminGetCoupon(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminGetCoupon", reflect.TypeOf((*MockStore)(nil).AdminGetCoupon), ctx, arg)
+}
+
+// AdminHasPermission mocks base method.
+func (m *MockStore) AdminHasPermission(ctx context.Context, arg db.AdminHasPermissionParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminHasPermission", ctx, arg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminHasPermission indicates an expected call of AdminHasPermission.
+func (mr *MockStoreMockRecorder) AdminHasPermission(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminHasPermission", reflect.TypeOf((*MockStore)(nil).AdminHasPermission), ctx, arg)
+}
+
 // AdminListBrandPromotions mocks base method.
 func (m *MockStore) AdminListBrandPromotions(ctx context.Context, adminID int64) ([]*db.AdminListBrandPromotionsRow, error) {
 	m.ctrl.T.Helper()
@@ -311,6 +371,21 @@ func (mr *MockStoreMockRecorder) AdminListCategoryPromotions(ctx, adminID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListCategoryPromotions", reflect.TypeOf((*MockStore)(nil).AdminListCategoryPromotions), ctx, adminID)
 }
 
+// AdminListCoupons mocks base method.
+func (m *MockStore) AdminListCoupons(ctx context.Context, arg db.AdminListCouponsParams) ([]*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminListCoupons", ctx, arg)
+	ret0, _ := ret[0].([]*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminListCoupons indicates an expected call of AdminListCoupons.
+func (mr *MockStoreMockRecorder) AdminListCoupons(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListCoupons", reflect.TypeOf((*MockStore)(nil).AdminListCoupons), ctx, arg)
+}
+
 // AdminListFeaturedProductItems mocks base method.
 func (m *MockStore) AdminListFeaturedProductItems(ctx context.Context, adminID int64) ([]*db.AdminListFeaturedProductItemsRow, error) {
 	m.ctrl.T.Helper()
@@ -371,6 +446,21 @@ func (mr *MockStoreMockRecorder) AdminListProductPromotions(ctx, adminID any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListProductPromotions", reflect.TypeOf((*MockStore)(nil).AdminListProductPromotions), ctx, adminID)
 }
 
+// AdminListReturnRequests mocks base method.
+func (m *MockStore) AdminListReturnRequests(ctx context.Context, arg db.AdminListReturnRequestsParams) ([]*db.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminListReturnRequests", ctx, arg)
+	ret0, _ := ret[0].([]*db.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminListReturnRequests indicates an expected call of AdminListReturnRequests.
+func (mr *MockStoreMockRecorder) AdminListReturnRequests(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListReturnRequests", reflect.TypeOf((*MockStore)(nil).AdminListReturnRequests), ctx, arg)
+}
+
 // AdminListShopOrdersNextPage mocks base method.
 func (m *MockStore) AdminListShopOrdersNextPage(ctx context.Context, arg db.AdminListShopOrdersNextPageParams) ([]*db.AdminListShopOrdersNextPageRow, error) {
 	m.ctrl.T.Helper()
@@ -446,6 +536,21 @@ func (mr *MockStoreMockRecorder) AdminUpdateCategoryPromotion(ctx, arg any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminUpdateCategoryPromotion", reflect.TypeOf((*MockStore)(nil).AdminUpdateCategoryPromotion), ctx, arg)
 }
 
+// AdminUpdateCoupon mocks base method.
+func (m *MockStore) AdminUpdateCoupon(ctx context.Context, arg db.AdminUpdateCouponParams) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminUpdateCoupon", ctx, arg)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminUpdateCoupon indicates an expected call of AdminUpdateCoupon.
+func (mr *MockStoreMockRecorder) AdminUpdateCoupon(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminUpdateCoupon", reflect.TypeOf((*MockStore)(nil).AdminUpdateCoupon), ctx, arg)
+}
+
 // AdminUpdateFeaturedProductItem mocks base method.
 func (m *MockStore) AdminUpdateFeaturedProductItem(ctx context.Context, arg db.AdminUpdateFeaturedProductItemParams) (*db.FeaturedProductItem, error) {
 	m.ctrl.T.Helper()
@@ -581,6 +686,21 @@ func (mr *MockStoreMockRecorder) AdminUpdatePromotion(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminUpdatePromotion", reflect.TypeOf((*MockStore)(nil).AdminUpdatePromotion), ctx, arg)
 }
 
+// AdminUpdateReturnRequest mocks base method.
+func (m *MockStore) AdminUpdateReturnRequest(ctx context.Context, arg db.AdminUpdateReturnRequestParams) (*db.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminUpdateReturnRequest", ctx, arg)
+	ret0, _ := ret[0].(*db.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminUpdateReturnRequest indicates an expected call of AdminUpdateReturnRequest.
+func (mr *MockStoreMockRecorder) AdminUpdateReturnRequest(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminUpdateReturnRequest", reflect.TypeOf((*MockStore)(nil).AdminUpdateReturnRequest), ctx, arg)
+}
+
 // AdminUpdateShippingMethod mocks base method.
 func (m *MockStore) AdminUpdateShippingMethod(ctx context.Context, arg db.AdminUpdateShippingMethodParams) (*db.ShippingMethod, error) {
 	m.ctrl.T.Helper()
@@ -611,6 +731,141 @@ func (mr *MockStoreMockRecorder) AdminUpdateUser(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminUpdateUser", reflect.TypeOf((*MockStore)(nil).AdminUpdateUser), ctx, arg)
 }
 
+// AnonymizeUser mocks base method.
+func (m *MockStore) AnonymizeUser(ctx context.Context, id int64) (*db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", ctx, id)
+	ret0, _ := ret[0].(*db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockStoreMockRecorder) AnonymizeUser(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockStore)(nil).AnonymizeUser), ctx, id)
+}
+
+// AnonymizeUserTx mocks base method.
+func (m *MockStore) AnonymizeUserTx(ctx context.Context, userID int64) (*db.AnonymizeUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUserTx", ctx, userID)
+	ret0, _ := ret[0].(*db.AnonymizeUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUserTx indicates an expected call of AnonymizeUserTx.
+func (mr *MockStoreMockRecorder) AnonymizeUserTx(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUserTx", reflect.TypeOf((*MockStore)(nil).AnonymizeUserTx), ctx, userID)
+}
+
+// CancelShopOrderTx mocks base method.
+func (m *MockStore) CancelShopOrderTx(ctx context.Context, arg db.CancelShopOrderTxParams) (*db.CancelShopOrderTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelShopOrderTx", ctx, arg)
+	ret0, _ := ret[0].(*db.CancelShopOrderTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelShopOrderTx indicates an expected call of CancelShopOrderTx.
+func (mr *MockStoreMockRecorder) CancelShopOrderTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelShopOrderTx", reflect.TypeOf((*MockStore)(nil).CancelShopOrderTx), ctx, arg)
+}
+
+// CancelUserDeletion mocks base method.
+func (m *MockStore) CancelUserDeletion(ctx context.Context, userID int64) (*db.UserDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelUserDeletion", ctx, userID)
+	ret0, _ := ret[0].(*db.UserDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelUserDeletion indicates an expected call of CancelUserDeletion.
+func (mr *MockStoreMockRecorder) CancelUserDeletion(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelUserDeletion", reflect.TypeOf((*MockStore)(nil).CancelUserDeletion), ctx, userID)
+}
+
+// ChangeUserEmailTx mocks base method.
+func (m *MockStore) ChangeUserEmailTx(ctx context.Context, arg db.ChangeUserEmailTxParams) (*db.ChangeUserEmailTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserEmailTx", ctx, arg)
+	ret0, _ := ret[0].(*db.ChangeUserEmailTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeUserEmailTx indicates an expected call of ChangeUserEmailTx.
+func (mr *MockStoreMockRecorder) ChangeUserEmailTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserEmailTx", reflect.TypeOf((*MockStore)(nil).ChangeUserEmailTx), ctx, arg)
+}
+
+// CompleteRefundPaymentTransactionTx mocks base method.
+func (m *MockStore) CompleteRefundPaymentTransactionTx(ctx context.Context, arg db.CompleteRefundPaymentTransactionTxParams) (*db.CompleteRefundPaymentTransactionTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRefundPaymentTransactionTx", ctx, arg)
+	ret0, _ := ret[0].(*db.CompleteRefundPaymentTransactionTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteRefundPaymentTransactionTx indicates an expected call of CompleteRefundPaymentTransactionTx.
+func (mr *MockStoreMockRecorder) CompleteRefundPaymentTransactionTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRefundPaymentTransactionTx", reflect.TypeOf((*MockStore)(nil).CompleteRefundPaymentTransactionTx), ctx, arg)
+}
+
+// CountAdminRecoveryCodesLeft mocks base method.
+func (m *MockStore) CountAdminRecoveryCodesLeft(ctx context.Context, adminID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAdminRecoveryCodesLeft", ctx, adminID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAdminRecoveryCodesLeft indicates an expected call of CountAdminRecoveryCodesLeft.
+func (mr *MockStoreMockRecorder) CountAdminRecoveryCodesLeft(ctx, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAdminRecoveryCodesLeft", reflect.TypeOf((*MockStore)(nil).CountAdminRecoveryCodesLeft), ctx, adminID)
+}
+
+// CountCouponRedemptionsByUser mocks base method.
+func (m *MockStore) CountCouponRedemptionsByUser(ctx context.Context, arg db.CountCouponRedemptionsByUserParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCouponRedemptionsByUser", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCouponRedemptionsByUser indicates an expected call of CountCouponRedemptionsByUser.
+func (mr *MockStoreMockRecorder) CountCouponRedemptionsByUser(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCouponRedemptionsByUser", reflect.TypeOf((*MockStore)(nil).CountCouponRedemptionsByUser), ctx, arg)
+}
+
+// CountUserReviewImages mocks base method.
+func (m *MockStore) CountUserReviewImages(ctx context.Context, reviewID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserReviewImages", ctx, reviewID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserReviewImages indicates an expected call of CountUserReviewImages.
+func (mr *MockStoreMockRecorder) CountUserReviewImages(ctx, reviewID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserReviewImages", reflect.TypeOf((*MockStore)(nil).CountUserReviewImages), ctx, reviewID)
+}
+
 // CreateAddress mocks base method.
 func (m *MockStore) CreateAddress(ctx context.Context, arg db.CreateAddressParams) (*db.Address, error) {
 	m.ctrl.T.Helper()
@@ -641,6 +896,81 @@ func (mr *MockStoreMockRecorder) CreateAdmin(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdmin", reflect.TypeOf((*MockStore)(nil).CreateAdmin), ctx, arg)
 }
 
+// CreateAdminLoginChallenge mocks base method.
+func (m *MockStore) CreateAdminLoginChallenge(ctx context.Context, arg db.CreateAdminLoginChallengeParams) (*db.AdminLoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdminLoginChallenge", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminLoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdminLoginChallenge indicates an expected call of CreateAdminLoginChallenge.
+func (mr *MockStoreMockRecorder) CreateAdminLoginChallenge(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdminLoginChallenge", reflect.TypeOf((*MockStore)(nil).CreateAdminLoginChallenge), ctx, arg)
+}
+
+// CreateAdminRole mocks base method.
+func (m *MockStore) CreateAdminRole(ctx context.Context, arg db.CreateAdminRoleParams) (*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdminRole", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdminRole indicates an expected call of CreateAdminRole.
+func (mr *MockStoreMockRecorder) CreateAdminRole(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdminRole", reflect.TypeOf((*MockStore)(nil).CreateAdminRole), ctx, arg)
+}
+
+// CreateAdminRoleAssignment mocks base method.
+func (m *MockStore) CreateAdminRoleAssignment(ctx context.Context, arg db.CreateAdminRoleAssignmentParams) (*db.AdminRoleAssignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdminRoleAssignment", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRoleAssignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdminRoleAssignment indicates an expected call of CreateAdminRoleAssignment.
+func (mr *MockStoreMockRecorder) CreateAdminRoleAssignment(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdminRoleAssignment", reflect.TypeOf((*MockStore)(nil).CreateAdminRoleAssignment), ctx, arg)
+}
+
+// CreateAdminRolePermission mocks base method.
+func (m *MockStore) CreateAdminRolePermission(ctx context.Context, arg db.CreateAdminRolePermissionParams) (*db.AdminRolePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdminRolePermission", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRolePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdminRolePermission indicates an expected call of CreateAdminRolePermission.
+func (mr *MockStoreMockRecorder) CreateAdminRolePermission(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdminRolePermission", reflect.TypeOf((*MockStore)(nil).CreateAdminRolePermission), ctx, arg)
+}
+
+// CreateAdminRoleTx mocks base method.
+func (m *MockStore) CreateAdminRoleTx(ctx context.Context, arg db.CreateAdminRoleTxParams) (*db.AdminRoleTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdminRoleTx", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRoleTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdminRoleTx indicates an expected call of CreateAdminRoleTx.
+func (mr *MockStoreMockRecorder) CreateAdminRoleTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdminRoleTx", reflect.TypeOf((*MockStore)(nil).CreateAdminRoleTx), ctx, arg)
+}
+
 // CreateAdminSession mocks base method.
 func (m *MockStore) CreateAdminSession(ctx context.Context, arg db.CreateAdminSessionParams) (*db.AdminSession, error) {
 	m.ctrl.T.Helper()
@@ -716,6 +1046,36 @@ func (mr *MockStoreMockRecorder) CreateCategoryPromotion(ctx, arg any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryPromotion", reflect.TypeOf((*MockStore)(nil).CreateCategoryPromotion), ctx, arg)
 }
 
+// CreateCouponRedemption mocks base method.
+func (m *MockStore) CreateCouponRedemption(ctx context.Context, arg db.CreateCouponRedemptionParams) (*db.CouponRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCouponRedemption", ctx, arg)
+	ret0, _ := ret[0].(*db.CouponRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCouponRedemption indicates an expected call of CreateCouponRedemption.
+func (mr *MockStoreMockRecorder) CreateCouponRedemption(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCouponRedemption", reflect.TypeOf((*MockStore)(nil).CreateCouponRedemption), ctx, arg)
+}
+
+// CreateEmailChange mocks base method.
+func (m *MockStore) CreateEmailChange(ctx context.Context, arg db.CreateEmailChangeParams) (*db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChange", ctx, arg)
+	ret0, _ := ret[0].(*db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChange indicates an expected call of CreateEmailChange.
+func (mr *MockStoreMockRecorder) CreateEmailChange(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChange", reflect.TypeOf((*MockStore)(nil).CreateEmailChange), ctx, arg)
+}
+
 // CreateHomePageTextBanner mocks base method.
 func (m *MockStore) CreateHomePageTextBanner(ctx context.Context, arg db.CreateHomePageTextBannerParams) (*db.HomePageTextBanner, error) {
 	m.ctrl.T.Helper()
@@ -731,6 +1091,21 @@ func (mr *MockStoreMockRecorder) CreateHomePageTextBanner(ctx, arg any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHomePageTextBanner", reflect.TypeOf((*MockStore)(nil).CreateHomePageTextBanner), ctx, arg)
 }
 
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (*db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ctx, arg)
+	ret0, _ := ret[0].(*db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), ctx, arg)
+}
+
 // CreateNotification mocks base method.
 func (m *MockStore) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (*db.Notification, error) {
 	m.ctrl.T.Helper()
@@ -776,6 +1151,21 @@ func (mr *MockStoreMockRecorder) CreatePaymentMethod(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentMethod", reflect.TypeOf((*MockStore)(nil).CreatePaymentMethod), ctx, arg)
 }
 
+// CreatePaymentTransaction mocks base method.
+func (m *MockStore) CreatePaymentTransaction(ctx context.Context, arg db.CreatePaymentTransactionParams) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentTransaction", ctx, arg)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentTransaction indicates an expected call of CreatePaymentTransaction.
+func (mr *MockStoreMockRecorder) CreatePaymentTransaction(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentTransaction", reflect.TypeOf((*MockStore)(nil).CreatePaymentTransaction), ctx, arg)
+}
+
 // CreatePaymentType mocks base method.
 func (m *MockStore) CreatePaymentType(ctx context.Context, value string) (*db.PaymentType, error) {
 	m.ctrl.T.Helper()
@@ -956,6 +1346,65 @@ func (mr *MockStoreMockRecorder) CreateResetPassword(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResetPassword", reflect.TypeOf((*MockStore)(nil).CreateResetPassword), ctx, arg)
 }
 
+// CreateReturnRequest mocks base method.
+func (m *MockStore) CreateReturnRequest(ctx context.Context, arg db.CreateReturnRequestParams) (*db.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReturnRequest", ctx, arg)
+	ret0, _ := ret[0].(*db.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReturnRequest indicates an expected call of CreateReturnRequest.
+func (mr *MockStoreMockRecorder) CreateReturnRequest(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReturnRequest", reflect.TypeOf((*MockStore)(nil).CreateReturnRequest), ctx, arg)
+}
+
+// CreateReturnRequestItem mocks base method.
+func (m *MockStore) CreateReturnRequestItem(ctx context.Context, arg db.CreateReturnRequestItemParams) (*db.ReturnRequestItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReturnRequestItem", ctx, arg)
+	ret0, _ := ret[0].(*db.ReturnRequestItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReturnRequestItem indicates an expected call of CreateReturnRequestItem.
+func (mr *MockStoreMockRecorder) CreateReturnRequestItem(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReturnRequestItem", reflect.TypeOf((*MockStore)(nil).CreateReturnRequestItem), ctx, arg)
+}
+
+// CreateReturnRequestTx mocks base method.
+func (m *MockStore) CreateReturnRequestTx(ctx context.Context, arg db.CreateReturnRequestTxParams) (*db.CreateReturnRequestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReturnRequestTx", ctx, arg)
+	ret0, _ := ret[0].(*db.CreateReturnRequestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReturnRequestTx indicates an expected call of CreateReturnRequestTx.
+func (mr *MockStoreMockRecorder) CreateReturnRequestTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReturnRequestTx", reflect.TypeOf((*MockStore)(nil).CreateReturnRequestTx), ctx, arg)
+}
+
+// CreateSearchQueryLog mocks base method.
+func (m *MockStore) CreateSearchQueryLog(ctx context.Context, arg db.CreateSearchQueryLogParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSearchQueryLog", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSearchQueryLog indicates an expected call of CreateSearchQueryLog.
+func (mr *MockStoreMockRecorder) CreateSearchQueryLog(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSearchQueryLog", reflect.TypeOf((*MockStore)(nil).CreateSearchQueryLog), ctx, arg)
+}
+
 // CreateShippingMethod mocks base method.
 func (m *MockStore) CreateShippingMethod(ctx context.Context, arg db.CreateShippingMethodParams) (*db.ShippingMethod, error) {
 	m.ctrl.T.Helper()
@@ -1001,6 +1450,21 @@ func (mr *MockStoreMockRecorder) CreateShopOrderItem(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShopOrderItem", reflect.TypeOf((*MockStore)(nil).CreateShopOrderItem), ctx, arg)
 }
 
+// CreateShopOrderStatusHistory mocks base method.
+func (m *MockStore) CreateShopOrderStatusHistory(ctx context.Context, arg db.CreateShopOrderStatusHistoryParams) (*db.ShopOrderStatusHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShopOrderStatusHistory", ctx, arg)
+	ret0, _ := ret[0].(*db.ShopOrderStatusHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShopOrderStatusHistory indicates an expected call of CreateShopOrderStatusHistory.
+func (mr *MockStoreMockRecorder) CreateShopOrderStatusHistory(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShopOrderStatusHistory", reflect.TypeOf((*MockStore)(nil).CreateShopOrderStatusHistory), ctx, arg)
+}
+
 // CreateShoppingCart mocks base method.
 func (m *MockStore) CreateShoppingCart(ctx context.Context, userID int64) (*db.ShoppingCart, error) {
 	m.ctrl.T.Helper()
@@ -1031,11 +1495,26 @@ func (mr *MockStoreMockRecorder) CreateShoppingCartItem(ctx, arg any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShoppingCartItem", reflect.TypeOf((*MockStore)(nil).CreateShoppingCartItem), ctx, arg)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (*db.User, error) {
+// CreateStockReservation mocks base method.
+func (m *MockStore) CreateStockReservation(ctx context.Context, arg db.CreateStockReservationParams) (*db.StockReservation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
-	ret0, _ := ret[0].(*db.User)
+	ret := m.ctrl.Call(m, "CreateStockReservation", ctx, arg)
+	ret0, _ := ret[0].(*db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStockReservation indicates an expected call of CreateStockReservation.
+func (mr *MockStoreMockRecorder) CreateStockReservation(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStockReservation", reflect.TypeOf((*MockStore)(nil).CreateStockReservation), ctx, arg)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (*db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
+	ret0, _ := ret[0].(*db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1061,6 +1540,21 @@ func (mr *MockStoreMockRecorder) CreateUserReview(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserReview", reflect.TypeOf((*MockStore)(nil).CreateUserReview), ctx, arg)
 }
 
+// CreateUserReviewImage mocks base method.
+func (m *MockStore) CreateUserReviewImage(ctx context.Context, arg db.CreateUserReviewImageParams) (*db.UserReviewImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserReviewImage", ctx, arg)
+	ret0, _ := ret[0].(*db.UserReviewImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserReviewImage indicates an expected call of CreateUserReviewImage.
+func (mr *MockStoreMockRecorder) CreateUserReviewImage(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserReviewImage", reflect.TypeOf((*MockStore)(nil).CreateUserReviewImage), ctx, arg)
+}
+
 // CreateUserSession mocks base method.
 func (m *MockStore) CreateUserSession(ctx context.Context, arg db.CreateUserSessionParams) (*db.UserSession, error) {
 	m.ctrl.T.Helper()
@@ -1121,6 +1615,21 @@ func (mr *MockStoreMockRecorder) CreateVariationOption(ctx, arg any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariationOption", reflect.TypeOf((*MockStore)(nil).CreateVariationOption), ctx, arg)
 }
 
+// CreateVerifiedUserWithCartAndWishList mocks base method.
+func (m *MockStore) CreateVerifiedUserWithCartAndWishList(ctx context.Context, arg db.CreateVerifiedUserWithCartAndWishListParams) (*db.CreateVerifiedUserWithCartAndWishListRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVerifiedUserWithCartAndWishList", ctx, arg)
+	ret0, _ := ret[0].(*db.CreateVerifiedUserWithCartAndWishListRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVerifiedUserWithCartAndWishList indicates an expected call of CreateVerifiedUserWithCartAndWishList.
+func (mr *MockStoreMockRecorder) CreateVerifiedUserWithCartAndWishList(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifiedUserWithCartAndWishList", reflect.TypeOf((*MockStore)(nil).CreateVerifiedUserWithCartAndWishList), ctx, arg)
+}
+
 // CreateVerifyEmail mocks base method.
 func (m *MockStore) CreateVerifyEmail(ctx context.Context, arg db.CreateVerifyEmailParams) (*db.VerifyEmail, error) {
 	m.ctrl.T.Helper()
@@ -1166,6 +1675,21 @@ func (mr *MockStoreMockRecorder) CreateWishListItem(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWishListItem", reflect.TypeOf((*MockStore)(nil).CreateWishListItem), ctx, arg)
 }
 
+// DecrementCouponUsedCount mocks base method.
+func (m *MockStore) DecrementCouponUsedCount(ctx context.Context, id int64) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrementCouponUsedCount", ctx, id)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecrementCouponUsedCount indicates an expected call of DecrementCouponUsedCount.
+func (mr *MockStoreMockRecorder) DecrementCouponUsedCount(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementCouponUsedCount", reflect.TypeOf((*MockStore)(nil).DecrementCouponUsedCount), ctx, id)
+}
+
 // DeleteAddress mocks base method.
 func (m *MockStore) DeleteAddress(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1180,6 +1704,21 @@ func (mr *MockStoreMockRecorder) DeleteAddress(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockStore)(nil).DeleteAddress), ctx, id)
 }
 
+// DeleteAddressesByUserID mocks base method.
+func (m *MockStore) DeleteAddressesByUserID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddressesByUserID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAddressesByUserID indicates an expected call of DeleteAddressesByUserID.
+func (mr *MockStoreMockRecorder) DeleteAddressesByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddressesByUserID", reflect.TypeOf((*MockStore)(nil).DeleteAddressesByUserID), ctx, userID)
+}
+
 // DeleteAdmin mocks base method.
 func (m *MockStore) DeleteAdmin(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1194,6 +1733,78 @@ func (mr *MockStoreMockRecorder) DeleteAdmin(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdmin", reflect.TypeOf((*MockStore)(nil).DeleteAdmin), ctx, id)
 }
 
+// DeleteAdminRole mocks base method.
+func (m *MockStore) DeleteAdminRole(ctx context.Context, id int64) (*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAdminRole", ctx, id)
+	ret0, _ := ret[0].(*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAdminRole indicates an expected call of DeleteAdminRole.
+func (mr *MockStoreMockRecorder) DeleteAdminRole(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdminRole", reflect.TypeOf((*MockStore)(nil).DeleteAdminRole), ctx, id)
+}
+
+// DeleteAdminRoleAssignmentsByAdminID mocks base method.
+func (m *MockStore) DeleteAdminRoleAssignmentsByAdminID(ctx context.Context, adminID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAdminRoleAssignmentsByAdminID", ctx, adminID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAdminRoleAssignmentsByAdminID indicates an expected call of DeleteAdminRoleAssignmentsByAdminID.
+func (mr *MockStoreMockRecorder) DeleteAdminRoleAssignmentsByAdminID(ctx, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdminRoleAssignmentsByAdminID", reflect.TypeOf((*MockStore)(nil).DeleteAdminRoleAssignmentsByAdminID), ctx, adminID)
+}
+
+// DeleteAdminRolePermissionsByRoleID mocks base method.
+func (m *MockStore) DeleteAdminRolePermissionsByRoleID(ctx context.Context, roleID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAdminRolePermissionsByRoleID", ctx, roleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAdminRolePermissionsByRoleID indicates an expected call of DeleteAdminRolePermissionsByRoleID.
+func (mr *MockStoreMockRecorder) DeleteAdminRolePermissionsByRoleID(ctx, roleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdminRolePermissionsByRoleID", reflect.TypeOf((*MockStore)(nil).DeleteAdminRolePermissionsByRoleID), ctx, roleID)
+}
+
+// DeleteAdminRoleTx mocks base method.
+func (m *MockStore) DeleteAdminRoleTx(ctx context.Context, id int64) (*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAdminRoleTx", ctx, id)
+	ret0, _ := ret[0].(*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAdminRoleTx indicates an expected call of DeleteAdminRoleTx.
+func (mr *MockStoreMockRecorder) DeleteAdminRoleTx(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdminRoleTx", reflect.TypeOf((*MockStore)(nil).DeleteAdminRoleTx), ctx, id)
+}
+
+// DeleteAdminTotp mocks base method.
+func (m *MockStore) DeleteAdminTotp(ctx context.Context, adminID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAdminTotp", ctx, adminID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAdminTotp indicates an expected call of DeleteAdminTotp.
+func (mr *MockStoreMockRecorder) DeleteAdminTotp(ctx, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdminTotp", reflect.TypeOf((*MockStore)(nil).DeleteAdminTotp), ctx, adminID)
+}
+
 // DeleteAdminTypeByID mocks base method.
 func (m *MockStore) DeleteAdminTypeByID(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1265,6 +1876,65 @@ func (mr *MockStoreMockRecorder) DeleteCategoryPromotion(ctx, arg any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryPromotion", reflect.TypeOf((*MockStore)(nil).DeleteCategoryPromotion), ctx, arg)
 }
 
+// DeleteCouponRedemptionByShopOrderID mocks base method.
+func (m *MockStore) DeleteCouponRedemptionByShopOrderID(ctx context.Context, shopOrderID int64) (*db.CouponRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCouponRedemptionByShopOrderID", ctx, shopOrderID)
+	ret0, _ := ret[0].(*db.CouponRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCouponRedemptionByShopOrderID indicates an expected call of DeleteCouponRedemptionByShopOrderID.
+func (mr *MockStoreMockRecorder) DeleteCouponRedemptionByShopOrderID(ctx, shopOrderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCouponRedemptionByShopOrderID", reflect.TypeOf((*MockStore)(nil).DeleteCouponRedemptionByShopOrderID), ctx, shopOrderID)
+}
+
+// DeleteEmailChangesByUserID mocks base method.
+func (m *MockStore) DeleteEmailChangesByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmailChangesByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEmailChangesByUserID indicates an expected call of DeleteEmailChangesByUserID.
+func (mr *MockStoreMockRecorder) DeleteEmailChangesByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailChangesByUserID", reflect.TypeOf((*MockStore)(nil).DeleteEmailChangesByUserID), ctx, userID)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredIdempotencyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredIdempotencyKeys), ctx)
+}
+
+// DeleteExpiredStockReservations mocks base method.
+func (m *MockStore) DeleteExpiredStockReservations(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredStockReservations", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredStockReservations indicates an expected call of DeleteExpiredStockReservations.
+func (mr *MockStoreMockRecorder) DeleteExpiredStockReservations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredStockReservations", reflect.TypeOf((*MockStore)(nil).DeleteExpiredStockReservations), ctx)
+}
+
 // DeleteFeaturedProductItem mocks base method.
 func (m *MockStore) DeleteFeaturedProductItem(ctx context.Context, arg db.DeleteFeaturedProductItemParams) error {
 	m.ctrl.T.Helper()
@@ -1293,6 +1963,20 @@ func (mr *MockStoreMockRecorder) DeleteHomePageTextBanner(ctx, arg any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHomePageTextBanner", reflect.TypeOf((*MockStore)(nil).DeleteHomePageTextBanner), ctx, arg)
 }
 
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), ctx, arg)
+}
+
 // DeleteNotification mocks base method.
 func (m *MockStore) DeleteNotification(ctx context.Context, arg db.DeleteNotificationParams) (*db.Notification, error) {
 	m.ctrl.T.Helper()
@@ -1322,6 +2006,21 @@ func (mr *MockStoreMockRecorder) DeleteNotificationAllByUser(ctx, userID any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotificationAllByUser", reflect.TypeOf((*MockStore)(nil).DeleteNotificationAllByUser), ctx, userID)
 }
 
+// DeleteOldSearchQueryLogs mocks base method.
+func (m *MockStore) DeleteOldSearchQueryLogs(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldSearchQueryLogs", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOldSearchQueryLogs indicates an expected call of DeleteOldSearchQueryLogs.
+func (mr *MockStoreMockRecorder) DeleteOldSearchQueryLogs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldSearchQueryLogs", reflect.TypeOf((*MockStore)(nil).DeleteOldSearchQueryLogs), ctx)
+}
+
 // DeleteOrderStatus mocks base method.
 func (m *MockStore) DeleteOrderStatus(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1519,6 +2218,20 @@ func (mr *MockStoreMockRecorder) DeletePromotion(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePromotion", reflect.TypeOf((*MockStore)(nil).DeletePromotion), ctx, id)
 }
 
+// DeleteResetPasswordsByUserID mocks base method.
+func (m *MockStore) DeleteResetPasswordsByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteResetPasswordsByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteResetPasswordsByUserID indicates an expected call of DeleteResetPasswordsByUserID.
+func (mr *MockStoreMockRecorder) DeleteResetPasswordsByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteResetPasswordsByUserID", reflect.TypeOf((*MockStore)(nil).DeleteResetPasswordsByUserID), ctx, userID)
+}
+
 // DeleteShippingMethod mocks base method.
 func (m *MockStore) DeleteShippingMethod(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1563,11 +2276,12 @@ func (mr *MockStoreMockRecorder) DeleteShopOrderItem(ctx, arg any) *gomock.Call
 }
 
 // DeleteShopOrderItemTx mocks base method.
-func (m *MockStore) DeleteShopOrderItemTx(ctx context.Context, arg db.DeleteShopOrderItemTxParams) error {
+func (m *MockStore) DeleteShopOrderItemTx(ctx context.Context, arg db.DeleteShopOrderItemTxParams) (*db.DeleteShopOrderItemTxResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteShopOrderItemTx", ctx, arg)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*db.DeleteShopOrderItemTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteShopOrderItemTx indicates an expected call of DeleteShopOrderItemTx.
@@ -1619,6 +2333,21 @@ func (mr *MockStoreMockRecorder) DeleteShoppingCartItemAllByUser(ctx, arg any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShoppingCartItemAllByUser", reflect.TypeOf((*MockStore)(nil).DeleteShoppingCartItemAllByUser), ctx, arg)
 }
 
+// DeleteStockReservationsByCartID mocks base method.
+func (m *MockStore) DeleteStockReservationsByCartID(ctx context.Context, shoppingCartID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStockReservationsByCartID", ctx, shoppingCartID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStockReservationsByCartID indicates an expected call of DeleteStockReservationsByCartID.
+func (mr *MockStoreMockRecorder) DeleteStockReservationsByCartID(ctx, shoppingCartID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStockReservationsByCartID", reflect.TypeOf((*MockStore)(nil).DeleteStockReservationsByCartID), ctx, shoppingCartID)
+}
+
 // DeleteUser mocks base method.
 func (m *MockStore) DeleteUser(ctx context.Context, id int64) (*db.User, error) {
 	m.ctrl.T.Helper()
@@ -1678,6 +2407,21 @@ func (mr *MockStoreMockRecorder) DeleteUserReview(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserReview", reflect.TypeOf((*MockStore)(nil).DeleteUserReview), ctx, arg)
 }
 
+// DeleteUserSessions mocks base method.
+func (m *MockStore) DeleteUserSessions(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockStoreMockRecorder) DeleteUserSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockStore)(nil).DeleteUserSessions), ctx, userID)
+}
+
 // DeleteVariation mocks base method.
 func (m *MockStore) DeleteVariation(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1706,6 +2450,20 @@ func (mr *MockStoreMockRecorder) DeleteVariationOption(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariationOption", reflect.TypeOf((*MockStore)(nil).DeleteVariationOption), ctx, id)
 }
 
+// DeleteVerifyEmailsByUserID mocks base method.
+func (m *MockStore) DeleteVerifyEmailsByUserID(ctx context.Context, userID null.Int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVerifyEmailsByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVerifyEmailsByUserID indicates an expected call of DeleteVerifyEmailsByUserID.
+func (mr *MockStoreMockRecorder) DeleteVerifyEmailsByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVerifyEmailsByUserID", reflect.TypeOf((*MockStore)(nil).DeleteVerifyEmailsByUserID), ctx, userID)
+}
+
 // DeleteWishList mocks base method.
 func (m *MockStore) DeleteWishList(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -1749,6 +2507,36 @@ func (mr *MockStoreMockRecorder) DeleteWishListItemAll(ctx, wishListID any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWishListItemAll", reflect.TypeOf((*MockStore)(nil).DeleteWishListItemAll), ctx, wishListID)
 }
 
+// EnableAdminTotp mocks base method.
+func (m *MockStore) EnableAdminTotp(ctx context.Context, arg db.EnableAdminTotpParams) (*db.AdminTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableAdminTotp", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableAdminTotp indicates an expected call of EnableAdminTotp.
+func (mr *MockStoreMockRecorder) EnableAdminTotp(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAdminTotp", reflect.TypeOf((*MockStore)(nil).EnableAdminTotp), ctx, arg)
+}
+
+// EnableAdminTotpTx mocks base method.
+func (m *MockStore) EnableAdminTotpTx(ctx context.Context, arg db.EnableAdminTotpTxParams) (*db.AdminTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableAdminTotpTx", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableAdminTotpTx indicates an expected call of EnableAdminTotpTx.
+func (mr *MockStoreMockRecorder) EnableAdminTotpTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAdminTotpTx", reflect.TypeOf((*MockStore)(nil).EnableAdminTotpTx), ctx, arg)
+}
+
 // FinishedPurchaseTx mocks base method.
 func (m *MockStore) FinishedPurchaseTx(ctx context.Context, arg db.FinishedPurchaseTxParams) (*db.FinishedPurchaseTxResult, error) {
 	m.ctrl.T.Helper()
@@ -1854,6 +2642,66 @@ func (mr *MockStoreMockRecorder) GetAdminByEmail(ctx, email any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminByEmail", reflect.TypeOf((*MockStore)(nil).GetAdminByEmail), ctx, email)
 }
 
+// GetAdminLoginChallenge mocks base method.
+func (m *MockStore) GetAdminLoginChallenge(ctx context.Context, tokenHash string) (*db.AdminLoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminLoginChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(*db.AdminLoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminLoginChallenge indicates an expected call of GetAdminLoginChallenge.
+func (mr *MockStoreMockRecorder) GetAdminLoginChallenge(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminLoginChallenge", reflect.TypeOf((*MockStore)(nil).GetAdminLoginChallenge), ctx, tokenHash)
+}
+
+// GetAdminRole mocks base method.
+func (m *MockStore) GetAdminRole(ctx context.Context, id int64) (*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminRole", ctx, id)
+	ret0, _ := ret[0].(*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminRole indicates an expected call of GetAdminRole.
+func (mr *MockStoreMockRecorder) GetAdminRole(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminRole", reflect.TypeOf((*MockStore)(nil).GetAdminRole), ctx, id)
+}
+
+// GetAdminRoleForUpdate mocks base method.
+func (m *MockStore) GetAdminRoleForUpdate(ctx context.Context, id int64) (*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminRoleForUpdate", ctx, id)
+	ret0, _ := ret[0].(*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminRoleForUpdate indicates an expected call of GetAdminRoleForUpdate.
+func (mr *MockStoreMockRecorder) GetAdminRoleForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminRoleForUpdate", reflect.TypeOf((*MockStore)(nil).GetAdminRoleForUpdate), ctx, id)
+}
+
+// GetAdminSecuritySetting mocks base method.
+func (m *MockStore) GetAdminSecuritySetting(ctx context.Context) (*db.AdminSecuritySetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminSecuritySetting", ctx)
+	ret0, _ := ret[0].(*db.AdminSecuritySetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminSecuritySetting indicates an expected call of GetAdminSecuritySetting.
+func (mr *MockStoreMockRecorder) GetAdminSecuritySetting(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminSecuritySetting", reflect.TypeOf((*MockStore)(nil).GetAdminSecuritySetting), ctx)
+}
+
 // GetAdminSession mocks base method.
 func (m *MockStore) GetAdminSession(ctx context.Context, id uuid.UUID) (*db.AdminSession, error) {
 	m.ctrl.T.Helper()
@@ -1869,6 +2717,21 @@ func (mr *MockStoreMockRecorder) GetAdminSession(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminSession", reflect.TypeOf((*MockStore)(nil).GetAdminSession), ctx, id)
 }
 
+// GetAdminTotp mocks base method.
+func (m *MockStore) GetAdminTotp(ctx context.Context, adminID int64) (*db.AdminTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminTotp", ctx, adminID)
+	ret0, _ := ret[0].(*db.AdminTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminTotp indicates an expected call of GetAdminTotp.
+func (mr *MockStoreMockRecorder) GetAdminTotp(ctx, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminTotp", reflect.TypeOf((*MockStore)(nil).GetAdminTotp), ctx, adminID)
+}
+
 // GetAdminType mocks base method.
 func (m *MockStore) GetAdminType(ctx context.Context, id int64) (*db.AdminType, error) {
 	m.ctrl.T.Helper()
@@ -1944,6 +2807,36 @@ func (mr *MockStoreMockRecorder) GetCompletedDailyOrderTotal(ctx, adminID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompletedDailyOrderTotal", reflect.TypeOf((*MockStore)(nil).GetCompletedDailyOrderTotal), ctx, adminID)
 }
 
+// GetCouponByCodeForUpdate mocks base method.
+func (m *MockStore) GetCouponByCodeForUpdate(ctx context.Context, code string) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCouponByCodeForUpdate", ctx, code)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCouponByCodeForUpdate indicates an expected call of GetCouponByCodeForUpdate.
+func (mr *MockStoreMockRecorder) GetCouponByCodeForUpdate(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCouponByCodeForUpdate", reflect.TypeOf((*MockStore)(nil).GetCouponByCodeForUpdate), ctx, code)
+}
+
+// GetCouponRedemptionByShopOrderID mocks base method.
+func (m *MockStore) GetCouponRedemptionByShopOrderID(ctx context.Context, shopOrderID int64) (*db.CouponRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCouponRedemptionByShopOrderID", ctx, shopOrderID)
+	ret0, _ := ret[0].(*db.CouponRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCouponRedemptionByShopOrderID indicates an expected call of GetCouponRedemptionByShopOrderID.
+func (mr *MockStoreMockRecorder) GetCouponRedemptionByShopOrderID(ctx, shopOrderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCouponRedemptionByShopOrderID", reflect.TypeOf((*MockStore)(nil).GetCouponRedemptionByShopOrderID), ctx, shopOrderID)
+}
+
 // GetFeaturedProductItem mocks base method.
 func (m *MockStore) GetFeaturedProductItem(ctx context.Context, productItemID int64) (*db.FeaturedProductItem, error) {
 	m.ctrl.T.Helper()
@@ -1974,25 +2867,70 @@ func (mr *MockStoreMockRecorder) GetHomePageTextBanner(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomePageTextBanner", reflect.TypeOf((*MockStore)(nil).GetHomePageTextBanner), ctx, id)
 }
 
-// GetLastUsedResetPassword mocks base method.
-func (m *MockStore) GetLastUsedResetPassword(ctx context.Context, email string) (*db.ResetPassword, error) {
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (*db.IdempotencyKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLastUsedResetPassword", ctx, email)
-	ret0, _ := ret[0].(*db.ResetPassword)
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, arg)
+	ret0, _ := ret[0].(*db.IdempotencyKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLastUsedResetPassword indicates an expected call of GetLastUsedResetPassword.
-func (mr *MockStoreMockRecorder) GetLastUsedResetPassword(ctx, email any) *gomock.Call {
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastUsedResetPassword", reflect.TypeOf((*MockStore)(nil).GetLastUsedResetPassword), ctx, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), ctx, arg)
 }
 
-// GetNotification mocks base method.
-func (m *MockStore) GetNotification(ctx context.Context, arg db.GetNotificationParams) (*db.Notification, error) {
+// GetLastEmailChange mocks base method.
+func (m *MockStore) GetLastEmailChange(ctx context.Context, userID int64) (*db.EmailChange, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNotification", ctx, arg)
+	ret := m.ctrl.Call(m, "GetLastEmailChange", ctx, userID)
+	ret0, _ := ret[0].(*db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastEmailChange indicates an expected call of GetLastEmailChange.
+func (mr *MockStoreMockRecorder) GetLastEmailChange(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastEmailChange", reflect.TypeOf((*MockStore)(nil).GetLastEmailChange), ctx, userID)
+}
+
+// GetLastPaymentTransactionForUpdate mocks base method.
+func (m *MockStore) GetLastPaymentTransactionForUpdate(ctx context.Context, shopOrderID int64) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastPaymentTransactionForUpdate", ctx, shopOrderID)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastPaymentTransactionForUpdate indicates an expected call of GetLastPaymentTransactionForUpdate.
+func (mr *MockStoreMockRecorder) GetLastPaymentTransactionForUpdate(ctx, shopOrderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastPaymentTransactionForUpdate", reflect.TypeOf((*MockStore)(nil).GetLastPaymentTransactionForUpdate), ctx, shopOrderID)
+}
+
+// GetLastUsedResetPassword mocks base method.
+func (m *MockStore) GetLastUsedResetPassword(ctx context.Context, email string) (*db.ResetPassword, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastUsedResetPassword", ctx, email)
+	ret0, _ := ret[0].(*db.ResetPassword)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastUsedResetPassword indicates an expected call of GetLastUsedResetPassword.
+func (mr *MockStoreMockRecorder) GetLastUsedResetPassword(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastUsedResetPassword", reflect.TypeOf((*MockStore)(nil).GetLastUsedResetPassword), ctx, email)
+}
+
+// GetNotification mocks base method.
+func (m *MockStore) GetNotification(ctx context.Context, arg db.GetNotificationParams) (*db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", ctx, arg)
 	ret0, _ := ret[0].(*db.Notification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
@@ -2034,6 +2972,21 @@ func (mr *MockStoreMockRecorder) GetOrderStatus(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatus", reflect.TypeOf((*MockStore)(nil).GetOrderStatus), ctx, id)
 }
 
+// GetOrderStatusByCode mocks base method.
+func (m *MockStore) GetOrderStatusByCode(ctx context.Context, code null.String) (*db.OrderStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatusByCode", ctx, code)
+	ret0, _ := ret[0].(*db.OrderStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatusByCode indicates an expected call of GetOrderStatusByCode.
+func (mr *MockStoreMockRecorder) GetOrderStatusByCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatusByCode", reflect.TypeOf((*MockStore)(nil).GetOrderStatusByCode), ctx, code)
+}
+
 // GetOrderStatusByUserID mocks base method.
 func (m *MockStore) GetOrderStatusByUserID(ctx context.Context, arg db.GetOrderStatusByUserIDParams) (*db.GetOrderStatusByUserIDRow, error) {
 	m.ctrl.T.Helper()
@@ -2064,6 +3017,51 @@ func (mr *MockStoreMockRecorder) GetPaymentMethod(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentMethod", reflect.TypeOf((*MockStore)(nil).GetPaymentMethod), ctx, arg)
 }
 
+// GetPaymentTransaction mocks base method.
+func (m *MockStore) GetPaymentTransaction(ctx context.Context, id int64) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentTransaction", ctx, id)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentTransaction indicates an expected call of GetPaymentTransaction.
+func (mr *MockStoreMockRecorder) GetPaymentTransaction(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentTransaction", reflect.TypeOf((*MockStore)(nil).GetPaymentTransaction), ctx, id)
+}
+
+// GetPaymentTransactionByProviderReference mocks base method.
+func (m *MockStore) GetPaymentTransactionByProviderReference(ctx context.Context, arg db.GetPaymentTransactionByProviderReferenceParams) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentTransactionByProviderReference", ctx, arg)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentTransactionByProviderReference indicates an expected call of GetPaymentTransactionByProviderReference.
+func (mr *MockStoreMockRecorder) GetPaymentTransactionByProviderReference(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentTransactionByProviderReference", reflect.TypeOf((*MockStore)(nil).GetPaymentTransactionByProviderReference), ctx, arg)
+}
+
+// GetPaymentTransactionForUpdate mocks base method.
+func (m *MockStore) GetPaymentTransactionForUpdate(ctx context.Context, id int64) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentTransactionForUpdate", ctx, id)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentTransactionForUpdate indicates an expected call of GetPaymentTransactionForUpdate.
+func (mr *MockStoreMockRecorder) GetPaymentTransactionForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentTransactionForUpdate", reflect.TypeOf((*MockStore)(nil).GetPaymentTransactionForUpdate), ctx, id)
+}
+
 // GetPaymentType mocks base method.
 func (m *MockStore) GetPaymentType(ctx context.Context, id int64) (*db.PaymentType, error) {
 	m.ctrl.T.Helper()
@@ -2304,6 +3302,21 @@ func (mr *MockStoreMockRecorder) GetPromotion(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotion", reflect.TypeOf((*MockStore)(nil).GetPromotion), ctx, id)
 }
 
+// GetReservedQtyBySizeIDForOtherCarts mocks base method.
+func (m *MockStore) GetReservedQtyBySizeIDForOtherCarts(ctx context.Context, arg db.GetReservedQtyBySizeIDForOtherCartsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservedQtyBySizeIDForOtherCarts", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservedQtyBySizeIDForOtherCarts indicates an expected call of GetReservedQtyBySizeIDForOtherCarts.
+func (mr *MockStoreMockRecorder) GetReservedQtyBySizeIDForOtherCarts(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservedQtyBySizeIDForOtherCarts", reflect.TypeOf((*MockStore)(nil).GetReservedQtyBySizeIDForOtherCarts), ctx, arg)
+}
+
 // GetResetPassword mocks base method.
 func (m *MockStore) GetResetPassword(ctx context.Context, id int64) (*db.ResetPassword, error) {
 	m.ctrl.T.Helper()
@@ -2319,34 +3332,79 @@ func (mr *MockStoreMockRecorder) GetResetPassword(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResetPassword", reflect.TypeOf((*MockStore)(nil).GetResetPassword), ctx, id)
 }
 
-// GetResetPasswordUserIDByID mocks base method.
-func (m *MockStore) GetResetPasswordUserIDByID(ctx context.Context, arg db.GetResetPasswordUserIDByIDParams) (int64, error) {
+// GetResetPasswordsByEmail mocks base method.
+func (m *MockStore) GetResetPasswordsByEmail(ctx context.Context, email string) (*db.GetResetPasswordsByEmailRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResetPasswordsByEmail", ctx, email)
+	ret0, _ := ret[0].(*db.GetResetPasswordsByEmailRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResetPasswordsByEmail indicates an expected call of GetResetPasswordsByEmail.
+func (mr *MockStoreMockRecorder) GetResetPasswordsByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResetPasswordsByEmail", reflect.TypeOf((*MockStore)(nil).GetResetPasswordsByEmail), ctx, email)
+}
+
+// GetReturnRequestByUserID mocks base method.
+func (m *MockStore) GetReturnRequestByUserID(ctx context.Context, arg db.GetReturnRequestByUserIDParams) (*db.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReturnRequestByUserID", ctx, arg)
+	ret0, _ := ret[0].(*db.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReturnRequestByUserID indicates an expected call of GetReturnRequestByUserID.
+func (mr *MockStoreMockRecorder) GetReturnRequestByUserID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturnRequestByUserID", reflect.TypeOf((*MockStore)(nil).GetReturnRequestByUserID), ctx, arg)
+}
+
+// GetReturnRequestForUpdate mocks base method.
+func (m *MockStore) GetReturnRequestForUpdate(ctx context.Context, id int64) (*db.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReturnRequestForUpdate", ctx, id)
+	ret0, _ := ret[0].(*db.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReturnRequestForUpdate indicates an expected call of GetReturnRequestForUpdate.
+func (mr *MockStoreMockRecorder) GetReturnRequestForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturnRequestForUpdate", reflect.TypeOf((*MockStore)(nil).GetReturnRequestForUpdate), ctx, id)
+}
+
+// GetReturnedQtyByShopOrderItemID mocks base method.
+func (m *MockStore) GetReturnedQtyByShopOrderItemID(ctx context.Context, shopOrderItemID int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetResetPasswordUserIDByID", ctx, arg)
+	ret := m.ctrl.Call(m, "GetReturnedQtyByShopOrderItemID", ctx, shopOrderItemID)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetResetPasswordUserIDByID indicates an expected call of GetResetPasswordUserIDByID.
-func (mr *MockStoreMockRecorder) GetResetPasswordUserIDByID(ctx, arg any) *gomock.Call {
+// GetReturnedQtyByShopOrderItemID indicates an expected call of GetReturnedQtyByShopOrderItemID.
+func (mr *MockStoreMockRecorder) GetReturnedQtyByShopOrderItemID(ctx, shopOrderItemID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResetPasswordUserIDByID", reflect.TypeOf((*MockStore)(nil).GetResetPasswordUserIDByID), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturnedQtyByShopOrderItemID", reflect.TypeOf((*MockStore)(nil).GetReturnedQtyByShopOrderItemID), ctx, shopOrderItemID)
 }
 
-// GetResetPasswordsByEmail mocks base method.
-func (m *MockStore) GetResetPasswordsByEmail(ctx context.Context, email string) (*db.GetResetPasswordsByEmailRow, error) {
+// GetSearchCorrection mocks base method.
+func (m *MockStore) GetSearchCorrection(ctx context.Context, query string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetResetPasswordsByEmail", ctx, email)
-	ret0, _ := ret[0].(*db.GetResetPasswordsByEmailRow)
+	ret := m.ctrl.Call(m, "GetSearchCorrection", ctx, query)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetResetPasswordsByEmail indicates an expected call of GetResetPasswordsByEmail.
-func (mr *MockStoreMockRecorder) GetResetPasswordsByEmail(ctx, email any) *gomock.Call {
+// GetSearchCorrection indicates an expected call of GetSearchCorrection.
+func (mr *MockStoreMockRecorder) GetSearchCorrection(ctx, query any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResetPasswordsByEmail", reflect.TypeOf((*MockStore)(nil).GetResetPasswordsByEmail), ctx, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchCorrection", reflect.TypeOf((*MockStore)(nil).GetSearchCorrection), ctx, query)
 }
 
 // GetShippingMethod mocks base method.
@@ -2394,6 +3452,21 @@ func (mr *MockStoreMockRecorder) GetShopOrder(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShopOrder", reflect.TypeOf((*MockStore)(nil).GetShopOrder), ctx, id)
 }
 
+// GetShopOrderForUpdate mocks base method.
+func (m *MockStore) GetShopOrderForUpdate(ctx context.Context, id int64) (*db.ShopOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShopOrderForUpdate", ctx, id)
+	ret0, _ := ret[0].(*db.ShopOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShopOrderForUpdate indicates an expected call of GetShopOrderForUpdate.
+func (mr *MockStoreMockRecorder) GetShopOrderForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShopOrderForUpdate", reflect.TypeOf((*MockStore)(nil).GetShopOrderForUpdate), ctx, id)
+}
+
 // GetShopOrderItem mocks base method.
 func (m *MockStore) GetShopOrderItem(ctx context.Context, id int64) (*db.ShopOrderItem, error) {
 	m.ctrl.T.Helper()
@@ -2424,6 +3497,21 @@ func (mr *MockStoreMockRecorder) GetShopOrderItemByUserIDOrderID(ctx, arg any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShopOrderItemByUserIDOrderID", reflect.TypeOf((*MockStore)(nil).GetShopOrderItemByUserIDOrderID), ctx, arg)
 }
 
+// GetShopOrdersCountByStatusCodes mocks base method.
+func (m *MockStore) GetShopOrdersCountByStatusCodes(ctx context.Context, arg db.GetShopOrdersCountByStatusCodesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShopOrdersCountByStatusCodes", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShopOrdersCountByStatusCodes indicates an expected call of GetShopOrdersCountByStatusCodes.
+func (mr *MockStoreMockRecorder) GetShopOrdersCountByStatusCodes(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShopOrdersCountByStatusCodes", reflect.TypeOf((*MockStore)(nil).GetShopOrdersCountByStatusCodes), ctx, arg)
+}
+
 // GetShopOrdersCountByStatusId mocks base method.
 func (m *MockStore) GetShopOrdersCountByStatusId(ctx context.Context, arg db.GetShopOrdersCountByStatusIdParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -2589,6 +3677,21 @@ func (mr *MockStoreMockRecorder) GetUserByEmail(ctx, email any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), ctx, email)
 }
 
+// GetUserDeletion mocks base method.
+func (m *MockStore) GetUserDeletion(ctx context.Context, userID int64) (*db.UserDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDeletion", ctx, userID)
+	ret0, _ := ret[0].(*db.UserDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDeletion indicates an expected call of GetUserDeletion.
+func (mr *MockStoreMockRecorder) GetUserDeletion(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDeletion", reflect.TypeOf((*MockStore)(nil).GetUserDeletion), ctx, userID)
+}
+
 // GetUserReview mocks base method.
 func (m *MockStore) GetUserReview(ctx context.Context, arg db.GetUserReviewParams) (*db.UserReview, error) {
 	m.ctrl.T.Helper()
@@ -2739,6 +3842,156 @@ func (mr *MockStoreMockRecorder) GetWishListItemByUserIDCartID(ctx, arg any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWishListItemByUserIDCartID", reflect.TypeOf((*MockStore)(nil).GetWishListItemByUserIDCartID), ctx, arg)
 }
 
+// IncrementAdminLoginChallengeAttempts mocks base method.
+func (m *MockStore) IncrementAdminLoginChallengeAttempts(ctx context.Context, arg db.IncrementAdminLoginChallengeAttemptsParams) (*db.AdminLoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementAdminLoginChallengeAttempts", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminLoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementAdminLoginChallengeAttempts indicates an expected call of IncrementAdminLoginChallengeAttempts.
+func (mr *MockStoreMockRecorder) IncrementAdminLoginChallengeAttempts(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementAdminLoginChallengeAttempts", reflect.TypeOf((*MockStore)(nil).IncrementAdminLoginChallengeAttempts), ctx, arg)
+}
+
+// IncrementCouponUsedCount mocks base method.
+func (m *MockStore) IncrementCouponUsedCount(ctx context.Context, id int64) (*db.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementCouponUsedCount", ctx, id)
+	ret0, _ := ret[0].(*db.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementCouponUsedCount indicates an expected call of IncrementCouponUsedCount.
+func (mr *MockStoreMockRecorder) IncrementCouponUsedCount(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementCouponUsedCount", reflect.TypeOf((*MockStore)(nil).IncrementCouponUsedCount), ctx, id)
+}
+
+// IncrementEmailChangeAttempts mocks base method.
+func (m *MockStore) IncrementEmailChangeAttempts(ctx context.Context, arg db.IncrementEmailChangeAttemptsParams) (*db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementEmailChangeAttempts", ctx, arg)
+	ret0, _ := ret[0].(*db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementEmailChangeAttempts indicates an expected call of IncrementEmailChangeAttempts.
+func (mr *MockStoreMockRecorder) IncrementEmailChangeAttempts(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementEmailChangeAttempts", reflect.TypeOf((*MockStore)(nil).IncrementEmailChangeAttempts), ctx, arg)
+}
+
+// IncrementResetPasswordAttempts mocks base method.
+func (m *MockStore) IncrementResetPasswordAttempts(ctx context.Context, arg db.IncrementResetPasswordAttemptsParams) (*db.ResetPassword, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementResetPasswordAttempts", ctx, arg)
+	ret0, _ := ret[0].(*db.ResetPassword)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementResetPasswordAttempts indicates an expected call of IncrementResetPasswordAttempts.
+func (mr *MockStoreMockRecorder) IncrementResetPasswordAttempts(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementResetPasswordAttempts", reflect.TypeOf((*MockStore)(nil).IncrementResetPasswordAttempts), ctx, arg)
+}
+
+// IncrementVerifyEmailAttempts mocks base method.
+func (m *MockStore) IncrementVerifyEmailAttempts(ctx context.Context, arg db.IncrementVerifyEmailAttemptsParams) (*db.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementVerifyEmailAttempts", ctx, arg)
+	ret0, _ := ret[0].(*db.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementVerifyEmailAttempts indicates an expected call of IncrementVerifyEmailAttempts.
+func (mr *MockStoreMockRecorder) IncrementVerifyEmailAttempts(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementVerifyEmailAttempts", reflect.TypeOf((*MockStore)(nil).IncrementVerifyEmailAttempts), ctx, arg)
+}
+
+// IsAdminSessionActive mocks base method.
+func (m *MockStore) IsAdminSessionActive(ctx context.Context, arg db.IsAdminSessionActiveParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAdminSessionActive", ctx, arg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAdminSessionActive indicates an expected call of IsAdminSessionActive.
+func (mr *MockStoreMockRecorder) IsAdminSessionActive(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAdminSessionActive", reflect.TypeOf((*MockStore)(nil).IsAdminSessionActive), ctx, arg)
+}
+
+// IsProductCategoryInSubtree mocks base method.
+func (m *MockStore) IsProductCategoryInSubtree(ctx context.Context, arg db.IsProductCategoryInSubtreeParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsProductCategoryInSubtree", ctx, arg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsProductCategoryInSubtree indicates an expected call of IsProductCategoryInSubtree.
+func (mr *MockStoreMockRecorder) IsProductCategoryInSubtree(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsProductCategoryInSubtree", reflect.TypeOf((*MockStore)(nil).IsProductCategoryInSubtree), ctx, arg)
+}
+
+// IsUserSessionActive mocks base method.
+func (m *MockStore) IsUserSessionActive(ctx context.Context, arg db.IsUserSessionActiveParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsUserSessionActive", ctx, arg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUserSessionActive indicates an expected call of IsUserSessionActive.
+func (mr *MockStoreMockRecorder) IsUserSessionActive(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUserSessionActive", reflect.TypeOf((*MockStore)(nil).IsUserSessionActive), ctx, arg)
+}
+
+// ListActiveAdminSessions mocks base method.
+func (m *MockStore) ListActiveAdminSessions(ctx context.Context, adminID int64) ([]*db.AdminSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveAdminSessions", ctx, adminID)
+	ret0, _ := ret[0].([]*db.AdminSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveAdminSessions indicates an expected call of ListActiveAdminSessions.
+func (mr *MockStoreMockRecorder) ListActiveAdminSessions(ctx, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveAdminSessions", reflect.TypeOf((*MockStore)(nil).ListActiveAdminSessions), ctx, adminID)
+}
+
+// ListActiveUserSessions mocks base method.
+func (m *MockStore) ListActiveUserSessions(ctx context.Context, userID int64) ([]*db.UserSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]*db.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveUserSessions indicates an expected call of ListActiveUserSessions.
+func (mr *MockStoreMockRecorder) ListActiveUserSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveUserSessions", reflect.TypeOf((*MockStore)(nil).ListActiveUserSessions), ctx, userID)
+}
+
 // ListAddressesByCity mocks base method.
 func (m *MockStore) ListAddressesByCity(ctx context.Context, arg db.ListAddressesByCityParams) ([]*db.Address, error) {
 	m.ctrl.T.Helper()
@@ -2784,6 +4037,66 @@ func (mr *MockStoreMockRecorder) ListAddressesByUserID(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddressesByUserID", reflect.TypeOf((*MockStore)(nil).ListAddressesByUserID), ctx, id)
 }
 
+// ListAdminPermissions mocks base method.
+func (m *MockStore) ListAdminPermissions(ctx context.Context) ([]*db.AdminPermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdminPermissions", ctx)
+	ret0, _ := ret[0].([]*db.AdminPermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdminPermissions indicates an expected call of ListAdminPermissions.
+func (mr *MockStoreMockRecorder) ListAdminPermissions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdminPermissions", reflect.TypeOf((*MockStore)(nil).ListAdminPermissions), ctx)
+}
+
+// ListAdminRolePermissionsByRoleIDs mocks base method.
+func (m *MockStore) ListAdminRolePermissionsByRoleIDs(ctx context.Context, roleIds []int64) ([]*db.AdminRolePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdminRolePermissionsByRoleIDs", ctx, roleIds)
+	ret0, _ := ret[0].([]*db.AdminRolePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdminRolePermissionsByRoleIDs indicates an expected call of ListAdminRolePermissionsByRoleIDs.
+func (mr *MockStoreMockRecorder) ListAdminRolePermissionsByRoleIDs(ctx, roleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdminRolePermissionsByRoleIDs", reflect.TypeOf((*MockStore)(nil).ListAdminRolePermissionsByRoleIDs), ctx, roleIds)
+}
+
+// ListAdminRoles mocks base method.
+func (m *MockStore) ListAdminRoles(ctx context.Context, arg db.ListAdminRolesParams) ([]*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdminRoles", ctx, arg)
+	ret0, _ := ret[0].([]*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdminRoles indicates an expected call of ListAdminRoles.
+func (mr *MockStoreMockRecorder) ListAdminRoles(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdminRoles", reflect.TypeOf((*MockStore)(nil).ListAdminRoles), ctx, arg)
+}
+
+// ListAdminRolesByAdminID mocks base method.
+func (m *MockStore) ListAdminRolesByAdminID(ctx context.Context, adminID int64) ([]*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdminRolesByAdminID", ctx, adminID)
+	ret0, _ := ret[0].([]*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdminRolesByAdminID indicates an expected call of ListAdminRolesByAdminID.
+func (mr *MockStoreMockRecorder) ListAdminRolesByAdminID(ctx, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdminRolesByAdminID", reflect.TypeOf((*MockStore)(nil).ListAdminRolesByAdminID), ctx, adminID)
+}
+
 // ListAdminTypes mocks base method.
 func (m *MockStore) ListAdminTypes(ctx context.Context, arg db.ListAdminTypesParams) ([]*db.AdminType, error) {
 	m.ctrl.T.Helper()
@@ -2889,7 +4202,22 @@ func (mr *MockStoreMockRecorder) ListCategoryPromotionsWithImages(ctx any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategoryPromotionsWithImages", reflect.TypeOf((*MockStore)(nil).ListCategoryPromotionsWithImages), ctx)
 }
 
-// ListFeaturedProductItems mocks base method.
+// ListDueUserDeletions mocks base method.
+func (m *MockStore) ListDueUserDeletions(ctx context.Context, limit int32) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueUserDeletions", ctx, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueUserDeletions indicates an expected call of ListDueUserDeletions.
+func (mr *MockStoreMockRecorder) ListDueUserDeletions(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueUserDeletions", reflect.TypeOf((*MockStore)(nil).ListDueUserDeletions), ctx, limit)
+}
+
+// ListFeaturedProductItems mocks base method.
 func (m *MockStore) ListFeaturedProductItems(ctx context.Context, arg db.ListFeaturedProductItemsParams) ([]*db.FeaturedProductItem, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListFeaturedProductItems", ctx, arg)
@@ -2964,6 +4292,36 @@ func (mr *MockStoreMockRecorder) ListPaymentMethods(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentMethods", reflect.TypeOf((*MockStore)(nil).ListPaymentMethods), ctx, arg)
 }
 
+// ListPaymentTransactionsByShopOrderID mocks base method.
+func (m *MockStore) ListPaymentTransactionsByShopOrderID(ctx context.Context, shopOrderID int64) ([]*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentTransactionsByShopOrderID", ctx, shopOrderID)
+	ret0, _ := ret[0].([]*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentTransactionsByShopOrderID indicates an expected call of ListPaymentTransactionsByShopOrderID.
+func (mr *MockStoreMockRecorder) ListPaymentTransactionsByShopOrderID(ctx, shopOrderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentTransactionsByShopOrderID", reflect.TypeOf((*MockStore)(nil).ListPaymentTransactionsByShopOrderID), ctx, shopOrderID)
+}
+
+// ListPaymentTransactionsWithPendingRefund mocks base method.
+func (m *MockStore) ListPaymentTransactionsWithPendingRefund(ctx context.Context, limit int32) ([]*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentTransactionsWithPendingRefund", ctx, limit)
+	ret0, _ := ret[0].([]*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentTransactionsWithPendingRefund indicates an expected call of ListPaymentTransactionsWithPendingRefund.
+func (mr *MockStoreMockRecorder) ListPaymentTransactionsWithPendingRefund(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentTransactionsWithPendingRefund", reflect.TypeOf((*MockStore)(nil).ListPaymentTransactionsWithPendingRefund), ctx, limit)
+}
+
 // ListPaymentTypes mocks base method.
 func (m *MockStore) ListPaymentTypes(ctx context.Context) ([]*db.PaymentType, error) {
 	m.ctrl.T.Helper()
@@ -3024,6 +4382,36 @@ func (mr *MockStoreMockRecorder) ListProductCategoriesByParent(ctx, parentCatego
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductCategoriesByParent", reflect.TypeOf((*MockStore)(nil).ListProductCategoriesByParent), ctx, parentCategoryID)
 }
 
+// ListProductCategoryBreadcrumbs mocks base method.
+func (m *MockStore) ListProductCategoryBreadcrumbs(ctx context.Context, id int64) ([]*db.ListProductCategoryBreadcrumbsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductCategoryBreadcrumbs", ctx, id)
+	ret0, _ := ret[0].([]*db.ListProductCategoryBreadcrumbsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductCategoryBreadcrumbs indicates an expected call of ListProductCategoryBreadcrumbs.
+func (mr *MockStoreMockRecorder) ListProductCategoryBreadcrumbs(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductCategoryBreadcrumbs", reflect.TypeOf((*MockStore)(nil).ListProductCategoryBreadcrumbs), ctx, id)
+}
+
+// ListProductCategoryTree mocks base method.
+func (m *MockStore) ListProductCategoryTree(ctx context.Context) ([]*db.ListProductCategoryTreeRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductCategoryTree", ctx)
+	ret0, _ := ret[0].([]*db.ListProductCategoryTreeRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductCategoryTree indicates an expected call of ListProductCategoryTree.
+func (mr *MockStoreMockRecorder) ListProductCategoryTree(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductCategoryTree", reflect.TypeOf((*MockStore)(nil).ListProductCategoryTree), ctx)
+}
+
 // ListProductColors mocks base method.
 func (m *MockStore) ListProductColors(ctx context.Context) ([]*db.ProductColor, error) {
 	m.ctrl.T.Helper()
@@ -3084,6 +4472,21 @@ func (mr *MockStoreMockRecorder) ListProductImagesV2(ctx, limit any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductImagesV2", reflect.TypeOf((*MockStore)(nil).ListProductImagesV2), ctx, limit)
 }
 
+// ListProductItemFacets mocks base method.
+func (m *MockStore) ListProductItemFacets(ctx context.Context, arg db.ListProductItemFacetsParams) ([]*db.ListProductItemFacetsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductItemFacets", ctx, arg)
+	ret0, _ := ret[0].([]*db.ListProductItemFacetsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductItemFacets indicates an expected call of ListProductItemFacets.
+func (mr *MockStoreMockRecorder) ListProductItemFacets(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductItemFacets", reflect.TypeOf((*MockStore)(nil).ListProductItemFacets), ctx, arg)
+}
+
 // ListProductItems mocks base method.
 func (m *MockStore) ListProductItems(ctx context.Context, arg db.ListProductItemsParams) ([]*db.ListProductItemsRow, error) {
 	m.ctrl.T.Helper()
@@ -3309,6 +4712,21 @@ func (mr *MockStoreMockRecorder) ListProductPromotionsWithImages(ctx any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductPromotionsWithImages", reflect.TypeOf((*MockStore)(nil).ListProductPromotionsWithImages), ctx)
 }
 
+// ListProductReviews mocks base method.
+func (m *MockStore) ListProductReviews(ctx context.Context, arg db.ListProductReviewsParams) ([]*db.ListProductReviewsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductReviews", ctx, arg)
+	ret0, _ := ret[0].([]*db.ListProductReviewsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductReviews indicates an expected call of ListProductReviews.
+func (mr *MockStoreMockRecorder) ListProductReviews(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductReviews", reflect.TypeOf((*MockStore)(nil).ListProductReviews), ctx, arg)
+}
+
 // ListProductSizes mocks base method.
 func (m *MockStore) ListProductSizes(ctx context.Context) ([]*db.ProductSize, error) {
 	m.ctrl.T.Helper()
@@ -3414,6 +4832,66 @@ func (mr *MockStoreMockRecorder) ListPromotions(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotions", reflect.TypeOf((*MockStore)(nil).ListPromotions), ctx)
 }
 
+// ListReturnRequestItemsByRequestID mocks base method.
+func (m *MockStore) ListReturnRequestItemsByRequestID(ctx context.Context, returnRequestID int64) ([]*db.ListReturnRequestItemsByRequestIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReturnRequestItemsByRequestID", ctx, returnRequestID)
+	ret0, _ := ret[0].([]*db.ListReturnRequestItemsByRequestIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReturnRequestItemsByRequestID indicates an expected call of ListReturnRequestItemsByRequestID.
+func (mr *MockStoreMockRecorder) ListReturnRequestItemsByRequestID(ctx, returnRequestID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReturnRequestItemsByRequestID", reflect.TypeOf((*MockStore)(nil).ListReturnRequestItemsByRequestID), ctx, returnRequestID)
+}
+
+// ListReturnRequestsByUserID mocks base method.
+func (m *MockStore) ListReturnRequestsByUserID(ctx context.Context, arg db.ListReturnRequestsByUserIDParams) ([]*db.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReturnRequestsByUserID", ctx, arg)
+	ret0, _ := ret[0].([]*db.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReturnRequestsByUserID indicates an expected call of ListReturnRequestsByUserID.
+func (mr *MockStoreMockRecorder) ListReturnRequestsByUserID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReturnRequestsByUserID", reflect.TypeOf((*MockStore)(nil).ListReturnRequestsByUserID), ctx, arg)
+}
+
+// ListSearchQueryStats mocks base method.
+func (m *MockStore) ListSearchQueryStats(ctx context.Context, arg db.ListSearchQueryStatsParams) ([]*db.ListSearchQueryStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSearchQueryStats", ctx, arg)
+	ret0, _ := ret[0].([]*db.ListSearchQueryStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSearchQueryStats indicates an expected call of ListSearchQueryStats.
+func (mr *MockStoreMockRecorder) ListSearchQueryStats(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSearchQueryStats", reflect.TypeOf((*MockStore)(nil).ListSearchQueryStats), ctx, arg)
+}
+
+// ListSearchSuggestions mocks base method.
+func (m *MockStore) ListSearchSuggestions(ctx context.Context, arg db.ListSearchSuggestionsParams) ([]*db.ListSearchSuggestionsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSearchSuggestions", ctx, arg)
+	ret0, _ := ret[0].([]*db.ListSearchSuggestionsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSearchSuggestions indicates an expected call of ListSearchSuggestions.
+func (mr *MockStoreMockRecorder) ListSearchSuggestions(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSearchSuggestions", reflect.TypeOf((*MockStore)(nil).ListSearchSuggestions), ctx, arg)
+}
+
 // ListShippingMethods mocks base method.
 func (m *MockStore) ListShippingMethods(ctx context.Context) ([]*db.ShippingMethod, error) {
 	m.ctrl.T.Helper()
@@ -3459,6 +4937,21 @@ func (mr *MockStoreMockRecorder) ListShopOrderItems(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrderItems", reflect.TypeOf((*MockStore)(nil).ListShopOrderItems), ctx, arg)
 }
 
+// ListShopOrderItemsByOrderID mocks base method.
+func (m *MockStore) ListShopOrderItemsByOrderID(ctx context.Context, orderID int64) ([]*db.ShopOrderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShopOrderItemsByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]*db.ShopOrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShopOrderItemsByOrderID indicates an expected call of ListShopOrderItemsByOrderID.
+func (mr *MockStoreMockRecorder) ListShopOrderItemsByOrderID(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrderItemsByOrderID", reflect.TypeOf((*MockStore)(nil).ListShopOrderItemsByOrderID), ctx, orderID)
+}
+
 // ListShopOrderItemsByUserID mocks base method.
 func (m *MockStore) ListShopOrderItemsByUserID(ctx context.Context, arg db.ListShopOrderItemsByUserIDParams) ([]*db.ListShopOrderItemsByUserIDRow, error) {
 	m.ctrl.T.Helper()
@@ -3489,6 +4982,51 @@ func (mr *MockStoreMockRecorder) ListShopOrderItemsByUserIDOrderID(ctx, arg any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrderItemsByUserIDOrderID", reflect.TypeOf((*MockStore)(nil).ListShopOrderItemsByUserIDOrderID), ctx, arg)
 }
 
+// ListShopOrderItemsForExport mocks base method.
+func (m *MockStore) ListShopOrderItemsForExport(ctx context.Context, userID int64) ([]*db.ShopOrderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShopOrderItemsForExport", ctx, userID)
+	ret0, _ := ret[0].([]*db.ShopOrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShopOrderItemsForExport indicates an expected call of ListShopOrderItemsForExport.
+func (mr *MockStoreMockRecorder) ListShopOrderItemsForExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrderItemsForExport", reflect.TypeOf((*MockStore)(nil).ListShopOrderItemsForExport), ctx, userID)
+}
+
+// ListShopOrderItemsToRestock mocks base method.
+func (m *MockStore) ListShopOrderItemsToRestock(ctx context.Context, orderID int64) ([]*db.ListShopOrderItemsToRestockRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShopOrderItemsToRestock", ctx, orderID)
+	ret0, _ := ret[0].([]*db.ListShopOrderItemsToRestockRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShopOrderItemsToRestock indicates an expected call of ListShopOrderItemsToRestock.
+func (mr *MockStoreMockRecorder) ListShopOrderItemsToRestock(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrderItemsToRestock", reflect.TypeOf((*MockStore)(nil).ListShopOrderItemsToRestock), ctx, orderID)
+}
+
+// ListShopOrderStatusHistoryByUserIDOrderID mocks base method.
+func (m *MockStore) ListShopOrderStatusHistoryByUserIDOrderID(ctx context.Context, arg db.ListShopOrderStatusHistoryByUserIDOrderIDParams) ([]*db.ListShopOrderStatusHistoryByUserIDOrderIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShopOrderStatusHistoryByUserIDOrderID", ctx, arg)
+	ret0, _ := ret[0].([]*db.ListShopOrderStatusHistoryByUserIDOrderIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShopOrderStatusHistoryByUserIDOrderID indicates an expected call of ListShopOrderStatusHistoryByUserIDOrderID.
+func (mr *MockStoreMockRecorder) ListShopOrderStatusHistoryByUserIDOrderID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrderStatusHistoryByUserIDOrderID", reflect.TypeOf((*MockStore)(nil).ListShopOrderStatusHistoryByUserIDOrderID), ctx, arg)
+}
+
 // ListShopOrders mocks base method.
 func (m *MockStore) ListShopOrders(ctx context.Context, arg db.ListShopOrdersParams) ([]*db.ShopOrder, error) {
 	m.ctrl.T.Helper()
@@ -3549,6 +5087,21 @@ func (mr *MockStoreMockRecorder) ListShopOrdersByUserIDV2(ctx, arg any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrdersByUserIDV2", reflect.TypeOf((*MockStore)(nil).ListShopOrdersByUserIDV2), ctx, arg)
 }
 
+// ListShopOrdersForExport mocks base method.
+func (m *MockStore) ListShopOrdersForExport(ctx context.Context, userID int64) ([]*db.ShopOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShopOrdersForExport", ctx, userID)
+	ret0, _ := ret[0].([]*db.ShopOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShopOrdersForExport indicates an expected call of ListShopOrdersForExport.
+func (mr *MockStoreMockRecorder) ListShopOrdersForExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShopOrdersForExport", reflect.TypeOf((*MockStore)(nil).ListShopOrdersForExport), ctx, userID)
+}
+
 // ListShoppingCartItems mocks base method.
 func (m *MockStore) ListShoppingCartItems(ctx context.Context, arg db.ListShoppingCartItemsParams) ([]*db.ShoppingCartItem, error) {
 	m.ctrl.T.Helper()
@@ -3609,6 +5162,21 @@ func (mr *MockStoreMockRecorder) ListShoppingCarts(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShoppingCarts", reflect.TypeOf((*MockStore)(nil).ListShoppingCarts), ctx, arg)
 }
 
+// ListStockReservationsByCartID mocks base method.
+func (m *MockStore) ListStockReservationsByCartID(ctx context.Context, shoppingCartID int64) ([]*db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStockReservationsByCartID", ctx, shoppingCartID)
+	ret0, _ := ret[0].([]*db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStockReservationsByCartID indicates an expected call of ListStockReservationsByCartID.
+func (mr *MockStoreMockRecorder) ListStockReservationsByCartID(ctx, shoppingCartID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStockReservationsByCartID", reflect.TypeOf((*MockStore)(nil).ListStockReservationsByCartID), ctx, shoppingCartID)
+}
+
 // ListUserReviews mocks base method.
 func (m *MockStore) ListUserReviews(ctx context.Context, arg db.ListUserReviewsParams) ([]*db.UserReview, error) {
 	m.ctrl.T.Helper()
@@ -3624,6 +5192,51 @@ func (mr *MockStoreMockRecorder) ListUserReviews(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserReviews", reflect.TypeOf((*MockStore)(nil).ListUserReviews), ctx, arg)
 }
 
+// ListUserReviewsForExport mocks base method.
+func (m *MockStore) ListUserReviewsForExport(ctx context.Context, userID int64) ([]*db.UserReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserReviewsForExport", ctx, userID)
+	ret0, _ := ret[0].([]*db.UserReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserReviewsForExport indicates an expected call of ListUserReviewsForExport.
+func (mr *MockStoreMockRecorder) ListUserReviewsForExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserReviewsForExport", reflect.TypeOf((*MockStore)(nil).ListUserReviewsForExport), ctx, userID)
+}
+
+// ListUserReviewsForModeration mocks base method.
+func (m *MockStore) ListUserReviewsForModeration(ctx context.Context, arg db.ListUserReviewsForModerationParams) ([]*db.ListUserReviewsForModerationRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserReviewsForModeration", ctx, arg)
+	ret0, _ := ret[0].([]*db.ListUserReviewsForModerationRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserReviewsForModeration indicates an expected call of ListUserReviewsForModeration.
+func (mr *MockStoreMockRecorder) ListUserReviewsForModeration(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserReviewsForModeration", reflect.TypeOf((*MockStore)(nil).ListUserReviewsForModeration), ctx, arg)
+}
+
+// ListUserSessionsForExport mocks base method.
+func (m *MockStore) ListUserSessionsForExport(ctx context.Context, userID int64) ([]*db.UserSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessionsForExport", ctx, userID)
+	ret0, _ := ret[0].([]*db.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessionsForExport indicates an expected call of ListUserSessionsForExport.
+func (mr *MockStoreMockRecorder) ListUserSessionsForExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessionsForExport", reflect.TypeOf((*MockStore)(nil).ListUserSessionsForExport), ctx, userID)
+}
+
 // ListUsers mocks base method.
 func (m *MockStore) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]*db.User, error) {
 	m.ctrl.T.Helper()
@@ -3729,169 +5342,557 @@ func (mr *MockStoreMockRecorder) ListWishLists(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWishLists", reflect.TypeOf((*MockStore)(nil).ListWishLists), ctx, arg)
 }
 
-// SearchProductItems mocks base method.
-func (m *MockStore) SearchProductItems(ctx context.Context, arg db.SearchProductItemsParams) ([]*db.SearchProductItemsRow, error) {
+// LockProductCategoryTree mocks base method.
+func (m *MockStore) LockProductCategoryTree(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProductItems", ctx, arg)
-	ret0, _ := ret[0].([]*db.SearchProductItemsRow)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "LockProductCategoryTree", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// SearchProductItems indicates an expected call of SearchProductItems.
-func (mr *MockStoreMockRecorder) SearchProductItems(ctx, arg any) *gomock.Call {
+// LockProductCategoryTree indicates an expected call of LockProductCategoryTree.
+func (mr *MockStoreMockRecorder) LockProductCategoryTree(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItems", reflect.TypeOf((*MockStore)(nil).SearchProductItems), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockProductCategoryTree", reflect.TypeOf((*MockStore)(nil).LockProductCategoryTree), ctx)
 }
 
-// SearchProductItemsNextPage mocks base method.
-func (m *MockStore) SearchProductItemsNextPage(ctx context.Context, arg db.SearchProductItemsNextPageParams) ([]*db.SearchProductItemsNextPageRow, error) {
+// MarkUserDeletionAnonymized mocks base method.
+func (m *MockStore) MarkUserDeletionAnonymized(ctx context.Context, userID int64) (*db.UserDeletion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProductItemsNextPage", ctx, arg)
-	ret0, _ := ret[0].([]*db.SearchProductItemsNextPageRow)
+	ret := m.ctrl.Call(m, "MarkUserDeletionAnonymized", ctx, userID)
+	ret0, _ := ret[0].(*db.UserDeletion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchProductItemsNextPage indicates an expected call of SearchProductItemsNextPage.
-func (mr *MockStoreMockRecorder) SearchProductItemsNextPage(ctx, arg any) *gomock.Call {
+// MarkUserDeletionAnonymized indicates an expected call of MarkUserDeletionAnonymized.
+func (mr *MockStoreMockRecorder) MarkUserDeletionAnonymized(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsNextPage", reflect.TypeOf((*MockStore)(nil).SearchProductItemsNextPage), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUserDeletionAnonymized", reflect.TypeOf((*MockStore)(nil).MarkUserDeletionAnonymized), ctx, userID)
 }
 
-// SearchProductItemsNextPageOld mocks base method.
-func (m *MockStore) SearchProductItemsNextPageOld(ctx context.Context, arg db.SearchProductItemsNextPageOldParams) ([]*db.SearchProductItemsNextPageOldRow, error) {
+// ModerateUserReview mocks base method.
+func (m *MockStore) ModerateUserReview(ctx context.Context, arg db.ModerateUserReviewParams) (*db.UserReview, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProductItemsNextPageOld", ctx, arg)
-	ret0, _ := ret[0].([]*db.SearchProductItemsNextPageOldRow)
+	ret := m.ctrl.Call(m, "ModerateUserReview", ctx, arg)
+	ret0, _ := ret[0].(*db.UserReview)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchProductItemsNextPageOld indicates an expected call of SearchProductItemsNextPageOld.
-func (mr *MockStoreMockRecorder) SearchProductItemsNextPageOld(ctx, arg any) *gomock.Call {
+// ModerateUserReview indicates an expected call of ModerateUserReview.
+func (mr *MockStoreMockRecorder) ModerateUserReview(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsNextPageOld", reflect.TypeOf((*MockStore)(nil).SearchProductItemsNextPageOld), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModerateUserReview", reflect.TypeOf((*MockStore)(nil).ModerateUserReview), ctx, arg)
 }
 
-// SearchProductItemsOld mocks base method.
-func (m *MockStore) SearchProductItemsOld(ctx context.Context, arg db.SearchProductItemsOldParams) ([]*db.SearchProductItemsOldRow, error) {
+// MoveProductCategoryTx mocks base method.
+func (m *MockStore) MoveProductCategoryTx(ctx context.Context, arg db.MoveProductCategoryTxParams) (*db.ProductCategory, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProductItemsOld", ctx, arg)
-	ret0, _ := ret[0].([]*db.SearchProductItemsOldRow)
+	ret := m.ctrl.Call(m, "MoveProductCategoryTx", ctx, arg)
+	ret0, _ := ret[0].(*db.ProductCategory)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchProductItemsOld indicates an expected call of SearchProductItemsOld.
-func (mr *MockStoreMockRecorder) SearchProductItemsOld(ctx, arg any) *gomock.Call {
+// MoveProductCategoryTx indicates an expected call of MoveProductCategoryTx.
+func (mr *MockStoreMockRecorder) MoveProductCategoryTx(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsOld", reflect.TypeOf((*MockStore)(nil).SearchProductItemsOld), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveProductCategoryTx", reflect.TypeOf((*MockStore)(nil).MoveProductCategoryTx), ctx, arg)
 }
 
-// SearchProducts mocks base method.
-func (m *MockStore) SearchProducts(ctx context.Context, arg db.SearchProductsParams) ([]*db.SearchProductsRow, error) {
+// QuoteCartTx mocks base method.
+func (m *MockStore) QuoteCartTx(ctx context.Context, arg db.QuoteCartTxParams) (*db.QuoteCartTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProducts", ctx, arg)
-	ret0, _ := ret[0].([]*db.SearchProductsRow)
+	ret := m.ctrl.Call(m, "QuoteCartTx", ctx, arg)
+	ret0, _ := ret[0].(*db.QuoteCartTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchProducts indicates an expected call of SearchProducts.
-func (mr *MockStoreMockRecorder) SearchProducts(ctx, arg any) *gomock.Call {
+// QuoteCartTx indicates an expected call of QuoteCartTx.
+func (mr *MockStoreMockRecorder) QuoteCartTx(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockStore)(nil).SearchProducts), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteCartTx", reflect.TypeOf((*MockStore)(nil).QuoteCartTx), ctx, arg)
 }
 
-// SearchProductsNextPage mocks base method.
-func (m *MockStore) SearchProductsNextPage(ctx context.Context, arg db.SearchProductsNextPageParams) ([]*db.SearchProductsNextPageRow, error) {
+// RedactShopOrderAddresses mocks base method.
+func (m *MockStore) RedactShopOrderAddresses(ctx context.Context, userID int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProductsNextPage", ctx, arg)
-	ret0, _ := ret[0].([]*db.SearchProductsNextPageRow)
+	ret := m.ctrl.Call(m, "RedactShopOrderAddresses", ctx, userID)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchProductsNextPage indicates an expected call of SearchProductsNextPage.
-func (mr *MockStoreMockRecorder) SearchProductsNextPage(ctx, arg any) *gomock.Call {
+// RedactShopOrderAddresses indicates an expected call of RedactShopOrderAddresses.
+func (mr *MockStoreMockRecorder) RedactShopOrderAddresses(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductsNextPage", reflect.TypeOf((*MockStore)(nil).SearchProductsNextPage), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedactShopOrderAddresses", reflect.TypeOf((*MockStore)(nil).RedactShopOrderAddresses), ctx, userID)
 }
 
-// SignUpTx mocks base method.
-func (m *MockStore) SignUpTx(ctx context.Context, arg db.SignUpTxParams) (*db.SignUpTxResult, error) {
+// ReplaceAdminRecoveryCodes mocks base method.
+func (m *MockStore) ReplaceAdminRecoveryCodes(ctx context.Context, arg db.ReplaceAdminRecoveryCodesParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUpTx", ctx, arg)
-	ret0, _ := ret[0].(*db.SignUpTxResult)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ReplaceAdminRecoveryCodes", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// SignUpTx indicates an expected call of SignUpTx.
-func (mr *MockStoreMockRecorder) SignUpTx(ctx, arg any) *gomock.Call {
+// ReplaceAdminRecoveryCodes indicates an expected call of ReplaceAdminRecoveryCodes.
+func (mr *MockStoreMockRecorder) ReplaceAdminRecoveryCodes(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpTx", reflect.TypeOf((*MockStore)(nil).SignUpTx), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceAdminRecoveryCodes", reflect.TypeOf((*MockStore)(nil).ReplaceAdminRecoveryCodes), ctx, arg)
 }
 
-// UpdateAddress mocks base method.
-func (m *MockStore) UpdateAddress(ctx context.Context, arg db.UpdateAddressParams) (*db.Address, error) {
+// ReserveCartTx mocks base method.
+func (m *MockStore) ReserveCartTx(ctx context.Context, arg db.ReserveCartTxParams) (*db.ReserveCartTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAddress", ctx, arg)
-	ret0, _ := ret[0].(*db.Address)
+	ret := m.ctrl.Call(m, "ReserveCartTx", ctx, arg)
+	ret0, _ := ret[0].(*db.ReserveCartTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAddress indicates an expected call of UpdateAddress.
-func (mr *MockStoreMockRecorder) UpdateAddress(ctx, arg any) *gomock.Call {
+// ReserveCartTx indicates an expected call of ReserveCartTx.
+func (mr *MockStoreMockRecorder) ReserveCartTx(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddress", reflect.TypeOf((*MockStore)(nil).UpdateAddress), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveCartTx", reflect.TypeOf((*MockStore)(nil).ReserveCartTx), ctx, arg)
 }
 
-// UpdateAdmin mocks base method.
-func (m *MockStore) UpdateAdmin(ctx context.Context, arg db.UpdateAdminParams) (*db.Admin, error) {
+// RestockProductSize mocks base method.
+func (m *MockStore) RestockProductSize(ctx context.Context, arg db.RestockProductSizeParams) (*db.ProductSize, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAdmin", ctx, arg)
-	ret0, _ := ret[0].(*db.Admin)
+	ret := m.ctrl.Call(m, "RestockProductSize", ctx, arg)
+	ret0, _ := ret[0].(*db.ProductSize)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAdmin indicates an expected call of UpdateAdmin.
-func (mr *MockStoreMockRecorder) UpdateAdmin(ctx, arg any) *gomock.Call {
+// RestockProductSize indicates an expected call of RestockProductSize.
+func (mr *MockStoreMockRecorder) RestockProductSize(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdmin", reflect.TypeOf((*MockStore)(nil).UpdateAdmin), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestockProductSize", reflect.TypeOf((*MockStore)(nil).RestockProductSize), ctx, arg)
 }
 
-// UpdateAdminSession mocks base method.
-func (m *MockStore) UpdateAdminSession(ctx context.Context, arg db.UpdateAdminSessionParams) (*db.AdminSession, error) {
+// RevokeAdminSessionFamily mocks base method.
+func (m *MockStore) RevokeAdminSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAdminSession", ctx, arg)
-	ret0, _ := ret[0].(*db.AdminSession)
+	ret := m.ctrl.Call(m, "RevokeAdminSessionFamily", ctx, familyID)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAdminSession indicates an expected call of UpdateAdminSession.
-func (mr *MockStoreMockRecorder) UpdateAdminSession(ctx, arg any) *gomock.Call {
+// RevokeAdminSessionFamily indicates an expected call of RevokeAdminSessionFamily.
+func (mr *MockStoreMockRecorder) RevokeAdminSessionFamily(ctx, familyID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminSession", reflect.TypeOf((*MockStore)(nil).UpdateAdminSession), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAdminSessionFamily", reflect.TypeOf((*MockStore)(nil).RevokeAdminSessionFamily), ctx, familyID)
 }
 
-// UpdateAdminType mocks base method.
-func (m *MockStore) UpdateAdminType(ctx context.Context, arg db.UpdateAdminTypeParams) (*db.AdminType, error) {
+// RevokeAdminSessions mocks base method.
+func (m *MockStore) RevokeAdminSessions(ctx context.Context, adminID int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAdminType", ctx, arg)
-	ret0, _ := ret[0].(*db.AdminType)
+	ret := m.ctrl.Call(m, "RevokeAdminSessions", ctx, adminID)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAdminType indicates an expected call of UpdateAdminType.
-func (mr *MockStoreMockRecorder) UpdateAdminType(ctx, arg any) *gomock.Call {
+// RevokeAdminSessions indicates an expected call of RevokeAdminSessions.
+func (mr *MockStoreMockRecorder) RevokeAdminSessions(ctx, adminID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminType", reflect.TypeOf((*MockStore)(nil).UpdateAdminType), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAdminSessions", reflect.TypeOf((*MockStore)(nil).RevokeAdminSessions), ctx, adminID)
+}
+
+// RevokeOtherUserSessions mocks base method.
+func (m *MockStore) RevokeOtherUserSessions(ctx context.Context, arg db.RevokeOtherUserSessionsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherUserSessions", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOtherUserSessions indicates an expected call of RevokeOtherUserSessions.
+func (mr *MockStoreMockRecorder) RevokeOtherUserSessions(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherUserSessions", reflect.TypeOf((*MockStore)(nil).RevokeOtherUserSessions), ctx, arg)
+}
+
+// RevokeUserSessionFamily mocks base method.
+func (m *MockStore) RevokeUserSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessionFamily", ctx, familyID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessionFamily indicates an expected call of RevokeUserSessionFamily.
+func (mr *MockStoreMockRecorder) RevokeUserSessionFamily(ctx, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessionFamily", reflect.TypeOf((*MockStore)(nil).RevokeUserSessionFamily), ctx, familyID)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockStore) RevokeUserSessions(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockStoreMockRecorder) RevokeUserSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockStore)(nil).RevokeUserSessions), ctx, userID)
+}
+
+// RotateAdminSession mocks base method.
+func (m *MockStore) RotateAdminSession(ctx context.Context, arg db.RotateAdminSessionParams) (*db.AdminSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAdminSession", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAdminSession indicates an expected call of RotateAdminSession.
+func (mr *MockStoreMockRecorder) RotateAdminSession(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAdminSession", reflect.TypeOf((*MockStore)(nil).RotateAdminSession), ctx, arg)
+}
+
+// RotateAdminSessionTx mocks base method.
+func (m *MockStore) RotateAdminSessionTx(ctx context.Context, arg db.RotateAdminSessionTxParams) (*db.AdminSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAdminSessionTx", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAdminSessionTx indicates an expected call of RotateAdminSessionTx.
+func (mr *MockStoreMockRecorder) RotateAdminSessionTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAdminSessionTx", reflect.TypeOf((*MockStore)(nil).RotateAdminSessionTx), ctx, arg)
+}
+
+// RotateUserSession mocks base method.
+func (m *MockStore) RotateUserSession(ctx context.Context, arg db.RotateUserSessionParams) (*db.UserSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateUserSession", ctx, arg)
+	ret0, _ := ret[0].(*db.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateUserSession indicates an expected call of RotateUserSession.
+func (mr *MockStoreMockRecorder) RotateUserSession(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateUserSession", reflect.TypeOf((*MockStore)(nil).RotateUserSession), ctx, arg)
+}
+
+// RotateUserSessionTx mocks base method.
+func (m *MockStore) RotateUserSessionTx(ctx context.Context, arg db.RotateUserSessionTxParams) (*db.UserSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateUserSessionTx", ctx, arg)
+	ret0, _ := ret[0].(*db.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateUserSessionTx indicates an expected call of RotateUserSessionTx.
+func (mr *MockStoreMockRecorder) RotateUserSessionTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateUserSessionTx", reflect.TypeOf((*MockStore)(nil).RotateUserSessionTx), ctx, arg)
+}
+
+// SearchProductItems mocks base method.
+func (m *MockStore) SearchProductItems(ctx context.Context, arg db.SearchProductItemsParams) ([]*db.SearchProductItemsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProductItems", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductItemsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProductItems indicates an expected call of SearchProductItems.
+func (mr *MockStoreMockRecorder) SearchProductItems(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItems", reflect.TypeOf((*MockStore)(nil).SearchProductItems), ctx, arg)
+}
+
+// SearchProductItemsFaceted mocks base method.
+func (m *MockStore) SearchProductItemsFaceted(ctx context.Context, arg db.SearchProductItemsFacetedParams) ([]*db.SearchProductItemsFacetedRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProductItemsFaceted", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductItemsFacetedRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProductItemsFaceted indicates an expected call of SearchProductItemsFaceted.
+func (mr *MockStoreMockRecorder) SearchProductItemsFaceted(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsFaceted", reflect.TypeOf((*MockStore)(nil).SearchProductItemsFaceted), ctx, arg)
+}
+
+// SearchProductItemsNextPage mocks base method.
+func (m *MockStore) SearchProductItemsNextPage(ctx context.Context, arg db.SearchProductItemsNextPageParams) ([]*db.SearchProductItemsNextPageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProductItemsNextPage", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductItemsNextPageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProductItemsNextPage indicates an expected call of SearchProductItemsNextPage.
+func (mr *MockStoreMockRecorder) SearchProductItemsNextPage(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsNextPage", reflect.TypeOf((*MockStore)(nil).SearchProductItemsNextPage), ctx, arg)
+}
+
+// SearchProductItemsNextPageOld mocks base method.
+func (m *MockStore) SearchProductItemsNextPageOld(ctx context.Context, arg db.SearchProductItemsNextPageOldParams) ([]*db.SearchProductItemsNextPageOldRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProductItemsNextPageOld", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductItemsNextPageOldRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProductItemsNextPageOld indicates an expected call of SearchProductItemsNextPageOld.
+func (mr *MockStoreMockRecorder) SearchProductItemsNextPageOld(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsNextPageOld", reflect.TypeOf((*MockStore)(nil).SearchProductItemsNextPageOld), ctx, arg)
+}
+
+// SearchProductItemsOld mocks base method.
+func (m *MockStore) SearchProductItemsOld(ctx context.Context, arg db.SearchProductItemsOldParams) ([]*db.SearchProductItemsOldRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProductItemsOld", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductItemsOldRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProductItemsOld indicates an expected call of SearchProductItemsOld.
+func (mr *MockStoreMockRecorder) SearchProductItemsOld(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductItemsOld", reflect.TypeOf((*MockStore)(nil).SearchProductItemsOld), ctx, arg)
+}
+
+// SearchProducts mocks base method.
+func (m *MockStore) SearchProducts(ctx context.Context, arg db.SearchProductsParams) ([]*db.SearchProductsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockStoreMockRecorder) SearchProducts(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockStore)(nil).SearchProducts), ctx, arg)
+}
+
+// SearchProductsNextPage mocks base method.
+func (m *MockStore) SearchProductsNextPage(ctx context.Context, arg db.SearchProductsNextPageParams) ([]*db.SearchProductsNextPageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProductsNextPage", ctx, arg)
+	ret0, _ := ret[0].([]*db.SearchProductsNextPageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProductsNextPage indicates an expected call of SearchProductsNextPage.
+func (mr *MockStoreMockRecorder) SearchProductsNextPage(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProductsNextPage", reflect.TypeOf((*MockStore)(nil).SearchProductsNextPage), ctx, arg)
+}
+
+// SetAdminRolesTx mocks base method.
+func (m *MockStore) SetAdminRolesTx(ctx context.Context, arg db.SetAdminRolesTxParams) ([]*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAdminRolesTx", ctx, arg)
+	ret0, _ := ret[0].([]*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAdminRolesTx indicates an expected call of SetAdminRolesTx.
+func (mr *MockStoreMockRecorder) SetAdminRolesTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAdminRolesTx", reflect.TypeOf((*MockStore)(nil).SetAdminRolesTx), ctx, arg)
+}
+
+// ShopOrderTrackNumberExists mocks base method.
+func (m *MockStore) ShopOrderTrackNumberExists(ctx context.Context, trackNumber string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShopOrderTrackNumberExists", ctx, trackNumber)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShopOrderTrackNumberExists indicates an expected call of ShopOrderTrackNumberExists.
+func (mr *MockStoreMockRecorder) ShopOrderTrackNumberExists(ctx, trackNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShopOrderTrackNumberExists", reflect.TypeOf((*MockStore)(nil).ShopOrderTrackNumberExists), ctx, trackNumber)
+}
+
+// SignUpTx mocks base method.
+func (m *MockStore) SignUpTx(ctx context.Context, arg db.SignUpTxParams) (*db.SignUpTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignUpTx", ctx, arg)
+	ret0, _ := ret[0].(*db.SignUpTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignUpTx indicates an expected call of SignUpTx.
+func (mr *MockStoreMockRecorder) SignUpTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpTx", reflect.TypeOf((*MockStore)(nil).SignUpTx), ctx, arg)
+}
+
+// StartRefundPaymentTransactionTx mocks base method.
+func (m *MockStore) StartRefundPaymentTransactionTx(ctx context.Context, arg db.StartRefundPaymentTransactionTxParams) (*db.StartRefundPaymentTransactionTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartRefundPaymentTransactionTx", ctx, arg)
+	ret0, _ := ret[0].(*db.StartRefundPaymentTransactionTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartRefundPaymentTransactionTx indicates an expected call of StartRefundPaymentTransactionTx.
+func (mr *MockStoreMockRecorder) StartRefundPaymentTransactionTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRefundPaymentTransactionTx", reflect.TypeOf((*MockStore)(nil).StartRefundPaymentTransactionTx), ctx, arg)
+}
+
+// UpdateAddress mocks base method.
+func (m *MockStore) UpdateAddress(ctx context.Context, arg db.UpdateAddressParams) (*db.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddress", ctx, arg)
+	ret0, _ := ret[0].(*db.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAddress indicates an expected call of UpdateAddress.
+func (mr *MockStoreMockRecorder) UpdateAddress(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddress", reflect.TypeOf((*MockStore)(nil).UpdateAddress), ctx, arg)
+}
+
+// UpdateAdmin mocks base method.
+func (m *MockStore) UpdateAdmin(ctx context.Context, arg db.UpdateAdminParams) (*db.Admin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdmin", ctx, arg)
+	ret0, _ := ret[0].(*db.Admin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdmin indicates an expected call of UpdateAdmin.
+func (mr *MockStoreMockRecorder) UpdateAdmin(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdmin", reflect.TypeOf((*MockStore)(nil).UpdateAdmin), ctx, arg)
+}
+
+// UpdateAdminRole mocks base method.
+func (m *MockStore) UpdateAdminRole(ctx context.Context, arg db.UpdateAdminRoleParams) (*db.AdminRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminRole", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdminRole indicates an expected call of UpdateAdminRole.
+func (mr *MockStoreMockRecorder) UpdateAdminRole(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminRole", reflect.TypeOf((*MockStore)(nil).UpdateAdminRole), ctx, arg)
+}
+
+// UpdateAdminRoleTx mocks base method.
+func (m *MockStore) UpdateAdminRoleTx(ctx context.Context, arg db.UpdateAdminRoleTxParams) (*db.AdminRoleTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminRoleTx", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRoleTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdminRoleTx indicates an expected call of UpdateAdminRoleTx.
+func (mr *MockStoreMockRecorder) UpdateAdminRoleTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminRoleTx", reflect.TypeOf((*MockStore)(nil).UpdateAdminRoleTx), ctx, arg)
+}
+
+// UpdateAdminSecuritySetting mocks base method.
+func (m *MockStore) UpdateAdminSecuritySetting(ctx context.Context, requireTwoFactor bool) (*db.AdminSecuritySetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminSecuritySetting", ctx, requireTwoFactor)
+	ret0, _ := ret[0].(*db.AdminSecuritySetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdminSecuritySetting indicates an expected call of UpdateAdminSecuritySetting.
+func (mr *MockStoreMockRecorder) UpdateAdminSecuritySetting(ctx, requireTwoFactor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminSecuritySetting", reflect.TypeOf((*MockStore)(nil).UpdateAdminSecuritySetting), ctx, requireTwoFactor)
+}
+
+// UpdateAdminSession mocks base method.
+func (m *MockStore) UpdateAdminSession(ctx context.Context, arg db.UpdateAdminSessionParams) (*db.AdminSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminSession", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdminSession indicates an expected call of UpdateAdminSession.
+func (mr *MockStoreMockRecorder) UpdateAdminSession(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminSession", reflect.TypeOf((*MockStore)(nil).UpdateAdminSession), ctx, arg)
+}
+
+// UpdateAdminTotpLastUsedStep mocks base method.
+func (m *MockStore) UpdateAdminTotpLastUsedStep(ctx context.Context, arg db.UpdateAdminTotpLastUsedStepParams) (*db.AdminTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminTotpLastUsedStep", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdminTotpLastUsedStep indicates an expected call of UpdateAdminTotpLastUsedStep.
+func (mr *MockStoreMockRecorder) UpdateAdminTotpLastUsedStep(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminTotpLastUsedStep", reflect.TypeOf((*MockStore)(nil).UpdateAdminTotpLastUsedStep), ctx, arg)
+}
+
+// UpdateAdminType mocks base method.
+func (m *MockStore) UpdateAdminType(ctx context.Context, arg db.UpdateAdminTypeParams) (*db.AdminType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminType", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdminType indicates an expected call of UpdateAdminType.
+func (mr *MockStoreMockRecorder) UpdateAdminType(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminType", reflect.TypeOf((*MockStore)(nil).UpdateAdminType), ctx, arg)
 }
 
 // UpdateAppPolicy mocks base method.
@@ -3954,6 +5955,21 @@ func (mr *MockStoreMockRecorder) UpdateHomePageTextBanner(ctx, arg any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomePageTextBanner", reflect.TypeOf((*MockStore)(nil).UpdateHomePageTextBanner), ctx, arg)
 }
 
+// UpdateIdempotencyKeyResponse mocks base method.
+func (m *MockStore) UpdateIdempotencyKeyResponse(ctx context.Context, arg db.UpdateIdempotencyKeyResponseParams) (*db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIdempotencyKeyResponse", ctx, arg)
+	ret0, _ := ret[0].(*db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateIdempotencyKeyResponse indicates an expected call of UpdateIdempotencyKeyResponse.
+func (mr *MockStoreMockRecorder) UpdateIdempotencyKeyResponse(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIdempotencyKeyResponse", reflect.TypeOf((*MockStore)(nil).UpdateIdempotencyKeyResponse), ctx, arg)
+}
+
 // UpdateNotification mocks base method.
 func (m *MockStore) UpdateNotification(ctx context.Context, arg db.UpdateNotificationParams) (*db.Notification, error) {
 	m.ctrl.T.Helper()
@@ -3999,6 +6015,66 @@ func (mr *MockStoreMockRecorder) UpdatePaymentMethod(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentMethod", reflect.TypeOf((*MockStore)(nil).UpdatePaymentMethod), ctx, arg)
 }
 
+// UpdatePaymentTransaction mocks base method.
+func (m *MockStore) UpdatePaymentTransaction(ctx context.Context, arg db.UpdatePaymentTransactionParams) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentTransaction", ctx, arg)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentTransaction indicates an expected call of UpdatePaymentTransaction.
+func (mr *MockStoreMockRecorder) UpdatePaymentTransaction(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentTransaction", reflect.TypeOf((*MockStore)(nil).UpdatePaymentTransaction), ctx, arg)
+}
+
+// UpdatePaymentTransactionAmount mocks base method.
+func (m *MockStore) UpdatePaymentTransactionAmount(ctx context.Context, arg db.UpdatePaymentTransactionAmountParams) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentTransactionAmount", ctx, arg)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentTransactionAmount indicates an expected call of UpdatePaymentTransactionAmount.
+func (mr *MockStoreMockRecorder) UpdatePaymentTransactionAmount(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentTransactionAmount", reflect.TypeOf((*MockStore)(nil).UpdatePaymentTransactionAmount), ctx, arg)
+}
+
+// UpdatePaymentTransactionRefund mocks base method.
+func (m *MockStore) UpdatePaymentTransactionRefund(ctx context.Context, arg db.UpdatePaymentTransactionRefundParams) (*db.PaymentTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentTransactionRefund", ctx, arg)
+	ret0, _ := ret[0].(*db.PaymentTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentTransactionRefund indicates an expected call of UpdatePaymentTransactionRefund.
+func (mr *MockStoreMockRecorder) UpdatePaymentTransactionRefund(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentTransactionRefund", reflect.TypeOf((*MockStore)(nil).UpdatePaymentTransactionRefund), ctx, arg)
+}
+
+// UpdatePaymentTransactionTx mocks base method.
+func (m *MockStore) UpdatePaymentTransactionTx(ctx context.Context, arg db.UpdatePaymentTransactionTxParams) (*db.UpdatePaymentTransactionTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentTransactionTx", ctx, arg)
+	ret0, _ := ret[0].(*db.UpdatePaymentTransactionTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentTransactionTx indicates an expected call of UpdatePaymentTransactionTx.
+func (mr *MockStoreMockRecorder) UpdatePaymentTransactionTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentTransactionTx", reflect.TypeOf((*MockStore)(nil).UpdatePaymentTransactionTx), ctx, arg)
+}
+
 // UpdatePaymentType mocks base method.
 func (m *MockStore) UpdatePaymentType(ctx context.Context, arg db.UpdatePaymentTypeParams) (*db.PaymentType, error) {
 	m.ctrl.T.Helper()
@@ -4059,6 +6135,21 @@ func (mr *MockStoreMockRecorder) UpdateProductCategory(ctx, arg any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductCategory", reflect.TypeOf((*MockStore)(nil).UpdateProductCategory), ctx, arg)
 }
 
+// UpdateProductCategoryParent mocks base method.
+func (m *MockStore) UpdateProductCategoryParent(ctx context.Context, arg db.UpdateProductCategoryParentParams) (*db.ProductCategory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductCategoryParent", ctx, arg)
+	ret0, _ := ret[0].(*db.ProductCategory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProductCategoryParent indicates an expected call of UpdateProductCategoryParent.
+func (mr *MockStoreMockRecorder) UpdateProductCategoryParent(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductCategoryParent", reflect.TypeOf((*MockStore)(nil).UpdateProductCategoryParent), ctx, arg)
+}
+
 // UpdateProductColor mocks base method.
 func (m *MockStore) UpdateProductColor(ctx context.Context, arg db.UpdateProductColorParams) (*db.ProductColor, error) {
 	m.ctrl.T.Helper()
@@ -4165,18 +6256,33 @@ func (mr *MockStoreMockRecorder) UpdatePromotion(ctx, arg any) *gomock.Call {
 }
 
 // UpdateResetPassword mocks base method.
-func (m *MockStore) UpdateResetPassword(ctx context.Context, arg db.UpdateResetPasswordParams) (*db.ResetPassword, error) {
+func (m *MockStore) UpdateResetPassword(ctx context.Context, id int64) (*db.ResetPassword, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateResetPassword", ctx, arg)
+	ret := m.ctrl.Call(m, "UpdateResetPassword", ctx, id)
 	ret0, _ := ret[0].(*db.ResetPassword)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateResetPassword indicates an expected call of UpdateResetPassword.
-func (mr *MockStoreMockRecorder) UpdateResetPassword(ctx, arg any) *gomock.Call {
+func (mr *MockStoreMockRecorder) UpdateResetPassword(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResetPassword", reflect.TypeOf((*MockStore)(nil).UpdateResetPassword), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResetPassword", reflect.TypeOf((*MockStore)(nil).UpdateResetPassword), ctx, id)
+}
+
+// UpdateReturnRequestTx mocks base method.
+func (m *MockStore) UpdateReturnRequestTx(ctx context.Context, arg db.UpdateReturnRequestTxParams) (*db.UpdateReturnRequestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReturnRequestTx", ctx, arg)
+	ret0, _ := ret[0].(*db.UpdateReturnRequestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateReturnRequestTx indicates an expected call of UpdateReturnRequestTx.
+func (mr *MockStoreMockRecorder) UpdateReturnRequestTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReturnRequestTx", reflect.TypeOf((*MockStore)(nil).UpdateReturnRequestTx), ctx, arg)
 }
 
 // UpdateShippingMethod mocks base method.
@@ -4224,6 +6330,36 @@ func (mr *MockStoreMockRecorder) UpdateShopOrderItem(ctx, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShopOrderItem", reflect.TypeOf((*MockStore)(nil).UpdateShopOrderItem), ctx, arg)
 }
 
+// UpdateShopOrderStatusByUserID mocks base method.
+func (m *MockStore) UpdateShopOrderStatusByUserID(ctx context.Context, arg db.UpdateShopOrderStatusByUserIDParams) (*db.ShopOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShopOrderStatusByUserID", ctx, arg)
+	ret0, _ := ret[0].(*db.ShopOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateShopOrderStatusByUserID indicates an expected call of UpdateShopOrderStatusByUserID.
+func (mr *MockStoreMockRecorder) UpdateShopOrderStatusByUserID(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShopOrderStatusByUserID", reflect.TypeOf((*MockStore)(nil).UpdateShopOrderStatusByUserID), ctx, arg)
+}
+
+// UpdateShopOrderTx mocks base method.
+func (m *MockStore) UpdateShopOrderTx(ctx context.Context, arg db.UpdateShopOrderTxParams) (*db.UpdateShopOrderTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShopOrderTx", ctx, arg)
+	ret0, _ := ret[0].(*db.UpdateShopOrderTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateShopOrderTx indicates an expected call of UpdateShopOrderTx.
+func (mr *MockStoreMockRecorder) UpdateShopOrderTx(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShopOrderTx", reflect.TypeOf((*MockStore)(nil).UpdateShopOrderTx), ctx, arg)
+}
+
 // UpdateShoppingCart mocks base method.
 func (m *MockStore) UpdateShoppingCart(ctx context.Context, arg db.UpdateShoppingCartParams) (*db.ShoppingCart, error) {
 	m.ctrl.T.Helper()
@@ -4269,6 +6405,21 @@ func (mr *MockStoreMockRecorder) UpdateUser(ctx, arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), ctx, arg)
 }
 
+// UpdateUserEmail mocks base method.
+func (m *MockStore) UpdateUserEmail(ctx context.Context, arg db.UpdateUserEmailParams) (*db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", ctx, arg)
+	ret0, _ := ret[0].(*db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockStoreMockRecorder) UpdateUserEmail(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockStore)(nil).UpdateUserEmail), ctx, arg)
+}
+
 // UpdateUserEmailisVerifiedForTest mocks base method.
 func (m *MockStore) UpdateUserEmailisVerifiedForTest(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -4402,3 +6553,93 @@ func (mr *MockStoreMockRecorder) UpdateWishListItem(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWishListItem", reflect.TypeOf((*MockStore)(nil).UpdateWishListItem), ctx, arg)
 }
+
+// UpsertAdminTotp mocks base method.
+func (m *MockStore) UpsertAdminTotp(ctx context.Context, arg db.UpsertAdminTotpParams) (*db.AdminTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAdminTotp", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertAdminTotp indicates an expected call of UpsertAdminTotp.
+func (mr *MockStoreMockRecorder) UpsertAdminTotp(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAdminTotp", reflect.TypeOf((*MockStore)(nil).UpsertAdminTotp), ctx, arg)
+}
+
+// UpsertUserDeletion mocks base method.
+func (m *MockStore) UpsertUserDeletion(ctx context.Context, arg db.UpsertUserDeletionParams) (*db.UserDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserDeletion", ctx, arg)
+	ret0, _ := ret[0].(*db.UserDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserDeletion indicates an expected call of UpsertUserDeletion.
+func (mr *MockStoreMockRecorder) UpsertUserDeletion(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserDeletion", reflect.TypeOf((*MockStore)(nil).UpsertUserDeletion), ctx, arg)
+}
+
+// UseAdminLoginChallenge mocks base method.
+func (m *MockStore) UseAdminLoginChallenge(ctx context.Context, id int64) (*db.AdminLoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAdminLoginChallenge", ctx, id)
+	ret0, _ := ret[0].(*db.AdminLoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAdminLoginChallenge indicates an expected call of UseAdminLoginChallenge.
+func (mr *MockStoreMockRecorder) UseAdminLoginChallenge(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAdminLoginChallenge", reflect.TypeOf((*MockStore)(nil).UseAdminLoginChallenge), ctx, id)
+}
+
+// UseAdminRecoveryCode mocks base method.
+func (m *MockStore) UseAdminRecoveryCode(ctx context.Context, arg db.UseAdminRecoveryCodeParams) (*db.AdminRecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAdminRecoveryCode", ctx, arg)
+	ret0, _ := ret[0].(*db.AdminRecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAdminRecoveryCode indicates an expected call of UseAdminRecoveryCode.
+func (mr *MockStoreMockRecorder) UseAdminRecoveryCode(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAdminRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseAdminRecoveryCode), ctx, arg)
+}
+
+// UseEmailChange mocks base method.
+func (m *MockStore) UseEmailChange(ctx context.Context, id int64) (*db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailChange", ctx, id)
+	ret0, _ := ret[0].(*db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailChange indicates an expected call of UseEmailChange.
+func (mr *MockStoreMockRecorder) UseEmailChange(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailChange", reflect.TypeOf((*MockStore)(nil).UseEmailChange), ctx, id)
+}
+
+// VerifyUserEmailForSocialLogin mocks base method.
+func (m *MockStore) VerifyUserEmailForSocialLogin(ctx context.Context, arg db.VerifyUserEmailForSocialLoginParams) (*db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserEmailForSocialLogin", ctx, arg)
+	ret0, _ := ret[0].(*db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUserEmailForSocialLogin indicates an expected call of VerifyUserEmailForSocialLogin.
+func (mr *MockStoreMockRecorder) VerifyUserEmailForSocialLogin(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserEmailForSocialLogin", reflect.TypeOf((*MockStore)(nil).VerifyUserEmailForSocialLogin), ctx, arg)
+}
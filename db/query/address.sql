@@ -67,3 +67,7 @@ DELETE FROM "address" AS ad
 WHERE user_id = $1
 AND ad.id = $2
 RETURNING *;
+
+-- name: DeleteAddressesByUserID :execrows
+DELETE FROM "address"
+WHERE user_id = $1;
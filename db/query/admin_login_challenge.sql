@@ -0,0 +1,34 @@
+-- name: CreateAdminLoginChallenge :one
+INSERT INTO "admin_login_challenge" (
+  admin_id,
+  token_hash,
+  expired_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetAdminLoginChallenge :one
+SELECT * FROM "admin_login_challenge"
+WHERE token_hash = $1
+AND is_used = FALSE
+AND expired_at > now()
+LIMIT 1;
+
+-- name: IncrementAdminLoginChallengeAttempts :one
+UPDATE "admin_login_challenge"
+SET
+attempts = attempts + 1,
+expired_at = CASE WHEN attempts + 1 >= $1::int THEN now() ELSE expired_at END
+WHERE id = $2
+AND is_used = FALSE
+AND expired_at > now()
+RETURNING *;
+
+-- name: UseAdminLoginChallenge :one
+UPDATE "admin_login_challenge"
+SET is_used = TRUE
+WHERE id = $1
+AND is_used = FALSE
+AND expired_at > now()
+RETURNING *;
@@ -0,0 +1,89 @@
+-- name: AdminHasPermission :one
+SELECT EXISTS (
+  SELECT 1
+  FROM "admin_role_assignment" AS ara
+  JOIN "admin_role_permission" AS arp ON arp.role_id = ara.role_id
+  JOIN "admin" AS a ON a.id = ara.admin_id
+  WHERE ara.admin_id = $1
+  AND arp.permission = $2
+  AND a.active = TRUE
+);
+
+-- name: CreateAdminRole :one
+INSERT INTO "admin_role" (
+  name,
+  description
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: CreateAdminRoleAssignment :one
+INSERT INTO "admin_role_assignment" (
+  admin_id,
+  role_id
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: CreateAdminRolePermission :one
+INSERT INTO "admin_role_permission" (
+  role_id,
+  permission
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: DeleteAdminRole :one
+DELETE FROM "admin_role"
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAdminRoleAssignmentsByAdminID :exec
+DELETE FROM "admin_role_assignment"
+WHERE admin_id = $1;
+
+-- name: DeleteAdminRolePermissionsByRoleID :exec
+DELETE FROM "admin_role_permission"
+WHERE role_id = $1;
+
+-- name: GetAdminRole :one
+SELECT * FROM "admin_role"
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAdminRoleForUpdate :one
+SELECT * FROM "admin_role"
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAdminPermissions :many
+SELECT * FROM "admin_permission"
+ORDER BY code;
+
+-- name: ListAdminRolePermissionsByRoleIDs :many
+SELECT * FROM "admin_role_permission"
+WHERE role_id = ANY(sqlc.arg(role_ids)::bigint[])
+ORDER BY role_id, permission;
+
+-- name: ListAdminRoles :many
+SELECT * FROM "admin_role"
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListAdminRolesByAdminID :many
+SELECT ar.* FROM "admin_role" AS ar
+JOIN "admin_role_assignment" AS ara ON ara.role_id = ar.id
+WHERE ara.admin_id = $1
+ORDER BY ar.id;
+
+-- name: UpdateAdminRole :one
+UPDATE "admin_role"
+SET
+name = COALESCE(sqlc.narg(name),name),
+description = COALESCE(sqlc.narg(description),description),
+updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,10 @@
+-- name: GetAdminSecuritySetting :one
+SELECT * FROM "admin_security_setting"
+LIMIT 1;
+
+-- name: UpdateAdminSecuritySetting :one
+UPDATE "admin_security_setting"
+SET
+require_two_factor = $1,
+updated_at = now()
+RETURNING *;
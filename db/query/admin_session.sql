@@ -6,9 +6,10 @@ INSERT INTO "admin_session" (
   admin_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
 RETURNING *;
 
@@ -16,6 +17,49 @@ RETURNING *;
 SELECT * FROM "admin_session"
 WHERE id = $1 LIMIT 1;
 
+-- name: IsAdminSessionActive :one
+SELECT EXISTS (
+  SELECT 1 FROM "admin_session"
+  WHERE id = $1
+  AND admin_id = $2
+  AND is_blocked = false
+  AND expires_at > now()
+);
+
+-- name: ListActiveAdminSessions :many
+SELECT * FROM "admin_session"
+WHERE admin_id = $1
+AND is_blocked = false
+AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: RevokeAdminSessionFamily :execrows
+UPDATE "admin_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE family_id = $1
+AND is_blocked = false;
+
+-- name: RevokeAdminSessions :execrows
+UPDATE "admin_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE admin_id = $1
+AND is_blocked = false;
+
+-- name: RotateAdminSession :one
+UPDATE "admin_session"
+SET
+replaced_by = sqlc.arg(replaced_by)::uuid,
+is_blocked = true,
+updated_at = now()
+WHERE id = sqlc.arg(id)
+AND replaced_by IS NULL
+AND is_blocked = false
+RETURNING *;
+
 -- name: UpdateAdminSession :one
 UPDATE "admin_session"
 SET 
@@ -0,0 +1,66 @@
+-- name: CountAdminRecoveryCodesLeft :one
+SELECT COUNT(*) FROM "admin_recovery_code"
+WHERE admin_id = $1
+AND is_used = FALSE;
+
+-- name: DeleteAdminTotp :exec
+WITH deleted_codes AS (
+  DELETE FROM "admin_recovery_code"
+  WHERE admin_id = $1
+)
+DELETE FROM "admin_totp"
+WHERE admin_id = $1;
+
+-- name: EnableAdminTotp :one
+UPDATE "admin_totp"
+SET
+enabled = TRUE,
+last_used_step = $2,
+enabled_at = now()
+WHERE admin_id = $1
+AND enabled = FALSE
+RETURNING *;
+
+-- name: GetAdminTotp :one
+SELECT * FROM "admin_totp"
+WHERE admin_id = $1 LIMIT 1;
+
+-- name: ReplaceAdminRecoveryCodes :exec
+WITH deleted_codes AS (
+  DELETE FROM "admin_recovery_code"
+  WHERE admin_id = sqlc.arg(admin_id)
+)
+INSERT INTO "admin_recovery_code" (
+  admin_id,
+  code_hash
+)
+SELECT sqlc.arg(admin_id), unnest(sqlc.arg(code_hashes)::varchar[]);
+
+-- name: UpdateAdminTotpLastUsedStep :one
+UPDATE "admin_totp"
+SET last_used_step = $2
+WHERE admin_id = $1
+AND enabled = TRUE
+AND last_used_step < $2
+RETURNING *;
+
+-- name: UpsertAdminTotp :one
+INSERT INTO "admin_totp" (
+  admin_id,
+  secret
+) VALUES (
+  $1, $2
+) ON CONFLICT(admin_id) DO UPDATE SET
+secret = EXCLUDED.secret,
+last_used_step = 0,
+created_at = now()
+WHERE "admin_totp".enabled = FALSE
+RETURNING *;
+
+-- name: UseAdminRecoveryCode :one
+UPDATE "admin_recovery_code"
+SET is_used = TRUE
+WHERE admin_id = $1
+AND code_hash = $2
+AND is_used = FALSE
+RETURNING *;
@@ -0,0 +1,135 @@
+-- name: AdminCreateCoupon :one
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+INSERT INTO "coupon" (
+  code,
+  description,
+  discount_type,
+  discount_value,
+  min_order_value,
+  max_uses,
+  max_uses_per_user,
+  category_id,
+  brand_id,
+  product_id,
+  start_date,
+  end_date,
+  active
+)
+SELECT sqlc.arg(code), sqlc.arg(description), sqlc.arg(discount_type),
+sqlc.arg(discount_value), sqlc.arg(min_order_value), sqlc.narg(max_uses),
+sqlc.narg(max_uses_per_user), sqlc.narg(category_id), sqlc.narg(brand_id),
+sqlc.narg(product_id), sqlc.arg(start_date), sqlc.arg(end_date), sqlc.arg(active) FROM t1
+WHERE is_admin=1
+RETURNING *;
+
+-- name: AdminGetCoupon :one
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+SELECT * FROM "coupon"
+WHERE "coupon".id = sqlc.arg(id)
+AND (SELECT is_admin FROM t1) = 1
+LIMIT 1;
+
+-- name: AdminListCoupons :many
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+SELECT * FROM "coupon"
+WHERE (SELECT is_admin FROM t1) = 1
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: AdminUpdateCoupon :one
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+UPDATE "coupon"
+SET
+code = COALESCE(sqlc.narg(code),code),
+description = COALESCE(sqlc.narg(description),description),
+discount_type = COALESCE(sqlc.narg(discount_type),discount_type),
+discount_value = COALESCE(sqlc.narg(discount_value),discount_value),
+min_order_value = COALESCE(sqlc.narg(min_order_value),min_order_value),
+max_uses = COALESCE(sqlc.narg(max_uses),max_uses),
+max_uses_per_user = COALESCE(sqlc.narg(max_uses_per_user),max_uses_per_user),
+category_id = COALESCE(sqlc.narg(category_id),category_id),
+brand_id = COALESCE(sqlc.narg(brand_id),brand_id),
+product_id = COALESCE(sqlc.narg(product_id),product_id),
+start_date = COALESCE(sqlc.narg(start_date),start_date),
+end_date = COALESCE(sqlc.narg(end_date),end_date),
+active = COALESCE(sqlc.narg(active),active),
+updated_at = NOW()
+WHERE "coupon".id = sqlc.arg(id)
+AND (SELECT is_admin FROM t1) = 1
+RETURNING *;
+
+-- name: AdminDeleteCoupon :one
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+DELETE FROM "coupon"
+WHERE "coupon".id = sqlc.arg(id)
+AND (SELECT is_admin FROM t1) = 1
+RETURNING *;
+
+-- name: GetCouponByCodeForUpdate :one
+SELECT * FROM "coupon"
+WHERE code = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: IncrementCouponUsedCount :one
+UPDATE "coupon"
+SET used_count = used_count + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: CountCouponRedemptionsByUser :one
+SELECT COUNT(*) FROM "coupon_redemption"
+WHERE coupon_id = $1
+AND user_id = $2;
+
+-- name: CreateCouponRedemption :one
+INSERT INTO "coupon_redemption" (
+  coupon_id,
+  user_id,
+  shop_order_id,
+  discount_amount
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetCouponRedemptionByShopOrderID :one
+SELECT * FROM "coupon_redemption"
+WHERE shop_order_id = $1 LIMIT 1;
+
+-- name: DeleteCouponRedemptionByShopOrderID :one
+-- the redemption of a cancelled or returned order doesn't count against the coupon limits anymore
+DELETE FROM "coupon_redemption"
+WHERE shop_order_id = $1
+RETURNING *;
+
+-- name: DecrementCouponUsedCount :one
+UPDATE "coupon"
+SET used_count = GREATEST(used_count - 1, 0)
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,42 @@
+-- name: CreateEmailChange :one
+INSERT INTO "email_change" (
+  user_id,
+  new_email,
+  secret_code
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetLastEmailChange :one
+SELECT * FROM "email_change"
+WHERE user_id = $1
+AND is_used = FALSE
+AND expired_at > now()
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: IncrementEmailChangeAttempts :one
+UPDATE "email_change"
+SET
+  attempts = attempts + 1,
+  expired_at = CASE WHEN attempts + 1 >= @max_attempts::int THEN now() ELSE expired_at END
+WHERE
+  id = @id
+  AND is_used = FALSE
+  AND expired_at > now()
+RETURNING *;
+
+-- name: UseEmailChange :one
+UPDATE "email_change"
+SET
+  is_used = TRUE
+WHERE
+  id = @id
+  AND is_used = FALSE
+  AND expired_at > now()
+RETURNING *;
+
+-- name: DeleteEmailChangesByUserID :exec
+DELETE FROM "email_change"
+WHERE user_id = $1;
@@ -0,0 +1,37 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO "idempotency_key" (
+  user_id,
+  key,
+  request_method,
+  request_path,
+  request_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (user_id, key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM "idempotency_key"
+WHERE user_id = $1
+AND key = $2
+LIMIT 1;
+
+-- name: UpdateIdempotencyKeyResponse :one
+UPDATE "idempotency_key"
+SET
+response_status = $3,
+response_body = $4
+WHERE user_id = $1
+AND key = $2
+RETURNING *;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM "idempotency_key"
+WHERE user_id = $1
+AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM "idempotency_key"
+WHERE expires_at < now();
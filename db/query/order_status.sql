@@ -11,6 +11,10 @@ RETURNING *;
 SELECT * FROM "order_status"
 WHERE id = $1 LIMIT 1;
 
+-- name: GetOrderStatusByCode :one
+SELECT * FROM "order_status"
+WHERE code = $1 LIMIT 1;
+
 -- name: GetOrderStatusByUserID :one
 SELECT os.*, so.user_id
 FROM "order_status" AS os
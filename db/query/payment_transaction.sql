@@ -0,0 +1,72 @@
+-- name: CreatePaymentTransaction :one
+INSERT INTO "payment_transaction" (
+  shop_order_id,
+  provider,
+  amount
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetPaymentTransaction :one
+SELECT * FROM "payment_transaction"
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentTransactionByProviderReference :one
+SELECT * FROM "payment_transaction"
+WHERE provider = $1
+AND provider_reference = $2
+LIMIT 1;
+
+-- name: GetPaymentTransactionForUpdate :one
+SELECT * FROM "payment_transaction"
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetLastPaymentTransactionForUpdate :one
+SELECT * FROM "payment_transaction"
+WHERE shop_order_id = $1
+ORDER BY id DESC
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPaymentTransactionsByShopOrderID :many
+SELECT * FROM "payment_transaction"
+WHERE shop_order_id = $1
+ORDER BY id;
+
+-- name: ListPaymentTransactionsWithPendingRefund :many
+SELECT * FROM "payment_transaction"
+WHERE pending_refund_amount::numeric > 0
+OR refund_in_flight_amount::numeric > 0
+ORDER BY id
+LIMIT $1;
+
+-- name: UpdatePaymentTransaction :one
+UPDATE "payment_transaction"
+SET
+provider_reference = COALESCE(sqlc.narg(provider_reference),provider_reference),
+status = sqlc.arg(status),
+updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdatePaymentTransactionAmount :one
+UPDATE "payment_transaction"
+SET
+amount = sqlc.arg(amount),
+updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdatePaymentTransactionRefund :one
+UPDATE "payment_transaction"
+SET
+status = sqlc.arg(status),
+refunded_amount = sqlc.arg(refunded_amount),
+pending_refund_amount = sqlc.arg(pending_refund_amount),
+refund_in_flight_amount = sqlc.arg(refund_in_flight_amount),
+refund_sequence = sqlc.arg(refund_sequence),
+updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -16,9 +16,10 @@ SELECT 1 AS is_admin
     )
 INSERT INTO "payment_type" (
   value,
-  is_active
+  is_active,
+  provider
 )
-SELECT sqlc.arg(value), sqlc.arg(is_active) FROM t1
+SELECT sqlc.arg(value), sqlc.arg(is_active), sqlc.arg(provider) FROM t1
 WHERE is_admin=1
 ON CONFLICT(value) DO UPDATE SET value = sqlc.arg(value)
 RETURNING *;
@@ -53,7 +54,8 @@ SELECT 1 AS is_admin
 UPDATE "payment_type"
 SET 
 value = COALESCE(sqlc.narg(value),value),
-is_active = COALESCE(sqlc.narg(is_active),is_active)
+is_active = COALESCE(sqlc.narg(is_active),is_active),
+provider = COALESCE(sqlc.narg(provider),provider)
 WHERE "payment_type".id = sqlc.arg(id)
 AND (SELECT is_admin FROM t1) = 1
 RETURNING *;
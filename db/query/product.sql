@@ -104,7 +104,7 @@ WHERE id = ANY(sqlc.arg(ids)::bigint[]);
 -- name: ListProductsV2 :many
 WITH t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
 ORDER BY id DESC
 LIMIT $1 +1
@@ -116,7 +116,7 @@ LIMIT $1;
 -- name: ListProductsNextPage :many
 WITH t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
 WHERE
  p.id < sqlc.arg(id) 
@@ -128,24 +128,35 @@ SELECT *,COUNT(*) OVER()>10 AS next_available FROM t1
 LIMIT $1;
 
 -- name: SearchProducts :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query(sqlc.arg(query)::VARCHAR) AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
+CROSS JOIN search AS s
+LEFT JOIN "product_brand" AS pb ON pb.id = p.brand_id
+LEFT JOIN "product_category" AS pc ON pc.id = p.category_id
 WHERE 
-p.search @@ 
-CASE
-    WHEN char_length(sqlc.arg(query)::VARCHAR) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
@@ -153,26 +164,36 @@ SELECT *,COUNT(*) OVER()>10 AS next_available FROM t1
 LIMIT $1;
 
 -- name: SearchProductsNextPage :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query(sqlc.arg(query)::VARCHAR) AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
-
+CROSS JOIN search AS s
+LEFT JOIN "product_brand" AS pb ON pb.id = p.brand_id
+LEFT JOIN "product_category" AS pc ON pc.id = p.category_id
 WHERE 
 p.id < sqlc.arg(product_id) AND
-p.search @@ 
-CASE
-    WHEN char_length(sqlc.arg(query)::VARCHAR) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*')::VARCHAR)
-    ELSE to_tsquery(sqlc.arg(query)::VARCHAR)
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length(sqlc.arg(query)::VARCHAR) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*')::VARCHAR)
-    ELSE to_tsquery(sqlc.arg(query)::VARCHAR)
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
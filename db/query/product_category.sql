@@ -64,4 +64,54 @@ RETURNING *;
 -- name: DeleteProductCategory :exec
 DELETE FROM "product_category"
 WHERE id = sqlc.arg(id)
-AND ( parent_category_id is NULL OR parent_category_id = sqlc.arg(parent_category_id) );
\ No newline at end of file
+AND ( parent_category_id is NULL OR parent_category_id = sqlc.arg(parent_category_id) );
+
+-- name: ListProductCategoryTree :many
+-- every category with the number of its active products, the tree is assembled from the parent ids
+SELECT
+ pc.id, pc.parent_category_id, pc.category_name, pc.category_image,
+ COUNT(p.id) AS product_count
+FROM "product_category" AS pc
+LEFT JOIN "product" AS p ON p.category_id = pc.id AND p.active = TRUE
+GROUP BY pc.id
+ORDER BY pc.id;
+
+-- name: ListProductCategoryBreadcrumbs :many
+-- the ancestors of the category from the root down to the category itself
+WITH RECURSIVE breadcrumb AS (
+SELECT pc.id, pc.parent_category_id, pc.category_name, 0 AS depth
+FROM "product_category" AS pc
+WHERE pc.id = $1
+UNION ALL
+SELECT parent.id, parent.parent_category_id, parent.category_name, b.depth + 1
+FROM "product_category" AS parent
+INNER JOIN breadcrumb AS b ON parent.id = b.parent_category_id
+-- stops on a cycle written to the table outside of MoveProductCategoryTx
+WHERE b.depth < 64
+)
+SELECT id, parent_category_id, category_name FROM breadcrumb
+ORDER BY depth DESC;
+
+-- name: LockProductCategoryTree :exec
+-- serializes the moves of the categories until the end of the transaction, so two moves can't build a cycle together
+SELECT pg_advisory_xact_lock(hashtext('product_category_tree'));
+
+-- name: IsProductCategoryInSubtree :one
+-- whether the category is the root category or one of its descendants
+WITH RECURSIVE subtree AS (
+SELECT sqlc.arg(root_id)::BIGINT AS id
+UNION
+SELECT pc.id FROM "product_category" AS pc
+INNER JOIN subtree AS s ON pc.parent_category_id = s.id
+)
+SELECT EXISTS (
+SELECT 1 FROM subtree
+WHERE id = sqlc.arg(category_id)::BIGINT
+) AS in_subtree;
+
+-- name: UpdateProductCategoryParent :one
+-- a NULL parent moves the category to the root
+UPDATE "product_category"
+SET parent_category_id = sqlc.narg(parent_category_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -37,7 +37,12 @@ WHERE is_admin=1
 RETURNING *;
     
 -- name: GetProductItem :one
-SELECT * ,COALESCE(stock.total_stock,0) as qty_in_stock FROM "product_item"
+SELECT * ,GREATEST(COALESCE(stock.total_stock,0) - COALESCE((
+    SELECT SUM(qty)
+    FROM "stock_reservation"
+    WHERE product_item_id = $1
+    AND expires_at > now()
+),0), 0) as qty_in_stock FROM "product_item"
 LEFT JOIN (
     SELECT 
         product_item_id, 
@@ -324,9 +329,10 @@ ORDER BY pi.id DESC
 LIMIT $1;
 
 -- name: ListProductItemsV2 :many
+-- a parent category matches the items of all its descendant categories
 WITH t1 AS(
 SELECT 
- pi.*, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.*, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image, /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -401,7 +407,15 @@ ELSE 1=1
 END
 AND CASE
     WHEN COALESCE(sqlc.narg(category_id), 0) > 0 
-    THEN pc.id = sqlc.narg(category_id)
+    THEN p.category_id IN (
+        WITH RECURSIVE category_tree AS (
+            SELECT sqlc.narg(category_id)::BIGINT AS id
+            UNION
+            SELECT c.id FROM "product_category" AS c
+            INNER JOIN category_tree AS ct ON c.parent_category_id = ct.id
+        )
+        SELECT id FROM category_tree
+    )
     ELSE 1=1
 END 
 AND CASE
@@ -452,9 +466,10 @@ SELECT *,COUNT(*) OVER()> $1 AS next_available FROM t1
 LIMIT $1;
 
 -- name: ListProductItemsNextPage :many
+-- a parent category matches the items of all its descendant categories
 WITH t1 AS(
 SELECT 
- pi.*, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.*, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image, /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -573,7 +588,15 @@ ELSE 1=1
 END
 AND CASE
     WHEN COALESCE(sqlc.narg(category_id), 0) > 0 
-    THEN pc.id = sqlc.narg(category_id)
+    THEN p.category_id IN (
+        WITH RECURSIVE category_tree AS (
+            SELECT sqlc.narg(category_id)::BIGINT AS id
+            UNION
+            SELECT c.id FROM "product_category" AS c
+            INNER JOIN category_tree AS ct ON c.parent_category_id = ct.id
+        )
+        SELECT id FROM category_tree
+    )
     ELSE 1=1
 END 
 AND CASE
@@ -663,22 +686,26 @@ LEFT JOIN (
 
 ) AS stock ON stock.product_item_id = pi.id
 WHERE pi.active = TRUE AND p.search @@ 
-CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+product_search_query(sqlc.arg(query)::VARCHAR)
 ORDER BY pi.id DESC, ts_rank(p.search, 
-CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+product_search_query(sqlc.arg(query)::VARCHAR)
 ) DESC
 LIMIT $1;
 
 -- name: SearchProductItems :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query(sqlc.arg(query)::VARCHAR) AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- pi.*, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.*, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image,   /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -695,6 +722,7 @@ SELECT
  COALESCE(ppromo.active, FALSE) AS product_promo_active
 FROM "product_item" AS pi
 INNER JOIN "product" AS p ON p.id = pi.product_id
+CROSS JOIN search AS s
 -- LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
 LEFT JOIN "product_color" AS pclr ON pclr.id = pi.color_id
@@ -719,20 +747,18 @@ LEFT JOIN (
 WHERE 
 pi.active = TRUE AND
 p.active =TRUE AND
-p.search @@ 
-CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 pi.id DESC,
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
@@ -781,23 +807,27 @@ LEFT JOIN (
 WHERE pi.active = TRUE 
 AND p.active = TRUE
 AND p.search @@  
-CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+product_search_query(sqlc.arg(query)::VARCHAR)
 AND pi.id < $2
 ORDER BY pi.id DESC, ts_rank(p.search, 
-CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+product_search_query(sqlc.arg(query)::VARCHAR)
 ) DESC
 LIMIT $1;
 
 -- name: SearchProductItemsNextPage :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query(sqlc.arg(query)::VARCHAR) AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- pi.*, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.*, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image,  /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -814,6 +844,7 @@ SELECT
  COALESCE(ppromo.active, FALSE) AS product_promo_active
 FROM "product_item" AS pi
 INNER JOIN "product" AS p ON p.id = pi.product_id
+CROSS JOIN search AS s
 -- LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
 LEFT JOIN "product_color" AS pclr ON pclr.id = pi.color_id
@@ -840,20 +871,18 @@ WHERE
 OR (pi.id = sqlc.arg(product_item_id) AND p.id < sqlc.arg(product_id))) AND
 pi.active = TRUE AND
 p.active =TRUE AND
-p.search @@ 
-CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 pi.id DESC,
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length(sqlc.arg(query)) > 0 THEN to_tsquery(concat(sqlc.arg(query), ':*'))
-    ELSE to_tsquery(sqlc.arg(query))
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
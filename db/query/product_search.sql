@@ -0,0 +1,160 @@
+-- name: SearchProductItemsFaceted :many
+-- every list filter matches any of its values, an empty list doesn't filter
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ CASE
+    WHEN char_length(trim(sqlc.arg(query)::VARCHAR)) > 0
+    THEN product_search_query(sqlc.arg(query)::VARCHAR)
+ END AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+) AS q
+)
+SELECT
+ pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active,
+ p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
+ pc.category_image, pb.brand_name, pb.brand_image,
+ pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
+ cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
+ cpromo.discount_rate AS category_promo_discount_rate,
+ cpromo.start_date AS category_promo_start_date, cpromo.end_date AS category_promo_end_date,
+ bpromo.id AS brand_promo_id, bpromo.name AS brand_promo_name, bpromo.description AS brand_promo_description,
+ bpromo.discount_rate AS brand_promo_discount_rate,
+ bpromo.start_date AS brand_promo_start_date, bpromo.end_date AS brand_promo_end_date,
+ ppromo.id AS product_promo_id, ppromo.name AS product_promo_name, ppromo.description AS product_promo_description,
+ ppromo.discount_rate AS product_promo_discount_rate,
+ ppromo.start_date AS product_promo_start_date, ppromo.end_date AS product_promo_end_date,
+ COALESCE(stock.total_stock,0) as qty_in_stock, p.active AS parent_product_active,
+ COALESCE(cpromo.active, FALSE) AS category_promo_active, COALESCE(bpromo.active, FALSE) AS brand_promo_active,
+ COALESCE(ppromo.active, FALSE) AS product_promo_active,
+ COUNT(*) OVER() AS total_count
+FROM "product_item" AS pi
+INNER JOIN "product" AS p ON p.id = pi.product_id
+CROSS JOIN search AS s
+LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
+LEFT JOIN "product_color" AS pclr ON pclr.id = pi.color_id
+LEFT JOIN "product_promotion" AS pp ON pp.product_id = p.id
+LEFT JOIN "promotion" AS ppromo ON ppromo.id = pp.promotion_id
+LEFT JOIN "product_category" AS pc ON pc.id = p.category_id
+LEFT JOIN "category_promotion" AS cp ON cp.category_id = p.category_id
+LEFT JOIN "promotion" AS cpromo ON cpromo.id = cp.promotion_id
+LEFT JOIN "product_brand" AS pb ON pb.id = p.brand_id
+LEFT JOIN "brand_promotion" AS bp ON bp.brand_id = p.brand_id
+LEFT JOIN "promotion" AS bpromo ON bpromo.id = bp.promotion_id
+LEFT JOIN (
+    SELECT
+        product_item_id,
+    SUM(qty) AS total_stock
+    FROM
+        product_size
+    GROUP BY
+        product_item_id
+) AS stock ON stock.product_item_id = pi.id
+WHERE
+pi.active = TRUE AND
+p.active = TRUE AND
+(s.tsq IS NULL
+OR p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
+AND (cardinality(sqlc.arg(brand_ids)::BIGINT[]) = 0 OR p.brand_id = ANY(sqlc.arg(brand_ids)::BIGINT[]))
+AND (cardinality(sqlc.arg(category_ids)::BIGINT[]) = 0 OR p.category_id = ANY(sqlc.arg(category_ids)::BIGINT[]))
+AND (cardinality(sqlc.arg(color_ids)::BIGINT[]) = 0 OR pi.color_id = ANY(sqlc.arg(color_ids)::BIGINT[]))
+AND (cardinality(sqlc.arg(sizes)::VARCHAR[]) = 0 OR EXISTS (
+    SELECT 1 FROM "product_size" AS ps
+    WHERE ps.product_item_id = pi.id
+    AND ps.qty > 0
+    AND ps.size_value = ANY(sqlc.arg(sizes)::VARCHAR[])
+))
+AND (sqlc.narg(min_price)::VARCHAR IS NULL OR pi.price::NUMERIC >= (sqlc.narg(min_price)::VARCHAR)::NUMERIC)
+AND (sqlc.narg(max_price)::VARCHAR IS NULL OR pi.price::NUMERIC <= (sqlc.narg(max_price)::VARCHAR)::NUMERIC)
+ORDER BY
+CASE
+    WHEN s.tsq IS NULL THEN 0
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC,
+pi.id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: ListProductItemFacets :many
+-- each facet is counted under every filter but its own, so the other values of a facet stay selectable,
+-- the price facet counts the items per bucket of width_bucket over price_bounds
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ CASE
+    WHEN char_length(trim(sqlc.arg(query)::VARCHAR)) > 0
+    THEN product_search_query(sqlc.arg(query)::VARCHAR)
+ END AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+) AS q
+), item AS (
+SELECT
+ pi.id, p.brand_id, p.category_id, pi.color_id, pi.price::NUMERIC AS price,
+ (cardinality(sqlc.arg(brand_ids)::BIGINT[]) = 0 OR p.brand_id = ANY(sqlc.arg(brand_ids)::BIGINT[])) AS brand_match,
+ (cardinality(sqlc.arg(category_ids)::BIGINT[]) = 0 OR p.category_id = ANY(sqlc.arg(category_ids)::BIGINT[])) AS category_match,
+ (cardinality(sqlc.arg(color_ids)::BIGINT[]) = 0 OR pi.color_id = ANY(sqlc.arg(color_ids)::BIGINT[])) AS color_match,
+ (cardinality(sqlc.arg(sizes)::VARCHAR[]) = 0 OR EXISTS (
+    SELECT 1 FROM "product_size" AS ps
+    WHERE ps.product_item_id = pi.id
+    AND ps.qty > 0
+    AND ps.size_value = ANY(sqlc.arg(sizes)::VARCHAR[])
+ )) AS size_match,
+ ((sqlc.narg(min_price)::VARCHAR IS NULL OR pi.price::NUMERIC >= (sqlc.narg(min_price)::VARCHAR)::NUMERIC)
+ AND (sqlc.narg(max_price)::VARCHAR IS NULL OR pi.price::NUMERIC <= (sqlc.narg(max_price)::VARCHAR)::NUMERIC)) AS price_match
+FROM "product_item" AS pi
+INNER JOIN "product" AS p ON p.id = pi.product_id
+CROSS JOIN search AS s
+LEFT JOIN "product_brand" AS pb ON pb.id = p.brand_id
+LEFT JOIN "product_category" AS pc ON pc.id = p.category_id
+WHERE
+pi.active = TRUE AND
+p.active = TRUE AND
+(s.tsq IS NULL
+OR p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
+)
+SELECT 'brand'::VARCHAR AS facet, pb.id AS value_id, pb.brand_name AS value, COUNT(*) AS item_count
+FROM item
+INNER JOIN "product_brand" AS pb ON pb.id = item.brand_id
+WHERE item.category_match AND item.color_match AND item.size_match AND item.price_match
+GROUP BY pb.id
+UNION ALL
+SELECT 'category'::VARCHAR, pc.id, pc.category_name, COUNT(*)
+FROM item
+INNER JOIN "product_category" AS pc ON pc.id = item.category_id
+WHERE item.brand_match AND item.color_match AND item.size_match AND item.price_match
+GROUP BY pc.id
+UNION ALL
+SELECT 'color'::VARCHAR, pclr.id, pclr.color_value, COUNT(*)
+FROM item
+INNER JOIN "product_color" AS pclr ON pclr.id = item.color_id
+WHERE item.brand_match AND item.category_match AND item.size_match AND item.price_match
+GROUP BY pclr.id
+UNION ALL
+SELECT 'size'::VARCHAR, 0::BIGINT, ps.size_value, COUNT(DISTINCT item.id)
+FROM item
+INNER JOIN "product_size" AS ps ON ps.product_item_id = item.id AND ps.qty > 0
+WHERE item.brand_match AND item.category_match AND item.color_match AND item.price_match
+GROUP BY ps.size_value
+UNION ALL
+SELECT 'price'::VARCHAR, width_bucket(item.price, (sqlc.arg(price_bounds)::VARCHAR[])::NUMERIC[])::BIGINT, ''::VARCHAR, COUNT(*)
+FROM item
+WHERE item.brand_match AND item.category_match AND item.color_match AND item.size_match
+GROUP BY 2
+ORDER BY facet, item_count DESC, value;
@@ -49,6 +49,12 @@ WHERE ps.id = ANY(sqlc.arg(sizes_ids)::bigint[]);
 SELECT * FROM "product_size"
 WHERE product_item_id = $1;
 
+-- name: RestockProductSize :one
+UPDATE "product_size"
+SET qty = qty + sqlc.arg(qty)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: UpdateProductSize :one
 UPDATE "product_size"
 SET 
@@ -11,15 +11,6 @@ INSERT INTO "reset_passwords" (
 SELECT * FROM "reset_passwords"
 WHERE id = $1 LIMIT 1;
 
--- name: GetResetPasswordUserIDByID :one
-SELECT user_id FROM "reset_passwords"
-WHERE
-    id = @id
-    AND secret_code = @secret_code
-    AND is_used = FALSE
-    AND expired_at > now()
-LIMIT 1;
-
 -- name: GetResetPasswordsByEmail :one
 SELECT u.email, u.username, u.is_blocked AS is_blocked_user, u.is_email_verified, 
 rp.* FROM "reset_passwords" AS rp
@@ -34,7 +25,17 @@ SET
     is_used = TRUE
 WHERE
     id = @id
-    AND secret_code = @secret_code
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING *;
+
+-- name: IncrementResetPasswordAttempts :one
+UPDATE "reset_passwords"
+SET
+    attempts = attempts + 1,
+    expired_at = CASE WHEN attempts + 1 >= @max_attempts::int THEN now() ELSE expired_at END
+WHERE
+    id = @id
     AND is_used = FALSE
     AND expired_at > now()
 RETURNING *;
@@ -47,4 +48,8 @@ WHERE u.email = $1
 AND is_used = TRUE
 AND expired_at > now()
 ORDER BY rp.updated_at DESC, rp.created_at DESC
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: DeleteResetPasswordsByUserID :exec
+DELETE FROM "reset_passwords"
+WHERE user_id = $1;
@@ -0,0 +1,63 @@
+-- name: CreateReturnRequest :one
+INSERT INTO "return_request" (
+  user_id,
+  shop_order_id,
+  reason
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetReturnRequestByUserID :one
+SELECT * FROM "return_request"
+WHERE id = $1
+AND user_id = $2
+LIMIT 1;
+
+-- name: GetReturnRequestForUpdate :one
+SELECT * FROM "return_request"
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListReturnRequestsByUserID :many
+SELECT * FROM "return_request"
+WHERE user_id = $3
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: AdminListReturnRequests :many
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+SELECT * FROM "return_request"
+WHERE (SELECT is_admin FROM t1) = 1
+AND CASE
+WHEN COALESCE(sqlc.narg(status)::VARCHAR, '') != ''
+THEN status = sqlc.narg(status)
+    ELSE 1=1
+END
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: AdminUpdateReturnRequest :one
+With t1 AS (
+SELECT 1 AS is_admin, "admin".id AS admin_id
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+UPDATE "return_request"
+SET
+status = sqlc.arg(status),
+admin_id = (SELECT admin_id FROM t1),
+admin_note = COALESCE(sqlc.narg(admin_note),admin_note),
+refund_amount = COALESCE(sqlc.narg(refund_amount),refund_amount),
+updated_at = now()
+WHERE "return_request".id = sqlc.arg(id)
+AND (SELECT is_admin FROM t1) = 1
+RETURNING *;
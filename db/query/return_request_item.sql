@@ -0,0 +1,24 @@
+-- name: CreateReturnRequestItem :one
+INSERT INTO "return_request_item" (
+  return_request_id,
+  shop_order_item_id,
+  qty
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListReturnRequestItemsByRequestID :many
+SELECT rri.*, soi.price, soi.discount, soi.size_id, soi.size_value,
+soi.color_value, soi.product_name, soi.coupon_discount, soi.quantity
+FROM "return_request_item" AS rri
+JOIN "shop_order_item" AS soi ON soi.id = rri.shop_order_item_id
+WHERE rri.return_request_id = $1
+ORDER BY rri.id;
+
+-- name: GetReturnedQtyByShopOrderItemID :one
+SELECT COALESCE(SUM(rri.qty), 0)::bigint AS returned_qty
+FROM "return_request_item" AS rri
+JOIN "return_request" AS rr ON rr.id = rri.return_request_id
+WHERE rri.shop_order_item_id = $1
+AND rr.status != 'rejected';
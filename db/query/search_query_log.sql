@@ -0,0 +1,113 @@
+-- name: CreateSearchQueryLog :exec
+INSERT INTO "search_query_log" (
+  query,
+  normalized_query,
+  result_count
+) VALUES (
+  $1, normalize_search_text($1), $2
+);
+
+-- name: ListSearchSuggestions :many
+-- the product names match the words being typed as prefixes, the brands and categories match the query
+-- anywhere in their name or by trigram similarity, the past queries starting with the query that found results
+-- in the last 30 days at least 3 times are ranked by how often they were searched
+WITH search AS (
+SELECT
+ product_search_query(sqlc.arg(query)::VARCHAR) AS tsq,
+ normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+)
+(SELECT 'product'::VARCHAR AS kind, p.id AS value_id, p.name AS value, ts_rank(p.search, s.tsq)::FLOAT8 AS rank
+FROM "product" AS p
+CROSS JOIN search AS s
+WHERE p.active = TRUE
+AND p.search @@ s.tsq
+ORDER BY rank DESC, p.id DESC
+LIMIT sqlc.arg(kind_limit)::INT)
+UNION ALL
+(SELECT 'brand'::VARCHAR, MIN(pb.id)::BIGINT, pb.brand_name, MAX(word_similarity(s.query_text, normalize_search_text(pb.brand_name)))::FLOAT8
+FROM "product_brand" AS pb
+CROSS JOIN search AS s
+WHERE normalize_search_text(pb.brand_name) LIKE '%' || s.query_text || '%'
+OR s.query_text <% normalize_search_text(pb.brand_name)
+GROUP BY pb.brand_name
+ORDER BY 4 DESC, 3
+LIMIT sqlc.arg(kind_limit)::INT)
+UNION ALL
+(SELECT 'category'::VARCHAR, MIN(pc.id)::BIGINT, pc.category_name, MAX(word_similarity(s.query_text, normalize_search_text(pc.category_name)))::FLOAT8
+FROM "product_category" AS pc
+CROSS JOIN search AS s
+WHERE normalize_search_text(pc.category_name) LIKE '%' || s.query_text || '%'
+OR s.query_text <% normalize_search_text(pc.category_name)
+GROUP BY pc.category_name
+ORDER BY 4 DESC, 3
+LIMIT sqlc.arg(kind_limit)::INT)
+UNION ALL
+(SELECT 'query'::VARCHAR, 0::BIGINT, l.normalized_query, COUNT(*)::FLOAT8
+FROM "search_query_log" AS l
+CROSS JOIN search AS s
+WHERE l.normalized_query LIKE s.query_text || '%'
+AND l.normalized_query <> s.query_text
+AND l.result_count > 0
+AND l.created_at > now() - interval '30 days'
+GROUP BY l.normalized_query
+HAVING COUNT(*) >= 3
+ORDER BY 4 DESC, 3
+LIMIT sqlc.arg(kind_limit)::INT)
+ORDER BY kind, rank DESC, value;
+
+-- name: GetSearchCorrection :one
+-- the product name, brand, category or past query that found results in at least 3 searches most similar to the query,
+-- pgx.ErrNoRows when none is similar enough
+WITH search AS (
+SELECT normalize_search_text(sqlc.arg(query)::VARCHAR) AS query_text
+), candidate AS (
+SELECT normalize_search_text(p.name) AS value
+FROM "product" AS p
+CROSS JOIN search AS s
+WHERE p.active = TRUE
+AND normalize_search_text(p.name) % s.query_text
+UNION
+SELECT normalize_search_text(pb.brand_name)
+FROM "product_brand" AS pb
+CROSS JOIN search AS s
+WHERE normalize_search_text(pb.brand_name) % s.query_text
+UNION
+SELECT normalize_search_text(pc.category_name)
+FROM "product_category" AS pc
+CROSS JOIN search AS s
+WHERE normalize_search_text(pc.category_name) % s.query_text
+UNION
+SELECT l.normalized_query
+FROM "search_query_log" AS l
+CROSS JOIN search AS s
+WHERE l.result_count > 0
+AND l.normalized_query % s.query_text
+GROUP BY l.normalized_query
+HAVING COUNT(*) >= 3
+)
+SELECT c.value::VARCHAR AS suggestion
+FROM candidate AS c
+CROSS JOIN search AS s
+WHERE c.value <> s.query_text
+ORDER BY similarity(c.value, s.query_text) DESC, c.value
+LIMIT 1;
+
+-- name: ListSearchQueryStats :many
+-- the most searched queries since the given time, with zero_results only the queries that never found anything
+SELECT
+ normalized_query AS query,
+ COUNT(*) AS search_count,
+ COUNT(*) FILTER (WHERE result_count = 0) AS zero_result_count,
+ MAX(created_at)::timestamptz AS last_searched_at
+FROM "search_query_log"
+WHERE created_at >= sqlc.arg(since)
+GROUP BY normalized_query
+HAVING NOT sqlc.arg(zero_results)::BOOLEAN OR MAX(result_count) = 0
+ORDER BY search_count DESC, query
+LIMIT $1
+OFFSET $2;
+
+-- name: DeleteOldSearchQueryLogs :execrows
+-- the searches older than 90 days, the suggestions only look back 30 days
+DELETE FROM "search_query_log"
+WHERE created_at < now() - interval '90 days';
@@ -27,6 +27,26 @@ RETURNING *;
 SELECT * FROM "shop_order"
 WHERE id = $1 LIMIT 1;
 
+-- name: ShopOrderTrackNumberExists :one
+SELECT EXISTS (
+  SELECT 1 FROM "shop_order"
+  WHERE track_number = $1
+);
+
+-- name: GetShopOrderForUpdate :one
+SELECT * FROM "shop_order"
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateShopOrderStatusByUserID :one
+UPDATE "shop_order"
+SET
+order_status_id = sqlc.arg(order_status_id),
+updated_at = now()
+WHERE id = sqlc.arg(id)
+AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
 -- name: ListShopOrders :many
 SELECT * FROM "shop_order"
 ORDER BY id
@@ -104,7 +124,8 @@ shipping_method_id = COALESCE(sqlc.narg(shipping_method_id),shipping_method_id),
 order_status_id = COALESCE(sqlc.narg(order_status_id),order_status_id),
 updated_at = NOW(),
 completed_at = CASE
-    WHEN order_status_id != 2 AND sqlc.narg(order_status_id) =2
+    WHEN order_status_id IS DISTINCT FROM sqlc.narg(order_status_id)
+    AND sqlc.narg(order_status_id) = (SELECT id FROM "order_status" WHERE code = 'delivered')
     THEN NOW()
     ELSE completed_at
 END
@@ -127,6 +148,18 @@ SELECT COUNT(*) AS orders_count FROM shop_order
 WHERE order_status_id = sqlc.arg(order_status_id)
 AND EXISTS(SELECT is_admin FROM t1);
 
+-- name: GetShopOrdersCountByStatusCodes :one
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = sqlc.arg(admin_id)
+    AND active = TRUE
+    )
+SELECT COUNT(*) AS orders_count FROM shop_order AS so
+LEFT JOIN "order_status" AS os ON os.id = so.order_status_id
+WHERE os.code = ANY(sqlc.arg(codes)::varchar[])
+AND EXISTS(SELECT is_admin FROM t1);
+
 -- name: GetTotalShopOrder :one
 With t1 AS (
 SELECT 1 AS is_admin
@@ -148,9 +181,9 @@ SELECT
     COALESCE(SUM(CAST(order_total AS NUMERIC)),'0')::VARCHAR AS daily_revenue
 FROM
     shop_order
-WHERE order_status_id = 2
-AND updated_at >= CURRENT_DATE
-AND updated_at < CURRENT_DATE + INTERVAL '1 day'
+WHERE order_status_id = (SELECT id FROM "order_status" WHERE code = 'delivered')
+AND completed_at >= CURRENT_DATE
+AND completed_at < CURRENT_DATE + INTERVAL '1 day'
 AND EXISTS(SELECT is_admin FROM t1);
 
 -- name: AdminListShopOrdersV2 :many
@@ -222,4 +255,22 @@ ORDER BY so.id DESC
 LIMIT $1 + 1
 )
 SELECT *,COUNT(*) OVER()>10 AS next_available FROM t2
-LIMIT $1;
\ No newline at end of file
+LIMIT $1;
+
+-- name: RedactShopOrderAddresses :execrows
+-- the orders are kept for accounting without the address of the user
+UPDATE "shop_order"
+SET
+shipping_address_id = NULL,
+address_name = 'redacted',
+address_telephone = 'redacted',
+address_line = 'redacted',
+address_region = 'redacted',
+address_city = 'redacted',
+updated_at = now()
+WHERE user_id = $1;
+
+-- name: ListShopOrdersForExport :many
+SELECT * FROM "shop_order"
+WHERE user_id = $1
+ORDER BY id;
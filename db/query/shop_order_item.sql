@@ -5,9 +5,14 @@ INSERT INTO "shop_order_item" (
   quantity,
   price,
   discount,
-  shipping_method_price
+  shipping_method_price,
+  size_id,
+  size_value,
+  color_value,
+  product_name,
+  coupon_discount
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 )
 RETURNING *;
 
@@ -33,10 +38,10 @@ OFFSET $2;
 -- SELECT * FROM "shop_order_item"
 -- WHERE order_id = $1
 -- ORDER BY id;
-SELECT os.status, so.track_number, soi.shipping_method_price AS delivery_price, so.order_total, soi.*, p.name AS product_name, pt.value as payment_type,
+SELECT os.status, so.track_number, soi.shipping_method_price AS delivery_price, so.order_total, soi.*, pt.value as payment_type,
 -- pi.product_image, 
 pimg.product_image_1 AS product_image,
-pcolor.color_value AS product_color, psize.size_value AS product_size,
+COALESCE(soi.color_value, pcolor.color_value) AS product_color, COALESCE(soi.size_value, psize.size_value) AS product_size,
 pi.active AS product_active, a.address_line, a.region, a.city,
 DENSE_RANK() OVER(ORDER BY so.id) as order_number
 -- , pt.value AS payment_type 
@@ -44,10 +49,9 @@ FROM "shop_order_item" AS soi
 LEFT JOIN "shop_order" AS so ON so.id = soi.order_id
 LEFT JOIN "product_item" AS pi ON pi.id = soi.product_item_id
 LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
-LEFT JOIN "product_size" AS psize ON psize.product_item_id = pi.id
+LEFT JOIN "product_size" AS psize ON psize.id = soi.size_id
 LEFT JOIN "product_color" AS pcolor ON pcolor.id = pi.color_id
 LEFT JOIN "order_status" AS os ON os.id = so.order_status_id
-LEFT JOIN "product" AS p ON p.id = pi.product_id
 LEFT JOIN "address" AS a ON a.id = so.shipping_address_id
 -- LEFT JOIN "payment_method" AS pm ON pm.id = so.payment_method_id
 LEFT JOIN "payment_type" AS pt ON pt.id = so.payment_type_id
@@ -65,10 +69,23 @@ ORDER BY so.id
 LIMIT $1
 OFFSET $2;
 
--- -- name: ListShopOrderItemsByOrderID :many
--- SELECT * FROM "shop_order_item"
--- WHERE order_id = $1
--- ORDER BY id;
+-- name: ListShopOrderItemsByOrderID :many
+SELECT * FROM "shop_order_item"
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: ListShopOrderItemsToRestock :many
+-- the qty of the order items that isn't back in stock yet, the items of the received return requests already are
+SELECT soi.id, soi.size_id, (soi.quantity - COALESCE((
+    SELECT SUM(rri.qty)
+    FROM "return_request_item" AS rri
+    JOIN "return_request" AS rr ON rr.id = rri.return_request_id
+    WHERE rri.shop_order_item_id = soi.id
+    AND rr.status IN ('received', 'refunded')
+), 0))::INT AS qty
+FROM "shop_order_item" AS soi
+WHERE soi.order_id = $1
+ORDER BY soi.id;
 
 -- name: UpdateShopOrderItem :one
 UPDATE "shop_order_item"
@@ -93,4 +110,10 @@ SELECT 1 AS is_admin
 DELETE FROM "shop_order_item"
 WHERE "shop_order_item".id = $1
 AND (SELECT is_admin FROM t1) = 1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ListShopOrderItemsForExport :many
+SELECT soi.* FROM "shop_order_item" AS soi
+JOIN "shop_order" AS so ON so.id = soi.order_id
+WHERE so.user_id = $1
+ORDER BY soi.order_id, soi.id;
@@ -0,0 +1,22 @@
+-- name: CreateShopOrderStatusHistory :one
+INSERT INTO "shop_order_status_history" (
+  shop_order_id,
+  from_status_id,
+  to_status_id,
+  admin_id,
+  note
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListShopOrderStatusHistoryByUserIDOrderID :many
+SELECT sh.id, sh.shop_order_id, fos.status AS from_status, tos.status AS to_status,
+tos.code AS to_status_code, sh.note, sh.created_at
+FROM "shop_order_status_history" AS sh
+LEFT JOIN "shop_order" AS so ON so.id = sh.shop_order_id
+LEFT JOIN "order_status" AS fos ON fos.id = sh.from_status_id
+LEFT JOIN "order_status" AS tos ON tos.id = sh.to_status_id
+WHERE so.user_id = $1
+AND sh.shop_order_id = $2
+ORDER BY sh.id;
@@ -0,0 +1,31 @@
+-- name: CreateStockReservation :one
+INSERT INTO "stock_reservation" (
+  shopping_cart_id,
+  product_item_id,
+  size_id,
+  qty,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListStockReservationsByCartID :many
+SELECT * FROM "stock_reservation"
+WHERE shopping_cart_id = $1
+AND expires_at > now()
+ORDER BY id;
+
+-- name: GetReservedQtyBySizeIDForOtherCarts :one
+SELECT COALESCE(SUM(qty),0)::bigint AS reserved_qty FROM "stock_reservation"
+WHERE size_id = $1
+AND shopping_cart_id <> $2
+AND expires_at > now();
+
+-- name: DeleteStockReservationsByCartID :execrows
+DELETE FROM "stock_reservation"
+WHERE shopping_cart_id = $1;
+
+-- name: DeleteExpiredStockReservations :execrows
+DELETE FROM "stock_reservation"
+WHERE expires_at <= now();
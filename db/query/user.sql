@@ -40,6 +40,34 @@ t3 AS(
 
 SELECT t1.*, t2.id AS shopping_cart_id, t3.id AS wish_list_id FROM t1, t2, t3;
 
+-- name: CreateVerifiedUserWithCartAndWishList :one
+-- the email of a social login is verified by its provider
+WITH t1 AS(
+INSERT INTO "user" (
+  username,
+  email,
+  password,
+  is_email_verified
+) VALUES (
+  $1, $2, $3, TRUE
+)
+RETURNING *
+),
+t2 AS(
+  INSERT INTO "shopping_cart" (
+  user_id
+) VALUES ((Select id from t1))
+  RETURNING id
+),
+t3 AS(
+  INSERT INTO "wish_list" (
+    user_id
+) VALUES ((Select id from t1))
+  RETURNING id
+)
+
+SELECT t1.*, t2.id AS shopping_cart_id, t3.id AS wish_list_id FROM t1, t2, t3;
+
 -- name: GetUser :one
 SELECT * FROM "user"
 WHERE id = $1 LIMIT 1;
@@ -102,6 +130,40 @@ UPDATE "user"
 SET is_email_verified = TRUE
 WHERE id = sqlc.arg(id);
 
+-- name: VerifyUserEmailForSocialLogin :one
+-- the password set before the email was verified might not be the owner's, so it's replaced
+UPDATE "user"
+SET
+password = CASE WHEN is_email_verified THEN password ELSE sqlc.arg(password) END,
+is_email_verified = TRUE,
+updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE "user"
+SET
+email = sqlc.arg(email),
+is_email_verified = TRUE,
+updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AnonymizeUser :one
+-- the row is kept for the orders of the user, the email is freed for a new account
+UPDATE "user"
+SET
+username = 'deleted user',
+email = 'deleted-' || id || '@deleted.invalid',
+password = '',
+default_payment = NULL,
+default_address_id = NULL,
+is_blocked = TRUE,
+is_email_verified = FALSE,
+updated_at = now()
+WHERE id = $1
+RETURNING *;
+
 -- name: DeleteUser :one
 DELETE FROM "user"
 WHERE id = $1
@@ -0,0 +1,40 @@
+-- name: UpsertUserDeletion :one
+-- a new request of a pending deletion restarts its grace period
+INSERT INTO "user_deletion" (
+  user_id,
+  delete_after
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  requested_at = now(),
+  delete_after = EXCLUDED.delete_after
+WHERE "user_deletion".anonymized_at = '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: GetUserDeletion :one
+SELECT * FROM "user_deletion"
+WHERE user_id = $1 LIMIT 1;
+
+-- name: CancelUserDeletion :one
+DELETE FROM "user_deletion"
+WHERE user_id = $1
+AND anonymized_at = '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: ListDueUserDeletions :many
+SELECT user_id FROM "user_deletion"
+WHERE anonymized_at = '0001-01-01 00:00:00Z'
+AND delete_after <= now()
+ORDER BY delete_after
+LIMIT $1;
+
+-- name: MarkUserDeletionAnonymized :one
+UPDATE "user_deletion"
+SET
+  anonymized_at = now()
+WHERE user_id = $1
+AND anonymized_at = '0001-01-01 00:00:00Z'
+AND delete_after <= now()
+RETURNING *;
@@ -2,9 +2,11 @@
 INSERT INTO "user_review" (
   user_id,
   ordered_product_id,
-  rating_value
+  rating_value,
+  title,
+  body
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -22,10 +24,18 @@ LIMIT $1
 OFFSET $2;
 
 -- name: UpdateUserReview :one
+-- an approved review goes back to the moderation when its text changes
 UPDATE "user_review"
 SET 
 ordered_product_id = COALESCE(sqlc.narg(ordered_product_id),ordered_product_id),
 rating_value = COALESCE(sqlc.narg(rating_value),rating_value),
+title = COALESCE(sqlc.narg(title),title),
+body = COALESCE(sqlc.narg(body),body),
+status = CASE
+    WHEN status = 'approved' AND (sqlc.narg(title) IS NOT NULL OR sqlc.narg(body) IS NOT NULL)
+    THEN 'pending'
+    ELSE status
+END,
 updated_at = now()
 WHERE id = sqlc.arg(id)
 AND user_id = sqlc.arg(user_id)
@@ -35,4 +45,106 @@ RETURNING *;
 DELETE FROM "user_review"
 WHERE id = $1
 And user_id =$2
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ListUserReviewsForExport :many
+SELECT * FROM "user_review"
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: ListProductReviews :many
+-- the approved reviews of a product, a review is a verified purchase when its ordered product was delivered to the reviewer
+SELECT
+ ur.id, ur.product_id, ur.rating_value, ur.title, ur.body, ur.admin_reply, ur.replied_at, ur.created_at, ur.updated_at,
+ u.username,
+ EXISTS (
+    SELECT 1 FROM "shop_order_item" AS soi
+    INNER JOIN "shop_order" AS so ON so.id = soi.order_id
+    WHERE soi.id = ur.ordered_product_id
+    AND so.user_id = ur.user_id
+    AND so.order_status_id = (SELECT id FROM "order_status" WHERE code = 'delivered')
+ ) AS verified_purchase,
+ ARRAY(
+    SELECT img.image_url FROM "user_review_image" AS img
+    WHERE img.review_id = ur.id
+    ORDER BY img.id
+ )::VARCHAR[] AS images,
+ COUNT(*) OVER() AS total_count
+FROM "user_review" AS ur
+INNER JOIN "user" AS u ON u.id = ur.user_id
+WHERE ur.product_id = sqlc.arg(product_id)
+AND ur.status = 'approved'
+ORDER BY
+CASE
+    WHEN sqlc.arg(sort)::VARCHAR = 'highest_rating'
+    THEN ur.rating_value END DESC,
+CASE
+    WHEN sqlc.arg(sort)::VARCHAR = 'lowest_rating'
+    THEN ur.rating_value END ASC,
+CASE
+    WHEN sqlc.arg(sort)::VARCHAR = 'oldest'
+    THEN ur.id END ASC,
+ur.id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: ListUserReviewsForModeration :many
+-- the oldest reviews come first so the queue is worked through in order
+SELECT
+ ur.*, p.name AS product_name, u.username,
+ ARRAY(
+    SELECT img.image_url FROM "user_review_image" AS img
+    WHERE img.review_id = ur.id
+    ORDER BY img.id
+ )::VARCHAR[] AS images,
+ COUNT(*) OVER() AS total_count
+FROM "user_review" AS ur
+INNER JOIN "product" AS p ON p.id = ur.product_id
+INNER JOIN "user" AS u ON u.id = ur.user_id
+WHERE ur.status = sqlc.arg(status)
+ORDER BY ur.created_at, ur.id
+LIMIT $1
+OFFSET $2;
+
+-- name: ModerateUserReview :one
+-- an empty reply removes the reply of the review
+UPDATE "user_review"
+SET
+status = COALESCE(sqlc.narg(status), status),
+moderated_at = CASE
+    WHEN sqlc.narg(status) IS NULL THEN moderated_at
+    ELSE now()
+END,
+admin_reply = CASE
+    WHEN sqlc.narg(admin_reply)::VARCHAR IS NULL THEN admin_reply
+    ELSE NULLIF(sqlc.narg(admin_reply)::VARCHAR, '')
+END,
+replied_at = CASE
+    WHEN sqlc.narg(admin_reply)::VARCHAR IS NULL THEN replied_at
+    WHEN sqlc.narg(admin_reply)::VARCHAR = '' THEN NULL
+    ELSE now()
+END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountUserReviewImages :one
+SELECT COUNT(*) FROM "user_review_image"
+WHERE review_id = $1;
+
+-- name: CreateUserReviewImage :one
+-- a new image sends an approved review back to the moderation
+WITH review AS (
+UPDATE "user_review"
+SET
+status = CASE WHEN status = 'approved' THEN 'pending' ELSE status END,
+updated_at = now()
+WHERE id = sqlc.arg(review_id)
+AND user_id = sqlc.arg(user_id)
+RETURNING id
+)
+INSERT INTO "user_review_image" (
+  review_id,
+  image_url
+)
+SELECT id, sqlc.arg(image_url)::VARCHAR FROM review
+RETURNING *;
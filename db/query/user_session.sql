@@ -6,16 +6,78 @@ INSERT INTO "user_session" (
   user_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
 RETURNING *;
 
+-- name: DeleteUserSessions :execrows
+DELETE FROM "user_session"
+WHERE user_id = $1;
+
 -- name: GetUserSession :one
 SELECT * FROM "user_session"
 WHERE id = $1 LIMIT 1;
 
+-- name: IsUserSessionActive :one
+SELECT EXISTS (
+  SELECT 1 FROM "user_session"
+  WHERE id = $1
+  AND user_id = $2
+  AND is_blocked = false
+  AND expires_at > now()
+);
+
+-- name: ListActiveUserSessions :many
+SELECT * FROM "user_session"
+WHERE user_id = $1
+AND is_blocked = false
+AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: ListUserSessionsForExport :many
+SELECT * FROM "user_session"
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: RevokeUserSessionFamily :execrows
+UPDATE "user_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE family_id = $1
+AND is_blocked = false;
+
+-- name: RevokeUserSessions :execrows
+UPDATE "user_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE user_id = $1
+AND is_blocked = false;
+
+-- name: RevokeOtherUserSessions :execrows
+UPDATE "user_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE user_id = sqlc.arg(user_id)
+AND id != sqlc.arg(current_session_id)
+AND is_blocked = false;
+
+-- name: RotateUserSession :one
+UPDATE "user_session"
+SET
+replaced_by = sqlc.arg(replaced_by)::uuid,
+is_blocked = true,
+updated_at = now()
+WHERE id = sqlc.arg(id)
+AND replaced_by IS NULL
+AND is_blocked = false
+RETURNING *;
+
 -- name: UpdateUserSession :one
 UPDATE "user_session"
 SET 
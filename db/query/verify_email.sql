@@ -28,8 +28,9 @@ AND u.is_email_verified = FALSE
 t2 AS (
 UPDATE "verify_email" 
 SET is_used = TRUE
-WHERE secret_code = sqlc.arg(secret_code)
+WHERE id = sqlc.arg(id)
 AND user_id = (SELECT id FROM t1)
+AND is_used = FALSE
 AND expired_at > NOW()
 RETURNING *
 ),
@@ -52,4 +53,24 @@ t5 AS(
   RETURNING id
 )
 
-SELECT t3.*, t4.id AS shopping_cart_id, t5.id AS wish_list_id FROM t3,t4,t5;
\ No newline at end of file
+SELECT t3.*, t4.id AS shopping_cart_id, t5.id AS wish_list_id FROM t3,t4,t5;
+
+-- name: IncrementVerifyEmailAttempts :one
+UPDATE "verify_email"
+SET
+    attempts = attempts + 1,
+    expired_at = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN NOW() ELSE expired_at END
+WHERE id = (
+    SELECT ve.id FROM "verify_email" AS ve
+    JOIN "user" AS u ON u.id = ve.user_id
+    WHERE u.email = sqlc.arg(email)
+    AND ve.is_used = FALSE
+    AND ve.expired_at > NOW()
+    ORDER BY ve.created_at DESC
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: DeleteVerifyEmailsByUserID :exec
+DELETE FROM "verify_email"
+WHERE user_id = $1;
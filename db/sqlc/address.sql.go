@@ -69,6 +69,19 @@ func (q *Queries) DeleteAddress(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteAddressesByUserID = `-- name: DeleteAddressesByUserID :execrows
+DELETE FROM "address"
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteAddressesByUserID(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAddressesByUserID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteUserAddress = `-- name: DeleteUserAddress :one
 DELETE FROM "address" AS ad
 WHERE user_id = $1
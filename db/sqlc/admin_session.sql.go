@@ -21,11 +21,12 @@ INSERT INTO "admin_session" (
   admin_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked
+RETURNING id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by
 `
 
 type CreateAdminSessionParams struct {
@@ -36,6 +37,7 @@ type CreateAdminSessionParams struct {
 	ClientIp     string    `json:"client_ip"`
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	FamilyID     uuid.UUID `json:"family_id"`
 }
 
 func (q *Queries) CreateAdminSession(ctx context.Context, arg CreateAdminSessionParams) (*AdminSession, error) {
@@ -47,6 +49,7 @@ func (q *Queries) CreateAdminSession(ctx context.Context, arg CreateAdminSession
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.FamilyID,
 	)
 	var i AdminSession
 	err := row.Scan(
@@ -59,12 +62,14 @@ func (q *Queries) CreateAdminSession(ctx context.Context, arg CreateAdminSession
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return &i, err
 }
 
 const getAdminSession = `-- name: GetAdminSession :one
-SELECT id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked FROM "admin_session"
+SELECT id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by FROM "admin_session"
 WHERE id = $1 LIMIT 1
 `
 
@@ -81,6 +86,140 @@ func (q *Queries) GetAdminSession(ctx context.Context, id uuid.UUID) (*AdminSess
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
+	)
+	return &i, err
+}
+
+const isAdminSessionActive = `-- name: IsAdminSessionActive :one
+SELECT EXISTS (
+  SELECT 1 FROM "admin_session"
+  WHERE id = $1
+  AND admin_id = $2
+  AND is_blocked = false
+  AND expires_at > now()
+)
+`
+
+type IsAdminSessionActiveParams struct {
+	ID      uuid.UUID `json:"id"`
+	AdminID int64     `json:"admin_id"`
+}
+
+func (q *Queries) IsAdminSessionActive(ctx context.Context, arg IsAdminSessionActiveParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isAdminSessionActive, arg.ID, arg.AdminID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listActiveAdminSessions = `-- name: ListActiveAdminSessions :many
+SELECT id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by FROM "admin_session"
+WHERE admin_id = $1
+AND is_blocked = false
+AND expires_at > now()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListActiveAdminSessions(ctx context.Context, adminID int64) ([]*AdminSession, error) {
+	rows, err := q.db.Query(ctx, listActiveAdminSessions, adminID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*AdminSession{}
+	for rows.Next() {
+		var i AdminSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.AdminID,
+			&i.RefreshToken,
+			&i.AdminAgent,
+			&i.ClientIp,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.IsBlocked,
+			&i.FamilyID,
+			&i.ReplacedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAdminSessionFamily = `-- name: RevokeAdminSessionFamily :execrows
+UPDATE "admin_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE family_id = $1
+AND is_blocked = false
+`
+
+func (q *Queries) RevokeAdminSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAdminSessionFamily, familyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeAdminSessions = `-- name: RevokeAdminSessions :execrows
+UPDATE "admin_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE admin_id = $1
+AND is_blocked = false
+`
+
+func (q *Queries) RevokeAdminSessions(ctx context.Context, adminID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAdminSessions, adminID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotateAdminSession = `-- name: RotateAdminSession :one
+UPDATE "admin_session"
+SET
+replaced_by = $1::uuid,
+is_blocked = true,
+updated_at = now()
+WHERE id = $2
+AND replaced_by IS NULL
+AND is_blocked = false
+RETURNING id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by
+`
+
+type RotateAdminSessionParams struct {
+	ReplacedBy uuid.UUID `json:"replaced_by"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (q *Queries) RotateAdminSession(ctx context.Context, arg RotateAdminSessionParams) (*AdminSession, error) {
+	row := q.db.QueryRow(ctx, rotateAdminSession, arg.ReplacedBy, arg.ID)
+	var i AdminSession
+	err := row.Scan(
+		&i.ID,
+		&i.AdminID,
+		&i.RefreshToken,
+		&i.AdminAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return &i, err
 }
@@ -93,7 +232,7 @@ updated_at = now()
 WHERE id = $2
 AND admin_id = $3
 AND refresh_token = $4
-RETURNING id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked
+RETURNING id, admin_id, refresh_token, admin_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by
 `
 
 type UpdateAdminSessionParams struct {
@@ -121,6 +260,8 @@ func (q *Queries) UpdateAdminSession(ctx context.Context, arg UpdateAdminSession
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return &i, err
 }
@@ -23,6 +23,7 @@ func createRandomAdminSession(t *testing.T) AdminSession {
 		IsBlocked:    false,
 		ExpiresAt:    time.Now().Local().UTC(),
 	}
+	arg.FamilyID = arg.ID
 
 	adminSession, err := testStore.CreateAdminSession(context.Background(), arg)
 	require.NoError(t, err)
@@ -35,6 +36,8 @@ func createRandomAdminSession(t *testing.T) AdminSession {
 	require.Equal(t, arg.ClientIp, adminSession.ClientIp)
 	require.Equal(t, arg.IsBlocked, adminSession.IsBlocked)
 	require.WithinDuration(t, arg.ExpiresAt, adminSession.ExpiresAt, time.Second)
+	require.Equal(t, arg.FamilyID, adminSession.FamilyID)
+	require.False(t, adminSession.ReplacedBy.Valid)
 
 	require.NotEmpty(t, adminSession.CreatedAt)
 
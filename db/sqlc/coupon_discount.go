@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/quagmt/udecimal"
+)
+
+const (
+	CouponDiscountPercentage = "percentage"
+	CouponDiscountFixed      = "fixed"
+)
+
+// CouponNotApplicableError is returned when a coupon code can't be redeemed on the order
+type CouponNotApplicableError struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+func (e *CouponNotApplicableError) Error() string {
+	return fmt.Sprintf("coupon %s is not applicable: %s", e.Code, e.Reason)
+}
+
+// NormalizeCouponCode returns the code the way it is stored in the coupon table
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// getRedeemableCoupon locks the coupon row and checks its window and usage limits for the user
+func getRedeemableCoupon(ctx context.Context, q *Queries, code string, userID int64) (*Coupon, error) {
+	coupon, err := q.GetCouponByCodeForUpdate(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &CouponNotApplicableError{Code: code, Reason: "not found"}
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !coupon.Active || now.Before(coupon.StartDate) || now.After(coupon.EndDate) {
+		return nil, &CouponNotApplicableError{Code: code, Reason: "expired or inactive"}
+	}
+
+	if coupon.MaxUses.Valid && coupon.UsedCount >= coupon.MaxUses.Int64 {
+		return nil, &CouponNotApplicableError{Code: code, Reason: "usage limit reached"}
+	}
+
+	if coupon.MaxUsesPerUser.Valid {
+		userRedemptions, err := q.CountCouponRedemptionsByUser(ctx, CountCouponRedemptionsByUserParams{
+			CouponID: coupon.ID,
+			UserID:   userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if userRedemptions >= coupon.MaxUsesPerUser.Int64 {
+			return nil, &CouponNotApplicableError{Code: code, Reason: "usage limit per user reached"}
+		}
+	}
+
+	return coupon, nil
+}
+
+// couponIsScoped reports whether the coupon only applies to a category, brand or product
+func couponIsScoped(coupon *Coupon) bool {
+	return coupon.CategoryID.Valid || coupon.BrandID.Valid || coupon.ProductID.Valid
+}
+
+// couponCoversProduct reports whether the product matches every scope set on the coupon
+func couponCoversProduct(coupon *Coupon, product *Product) bool {
+	if coupon.CategoryID.Valid && coupon.CategoryID.Int64 != product.CategoryID {
+		return false
+	}
+	if coupon.BrandID.Valid && coupon.BrandID.Int64 != product.BrandID {
+		return false
+	}
+	if coupon.ProductID.Valid && coupon.ProductID.Int64 != product.ID {
+		return false
+	}
+	return true
+}
+
+// couponDiscount returns the amount taken off the eligible lines total,
+// it never goes above the eligible total
+func couponDiscount(coupon *Coupon, subtotal, eligibleTotal udecimal.Decimal) (udecimal.Decimal, error) {
+	minOrderValue, err := udecimal.Parse(coupon.MinOrderValue)
+	if err != nil {
+		return udecimal.Zero, err
+	}
+
+	if subtotal.LessThan(minOrderValue) {
+		return udecimal.Zero, &CouponNotApplicableError{Code: coupon.Code, Reason: "order is below the minimum value"}
+	}
+
+	if !eligibleTotal.IsPos() {
+		return udecimal.Zero, &CouponNotApplicableError{Code: coupon.Code, Reason: "no eligible items"}
+	}
+
+	value, err := udecimal.Parse(coupon.DiscountValue)
+	if err != nil {
+		return udecimal.Zero, err
+	}
+
+	var amount udecimal.Decimal
+	switch coupon.DiscountType {
+	case CouponDiscountPercentage:
+		amount, err = eligibleTotal.Mul(value).Div64(100)
+		if err != nil {
+			return udecimal.Zero, err
+		}
+	case CouponDiscountFixed:
+		amount = value
+	default:
+		return udecimal.Zero, fmt.Errorf("unknown coupon discount type %q", coupon.DiscountType)
+	}
+
+	return udecimal.Min(amount, eligibleTotal).RoundBank(2), nil
+}
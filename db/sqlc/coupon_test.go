@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func adminCreateRandomCoupon(t *testing.T, admin Admin) *Coupon {
+	arg := AdminCreateCouponParams{
+		AdminID:        admin.ID,
+		Code:           NormalizeCouponCode(util.RandomString(10)),
+		Description:    util.RandomString(6),
+		DiscountType:   CouponDiscountPercentage,
+		DiscountValue:  "10",
+		MinOrderValue:  "0",
+		MaxUses:        null.IntFrom(5),
+		MaxUsesPerUser: null.IntFrom(1),
+		StartDate:      time.Now().Add(-time.Hour),
+		EndDate:        time.Now().Add(time.Hour),
+		Active:         true,
+	}
+
+	coupon, err := testStore.AdminCreateCoupon(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, coupon)
+
+	require.Equal(t, arg.Code, coupon.Code)
+	require.Equal(t, arg.DiscountType, coupon.DiscountType)
+	require.Equal(t, arg.DiscountValue, coupon.DiscountValue)
+	require.Equal(t, arg.MaxUses, coupon.MaxUses)
+	require.Equal(t, arg.MaxUsesPerUser, coupon.MaxUsesPerUser)
+	require.Equal(t, int64(0), coupon.UsedCount)
+	require.False(t, coupon.CategoryID.Valid)
+	require.WithinDuration(t, arg.StartDate, coupon.StartDate, time.Second)
+	require.WithinDuration(t, arg.EndDate, coupon.EndDate, time.Second)
+
+	return coupon
+}
+
+func TestAdminCreateCoupon(t *testing.T) {
+	admin := createRandomAdmin(t)
+	adminCreateRandomCoupon(t, admin)
+}
+
+func TestAdminGetCoupon(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon1 := adminCreateRandomCoupon(t, admin)
+
+	coupon2, err := testStore.AdminGetCoupon(context.Background(), AdminGetCouponParams{
+		AdminID: admin.ID,
+		ID:      coupon1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, coupon1, coupon2)
+}
+
+func TestAdminUpdateCoupon(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon1 := adminCreateRandomCoupon(t, admin)
+
+	arg := AdminUpdateCouponParams{
+		AdminID:       admin.ID,
+		ID:            coupon1.ID,
+		DiscountType:  null.StringFrom(CouponDiscountFixed),
+		DiscountValue: null.StringFrom("15.50"),
+		Active:        null.BoolFrom(false),
+	}
+
+	coupon2, err := testStore.AdminUpdateCoupon(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, coupon2)
+
+	require.Equal(t, coupon1.ID, coupon2.ID)
+	require.Equal(t, coupon1.Code, coupon2.Code)
+	require.Equal(t, arg.DiscountType.String, coupon2.DiscountType)
+	require.Equal(t, arg.DiscountValue.String, coupon2.DiscountValue)
+	require.False(t, coupon2.Active)
+	require.NotEqual(t, coupon1.UpdatedAt, coupon2.UpdatedAt)
+}
+
+func TestAdminUpdateCouponPercentageAbove100(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon := adminCreateRandomCoupon(t, admin)
+
+	// the stored type is percentage, only the value is sent
+	updatedCoupon, err := testStore.AdminUpdateCoupon(context.Background(), AdminUpdateCouponParams{
+		AdminID:       admin.ID,
+		ID:            coupon.ID,
+		DiscountValue: null.StringFrom("500"),
+	})
+	require.Error(t, err)
+	require.Empty(t, updatedCoupon)
+
+	var pqErr *pgconn.PgError
+	require.ErrorAs(t, err, &pqErr)
+	require.Equal(t, "coupon_percentage_check", pqErr.ConstraintName)
+}
+
+func TestAdminDeleteCoupon(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon1 := adminCreateRandomCoupon(t, admin)
+
+	_, err := testStore.AdminDeleteCoupon(context.Background(), AdminDeleteCouponParams{
+		AdminID: admin.ID,
+		ID:      coupon1.ID,
+	})
+	require.NoError(t, err)
+
+	coupon2, err := testStore.AdminGetCoupon(context.Background(), AdminGetCouponParams{
+		AdminID: admin.ID,
+		ID:      coupon1.ID,
+	})
+	require.Error(t, err)
+	require.EqualError(t, err, pgx.ErrNoRows.Error())
+	require.Empty(t, coupon2)
+}
+
+func TestAdminListCoupons(t *testing.T) {
+	admin := createRandomAdmin(t)
+	for i := 0; i < 5; i++ {
+		adminCreateRandomCoupon(t, admin)
+	}
+
+	coupons, err := testStore.AdminListCoupons(context.Background(), AdminListCouponsParams{
+		AdminID: admin.ID,
+		Limit:   5,
+		Offset:  0,
+	})
+	require.NoError(t, err)
+	require.Len(t, coupons, 5)
+
+	for _, coupon := range coupons {
+		require.NotEmpty(t, coupon)
+	}
+}
+
+func TestGetCouponByCodeForUpdate(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon1 := adminCreateRandomCoupon(t, admin)
+
+	coupon2, err := testStore.GetCouponByCodeForUpdate(context.Background(), coupon1.Code)
+	require.NoError(t, err)
+	require.Equal(t, coupon1, coupon2)
+
+	coupon3, err := testStore.IncrementCouponUsedCount(context.Background(), coupon1.ID)
+	require.NoError(t, err)
+	require.Equal(t, coupon1.UsedCount+1, coupon3.UsedCount)
+}
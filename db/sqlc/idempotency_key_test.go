@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, user User) *IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		UserID:        user.ID,
+		Key:           util.RandomString(16),
+		RequestMethod: "POST",
+		RequestPath:   "/usr/v1/users/1/addresses",
+		RequestHash:   util.RandomString(64),
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	idempotencyKey, err := testStore.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, idempotencyKey)
+
+	require.Equal(t, arg.UserID, idempotencyKey.UserID)
+	require.Equal(t, arg.Key, idempotencyKey.Key)
+	require.Equal(t, arg.RequestHash, idempotencyKey.RequestHash)
+	require.False(t, idempotencyKey.ResponseStatus.Valid)
+	require.WithinDuration(t, arg.ExpiresAt, idempotencyKey.ExpiresAt, time.Second)
+
+	return idempotencyKey
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey1 := createRandomIdempotencyKey(t, user)
+
+	// the same key for the same user is not inserted twice
+	idempotencyKey2, err := testStore.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		UserID:        user.ID,
+		Key:           idempotencyKey1.Key,
+		RequestMethod: idempotencyKey1.RequestMethod,
+		RequestPath:   idempotencyKey1.RequestPath,
+		RequestHash:   util.RandomString(64),
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Empty(t, idempotencyKey2)
+}
+
+func TestUpdateIdempotencyKeyResponse(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey1 := createRandomIdempotencyKey(t, user)
+
+	arg := UpdateIdempotencyKeyResponseParams{
+		UserID:         user.ID,
+		Key:            idempotencyKey1.Key,
+		ResponseStatus: null.IntFrom(200),
+		ResponseBody:   null.StringFrom(`{"id":1}`),
+	}
+
+	idempotencyKey2, err := testStore.UpdateIdempotencyKeyResponse(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ResponseStatus, idempotencyKey2.ResponseStatus)
+	require.Equal(t, arg.ResponseBody, idempotencyKey2.ResponseBody)
+
+	idempotencyKey3, err := testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		UserID: user.ID,
+		Key:    idempotencyKey1.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, idempotencyKey2, idempotencyKey3)
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey1 := createRandomIdempotencyKey(t, user)
+
+	err := testStore.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{
+		UserID: user.ID,
+		Key:    idempotencyKey1.Key,
+	})
+	require.NoError(t, err)
+
+	idempotencyKey2, err := testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		UserID: user.ID,
+		Key:    idempotencyKey1.Key,
+	})
+	require.Error(t, err)
+	require.EqualError(t, err, pgx.ErrNoRows.Error())
+	require.Empty(t, idempotencyKey2)
+}
@@ -35,6 +35,58 @@ type Admin struct {
 	Active    bool      `json:"active"`
 }
 
+type AdminLoginChallenge struct {
+	ID      int64 `json:"id"`
+	AdminID int64 `json:"admin_id"`
+	// sha256 of the challenge token returned by the password step of the login
+	TokenHash string    `json:"token_hash"`
+	Attempts  int32     `json:"attempts"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type AdminPermission struct {
+	// name checked by the admin routes, like orders:manage
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type AdminRecoveryCode struct {
+	ID      int64 `json:"id"`
+	AdminID int64 `json:"admin_id"`
+	// sha256 of the one-time recovery code
+	CodeHash  string    `json:"code_hash"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AdminRole struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type AdminRoleAssignment struct {
+	AdminID   int64     `json:"admin_id"`
+	RoleID    int64     `json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AdminRolePermission struct {
+	RoleID     int64  `json:"role_id"`
+	Permission string `json:"permission"`
+}
+
+type AdminSecuritySetting struct {
+	// the table holds a single row
+	ID               bool      `json:"id"`
+	RequireTwoFactor bool      `json:"require_two_factor"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
 type AdminSession struct {
 	ID           uuid.UUID `json:"id"`
 	AdminID      int64     `json:"admin_id"`
@@ -45,6 +97,21 @@ type AdminSession struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	IsBlocked    bool      `json:"is_blocked"`
+	// id of the login session every rotated refresh token descends from
+	FamilyID uuid.UUID `json:"family_id"`
+	// id of the session created when this refresh token was rotated, a rotated token must not be used again
+	ReplacedBy uuid.NullUUID `json:"replaced_by"`
+}
+
+type AdminTotp struct {
+	AdminID int64 `json:"admin_id"`
+	// base32 totp secret, the admin can only login with it once enabled is true
+	Secret  string `json:"secret"`
+	Enabled bool   `json:"enabled"`
+	// time step of the last accepted code, a code can't be used twice
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
+	EnabledAt    time.Time `json:"enabled_at"`
 }
 
 type AdminType struct {
@@ -77,6 +144,49 @@ type CategoryPromotion struct {
 	Active bool `json:"active"`
 }
 
+type Coupon struct {
+	ID             int64     `json:"id"`
+	Code           string    `json:"code"`
+	Description    string    `json:"description"`
+	DiscountType   string    `json:"discount_type"`
+	DiscountValue  string    `json:"discount_value"`
+	MinOrderValue  string    `json:"min_order_value"`
+	MaxUses        null.Int  `json:"max_uses"`
+	MaxUsesPerUser null.Int  `json:"max_uses_per_user"`
+	UsedCount      int64     `json:"used_count"`
+	CategoryID     null.Int  `json:"category_id"`
+	BrandID        null.Int  `json:"brand_id"`
+	ProductID      null.Int  `json:"product_id"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CouponRedemption struct {
+	ID             int64     `json:"id"`
+	CouponID       int64     `json:"coupon_id"`
+	UserID         int64     `json:"user_id"`
+	ShopOrderID    int64     `json:"shop_order_id"`
+	DiscountAmount string    `json:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type EmailChange struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// the login email of the user is only replaced once the otp sent to this address is confirmed
+	NewEmail string `json:"new_email"`
+	// hash of the otp sent to the new email
+	SecretCode string `json:"secret_code"`
+	// wrong codes entered for this otp, it expires once the limit is reached
+	Attempts  int32     `json:"attempts"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
 type FeaturedProductItem struct {
 	ID            int64     `json:"id"`
 	ProductItemID int64     `json:"product_item_id"`
@@ -96,6 +206,19 @@ type HomePageTextBanner struct {
 	Active bool `json:"active"`
 }
 
+type IdempotencyKey struct {
+	ID             int64       `json:"id"`
+	UserID         int64       `json:"user_id"`
+	Key            string      `json:"key"`
+	RequestMethod  string      `json:"request_method"`
+	RequestPath    string      `json:"request_path"`
+	RequestHash    string      `json:"request_hash"`
+	ResponseStatus null.Int    `json:"response_status"`
+	ResponseBody   null.String `json:"response_body"`
+	CreatedAt      time.Time   `json:"created_at"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+}
+
 type Notification struct {
 	UserID          int64       `json:"user_id"`
 	DeviceID        null.String `json:"device_id"`
@@ -111,6 +234,8 @@ type OrderStatus struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// stable key used by the order state machine, like pending, shipped and delivered
+	Code null.String `json:"code"`
 }
 
 type PaymentMethod struct {
@@ -121,11 +246,34 @@ type PaymentMethod struct {
 	IsDefault     bool   `json:"is_default"`
 }
 
+type PaymentTransaction struct {
+	ID          int64  `json:"id"`
+	ShopOrderID int64  `json:"shop_order_id"`
+	Provider    string `json:"provider"`
+	// id of the payment at the provider, set once the payment is authorized
+	ProviderReference null.String `json:"provider_reference"`
+	// pending, authorized, captured, partially_refunded, refunded or failed
+	Status string `json:"status"`
+	Amount string `json:"amount"`
+	// part of the amount the provider gave back
+	RefundedAmount string `json:"refunded_amount"`
+	// part of the amount to give back that the provider did not refund yet
+	PendingRefundAmount string `json:"pending_refund_amount"`
+	// part of the amount the provider was asked to give back and did not confirm yet
+	RefundInFlightAmount string `json:"refund_in_flight_amount"`
+	// number of refunds asked to the provider, it keys the idempotency of the refund in flight
+	RefundSequence int64     `json:"refund_sequence"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
 type PaymentType struct {
 	ID int64 `json:"id"`
 	// for companies payment system like BCD
 	Value    string `json:"value"`
 	IsActive bool   `json:"is_active"`
+	// name of the payment provider that charges this payment type, like cod
+	Provider string `json:"provider"`
 }
 
 type Product struct {
@@ -139,6 +287,9 @@ type Product struct {
 	// default is false
 	Active bool        `json:"active"`
 	Search null.String `json:"search"`
+	// the average rating of the approved reviews, kept by the user_review_rating_refresh trigger
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int64   `json:"rating_count"`
 }
 
 type ProductBrand struct {
@@ -218,6 +369,42 @@ type ResetPassword struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 	ExpiredAt  time.Time `json:"expired_at"`
 	IsUsed     bool      `json:"is_used"`
+	// wrong codes entered for this otp, it expires once the limit is reached
+	Attempts int32 `json:"attempts"`
+}
+
+type ReturnRequest struct {
+	ID          int64 `json:"id"`
+	UserID      int64 `json:"user_id"`
+	ShopOrderID int64 `json:"shop_order_id"`
+	// requested, approved, rejected, received or refunded
+	Status    string   `json:"status"`
+	Reason    string   `json:"reason"`
+	AdminID   null.Int `json:"admin_id"`
+	AdminNote string   `json:"admin_note"`
+	// computed from the price and discount of the returned order items
+	RefundAmount null.String `json:"refund_amount"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+type ReturnRequestItem struct {
+	ID              int64     `json:"id"`
+	ReturnRequestID int64     `json:"return_request_id"`
+	ShopOrderItemID int64     `json:"shop_order_item_id"`
+	Qty             int32     `json:"qty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// the queries customers searched for, no user is recorded
+type SearchQueryLog struct {
+	ID    int64  `json:"id"`
+	Query string `json:"query"`
+	// the query after normalize_search_text, the queries are grouped by it
+	NormalizedQuery string `json:"normalized_query"`
+	// the number of results found for the query, the paginated searches count their first page, 0 when nothing was found
+	ResultCount int64     `json:"result_count"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type ShippingMethod struct {
@@ -260,6 +447,26 @@ type ShopOrderItem struct {
 	Quantity            int32     `json:"quantity"`
 	// discount of product when ordered
 	Discount int32 `json:"discount"`
+	// product size the qty was taken from, used to restock it
+	SizeID null.Int `json:"size_id"`
+	// size of product when ordered
+	SizeValue null.String `json:"size_value"`
+	// color of product when ordered
+	ColorValue null.String `json:"color_value"`
+	// name of product when ordered
+	ProductName null.String `json:"product_name"`
+	// part of the order's coupon discount taken off this line
+	CouponDiscount string `json:"coupon_discount"`
+}
+
+type ShopOrderStatusHistory struct {
+	ID           int64     `json:"id"`
+	ShopOrderID  int64     `json:"shop_order_id"`
+	FromStatusID null.Int  `json:"from_status_id"`
+	ToStatusID   int64     `json:"to_status_id"`
+	AdminID      null.Int  `json:"admin_id"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type ShoppingCart struct {
@@ -279,6 +486,16 @@ type ShoppingCartItem struct {
 	Qty            int32     `json:"qty"`
 }
 
+type StockReservation struct {
+	ID             int64     `json:"id"`
+	ShoppingCartID int64     `json:"shopping_cart_id"`
+	ProductItemID  int64     `json:"product_item_id"`
+	SizeID         int64     `json:"size_id"`
+	Qty            int32     `json:"qty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
 type User struct {
 	ID               int64     `json:"id"`
 	Username         string    `json:"username"`
@@ -292,6 +509,16 @@ type User struct {
 	IsEmailVerified  bool      `json:"is_email_verified"`
 }
 
+// accounts their users asked to delete, the personal data is anonymized once the grace period is over
+type UserDeletion struct {
+	UserID      int64     `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+	// end of the grace period, the user can cancel the deletion until then
+	DeleteAfter time.Time `json:"delete_after"`
+	// set once the personal data of the user was anonymized, the shop orders are kept for accounting
+	AnonymizedAt time.Time `json:"anonymized_at"`
+}
+
 type UserReview struct {
 	ID               int64     `json:"id"`
 	UserID           int64     `json:"user_id"`
@@ -299,6 +526,21 @@ type UserReview struct {
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 	RatingValue      int32     `json:"rating_value"`
+	ProductID        int64     `json:"product_id"`
+	Title            string    `json:"title"`
+	Body             string    `json:"body"`
+	// pending until an admin approves or hides the review, only the approved reviews are shown and counted
+	Status      string      `json:"status"`
+	AdminReply  null.String `json:"admin_reply"`
+	RepliedAt   null.Time   `json:"replied_at"`
+	ModeratedAt null.Time   `json:"moderated_at"`
+}
+
+type UserReviewImage struct {
+	ID        int64     `json:"id"`
+	ReviewID  int64     `json:"review_id"`
+	ImageUrl  string    `json:"image_url"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type UserSession struct {
@@ -311,6 +553,10 @@ type UserSession struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	IsBlocked    bool      `json:"is_blocked"`
+	// id of the login session every rotated refresh token descends from
+	FamilyID uuid.UUID `json:"family_id"`
+	// id of the session created when this refresh token was rotated, a rotated token must not be used again
+	ReplacedBy uuid.NullUUID `json:"replaced_by"`
 }
 
 type Variation struct {
@@ -334,6 +580,8 @@ type VerifyEmail struct {
 	CreatedAt  time.Time `json:"created_at"`
 	ExpiredAt  time.Time `json:"expired_at"`
 	IsUsed     bool      `json:"is_used"`
+	// wrong codes entered for this otp, it expires once the limit is reached
+	Attempts int32 `json:"attempts"`
 }
 
 type WishList struct {
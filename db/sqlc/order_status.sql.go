@@ -20,7 +20,7 @@ SELECT 1 AS is_admin
     WHERE "admin".id = $1
     AND active = TRUE
     )
-SELECT id, status, created_at, updated_at FROM "order_status"
+SELECT id, status, created_at, updated_at, code FROM "order_status"
 WHERE EXISTS (SELECT 1 FROM t1)
 `
 
@@ -41,6 +41,7 @@ func (q *Queries) AdminListOrderStatuses(ctx context.Context, adminID int64) ([]
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Code,
 		); err != nil {
 			return nil, err
 		}
@@ -59,7 +60,7 @@ INSERT INTO "order_status" (
   $1
 )
 ON CONFLICT(status) DO UPDATE SET status = $1
-RETURNING id, status, created_at, updated_at
+RETURNING id, status, created_at, updated_at, code
 `
 
 func (q *Queries) CreateOrderStatus(ctx context.Context, status string) (*OrderStatus, error) {
@@ -70,6 +71,7 @@ func (q *Queries) CreateOrderStatus(ctx context.Context, status string) (*OrderS
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Code,
 	)
 	return &i, err
 }
@@ -85,7 +87,7 @@ func (q *Queries) DeleteOrderStatus(ctx context.Context, id int64) error {
 }
 
 const getOrderStatus = `-- name: GetOrderStatus :one
-SELECT id, status, created_at, updated_at FROM "order_status"
+SELECT id, status, created_at, updated_at, code FROM "order_status"
 WHERE id = $1 LIMIT 1
 `
 
@@ -97,12 +99,31 @@ func (q *Queries) GetOrderStatus(ctx context.Context, id int64) (*OrderStatus, e
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Code,
+	)
+	return &i, err
+}
+
+const getOrderStatusByCode = `-- name: GetOrderStatusByCode :one
+SELECT id, status, created_at, updated_at, code FROM "order_status"
+WHERE code = $1 LIMIT 1
+`
+
+func (q *Queries) GetOrderStatusByCode(ctx context.Context, code null.String) (*OrderStatus, error) {
+	row := q.db.QueryRow(ctx, getOrderStatusByCode, code)
+	var i OrderStatus
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Code,
 	)
 	return &i, err
 }
 
 const getOrderStatusByUserID = `-- name: GetOrderStatusByUserID :one
-SELECT os.id, os.status, os.created_at, os.updated_at, so.user_id
+SELECT os.id, os.status, os.created_at, os.updated_at, os.code, so.user_id
 FROM "order_status" AS os
 LEFT JOIN "shop_order" AS so ON so.order_status_id = os.id
 WHERE so.user_id = $1
@@ -116,11 +137,12 @@ type GetOrderStatusByUserIDParams struct {
 }
 
 type GetOrderStatusByUserIDRow struct {
-	ID        int64     `json:"id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    null.Int  `json:"user_id"`
+	ID        int64       `json:"id"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Code      null.String `json:"code"`
+	UserID    null.Int    `json:"user_id"`
 }
 
 func (q *Queries) GetOrderStatusByUserID(ctx context.Context, arg GetOrderStatusByUserIDParams) (*GetOrderStatusByUserIDRow, error) {
@@ -131,13 +153,14 @@ func (q *Queries) GetOrderStatusByUserID(ctx context.Context, arg GetOrderStatus
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Code,
 		&i.UserID,
 	)
 	return &i, err
 }
 
 const listOrderStatuses = `-- name: ListOrderStatuses :many
-SELECT id, status, created_at, updated_at FROM "order_status"
+SELECT id, status, created_at, updated_at, code FROM "order_status"
 `
 
 func (q *Queries) ListOrderStatuses(ctx context.Context) ([]*OrderStatus, error) {
@@ -154,6 +177,7 @@ func (q *Queries) ListOrderStatuses(ctx context.Context) ([]*OrderStatus, error)
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Code,
 		); err != nil {
 			return nil, err
 		}
@@ -166,7 +190,7 @@ func (q *Queries) ListOrderStatuses(ctx context.Context) ([]*OrderStatus, error)
 }
 
 const listOrderStatusesByUserID = `-- name: ListOrderStatusesByUserID :many
-SELECT os.id, os.status, os.created_at, os.updated_at, so.user_id
+SELECT os.id, os.status, os.created_at, os.updated_at, os.code, so.user_id
 FROM "order_status" AS os
 LEFT JOIN "shop_order" AS so ON so.order_status_id = os.id
 WHERE so.user_id = $3
@@ -182,11 +206,12 @@ type ListOrderStatusesByUserIDParams struct {
 }
 
 type ListOrderStatusesByUserIDRow struct {
-	ID        int64     `json:"id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    null.Int  `json:"user_id"`
+	ID        int64       `json:"id"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Code      null.String `json:"code"`
+	UserID    null.Int    `json:"user_id"`
 }
 
 func (q *Queries) ListOrderStatusesByUserID(ctx context.Context, arg ListOrderStatusesByUserIDParams) ([]*ListOrderStatusesByUserIDRow, error) {
@@ -203,6 +228,7 @@ func (q *Queries) ListOrderStatusesByUserID(ctx context.Context, arg ListOrderSt
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Code,
 			&i.UserID,
 		); err != nil {
 			return nil, err
@@ -221,7 +247,7 @@ SET
 status = COALESCE($1,status),
 updated_at = now()
 WHERE id = $2
-RETURNING id, status, created_at, updated_at
+RETURNING id, status, created_at, updated_at, code
 `
 
 type UpdateOrderStatusParams struct {
@@ -237,6 +263,7 @@ func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusPa
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Code,
 	)
 	return &i, err
 }
@@ -15,29 +15,41 @@ const adminCreatePaymentType = `-- name: AdminCreatePaymentType :one
 With t1 AS (
 SELECT 1 AS is_admin
     FROM "admin"
-    WHERE "admin".id = $3
+    WHERE "admin".id = $4
     AND active = TRUE
     )
 INSERT INTO "payment_type" (
   value,
-  is_active
+  is_active,
+  provider
 )
-SELECT $1, $2 FROM t1
+SELECT $1, $2, $3 FROM t1
 WHERE is_admin=1
 ON CONFLICT(value) DO UPDATE SET value = $1
-RETURNING id, value, is_active
+RETURNING id, value, is_active, provider
 `
 
 type AdminCreatePaymentTypeParams struct {
 	Value    string `json:"value"`
 	IsActive bool   `json:"is_active"`
+	Provider string `json:"provider"`
 	AdminID  int64  `json:"admin_id"`
 }
 
 func (q *Queries) AdminCreatePaymentType(ctx context.Context, arg AdminCreatePaymentTypeParams) (*PaymentType, error) {
-	row := q.db.QueryRow(ctx, adminCreatePaymentType, arg.Value, arg.IsActive, arg.AdminID)
+	row := q.db.QueryRow(ctx, adminCreatePaymentType,
+		arg.Value,
+		arg.IsActive,
+		arg.Provider,
+		arg.AdminID,
+	)
 	var i PaymentType
-	err := row.Scan(&i.ID, &i.Value, &i.IsActive)
+	err := row.Scan(
+		&i.ID,
+		&i.Value,
+		&i.IsActive,
+		&i.Provider,
+	)
 	return &i, err
 }
 
@@ -71,7 +83,7 @@ SELECT 1 AS is_admin
     WHERE "admin".id = $1
     AND active = TRUE
     )
-SELECT id, value, is_active FROM "payment_type"
+SELECT id, value, is_active, provider FROM "payment_type"
 WHERE (SELECT is_admin FROM t1) = 1
 `
 
@@ -87,7 +99,12 @@ func (q *Queries) AdminListPaymentTypes(ctx context.Context, adminID int64) ([]*
 	items := []*PaymentType{}
 	for rows.Next() {
 		var i PaymentType
-		if err := rows.Scan(&i.ID, &i.Value, &i.IsActive); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Value,
+			&i.IsActive,
+			&i.Provider,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
@@ -102,21 +119,23 @@ const adminUpdatePaymentType = `-- name: AdminUpdatePaymentType :one
 With t1 AS (
 SELECT 1 AS is_admin
     FROM "admin"
-    WHERE "admin".id = $4
+    WHERE "admin".id = $5
     AND active = TRUE
     )
 UPDATE "payment_type"
 SET 
 value = COALESCE($1,value),
-is_active = COALESCE($2,is_active)
-WHERE "payment_type".id = $3
+is_active = COALESCE($2,is_active),
+provider = COALESCE($3,provider)
+WHERE "payment_type".id = $4
 AND (SELECT is_admin FROM t1) = 1
-RETURNING id, value, is_active
+RETURNING id, value, is_active, provider
 `
 
 type AdminUpdatePaymentTypeParams struct {
 	Value    null.String `json:"value"`
 	IsActive null.Bool   `json:"is_active"`
+	Provider null.String `json:"provider"`
 	ID       int64       `json:"id"`
 	AdminID  int64       `json:"admin_id"`
 }
@@ -125,11 +144,17 @@ func (q *Queries) AdminUpdatePaymentType(ctx context.Context, arg AdminUpdatePay
 	row := q.db.QueryRow(ctx, adminUpdatePaymentType,
 		arg.Value,
 		arg.IsActive,
+		arg.Provider,
 		arg.ID,
 		arg.AdminID,
 	)
 	var i PaymentType
-	err := row.Scan(&i.ID, &i.Value, &i.IsActive)
+	err := row.Scan(
+		&i.ID,
+		&i.Value,
+		&i.IsActive,
+		&i.Provider,
+	)
 	return &i, err
 }
 
@@ -140,13 +165,18 @@ INSERT INTO "payment_type" (
   $1
 ) 
 ON CONFLICT(value) DO UPDATE SET value = $1
-RETURNING id, value, is_active
+RETURNING id, value, is_active, provider
 `
 
 func (q *Queries) CreatePaymentType(ctx context.Context, value string) (*PaymentType, error) {
 	row := q.db.QueryRow(ctx, createPaymentType, value)
 	var i PaymentType
-	err := row.Scan(&i.ID, &i.Value, &i.IsActive)
+	err := row.Scan(
+		&i.ID,
+		&i.Value,
+		&i.IsActive,
+		&i.Provider,
+	)
 	return &i, err
 }
 
@@ -161,19 +191,24 @@ func (q *Queries) DeletePaymentType(ctx context.Context, id int64) error {
 }
 
 const getPaymentType = `-- name: GetPaymentType :one
-SELECT id, value, is_active FROM "payment_type"
+SELECT id, value, is_active, provider FROM "payment_type"
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetPaymentType(ctx context.Context, id int64) (*PaymentType, error) {
 	row := q.db.QueryRow(ctx, getPaymentType, id)
 	var i PaymentType
-	err := row.Scan(&i.ID, &i.Value, &i.IsActive)
+	err := row.Scan(
+		&i.ID,
+		&i.Value,
+		&i.IsActive,
+		&i.Provider,
+	)
 	return &i, err
 }
 
 const listPaymentTypes = `-- name: ListPaymentTypes :many
-SELECT id, value, is_active FROM "payment_type"
+SELECT id, value, is_active, provider FROM "payment_type"
 `
 
 func (q *Queries) ListPaymentTypes(ctx context.Context) ([]*PaymentType, error) {
@@ -185,7 +220,12 @@ func (q *Queries) ListPaymentTypes(ctx context.Context) ([]*PaymentType, error)
 	items := []*PaymentType{}
 	for rows.Next() {
 		var i PaymentType
-		if err := rows.Scan(&i.ID, &i.Value, &i.IsActive); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Value,
+			&i.IsActive,
+			&i.Provider,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
@@ -202,7 +242,7 @@ SET
 value = COALESCE($1,value),
 is_active = COALESCE($2,is_active)
 WHERE id = $3
-RETURNING id, value, is_active
+RETURNING id, value, is_active, provider
 `
 
 type UpdatePaymentTypeParams struct {
@@ -214,6 +254,11 @@ type UpdatePaymentTypeParams struct {
 func (q *Queries) UpdatePaymentType(ctx context.Context, arg UpdatePaymentTypeParams) (*PaymentType, error) {
 	row := q.db.QueryRow(ctx, updatePaymentType, arg.Value, arg.IsActive, arg.ID)
 	var i PaymentType
-	err := row.Scan(&i.ID, &i.Value, &i.IsActive)
+	err := row.Scan(
+		&i.ID,
+		&i.Value,
+		&i.IsActive,
+		&i.Provider,
+	)
 	return &i, err
 }
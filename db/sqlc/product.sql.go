@@ -28,7 +28,7 @@ INSERT INTO "product" (
 )
 SELECT $1, $2, $3, $4, $5 FROM t1
 WHERE is_admin=1
-RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search
+RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count
 `
 
 type AdminCreateProductParams struct {
@@ -60,6 +60,8 @@ func (q *Queries) AdminCreateProduct(ctx context.Context, arg AdminCreateProduct
 		&i.UpdatedAt,
 		&i.Active,
 		&i.Search,
+		&i.RatingAverage,
+		&i.RatingCount,
 	)
 	return &i, err
 }
@@ -103,7 +105,7 @@ active = COALESCE($5,active),
 updated_at = now()
 WHERE "product".id = $6
 AND (SELECT is_admin FROM t1) = 1
-RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search
+RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count
 `
 
 type AdminUpdateProductParams struct {
@@ -138,6 +140,8 @@ func (q *Queries) AdminUpdateProduct(ctx context.Context, arg AdminUpdateProduct
 		&i.UpdatedAt,
 		&i.Active,
 		&i.Search,
+		&i.RatingAverage,
+		&i.RatingCount,
 	)
 	return &i, err
 }
@@ -153,7 +157,7 @@ INSERT INTO "product" (
 ) VALUES (
   $1, $2, $3, $4, $5
 )
-RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search
+RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count
 `
 
 type CreateProductParams struct {
@@ -183,6 +187,8 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (*
 		&i.UpdatedAt,
 		&i.Active,
 		&i.Search,
+		&i.RatingAverage,
+		&i.RatingCount,
 	)
 	return &i, err
 }
@@ -198,7 +204,7 @@ func (q *Queries) DeleteProduct(ctx context.Context, id int64) error {
 }
 
 const getProduct = `-- name: GetProduct :one
-SELECT id, category_id, brand_id, name, description, created_at, updated_at, active, search FROM "product"
+SELECT id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count FROM "product"
 WHERE id = $1 LIMIT 1
 `
 
@@ -215,12 +221,14 @@ func (q *Queries) GetProduct(ctx context.Context, id int64) (*Product, error) {
 		&i.UpdatedAt,
 		&i.Active,
 		&i.Search,
+		&i.RatingAverage,
+		&i.RatingCount,
 	)
 	return &i, err
 }
 
 const getProductsByIDs = `-- name: GetProductsByIDs :many
-SELECT id, category_id, brand_id, name, description, created_at, updated_at, active, search FROM "product"
+SELECT id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count FROM "product"
 WHERE id = ANY($1::bigint[])
 `
 
@@ -243,6 +251,8 @@ func (q *Queries) GetProductsByIDs(ctx context.Context, ids []int64) ([]*Product
 			&i.UpdatedAt,
 			&i.Active,
 			&i.Search,
+			&i.RatingAverage,
+			&i.RatingCount,
 		); err != nil {
 			return nil, err
 		}
@@ -255,7 +265,7 @@ func (q *Queries) GetProductsByIDs(ctx context.Context, ids []int64) ([]*Product
 }
 
 const listProducts = `-- name: ListProducts :many
-SELECT id, category_id, brand_id, name, description, created_at, updated_at, active, search ,
+SELECT id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count ,
 COUNT(*) OVER() AS total_count
 FROM "product"
 ORDER BY id
@@ -269,16 +279,18 @@ type ListProductsParams struct {
 }
 
 type ListProductsRow struct {
-	ID          int64       `json:"id"`
-	CategoryID  int64       `json:"category_id"`
-	BrandID     int64       `json:"brand_id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
-	Active      bool        `json:"active"`
-	Search      null.String `json:"search"`
-	TotalCount  int64       `json:"total_count"`
+	ID            int64       `json:"id"`
+	CategoryID    int64       `json:"category_id"`
+	BrandID       int64       `json:"brand_id"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Active        bool        `json:"active"`
+	Search        null.String `json:"search"`
+	RatingAverage float64     `json:"rating_average"`
+	RatingCount   int64       `json:"rating_count"`
+	TotalCount    int64       `json:"total_count"`
 }
 
 // WITH total_records AS (
@@ -307,6 +319,8 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]*
 			&i.UpdatedAt,
 			&i.Active,
 			&i.Search,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.TotalCount,
 		); err != nil {
 			return nil, err
@@ -322,7 +336,7 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]*
 const listProductsNextPage = `-- name: ListProductsNextPage :many
 WITH t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
 WHERE
  p.id < $2 
@@ -348,6 +362,8 @@ type ListProductsNextPageRow struct {
 	Active        bool      `json:"active"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	RatingAverage float64   `json:"rating_average"`
+	RatingCount   int64     `json:"rating_count"`
 	NextAvailable bool      `json:"next_available"`
 }
 
@@ -369,6 +385,8 @@ func (q *Queries) ListProductsNextPage(ctx context.Context, arg ListProductsNext
 			&i.Active,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.NextAvailable,
 		); err != nil {
 			return nil, err
@@ -384,7 +402,7 @@ func (q *Queries) ListProductsNextPage(ctx context.Context, arg ListProductsNext
 const listProductsV2 = `-- name: ListProductsV2 :many
 WITH t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
 ORDER BY id DESC
 LIMIT $1 +1
@@ -403,6 +421,8 @@ type ListProductsV2Row struct {
 	Active        bool      `json:"active"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	RatingAverage float64   `json:"rating_average"`
+	RatingCount   int64     `json:"rating_count"`
 	NextAvailable bool      `json:"next_available"`
 }
 
@@ -424,6 +444,8 @@ func (q *Queries) ListProductsV2(ctx context.Context, limit int32) ([]*ListProdu
 			&i.Active,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.NextAvailable,
 		); err != nil {
 			return nil, err
@@ -437,24 +459,35 @@ func (q *Queries) ListProductsV2(ctx context.Context, limit int32) ([]*ListProdu
 }
 
 const searchProducts = `-- name: SearchProducts :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query($2::VARCHAR) AS tsq,
+ normalize_search_text($2::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
+CROSS JOIN search AS s
+LEFT JOIN "product_brand" AS pb ON pb.id = p.brand_id
+LEFT JOIN "product_category" AS pc ON pc.id = p.category_id
 WHERE 
-p.search @@ 
-CASE
-    WHEN char_length($2::VARCHAR) > 0 THEN to_tsquery(concat($2, ':*'))
-    ELSE to_tsquery($2)
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length($2) > 0 THEN to_tsquery(concat($2, ':*'))
-    ELSE to_tsquery($2)
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
@@ -476,6 +509,8 @@ type SearchProductsRow struct {
 	Active        bool      `json:"active"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	RatingAverage float64   `json:"rating_average"`
+	RatingCount   int64     `json:"rating_count"`
 	NextAvailable bool      `json:"next_available"`
 }
 
@@ -497,6 +532,8 @@ func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams)
 			&i.Active,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.NextAvailable,
 		); err != nil {
 			return nil, err
@@ -510,26 +547,36 @@ func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams)
 }
 
 const searchProductsNextPage = `-- name: SearchProductsNextPage :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query($2::VARCHAR) AS tsq,
+ normalize_search_text($2::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at
+ p.id, p.name, p.description, p.category_id, p.brand_id, p.active, p.created_at, p.updated_at, p.rating_average, p.rating_count
 FROM "product" AS p
-
+CROSS JOIN search AS s
+LEFT JOIN "product_brand" AS pb ON pb.id = p.brand_id
+LEFT JOIN "product_category" AS pc ON pc.id = p.category_id
 WHERE 
-p.id < $2 AND
-p.search @@ 
-CASE
-    WHEN char_length($3::VARCHAR) > 0 THEN to_tsquery(concat($3, ':*')::VARCHAR)
-    ELSE to_tsquery($3::VARCHAR)
-END
+p.id < $3 AND
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length($3::VARCHAR) > 0 THEN to_tsquery(concat($3, ':*')::VARCHAR)
-    ELSE to_tsquery($3::VARCHAR)
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
@@ -539,8 +586,8 @@ LIMIT $1
 
 type SearchProductsNextPageParams struct {
 	Limit     int32  `json:"limit"`
-	ProductID int64  `json:"product_id"`
 	Query     string `json:"query"`
+	ProductID int64  `json:"product_id"`
 }
 
 type SearchProductsNextPageRow struct {
@@ -552,11 +599,13 @@ type SearchProductsNextPageRow struct {
 	Active        bool      `json:"active"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	RatingAverage float64   `json:"rating_average"`
+	RatingCount   int64     `json:"rating_count"`
 	NextAvailable bool      `json:"next_available"`
 }
 
 func (q *Queries) SearchProductsNextPage(ctx context.Context, arg SearchProductsNextPageParams) ([]*SearchProductsNextPageRow, error) {
-	rows, err := q.db.Query(ctx, searchProductsNextPage, arg.Limit, arg.ProductID, arg.Query)
+	rows, err := q.db.Query(ctx, searchProductsNextPage, arg.Limit, arg.Query, arg.ProductID)
 	if err != nil {
 		return nil, err
 	}
@@ -573,6 +622,8 @@ func (q *Queries) SearchProductsNextPage(ctx context.Context, arg SearchProducts
 			&i.Active,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.NextAvailable,
 		); err != nil {
 			return nil, err
@@ -596,7 +647,7 @@ description = COALESCE($4,description),
 active = COALESCE($5,active),
 updated_at = now()
 WHERE id = $6
-RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search
+RETURNING id, category_id, brand_id, name, description, created_at, updated_at, active, search, rating_average, rating_count
 `
 
 type UpdateProductParams struct {
@@ -632,6 +683,8 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (*
 		&i.UpdatedAt,
 		&i.Active,
 		&i.Search,
+		&i.RatingAverage,
+		&i.RatingCount,
 	)
 	return &i, err
 }
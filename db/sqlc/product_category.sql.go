@@ -144,6 +144,32 @@ func (q *Queries) GetProductCategoryByParent(ctx context.Context, arg GetProduct
 	return &i, err
 }
 
+const isProductCategoryInSubtree = `-- name: IsProductCategoryInSubtree :one
+WITH RECURSIVE subtree AS (
+SELECT $1::BIGINT AS id
+UNION
+SELECT pc.id FROM "product_category" AS pc
+INNER JOIN subtree AS s ON pc.parent_category_id = s.id
+)
+SELECT EXISTS (
+SELECT 1 FROM subtree
+WHERE id = $2::BIGINT
+) AS in_subtree
+`
+
+type IsProductCategoryInSubtreeParams struct {
+	RootID     int64 `json:"root_id"`
+	CategoryID int64 `json:"category_id"`
+}
+
+// whether the category is the root category or one of its descendants
+func (q *Queries) IsProductCategoryInSubtree(ctx context.Context, arg IsProductCategoryInSubtreeParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isProductCategoryInSubtree, arg.RootID, arg.CategoryID)
+	var in_subtree bool
+	err := row.Scan(&in_subtree)
+	return in_subtree, err
+}
+
 const listProductCategories = `-- name: ListProductCategories :many
 SELECT id, parent_category_id, category_name, category_image FROM "product_category"
 ORDER BY id
@@ -208,6 +234,108 @@ func (q *Queries) ListProductCategoriesByParent(ctx context.Context, parentCateg
 	return items, nil
 }
 
+const listProductCategoryBreadcrumbs = `-- name: ListProductCategoryBreadcrumbs :many
+WITH RECURSIVE breadcrumb AS (
+SELECT pc.id, pc.parent_category_id, pc.category_name, 0 AS depth
+FROM "product_category" AS pc
+WHERE pc.id = $1
+UNION ALL
+SELECT parent.id, parent.parent_category_id, parent.category_name, b.depth + 1
+FROM "product_category" AS parent
+INNER JOIN breadcrumb AS b ON parent.id = b.parent_category_id
+WHERE b.depth < 64
+)
+SELECT id, parent_category_id, category_name FROM breadcrumb
+ORDER BY depth DESC
+`
+
+type ListProductCategoryBreadcrumbsRow struct {
+	ID               int64    `json:"id"`
+	ParentCategoryID null.Int `json:"parent_category_id"`
+	CategoryName     string   `json:"category_name"`
+}
+
+// the ancestors of the category from the root down to the category itself
+// stops on a cycle written to the table outside of MoveProductCategoryTx
+func (q *Queries) ListProductCategoryBreadcrumbs(ctx context.Context, id int64) ([]*ListProductCategoryBreadcrumbsRow, error) {
+	rows, err := q.db.Query(ctx, listProductCategoryBreadcrumbs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListProductCategoryBreadcrumbsRow{}
+	for rows.Next() {
+		var i ListProductCategoryBreadcrumbsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentCategoryID,
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductCategoryTree = `-- name: ListProductCategoryTree :many
+SELECT
+ pc.id, pc.parent_category_id, pc.category_name, pc.category_image,
+ COUNT(p.id) AS product_count
+FROM "product_category" AS pc
+LEFT JOIN "product" AS p ON p.category_id = pc.id AND p.active = TRUE
+GROUP BY pc.id
+ORDER BY pc.id
+`
+
+type ListProductCategoryTreeRow struct {
+	ID               int64    `json:"id"`
+	ParentCategoryID null.Int `json:"parent_category_id"`
+	CategoryName     string   `json:"category_name"`
+	CategoryImage    string   `json:"category_image"`
+	ProductCount     int64    `json:"product_count"`
+}
+
+// every category with the number of its active products, the tree is assembled from the parent ids
+func (q *Queries) ListProductCategoryTree(ctx context.Context) ([]*ListProductCategoryTreeRow, error) {
+	rows, err := q.db.Query(ctx, listProductCategoryTree)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListProductCategoryTreeRow{}
+	for rows.Next() {
+		var i ListProductCategoryTreeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentCategoryID,
+			&i.CategoryName,
+			&i.CategoryImage,
+			&i.ProductCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockProductCategoryTree = `-- name: LockProductCategoryTree :exec
+SELECT pg_advisory_xact_lock(hashtext('product_category_tree'))
+`
+
+// serializes the moves of the categories until the end of the transaction, so two moves can't build a cycle together
+func (q *Queries) LockProductCategoryTree(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockProductCategoryTree)
+	return err
+}
+
 const updateProductCategory = `-- name: UpdateProductCategory :one
 
 UPDATE "product_category"
@@ -237,3 +365,28 @@ func (q *Queries) UpdateProductCategory(ctx context.Context, arg UpdateProductCa
 	)
 	return &i, err
 }
+
+const updateProductCategoryParent = `-- name: UpdateProductCategoryParent :one
+UPDATE "product_category"
+SET parent_category_id = $1
+WHERE id = $2
+RETURNING id, parent_category_id, category_name, category_image
+`
+
+type UpdateProductCategoryParentParams struct {
+	ParentCategoryID null.Int `json:"parent_category_id"`
+	ID               int64    `json:"id"`
+}
+
+// a NULL parent moves the category to the root
+func (q *Queries) UpdateProductCategoryParent(ctx context.Context, arg UpdateProductCategoryParentParams) (*ProductCategory, error) {
+	row := q.db.QueryRow(ctx, updateProductCategoryParent, arg.ParentCategoryID, arg.ID)
+	var i ProductCategory
+	err := row.Scan(
+		&i.ID,
+		&i.ParentCategoryID,
+		&i.CategoryName,
+		&i.CategoryImage,
+	)
+	return &i, err
+}
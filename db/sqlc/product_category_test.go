@@ -294,3 +294,74 @@ func TestListProductCategories(t *testing.T) {
 
 	}
 }
+
+func createRandomProductCategoryChild(t *testing.T, parentID int64) ProductCategory {
+	arg := CreateProductCategoryParams{
+		ParentCategoryID: null.IntFrom(parentID),
+		CategoryName:     util.RandomString(5),
+		CategoryImage:    util.RandomURL(),
+	}
+
+	productCategory, err := testStore.CreateProductCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, productCategory)
+	require.Equal(t, arg.ParentCategoryID, productCategory.ParentCategoryID)
+
+	return *productCategory
+}
+
+func createRandomProductInCategory(t *testing.T, categoryID int64) Product {
+	brand := createRandomProductBrand(t)
+
+	product, err := testStore.CreateProduct(context.Background(), CreateProductParams{
+		CategoryID:  categoryID,
+		BrandID:     brand.ID,
+		Name:        util.RandomUser(),
+		Description: util.RandomUser(),
+		Active:      true,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, product)
+
+	return *product
+}
+
+func TestListProductCategoryTree(t *testing.T) {
+	parent := createRandomProductCategoryForUpdateOrDelete(t)
+	child := createRandomProductCategoryChild(t, parent.ID)
+	createRandomProductInCategory(t, child.ID)
+	createRandomProductInCategory(t, child.ID)
+
+	categories, err := testStore.ListProductCategoryTree(context.Background())
+	require.NoError(t, err)
+
+	counts := make(map[int64]*ListProductCategoryTreeRow)
+	for _, category := range categories {
+		counts[category.ID] = category
+	}
+
+	require.Contains(t, counts, parent.ID)
+	require.Contains(t, counts, child.ID)
+	require.Equal(t, int64(0), counts[parent.ID].ProductCount)
+	require.Equal(t, int64(2), counts[child.ID].ProductCount)
+	require.Equal(t, null.IntFrom(parent.ID), counts[child.ID].ParentCategoryID)
+}
+
+func TestListProductCategoryBreadcrumbs(t *testing.T) {
+	root := createRandomProductCategoryForUpdateOrDelete(t)
+	middle := createRandomProductCategoryChild(t, root.ID)
+	leaf := createRandomProductCategoryChild(t, middle.ID)
+
+	breadcrumbs, err := testStore.ListProductCategoryBreadcrumbs(context.Background(), leaf.ID)
+	require.NoError(t, err)
+	require.Len(t, breadcrumbs, 3)
+
+	require.Equal(t, root.ID, breadcrumbs[0].ID)
+	require.Equal(t, middle.ID, breadcrumbs[1].ID)
+	require.Equal(t, leaf.ID, breadcrumbs[2].ID)
+	require.Equal(t, leaf.CategoryName, breadcrumbs[2].CategoryName)
+
+	breadcrumbs, err = testStore.ListProductCategoryBreadcrumbs(context.Background(), 0)
+	require.NoError(t, err)
+	require.Empty(t, breadcrumbs)
+}
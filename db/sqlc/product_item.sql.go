@@ -211,7 +211,12 @@ func (q *Queries) GetActiveProductItems(ctx context.Context, adminID int64) (int
 }
 
 const getProductItem = `-- name: GetProductItem :one
-SELECT id, product_id, image_id, color_id, price, created_at, updated_at, product_sku, active, product_item_id, total_stock ,COALESCE(stock.total_stock,0) as qty_in_stock FROM "product_item"
+SELECT id, product_id, image_id, color_id, price, created_at, updated_at, product_sku, active, product_item_id, total_stock ,GREATEST(COALESCE(stock.total_stock,0) - COALESCE((
+    SELECT SUM(qty)
+    FROM "stock_reservation"
+    WHERE product_item_id = $1
+    AND expires_at > now()
+),0), 0) as qty_in_stock FROM "product_item"
 LEFT JOIN (
     SELECT 
         product_item_id, 
@@ -425,7 +430,7 @@ func (q *Queries) GetTotalProductItems(ctx context.Context, adminID int64) (int6
 }
 
 const listProductItems = `-- name: ListProductItems :many
-SELECT pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.id, p.category_id, p.brand_id, p.name, p.description, p.created_at, p.updated_at, p.active, p.search, COALESCE(stock.total_stock,0) as qty_in_stock, /*ps.size_value,*/ pimg.product_image_1,
+SELECT pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.id, p.category_id, p.brand_id, p.name, p.description, p.created_at, p.updated_at, p.active, p.search, p.rating_average, p.rating_count, COALESCE(stock.total_stock,0) as qty_in_stock, /*ps.size_value,*/ pimg.product_image_1,
 pimg.product_image_2, pimg.product_image_3, pclr.color_value,
 COUNT(*) OVER() AS total_count
 FROM "product_item" AS pi
@@ -471,6 +476,8 @@ type ListProductItemsRow struct {
 	UpdatedAt_2   null.Time   `json:"updated_at_2"`
 	Active_2      null.Bool   `json:"active_2"`
 	Search        null.String `json:"search"`
+	RatingAverage null.Float  `json:"rating_average"`
+	RatingCount   null.Int    `json:"rating_count"`
 	QtyInStock    int64       `json:"qty_in_stock"`
 	ProductImage1 null.String `json:"product_image_1"`
 	ProductImage2 null.String `json:"product_image_2"`
@@ -508,6 +515,8 @@ func (q *Queries) ListProductItems(ctx context.Context, arg ListProductItemsPara
 			&i.UpdatedAt_2,
 			&i.Active_2,
 			&i.Search,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.QtyInStock,
 			&i.ProductImage1,
 			&i.ProductImage2,
@@ -656,7 +665,7 @@ func (q *Queries) ListProductItemsByIDs(ctx context.Context, productsIds []int64
 const listProductItemsNextPage = `-- name: ListProductItemsNextPage :many
 WITH t1 AS(
 SELECT 
- pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image, /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -774,7 +783,15 @@ ELSE 1=1
 END
 AND CASE
     WHEN COALESCE($13, 0) > 0 
-    THEN pc.id = $13
+    THEN p.category_id IN (
+        WITH RECURSIVE category_tree AS (
+            SELECT $13::BIGINT AS id
+            UNION
+            SELECT c.id FROM "product_category" AS c
+            INNER JOIN category_tree AS ct ON c.parent_category_id = ct.id
+        )
+        SELECT id FROM category_tree
+    )
     ELSE 1=1
 END 
 AND CASE
@@ -850,6 +867,8 @@ type ListProductItemsNextPageRow struct {
 	Description               string      `json:"description"`
 	CategoryID                int64       `json:"category_id"`
 	BrandID                   int64       `json:"brand_id"`
+	RatingAverage             float64     `json:"rating_average"`
+	RatingCount               int64       `json:"rating_count"`
 	CategoryName              null.String `json:"category_name"`
 	ParentCategoryID          null.Int    `json:"parent_category_id"`
 	CategoryImage             null.String `json:"category_image"`
@@ -885,6 +904,7 @@ type ListProductItemsNextPageRow struct {
 	NextAvailable             bool        `json:"next_available"`
 }
 
+// a parent category matches the items of all its descendant categories
 // LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 // AND CASE
 //
@@ -937,6 +957,8 @@ func (q *Queries) ListProductItemsNextPage(ctx context.Context, arg ListProductI
 			&i.Description,
 			&i.CategoryID,
 			&i.BrandID,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.CategoryName,
 			&i.ParentCategoryID,
 			&i.CategoryImage,
@@ -1204,7 +1226,7 @@ func (q *Queries) ListProductItemsNextPageOld(ctx context.Context, arg ListProdu
 const listProductItemsV2 = `-- name: ListProductItemsV2 :many
 WITH t1 AS(
 SELECT 
- pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image, /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -1278,7 +1300,15 @@ ELSE 1=1
 END
 AND CASE
     WHEN COALESCE($5, 0) > 0 
-    THEN pc.id = $5
+    THEN p.category_id IN (
+        WITH RECURSIVE category_tree AS (
+            SELECT $5::BIGINT AS id
+            UNION
+            SELECT c.id FROM "product_category" AS c
+            INNER JOIN category_tree AS ct ON c.parent_category_id = ct.id
+        )
+        SELECT id FROM category_tree
+    )
     ELSE 1=1
 END 
 AND CASE
@@ -1350,6 +1380,8 @@ type ListProductItemsV2Row struct {
 	Description               string      `json:"description"`
 	CategoryID                int64       `json:"category_id"`
 	BrandID                   int64       `json:"brand_id"`
+	RatingAverage             float64     `json:"rating_average"`
+	RatingCount               int64       `json:"rating_count"`
 	CategoryName              null.String `json:"category_name"`
 	ParentCategoryID          null.Int    `json:"parent_category_id"`
 	CategoryImage             null.String `json:"category_image"`
@@ -1385,6 +1417,7 @@ type ListProductItemsV2Row struct {
 	NextAvailable             bool        `json:"next_available"`
 }
 
+// a parent category matches the items of all its descendant categories
 // LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 // AND CASE
 //
@@ -1433,6 +1466,8 @@ func (q *Queries) ListProductItemsV2(ctx context.Context, arg ListProductItemsV2
 			&i.Description,
 			&i.CategoryID,
 			&i.BrandID,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.CategoryName,
 			&i.ParentCategoryID,
 			&i.CategoryImage,
@@ -2732,9 +2767,19 @@ func (q *Queries) ListProductItemsWithPromotionsNextPage(ctx context.Context, ar
 }
 
 const searchProductItems = `-- name: SearchProductItems :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query($2::VARCHAR) AS tsq,
+ normalize_search_text($2::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image,   /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -2751,6 +2796,7 @@ SELECT
  COALESCE(ppromo.active, FALSE) AS product_promo_active
 FROM "product_item" AS pi
 INNER JOIN "product" AS p ON p.id = pi.product_id
+CROSS JOIN search AS s
 LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
 LEFT JOIN "product_color" AS pclr ON pclr.id = pi.color_id
 LEFT JOIN "product_promotion" AS pp ON pp.product_id = p.id 
@@ -2774,20 +2820,18 @@ LEFT JOIN (
 WHERE 
 pi.active = TRUE AND
 p.active =TRUE AND
-p.search @@ 
-CASE
-    WHEN char_length($2) > 0 THEN to_tsquery(concat($2, ':*'))
-    ELSE to_tsquery($2)
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 pi.id DESC,
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length($2) > 0 THEN to_tsquery(concat($2, ':*'))
-    ELSE to_tsquery($2)
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
@@ -2796,8 +2840,8 @@ LIMIT $1
 `
 
 type SearchProductItemsParams struct {
-	Limit int32       `json:"limit"`
-	Query interface{} `json:"query"`
+	Limit int32  `json:"limit"`
+	Query string `json:"query"`
 }
 
 type SearchProductItemsRow struct {
@@ -2814,6 +2858,8 @@ type SearchProductItemsRow struct {
 	Description               string      `json:"description"`
 	CategoryID                int64       `json:"category_id"`
 	BrandID                   int64       `json:"brand_id"`
+	RatingAverage             float64     `json:"rating_average"`
+	RatingCount               int64       `json:"rating_count"`
 	CategoryName              null.String `json:"category_name"`
 	ParentCategoryID          null.Int    `json:"parent_category_id"`
 	CategoryImage             null.String `json:"category_image"`
@@ -2873,6 +2919,8 @@ func (q *Queries) SearchProductItems(ctx context.Context, arg SearchProductItems
 			&i.Description,
 			&i.CategoryID,
 			&i.BrandID,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.CategoryName,
 			&i.ParentCategoryID,
 			&i.CategoryImage,
@@ -2918,9 +2966,19 @@ func (q *Queries) SearchProductItems(ctx context.Context, arg SearchProductItems
 }
 
 const searchProductItemsNextPage = `-- name: SearchProductItemsNextPage :many
-WITH t1 AS(
+WITH search AS (
+SELECT
+ q.tsq,
+ q.query_text,
+ EXISTS (SELECT 1 FROM "product" AS sp WHERE sp.active = TRUE AND sp.search @@ q.tsq) AS has_match
+FROM (
+SELECT
+ product_search_query($2::VARCHAR) AS tsq,
+ normalize_search_text($2::VARCHAR) AS query_text
+) AS q
+), t1 AS(
 SELECT 
- pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, pc.category_name, pc.parent_category_id,
+ pi.id, pi.product_id, pi.image_id, pi.color_id, pi.price, pi.created_at, pi.updated_at, pi.product_sku, pi.active, p.name, p.description, p.category_id, p.brand_id, p.rating_average, p.rating_count, pc.category_name, pc.parent_category_id,
  pc.category_image, pb.brand_name, pb.brand_image,  /*ps.size_value,*/
  pimg.product_image_1, pimg.product_image_2, pimg.product_image_3, pclr.color_value,
  cpromo.id AS category_promo_id, cpromo.name AS category_promo_name, cpromo.description AS category_promo_description,
@@ -2937,6 +2995,7 @@ SELECT
  COALESCE(ppromo.active, FALSE) AS product_promo_active
 FROM "product_item" AS pi
 INNER JOIN "product" AS p ON p.id = pi.product_id
+CROSS JOIN search AS s
 LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
 LEFT JOIN "product_color" AS pclr ON pclr.id = pi.color_id
 LEFT JOIN "product_promotion" AS pp ON pp.product_id = p.id 
@@ -2958,24 +3017,22 @@ LEFT JOIN (
 
 ) AS stock ON stock.product_item_id = pi.id  
 WHERE 
-(pi.id < $2
-OR (pi.id = $2 AND p.id < $3)) AND
+(pi.id < $3
+OR (pi.id = $3 AND p.id < $4)) AND
 pi.active = TRUE AND
 p.active =TRUE AND
-p.search @@ 
-CASE
-    WHEN char_length($4) > 0 THEN to_tsquery(concat($4, ':*'))
-    ELSE to_tsquery($4)
-END
+(p.search @@ s.tsq
+OR (NOT s.has_match
+AND (s.query_text <% normalize_search_text(p.name)
+OR s.query_text <% normalize_search_text(pb.brand_name)
+OR s.query_text <% normalize_search_text(pc.category_name))))
 ORDER BY 
 pi.id DESC,
 p.id DESC,
-ts_rank(p.search, 
 CASE
-    WHEN char_length($4) > 0 THEN to_tsquery(concat($4, ':*'))
-    ELSE to_tsquery($4)
-END
-) DESC
+    WHEN s.has_match THEN ts_rank(p.search, s.tsq)
+    ELSE word_similarity(s.query_text, normalize_search_text(p.name))
+END DESC
 LIMIT $1 +1
 )
 
@@ -2984,10 +3041,10 @@ LIMIT $1
 `
 
 type SearchProductItemsNextPageParams struct {
-	Limit         int32       `json:"limit"`
-	ProductItemID int64       `json:"product_item_id"`
-	ProductID     int64       `json:"product_id"`
-	Query         interface{} `json:"query"`
+	Limit         int32  `json:"limit"`
+	Query         string `json:"query"`
+	ProductItemID int64  `json:"product_item_id"`
+	ProductID     int64  `json:"product_id"`
 }
 
 type SearchProductItemsNextPageRow struct {
@@ -3004,6 +3061,8 @@ type SearchProductItemsNextPageRow struct {
 	Description               string      `json:"description"`
 	CategoryID                int64       `json:"category_id"`
 	BrandID                   int64       `json:"brand_id"`
+	RatingAverage             float64     `json:"rating_average"`
+	RatingCount               int64       `json:"rating_count"`
 	CategoryName              null.String `json:"category_name"`
 	ParentCategoryID          null.Int    `json:"parent_category_id"`
 	CategoryImage             null.String `json:"category_image"`
@@ -3043,9 +3102,9 @@ type SearchProductItemsNextPageRow struct {
 func (q *Queries) SearchProductItemsNextPage(ctx context.Context, arg SearchProductItemsNextPageParams) ([]*SearchProductItemsNextPageRow, error) {
 	rows, err := q.db.Query(ctx, searchProductItemsNextPage,
 		arg.Limit,
+		arg.Query,
 		arg.ProductItemID,
 		arg.ProductID,
-		arg.Query,
 	)
 	if err != nil {
 		return nil, err
@@ -3068,6 +3127,8 @@ func (q *Queries) SearchProductItemsNextPage(ctx context.Context, arg SearchProd
 			&i.Description,
 			&i.CategoryID,
 			&i.BrandID,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.CategoryName,
 			&i.ParentCategoryID,
 			&i.CategoryImage,
@@ -3153,24 +3214,18 @@ LEFT JOIN (
 WHERE pi.active = TRUE 
 AND p.active = TRUE
 AND p.search @@  
-CASE
-    WHEN char_length($3) > 0 THEN to_tsquery(concat($3, ':*'))
-    ELSE to_tsquery($3)
-END
+product_search_query($3::VARCHAR)
 AND pi.id < $2
 ORDER BY pi.id DESC, ts_rank(p.search, 
-CASE
-    WHEN char_length($3) > 0 THEN to_tsquery(concat($3, ':*'))
-    ELSE to_tsquery($3)
-END
+product_search_query($3::VARCHAR)
 ) DESC
 LIMIT $1
 `
 
 type SearchProductItemsNextPageOldParams struct {
-	Limit int32       `json:"limit"`
-	ID    int64       `json:"id"`
-	Query interface{} `json:"query"`
+	Limit int32  `json:"limit"`
+	ID    int64  `json:"id"`
+	Query string `json:"query"`
 }
 
 type SearchProductItemsNextPageOldRow struct {
@@ -3329,22 +3384,16 @@ LEFT JOIN (
 
 ) AS stock ON stock.product_item_id = pi.id
 WHERE pi.active = TRUE AND p.search @@ 
-CASE
-    WHEN char_length($2) > 0 THEN to_tsquery(concat($2, ':*'))
-    ELSE to_tsquery($2)
-END
+product_search_query($2::VARCHAR)
 ORDER BY pi.id DESC, ts_rank(p.search, 
-CASE
-    WHEN char_length($2) > 0 THEN to_tsquery(concat($2, ':*'))
-    ELSE to_tsquery($2)
-END
+product_search_query($2::VARCHAR)
 ) DESC
 LIMIT $1
 `
 
 type SearchProductItemsOldParams struct {
-	Limit int32       `json:"limit"`
-	Query interface{} `json:"query"`
+	Limit int32  `json:"limit"`
+	Query string `json:"query"`
 }
 
 type SearchProductItemsOldRow struct {
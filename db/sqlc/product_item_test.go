@@ -926,3 +926,43 @@ func TestListProductItemsWithBestSales(t *testing.T) {
 	require.NotEmpty(t, initialSearchResult)
 	require.Equal(t, len(initialSearchResult), 20)
 }
+
+func TestListProductItemsV2ByParentCategory(t *testing.T) {
+	parent := createRandomProductCategoryForUpdateOrDelete(t)
+	child := createRandomProductCategoryChild(t, parent.ID)
+	grandchild := createRandomProductCategoryChild(t, child.ID)
+
+	itemIDs := make([]int64, 0, 2)
+	for _, categoryID := range []int64{child.ID, grandchild.ID} {
+		product := createRandomProductInCategory(t, categoryID)
+		productItem, err := testStore.CreateProductItem(context.Background(), CreateProductItemParams{
+			ProductID:  product.ID,
+			ProductSku: util.RandomInt(100, 300),
+			ImageID:    createRandomProductImage(t).ID,
+			ColorID:    createRandomProductColor(t).ID,
+			Price:      util.RandomDecimalString(1, 100),
+			Active:     true,
+		})
+		require.NoError(t, err)
+		itemIDs = append(itemIDs, productItem.ID)
+	}
+
+	items, err := testStore.ListProductItemsV2(context.Background(), ListProductItemsV2Params{
+		Limit:      10,
+		CategoryID: parent.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	for _, item := range items {
+		require.Contains(t, itemIDs, item.ID)
+	}
+
+	// the filter doesn't go up the tree
+	items, err = testStore.ListProductItemsV2(context.Background(), ListProductItemsV2Params{
+		Limit:      10,
+		CategoryID: grandchild.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, itemIDs[1], items[0].ID)
+}
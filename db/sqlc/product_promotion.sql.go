@@ -267,7 +267,7 @@ func (q *Queries) ListProductPromotions(ctx context.Context, arg ListProductProm
 }
 
 const listProductPromotionsWithImages = `-- name: ListProductPromotionsWithImages :many
-SELECT pp.product_id, promotion_id, product_promotion_image, pp.active, p.id, category_id, brand_id, p.name, p.description, p.created_at, p.updated_at, p.active, search, promo.id, promo.name, promo.description, discount_rate, start_date, end_date, promo.active, pi.id, pi.product_id, image_id, color_id, price, pi.created_at, pi.updated_at, product_sku, pi.active FROM "product_promotion" AS pp
+SELECT pp.product_id, promotion_id, product_promotion_image, pp.active, p.id, category_id, brand_id, p.name, p.description, p.created_at, p.updated_at, p.active, search, rating_average, rating_count, promo.id, promo.name, promo.description, discount_rate, start_date, end_date, promo.active, pi.id, pi.product_id, image_id, color_id, price, pi.created_at, pi.updated_at, product_sku, pi.active FROM "product_promotion" AS pp
 LEFT JOIN "product" AS p ON p.id = pp.product_id
 JOIN "promotion" AS promo ON promo.id = pp.promotion_id AND promo.active = TRUE AND promo.start_date <= CURRENT_DATE AND promo.end_date >= CURRENT_DATE
 JOIN "product_item" AS pi ON pi.product_id = p.id AND pi.active =TRUE
@@ -289,6 +289,8 @@ type ListProductPromotionsWithImagesRow struct {
 	UpdatedAt             null.Time   `json:"updated_at"`
 	Active_2              null.Bool   `json:"active_2"`
 	Search                null.String `json:"search"`
+	RatingAverage         null.Float  `json:"rating_average"`
+	RatingCount           null.Int    `json:"rating_count"`
 	ID_2                  int64       `json:"id_2"`
 	Name_2                string      `json:"name_2"`
 	Description_2         string      `json:"description_2"`
@@ -330,6 +332,8 @@ func (q *Queries) ListProductPromotionsWithImages(ctx context.Context) ([]*ListP
 			&i.UpdatedAt,
 			&i.Active_2,
 			&i.Search,
+			&i.RatingAverage,
+			&i.RatingCount,
 			&i.ID_2,
 			&i.Name_2,
 			&i.Description_2,
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProductItemsFaceted(t *testing.T) {
+	productItem := createRandomProductItem(t)
+	product, err := testStore.GetProduct(context.Background(), productItem.ProductID)
+	require.NoError(t, err)
+
+	arg := SearchProductItemsFacetedParams{
+		Limit:       10,
+		Offset:      0,
+		BrandIds:    []int64{product.BrandID},
+		CategoryIds: []int64{},
+		ColorIds:    []int64{productItem.ColorID},
+		Sizes:       []string{},
+	}
+
+	productItems, err := testStore.SearchProductItemsFaceted(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, productItems)
+	for _, item := range productItems {
+		require.Equal(t, productItem.ID, item.ID)
+		require.Equal(t, product.BrandID, item.BrandID)
+	}
+
+	// another color of the brand matches nothing
+	arg.ColorIds = []int64{createRandomProductColor(t).ID}
+	productItems, err = testStore.SearchProductItemsFaceted(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, productItems)
+}
+
+func TestListProductItemFacets(t *testing.T) {
+	// an item of another brand
+	createRandomProductItem(t)
+	productItem := createRandomProductItem(t)
+	product, err := testStore.GetProduct(context.Background(), productItem.ProductID)
+	require.NoError(t, err)
+
+	facets, err := testStore.ListProductItemFacets(context.Background(), ListProductItemFacetsParams{
+		BrandIds:    []int64{product.BrandID},
+		CategoryIds: []int64{},
+		ColorIds:    []int64{},
+		Sizes:       []string{},
+		PriceBounds: []string{"50", "100"},
+	})
+	require.NoError(t, err)
+
+	counts := map[string]map[int64]int64{}
+	for _, facet := range facets {
+		if counts[facet.Facet] == nil {
+			counts[facet.Facet] = map[int64]int64{}
+		}
+		counts[facet.Facet][facet.ValueID] += facet.ItemCount
+	}
+
+	// the category and the color are counted under the brand filter
+	require.Equal(t, int64(1), counts["category"][product.CategoryID])
+	require.Equal(t, int64(1), counts["color"][productItem.ColorID])
+
+	// the brand facet ignores its own filter
+	require.Equal(t, int64(1), counts["brand"][product.BrandID])
+	require.Greater(t, len(counts["brand"]), 1)
+
+	var priced int64
+	for _, count := range counts["price"] {
+		priced += count
+	}
+	require.Equal(t, int64(1), priced)
+}
+
+func TestSearchProductItemsFacetedMultilingual(t *testing.T) {
+	productItem := createRandomProductItem(t)
+	name := util.RandomString(10)
+	product, err := testStore.UpdateProduct(context.Background(), UpdateProductParams{
+		ID:          productItem.ProductID,
+		Name:        null.StringFrom("قميص أحمر " + name),
+		Description: null.StringFrom("قُمصان"),
+	})
+	require.NoError(t, err)
+
+	brand, err := testStore.GetProductBrand(context.Background(), product.BrandID)
+	require.NoError(t, err)
+
+	search := func(query string) []*SearchProductItemsFacetedRow {
+		productItems, err := testStore.SearchProductItemsFaceted(context.Background(), SearchProductItemsFacetedParams{
+			Limit:       10,
+			Offset:      0,
+			Query:       query,
+			BrandIds:    []int64{product.BrandID},
+			CategoryIds: []int64{product.CategoryID},
+			ColorIds:    []int64{},
+			Sizes:       []string{},
+		})
+		require.NoError(t, err)
+		return productItems
+	}
+
+	typo := name[:9] + "x"
+	if name[9] == 'x' {
+		typo = name[:9] + "y"
+	}
+
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		// the hamza and the diacritics are normalised on both sides
+		{name: "ArabicWithoutHamza", query: "احمر"},
+		{name: "ArabicWithDiacritics", query: "قمصان"},
+		{name: "BrandName", query: brand.BrandName},
+		{name: "Operators", query: name + " & | \"-"},
+		{name: "Misspelled", query: typo},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			productItems := search(tc.query)
+			require.NotEmpty(t, productItems)
+
+			var found bool
+			for _, item := range productItems {
+				if item.ID == productItem.ID {
+					found = true
+				}
+			}
+			require.True(t, found)
+		})
+	}
+}
@@ -273,6 +273,30 @@ func (q *Queries) ListProductSizesByProductItemID(ctx context.Context, productIt
 	return items, nil
 }
 
+const restockProductSize = `-- name: RestockProductSize :one
+UPDATE "product_size"
+SET qty = qty + $1
+WHERE id = $2
+RETURNING id, product_item_id, size_value, qty
+`
+
+type RestockProductSizeParams struct {
+	Qty int32 `json:"qty"`
+	ID  int64 `json:"id"`
+}
+
+func (q *Queries) RestockProductSize(ctx context.Context, arg RestockProductSizeParams) (*ProductSize, error) {
+	row := q.db.QueryRow(ctx, restockProductSize, arg.Qty, arg.ID)
+	var i ProductSize
+	err := row.Scan(
+		&i.ID,
+		&i.ProductItemID,
+		&i.SizeValue,
+		&i.Qty,
+	)
+	return &i, err
+}
+
 const updateProductSize = `-- name: UpdateProductSize :one
 UPDATE "product_size"
 SET 
@@ -14,6 +14,7 @@ import (
 type Querier interface {
 	AdminCreateBrandPromotion(ctx context.Context, arg AdminCreateBrandPromotionParams) (*BrandPromotion, error)
 	AdminCreateCategoryPromotion(ctx context.Context, arg AdminCreateCategoryPromotionParams) (*CategoryPromotion, error)
+	AdminCreateCoupon(ctx context.Context, arg AdminCreateCouponParams) (*Coupon, error)
 	AdminCreateFeaturedProductItem(ctx context.Context, arg AdminCreateFeaturedProductItemParams) (*FeaturedProductItem, error)
 	AdminCreatePaymentType(ctx context.Context, arg AdminCreatePaymentTypeParams) (*PaymentType, error)
 	AdminCreateProduct(ctx context.Context, arg AdminCreateProductParams) (*Product, error)
@@ -28,10 +29,14 @@ type Querier interface {
 	AdminCreateProductSize(ctx context.Context, arg AdminCreateProductSizeParams) (*ProductSize, error)
 	AdminCreatePromotion(ctx context.Context, arg AdminCreatePromotionParams) (*Promotion, error)
 	AdminCreateShippingMethod(ctx context.Context, arg AdminCreateShippingMethodParams) (*ShippingMethod, error)
+	AdminDeleteCoupon(ctx context.Context, arg AdminDeleteCouponParams) (*Coupon, error)
 	AdminDeletePaymentType(ctx context.Context, arg AdminDeletePaymentTypeParams) error
 	AdminDeleteProduct(ctx context.Context, arg AdminDeleteProductParams) error
+	AdminGetCoupon(ctx context.Context, arg AdminGetCouponParams) (*Coupon, error)
+	AdminHasPermission(ctx context.Context, arg AdminHasPermissionParams) (bool, error)
 	AdminListBrandPromotions(ctx context.Context, adminID int64) ([]*AdminListBrandPromotionsRow, error)
 	AdminListCategoryPromotions(ctx context.Context, adminID int64) ([]*AdminListCategoryPromotionsRow, error)
+	AdminListCoupons(ctx context.Context, arg AdminListCouponsParams) ([]*Coupon, error)
 	AdminListFeaturedProductItems(ctx context.Context, adminID int64) ([]*AdminListFeaturedProductItemsRow, error)
 	// ORDER BY id
 	// LIMIT $1
@@ -42,11 +47,13 @@ type Querier interface {
 	// OFFSET $2;
 	AdminListPaymentTypes(ctx context.Context, adminID int64) ([]*PaymentType, error)
 	AdminListProductPromotions(ctx context.Context, adminID int64) ([]*AdminListProductPromotionsRow, error)
+	AdminListReturnRequests(ctx context.Context, arg AdminListReturnRequestsParams) ([]*ReturnRequest, error)
 	AdminListShopOrdersNextPage(ctx context.Context, arg AdminListShopOrdersNextPageParams) ([]*AdminListShopOrdersNextPageRow, error)
 	AdminListShopOrdersV2(ctx context.Context, arg AdminListShopOrdersV2Params) ([]*AdminListShopOrdersV2Row, error)
 	AdminSearchUserByEmail(ctx context.Context, email string) ([]*AdminSearchUserByEmailRow, error)
 	AdminUpdateBrandPromotion(ctx context.Context, arg AdminUpdateBrandPromotionParams) (*BrandPromotion, error)
 	AdminUpdateCategoryPromotion(ctx context.Context, arg AdminUpdateCategoryPromotionParams) (*CategoryPromotion, error)
+	AdminUpdateCoupon(ctx context.Context, arg AdminUpdateCouponParams) (*Coupon, error)
 	AdminUpdateFeaturedProductItem(ctx context.Context, arg AdminUpdateFeaturedProductItemParams) (*FeaturedProductItem, error)
 	AdminUpdatePaymentType(ctx context.Context, arg AdminUpdatePaymentTypeParams) (*PaymentType, error)
 	// product_image = COALESCE(sqlc.narg(product_image),product_image),
@@ -59,19 +66,34 @@ type Querier interface {
 	AdminUpdateProductPromotion(ctx context.Context, arg AdminUpdateProductPromotionParams) (*ProductPromotion, error)
 	AdminUpdateProductSize(ctx context.Context, arg AdminUpdateProductSizeParams) (*ProductSize, error)
 	AdminUpdatePromotion(ctx context.Context, arg AdminUpdatePromotionParams) (*Promotion, error)
+	AdminUpdateReturnRequest(ctx context.Context, arg AdminUpdateReturnRequestParams) (*ReturnRequest, error)
 	AdminUpdateShippingMethod(ctx context.Context, arg AdminUpdateShippingMethodParams) (*ShippingMethod, error)
 	AdminUpdateUser(ctx context.Context, arg AdminUpdateUserParams) (*User, error)
+	// the row is kept for the orders of the user, the email is freed for a new account
+	AnonymizeUser(ctx context.Context, id int64) (*User, error)
+	CancelUserDeletion(ctx context.Context, userID int64) (*UserDeletion, error)
+	CountAdminRecoveryCodesLeft(ctx context.Context, adminID int64) (int64, error)
+	CountCouponRedemptionsByUser(ctx context.Context, arg CountCouponRedemptionsByUserParams) (int64, error)
+	CountUserReviewImages(ctx context.Context, reviewID int64) (int64, error)
 	CreateAddress(ctx context.Context, arg CreateAddressParams) (*Address, error)
 	CreateAdmin(ctx context.Context, arg CreateAdminParams) (*Admin, error)
+	CreateAdminLoginChallenge(ctx context.Context, arg CreateAdminLoginChallengeParams) (*AdminLoginChallenge, error)
+	CreateAdminRole(ctx context.Context, arg CreateAdminRoleParams) (*AdminRole, error)
+	CreateAdminRoleAssignment(ctx context.Context, arg CreateAdminRoleAssignmentParams) (*AdminRoleAssignment, error)
+	CreateAdminRolePermission(ctx context.Context, arg CreateAdminRolePermissionParams) (*AdminRolePermission, error)
 	CreateAdminSession(ctx context.Context, arg CreateAdminSessionParams) (*AdminSession, error)
 	CreateAdminType(ctx context.Context, adminType string) (*AdminType, error)
 	CreateAppPolicy(ctx context.Context, arg CreateAppPolicyParams) (*AppPolicy, error)
 	CreateBrandPromotion(ctx context.Context, arg CreateBrandPromotionParams) (*BrandPromotion, error)
 	CreateCategoryPromotion(ctx context.Context, arg CreateCategoryPromotionParams) (*CategoryPromotion, error)
+	CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) (*CouponRedemption, error)
+	CreateEmailChange(ctx context.Context, arg CreateEmailChangeParams) (*EmailChange, error)
 	CreateHomePageTextBanner(ctx context.Context, arg CreateHomePageTextBannerParams) (*HomePageTextBanner, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (*IdempotencyKey, error)
 	CreateNotification(ctx context.Context, arg CreateNotificationParams) (*Notification, error)
 	CreateOrderStatus(ctx context.Context, status string) (*OrderStatus, error)
 	CreatePaymentMethod(ctx context.Context, arg CreatePaymentMethodParams) (*PaymentMethod, error)
+	CreatePaymentTransaction(ctx context.Context, arg CreatePaymentTransactionParams) (*PaymentTransaction, error)
 	CreatePaymentType(ctx context.Context, value string) (*PaymentType, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (*Product, error)
 	CreateProductBrand(ctx context.Context, arg CreateProductBrandParams) (*ProductBrand, error)
@@ -86,31 +108,54 @@ type Querier interface {
 	CreateProductSize(ctx context.Context, arg CreateProductSizeParams) (*ProductSize, error)
 	CreatePromotion(ctx context.Context, arg CreatePromotionParams) (*Promotion, error)
 	CreateResetPassword(ctx context.Context, arg CreateResetPasswordParams) (*ResetPassword, error)
+	CreateReturnRequest(ctx context.Context, arg CreateReturnRequestParams) (*ReturnRequest, error)
+	CreateReturnRequestItem(ctx context.Context, arg CreateReturnRequestItemParams) (*ReturnRequestItem, error)
+	CreateSearchQueryLog(ctx context.Context, arg CreateSearchQueryLogParams) error
 	CreateShippingMethod(ctx context.Context, arg CreateShippingMethodParams) (*ShippingMethod, error)
 	CreateShopOrder(ctx context.Context, arg CreateShopOrderParams) (*ShopOrder, error)
 	CreateShopOrderItem(ctx context.Context, arg CreateShopOrderItemParams) (*ShopOrderItem, error)
+	CreateShopOrderStatusHistory(ctx context.Context, arg CreateShopOrderStatusHistoryParams) (*ShopOrderStatusHistory, error)
 	CreateShoppingCart(ctx context.Context, userID int64) (*ShoppingCart, error)
 	CreateShoppingCartItem(ctx context.Context, arg CreateShoppingCartItemParams) (*ShoppingCartItem, error)
+	CreateStockReservation(ctx context.Context, arg CreateStockReservationParams) (*StockReservation, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (*User, error)
 	CreateUserReview(ctx context.Context, arg CreateUserReviewParams) (*UserReview, error)
+	// a new image sends an approved review back to the moderation
+	CreateUserReviewImage(ctx context.Context, arg CreateUserReviewImageParams) (*UserReviewImage, error)
 	CreateUserSession(ctx context.Context, arg CreateUserSessionParams) (*UserSession, error)
 	CreateUserWithCartAndWishList(ctx context.Context, arg CreateUserWithCartAndWishListParams) (*CreateUserWithCartAndWishListRow, error)
 	CreateVariation(ctx context.Context, arg CreateVariationParams) (*Variation, error)
 	CreateVariationOption(ctx context.Context, arg CreateVariationOptionParams) (*VariationOption, error)
+	// the email of a social login is verified by its provider
+	CreateVerifiedUserWithCartAndWishList(ctx context.Context, arg CreateVerifiedUserWithCartAndWishListParams) (*CreateVerifiedUserWithCartAndWishListRow, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (*VerifyEmail, error)
 	CreateWishList(ctx context.Context, userID int64) (*WishList, error)
 	CreateWishListItem(ctx context.Context, arg CreateWishListItemParams) (*WishListItem, error)
+	DecrementCouponUsedCount(ctx context.Context, id int64) (*Coupon, error)
 	DeleteAddress(ctx context.Context, id int64) error
+	DeleteAddressesByUserID(ctx context.Context, userID int64) (int64, error)
 	DeleteAdmin(ctx context.Context, id int64) error
+	DeleteAdminRole(ctx context.Context, id int64) (*AdminRole, error)
+	DeleteAdminRoleAssignmentsByAdminID(ctx context.Context, adminID int64) error
+	DeleteAdminRolePermissionsByRoleID(ctx context.Context, roleID int64) error
+	DeleteAdminTotp(ctx context.Context, adminID int64) error
 	DeleteAdminTypeByID(ctx context.Context, id int64) error
 	DeleteAdminTypeByType(ctx context.Context, adminType string) error
 	DeleteAppPolicy(ctx context.Context, arg DeleteAppPolicyParams) (*AppPolicy, error)
 	DeleteBrandPromotion(ctx context.Context, arg DeleteBrandPromotionParams) error
 	DeleteCategoryPromotion(ctx context.Context, arg DeleteCategoryPromotionParams) error
+	// the redemption of a cancelled or returned order doesn't count against the coupon limits anymore
+	DeleteCouponRedemptionByShopOrderID(ctx context.Context, shopOrderID int64) (*CouponRedemption, error)
+	DeleteEmailChangesByUserID(ctx context.Context, userID int64) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteExpiredStockReservations(ctx context.Context) (int64, error)
 	DeleteFeaturedProductItem(ctx context.Context, arg DeleteFeaturedProductItemParams) error
 	DeleteHomePageTextBanner(ctx context.Context, arg DeleteHomePageTextBannerParams) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
 	DeleteNotification(ctx context.Context, arg DeleteNotificationParams) (*Notification, error)
 	DeleteNotificationAllByUser(ctx context.Context, userID int64) error
+	// the searches older than 90 days, the suggestions only look back 30 days
+	DeleteOldSearchQueryLogs(ctx context.Context) (int64, error)
 	DeleteOrderStatus(ctx context.Context, id int64) error
 	DeletePaymentMethod(ctx context.Context, arg DeletePaymentMethodParams) (*PaymentMethod, error)
 	DeletePaymentType(ctx context.Context, id int64) error
@@ -125,18 +170,22 @@ type Querier interface {
 	DeleteProductSize(ctx context.Context, id int64) error
 	DeleteProductSizeByProductItemID(ctx context.Context, productItemID int64) error
 	DeletePromotion(ctx context.Context, id int64) error
+	DeleteResetPasswordsByUserID(ctx context.Context, userID int64) error
 	DeleteShippingMethod(ctx context.Context, id int64) error
 	DeleteShopOrder(ctx context.Context, id int64) error
 	DeleteShopOrderItem(ctx context.Context, arg DeleteShopOrderItemParams) (*ShopOrderItem, error)
 	DeleteShoppingCart(ctx context.Context, id int64) error
 	DeleteShoppingCartItem(ctx context.Context, arg DeleteShoppingCartItemParams) error
 	DeleteShoppingCartItemAllByUser(ctx context.Context, arg DeleteShoppingCartItemAllByUserParams) ([]*ShoppingCartItem, error)
+	DeleteStockReservationsByCartID(ctx context.Context, shoppingCartID int64) (int64, error)
 	DeleteUser(ctx context.Context, id int64) (*User, error)
 	DeleteUserAddress(ctx context.Context, arg DeleteUserAddressParams) (*Address, error)
 	DeleteUserByEmailNotVerified(ctx context.Context, email string) error
 	DeleteUserReview(ctx context.Context, arg DeleteUserReviewParams) (*UserReview, error)
+	DeleteUserSessions(ctx context.Context, userID int64) (int64, error)
 	DeleteVariation(ctx context.Context, id int64) error
 	DeleteVariationOption(ctx context.Context, id int64) error
+	DeleteVerifyEmailsByUserID(ctx context.Context, userID null.Int) error
 	DeleteWishList(ctx context.Context, id int64) error
 	// WITH t1 AS (
 	//   SELECT id FROM "wish_list" AS wl
@@ -147,28 +196,43 @@ type Querier interface {
 	//   SELECT id FROM "wish_list" WHERE user_id = $1
 	// )
 	DeleteWishListItemAll(ctx context.Context, wishListID int64) ([]*WishListItem, error)
+	EnableAdminTotp(ctx context.Context, arg EnableAdminTotpParams) (*AdminTotp, error)
 	GetActiveProductItems(ctx context.Context, adminID int64) (int64, error)
 	GetActiveUsersCount(ctx context.Context, adminID int64) (int64, error)
 	GetAddress(ctx context.Context, id int64) (*Address, error)
 	GetAddressByCity(ctx context.Context, city string) (*Address, error)
 	GetAdmin(ctx context.Context, id int64) (*Admin, error)
 	GetAdminByEmail(ctx context.Context, email string) (*Admin, error)
+	GetAdminLoginChallenge(ctx context.Context, tokenHash string) (*AdminLoginChallenge, error)
+	GetAdminRole(ctx context.Context, id int64) (*AdminRole, error)
+	GetAdminRoleForUpdate(ctx context.Context, id int64) (*AdminRole, error)
+	GetAdminSecuritySetting(ctx context.Context) (*AdminSecuritySetting, error)
 	GetAdminSession(ctx context.Context, id uuid.UUID) (*AdminSession, error)
+	GetAdminTotp(ctx context.Context, adminID int64) (*AdminTotp, error)
 	GetAdminType(ctx context.Context, id int64) (*AdminType, error)
 	GetAppPolicy(ctx context.Context) (*AppPolicy, error)
 	GetBrandPromotion(ctx context.Context, arg GetBrandPromotionParams) (*BrandPromotion, error)
 	GetCategoryPromotion(ctx context.Context, arg GetCategoryPromotionParams) (*CategoryPromotion, error)
 	GetCompletedDailyOrderTotal(ctx context.Context, adminID int64) (string, error)
+	GetCouponByCodeForUpdate(ctx context.Context, code string) (*Coupon, error)
+	GetCouponRedemptionByShopOrderID(ctx context.Context, shopOrderID int64) (*CouponRedemption, error)
 	GetFeaturedProductItem(ctx context.Context, productItemID int64) (*FeaturedProductItem, error)
 	GetHomePageTextBanner(ctx context.Context, id int64) (*HomePageTextBanner, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (*IdempotencyKey, error)
+	GetLastEmailChange(ctx context.Context, userID int64) (*EmailChange, error)
+	GetLastPaymentTransactionForUpdate(ctx context.Context, shopOrderID int64) (*PaymentTransaction, error)
 	// AND secret_code = $2
 	GetLastUsedResetPassword(ctx context.Context, email string) (*ResetPassword, error)
 	GetNotification(ctx context.Context, arg GetNotificationParams) (*Notification, error)
 	GetNotificationV2(ctx context.Context, userID int64) (*Notification, error)
 	GetOrderStatus(ctx context.Context, id int64) (*OrderStatus, error)
+	GetOrderStatusByCode(ctx context.Context, code null.String) (*OrderStatus, error)
 	GetOrderStatusByUserID(ctx context.Context, arg GetOrderStatusByUserIDParams) (*GetOrderStatusByUserIDRow, error)
 	// id = $1
 	GetPaymentMethod(ctx context.Context, arg GetPaymentMethodParams) (*PaymentMethod, error)
+	GetPaymentTransaction(ctx context.Context, id int64) (*PaymentTransaction, error)
+	GetPaymentTransactionByProviderReference(ctx context.Context, arg GetPaymentTransactionByProviderReferenceParams) (*PaymentTransaction, error)
+	GetPaymentTransactionForUpdate(ctx context.Context, id int64) (*PaymentTransaction, error)
 	GetPaymentType(ctx context.Context, id int64) (*PaymentType, error)
 	GetProduct(ctx context.Context, id int64) (*Product, error)
 	GetProductBrand(ctx context.Context, id int64) (*ProductBrand, error)
@@ -185,14 +249,22 @@ type Querier interface {
 	GetProductSize(ctx context.Context, id int64) (*ProductSize, error)
 	GetProductsByIDs(ctx context.Context, ids []int64) ([]*Product, error)
 	GetPromotion(ctx context.Context, id int64) (*Promotion, error)
+	GetReservedQtyBySizeIDForOtherCarts(ctx context.Context, arg GetReservedQtyBySizeIDForOtherCartsParams) (int64, error)
 	GetResetPassword(ctx context.Context, id int64) (*ResetPassword, error)
-	GetResetPasswordUserIDByID(ctx context.Context, arg GetResetPasswordUserIDByIDParams) (int64, error)
 	GetResetPasswordsByEmail(ctx context.Context, email string) (*GetResetPasswordsByEmailRow, error)
+	GetReturnRequestByUserID(ctx context.Context, arg GetReturnRequestByUserIDParams) (*ReturnRequest, error)
+	GetReturnRequestForUpdate(ctx context.Context, id int64) (*ReturnRequest, error)
+	GetReturnedQtyByShopOrderItemID(ctx context.Context, shopOrderItemID int64) (int64, error)
+	// the product name, brand, category or past query with results most similar to the query,
+	// pgx.ErrNoRows when none is similar enough
+	GetSearchCorrection(ctx context.Context, query string) (string, error)
 	GetShippingMethod(ctx context.Context, id int64) (*ShippingMethod, error)
 	GetShippingMethodByUserID(ctx context.Context, arg GetShippingMethodByUserIDParams) (*GetShippingMethodByUserIDRow, error)
 	GetShopOrder(ctx context.Context, id int64) (*ShopOrder, error)
+	GetShopOrderForUpdate(ctx context.Context, id int64) (*ShopOrder, error)
 	GetShopOrderItem(ctx context.Context, id int64) (*ShopOrderItem, error)
 	GetShopOrderItemByUserIDOrderID(ctx context.Context, arg GetShopOrderItemByUserIDOrderIDParams) (*GetShopOrderItemByUserIDOrderIDRow, error)
+	GetShopOrdersCountByStatusCodes(ctx context.Context, arg GetShopOrdersCountByStatusCodesParams) (int64, error)
 	GetShopOrdersCountByStatusId(ctx context.Context, arg GetShopOrdersCountByStatusIdParams) (int64, error)
 	GetShoppingCart(ctx context.Context, id int64) (*ShoppingCart, error)
 	GetShoppingCartByUserIDCartID(ctx context.Context, arg GetShoppingCartByUserIDCartIDParams) (*ShoppingCart, error)
@@ -206,6 +278,7 @@ type Querier interface {
 	// SELECT * FROM "user"
 	// WHERE email = $1 LIMIT 1;
 	GetUserByEmail(ctx context.Context, email string) (*GetUserByEmailRow, error)
+	GetUserDeletion(ctx context.Context, userID int64) (*UserDeletion, error)
 	GetUserReview(ctx context.Context, arg GetUserReviewParams) (*UserReview, error)
 	GetUserSession(ctx context.Context, id uuid.UUID) (*UserSession, error)
 	GetVariation(ctx context.Context, id int64) (*Variation, error)
@@ -216,9 +289,24 @@ type Querier interface {
 	GetWishListByUserID(ctx context.Context, userID int64) (*WishList, error)
 	GetWishListItem(ctx context.Context, id int64) (*WishListItem, error)
 	GetWishListItemByUserIDCartID(ctx context.Context, arg GetWishListItemByUserIDCartIDParams) (*WishListItem, error)
+	IncrementAdminLoginChallengeAttempts(ctx context.Context, arg IncrementAdminLoginChallengeAttemptsParams) (*AdminLoginChallenge, error)
+	IncrementCouponUsedCount(ctx context.Context, id int64) (*Coupon, error)
+	IncrementEmailChangeAttempts(ctx context.Context, arg IncrementEmailChangeAttemptsParams) (*EmailChange, error)
+	IncrementResetPasswordAttempts(ctx context.Context, arg IncrementResetPasswordAttemptsParams) (*ResetPassword, error)
+	IncrementVerifyEmailAttempts(ctx context.Context, arg IncrementVerifyEmailAttemptsParams) (*VerifyEmail, error)
+	IsAdminSessionActive(ctx context.Context, arg IsAdminSessionActiveParams) (bool, error)
+	// whether the category is the root category or one of its descendants
+	IsProductCategoryInSubtree(ctx context.Context, arg IsProductCategoryInSubtreeParams) (bool, error)
+	IsUserSessionActive(ctx context.Context, arg IsUserSessionActiveParams) (bool, error)
+	ListActiveAdminSessions(ctx context.Context, adminID int64) ([]*AdminSession, error)
+	ListActiveUserSessions(ctx context.Context, userID int64) ([]*UserSession, error)
 	ListAddressesByCity(ctx context.Context, arg ListAddressesByCityParams) ([]*Address, error)
 	ListAddressesByID(ctx context.Context, addressesIds []int64) ([]*Address, error)
 	ListAddressesByUserID(ctx context.Context, id int64) ([]*ListAddressesByUserIDRow, error)
+	ListAdminPermissions(ctx context.Context) ([]*AdminPermission, error)
+	ListAdminRolePermissionsByRoleIDs(ctx context.Context, roleIds []int64) ([]*AdminRolePermission, error)
+	ListAdminRoles(ctx context.Context, arg ListAdminRolesParams) ([]*AdminRole, error)
+	ListAdminRolesByAdminID(ctx context.Context, adminID int64) ([]*AdminRole, error)
 	ListAdminTypes(ctx context.Context, arg ListAdminTypesParams) ([]*AdminType, error)
 	ListAdmins(ctx context.Context, arg ListAdminsParams) ([]*Admin, error)
 	ListAllUsers(ctx context.Context, arg ListAllUsersParams) ([]*User, error)
@@ -226,25 +314,37 @@ type Querier interface {
 	ListBrandPromotionsWithImages(ctx context.Context) ([]*ListBrandPromotionsWithImagesRow, error)
 	ListCategoryPromotions(ctx context.Context, arg ListCategoryPromotionsParams) ([]*CategoryPromotion, error)
 	ListCategoryPromotionsWithImages(ctx context.Context) ([]*ListCategoryPromotionsWithImagesRow, error)
+	ListDueUserDeletions(ctx context.Context, limit int32) ([]int64, error)
 	ListFeaturedProductItems(ctx context.Context, arg ListFeaturedProductItemsParams) ([]*FeaturedProductItem, error)
 	ListHomePageTextBanners(ctx context.Context) ([]*HomePageTextBanner, error)
 	ListOrderStatuses(ctx context.Context) ([]*OrderStatus, error)
 	ListOrderStatusesByUserID(ctx context.Context, arg ListOrderStatusesByUserIDParams) ([]*ListOrderStatusesByUserIDRow, error)
 	ListPaymentMethods(ctx context.Context, arg ListPaymentMethodsParams) ([]*PaymentMethod, error)
+	ListPaymentTransactionsByShopOrderID(ctx context.Context, shopOrderID int64) ([]*PaymentTransaction, error)
+	ListPaymentTransactionsWithPendingRefund(ctx context.Context, limit int32) ([]*PaymentTransaction, error)
 	ListPaymentTypes(ctx context.Context) ([]*PaymentType, error)
 	ListProductBrands(ctx context.Context) ([]*ProductBrand, error)
 	ListProductCategories(ctx context.Context) ([]*ProductCategory, error)
 	// LIMIT $1
 	// OFFSET $2;
 	ListProductCategoriesByParent(ctx context.Context, parentCategoryID null.Int) ([]*ProductCategory, error)
+	// the ancestors of the category from the root down to the category itself
+	// stops on a cycle written to the table outside of MoveProductCategoryTx
+	ListProductCategoryBreadcrumbs(ctx context.Context, id int64) ([]*ListProductCategoryBreadcrumbsRow, error)
+	// every category with the number of its active products, the tree is assembled from the parent ids
+	ListProductCategoryTree(ctx context.Context) ([]*ListProductCategoryTreeRow, error)
 	ListProductColors(ctx context.Context) ([]*ProductColor, error)
 	ListProductConfigurations(ctx context.Context, arg ListProductConfigurationsParams) ([]*ProductConfiguration, error)
 	ListProductImagesNextPage(ctx context.Context, arg ListProductImagesNextPageParams) ([]*ListProductImagesNextPageRow, error)
 	ListProductImagesV2(ctx context.Context, limit int32) ([]*ListProductImagesV2Row, error)
+	// each facet is counted under every filter but its own, so the other values of a facet stay selectable,
+	// the price facet counts the items per bucket of width_bucket over price_bounds
+	ListProductItemFacets(ctx context.Context, arg ListProductItemFacetsParams) ([]*ListProductItemFacetsRow, error)
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	ListProductItems(ctx context.Context, arg ListProductItemsParams) ([]*ListProductItemsRow, error)
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	ListProductItemsByIDs(ctx context.Context, productsIds []int64) ([]*ListProductItemsByIDsRow, error)
+	// a parent category matches the items of all its descendant categories
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	// AND CASE
 	//     WHEN COALESCE(sqlc.narg(size_id), 0) > 0
@@ -263,6 +363,7 @@ type Querier interface {
 	// )
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	ListProductItemsNextPageOld(ctx context.Context, arg ListProductItemsNextPageOldParams) ([]*ListProductItemsNextPageOldRow, error)
+	// a parent category matches the items of all its descendant categories
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	// AND CASE
 	//     WHEN COALESCE(sqlc.narg(size_id), 0) > 0
@@ -297,6 +398,8 @@ type Querier interface {
 	ListProductItemsWithPromotionsNextPage(ctx context.Context, arg ListProductItemsWithPromotionsNextPageParams) ([]*ListProductItemsWithPromotionsNextPageRow, error)
 	ListProductPromotions(ctx context.Context, arg ListProductPromotionsParams) ([]*ProductPromotion, error)
 	ListProductPromotionsWithImages(ctx context.Context) ([]*ListProductPromotionsWithImagesRow, error)
+	// the approved reviews of a product, a review is a verified purchase when its ordered product was delivered to the reviewer
+	ListProductReviews(ctx context.Context, arg ListProductReviewsParams) ([]*ListProductReviewsRow, error)
 	ListProductSizes(ctx context.Context) ([]*ProductSize, error)
 	// JOIN "shopping_cart_item" AS sci ON sci.size_id = ps.product_item_id
 	ListProductSizesByIDs(ctx context.Context, sizesIds []int64) ([]*ProductSize, error)
@@ -310,12 +413,21 @@ type Querier interface {
 	ListProductsNextPage(ctx context.Context, arg ListProductsNextPageParams) ([]*ListProductsNextPageRow, error)
 	ListProductsV2(ctx context.Context, limit int32) ([]*ListProductsV2Row, error)
 	ListPromotions(ctx context.Context) ([]*Promotion, error)
+	ListReturnRequestItemsByRequestID(ctx context.Context, returnRequestID int64) ([]*ListReturnRequestItemsByRequestIDRow, error)
+	ListReturnRequestsByUserID(ctx context.Context, arg ListReturnRequestsByUserIDParams) ([]*ReturnRequest, error)
+	// the most searched queries since the given time, with zero_results only the queries that never found anything
+	ListSearchQueryStats(ctx context.Context, arg ListSearchQueryStatsParams) ([]*ListSearchQueryStatsRow, error)
+	// the product names match the words being typed as prefixes, the brands and categories match the query
+	// anywhere in their name or by trigram similarity, the past queries starting with the query that found results
+	// in the last 30 days are ranked by how often they were searched
+	ListSearchSuggestions(ctx context.Context, arg ListSearchSuggestionsParams) ([]*ListSearchSuggestionsRow, error)
 	ListShippingMethods(ctx context.Context) ([]*ShippingMethod, error)
 	// ORDER BY id
 	// LIMIT $1
 	// OFFSET $2;
 	ListShippingMethodsByUserID(ctx context.Context, arg ListShippingMethodsByUserIDParams) ([]*ListShippingMethodsByUserIDRow, error)
 	ListShopOrderItems(ctx context.Context, arg ListShopOrderItemsParams) ([]*ShopOrderItem, error)
+	ListShopOrderItemsByOrderID(ctx context.Context, orderID int64) ([]*ShopOrderItem, error)
 	// ORDER BY soi.id;
 	ListShopOrderItemsByUserID(ctx context.Context, arg ListShopOrderItemsByUserIDParams) ([]*ListShopOrderItemsByUserIDRow, error)
 	// SELECT * FROM "shop_order_item"
@@ -326,18 +438,28 @@ type Querier interface {
 	// LEFT JOIN "payment_method" AS pm ON pm.id = so.payment_method_id
 	// LEFT JOIN "shipping_method" AS sm ON sm.id = so.shipping_method_id
 	ListShopOrderItemsByUserIDOrderID(ctx context.Context, arg ListShopOrderItemsByUserIDOrderIDParams) ([]*ListShopOrderItemsByUserIDOrderIDRow, error)
+	ListShopOrderItemsForExport(ctx context.Context, userID int64) ([]*ShopOrderItem, error)
+	// the qty of the order items that isn't back in stock yet, the items of the received return requests already are
+	ListShopOrderItemsToRestock(ctx context.Context, orderID int64) ([]*ListShopOrderItemsToRestockRow, error)
+	ListShopOrderStatusHistoryByUserIDOrderID(ctx context.Context, arg ListShopOrderStatusHistoryByUserIDOrderIDParams) ([]*ListShopOrderStatusHistoryByUserIDOrderIDRow, error)
 	ListShopOrders(ctx context.Context, arg ListShopOrdersParams) ([]*ShopOrder, error)
 	ListShopOrdersByUserID(ctx context.Context, arg ListShopOrdersByUserIDParams) ([]*ListShopOrdersByUserIDRow, error)
 	// ROW_NUMBER() OVER(ORDER BY so.id) AS order_number,
 	ListShopOrdersByUserIDNextPage(ctx context.Context, arg ListShopOrdersByUserIDNextPageParams) ([]*ListShopOrdersByUserIDNextPageRow, error)
 	// ROW_NUMBER() OVER(ORDER BY so.id) AS order_number,
 	ListShopOrdersByUserIDV2(ctx context.Context, arg ListShopOrdersByUserIDV2Params) ([]*ListShopOrdersByUserIDV2Row, error)
+	ListShopOrdersForExport(ctx context.Context, userID int64) ([]*ShopOrder, error)
 	// LIMIT 1;
 	ListShoppingCartItems(ctx context.Context, arg ListShoppingCartItemsParams) ([]*ShoppingCartItem, error)
 	ListShoppingCartItemsByCartID(ctx context.Context, shoppingCartID int64) ([]*ShoppingCartItem, error)
 	ListShoppingCartItemsByUserID(ctx context.Context, userID int64) ([]*ListShoppingCartItemsByUserIDRow, error)
 	ListShoppingCarts(ctx context.Context, arg ListShoppingCartsParams) ([]*ShoppingCart, error)
+	ListStockReservationsByCartID(ctx context.Context, shoppingCartID int64) ([]*StockReservation, error)
 	ListUserReviews(ctx context.Context, arg ListUserReviewsParams) ([]*UserReview, error)
+	ListUserReviewsForExport(ctx context.Context, userID int64) ([]*UserReview, error)
+	// the oldest reviews come first so the queue is worked through in order
+	ListUserReviewsForModeration(ctx context.Context, arg ListUserReviewsForModerationParams) ([]*ListUserReviewsForModerationRow, error)
+	ListUserSessionsForExport(ctx context.Context, userID int64) ([]*UserSession, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]*User, error)
 	ListVariationOptions(ctx context.Context, arg ListVariationOptionsParams) ([]*VariationOption, error)
 	ListVariations(ctx context.Context, arg ListVariationsParams) ([]*Variation, error)
@@ -345,8 +467,26 @@ type Querier interface {
 	ListWishListItemsByCartID(ctx context.Context, wishListID int64) ([]*WishListItem, error)
 	ListWishListItemsByUserID(ctx context.Context, userID int64) ([]*ListWishListItemsByUserIDRow, error)
 	ListWishLists(ctx context.Context, arg ListWishListsParams) ([]*WishList, error)
+	// serializes the moves of the categories until the end of the transaction, so two moves can't build a cycle together
+	LockProductCategoryTree(ctx context.Context) error
+	MarkUserDeletionAnonymized(ctx context.Context, userID int64) (*UserDeletion, error)
+	// an empty reply removes the reply of the review
+	ModerateUserReview(ctx context.Context, arg ModerateUserReviewParams) (*UserReview, error)
+	// the orders are kept for accounting without the address of the user
+	RedactShopOrderAddresses(ctx context.Context, userID int64) (int64, error)
+	ReplaceAdminRecoveryCodes(ctx context.Context, arg ReplaceAdminRecoveryCodesParams) error
+	RestockProductSize(ctx context.Context, arg RestockProductSizeParams) (*ProductSize, error)
+	RevokeAdminSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	RevokeAdminSessions(ctx context.Context, adminID int64) (int64, error)
+	RevokeOtherUserSessions(ctx context.Context, arg RevokeOtherUserSessionsParams) (int64, error)
+	RevokeUserSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	RevokeUserSessions(ctx context.Context, userID int64) (int64, error)
+	RotateAdminSession(ctx context.Context, arg RotateAdminSessionParams) (*AdminSession, error)
+	RotateUserSession(ctx context.Context, arg RotateUserSessionParams) (*UserSession, error)
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	SearchProductItems(ctx context.Context, arg SearchProductItemsParams) ([]*SearchProductItemsRow, error)
+	// every list filter matches any of its values, an empty list doesn't filter
+	SearchProductItemsFaceted(ctx context.Context, arg SearchProductItemsFacetedParams) ([]*SearchProductItemsFacetedRow, error)
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
 	SearchProductItemsNextPage(ctx context.Context, arg SearchProductItemsNextPageParams) ([]*SearchProductItemsNextPageRow, error)
 	// LEFT JOIN "product_size" AS ps ON ps.product_item_id = pi.id
@@ -355,17 +495,25 @@ type Querier interface {
 	SearchProductItemsOld(ctx context.Context, arg SearchProductItemsOldParams) ([]*SearchProductItemsOldRow, error)
 	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]*SearchProductsRow, error)
 	SearchProductsNextPage(ctx context.Context, arg SearchProductsNextPageParams) ([]*SearchProductsNextPageRow, error)
+	ShopOrderTrackNumberExists(ctx context.Context, trackNumber string) (bool, error)
 	UpdateAddress(ctx context.Context, arg UpdateAddressParams) (*Address, error)
 	UpdateAdmin(ctx context.Context, arg UpdateAdminParams) (*Admin, error)
+	UpdateAdminRole(ctx context.Context, arg UpdateAdminRoleParams) (*AdminRole, error)
+	UpdateAdminSecuritySetting(ctx context.Context, requireTwoFactor bool) (*AdminSecuritySetting, error)
 	UpdateAdminSession(ctx context.Context, arg UpdateAdminSessionParams) (*AdminSession, error)
+	UpdateAdminTotpLastUsedStep(ctx context.Context, arg UpdateAdminTotpLastUsedStepParams) (*AdminTotp, error)
 	UpdateAdminType(ctx context.Context, arg UpdateAdminTypeParams) (*AdminType, error)
 	UpdateAppPolicy(ctx context.Context, arg UpdateAppPolicyParams) (*AppPolicy, error)
 	UpdateBrandPromotion(ctx context.Context, arg UpdateBrandPromotionParams) (*BrandPromotion, error)
 	UpdateCategoryPromotion(ctx context.Context, arg UpdateCategoryPromotionParams) (*CategoryPromotion, error)
 	UpdateHomePageTextBanner(ctx context.Context, arg UpdateHomePageTextBannerParams) (*HomePageTextBanner, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (*IdempotencyKey, error)
 	UpdateNotification(ctx context.Context, arg UpdateNotificationParams) (*Notification, error)
 	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (*OrderStatus, error)
 	UpdatePaymentMethod(ctx context.Context, arg UpdatePaymentMethodParams) (*PaymentMethod, error)
+	UpdatePaymentTransaction(ctx context.Context, arg UpdatePaymentTransactionParams) (*PaymentTransaction, error)
+	UpdatePaymentTransactionAmount(ctx context.Context, arg UpdatePaymentTransactionAmountParams) (*PaymentTransaction, error)
+	UpdatePaymentTransactionRefund(ctx context.Context, arg UpdatePaymentTransactionRefundParams) (*PaymentTransaction, error)
 	UpdatePaymentType(ctx context.Context, arg UpdatePaymentTypeParams) (*PaymentType, error)
 	// )
 	// SELECT *
@@ -378,6 +526,8 @@ type Querier interface {
 	// LIMIT $2
 	// OFFSET $3;
 	UpdateProductCategory(ctx context.Context, arg UpdateProductCategoryParams) (*ProductCategory, error)
+	// a NULL parent moves the category to the root
+	UpdateProductCategoryParent(ctx context.Context, arg UpdateProductCategoryParentParams) (*ProductCategory, error)
 	UpdateProductColor(ctx context.Context, arg UpdateProductColorParams) (*ProductColor, error)
 	UpdateProductConfiguration(ctx context.Context, arg UpdateProductConfigurationParams) (*ProductConfiguration, error)
 	UpdateProductImage(ctx context.Context, arg UpdateProductImageParams) (*ProductImage, error)
@@ -389,7 +539,7 @@ type Querier interface {
 	// LIMIT $1
 	// OFFSET $2;
 	UpdatePromotion(ctx context.Context, arg UpdatePromotionParams) (*Promotion, error)
-	UpdateResetPassword(ctx context.Context, arg UpdateResetPasswordParams) (*ResetPassword, error)
+	UpdateResetPassword(ctx context.Context, id int64) (*ResetPassword, error)
 	UpdateShippingMethod(ctx context.Context, arg UpdateShippingMethodParams) (*ShippingMethod, error)
 	UpdateShopOrder(ctx context.Context, arg UpdateShopOrderParams) (*ShopOrder, error)
 	// -- name: ListShopOrderItemsByOrderID :many
@@ -397,12 +547,15 @@ type Querier interface {
 	// WHERE order_id = $1
 	// ORDER BY id;
 	UpdateShopOrderItem(ctx context.Context, arg UpdateShopOrderItemParams) (*ShopOrderItem, error)
+	UpdateShopOrderStatusByUserID(ctx context.Context, arg UpdateShopOrderStatusByUserIDParams) (*ShopOrder, error)
 	UpdateShoppingCart(ctx context.Context, arg UpdateShoppingCartParams) (*ShoppingCart, error)
 	UpdateShoppingCartItem(ctx context.Context, arg UpdateShoppingCartItemParams) (*UpdateShoppingCartItemRow, error)
 	// telephone = COALESCE(sqlc.narg(telephone),telephone),
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (*User, error)
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (*User, error)
 	UpdateUserEmailisVerifiedForTest(ctx context.Context, id int64) error
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (*User, error)
+	// an approved review goes back to the moderation when its text changes
 	UpdateUserReview(ctx context.Context, arg UpdateUserReviewParams) (*UserReview, error)
 	UpdateUserSession(ctx context.Context, arg UpdateUserSessionParams) (*UserSession, error)
 	UpdateVariation(ctx context.Context, arg UpdateVariationParams) (*Variation, error)
@@ -414,6 +567,14 @@ type Querier interface {
 	//   WHERE wl.id = sqlc.arg(wish_list_id)
 	// )
 	UpdateWishListItem(ctx context.Context, arg UpdateWishListItemParams) (*WishListItem, error)
+	UpsertAdminTotp(ctx context.Context, arg UpsertAdminTotpParams) (*AdminTotp, error)
+	// a new request of a pending deletion restarts its grace period
+	UpsertUserDeletion(ctx context.Context, arg UpsertUserDeletionParams) (*UserDeletion, error)
+	UseAdminLoginChallenge(ctx context.Context, id int64) (*AdminLoginChallenge, error)
+	UseAdminRecoveryCode(ctx context.Context, arg UseAdminRecoveryCodeParams) (*AdminRecoveryCode, error)
+	UseEmailChange(ctx context.Context, id int64) (*EmailChange, error)
+	// the password set before the email was verified might not be the owner's, so it's replaced
+	VerifyUserEmailForSocialLogin(ctx context.Context, arg VerifyUserEmailForSocialLoginParams) (*User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -17,7 +17,7 @@ INSERT INTO "reset_passwords" (
     secret_code
 ) VALUES (
     $1, $2
-) RETURNING id, user_id, secret_code, created_at, updated_at, expired_at, is_used
+) RETURNING id, user_id, secret_code, created_at, updated_at, expired_at, is_used, attempts
 `
 
 type CreateResetPasswordParams struct {
@@ -36,12 +36,23 @@ func (q *Queries) CreateResetPassword(ctx context.Context, arg CreateResetPasswo
 		&i.UpdatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
 
+const deleteResetPasswordsByUserID = `-- name: DeleteResetPasswordsByUserID :exec
+DELETE FROM "reset_passwords"
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteResetPasswordsByUserID(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteResetPasswordsByUserID, userID)
+	return err
+}
+
 const getLastUsedResetPassword = `-- name: GetLastUsedResetPassword :one
-SELECT rp.id, rp.user_id, rp.secret_code, rp.created_at, rp.updated_at, rp.expired_at, rp.is_used FROM "reset_passwords" AS rp
+SELECT rp.id, rp.user_id, rp.secret_code, rp.created_at, rp.updated_at, rp.expired_at, rp.is_used, rp.attempts FROM "reset_passwords" AS rp
 JOIN "user" AS u ON u.id = rp.user_id
 WHERE u.email = $1
 AND is_used = TRUE
@@ -62,12 +73,13 @@ func (q *Queries) GetLastUsedResetPassword(ctx context.Context, email string) (*
 		&i.UpdatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
 
 const getResetPassword = `-- name: GetResetPassword :one
-SELECT id, user_id, secret_code, created_at, updated_at, expired_at, is_used FROM "reset_passwords"
+SELECT id, user_id, secret_code, created_at, updated_at, expired_at, is_used, attempts FROM "reset_passwords"
 WHERE id = $1 LIMIT 1
 `
 
@@ -82,35 +94,14 @@ func (q *Queries) GetResetPassword(ctx context.Context, id int64) (*ResetPasswor
 		&i.UpdatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
 
-const getResetPasswordUserIDByID = `-- name: GetResetPasswordUserIDByID :one
-SELECT user_id FROM "reset_passwords"
-WHERE
-    id = $1
-    AND secret_code = $2
-    AND is_used = FALSE
-    AND expired_at > now()
-LIMIT 1
-`
-
-type GetResetPasswordUserIDByIDParams struct {
-	ID         int64  `json:"id"`
-	SecretCode string `json:"secret_code"`
-}
-
-func (q *Queries) GetResetPasswordUserIDByID(ctx context.Context, arg GetResetPasswordUserIDByIDParams) (int64, error) {
-	row := q.db.QueryRow(ctx, getResetPasswordUserIDByID, arg.ID, arg.SecretCode)
-	var user_id int64
-	err := row.Scan(&user_id)
-	return user_id, err
-}
-
 const getResetPasswordsByEmail = `-- name: GetResetPasswordsByEmail :one
 SELECT u.email, u.username, u.is_blocked AS is_blocked_user, u.is_email_verified, 
-rp.id, rp.user_id, rp.secret_code, rp.created_at, rp.updated_at, rp.expired_at, rp.is_used FROM "reset_passwords" AS rp
+rp.id, rp.user_id, rp.secret_code, rp.created_at, rp.updated_at, rp.expired_at, rp.is_used, rp.attempts FROM "reset_passwords" AS rp
 JOIN "user" AS u ON u.id = rp.user_id
 WHERE u.email = $1
 ORDER BY rp.created_at DESC
@@ -129,6 +120,7 @@ type GetResetPasswordsByEmailRow struct {
 	UpdatedAt       time.Time `json:"updated_at"`
 	ExpiredAt       time.Time `json:"expired_at"`
 	IsUsed          bool      `json:"is_used"`
+	Attempts        int32     `json:"attempts"`
 }
 
 func (q *Queries) GetResetPasswordsByEmail(ctx context.Context, email string) (*GetResetPasswordsByEmailRow, error) {
@@ -146,6 +138,40 @@ func (q *Queries) GetResetPasswordsByEmail(ctx context.Context, email string) (*
 		&i.UpdatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
+	)
+	return &i, err
+}
+
+const incrementResetPasswordAttempts = `-- name: IncrementResetPasswordAttempts :one
+UPDATE "reset_passwords"
+SET
+    attempts = attempts + 1,
+    expired_at = CASE WHEN attempts + 1 >= $1::int THEN now() ELSE expired_at END
+WHERE
+    id = $2
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING id, user_id, secret_code, created_at, updated_at, expired_at, is_used, attempts
+`
+
+type IncrementResetPasswordAttemptsParams struct {
+	MaxAttempts int32 `json:"max_attempts"`
+	ID          int64 `json:"id"`
+}
+
+func (q *Queries) IncrementResetPasswordAttempts(ctx context.Context, arg IncrementResetPasswordAttemptsParams) (*ResetPassword, error) {
+	row := q.db.QueryRow(ctx, incrementResetPasswordAttempts, arg.MaxAttempts, arg.ID)
+	var i ResetPassword
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.SecretCode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiredAt,
+		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
@@ -156,19 +182,13 @@ SET
     is_used = TRUE
 WHERE
     id = $1
-    AND secret_code = $2
     AND is_used = FALSE
     AND expired_at > now()
-RETURNING id, user_id, secret_code, created_at, updated_at, expired_at, is_used
+RETURNING id, user_id, secret_code, created_at, updated_at, expired_at, is_used, attempts
 `
 
-type UpdateResetPasswordParams struct {
-	ID         int64  `json:"id"`
-	SecretCode string `json:"secret_code"`
-}
-
-func (q *Queries) UpdateResetPassword(ctx context.Context, arg UpdateResetPasswordParams) (*ResetPassword, error) {
-	row := q.db.QueryRow(ctx, updateResetPassword, arg.ID, arg.SecretCode)
+func (q *Queries) UpdateResetPassword(ctx context.Context, id int64) (*ResetPassword, error) {
+	row := q.db.QueryRow(ctx, updateResetPassword, id)
 	var i ResetPassword
 	err := row.Scan(
 		&i.ID,
@@ -178,6 +198,7 @@ func (q *Queries) UpdateResetPassword(ctx context.Context, arg UpdateResetPasswo
 		&i.UpdatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
@@ -73,12 +73,7 @@ func TestUpdateResetPassword(t *testing.T) {
 	user1 := createRandomUser(t)
 	resetPassword := createRandomResetPassword(t, user1)
 
-	arg := UpdateResetPasswordParams{
-		ID:         resetPassword.ID,
-		SecretCode: resetPassword.SecretCode,
-	}
-
-	resetPassword2, err := testStore.UpdateResetPassword(context.Background(), arg)
+	resetPassword2, err := testStore.UpdateResetPassword(context.Background(), resetPassword.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, resetPassword2)
 
@@ -98,23 +93,13 @@ func TestGetLastUsedResetPassword(t *testing.T) {
 	resetPassword1 := createRandomResetPassword(t, user1)
 	resetPassword2 := createRandomResetPassword(t, user1)
 
-	arg1 := UpdateResetPasswordParams{
-		ID:         resetPassword1.ID,
-		SecretCode: resetPassword1.SecretCode,
-	}
-
-	updatedPasswordReset1, err := testStore.UpdateResetPassword(context.Background(), arg1)
+	updatedPasswordReset1, err := testStore.UpdateResetPassword(context.Background(), resetPassword1.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, resetPassword2)
 
-	arg2 := UpdateResetPasswordParams{
-		ID:         resetPassword2.ID,
-		SecretCode: resetPassword2.SecretCode,
-	}
-
 	time.Sleep(time.Second)
 
-	updatedPasswordReset2, err := testStore.UpdateResetPassword(context.Background(), arg2)
+	updatedPasswordReset2, err := testStore.UpdateResetPassword(context.Background(), resetPassword2.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, resetPassword2)
 
@@ -125,3 +110,30 @@ func TestGetLastUsedResetPassword(t *testing.T) {
 	require.Equal(t, updatedPasswordReset2, lastUsedCode)
 	require.NotEqual(t, updatedPasswordReset1, lastUsedCode)
 }
+
+func TestIncrementResetPasswordAttempts(t *testing.T) {
+	user1 := createRandomUser(t)
+	resetPassword := createRandomResetPassword(t, user1)
+
+	arg := IncrementResetPasswordAttemptsParams{
+		MaxAttempts: 2,
+		ID:          resetPassword.ID,
+	}
+
+	resetPassword1, err := testStore.IncrementResetPasswordAttempts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), resetPassword1.Attempts)
+	require.True(t, resetPassword1.ExpiredAt.After(time.Now()))
+
+	// the last allowed wrong code expires the otp
+	resetPassword2, err := testStore.IncrementResetPasswordAttempts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), resetPassword2.Attempts)
+	require.False(t, resetPassword2.ExpiredAt.After(time.Now()))
+
+	_, err = testStore.UpdateResetPassword(context.Background(), resetPassword.ID)
+	require.Error(t, err)
+
+	_, err = testStore.IncrementResetPasswordAttempts(context.Background(), arg)
+	require.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSearchSuggestions(t *testing.T) {
+	prefix := util.RandomString(8)
+	popular := prefix + " shirt"
+
+	for i := 0; i < 3; i++ {
+		err := testStore.CreateSearchQueryLog(context.Background(), CreateSearchQueryLogParams{
+			Query:       popular,
+			ResultCount: 4,
+		})
+		require.NoError(t, err)
+	}
+
+	// a query that found nothing isn't suggested
+	err := testStore.CreateSearchQueryLog(context.Background(), CreateSearchQueryLogParams{
+		Query:       prefix + " dress",
+		ResultCount: 0,
+	})
+	require.NoError(t, err)
+
+	// a query searched only once isn't suggested either
+	err = testStore.CreateSearchQueryLog(context.Background(), CreateSearchQueryLogParams{
+		Query:       prefix + " skirt",
+		ResultCount: 1,
+	})
+	require.NoError(t, err)
+
+	suggestions, err := testStore.ListSearchSuggestions(context.Background(), ListSearchSuggestionsParams{
+		Query:     prefix,
+		KindLimit: 5,
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	for _, suggestion := range suggestions {
+		if suggestion.Kind == "query" {
+			queries = append(queries, suggestion.Value)
+		}
+	}
+	require.Equal(t, []string{popular}, queries)
+}
+
+func TestGetSearchCorrection(t *testing.T) {
+	word := util.RandomString(10)
+	for i := 0; i < 3; i++ {
+		err := testStore.CreateSearchQueryLog(context.Background(), CreateSearchQueryLogParams{
+			Query:       word,
+			ResultCount: 2,
+		})
+		require.NoError(t, err)
+	}
+
+	typo := word[:9] + "x"
+	if word[9] == 'x' {
+		typo = word[:9] + "y"
+	}
+
+	correction, err := testStore.GetSearchCorrection(context.Background(), typo)
+	require.NoError(t, err)
+	require.Equal(t, word, correction)
+}
+
+func TestListSearchQueryStats(t *testing.T) {
+	query := util.RandomString(12)
+	for _, resultCount := range []int64{0, 0} {
+		err := testStore.CreateSearchQueryLog(context.Background(), CreateSearchQueryLogParams{
+			Query:       query,
+			ResultCount: resultCount,
+		})
+		require.NoError(t, err)
+	}
+
+	stats, err := testStore.ListSearchQueryStats(context.Background(), ListSearchQueryStatsParams{
+		Limit:       1000,
+		Offset:      0,
+		Since:       time.Now().Add(-time.Minute),
+		ZeroResults: true,
+	})
+	require.NoError(t, err)
+
+	var found *ListSearchQueryStatsRow
+	for _, stat := range stats {
+		require.Equal(t, stat.SearchCount, stat.ZeroResultCount)
+		if stat.Query == query {
+			found = stat
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, int64(2), found.SearchCount)
+	require.WithinDuration(t, time.Now(), found.LastSearchedAt, time.Minute)
+}
+
+func TestDeleteOldSearchQueryLogs(t *testing.T) {
+	query := util.RandomString(12)
+	err := testStore.CreateSearchQueryLog(context.Background(), CreateSearchQueryLogParams{
+		Query:       query,
+		ResultCount: 1,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.DeleteOldSearchQueryLogs(context.Background())
+	require.NoError(t, err)
+
+	// a recent search is kept
+	stats, err := testStore.ListSearchQueryStats(context.Background(), ListSearchQueryStatsParams{
+		Limit:  1000,
+		Offset: 0,
+		Since:  time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, stat := range stats {
+		if stat.Query == query {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
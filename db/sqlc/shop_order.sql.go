@@ -347,9 +347,9 @@ SELECT
     COALESCE(SUM(CAST(order_total AS NUMERIC)),'0')::VARCHAR AS daily_revenue
 FROM
     shop_order
-WHERE order_status_id = 2
-AND updated_at >= CURRENT_DATE
-AND updated_at < CURRENT_DATE + INTERVAL '1 day'
+WHERE order_status_id = (SELECT id FROM "order_status" WHERE code = 'delivered')
+AND completed_at >= CURRENT_DATE
+AND completed_at < CURRENT_DATE + INTERVAL '1 day'
 AND EXISTS(SELECT is_admin FROM t1)
 `
 
@@ -390,6 +390,62 @@ func (q *Queries) GetShopOrder(ctx context.Context, id int64) (*ShopOrder, error
 	return &i, err
 }
 
+const getShopOrderForUpdate = `-- name: GetShopOrderForUpdate :one
+SELECT id, track_number, user_id, payment_type_id, shipping_address_id, order_total, shipping_method_id, order_status_id, address_name, address_telephone, address_line, address_region, address_city, created_at, updated_at, completed_at, order_number FROM "shop_order"
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetShopOrderForUpdate(ctx context.Context, id int64) (*ShopOrder, error) {
+	row := q.db.QueryRow(ctx, getShopOrderForUpdate, id)
+	var i ShopOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TrackNumber,
+		&i.UserID,
+		&i.PaymentTypeID,
+		&i.ShippingAddressID,
+		&i.OrderTotal,
+		&i.ShippingMethodID,
+		&i.OrderStatusID,
+		&i.AddressName,
+		&i.AddressTelephone,
+		&i.AddressLine,
+		&i.AddressRegion,
+		&i.AddressCity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.OrderNumber,
+	)
+	return &i, err
+}
+
+const getShopOrdersCountByStatusCodes = `-- name: GetShopOrdersCountByStatusCodes :one
+With t1 AS (
+SELECT 1 AS is_admin
+    FROM "admin"
+    WHERE "admin".id = $2
+    AND active = TRUE
+    )
+SELECT COUNT(*) AS orders_count FROM shop_order AS so
+LEFT JOIN "order_status" AS os ON os.id = so.order_status_id
+WHERE os.code = ANY($1::varchar[])
+AND EXISTS(SELECT is_admin FROM t1)
+`
+
+type GetShopOrdersCountByStatusCodesParams struct {
+	Codes   []string `json:"codes"`
+	AdminID int64    `json:"admin_id"`
+}
+
+func (q *Queries) GetShopOrdersCountByStatusCodes(ctx context.Context, arg GetShopOrdersCountByStatusCodesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getShopOrdersCountByStatusCodes, arg.Codes, arg.AdminID)
+	var orders_count int64
+	err := row.Scan(&orders_count)
+	return orders_count, err
+}
+
 const getShopOrdersCountByStatusId = `-- name: GetShopOrdersCountByStatusId :one
 With t1 AS (
 SELECT 1 AS is_admin
@@ -754,6 +810,86 @@ func (q *Queries) ListShopOrdersByUserIDV2(ctx context.Context, arg ListShopOrde
 	return items, nil
 }
 
+const listShopOrdersForExport = `-- name: ListShopOrdersForExport :many
+SELECT id, track_number, user_id, payment_type_id, shipping_address_id, order_total, shipping_method_id, order_status_id, address_name, address_telephone, address_line, address_region, address_city, created_at, updated_at, completed_at, order_number FROM "shop_order"
+WHERE user_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListShopOrdersForExport(ctx context.Context, userID int64) ([]*ShopOrder, error) {
+	rows, err := q.db.Query(ctx, listShopOrdersForExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ShopOrder{}
+	for rows.Next() {
+		var i ShopOrder
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrackNumber,
+			&i.UserID,
+			&i.PaymentTypeID,
+			&i.ShippingAddressID,
+			&i.OrderTotal,
+			&i.ShippingMethodID,
+			&i.OrderStatusID,
+			&i.AddressName,
+			&i.AddressTelephone,
+			&i.AddressLine,
+			&i.AddressRegion,
+			&i.AddressCity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.OrderNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const redactShopOrderAddresses = `-- name: RedactShopOrderAddresses :execrows
+UPDATE "shop_order"
+SET
+shipping_address_id = NULL,
+address_name = 'redacted',
+address_telephone = 'redacted',
+address_line = 'redacted',
+address_region = 'redacted',
+address_city = 'redacted',
+updated_at = now()
+WHERE user_id = $1
+`
+
+// the orders are kept for accounting without the address of the user
+func (q *Queries) RedactShopOrderAddresses(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, redactShopOrderAddresses, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const shopOrderTrackNumberExists = `-- name: ShopOrderTrackNumberExists :one
+SELECT EXISTS (
+  SELECT 1 FROM "shop_order"
+  WHERE track_number = $1
+)
+`
+
+func (q *Queries) ShopOrderTrackNumberExists(ctx context.Context, trackNumber string) (bool, error) {
+	row := q.db.QueryRow(ctx, shopOrderTrackNumberExists, trackNumber)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const updateShopOrder = `-- name: UpdateShopOrder :one
 With t1 AS (
 SELECT 1 AS is_admin
@@ -772,7 +908,8 @@ shipping_method_id = COALESCE($6,shipping_method_id),
 order_status_id = COALESCE($7,order_status_id),
 updated_at = NOW(),
 completed_at = CASE
-    WHEN order_status_id != 2 AND $7 =2
+    WHEN order_status_id IS DISTINCT FROM $7
+    AND $7 = (SELECT id FROM "order_status" WHERE code = 'delivered')
     THEN NOW()
     ELSE completed_at
 END
@@ -827,3 +964,44 @@ func (q *Queries) UpdateShopOrder(ctx context.Context, arg UpdateShopOrderParams
 	)
 	return &i, err
 }
+
+const updateShopOrderStatusByUserID = `-- name: UpdateShopOrderStatusByUserID :one
+UPDATE "shop_order"
+SET
+order_status_id = $1,
+updated_at = now()
+WHERE id = $2
+AND user_id = $3
+RETURNING id, track_number, user_id, payment_type_id, shipping_address_id, order_total, shipping_method_id, order_status_id, address_name, address_telephone, address_line, address_region, address_city, created_at, updated_at, completed_at, order_number
+`
+
+type UpdateShopOrderStatusByUserIDParams struct {
+	OrderStatusID null.Int `json:"order_status_id"`
+	ID            int64    `json:"id"`
+	UserID        int64    `json:"user_id"`
+}
+
+func (q *Queries) UpdateShopOrderStatusByUserID(ctx context.Context, arg UpdateShopOrderStatusByUserIDParams) (*ShopOrder, error) {
+	row := q.db.QueryRow(ctx, updateShopOrderStatusByUserID, arg.OrderStatusID, arg.ID, arg.UserID)
+	var i ShopOrder
+	err := row.Scan(
+		&i.ID,
+		&i.TrackNumber,
+		&i.UserID,
+		&i.PaymentTypeID,
+		&i.ShippingAddressID,
+		&i.OrderTotal,
+		&i.ShippingMethodID,
+		&i.OrderStatusID,
+		&i.AddressName,
+		&i.AddressTelephone,
+		&i.AddressLine,
+		&i.AddressRegion,
+		&i.AddressCity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.OrderNumber,
+	)
+	return &i, err
+}
@@ -19,20 +19,30 @@ INSERT INTO "shop_order_item" (
   quantity,
   price,
   discount,
-  shipping_method_price
+  shipping_method_price,
+  size_id,
+  size_value,
+  color_value,
+  product_name,
+  coupon_discount
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 )
-RETURNING id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount
+RETURNING id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount, size_id, size_value, color_value, product_name, coupon_discount
 `
 
 type CreateShopOrderItemParams struct {
-	ProductItemID       int64  `json:"product_item_id"`
-	OrderID             int64  `json:"order_id"`
-	Quantity            int32  `json:"quantity"`
-	Price               string `json:"price"`
-	Discount            int32  `json:"discount"`
-	ShippingMethodPrice string `json:"shipping_method_price"`
+	ProductItemID       int64       `json:"product_item_id"`
+	OrderID             int64       `json:"order_id"`
+	Quantity            int32       `json:"quantity"`
+	Price               string      `json:"price"`
+	Discount            int32       `json:"discount"`
+	ShippingMethodPrice string      `json:"shipping_method_price"`
+	SizeID              null.Int    `json:"size_id"`
+	SizeValue           null.String `json:"size_value"`
+	ColorValue          null.String `json:"color_value"`
+	ProductName         null.String `json:"product_name"`
+	CouponDiscount      string      `json:"coupon_discount"`
 }
 
 func (q *Queries) CreateShopOrderItem(ctx context.Context, arg CreateShopOrderItemParams) (*ShopOrderItem, error) {
@@ -43,6 +53,11 @@ func (q *Queries) CreateShopOrderItem(ctx context.Context, arg CreateShopOrderIt
 		arg.Price,
 		arg.Discount,
 		arg.ShippingMethodPrice,
+		arg.SizeID,
+		arg.SizeValue,
+		arg.ColorValue,
+		arg.ProductName,
+		arg.CouponDiscount,
 	)
 	var i ShopOrderItem
 	err := row.Scan(
@@ -55,6 +70,11 @@ func (q *Queries) CreateShopOrderItem(ctx context.Context, arg CreateShopOrderIt
 		&i.UpdatedAt,
 		&i.Quantity,
 		&i.Discount,
+		&i.SizeID,
+		&i.SizeValue,
+		&i.ColorValue,
+		&i.ProductName,
+		&i.CouponDiscount,
 	)
 	return &i, err
 }
@@ -69,7 +89,7 @@ SELECT 1 AS is_admin
 DELETE FROM "shop_order_item"
 WHERE "shop_order_item".id = $1
 AND (SELECT is_admin FROM t1) = 1
-RETURNING id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount
+RETURNING id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount, size_id, size_value, color_value, product_name, coupon_discount
 `
 
 type DeleteShopOrderItemParams struct {
@@ -90,12 +110,17 @@ func (q *Queries) DeleteShopOrderItem(ctx context.Context, arg DeleteShopOrderIt
 		&i.UpdatedAt,
 		&i.Quantity,
 		&i.Discount,
+		&i.SizeID,
+		&i.SizeValue,
+		&i.ColorValue,
+		&i.ProductName,
+		&i.CouponDiscount,
 	)
 	return &i, err
 }
 
 const getShopOrderItem = `-- name: GetShopOrderItem :one
-SELECT id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount FROM "shop_order_item"
+SELECT id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount, size_id, size_value, color_value, product_name, coupon_discount FROM "shop_order_item"
 WHERE id = $1 LIMIT 1
 `
 
@@ -112,12 +137,17 @@ func (q *Queries) GetShopOrderItem(ctx context.Context, id int64) (*ShopOrderIte
 		&i.UpdatedAt,
 		&i.Quantity,
 		&i.Discount,
+		&i.SizeID,
+		&i.SizeValue,
+		&i.ColorValue,
+		&i.ProductName,
+		&i.CouponDiscount,
 	)
 	return &i, err
 }
 
 const getShopOrderItemByUserIDOrderID = `-- name: GetShopOrderItemByUserIDOrderID :one
-SELECT soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount, so.user_id
+SELECT soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount, soi.size_id, soi.size_value, soi.color_value, soi.product_name, soi.coupon_discount, so.user_id
 FROM "shop_order_item" AS soi
 LEFT JOIN "shop_order" AS so ON so.id = soi.order_id
 WHERE so.user_id = $1
@@ -131,16 +161,21 @@ type GetShopOrderItemByUserIDOrderIDParams struct {
 }
 
 type GetShopOrderItemByUserIDOrderIDRow struct {
-	ID                  int64     `json:"id"`
-	ProductItemID       int64     `json:"product_item_id"`
-	OrderID             int64     `json:"order_id"`
-	Price               string    `json:"price"`
-	ShippingMethodPrice string    `json:"shipping_method_price"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	Quantity            int32     `json:"quantity"`
-	Discount            int32     `json:"discount"`
-	UserID              null.Int  `json:"user_id"`
+	ID                  int64       `json:"id"`
+	ProductItemID       int64       `json:"product_item_id"`
+	OrderID             int64       `json:"order_id"`
+	Price               string      `json:"price"`
+	ShippingMethodPrice string      `json:"shipping_method_price"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+	Quantity            int32       `json:"quantity"`
+	Discount            int32       `json:"discount"`
+	SizeID              null.Int    `json:"size_id"`
+	SizeValue           null.String `json:"size_value"`
+	ColorValue          null.String `json:"color_value"`
+	ProductName         null.String `json:"product_name"`
+	CouponDiscount      string      `json:"coupon_discount"`
+	UserID              null.Int    `json:"user_id"`
 }
 
 func (q *Queries) GetShopOrderItemByUserIDOrderID(ctx context.Context, arg GetShopOrderItemByUserIDOrderIDParams) (*GetShopOrderItemByUserIDOrderIDRow, error) {
@@ -156,13 +191,18 @@ func (q *Queries) GetShopOrderItemByUserIDOrderID(ctx context.Context, arg GetSh
 		&i.UpdatedAt,
 		&i.Quantity,
 		&i.Discount,
+		&i.SizeID,
+		&i.SizeValue,
+		&i.ColorValue,
+		&i.ProductName,
+		&i.CouponDiscount,
 		&i.UserID,
 	)
 	return &i, err
 }
 
 const listShopOrderItems = `-- name: ListShopOrderItems :many
-SELECT id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount FROM "shop_order_item"
+SELECT id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount, size_id, size_value, color_value, product_name, coupon_discount FROM "shop_order_item"
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -192,6 +232,52 @@ func (q *Queries) ListShopOrderItems(ctx context.Context, arg ListShopOrderItems
 			&i.UpdatedAt,
 			&i.Quantity,
 			&i.Discount,
+			&i.SizeID,
+			&i.SizeValue,
+			&i.ColorValue,
+			&i.ProductName,
+			&i.CouponDiscount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShopOrderItemsByOrderID = `-- name: ListShopOrderItemsByOrderID :many
+SELECT id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount, size_id, size_value, color_value, product_name, coupon_discount FROM "shop_order_item"
+WHERE order_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListShopOrderItemsByOrderID(ctx context.Context, orderID int64) ([]*ShopOrderItem, error) {
+	rows, err := q.db.Query(ctx, listShopOrderItemsByOrderID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ShopOrderItem{}
+	for rows.Next() {
+		var i ShopOrderItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductItemID,
+			&i.OrderID,
+			&i.Price,
+			&i.ShippingMethodPrice,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Quantity,
+			&i.Discount,
+			&i.SizeID,
+			&i.SizeValue,
+			&i.ColorValue,
+			&i.ProductName,
+			&i.CouponDiscount,
 		); err != nil {
 			return nil, err
 		}
@@ -205,7 +291,7 @@ func (q *Queries) ListShopOrderItems(ctx context.Context, arg ListShopOrderItems
 
 const listShopOrderItemsByUserID = `-- name: ListShopOrderItemsByUserID :many
 
-SELECT so.id, so.track_number, so.user_id, so.payment_type_id, so.shipping_address_id, so.order_total, so.shipping_method_id, so.order_status_id, so.address_name, so.address_telephone, so.address_line, so.address_region, so.address_city, so.created_at, so.updated_at, so.completed_at, so.order_number, soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount 
+SELECT so.id, so.track_number, so.user_id, so.payment_type_id, so.shipping_address_id, so.order_total, so.shipping_method_id, so.order_status_id, so.address_name, so.address_telephone, so.address_line, so.address_region, so.address_city, so.created_at, so.updated_at, so.completed_at, so.order_number, soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount, soi.size_id, soi.size_value, soi.color_value, soi.product_name, soi.coupon_discount 
 FROM "shop_order" AS so
 LEFT JOIN "shop_order_item" AS soi ON soi.order_id = so.id
 WHERE so.user_id = $3
@@ -247,6 +333,11 @@ type ListShopOrderItemsByUserIDRow struct {
 	UpdatedAt_2         null.Time   `json:"updated_at_2"`
 	Quantity            null.Int    `json:"quantity"`
 	Discount            null.Int    `json:"discount"`
+	SizeID              null.Int    `json:"size_id"`
+	SizeValue           null.String `json:"size_value"`
+	ColorValue          null.String `json:"color_value"`
+	ProductName         null.String `json:"product_name"`
+	CouponDiscount      null.String `json:"coupon_discount"`
 }
 
 // ORDER BY soi.id;
@@ -286,6 +377,11 @@ func (q *Queries) ListShopOrderItemsByUserID(ctx context.Context, arg ListShopOr
 			&i.UpdatedAt_2,
 			&i.Quantity,
 			&i.Discount,
+			&i.SizeID,
+			&i.SizeValue,
+			&i.ColorValue,
+			&i.ProductName,
+			&i.CouponDiscount,
 		); err != nil {
 			return nil, err
 		}
@@ -298,19 +394,18 @@ func (q *Queries) ListShopOrderItemsByUserID(ctx context.Context, arg ListShopOr
 }
 
 const listShopOrderItemsByUserIDOrderID = `-- name: ListShopOrderItemsByUserIDOrderID :many
-SELECT os.status, so.track_number, soi.shipping_method_price AS delivery_price, so.order_total, soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount, p.name AS product_name, pt.value as payment_type,
+SELECT os.status, so.track_number, soi.shipping_method_price AS delivery_price, so.order_total, soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount, soi.size_id, soi.size_value, soi.color_value, soi.product_name, soi.coupon_discount, pt.value as payment_type,
 pimg.product_image_1 AS product_image,
-pcolor.color_value AS product_color, psize.size_value AS product_size,
+COALESCE(soi.color_value, pcolor.color_value) AS product_color, COALESCE(soi.size_value, psize.size_value) AS product_size,
 pi.active AS product_active, a.address_line, a.region, a.city,
 DENSE_RANK() OVER(ORDER BY so.id) as order_number
 FROM "shop_order_item" AS soi
 LEFT JOIN "shop_order" AS so ON so.id = soi.order_id
 LEFT JOIN "product_item" AS pi ON pi.id = soi.product_item_id
 LEFT JOIN "product_image" AS pimg ON pimg.id = pi.image_id
-LEFT JOIN "product_size" AS psize ON psize.product_item_id = pi.id
+LEFT JOIN "product_size" AS psize ON psize.id = soi.size_id
 LEFT JOIN "product_color" AS pcolor ON pcolor.id = pi.color_id
 LEFT JOIN "order_status" AS os ON os.id = so.order_status_id
-LEFT JOIN "product" AS p ON p.id = pi.product_id
 LEFT JOIN "address" AS a ON a.id = so.shipping_address_id
 LEFT JOIN "payment_type" AS pt ON pt.id = so.payment_type_id
 WHERE so.user_id = $1
@@ -336,7 +431,11 @@ type ListShopOrderItemsByUserIDOrderIDRow struct {
 	UpdatedAt           time.Time   `json:"updated_at"`
 	Quantity            int32       `json:"quantity"`
 	Discount            int32       `json:"discount"`
+	SizeID              null.Int    `json:"size_id"`
+	SizeValue           null.String `json:"size_value"`
+	ColorValue          null.String `json:"color_value"`
 	ProductName         null.String `json:"product_name"`
+	CouponDiscount      string      `json:"coupon_discount"`
 	PaymentType         null.String `json:"payment_type"`
 	ProductImage        null.String `json:"product_image"`
 	ProductColor        null.String `json:"product_color"`
@@ -378,7 +477,11 @@ func (q *Queries) ListShopOrderItemsByUserIDOrderID(ctx context.Context, arg Lis
 			&i.UpdatedAt,
 			&i.Quantity,
 			&i.Discount,
+			&i.SizeID,
+			&i.SizeValue,
+			&i.ColorValue,
 			&i.ProductName,
+			&i.CouponDiscount,
 			&i.PaymentType,
 			&i.ProductImage,
 			&i.ProductColor,
@@ -399,8 +502,93 @@ func (q *Queries) ListShopOrderItemsByUserIDOrderID(ctx context.Context, arg Lis
 	return items, nil
 }
 
-const updateShopOrderItem = `-- name: UpdateShopOrderItem :one
+const listShopOrderItemsForExport = `-- name: ListShopOrderItemsForExport :many
+SELECT soi.id, soi.product_item_id, soi.order_id, soi.price, soi.shipping_method_price, soi.created_at, soi.updated_at, soi.quantity, soi.discount, soi.size_id, soi.size_value, soi.color_value, soi.product_name, soi.coupon_discount FROM "shop_order_item" AS soi
+JOIN "shop_order" AS so ON so.id = soi.order_id
+WHERE so.user_id = $1
+ORDER BY soi.order_id, soi.id
+`
 
+func (q *Queries) ListShopOrderItemsForExport(ctx context.Context, userID int64) ([]*ShopOrderItem, error) {
+	rows, err := q.db.Query(ctx, listShopOrderItemsForExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ShopOrderItem{}
+	for rows.Next() {
+		var i ShopOrderItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductItemID,
+			&i.OrderID,
+			&i.Price,
+			&i.ShippingMethodPrice,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Quantity,
+			&i.Discount,
+			&i.SizeID,
+			&i.SizeValue,
+			&i.ColorValue,
+			&i.ProductName,
+			&i.CouponDiscount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShopOrderItemsToRestock = `-- name: ListShopOrderItemsToRestock :many
+SELECT soi.id, soi.size_id, (soi.quantity - COALESCE((
+    SELECT SUM(rri.qty)
+    FROM "return_request_item" AS rri
+    JOIN "return_request" AS rr ON rr.id = rri.return_request_id
+    WHERE rri.shop_order_item_id = soi.id
+    AND rr.status IN ('received', 'refunded')
+), 0))::INT AS qty
+FROM "shop_order_item" AS soi
+WHERE soi.order_id = $1
+ORDER BY soi.id
+`
+
+type ListShopOrderItemsToRestockRow struct {
+	ID     int64    `json:"id"`
+	SizeID null.Int `json:"size_id"`
+	Qty    int32    `json:"qty"`
+}
+
+// the qty of the order items that isn't back in stock yet, the items of the received return requests already are
+func (q *Queries) ListShopOrderItemsToRestock(ctx context.Context, orderID int64) ([]*ListShopOrderItemsToRestockRow, error) {
+	rows, err := q.db.Query(ctx, listShopOrderItemsToRestock, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListShopOrderItemsToRestockRow{}
+	for rows.Next() {
+		var i ListShopOrderItemsToRestockRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SizeID,
+			&i.Qty,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateShopOrderItem = `-- name: UpdateShopOrderItem :one
 UPDATE "shop_order_item"
 SET 
 quantity = COALESCE($1,quantity),
@@ -411,7 +599,7 @@ updated_at = now()
 WHERE id = $5
 AND order_id = $6
 AND product_item_id = $7
-RETURNING id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount
+RETURNING id, product_item_id, order_id, price, shipping_method_price, created_at, updated_at, quantity, discount, size_id, size_value, color_value, product_name, coupon_discount
 `
 
 type UpdateShopOrderItemParams struct {
@@ -424,10 +612,6 @@ type UpdateShopOrderItemParams struct {
 	ProductItemID       int64       `json:"product_item_id"`
 }
 
-// -- name: ListShopOrderItemsByOrderID :many
-// SELECT * FROM "shop_order_item"
-// WHERE order_id = $1
-// ORDER BY id;
 func (q *Queries) UpdateShopOrderItem(ctx context.Context, arg UpdateShopOrderItemParams) (*ShopOrderItem, error) {
 	row := q.db.QueryRow(ctx, updateShopOrderItem,
 		arg.Quantity,
@@ -449,6 +633,11 @@ func (q *Queries) UpdateShopOrderItem(ctx context.Context, arg UpdateShopOrderIt
 		&i.UpdatedAt,
 		&i.Quantity,
 		&i.Discount,
+		&i.SizeID,
+		&i.SizeValue,
+		&i.ColorValue,
+		&i.ProductName,
+		&i.CouponDiscount,
 	)
 	return &i, err
 }
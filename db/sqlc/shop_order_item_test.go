@@ -13,6 +13,7 @@ import (
 func createRandomShopOrderItem(t *testing.T) (ShopOrderItem, ShopOrder) {
 	shopOrder := createRandomShopOrder(t)
 	productItem := createRandomProductItem(t)
+	size := createRandomProductSizeWithItemID(t, productItem.ID)
 	arg := CreateShopOrderItemParams{
 		ProductItemID:       productItem.ID,
 		OrderID:             shopOrder.ID,
@@ -20,6 +21,11 @@ func createRandomShopOrderItem(t *testing.T) (ShopOrderItem, ShopOrder) {
 		Price:               util.RandomDecimalString(1, 200),
 		Discount:            int32(util.RandomInt(0, 90)),
 		ShippingMethodPrice: util.RandomDecimalString(1, 100),
+		SizeID:              null.IntFrom(size.ID),
+		SizeValue:           null.StringFrom(size.SizeValue),
+		ColorValue:          null.StringFrom(util.RandomColor()),
+		ProductName:         null.StringFrom(util.RandomUser()),
+		CouponDiscount:      "0",
 	}
 
 	shopOrderItem, err := testStore.CreateShopOrderItem(context.Background(), arg)
@@ -32,6 +38,10 @@ func createRandomShopOrderItem(t *testing.T) (ShopOrderItem, ShopOrder) {
 	require.Equal(t, arg.Price, shopOrderItem.Price)
 	require.Equal(t, arg.Discount, shopOrderItem.Discount)
 	require.Equal(t, arg.ShippingMethodPrice, shopOrderItem.ShippingMethodPrice)
+	require.Equal(t, arg.SizeID, shopOrderItem.SizeID)
+	require.Equal(t, arg.SizeValue, shopOrderItem.SizeValue)
+	require.Equal(t, arg.ColorValue, shopOrderItem.ColorValue)
+	require.Equal(t, arg.ProductName, shopOrderItem.ProductName)
 
 	return *shopOrderItem, shopOrder
 }
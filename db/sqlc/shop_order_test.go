@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/util"
 	"github.com/guregu/null/v6"
 	"github.com/jackc/pgx/v5"
@@ -21,7 +22,7 @@ func createRandomShopOrder(t *testing.T) ShopOrder {
 	shippingMethod := createRandomShippingMethod(t)
 	orderStatus := createRandomOrderStatus(t)
 	arg := CreateShopOrderParams{
-		TrackNumber: util.GenerateTrackNumber(),
+		TrackNumber: secure.TrackNumber(),
 		UserID:      paymentMethod.UserID,
 		// PaymentMethodID:   paymentMethod.ID,
 		ShippingAddressID: null.IntFrom(address.ID),
@@ -61,7 +62,7 @@ func createRandomShopOrderForListV2(t *testing.T) ShopOrder {
 	shippingMethod := createRandomShippingMethod(t)
 	orderStatus := createRandomOrderStatus(t)
 	arg := CreateShopOrderParams{
-		TrackNumber: util.GenerateTrackNumber(),
+		TrackNumber: secure.TrackNumber(),
 		UserID:      paymentMethod.UserID, PaymentTypeID: util.RandomMoney(),
 		// PaymentMethodID:   paymentMethod.ID,
 		ShippingAddressID: null.IntFrom(address.ID),
@@ -10,8 +10,27 @@ import (
 type Store interface {
 	Querier
 	FinishedPurchaseTx(ctx context.Context, arg FinishedPurchaseTxParams) (*FinishedPurchaseTxResult, error)
-	DeleteShopOrderItemTx(ctx context.Context, arg DeleteShopOrderItemTxParams) error
+	DeleteShopOrderItemTx(ctx context.Context, arg DeleteShopOrderItemTxParams) (*DeleteShopOrderItemTxResult, error)
 	SignUpTx(ctx context.Context, arg SignUpTxParams) (*SignUpTxResult, error)
+	QuoteCartTx(ctx context.Context, arg QuoteCartTxParams) (*QuoteCartTxResult, error)
+	ReserveCartTx(ctx context.Context, arg ReserveCartTxParams) (*ReserveCartTxResult, error)
+	UpdateShopOrderTx(ctx context.Context, arg UpdateShopOrderTxParams) (*UpdateShopOrderTxResult, error)
+	CancelShopOrderTx(ctx context.Context, arg CancelShopOrderTxParams) (*CancelShopOrderTxResult, error)
+	CreateReturnRequestTx(ctx context.Context, arg CreateReturnRequestTxParams) (*CreateReturnRequestTxResult, error)
+	UpdateReturnRequestTx(ctx context.Context, arg UpdateReturnRequestTxParams) (*UpdateReturnRequestTxResult, error)
+	UpdatePaymentTransactionTx(ctx context.Context, arg UpdatePaymentTransactionTxParams) (*UpdatePaymentTransactionTxResult, error)
+	StartRefundPaymentTransactionTx(ctx context.Context, arg StartRefundPaymentTransactionTxParams) (*StartRefundPaymentTransactionTxResult, error)
+	CompleteRefundPaymentTransactionTx(ctx context.Context, arg CompleteRefundPaymentTransactionTxParams) (*CompleteRefundPaymentTransactionTxResult, error)
+	CreateAdminRoleTx(ctx context.Context, arg CreateAdminRoleTxParams) (*AdminRoleTxResult, error)
+	UpdateAdminRoleTx(ctx context.Context, arg UpdateAdminRoleTxParams) (*AdminRoleTxResult, error)
+	DeleteAdminRoleTx(ctx context.Context, id int64) (*AdminRole, error)
+	SetAdminRolesTx(ctx context.Context, arg SetAdminRolesTxParams) ([]*AdminRole, error)
+	RotateUserSessionTx(ctx context.Context, arg RotateUserSessionTxParams) (*UserSession, error)
+	RotateAdminSessionTx(ctx context.Context, arg RotateAdminSessionTxParams) (*AdminSession, error)
+	EnableAdminTotpTx(ctx context.Context, arg EnableAdminTotpTxParams) (*AdminTotp, error)
+	ChangeUserEmailTx(ctx context.Context, arg ChangeUserEmailTxParams) (*ChangeUserEmailTxResult, error)
+	AnonymizeUserTx(ctx context.Context, userID int64) (*AnonymizeUserTxResult, error)
+	MoveProductCategoryTx(ctx context.Context, arg MoveProductCategoryTxParams) (*ProductCategory, error)
 }
 
 // Store provides all functions to execute db queries and transactions
@@ -3,11 +3,13 @@ package db
 import (
 	"context"
 	"log"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
 	"github.com/quagmt/udecimal"
 	"github.com/stretchr/testify/require"
 )
@@ -43,6 +45,7 @@ func TestFinishedPurchaseTx(t *testing.T) {
 	var price udecimal.Decimal
 	// var listPrice []udecimal.Decimal
 	var totalPrice string
+	var orderTotal udecimal.Decimal
 	// var listTotalPrice []string
 
 	errs := make(chan error)
@@ -74,6 +77,7 @@ func TestFinishedPurchaseTx(t *testing.T) {
 			log.Fatal("err is: ", err)
 		}
 		price = udecimal.Zero
+		orderTotal = udecimal.MustParse(shippingMethod.Price)
 		for x := 0; x < n; x++ {
 			product := createRandomProduct(t)
 			image := createRandomProductImage(t)
@@ -110,9 +114,10 @@ func TestFinishedPurchaseTx(t *testing.T) {
 				log.Fatal("err is: ", err)
 			}
 
-			totalPrice += price.String()
+			orderTotal = orderTotal.Add(price.Mul64(uint64(shoppingCartItem.Qty)))
 			// time.Sleep(3 * time.Second)
 		}
+		totalPrice = orderTotal.StringFixed(2)
 		go func() {
 			lock.Lock()
 			result, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
@@ -152,6 +157,8 @@ func TestFinishedPurchaseTx(t *testing.T) {
 			// require.Equal(t, listPaymentMethod[z].ID, finishedShopOrder.PaymentMethodID)
 			require.Equal(t, listShippingMethod[z].ID, finishedShopOrder.ShippingMethodID)
 			require.Equal(t, listOrderStatus[z].ID, finishedShopOrder.OrderStatusID.Int64)
+			require.Equal(t, totalPrice, finishedShopOrder.OrderTotal)
+			require.Equal(t, totalPrice, resultList[z].OrderTotal)
 
 			_, err = testStore.GetShopOrder(context.Background(), finishedShopOrder.ID)
 			require.NoError(t, err)
@@ -197,6 +204,235 @@ func TestFinishedPurchaseTx(t *testing.T) {
 	}
 }
 
+// createRandomCartWithItem creates a cart for the user holding a single item with qty 1
+func createRandomCartWithItem(t *testing.T, userID int64) (*ShoppingCart, *ProductItem) {
+	shoppingCart, err := testStore.CreateShoppingCart(context.Background(), userID)
+	require.NoError(t, err)
+
+	product := createRandomProduct(t)
+	image := createRandomProductImage(t)
+	color := createRandomProductColor(t)
+	productItem, err := testStore.CreateProductItem(context.Background(), CreateProductItemParams{
+		ProductID:  product.ID,
+		ImageID:    image.ID,
+		ColorID:    color.ID,
+		ProductSku: util.RandomInt(5, 100),
+		Price:      util.RandomDecimalString(1, 100),
+		Active:     true,
+	})
+	require.NoError(t, err)
+	size := createRandomProductSizeWithItemID(t, productItem.ID)
+
+	_, err = testStore.CreateShoppingCartItem(context.Background(), CreateShoppingCartItemParams{
+		ShoppingCartID: shoppingCart.ID,
+		ProductItemID:  size.ProductItemID,
+		SizeID:         size.ID,
+		Qty:            1,
+	})
+	require.NoError(t, err)
+
+	return shoppingCart, productItem
+}
+
+func TestFinishedPurchaseTxOrderTotalMismatch(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	orderStatus := createRandomOrderStatus(t)
+	shoppingCart, productItem := createRandomCartWithItem(t, userAddress.UserID)
+
+	// the client total ignores the shipping price
+	result, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+		OrderTotal:       productItem.Price,
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var totalErr *OrderTotalMismatchError
+	require.ErrorAs(t, err, &totalErr)
+	require.Equal(t, productItem.Price, totalErr.Got)
+
+	shopCartItems, err := testStore.ListShoppingCartItemsByCartID(context.Background(), shoppingCart.ID)
+	require.NoError(t, err)
+	require.Len(t, shopCartItems, 1)
+}
+
+func TestFinishedPurchaseTxEmptyCart(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	orderStatus := createRandomOrderStatus(t)
+
+	shoppingCart, err := testStore.CreateShoppingCart(context.Background(), userAddress.UserID)
+	require.NoError(t, err)
+
+	result, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+	})
+	require.ErrorIs(t, err, ErrEmptyShoppingCart)
+	require.Empty(t, result)
+
+	// no shipping only order is left behind
+	shopOrders, err := testStore.ListShopOrdersForExport(context.Background(), userAddress.UserID)
+	require.NoError(t, err)
+	require.Empty(t, shopOrders)
+}
+
+func TestFinishedPurchaseTxKeepsOrderItemDetails(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	orderStatus := createRandomOrderStatus(t)
+	shoppingCart, productItem := createRandomCartWithItem(t, userAddress.UserID)
+
+	cartItems, err := testStore.ListShoppingCartItemsByCartID(context.Background(), shoppingCart.ID)
+	require.NoError(t, err)
+	require.Len(t, cartItems, 1)
+
+	productSize, err := testStore.GetProductSize(context.Background(), cartItems[0].SizeID)
+	require.NoError(t, err)
+
+	product, err := testStore.GetProduct(context.Background(), productItem.ProductID)
+	require.NoError(t, err)
+
+	color, err := testStore.GetProductColor(context.Background(), productItem.ColorID)
+	require.NoError(t, err)
+
+	result, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+	})
+	require.NoError(t, err)
+
+	shopOrderItems, err := testStore.ListShopOrderItemsByUserIDOrderID(context.Background(), ListShopOrderItemsByUserIDOrderIDParams{
+		UserID:  userAddress.UserID,
+		OrderID: result.ShopOrderID,
+	})
+	require.NoError(t, err)
+	require.Len(t, shopOrderItems, 1)
+
+	shopOrderItem := shopOrderItems[0]
+	require.Equal(t, null.IntFrom(productSize.ID), shopOrderItem.SizeID)
+	require.Equal(t, null.StringFrom(productSize.SizeValue), shopOrderItem.SizeValue)
+	require.Equal(t, null.StringFrom(color.ColorValue), shopOrderItem.ColorValue)
+	require.Equal(t, null.StringFrom(product.Name), shopOrderItem.ProductName)
+	require.Equal(t, shopOrderItem.SizeValue, shopOrderItem.ProductSize)
+}
+
+func TestFinishedPurchaseTxWithCoupon(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon := adminCreateRandomCoupon(t, admin)
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	orderStatus := createRandomOrderStatus(t)
+	shoppingCart, _ := createRandomCartWithItem(t, userAddress.UserID)
+
+	arg := FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+		CouponCode:       strings.ToLower(coupon.Code),
+	}
+
+	result, err := testStore.FinishedPurchaseTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+	require.NotEmpty(t, result.CouponDiscount)
+
+	redemption, err := testStore.GetCouponRedemptionByShopOrderID(context.Background(), result.ShopOrderID)
+	require.NoError(t, err)
+	require.Equal(t, coupon.ID, redemption.CouponID)
+	require.Equal(t, userAddress.UserID, redemption.UserID)
+	require.Equal(t, result.CouponDiscount, redemption.DiscountAmount)
+
+	redeemedCoupon, err := testStore.GetCouponByCodeForUpdate(context.Background(), coupon.Code)
+	require.NoError(t, err)
+	require.Equal(t, coupon.UsedCount+1, redeemedCoupon.UsedCount)
+
+	// the coupon allows a single use per user
+	shoppingCart, _ = createRandomCartWithItem(t, userAddress.UserID)
+	arg.ShoppingCartID = shoppingCart.ID
+
+	result, err = testStore.FinishedPurchaseTx(context.Background(), arg)
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var couponErr *CouponNotApplicableError
+	require.ErrorAs(t, err, &couponErr)
+}
+
+// the whole stock left in a size can be held and bought, only asking for more than it is rejected
+func TestFinishedPurchaseTxLastUnits(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	orderStatus := createRandomOrderStatus(t)
+
+	shoppingCart, err := testStore.CreateShoppingCart(context.Background(), userAddress.UserID)
+	require.NoError(t, err)
+
+	productItem := createRandomProductItem(t)
+	size := createRandomProductSizeWithItemID(t, productItem.ID)
+
+	_, err = testStore.CreateShoppingCartItem(context.Background(), CreateShoppingCartItemParams{
+		ShoppingCartID: shoppingCart.ID,
+		ProductItemID:  size.ProductItemID,
+		SizeID:         size.ID,
+		Qty:            size.Qty,
+	})
+	require.NoError(t, err)
+
+	quote, err := testStore.QuoteCartTx(context.Background(), QuoteCartTxParams{
+		UserID:           userAddress.UserID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, quote.Lines, 1)
+	require.Empty(t, quote.Lines[0].StockWarning)
+
+	_, err = testStore.ReserveCartTx(context.Background(), ReserveCartTxParams{
+		UserID:         userAddress.UserID,
+		ShoppingCartID: shoppingCart.ID,
+		Duration:       time.Minute,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+
+	productSize, err := testStore.GetProductSize(context.Background(), size.ID)
+	require.NoError(t, err)
+	require.Zero(t, productSize.Qty)
+}
+
 func TestFinishedPurchaseTxFailedNotEnoughStock(t *testing.T) {
 
 	// store := NewStore(testDB)
@@ -278,7 +514,7 @@ func TestFinishedPurchaseTxFailedNotEnoughStock(t *testing.T) {
 				ShoppingCartID: shoppingCart.ID,
 				ProductItemID:  size.ProductItemID,
 				SizeID:         size.ID,
-				Qty:            size.Qty,
+				Qty:            size.Qty + 1,
 			})
 			if err != nil {
 				log.Fatal("err is: ", err)
@@ -448,9 +684,18 @@ func TestFinishedPurchaseTxFailedEmptyStock(t *testing.T) {
 
 func TestDeleteShopOrderItemTx(t *testing.T) {
 	admin := createRandomAdmin(t)
-	shopOrderItem, shopOrder := createRandomShopOrderItem(t)
+	purchase, _ := createRandomPurchase(t, OrderStatusPending)
+
+	shopOrder, err := testStore.GetShopOrder(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
 
-	err := testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
+	shopOrderItem, err := testStore.GetShopOrderItem(context.Background(), purchase.ShopOrderItemID)
+	require.NoError(t, err)
+
+	productSize, err := testStore.GetProductSize(context.Background(), shopOrderItem.SizeID.Int64)
+	require.NoError(t, err)
+
+	_, err = testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
 		ShopOrderItemID: shopOrderItem.ID,
 		AdminID:         admin.ID,
 	})
@@ -463,9 +708,156 @@ func TestDeleteShopOrderItemTx(t *testing.T) {
 	require.Empty(t, deletedShopOrderItem)
 
 	updatedShopOrder, err := testStore.GetShopOrder(context.Background(), shopOrder.ID)
+	require.NoError(t, err)
+
+	lineTotal, err := discountedLineTotal(shopOrderItem.Price, shopOrderItem.Quantity, int64(shopOrderItem.Discount))
+	require.NoError(t, err)
+	require.Equal(t, udecimal.MustParse(shopOrder.OrderTotal).Sub(lineTotal).StringFixed(2), updatedShopOrder.OrderTotal)
+
+	restockedSize, err := testStore.GetProductSize(context.Background(), productSize.ID)
+	require.NoError(t, err)
+	require.Equal(t, productSize.Qty+shopOrderItem.Quantity, restockedSize.Qty)
+}
+
+func TestDeleteShopOrderItemTxCancelledOrder(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, userID := createRandomPurchase(t, OrderStatusPending)
+
+	// the cancellation restocked the items and refunded the order
+	_, err := testStore.CancelShopOrderTx(context.Background(), CancelShopOrderTxParams{
+		UserID:      userID,
+		ShopOrderID: purchase.ShopOrderID,
+	})
+	require.NoError(t, err)
+
+	shopOrderItem, err := testStore.GetShopOrderItem(context.Background(), purchase.ShopOrderItemID)
+	require.NoError(t, err)
+
+	productSize, err := testStore.GetProductSize(context.Background(), shopOrderItem.SizeID.Int64)
+	require.NoError(t, err)
+
+	_, err = testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
+		ShopOrderItemID: shopOrderItem.ID,
+		AdminID:         admin.ID,
+	})
+	require.ErrorIs(t, err, ErrShopOrderFinished)
+
+	keptShopOrderItem, err := testStore.GetShopOrderItem(context.Background(), shopOrderItem.ID)
+	require.NoError(t, err)
+	require.Equal(t, shopOrderItem.ID, keptShopOrderItem.ID)
+
+	sameSize, err := testStore.GetProductSize(context.Background(), productSize.ID)
+	require.NoError(t, err)
+	require.Equal(t, productSize.Qty, sameSize.Qty)
+}
+
+func TestDeleteShopOrderItemTxReceivedReturn(t *testing.T) {
+	admin := createRandomAdmin(t)
+	created, shopOrderItem := createRandomReturnRequestTx(t)
+
+	// the received return is the record of the qty given back
+	for _, status := range []string{ReturnStatusApproved, ReturnStatusReceived} {
+		_, err := testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+			AdminID:         admin.ID,
+			ReturnRequestID: created.ReturnRequest.ID,
+			Status:          status,
+		})
+		require.NoError(t, err)
+	}
+
+	productSize, err := testStore.GetProductSize(context.Background(), shopOrderItem.SizeID.Int64)
+	require.NoError(t, err)
+
+	_, err = testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
+		ShopOrderItemID: shopOrderItem.ID,
+		AdminID:         admin.ID,
+	})
+	require.ErrorIs(t, err, ErrShopOrderItemReturned)
+
+	returnRequestItems, err := testStore.ListReturnRequestItemsByRequestID(context.Background(), created.ReturnRequest.ID)
+	require.NoError(t, err)
+	require.Len(t, returnRequestItems, 1)
+
+	sameSize, err := testStore.GetProductSize(context.Background(), productSize.ID)
+	require.NoError(t, err)
+	require.Equal(t, productSize.Qty, sameSize.Qty)
+}
+
+func TestDeleteShopOrderItemTxCouponShare(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, _, eligibleItem, _ := createRandomScopedCouponPurchase(t, admin)
+
+	_, err := testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
+		ShopOrderItemID: eligibleItem.ID,
+		AdminID:         admin.ID,
+	})
+	require.NoError(t, err)
 
+	updatedShopOrder, err := testStore.GetShopOrder(context.Background(), purchase.ShopOrderID)
 	require.NoError(t, err)
-	require.NotEqual(t, updatedShopOrder, shopOrder)
-	// require.NotEqual(t, updatedShopOrder.OrderTotal, shopOrder.OrderTotal)
 
+	// the order total only drops by what the line was paid with
+	lineTotal, err := discountedLineTotal(eligibleItem.Price, eligibleItem.Quantity, int64(eligibleItem.Discount))
+	require.NoError(t, err)
+	paidTotal := lineTotal.Sub(udecimal.MustParse(eligibleItem.CouponDiscount))
+	require.Equal(t, udecimal.MustParse(purchase.OrderTotal).Sub(paidTotal).StringFixed(2), updatedShopOrder.OrderTotal)
+}
+
+func TestDeleteShopOrderItemTxCapturedPayment(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, _, _, otherItem := createRandomCapturedReturnPurchase(t, admin)
+
+	result, err := testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
+		ShopOrderItemID: otherItem.ID,
+		AdminID:         admin.ID,
+	})
+	require.NoError(t, err)
+
+	// what the line was paid with is given back from the captured payment
+	lineTotal, err := discountedLineTotal(otherItem.Price, otherItem.Quantity, int64(otherItem.Discount))
+	require.NoError(t, err)
+	paidTotal := lineTotal.Sub(udecimal.MustParse(otherItem.CouponDiscount))
+
+	paymentTransaction, err := testStore.GetPaymentTransaction(context.Background(), purchase.PaymentTransaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, paidTotal.StringFixed(2), paymentTransaction.PendingRefundAmount)
+	require.Equal(t, purchase.PaymentTransaction.Amount, paymentTransaction.Amount)
+	require.Equal(t, udecimal.MustParse(purchase.OrderTotal).Sub(paidTotal).StringFixed(2), result.ShopOrder.OrderTotal)
+}
+
+func TestDeleteShopOrderItemTxPendingPayment(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase := createRandomAwaitingPaymentPurchase(t)
+
+	result, err := testStore.DeleteShopOrderItemTx(context.Background(), DeleteShopOrderItemTxParams{
+		ShopOrderItemID: purchase.ShopOrderItemID,
+		AdminID:         admin.ID,
+	})
+	require.NoError(t, err)
+
+	// the provider is asked for the new total
+	paymentTransaction, err := testStore.GetPaymentTransaction(context.Background(), purchase.PaymentTransaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.ShopOrder.OrderTotal, paymentTransaction.Amount)
+	require.True(t, udecimal.MustParse(paymentTransaction.PendingRefundAmount).IsZero())
+}
+
+func TestUpdateShopOrderTxOrderTotalAbovePayment(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, _, _, _ := createRandomCapturedReturnPurchase(t, admin)
+
+	raisedTotal := udecimal.MustParse(purchase.OrderTotal).Add(udecimal.MustParse("1"))
+
+	_, err := testStore.UpdateShopOrderTx(context.Background(), UpdateShopOrderTxParams{
+		UpdateShopOrderParams: UpdateShopOrderParams{
+			ID:         purchase.ShopOrderID,
+			AdminID:    admin.ID,
+			OrderTotal: null.StringFrom(raisedTotal.StringFixed(2)),
+		},
+	})
+	require.ErrorIs(t, err, ErrOrderTotalAbovePayment)
+
+	shopOrder, err := testStore.GetShopOrder(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
+	require.Equal(t, purchase.OrderTotal, shopOrder.OrderTotal)
 }
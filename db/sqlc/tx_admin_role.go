@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guregu/null/v6"
+)
+
+// admin_permission codes checked by the admin routes
+const (
+	PermissionDashboardRead    = "dashboard:read"
+	PermissionCatalogManage    = "catalog:manage"
+	PermissionPricingManage    = "pricing:manage"
+	PermissionPromotionsManage = "promotions:manage"
+	PermissionOrdersRead       = "orders:read"
+	PermissionOrdersManage     = "orders:manage"
+	PermissionUsersRead        = "users:read"
+	PermissionUsersManage      = "users:manage"
+	PermissionUsersDelete      = "users:delete"
+	PermissionSettingsManage   = "settings:manage"
+	PermissionRolesManage      = "roles:manage"
+	PermissionSecurityManage   = "security:manage"
+)
+
+// AdminPermissions lists every permission code seeded in admin_permission
+var AdminPermissions = []string{
+	PermissionDashboardRead,
+	PermissionCatalogManage,
+	PermissionPricingManage,
+	PermissionPromotionsManage,
+	PermissionOrdersRead,
+	PermissionOrdersManage,
+	PermissionUsersRead,
+	PermissionUsersManage,
+	PermissionUsersDelete,
+	PermissionSettingsManage,
+	PermissionRolesManage,
+	PermissionSecurityManage,
+}
+
+// SuperAdminRole is the seeded role that holds every permission, it can't be changed or deleted
+const SuperAdminRole = "super_admin"
+
+// IsAdminPermission reports whether code is one of the known admin permissions
+func IsAdminPermission(code string) bool {
+	for _, permission := range AdminPermissions {
+		if permission == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedAdminRoleError is returned when the super admin role is changed or deleted
+type ProtectedAdminRoleError struct {
+	Name string `json:"name"`
+}
+
+func (e *ProtectedAdminRoleError) Error() string {
+	return fmt.Sprintf("admin role %q can't be changed", e.Name)
+}
+
+// AdminRoleTxResult is the result of the admin role transactions
+type AdminRoleTxResult struct {
+	Role        *AdminRole `json:"role"`
+	Permissions []string   `json:"permissions"`
+}
+
+// CreateAdminRoleTxParams contains the input parameters of the admin role creation transaction
+type CreateAdminRoleTxParams struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// CreateAdminRoleTx creates an admin role with its permissions
+func (store *SQLStore) CreateAdminRoleTx(ctx context.Context, arg CreateAdminRoleTxParams) (*AdminRoleTxResult, error) {
+	var result *AdminRoleTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		role, err := q.CreateAdminRole(ctx, CreateAdminRoleParams{
+			Name:        arg.Name,
+			Description: arg.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		permissions, err := createAdminRolePermissions(ctx, q, role.ID, arg.Permissions)
+		if err != nil {
+			return err
+		}
+
+		result = &AdminRoleTxResult{
+			Role:        role,
+			Permissions: permissions,
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// UpdateAdminRoleTxParams contains the input parameters of the admin role update transaction
+type UpdateAdminRoleTxParams struct {
+	ID          int64       `json:"id"`
+	Name        null.String `json:"name"`
+	Description null.String `json:"description"`
+	// Permissions replaces the permissions of the role when it's not nil
+	Permissions []string `json:"permissions"`
+}
+
+/*
+UpdateAdminRoleTx updates an admin role,
+
+the role row is locked and its permissions are replaced when new ones are given,
+the super admin role is refused so there is always a role that can manage the others.
+*/
+func (store *SQLStore) UpdateAdminRoleTx(ctx context.Context, arg UpdateAdminRoleTxParams) (*AdminRoleTxResult, error) {
+	var result *AdminRoleTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		role, err := q.GetAdminRoleForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if role.Name == SuperAdminRole {
+			return &ProtectedAdminRoleError{Name: role.Name}
+		}
+
+		role, err = q.UpdateAdminRole(ctx, UpdateAdminRoleParams{
+			ID:          arg.ID,
+			Name:        arg.Name,
+			Description: arg.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = &AdminRoleTxResult{
+			Role: role,
+		}
+
+		if arg.Permissions == nil {
+			rolePermissions, err := q.ListAdminRolePermissionsByRoleIDs(ctx, []int64{role.ID})
+			if err != nil {
+				return err
+			}
+
+			result.Permissions = make([]string, 0, len(rolePermissions))
+			for _, rolePermission := range rolePermissions {
+				result.Permissions = append(result.Permissions, rolePermission.Permission)
+			}
+			return nil
+		}
+
+		err = q.DeleteAdminRolePermissionsByRoleID(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Permissions, err = createAdminRolePermissions(ctx, q, role.ID, arg.Permissions)
+		return err
+	})
+
+	return result, err
+}
+
+// DeleteAdminRoleTx deletes an admin role and takes it off the admins holding it,
+// the super admin role is refused
+func (store *SQLStore) DeleteAdminRoleTx(ctx context.Context, id int64) (*AdminRole, error) {
+	var result *AdminRole
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		role, err := q.GetAdminRoleForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if role.Name == SuperAdminRole {
+			return &ProtectedAdminRoleError{Name: role.Name}
+		}
+
+		result, err = q.DeleteAdminRole(ctx, id)
+		return err
+	})
+
+	return result, err
+}
+
+// SetAdminRolesTxParams contains the input parameters of the admin role assignment transaction
+type SetAdminRolesTxParams struct {
+	AdminID int64   `json:"admin_id"`
+	RoleIDs []int64 `json:"role_ids"`
+}
+
+// SetAdminRolesTx replaces the roles assigned to the admin
+func (store *SQLStore) SetAdminRolesTx(ctx context.Context, arg SetAdminRolesTxParams) ([]*AdminRole, error) {
+	var result []*AdminRole
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		err = q.DeleteAdminRoleAssignmentsByAdminID(ctx, arg.AdminID)
+		if err != nil {
+			return err
+		}
+
+		for _, roleID := range arg.RoleIDs {
+			_, err = q.CreateAdminRoleAssignment(ctx, CreateAdminRoleAssignmentParams{
+				AdminID: arg.AdminID,
+				RoleID:  roleID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err = q.ListAdminRolesByAdminID(ctx, arg.AdminID)
+		return err
+	})
+
+	return result, err
+}
+
+func createAdminRolePermissions(ctx context.Context, q *Queries, roleID int64, permissions []string) ([]string, error) {
+	created := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		rolePermission, err := q.CreateAdminRolePermission(ctx, CreateAdminRolePermissionParams{
+			RoleID:     roleID,
+			Permission: permission,
+		})
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, rolePermission.Permission)
+	}
+	return created, nil
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAdminRole(t *testing.T, permissions ...string) *AdminRoleTxResult {
+	arg := CreateAdminRoleTxParams{
+		Name:        util.RandomString(10),
+		Description: util.RandomString(20),
+		Permissions: permissions,
+	}
+
+	result, err := testStore.CreateAdminRoleTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Role)
+
+	require.Equal(t, arg.Name, result.Role.Name)
+	require.Equal(t, arg.Description, result.Role.Description)
+	require.ElementsMatch(t, arg.Permissions, result.Permissions)
+	require.NotZero(t, result.Role.ID)
+
+	return result
+}
+
+func TestCreateAdminRoleTx(t *testing.T) {
+	createRandomAdminRole(t, PermissionOrdersRead, PermissionOrdersManage)
+}
+
+func TestUpdateAdminRoleTx(t *testing.T) {
+	role := createRandomAdminRole(t, PermissionOrdersRead)
+
+	// permissions are kept when none are given
+	result, err := testStore.UpdateAdminRoleTx(context.Background(), UpdateAdminRoleTxParams{
+		ID:          role.Role.ID,
+		Description: null.StringFrom(util.RandomString(20)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, role.Role.Name, result.Role.Name)
+	require.Equal(t, []string{PermissionOrdersRead}, result.Permissions)
+
+	result, err = testStore.UpdateAdminRoleTx(context.Background(), UpdateAdminRoleTxParams{
+		ID:          role.Role.ID,
+		Permissions: []string{PermissionCatalogManage, PermissionPricingManage},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{PermissionCatalogManage, PermissionPricingManage}, result.Permissions)
+
+	_, err = testStore.UpdateAdminRoleTx(context.Background(), UpdateAdminRoleTxParams{
+		ID:          role.Role.ID,
+		Permissions: []string{"unknown:permission"},
+	})
+	require.Error(t, err)
+}
+
+func TestSuperAdminRoleIsProtected(t *testing.T) {
+	superAdminRole := getSuperAdminRole(t)
+
+	result, err := testStore.UpdateAdminRoleTx(context.Background(), UpdateAdminRoleTxParams{
+		ID:          superAdminRole.ID,
+		Permissions: []string{PermissionDashboardRead},
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var protectedErr *ProtectedAdminRoleError
+	require.ErrorAs(t, err, &protectedErr)
+	require.Equal(t, SuperAdminRole, protectedErr.Name)
+
+	deletedRole, err := testStore.DeleteAdminRoleTx(context.Background(), superAdminRole.ID)
+	require.ErrorAs(t, err, &protectedErr)
+	require.Empty(t, deletedRole)
+}
+
+func TestSetAdminRolesTx(t *testing.T) {
+	admin := createRandomAdmin(t)
+	ordersRole := createRandomAdminRole(t, PermissionOrdersRead, PermissionOrdersManage)
+	catalogRole := createRandomAdminRole(t, PermissionCatalogManage)
+
+	roles, err := testStore.SetAdminRolesTx(context.Background(), SetAdminRolesTxParams{
+		AdminID: admin.ID,
+		RoleIDs: []int64{ordersRole.Role.ID, catalogRole.Role.ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+
+	allowed, err := testStore.AdminHasPermission(context.Background(), AdminHasPermissionParams{
+		AdminID:    admin.ID,
+		Permission: PermissionCatalogManage,
+	})
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// the assignments are replaced
+	roles, err = testStore.SetAdminRolesTx(context.Background(), SetAdminRolesTxParams{
+		AdminID: admin.ID,
+		RoleIDs: []int64{ordersRole.Role.ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	require.Equal(t, ordersRole.Role.ID, roles[0].ID)
+
+	allowed, err = testStore.AdminHasPermission(context.Background(), AdminHasPermissionParams{
+		AdminID:    admin.ID,
+		Permission: PermissionCatalogManage,
+	})
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// deleting a role takes it off the admin
+	deletedRole, err := testStore.DeleteAdminRoleTx(context.Background(), ordersRole.Role.ID)
+	require.NoError(t, err)
+	require.Equal(t, ordersRole.Role.ID, deletedRole.ID)
+
+	roles, err = testStore.ListAdminRolesByAdminID(context.Background(), admin.ID)
+	require.NoError(t, err)
+	require.Empty(t, roles)
+
+	_, err = testStore.GetAdminRole(context.Background(), ordersRole.Role.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func getSuperAdminRole(t *testing.T) *AdminRole {
+	roles, err := testStore.ListAdminRoles(context.Background(), ListAdminRolesParams{
+		Limit:  10,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+
+	for _, role := range roles {
+		if role.Name == SuperAdminRole {
+			return role
+		}
+	}
+
+	t.Fatalf("role %q isn't seeded", SuperAdminRole)
+	return nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+)
+
+// EnableAdminTotpTxParams contains the input parameters of the admin totp enabling transaction
+type EnableAdminTotpTxParams struct {
+	AdminID int64 `json:"admin_id"`
+	// LastUsedStep is the time step of the code that confirmed the enrolment
+	LastUsedStep int64 `json:"last_used_step"`
+	// RecoveryCodeHashes replace the recovery codes the admin had before
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+}
+
+// EnableAdminTotpTx turns on the pending totp of an admin together with a fresh set of recovery codes,
+// it returns pgx.ErrNoRows when there is no pending totp to enable
+func (store *SQLStore) EnableAdminTotpTx(ctx context.Context, arg EnableAdminTotpTxParams) (*AdminTotp, error) {
+	var result *AdminTotp
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.EnableAdminTotp(ctx, EnableAdminTotpParams{
+			AdminID:      arg.AdminID,
+			LastUsedStep: arg.LastUsedStep,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.ReplaceAdminRecoveryCodes(ctx, ReplaceAdminRecoveryCodesParams{
+			AdminID:    arg.AdminID,
+			CodeHashes: arg.RecoveryCodeHashes,
+		})
+	})
+
+	return result, err
+}
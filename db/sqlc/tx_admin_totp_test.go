@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableAdminTotpTx(t *testing.T) {
+	admin := createRandomAdmin(t)
+
+	pending, err := testStore.UpsertAdminTotp(context.Background(), UpsertAdminTotpParams{
+		AdminID: admin.ID,
+		Secret:  util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.False(t, pending.Enabled)
+
+	// a code of a pending totp isn't accepted at login
+	_, err = testStore.UpdateAdminTotpLastUsedStep(context.Background(), UpdateAdminTotpLastUsedStepParams{
+		AdminID:      admin.ID,
+		LastUsedStep: 10,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	hashes := []string{util.RandomString(64), util.RandomString(64)}
+	enabled, err := testStore.EnableAdminTotpTx(context.Background(), EnableAdminTotpTxParams{
+		AdminID:            admin.ID,
+		LastUsedStep:       10,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+	require.True(t, enabled.Enabled)
+	require.Equal(t, pending.Secret, enabled.Secret)
+	require.Equal(t, int64(10), enabled.LastUsedStep)
+	require.WithinDuration(t, time.Now(), enabled.EnabledAt, 5*time.Second)
+
+	left, err := testStore.CountAdminRecoveryCodesLeft(context.Background(), admin.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(hashes)), left)
+
+	// the secret of an enabled totp can't be replaced
+	_, err = testStore.UpsertAdminTotp(context.Background(), UpsertAdminTotpParams{
+		AdminID: admin.ID,
+		Secret:  util.RandomString(32),
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testStore.EnableAdminTotpTx(context.Background(), EnableAdminTotpTxParams{
+		AdminID:      admin.ID,
+		LastUsedStep: 11,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// a step can only be used once
+	_, err = testStore.UpdateAdminTotpLastUsedStep(context.Background(), UpdateAdminTotpLastUsedStepParams{
+		AdminID:      admin.ID,
+		LastUsedStep: 10,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	updated, err := testStore.UpdateAdminTotpLastUsedStep(context.Background(), UpdateAdminTotpLastUsedStepParams{
+		AdminID:      admin.ID,
+		LastUsedStep: 11,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), updated.LastUsedStep)
+}
+
+func TestUseAdminRecoveryCode(t *testing.T) {
+	admin := createRandomAdmin(t)
+	hash := util.RandomString(64)
+
+	err := testStore.ReplaceAdminRecoveryCodes(context.Background(), ReplaceAdminRecoveryCodesParams{
+		AdminID:    admin.ID,
+		CodeHashes: []string{hash, util.RandomString(64)},
+	})
+	require.NoError(t, err)
+
+	arg := UseAdminRecoveryCodeParams{
+		AdminID:  admin.ID,
+		CodeHash: hash,
+	}
+
+	code, err := testStore.UseAdminRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, code.IsUsed)
+
+	_, err = testStore.UseAdminRecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	left, err := testStore.CountAdminRecoveryCodesLeft(context.Background(), admin.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), left)
+
+	// new codes replace the old ones, used or not
+	err = testStore.ReplaceAdminRecoveryCodes(context.Background(), ReplaceAdminRecoveryCodesParams{
+		AdminID:    admin.ID,
+		CodeHashes: []string{util.RandomString(64), util.RandomString(64), util.RandomString(64)},
+	})
+	require.NoError(t, err)
+
+	left, err = testStore.CountAdminRecoveryCodesLeft(context.Background(), admin.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), left)
+}
+
+func TestAdminLoginChallenge(t *testing.T) {
+	admin := createRandomAdmin(t)
+
+	challenge, err := testStore.CreateAdminLoginChallenge(context.Background(), CreateAdminLoginChallengeParams{
+		AdminID:   admin.ID,
+		TokenHash: util.RandomString(64),
+		ExpiredAt: time.Now().Add(5 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	got, err := testStore.GetAdminLoginChallenge(context.Background(), challenge.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, challenge.ID, got.ID)
+
+	for attempts := int32(1); attempts <= 2; attempts++ {
+		got, err = testStore.IncrementAdminLoginChallengeAttempts(context.Background(), IncrementAdminLoginChallengeAttemptsParams{
+			MaxAttempts: 2,
+			ID:          challenge.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, attempts, got.Attempts)
+	}
+
+	// the last attempt expired the challenge
+	_, err = testStore.GetAdminLoginChallenge(context.Background(), challenge.TokenHash)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testStore.UseAdminLoginChallenge(context.Background(), challenge.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+
+	"github.com/guregu/null/v6"
+)
+
+type AnonymizeUserTxResult struct {
+	User *User `json:"user"`
+	// RedactedOrders are the shop orders kept for accounting without their address
+	RedactedOrders   int64 `json:"redacted_orders"`
+	DeletedAddresses int64 `json:"deleted_addresses"`
+	DeletedSessions  int64 `json:"deleted_sessions"`
+}
+
+/*
+AnonymizeUserTx erases the personal data of a user whose deletion grace period is over,
+
+the user row and its shop orders are kept for accounting, the orders lose their address snapshot,
+the addresses, sessions, device tokens and pending otps of the user are deleted.
+pgx.ErrNoRows is returned when the deletion was cancelled or isn't due yet.
+*/
+func (store *SQLStore) AnonymizeUserTx(ctx context.Context, userID int64) (*AnonymizeUserTxResult, error) {
+	var result *AnonymizeUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		_, err = q.MarkUserDeletionAnonymized(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// the default address is cleared before the addresses are deleted
+		user, err := q.AnonymizeUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		redactedOrders, err := q.RedactShopOrderAddresses(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		deletedAddresses, err := q.DeleteAddressesByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		deletedSessions, err := q.DeleteUserSessions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteNotificationAllByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteVerifyEmailsByUserID(ctx, null.IntFrom(userID))
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteResetPasswordsByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteEmailChangesByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		result = &AnonymizeUserTxResult{
+			User:             user,
+			RedactedOrders:   redactedOrders,
+			DeletedAddresses: deletedAddresses,
+			DeletedSessions:  deletedSessions,
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymizeUserTx(t *testing.T) {
+	shopOrder := createRandomShopOrder(t)
+	user, err := testStore.GetUser(context.Background(), shopOrder.UserID)
+	require.NoError(t, err)
+	createUserSessionForUser(t, user.ID)
+
+	// the deletion isn't due during the grace period
+	_, err = testStore.UpsertUserDeletion(context.Background(), UpsertUserDeletionParams{
+		UserID:      user.ID,
+		DeleteAfter: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.AnonymizeUserTx(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testStore.UpsertUserDeletion(context.Background(), UpsertUserDeletionParams{
+		UserID:      user.ID,
+		DeleteAfter: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	dueUsers, err := testStore.ListDueUserDeletions(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, dueUsers, user.ID)
+
+	result, err := testStore.AnonymizeUserTx(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, result.User.ID)
+	require.NotEqual(t, user.Email, result.User.Email)
+	require.Empty(t, result.User.Password)
+	require.True(t, result.User.IsBlocked)
+	require.Equal(t, int64(1), result.RedactedOrders)
+	require.Equal(t, int64(1), result.DeletedAddresses)
+	require.Equal(t, int64(1), result.DeletedSessions)
+
+	// the order is kept without the address of the user
+	gotOrder, err := testStore.GetShopOrder(context.Background(), shopOrder.ID)
+	require.NoError(t, err)
+	require.False(t, gotOrder.ShippingAddressID.Valid)
+	require.Equal(t, "redacted", gotOrder.AddressLine)
+
+	userDeletion, err := testStore.GetUserDeletion(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.False(t, userDeletion.AnonymizedAt.IsZero())
+
+	// an anonymized user can't be anonymized or restored again
+	_, err = testStore.AnonymizeUserTx(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testStore.CancelUserDeletion(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestCancelUserDeletion(t *testing.T) {
+	user := createRandomUser(t)
+
+	userDeletion, err := testStore.UpsertUserDeletion(context.Background(), UpsertUserDeletionParams{
+		UserID:      user.ID,
+		DeleteAfter: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, userDeletion.UserID)
+	require.True(t, userDeletion.AnonymizedAt.IsZero())
+
+	_, err = testStore.CancelUserDeletion(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	_, err = testStore.GetUserDeletion(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
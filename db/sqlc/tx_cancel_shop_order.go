@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+)
+
+// CancelShopOrderTxParams contains the input parameters of the shop order cancellation transaction
+type CancelShopOrderTxParams struct {
+	UserID      int64 `json:"user_id"`
+	ShopOrderID int64 `json:"shop_order_id"`
+}
+
+// CancelShopOrderTxResult is the result of the shop order cancellation transaction
+type CancelShopOrderTxResult struct {
+	ShopOrder      *ShopOrder              `json:"shop_order"`
+	OrderStatus    *OrderStatus            `json:"order_status"`
+	History        *ShopOrderStatusHistory `json:"history"`
+	RestockedSizes []*ProductSize          `json:"restocked_sizes"`
+}
+
+/*
+CancelShopOrderTx cancels the user's shop order,
+
+the order row is locked and it can only be cancelled while its status allows moving
+to cancelled in the order state machine, the qty of every order item is returned
+to its product size, the coupon redeemed by the order is released and the change
+is recorded in shop_order_status_history.
+*/
+func (store *SQLStore) CancelShopOrderTx(ctx context.Context, arg CancelShopOrderTxParams) (*CancelShopOrderTxResult, error) {
+	var result *CancelShopOrderTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		shopOrder, err := q.GetShopOrderForUpdate(ctx, arg.ShopOrderID)
+		if err != nil {
+			return err
+		}
+
+		if shopOrder.UserID != arg.UserID {
+			return pgx.ErrNoRows
+		}
+
+		var fromCode string
+		if shopOrder.OrderStatusID.Valid {
+			fromStatus, err := q.GetOrderStatus(ctx, shopOrder.OrderStatusID.Int64)
+			if err != nil {
+				return err
+			}
+			fromCode = fromStatus.Code.String
+		}
+
+		// unlike the admin, the user can't cancel an order with a status outside the state machine
+		if fromCode == "" || !CanTransitionOrderStatus(fromCode, OrderStatusCancelled) {
+			return &InvalidOrderStatusTransitionError{
+				From: fromCode,
+				To:   OrderStatusCancelled,
+			}
+		}
+
+		cancelledStatus, err := q.GetOrderStatusByCode(ctx, null.StringFrom(OrderStatusCancelled))
+		if err != nil {
+			return err
+		}
+
+		restockedSizes, err := releaseShopOrder(ctx, q, shopOrder.ID)
+		if err != nil {
+			return err
+		}
+
+		result = &CancelShopOrderTxResult{
+			OrderStatus:    cancelledStatus,
+			RestockedSizes: restockedSizes,
+		}
+
+		result.ShopOrder, err = q.UpdateShopOrderStatusByUserID(ctx, UpdateShopOrderStatusByUserIDParams{
+			OrderStatusID: null.IntFrom(cancelledStatus.ID),
+			ID:            shopOrder.ID,
+			UserID:        arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.History, err = q.CreateShopOrderStatusHistory(ctx, CreateShopOrderStatusHistoryParams{
+			ShopOrderID:  shopOrder.ID,
+			FromStatusID: shopOrder.OrderStatusID,
+			ToStatusID:   cancelledStatus.ID,
+			Note:         "cancelled by the user",
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// releaseShopOrder returns the qty of the order items that isn't back in stock yet to their product sizes,
+// records the refund of what's left of the captured payment and releases the coupon redeemed by the order,
+// every move of an order to cancelled or returned goes through it
+func releaseShopOrder(ctx context.Context, q *Queries, shopOrderID int64) ([]*ProductSize, error) {
+	items, err := q.ListShopOrderItemsToRestock(ctx, shopOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	restockedSizes := make([]*ProductSize, 0, len(items))
+	for _, item := range items {
+		// items made before the size was kept on the order can't be restocked
+		if !item.SizeID.Valid || item.Qty <= 0 {
+			continue
+		}
+
+		productSize, err := q.RestockProductSize(ctx, RestockProductSizeParams{
+			ID:  item.SizeID.Int64,
+			Qty: item.Qty,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		restockedSizes = append(restockedSizes, productSize)
+	}
+
+	_, _, err = requestOrderRefund(ctx, q, shopOrderID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	redemption, err := q.DeleteCouponRedemptionByShopOrderID(ctx, shopOrderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return restockedSizes, nil
+		}
+		return nil, err
+	}
+
+	_, err = q.DecrementCouponUsedCount(ctx, redemption.CouponID)
+	if err != nil {
+		return nil, err
+	}
+
+	return restockedSizes, nil
+}
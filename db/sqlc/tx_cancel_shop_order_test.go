@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelShopOrderTx(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	shoppingCart, _ := createRandomCartWithItem(t, userAddress.UserID)
+
+	pending, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusPending))
+	require.NoError(t, err)
+
+	cartItems, err := testStore.ListShoppingCartItemsByCartID(context.Background(), shoppingCart.ID)
+	require.NoError(t, err)
+	require.Len(t, cartItems, 1)
+
+	productSize, err := testStore.GetProductSize(context.Background(), cartItems[0].SizeID)
+	require.NoError(t, err)
+
+	purchase, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    pending.ID,
+	})
+	require.NoError(t, err)
+
+	// another user can't cancel the order
+	result, err := testStore.CancelShopOrderTx(context.Background(), CancelShopOrderTxParams{
+		UserID:      userAddress.UserID + 1,
+		ShopOrderID: purchase.ShopOrderID,
+	})
+	require.Error(t, err)
+	require.EqualError(t, err, pgx.ErrNoRows.Error())
+	require.Empty(t, result)
+
+	result, err = testStore.CancelShopOrderTx(context.Background(), CancelShopOrderTxParams{
+		UserID:      userAddress.UserID,
+		ShopOrderID: purchase.ShopOrderID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+	require.Equal(t, null.StringFrom(OrderStatusCancelled), result.OrderStatus.Code)
+	require.Equal(t, null.IntFrom(result.OrderStatus.ID), result.ShopOrder.OrderStatusID)
+	require.Equal(t, null.IntFrom(pending.ID), result.History.FromStatusID)
+	require.Equal(t, result.OrderStatus.ID, result.History.ToStatusID)
+	require.False(t, result.History.AdminID.Valid)
+
+	require.Len(t, result.RestockedSizes, 1)
+	require.Equal(t, productSize.ID, result.RestockedSizes[0].ID)
+	require.Equal(t, productSize.Qty, result.RestockedSizes[0].Qty)
+
+	// a cancelled order can't be cancelled again
+	result, err = testStore.CancelShopOrderTx(context.Background(), CancelShopOrderTxParams{
+		UserID:      userAddress.UserID,
+		ShopOrderID: purchase.ShopOrderID,
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var transitionErr *InvalidOrderStatusTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	require.Equal(t, OrderStatusCancelled, transitionErr.From)
+
+	restockedSize, err := testStore.GetProductSize(context.Background(), productSize.ID)
+	require.NoError(t, err)
+	require.Equal(t, productSize.Qty, restockedSize.Qty)
+}
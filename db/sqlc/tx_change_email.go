@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ChangeUserEmailTxParams contains the input parameters of the email change transaction
+type ChangeUserEmailTxParams struct {
+	// EmailChangeID is the confirmed email change request
+	EmailChangeID int64 `json:"email_change_id"`
+	UserID        int64 `json:"user_id"`
+	// CurrentSessionID is the session confirming the change, it stays active
+	CurrentSessionID uuid.UUID `json:"current_session_id"`
+}
+
+type ChangeUserEmailTxResult struct {
+	User            *User  `json:"user"`
+	OldEmail        string `json:"old_email"`
+	RevokedSessions int64  `json:"revoked_sessions"`
+}
+
+/*
+ChangeUserEmailTx commits the new email of a confirmed email change request,
+
+the request is used up in the same transaction so its otp can't change the email twice,
+and every other session of the user is revoked.
+A unique violation is returned when the new email was taken since the request.
+*/
+func (store *SQLStore) ChangeUserEmailTx(ctx context.Context, arg ChangeUserEmailTxParams) (*ChangeUserEmailTxResult, error) {
+	var result *ChangeUserEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		emailChange, err := q.UseEmailChange(ctx, arg.EmailChangeID)
+		if err != nil {
+			return err
+		}
+		if emailChange.UserID != arg.UserID {
+			return pgx.ErrNoRows
+		}
+
+		oldUser, err := q.GetUser(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		user, err := q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+			Email: emailChange.NewEmail,
+			ID:    arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		revoked, err := q.RevokeOtherUserSessions(ctx, RevokeOtherUserSessionsParams{
+			UserID:           arg.UserID,
+			CurrentSessionID: arg.CurrentSessionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = &ChangeUserEmailTxResult{
+			User:            user,
+			OldEmail:        oldUser.Email,
+			RevokedSessions: revoked,
+		}
+		return nil
+	})
+
+	return result, err
+}
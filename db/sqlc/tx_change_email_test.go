@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func createUserSessionForUser(t *testing.T, userID int64) *UserSession {
+	t.Helper()
+	id := uuid.New()
+
+	userSession, err := testStore.CreateUserSession(context.Background(), CreateUserSessionParams{
+		ID:           id,
+		UserID:       userID,
+		RefreshToken: util.RandomString(100),
+		UserAgent:    util.RandomString(6),
+		ClientIp:     util.RandomString(10),
+		ExpiresAt:    time.Now().Add(time.Hour).UTC(),
+		FamilyID:     id,
+	})
+	require.NoError(t, err)
+	return userSession
+}
+
+func TestChangeUserEmailTx(t *testing.T) {
+	user := createRandomUser(t)
+	currentSession := createUserSessionForUser(t, user.ID)
+	otherSession := createUserSessionForUser(t, user.ID)
+
+	emailChange, err := testStore.CreateEmailChange(context.Background(), CreateEmailChangeParams{
+		UserID:     user.ID,
+		NewEmail:   util.RandomEmail(),
+		SecretCode: util.RandomString(60),
+	})
+	require.NoError(t, err)
+
+	lastEmailChange, err := testStore.GetLastEmailChange(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, emailChange.ID, lastEmailChange.ID)
+
+	arg := ChangeUserEmailTxParams{
+		EmailChangeID:    emailChange.ID,
+		UserID:           user.ID,
+		CurrentSessionID: currentSession.ID,
+	}
+
+	result, err := testStore.ChangeUserEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, result.OldEmail)
+	require.Equal(t, emailChange.NewEmail, result.User.Email)
+	require.True(t, result.User.IsEmailVerified)
+	require.Equal(t, int64(1), result.RevokedSessions)
+
+	gotSession, err := testStore.GetUserSession(context.Background(), currentSession.ID)
+	require.NoError(t, err)
+	require.False(t, gotSession.IsBlocked)
+
+	gotSession, err = testStore.GetUserSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.True(t, gotSession.IsBlocked)
+
+	// the request is used up
+	_, err = testStore.ChangeUserEmailTx(context.Background(), arg)
+	require.Error(t, err)
+}
+
+func TestChangeUserEmailTxTakenEmail(t *testing.T) {
+	user := createRandomUser(t)
+	otherUser := createRandomUser(t)
+	currentSession := createUserSessionForUser(t, user.ID)
+
+	emailChange, err := testStore.CreateEmailChange(context.Background(), CreateEmailChangeParams{
+		UserID:     user.ID,
+		NewEmail:   otherUser.Email,
+		SecretCode: util.RandomString(60),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.ChangeUserEmailTx(context.Background(), ChangeUserEmailTxParams{
+		EmailChangeID:    emailChange.ID,
+		UserID:           user.ID,
+		CurrentSessionID: currentSession.ID,
+	})
+	require.Error(t, err)
+
+	// unique_violation
+	var pqErr *pgconn.PgError
+	require.ErrorAs(t, err, &pqErr)
+	require.Equal(t, "23505", pqErr.Code)
+
+	// the rolled back request can still be confirmed once the email is free
+	lastEmailChange, err := testStore.GetLastEmailChange(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.False(t, lastEmailChange.IsUsed)
+}
+
+func TestIncrementEmailChangeAttempts(t *testing.T) {
+	user := createRandomUser(t)
+
+	emailChange, err := testStore.CreateEmailChange(context.Background(), CreateEmailChangeParams{
+		UserID:     user.ID,
+		NewEmail:   util.RandomEmail(),
+		SecretCode: util.RandomString(60),
+	})
+	require.NoError(t, err)
+
+	arg := IncrementEmailChangeAttemptsParams{MaxAttempts: 2, ID: emailChange.ID}
+
+	emailChange, err = testStore.IncrementEmailChangeAttempts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), emailChange.Attempts)
+
+	// the last attempt expires the otp
+	emailChange, err = testStore.IncrementEmailChangeAttempts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), emailChange.Attempts)
+
+	_, err = testStore.GetLastEmailChange(context.Background(), user.ID)
+	require.Error(t, err)
+}
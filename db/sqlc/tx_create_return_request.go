@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReturnRequestItemParams is an order item and the qty of it the user wants to return
+type ReturnRequestItemParams struct {
+	ShopOrderItemID int64 `json:"shop_order_item_id"`
+	Qty             int32 `json:"qty"`
+}
+
+// CreateReturnRequestTxParams contains the input parameters of the return request transaction
+type CreateReturnRequestTxParams struct {
+	UserID      int64                     `json:"user_id"`
+	ShopOrderID int64                     `json:"shop_order_id"`
+	Reason      string                    `json:"reason"`
+	Items       []ReturnRequestItemParams `json:"items"`
+}
+
+// CreateReturnRequestTxResult is the result of the return request transaction
+type CreateReturnRequestTxResult struct {
+	ReturnRequest *ReturnRequest       `json:"return_request"`
+	Items         []*ReturnRequestItem `json:"items"`
+}
+
+// ReturnNotAllowedError is returned when the order or one of its items can't be returned
+type ReturnNotAllowedError struct {
+	ShopOrderItemID int64  `json:"shop_order_item_id,omitempty"`
+	Reason          string `json:"reason"`
+}
+
+func (e *ReturnNotAllowedError) Error() string {
+	if e.ShopOrderItemID != 0 {
+		return fmt.Sprintf("order item %d can't be returned: %s", e.ShopOrderItemID, e.Reason)
+	}
+	return fmt.Sprintf("order can't be returned: %s", e.Reason)
+}
+
+/*
+CreateReturnRequestTx opens a return request for items of the user's shop order,
+
+the order row is locked and it has to be delivered, every item has to belong to the
+order and its qty can't be more than what is left after the other return requests
+that were not rejected.
+*/
+func (store *SQLStore) CreateReturnRequestTx(ctx context.Context, arg CreateReturnRequestTxParams) (*CreateReturnRequestTxResult, error) {
+	var result *CreateReturnRequestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		shopOrder, err := q.GetShopOrderForUpdate(ctx, arg.ShopOrderID)
+		if err != nil {
+			return err
+		}
+
+		if shopOrder.UserID != arg.UserID {
+			return pgx.ErrNoRows
+		}
+
+		var statusCode string
+		if shopOrder.OrderStatusID.Valid {
+			orderStatus, err := q.GetOrderStatus(ctx, shopOrder.OrderStatusID.Int64)
+			if err != nil {
+				return err
+			}
+			statusCode = orderStatus.Code.String
+		}
+
+		if statusCode != OrderStatusDelivered {
+			return &ReturnNotAllowedError{Reason: "Order is not delivered"}
+		}
+
+		returnRequest, err := q.CreateReturnRequest(ctx, CreateReturnRequestParams{
+			UserID:      arg.UserID,
+			ShopOrderID: shopOrder.ID,
+			Reason:      arg.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = &CreateReturnRequestTxResult{
+			ReturnRequest: returnRequest,
+			Items:         make([]*ReturnRequestItem, 0, len(arg.Items)),
+		}
+
+		for _, item := range arg.Items {
+			shopOrderItem, err := q.GetShopOrderItem(ctx, item.ShopOrderItemID)
+			if err != nil {
+				return err
+			}
+
+			if shopOrderItem.OrderID != shopOrder.ID {
+				return &ReturnNotAllowedError{ShopOrderItemID: item.ShopOrderItemID, Reason: "Item is not in the order"}
+			}
+
+			returnedQty, err := q.GetReturnedQtyByShopOrderItemID(ctx, shopOrderItem.ID)
+			if err != nil {
+				return err
+			}
+
+			if int64(item.Qty) > int64(shopOrderItem.Quantity)-returnedQty {
+				return &ReturnNotAllowedError{ShopOrderItemID: item.ShopOrderItemID, Reason: "Qty is more than what is left to return"}
+			}
+
+			returnRequestItem, err := q.CreateReturnRequestItem(ctx, CreateReturnRequestItemParams{
+				ReturnRequestID: returnRequest.ID,
+				ShopOrderItemID: shopOrderItem.ID,
+				Qty:             item.Qty,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.Items = append(result.Items, returnRequestItem)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomPurchase buys a random cart for a new user and leaves the order in the given status
+func createRandomPurchase(t *testing.T, statusCode string) (*FinishedPurchaseTxResult, int64) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	shoppingCart, _ := createRandomCartWithItem(t, userAddress.UserID)
+
+	orderStatus, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(statusCode))
+	require.NoError(t, err)
+
+	purchase, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+	})
+	require.NoError(t, err)
+
+	return purchase, userAddress.UserID
+}
+
+func createRandomReturnRequestTx(t *testing.T) (*CreateReturnRequestTxResult, *ShopOrderItem) {
+	purchase, userID := createRandomPurchase(t, OrderStatusDelivered)
+
+	shopOrderItems, err := testStore.ListShopOrderItemsByOrderID(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
+	require.Len(t, shopOrderItems, 1)
+
+	result, err := testStore.CreateReturnRequestTx(context.Background(), CreateReturnRequestTxParams{
+		UserID:      userID,
+		ShopOrderID: purchase.ShopOrderID,
+		Reason:      util.RandomString(20),
+		Items: []ReturnRequestItemParams{
+			{ShopOrderItemID: shopOrderItems[0].ID, Qty: shopOrderItems[0].Quantity},
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+
+	return result, shopOrderItems[0]
+}
+
+func TestCreateReturnRequestTx(t *testing.T) {
+	result, shopOrderItem := createRandomReturnRequestTx(t)
+
+	require.Equal(t, ReturnStatusRequested, result.ReturnRequest.Status)
+	require.Equal(t, shopOrderItem.OrderID, result.ReturnRequest.ShopOrderID)
+	require.False(t, result.ReturnRequest.AdminID.Valid)
+	require.False(t, result.ReturnRequest.RefundAmount.Valid)
+
+	require.Len(t, result.Items, 1)
+	require.Equal(t, result.ReturnRequest.ID, result.Items[0].ReturnRequestID)
+	require.Equal(t, shopOrderItem.ID, result.Items[0].ShopOrderItemID)
+	require.Equal(t, shopOrderItem.Quantity, result.Items[0].Qty)
+
+	// the whole qty is already in a return request
+	result2, err := testStore.CreateReturnRequestTx(context.Background(), CreateReturnRequestTxParams{
+		UserID:      result.ReturnRequest.UserID,
+		ShopOrderID: result.ReturnRequest.ShopOrderID,
+		Reason:      util.RandomString(20),
+		Items: []ReturnRequestItemParams{
+			{ShopOrderItemID: shopOrderItem.ID, Qty: 1},
+		},
+	})
+	require.Error(t, err)
+	require.Empty(t, result2)
+
+	var returnErr *ReturnNotAllowedError
+	require.ErrorAs(t, err, &returnErr)
+	require.Equal(t, shopOrderItem.ID, returnErr.ShopOrderItemID)
+
+	// another user can't return the order
+	result2, err = testStore.CreateReturnRequestTx(context.Background(), CreateReturnRequestTxParams{
+		UserID:      result.ReturnRequest.UserID + 1,
+		ShopOrderID: result.ReturnRequest.ShopOrderID,
+		Reason:      util.RandomString(20),
+		Items: []ReturnRequestItemParams{
+			{ShopOrderItemID: shopOrderItem.ID, Qty: 1},
+		},
+	})
+	require.Error(t, err)
+	require.EqualError(t, err, pgx.ErrNoRows.Error())
+	require.Empty(t, result2)
+}
+
+func TestCreateReturnRequestTxNotDelivered(t *testing.T) {
+	purchase, userID := createRandomPurchase(t, OrderStatusPending)
+
+	shopOrderItems, err := testStore.ListShopOrderItemsByOrderID(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
+	require.Len(t, shopOrderItems, 1)
+
+	result, err := testStore.CreateReturnRequestTx(context.Background(), CreateReturnRequestTxParams{
+		UserID:      userID,
+		ShopOrderID: purchase.ShopOrderID,
+		Reason:      util.RandomString(20),
+		Items: []ReturnRequestItemParams{
+			{ShopOrderItemID: shopOrderItems[0].ID, Qty: 1},
+		},
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var returnErr *ReturnNotAllowedError
+	require.ErrorAs(t, err, &returnErr)
+	require.Zero(t, returnErr.ShopOrderItemID)
+}
+
+func TestListReturnRequestsByUserID(t *testing.T) {
+	result, _ := createRandomReturnRequestTx(t)
+
+	returnRequests, err := testStore.ListReturnRequestsByUserID(context.Background(), ListReturnRequestsByUserIDParams{
+		UserID: result.ReturnRequest.UserID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, returnRequests, 1)
+	require.Equal(t, result.ReturnRequest, returnRequests[0])
+
+	returnRequest, err := testStore.GetReturnRequestByUserID(context.Background(), GetReturnRequestByUserIDParams{
+		ID:     result.ReturnRequest.ID,
+		UserID: result.ReturnRequest.UserID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.ReturnRequest, returnRequest)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 
 	"github.com/guregu/null/v6"
 	"github.com/quagmt/udecimal"
@@ -13,69 +14,112 @@ type DeleteShopOrderItemTxParams struct {
 	ShopOrderItemID int64 `json:"shop_order_item_id"`
 }
 
+var (
+	ErrShopOrderItemReturned = errors.New("the order item is part of a return request and can't be deleted")
+	ErrShopOrderFinished     = errors.New("the items of a cancelled or returned order can't be deleted")
+)
+
+// DeleteShopOrderItemTxResult is the result of the shop order item delete transaction
+type DeleteShopOrderItemTxResult struct {
+	ShopOrder *ShopOrder `json:"shop_order"`
+}
+
 /*
-DeleteShopOrderItemTx performs a shop order item delete from DB, and update the new total price in shop order table
+DeleteShopOrderItemTx performs a shop order item delete from DB, returns its qty to
+the product size and update the new total price in shop order table
+
+an item that is part of a return request that wasn't rejected, or of a cancelled or
+returned order, is kept since its return items and refunds are the record of what was
+paid back. the line total is taken off the order total without the coupon share it was bought with,
+and the payment of the order follows the new total, see adjustOrderPayment.
 */
-func (store *SQLStore) DeleteShopOrderItemTx(ctx context.Context, arg DeleteShopOrderItemTxParams) error {
+func (store *SQLStore) DeleteShopOrderItemTx(ctx context.Context, arg DeleteShopOrderItemTxParams) (*DeleteShopOrderItemTxResult, error) {
+	var result *DeleteShopOrderItemTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		arg := DeleteShopOrderItemParams{
-			AdminID: arg.AdminID,
-			ID:      arg.ShopOrderItemID,
-		}
-
-		deletedShopOrderItem, err := q.DeleteShopOrderItem(ctx, arg)
+		shopOrderItem, err := q.GetShopOrderItem(ctx, arg.ShopOrderItemID)
 		if err != nil {
 			return err
 		}
 
-		shopOrder, err := q.GetShopOrder(ctx, deletedShopOrderItem.OrderID)
+		shopOrder, err := q.GetShopOrderForUpdate(ctx, shopOrderItem.OrderID)
 		if err != nil {
 			return err
 		}
 
-		deletedShopOrderItemPrice, err := udecimal.Parse(deletedShopOrderItem.Price)
-		if err != nil {
-			return err
+		if shopOrder.OrderStatusID.Valid {
+			orderStatus, err := q.GetOrderStatus(ctx, shopOrder.OrderStatusID.Int64)
+			if err != nil {
+				return err
+			}
+
+			switch orderStatus.Code.String {
+			case OrderStatusCancelled, OrderStatusReturned:
+				return ErrShopOrderFinished
+			}
 		}
 
-		deletedShopOrderItemDiscount, err := udecimal.NewFromInt64(int64(deletedShopOrderItem.Discount), 2)
+		returnedQty, err := q.GetReturnedQtyByShopOrderItemID(ctx, shopOrderItem.ID)
 		if err != nil {
 			return err
 		}
 
-		shopOrderTotalPrice, err := udecimal.Parse(shopOrder.OrderTotal)
+		if returnedQty > 0 {
+			return ErrShopOrderItemReturned
+		}
+
+		deletedShopOrderItem, err := q.DeleteShopOrderItem(ctx, DeleteShopOrderItemParams{
+			AdminID: arg.AdminID,
+			ID:      arg.ShopOrderItemID,
+		})
 		if err != nil {
 			return err
 		}
 
-		diviBy100, err := udecimal.NewFromInt64(100, 2)
+		// items made before the size was kept on the order can't be restocked
+		if deletedShopOrderItem.SizeID.Valid {
+			_, err = q.RestockProductSize(ctx, RestockProductSizeParams{
+				ID:  deletedShopOrderItem.SizeID.Int64,
+				Qty: deletedShopOrderItem.Quantity,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		shopOrderTotalPrice, err := udecimal.Parse(shopOrder.OrderTotal)
 		if err != nil {
 			return err
 		}
 
-		one, err := udecimal.NewFromInt64(1, 2)
+		deletedLineTotal, err := discountedLineTotal(
+			deletedShopOrderItem.Price,
+			deletedShopOrderItem.Quantity,
+			int64(deletedShopOrderItem.Discount),
+		)
 		if err != nil {
 			return err
 		}
 
-		divResult, err := deletedShopOrderItemDiscount.Div(diviBy100)
+		couponShare, err := itemCouponShare(
+			deletedShopOrderItem.CouponDiscount,
+			deletedShopOrderItem.Quantity,
+			deletedShopOrderItem.Quantity,
+		)
 		if err != nil {
 			return err
 		}
 
-		discountDecimal := one.Sub(divResult)
+		newTotalPrice := shopOrderTotalPrice.Sub(deletedLineTotal.Sub(couponShare))
 
-		qunt, err := udecimal.NewFromInt64(int64(deletedShopOrderItem.Quantity), 2)
+		err = adjustOrderPayment(ctx, q, shopOrder.ID, shopOrder.OrderTotal, newTotalPrice.StringFixed(2))
 		if err != nil {
 			return err
 		}
 
-		newTotalPrice := shopOrderTotalPrice.Sub(deletedShopOrderItemPrice.Mul(qunt).Mul(discountDecimal))
-
-		_, err = q.UpdateShopOrder(ctx, UpdateShopOrderParams{
+		updatedShopOrder, err := q.UpdateShopOrder(ctx, UpdateShopOrderParams{
 			AdminID:    arg.AdminID,
 			ID:         deletedShopOrderItem.OrderID,
 			OrderTotal: null.StringFrom(newTotalPrice.StringFixed(2)),
@@ -84,8 +128,11 @@ func (store *SQLStore) DeleteShopOrderItemTx(ctx context.Context, arg DeleteShop
 			return err
 		}
 
+		result = &DeleteShopOrderItemTxResult{
+			ShopOrder: updatedShopOrder,
+		}
 		return nil
 	})
 
-	return err
+	return result, err
 }
@@ -3,10 +3,12 @@ package db
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
-	"github.com/cshop/v3/util"
+	"github.com/cshop/v3/secure"
 	"github.com/guregu/null/v6"
+	"github.com/quagmt/udecimal"
 )
 
 // FinishedPurchaseTx contains the input parameters of the purchase transaction
@@ -17,16 +19,100 @@ type FinishedPurchaseTxParams struct {
 	PaymentTypeID    int64  `json:"payment_type_id"`
 	ShoppingCartID   int64  `json:"shopping_cart_id"`
 	ShippingMethodID int64  `json:"shipping_method_id"`
-	OrderStatusID    int64  `json:"order_status_id"`
-	OrderTotal       string `json:"order_total"`
+	OrderStatusID    int64  `json:"order_status_id"` // optional, the order waits in awaiting payment when it's not set
+	OrderTotal       string `json:"order_total"`     // optional, only used to verify the server side total
+	CouponCode       string `json:"coupon_code"`     // optional
 }
 
 // FinishedPurchaseTxResult is the result of the purchase transaction
 type FinishedPurchaseTxResult struct {
 	UpdatedProductSizeID int64 `json:"product_size_id"`
 	// UpdatedProductItemID int64 `json:"product_item_id"`
-	ShopOrderID     int64 `json:"shop_order_id"`
-	ShopOrderItemID int64 `json:"shop_order_item_id"`
+	ShopOrderID     int64  `json:"shop_order_id"`
+	ShopOrderItemID int64  `json:"shop_order_item_id"`
+	OrderTotal      string `json:"order_total"`
+	CouponDiscount  string `json:"coupon_discount,omitempty"`
+	// PaymentTransaction is the pending payment of the order at the provider of the payment type
+	PaymentTransaction *PaymentTransaction `json:"payment_transaction"`
+}
+
+// OrderTotalMismatchError is returned when the client sent an order total
+// that is different from the one computed by the server
+type OrderTotalMismatchError struct {
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+}
+
+func (e *OrderTotalMismatchError) Error() string {
+	return fmt.Sprintf("order total mismatch: expected %s, got %s", e.Expected, e.Got)
+}
+
+// maxTrackNumberAttempts track numbers are drawn before the purchase fails, a single collision is already unlikely
+const maxTrackNumberAttempts = 5
+
+var ErrNoFreeTrackNumber = errors.New("couldn't generate a free track number")
+
+var ErrEmptyShoppingCart = errors.New("Shopping Cart Items Not Found")
+
+// newTrackNumber draws track numbers until one isn't used by another shop order,
+// the unique constraint on shop_order.track_number still fails a concurrent purchase that drew the same one
+func newTrackNumber(ctx context.Context, q *Queries) (string, error) {
+	for range maxTrackNumberAttempts {
+		trackNumber := secure.TrackNumber()
+
+		exists, err := q.ShopOrderTrackNumberExists(ctx, trackNumber)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return trackNumber, nil
+		}
+	}
+	return "", ErrNoFreeTrackNumber
+}
+
+// purchaseLine holds the locked product size and the priced values of one cart item
+type purchaseLine struct {
+	cartItem       *ShoppingCartItem
+	productSize    *ProductSize
+	price          string
+	discount       int64
+	productName    string
+	colorValue     null.String
+	lineTotal      udecimal.Decimal
+	couponEligible bool
+	couponDiscount udecimal.Decimal
+}
+
+// spreadCouponDiscount splits the coupon discount over the eligible lines by their total,
+// the last eligible line takes the rounding rest so the shares add up to the discount
+func spreadCouponDiscount(lines []purchaseLine, discountAmount, eligibleTotal udecimal.Decimal) error {
+	last := -1
+	for i := range lines {
+		if lines[i].couponEligible {
+			last = i
+		}
+	}
+
+	rest := discountAmount
+	for i := range lines {
+		if !lines[i].couponEligible {
+			continue
+		}
+
+		if i == last {
+			lines[i].couponDiscount = rest
+			return nil
+		}
+
+		share, err := discountAmount.Mul(lines[i].lineTotal).Div(eligibleTotal)
+		if err != nil {
+			return err
+		}
+		lines[i].couponDiscount = share.RoundBank(2)
+		rest = rest.Sub(lines[i].couponDiscount)
+	}
+	return nil
 }
 
 /*
@@ -35,6 +121,16 @@ type FinishedPurchaseTxResult struct {
 once the payments is finished successfully it creates ShopOrderItem record,
 substract from/update the product DB, adds the products to the users' shop_order_item DB,
 and update products quantity within a single database transaction.
+
+the order total is computed from the locked product item prices, the best active
+promotion of every line and the shipping method price, the client total is only
+compared against it. when a coupon code is given, the coupon row is locked, its discount
+is taken off the total and the redemption is recorded against the created shop order,
+the discount is spread over the lines the coupon applies to and kept on every order item.
+the stock held by other carts is not available, the holds of this cart are consumed.
+every order item keeps the size, the color and the product name it was bought with.
+a pending payment_transaction is created for the provider of the payment type and the
+order waits in awaiting payment until the provider confirms it.
 */
 func (store *SQLStore) FinishedPurchaseTx(ctx context.Context, arg FinishedPurchaseTxParams) (*FinishedPurchaseTxResult, error) {
 	var result *FinishedPurchaseTxResult
@@ -47,44 +143,54 @@ func (store *SQLStore) FinishedPurchaseTx(ctx context.Context, arg FinishedPurch
 			return err
 		}
 
-		if shopCartItems == nil {
-			return errors.New("Shopping Cart Items Not Found")
+		// sqlc returns an empty slice for a cart without items
+		if len(shopCartItems) == 0 {
+			return ErrEmptyShoppingCart
 		}
 
-		trackNumber := util.GenerateTrackNumber()
-
-		// argPM := GetPaymentMethodParams{
-		// 	UserID:        arg.UserID,
-		// 	PaymentTypeID: arg.PaymentTypeID,
-		// }
+		shippingMethod, err := q.GetShippingMethod(ctx, arg.ShippingMethodID)
+		if err != nil {
+			return err
+		}
 
-		// paymentMethod, err := q.GetPaymentMethod(ctx, argPM)
-		// if err != nil {
-		// 	return err
-		// }
+		if shippingMethod == nil {
+			return errors.New("Shipping Method Not Found")
+		}
 
-		createdShopOrder, err := q.CreateShopOrder(ctx, CreateShopOrderParams{
-			TrackNumber:       trackNumber,
-			UserID:            arg.UserID,
-			PaymentTypeID:     arg.PaymentTypeID,
-			ShippingAddressID: null.IntFromPtr(&arg.AddressID),
-			OrderTotal:        arg.OrderTotal,
-			ShippingMethodID:  arg.ShippingMethodID,
-			OrderStatusID:     null.IntFrom(arg.OrderStatusID),
-		})
+		shippingPrice, err := udecimal.Parse(shippingMethod.Price)
 		if err != nil {
 			return err
 		}
 
-		shippingMethod, err := q.GetShippingMethod(ctx, arg.ShippingMethodID)
+		paymentType, err := q.GetPaymentType(ctx, arg.PaymentTypeID)
 		if err != nil {
 			return err
 		}
 
-		if shippingMethod == nil {
-			return errors.New("Shipping Method Not Found")
+		if !paymentType.IsActive {
+			return errors.New("Payment Type Not Active")
+		}
+
+		orderStatusID := arg.OrderStatusID
+		if orderStatusID == 0 {
+			awaitingPayment, err := q.GetOrderStatusByCode(ctx, null.StringFrom(OrderStatusAwaitingPayment))
+			if err != nil {
+				return err
+			}
+			orderStatusID = awaitingPayment.ID
+		}
+
+		var coupon *Coupon
+		if arg.CouponCode != "" {
+			coupon, err = getRedeemableCoupon(ctx, q, NormalizeCouponCode(arg.CouponCode), arg.UserID)
+			if err != nil {
+				return err
+			}
 		}
 
+		subtotal := udecimal.Zero
+		eligibleTotal := udecimal.Zero
+		lines := make([]purchaseLine, 0, len(shopCartItems))
 		for i := 0; i < len(shopCartItems); i++ {
 
 			productSize, err := q.GetProductItemSizeForUpdate(ctx, shopCartItems[i].SizeID)
@@ -96,61 +202,187 @@ func (store *SQLStore) FinishedPurchaseTx(ctx context.Context, arg FinishedPurch
 				return errors.New("Product Size Not Found")
 			}
 
-			if productSize.Qty > 0 && productSize.Qty <= shopCartItems[i].Qty {
+			available, err := availableQty(ctx, q, productSize, arg.ShoppingCartID)
+			if err != nil {
+				return err
+			}
+
+			if available > 0 && available < shopCartItems[i].Qty {
 				return errors.New("Not Enough Qty in Stock")
 			}
 
-			if productSize.Qty <= 0 {
+			if available <= 0 {
 				return errors.New("Stock is Empty")
 			}
 
-			result = &FinishedPurchaseTxResult{
-				ShopOrderID: createdShopOrder.ID,
+			productItem, err := q.GetProductItemWithPromotions(ctx, shopCartItems[i].ProductItemID)
+			if err != nil {
+				return err
 			}
 
-			updatedProductSize, err := q.UpdateProductSize(ctx, UpdateProductSizeParams{
-				ID:            productSize.ID,
-				ProductItemID: productSize.ProductItemID,
-				Qty:           null.IntFrom(int64(productSize.Qty - shopCartItems[i].Qty)),
-			})
+			bestDiscount := discount(*productItem)
+
+			lineTotal, err := discountedLineTotal(productItem.Price, shopCartItems[i].Qty, bestDiscount)
 			if err != nil {
 				return err
 			}
-			result.UpdatedProductSizeID = updatedProductSize.ID
+			subtotal = subtotal.Add(lineTotal)
 
-			// result.UpdatedProductItemID = updatedProductSize.ProductItemID
+			product, err := q.GetProduct(ctx, productItem.ProductID)
+			if err != nil {
+				return err
+			}
+
+			eligible := false
+			if coupon != nil {
+				eligible = !couponIsScoped(coupon) || couponCoversProduct(coupon, product)
+				if eligible {
+					eligibleTotal = eligibleTotal.Add(lineTotal)
+				}
+			}
+
+			lines = append(lines, purchaseLine{
+				cartItem:       shopCartItems[i],
+				productSize:    productSize,
+				price:          productItem.Price,
+				discount:       bestDiscount,
+				productName:    product.Name,
+				colorValue:     productItem.ColorValue,
+				lineTotal:      lineTotal,
+				couponEligible: eligible,
+			})
+		}
 
-			productItemAfterUpdate, err := q.GetProductItemWithPromotions(ctx, shopCartItems[i].ProductItemID)
+		orderTotal := subtotal.Add(shippingPrice)
+
+		redeemedAmount := udecimal.Zero
+		if coupon != nil {
+			redeemedAmount, err = couponDiscount(coupon, subtotal, eligibleTotal)
+			if err != nil {
+				return err
+			}
+			orderTotal = orderTotal.Sub(redeemedAmount)
+
+			// every line keeps its share, so a return or a delete gives back only what the line got
+			err = spreadCouponDiscount(lines, redeemedAmount, eligibleTotal)
 			if err != nil {
 				return err
 			}
+		}
+
+		// the lines and the coupon discount are already in cents, StringFixed truncates
+		// so the total is still rounded before it's stored and compared
+		orderTotal = orderTotal.RoundBank(2)
+		computedTotal := orderTotal.StringFixed(2)
+
+		if arg.OrderTotal != "" {
+			clientTotal, err := udecimal.Parse(arg.OrderTotal)
+			if err != nil || !clientTotal.Equal(orderTotal) {
+				return &OrderTotalMismatchError{
+					Expected: computedTotal,
+					Got:      arg.OrderTotal,
+				}
+			}
+		}
+
+		trackNumber, err := newTrackNumber(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		createdShopOrder, err := q.CreateShopOrder(ctx, CreateShopOrderParams{
+			TrackNumber:       trackNumber,
+			UserID:            arg.UserID,
+			PaymentTypeID:     arg.PaymentTypeID,
+			ShippingAddressID: null.IntFromPtr(&arg.AddressID),
+			OrderTotal:        computedTotal,
+			ShippingMethodID:  arg.ShippingMethodID,
+			OrderStatusID:     null.IntFrom(orderStatusID),
+		})
+		if err != nil {
+			return err
+		}
 
-			bestDiscount := discount(*productItemAfterUpdate)
+		_, err = q.CreateShopOrderStatusHistory(ctx, CreateShopOrderStatusHistoryParams{
+			ShopOrderID: createdShopOrder.ID,
+			ToStatusID:  orderStatusID,
+			Note:        "order placed",
+		})
+		if err != nil {
+			return err
+		}
 
-			// discountValue := udecimal.NewFromInt(bestDiscount)
+		paymentTransaction, err := q.CreatePaymentTransaction(ctx, CreatePaymentTransactionParams{
+			ShopOrderID: createdShopOrder.ID,
+			Provider:    paymentType.Provider,
+			Amount:      createdShopOrder.OrderTotal,
+		})
+		if err != nil {
+			return err
+		}
 
-			// discountDecimal := udecimal.NewFromInt(1).Sub(discountValue.Div(udecimal.NewFromInt(100)))
+		result = &FinishedPurchaseTxResult{
+			ShopOrderID:        createdShopOrder.ID,
+			OrderTotal:         createdShopOrder.OrderTotal,
+			PaymentTransaction: paymentTransaction,
+		}
 
-			// price, err := udecimal.NewFromString(productItem.Price)
-			// if err != nil {
-			// 	return err
-			// }
+		for _, line := range lines {
+			updatedProductSize, err := q.UpdateProductSize(ctx, UpdateProductSizeParams{
+				ID:            line.productSize.ID,
+				ProductItemID: line.productSize.ProductItemID,
+				Qty:           null.IntFrom(int64(line.productSize.Qty - line.cartItem.Qty)),
+			})
+			if err != nil {
+				return err
+			}
+			result.UpdatedProductSizeID = updatedProductSize.ID
 
-			// bestPrice := price.Mul(udecimal.NewFromInt(int64(shopCartItems[i].Qty))).Mul(discountDecimal)
+			// result.UpdatedProductItemID = updatedProductSize.ProductItemID
 
 			createdShopOrderItem, err := q.CreateShopOrderItem(ctx, CreateShopOrderItemParams{
-				ProductItemID:       shopCartItems[i].ProductItemID,
+				ProductItemID:       line.cartItem.ProductItemID,
 				OrderID:             createdShopOrder.ID,
-				Quantity:            shopCartItems[i].Qty,
-				Price:               productItemAfterUpdate.Price,
-				Discount:            int32(bestDiscount),
+				Quantity:            line.cartItem.Qty,
+				Price:               line.price,
+				Discount:            int32(line.discount),
 				ShippingMethodPrice: shippingMethod.Price,
+				SizeID:              null.IntFrom(line.productSize.ID),
+				SizeValue:           null.StringFrom(line.productSize.SizeValue),
+				ColorValue:          line.colorValue,
+				ProductName:         null.StringFrom(line.productName),
+				CouponDiscount:      line.couponDiscount.StringFixed(2),
 			})
 			if err != nil {
 				return err
 			}
 			result.ShopOrderItemID = createdShopOrderItem.ID
 		}
+
+		if coupon != nil {
+			_, err = q.IncrementCouponUsedCount(ctx, coupon.ID)
+			if err != nil {
+				return err
+			}
+
+			redemption, err := q.CreateCouponRedemption(ctx, CreateCouponRedemptionParams{
+				CouponID:       coupon.ID,
+				UserID:         arg.UserID,
+				ShopOrderID:    createdShopOrder.ID,
+				DiscountAmount: redeemedAmount.RoundBank(2).StringFixed(2),
+			})
+			if err != nil {
+				return err
+			}
+			result.CouponDiscount = redemption.DiscountAmount
+		}
+
+		// the holds of the cart are consumed by the order
+		_, err = q.DeleteStockReservationsByCartID(ctx, arg.ShoppingCartID)
+		if err != nil {
+			return err
+		}
+
 		_, err = q.DeleteShoppingCartItemAllByUser(ctx, DeleteShoppingCartItemAllByUserParams{
 			UserID:         arg.UserID,
 			ShoppingCartID: arg.ShoppingCartID,
@@ -165,8 +397,50 @@ func (store *SQLStore) FinishedPurchaseTx(ctx context.Context, arg FinishedPurch
 	return result, err
 }
 
+// discountedLineTotal returns price * qty after applying the discount rate (percent),
+// rounded to cents so the totals summed from the lines match the lines shown to the user
+func discountedLineTotal(price string, qty int32, discountRate int64) (udecimal.Decimal, error) {
+	unitPrice, err := udecimal.Parse(price)
+	if err != nil {
+		return udecimal.Zero, err
+	}
+
+	lineTotal := unitPrice.Mul64(uint64(qty))
+	if discountRate <= 0 {
+		return lineTotal.RoundBank(2), nil
+	}
+
+	lineTotal = lineTotal.Mul64(uint64(100 - min(discountRate, 100)))
+	lineTotal, err = lineTotal.Div64(100)
+	if err != nil {
+		return udecimal.Zero, err
+	}
+	return lineTotal.RoundBank(2), nil
+}
+
+// AppliedPromotion is the promotion picked for a product item, Source is one of
+// "product", "category" or "brand"
+type AppliedPromotion struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Source       string `json:"source"`
+	DiscountRate int64  `json:"discount_rate"`
+}
+
 func discount(productItem GetProductItemWithPromotionsRow) int64 {
+	bestPromo := bestPromotion(productItem)
+	if bestPromo == nil {
+		return 0
+	}
+
+	return bestPromo.DiscountRate
+}
+
+// bestPromotion returns the active promotion with the highest discount rate
+// among the product, category and brand promotions, or nil if none applies
+func bestPromotion(productItem GetProductItemWithPromotionsRow) *AppliedPromotion {
 	var promos []Promotion
+	var sources []string
 
 	if productItem.ProductPromoDiscountRate.Valid {
 		promos = append(promos, Promotion{
@@ -178,6 +452,7 @@ func discount(productItem GetProductItemWithPromotionsRow) int64 {
 			StartDate:    productItem.ProductPromoStartDate.Time,
 			EndDate:      productItem.ProductPromoEndDate.Time,
 		})
+		sources = append(sources, "product")
 	}
 
 	if productItem.CategoryPromoDiscountRate.Valid {
@@ -190,6 +465,7 @@ func discount(productItem GetProductItemWithPromotionsRow) int64 {
 			StartDate:    productItem.CategoryPromoStartDate.Time,
 			EndDate:      productItem.CategoryPromoEndDate.Time,
 		})
+		sources = append(sources, "category")
 	}
 
 	if productItem.BrandPromoDiscountRate.Valid {
@@ -202,32 +478,30 @@ func discount(productItem GetProductItemWithPromotionsRow) int64 {
 			StartDate:    productItem.BrandPromoStartDate.Time,
 			EndDate:      productItem.BrandPromoEndDate.Time,
 		})
+		sources = append(sources, "brand")
 	}
 
-	var validPromos []Promotion
+	bestIndex := -1
 	now := time.Now()
 
-	for _, promo := range promos {
-		if promo.Active &&
-			now.After(promo.StartDate) && now.Before(promo.EndDate) {
-			validPromos = append(validPromos, promo)
+	for i, promo := range promos {
+		if !promo.Active ||
+			!now.After(promo.StartDate) || !now.Before(promo.EndDate) {
+			continue
 		}
-	}
-
-	if len(validPromos) == 0 {
-		return 0
-	}
-
-	bestPromo := validPromos[0]
-	for _, promo := range validPromos {
-		if promo.DiscountRate > bestPromo.DiscountRate {
-			bestPromo = promo
+		if bestIndex == -1 || promo.DiscountRate > promos[bestIndex].DiscountRate {
+			bestIndex = i
 		}
 	}
 
-	if bestPromo.DiscountRate > 0 {
-		return bestPromo.DiscountRate
+	if bestIndex == -1 || promos[bestIndex].DiscountRate <= 0 {
+		return nil
 	}
 
-	return 0
+	return &AppliedPromotion{
+		ID:           promos[bestIndex].ID,
+		Name:         promos[bestIndex].Name,
+		Source:       sources[bestIndex],
+		DiscountRate: promos[bestIndex].DiscountRate,
+	}
 }
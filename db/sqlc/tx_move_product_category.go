@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/guregu/null/v6"
+)
+
+// ErrProductCategoryCycle is returned when a category is moved under itself or one of its descendants
+var ErrProductCategoryCycle = errors.New("a category can't be moved under itself or one of its subcategories")
+
+// MoveProductCategoryTxParams contains the input parameters of the category move transaction
+type MoveProductCategoryTxParams struct {
+	ID int64 `json:"id"`
+	// ParentCategoryID is the new parent, a null parent moves the category to the root
+	ParentCategoryID null.Int `json:"parent_category_id"`
+}
+
+/*
+MoveProductCategoryTx sets the parent of a category,
+
+the moves are serialized by an advisory lock so two concurrent moves can't build a cycle together,
+ErrProductCategoryCycle is returned when the new parent is the category itself or one of its descendants.
+*/
+func (store *SQLStore) MoveProductCategoryTx(ctx context.Context, arg MoveProductCategoryTxParams) (*ProductCategory, error) {
+	var result *ProductCategory
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		err = q.LockProductCategoryTree(ctx)
+		if err != nil {
+			return err
+		}
+
+		if arg.ParentCategoryID.Valid {
+			inSubtree, err := q.IsProductCategoryInSubtree(ctx, IsProductCategoryInSubtreeParams{
+				RootID:     arg.ID,
+				CategoryID: arg.ParentCategoryID.Int64,
+			})
+			if err != nil {
+				return err
+			}
+			if inSubtree {
+				return ErrProductCategoryCycle
+			}
+		}
+
+		result, err = q.UpdateProductCategoryParent(ctx, UpdateProductCategoryParentParams{
+			ParentCategoryID: arg.ParentCategoryID,
+			ID:               arg.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
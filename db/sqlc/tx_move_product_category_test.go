@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveProductCategoryTx(t *testing.T) {
+	root := createRandomProductCategoryForUpdateOrDelete(t)
+	child := createRandomProductCategoryChild(t, root.ID)
+	other := createRandomProductCategoryForUpdateOrDelete(t)
+
+	moved, err := testStore.MoveProductCategoryTx(context.Background(), MoveProductCategoryTxParams{
+		ID:               child.ID,
+		ParentCategoryID: null.IntFrom(other.ID),
+	})
+	require.NoError(t, err)
+	require.Equal(t, null.IntFrom(other.ID), moved.ParentCategoryID)
+	require.Equal(t, child.CategoryName, moved.CategoryName)
+
+	// a null parent moves the category to the root
+	moved, err = testStore.MoveProductCategoryTx(context.Background(), MoveProductCategoryTxParams{
+		ID: child.ID,
+	})
+	require.NoError(t, err)
+	require.False(t, moved.ParentCategoryID.Valid)
+
+	_, err = testStore.MoveProductCategoryTx(context.Background(), MoveProductCategoryTxParams{
+		ID:               child.ID,
+		ParentCategoryID: null.IntFrom(root.ID),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.MoveProductCategoryTx(context.Background(), MoveProductCategoryTxParams{
+		ID:               0,
+		ParentCategoryID: null.IntFrom(root.ID),
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestMoveProductCategoryTxCycle(t *testing.T) {
+	root := createRandomProductCategoryForUpdateOrDelete(t)
+	child := createRandomProductCategoryChild(t, root.ID)
+	grandchild := createRandomProductCategoryChild(t, child.ID)
+
+	for _, parentID := range []int64{root.ID, child.ID, grandchild.ID} {
+		_, err := testStore.MoveProductCategoryTx(context.Background(), MoveProductCategoryTxParams{
+			ID:               root.ID,
+			ParentCategoryID: null.IntFrom(parentID),
+		})
+		require.ErrorIs(t, err, ErrProductCategoryCycle)
+	}
+
+	category, err := testStore.GetProductCategory(context.Background(), root.ID)
+	require.NoError(t, err)
+	require.False(t, category.ParentCategoryID.Valid)
+}
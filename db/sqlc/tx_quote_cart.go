@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/quagmt/udecimal"
+)
+
+// QuoteCartTxParams contains the input parameters of the cart quote transaction
+type QuoteCartTxParams struct {
+	UserID           int64 `json:"user_id"`
+	ShoppingCartID   int64 `json:"shopping_cart_id"`
+	ShippingMethodID int64 `json:"shipping_method_id"`
+}
+
+// QuoteCartLine is the price breakdown of one shopping cart item
+type QuoteCartLine struct {
+	ShoppingCartItemID int64             `json:"shopping_cart_item_id"`
+	ProductItemID      int64             `json:"product_item_id"`
+	SizeID             int64             `json:"size_id"`
+	Qty                int32             `json:"qty"`
+	QtyInStock         int32             `json:"qty_in_stock"`
+	UnitPrice          string            `json:"unit_price"`
+	Promotion          *AppliedPromotion `json:"promotion"`
+	LineTotal          string            `json:"line_total"`
+	StockWarning       string            `json:"stock_warning,omitempty"`
+}
+
+// QuoteCartTxResult is the result of the cart quote transaction
+type QuoteCartTxResult struct {
+	Lines            []*QuoteCartLine `json:"lines"`
+	Subtotal         string           `json:"subtotal"`
+	ShippingMethodID int64            `json:"shipping_method_id"`
+	ShippingPrice    string           `json:"shipping_price"`
+	GrandTotal       string           `json:"grand_total"`
+}
+
+/*
+QuoteCartTx previews the price breakdown of the user's shopping cart with the given shipping method,
+it prices every line with the same promotion rules used by FinishedPurchaseTx without creating an order
+*/
+func (store *SQLStore) QuoteCartTx(ctx context.Context, arg QuoteCartTxParams) (*QuoteCartTxResult, error) {
+	var result *QuoteCartTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		shopCartItems, err := q.GetShoppingCartItemByUserIDCartID(ctx, GetShoppingCartItemByUserIDCartIDParams{
+			UserID: arg.UserID,
+			ID:     arg.ShoppingCartID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(shopCartItems) == 0 {
+			return pgx.ErrNoRows
+		}
+
+		shippingMethod, err := q.GetShippingMethod(ctx, arg.ShippingMethodID)
+		if err != nil {
+			return err
+		}
+
+		shippingPrice, err := udecimal.Parse(shippingMethod.Price)
+		if err != nil {
+			return err
+		}
+
+		subtotal := udecimal.Zero
+		result = &QuoteCartTxResult{
+			Lines:            make([]*QuoteCartLine, 0, len(shopCartItems)),
+			ShippingMethodID: shippingMethod.ID,
+			ShippingPrice:    shippingPrice.StringFixed(2),
+		}
+
+		for _, item := range shopCartItems {
+			productSize, err := q.GetProductSize(ctx, item.SizeID)
+			if err != nil {
+				return err
+			}
+
+			available, err := availableQty(ctx, q, productSize, arg.ShoppingCartID)
+			if err != nil {
+				return err
+			}
+
+			productItem, err := q.GetProductItemWithPromotions(ctx, item.ProductItemID)
+			if err != nil {
+				return err
+			}
+
+			promo := bestPromotion(*productItem)
+
+			var discountRate int64
+			if promo != nil {
+				discountRate = promo.DiscountRate
+			}
+
+			lineTotal, err := discountedLineTotal(productItem.Price, item.Qty, discountRate)
+			if err != nil {
+				return err
+			}
+			subtotal = subtotal.Add(lineTotal)
+
+			line := &QuoteCartLine{
+				ShoppingCartItemID: item.ID,
+				ProductItemID:      item.ProductItemID,
+				SizeID:             item.SizeID,
+				Qty:                item.Qty,
+				QtyInStock:         available,
+				UnitPrice:          productItem.Price,
+				Promotion:          promo,
+				LineTotal:          lineTotal.StringFixed(2),
+			}
+
+			// same checks as FinishedPurchaseTx, so the warning matches the purchase outcome
+			if available <= 0 {
+				line.StockWarning = "Stock is Empty"
+			} else if available < item.Qty {
+				line.StockWarning = "Not Enough Qty in Stock"
+			}
+
+			result.Lines = append(result.Lines, line)
+		}
+
+		// summed from the rounded lines like the order total of FinishedPurchaseTx,
+		// so the lines add up to the subtotal and the quoted total can be sent back as is
+		result.Subtotal = subtotal.StringFixed(2)
+		result.GrandTotal = subtotal.Add(shippingPrice).RoundBank(2).StringFixed(2)
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshop/v3/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/quagmt/udecimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteCartTx(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	shippingMethod := createRandomShippingMethod(t)
+
+	shoppingCart, err := testStore.CreateShoppingCart(context.Background(), userAddress.UserID)
+	require.NoError(t, err)
+
+	product := createRandomProduct(t)
+	image := createRandomProductImage(t)
+	color := createRandomProductColor(t)
+	productItem, err := testStore.CreateProductItem(context.Background(), CreateProductItemParams{
+		ProductID:  product.ID,
+		ImageID:    image.ID,
+		ColorID:    color.ID,
+		ProductSku: util.RandomInt(5, 100),
+		Price:      util.RandomDecimalString(1, 100),
+		Active:     true,
+	})
+	require.NoError(t, err)
+	size := createRandomProductSizeWithItemID(t, productItem.ID)
+
+	shoppingCartItem, err := testStore.CreateShoppingCartItem(context.Background(), CreateShoppingCartItemParams{
+		ShoppingCartID: shoppingCart.ID,
+		ProductItemID:  size.ProductItemID,
+		SizeID:         size.ID,
+		Qty:            size.Qty + 1,
+	})
+	require.NoError(t, err)
+
+	quote, err := testStore.QuoteCartTx(context.Background(), QuoteCartTxParams{
+		UserID:           userAddress.UserID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, quote)
+	require.Len(t, quote.Lines, 1)
+
+	line := quote.Lines[0]
+	require.Equal(t, shoppingCartItem.ID, line.ShoppingCartItemID)
+	require.Equal(t, productItem.Price, line.UnitPrice)
+	require.Equal(t, size.Qty, line.QtyInStock)
+	// buying more than the stock is rejected by FinishedPurchaseTx
+	require.Equal(t, "Not Enough Qty in Stock", line.StockWarning)
+
+	subtotal := udecimal.MustParse(line.LineTotal)
+	shippingPrice := udecimal.MustParse(shippingMethod.Price)
+	require.Equal(t, subtotal.Add(shippingPrice).StringFixed(2), quote.GrandTotal)
+
+	// the cart does not belong to another user
+	quote, err = testStore.QuoteCartTx(context.Background(), QuoteCartTxParams{
+		UserID:           userAddress.UserID + 1,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Empty(t, quote)
+}
+
+func TestQuoteCartTxTotalIsAccepted(t *testing.T) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	orderStatus := createRandomOrderStatus(t)
+	shoppingCart, _ := createRandomCartWithItem(t, userAddress.UserID)
+
+	for range 2 {
+		productItem := createRandomProductItem(t)
+		size := createRandomProductSizeWithItemID(t, productItem.ID)
+		_, err := testStore.CreateShoppingCartItem(context.Background(), CreateShoppingCartItemParams{
+			ShoppingCartID: shoppingCart.ID,
+			ProductItemID:  size.ProductItemID,
+			SizeID:         size.ID,
+			Qty:            1,
+		})
+		require.NoError(t, err)
+	}
+
+	quote, err := testStore.QuoteCartTx(context.Background(), QuoteCartTxParams{
+		UserID:           userAddress.UserID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, quote.Lines, 3)
+
+	// the lines shown to the user add up to the subtotal
+	linesTotal := udecimal.Zero
+	for _, line := range quote.Lines {
+		linesTotal = linesTotal.Add(udecimal.MustParse(line.LineTotal))
+	}
+	require.Equal(t, linesTotal.StringFixed(2), quote.Subtotal)
+
+	result, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+		OrderTotal:       quote.GrandTotal,
+	})
+	require.NoError(t, err)
+	require.Equal(t, quote.GrandTotal, result.OrderTotal)
+}
+
+func TestDiscountedLineTotal(t *testing.T) {
+	testCases := []struct {
+		price        string
+		qty          int32
+		discountRate int64
+		expected     string
+	}{
+		{price: "10.00", qty: 2, discountRate: 0, expected: "20.00"},
+		{price: "0.335", qty: 1, discountRate: 0, expected: "0.34"},
+		{price: "10.05", qty: 3, discountRate: 15, expected: "25.63"},
+		{price: "9.99", qty: 1, discountRate: 33, expected: "6.69"},
+		{price: "5.00", qty: 1, discountRate: 150, expected: "0.00"},
+	}
+
+	for _, tc := range testCases {
+		lineTotal, err := discountedLineTotal(tc.price, tc.qty, tc.discountRate)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, lineTotal.StringFixed(2))
+	}
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/quagmt/udecimal"
+)
+
+// StartRefundPaymentTransactionTxParams contains the input parameters of the transaction that starts a payment refund
+type StartRefundPaymentTransactionTxParams struct {
+	ID int64 `json:"id"`
+}
+
+// StartRefundPaymentTransactionTxResult is the result of the transaction that starts a payment refund
+type StartRefundPaymentTransactionTxResult struct {
+	PaymentTransaction *PaymentTransaction `json:"payment_transaction"`
+	// Amount is the refund in flight to ask the provider for, it's empty when there's nothing to refund
+	Amount string `json:"amount,omitempty"`
+}
+
+/*
+StartRefundPaymentTransactionTx moves the pending refund of a payment in flight before the provider is asked for it,
+
+the provider is called once the transaction is committed so the payment isn't locked during the call.
+each refund put in flight gets the next refund sequence, it keys the idempotency of the provider request
+so a refund asked again after a failure or a lost answer is made only once. a refund already in flight
+is returned as it is to be asked again, what's pending meanwhile waits for the next refund.
+*/
+func (store *SQLStore) StartRefundPaymentTransactionTx(ctx context.Context, arg StartRefundPaymentTransactionTxParams) (*StartRefundPaymentTransactionTxResult, error) {
+	var result *StartRefundPaymentTransactionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		paymentTransaction, err := q.GetPaymentTransactionForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result = &StartRefundPaymentTransactionTxResult{
+			PaymentTransaction: paymentTransaction,
+		}
+
+		inFlight, err := udecimal.Parse(paymentTransaction.RefundInFlightAmount)
+		if err != nil {
+			return err
+		}
+
+		if inFlight.IsPos() {
+			result.Amount = inFlight.StringFixed(2)
+			return nil
+		}
+
+		pending, err := udecimal.Parse(paymentTransaction.PendingRefundAmount)
+		if err != nil {
+			return err
+		}
+
+		if !pending.IsPos() {
+			return nil
+		}
+
+		refundInFlight := pending.StringFixed(2)
+		refundSequence := paymentTransaction.RefundSequence + 1
+
+		// the provider reported the payment refunded in full in the meantime
+		if !isRefundable(paymentTransaction.Status) {
+			refundInFlight = "0"
+			refundSequence = paymentTransaction.RefundSequence
+		}
+
+		result.PaymentTransaction, err = q.UpdatePaymentTransactionRefund(ctx, UpdatePaymentTransactionRefundParams{
+			ID:                   paymentTransaction.ID,
+			Status:               paymentTransaction.Status,
+			RefundedAmount:       paymentTransaction.RefundedAmount,
+			PendingRefundAmount:  "0",
+			RefundInFlightAmount: refundInFlight,
+			RefundSequence:       refundSequence,
+		})
+		if err != nil {
+			return err
+		}
+
+		if refundSequence != paymentTransaction.RefundSequence {
+			result.Amount = refundInFlight
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// CompleteRefundPaymentTransactionTxParams contains the input parameters of the transaction that records a payment refund
+type CompleteRefundPaymentTransactionTxParams struct {
+	ID int64 `json:"id"`
+	// RefundSequence is the sequence of the refund the provider made
+	RefundSequence int64 `json:"refund_sequence"`
+}
+
+// CompleteRefundPaymentTransactionTxResult is the result of the transaction that records a payment refund
+type CompleteRefundPaymentTransactionTxResult struct {
+	PaymentTransaction *PaymentTransaction `json:"payment_transaction"`
+	// Refunded is the amount the provider gave back, it's empty when the refund was recorded already
+	Refunded string `json:"refunded,omitempty"`
+}
+
+/*
+CompleteRefundPaymentTransactionTx records the refund in flight the provider made,
+
+it's moved to the refunded amount, the payment is refunded once all of its amount is
+given back and partially refunded until then. a refund of another sequence or one that
+was recorded already changes nothing, so recording the same answer twice is safe.
+*/
+func (store *SQLStore) CompleteRefundPaymentTransactionTx(ctx context.Context, arg CompleteRefundPaymentTransactionTxParams) (*CompleteRefundPaymentTransactionTxResult, error) {
+	var result *CompleteRefundPaymentTransactionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		paymentTransaction, err := q.GetPaymentTransactionForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result = &CompleteRefundPaymentTransactionTxResult{
+			PaymentTransaction: paymentTransaction,
+		}
+
+		inFlight, err := udecimal.Parse(paymentTransaction.RefundInFlightAmount)
+		if err != nil {
+			return err
+		}
+
+		if paymentTransaction.RefundSequence != arg.RefundSequence || !inFlight.IsPos() {
+			return nil
+		}
+
+		amount, err := udecimal.Parse(paymentTransaction.Amount)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := udecimal.Parse(paymentTransaction.RefundedAmount)
+		if err != nil {
+			return err
+		}
+
+		refunded = refunded.Add(inFlight)
+		status := PaymentStatusPartiallyRefunded
+		if refunded.GreaterThanOrEqual(amount) {
+			status = PaymentStatusRefunded
+		}
+
+		result.PaymentTransaction, err = q.UpdatePaymentTransactionRefund(ctx, UpdatePaymentTransactionRefundParams{
+			ID:                   paymentTransaction.ID,
+			Status:               status,
+			RefundedAmount:       refunded.StringFixed(2),
+			PendingRefundAmount:  paymentTransaction.PendingRefundAmount,
+			RefundInFlightAmount: "0",
+			RefundSequence:       paymentTransaction.RefundSequence,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Refunded = inFlight.StringFixed(2)
+		return nil
+	})
+
+	return result, err
+}
+
+/*
+requestOrderRefund records a refund of the last payment of the order as pending,
+StartRefundPaymentTransactionTx puts it in flight once the transaction is committed,
+
+only a captured payment is refunded and the refund is capped at its amount minus what's
+refunded, in flight or pending already, an empty amount asks for all of what's left. it returns the
+amount recorded and whether the order has a payment that can be refunded.
+*/
+func requestOrderRefund(ctx context.Context, q *Queries, shopOrderID int64, amount string) (udecimal.Decimal, bool, error) {
+	paymentTransaction, err := q.GetLastPaymentTransactionForUpdate(ctx, shopOrderID)
+	if err != nil {
+		// the orders placed before the payments have none
+		if errors.Is(err, pgx.ErrNoRows) {
+			return udecimal.Zero, false, nil
+		}
+		return udecimal.Zero, false, err
+	}
+
+	if !isRefundable(paymentTransaction.Status) {
+		return udecimal.Zero, false, nil
+	}
+
+	total, err := udecimal.Parse(paymentTransaction.Amount)
+	if err != nil {
+		return udecimal.Zero, false, err
+	}
+
+	refunded, err := udecimal.Parse(paymentTransaction.RefundedAmount)
+	if err != nil {
+		return udecimal.Zero, false, err
+	}
+
+	pending, err := udecimal.Parse(paymentTransaction.PendingRefundAmount)
+	if err != nil {
+		return udecimal.Zero, false, err
+	}
+
+	inFlight, err := udecimal.Parse(paymentTransaction.RefundInFlightAmount)
+	if err != nil {
+		return udecimal.Zero, false, err
+	}
+
+	refund := total.Sub(refunded).Sub(inFlight).Sub(pending)
+	if amount != "" {
+		requested, err := udecimal.Parse(amount)
+		if err != nil {
+			return udecimal.Zero, false, err
+		}
+		if requested.LessThan(refund) {
+			refund = requested
+		}
+	}
+
+	if !refund.IsPos() {
+		return udecimal.Zero, true, nil
+	}
+
+	_, err = q.UpdatePaymentTransactionRefund(ctx, UpdatePaymentTransactionRefundParams{
+		ID:                   paymentTransaction.ID,
+		Status:               paymentTransaction.Status,
+		RefundedAmount:       paymentTransaction.RefundedAmount,
+		PendingRefundAmount:  pending.Add(refund).StringFixed(2),
+		RefundInFlightAmount: paymentTransaction.RefundInFlightAmount,
+		RefundSequence:       paymentTransaction.RefundSequence,
+	})
+	if err != nil {
+		return udecimal.Zero, false, err
+	}
+
+	return refund, true, nil
+}
+
+// ErrOrderTotalAbovePayment is returned when the order total is raised above what the provider already holds or collected
+var ErrOrderTotalAbovePayment = errors.New("the order total can't be raised above its authorized or captured payment")
+
+/*
+adjustOrderPayment keeps the last payment of the order in line with its new total,
+
+a payment the provider didn't authorize yet is asked for the new total and an authorized
+one is only captured for it. the part of a captured payment the order doesn't cost anymore
+is recorded as a pending refund. the total can't be raised once the payment is authorized.
+*/
+func adjustOrderPayment(ctx context.Context, q *Queries, shopOrderID int64, orderTotal, newOrderTotal string) error {
+	total, err := udecimal.Parse(orderTotal)
+	if err != nil {
+		return err
+	}
+
+	newTotal, err := udecimal.Parse(newOrderTotal)
+	if err != nil {
+		return err
+	}
+
+	if newTotal.Equal(total) {
+		return nil
+	}
+
+	paymentTransaction, err := q.GetLastPaymentTransactionForUpdate(ctx, shopOrderID)
+	if err != nil {
+		// the orders placed before the payments have none
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	switch paymentTransaction.Status {
+	case PaymentStatusPending, PaymentStatusAuthorized:
+		if paymentTransaction.Status == PaymentStatusAuthorized && newTotal.GreaterThan(total) {
+			return ErrOrderTotalAbovePayment
+		}
+
+		_, err = q.UpdatePaymentTransactionAmount(ctx, UpdatePaymentTransactionAmountParams{
+			ID:     paymentTransaction.ID,
+			Amount: newTotal.StringFixed(2),
+		})
+		return err
+	case PaymentStatusCaptured, PaymentStatusPartiallyRefunded:
+		if newTotal.GreaterThan(total) {
+			return ErrOrderTotalAbovePayment
+		}
+
+		_, _, err = requestOrderRefund(ctx, q, shopOrderID, total.Sub(newTotal).StringFixed(2))
+		return err
+	}
+
+	return nil
+}
+
+// isRefundable reports whether a payment in the status has captured money that can be given back
+func isRefundable(status string) bool {
+	return status == PaymentStatusCaptured || status == PaymentStatusPartiallyRefunded
+}
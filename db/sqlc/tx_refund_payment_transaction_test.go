@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null/v6"
+	"github.com/quagmt/udecimal"
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomCapturedReturnPurchase returns a delivered purchase whose payment was captured
+func createRandomCapturedReturnPurchase(t *testing.T, admin Admin) (*FinishedPurchaseTxResult, int64, *ShopOrderItem, *ShopOrderItem) {
+	purchase, userID, eligibleItem, otherItem := createRandomScopedCouponPurchase(t, admin)
+
+	result, err := testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     purchase.PaymentTransaction.ID,
+		Status: PaymentStatusCaptured,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusCaptured, result.PaymentTransaction.Status)
+
+	purchase.PaymentTransaction = result.PaymentTransaction
+	return purchase, userID, eligibleItem, otherItem
+}
+
+// refundPaymentTransaction puts the pending refund of the payment in flight and records it as made by the provider
+func refundPaymentTransaction(t *testing.T, paymentTransactionID int64) *CompleteRefundPaymentTransactionTxResult {
+	started, err := testStore.StartRefundPaymentTransactionTx(context.Background(), StartRefundPaymentTransactionTxParams{
+		ID: paymentTransactionID,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.CompleteRefundPaymentTransactionTx(context.Background(), CompleteRefundPaymentTransactionTxParams{
+		ID:             paymentTransactionID,
+		RefundSequence: started.PaymentTransaction.RefundSequence,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestRefundPaymentTransactionTx(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, userID, _, otherItem := createRandomCapturedReturnPurchase(t, admin)
+	paymentTransaction := purchase.PaymentTransaction
+
+	// the refunded return request records its refund as pending on the payment
+	returnRequest := refundReturnRequest(t, admin, userID, otherItem)
+
+	pending, err := testStore.GetPaymentTransaction(context.Background(), paymentTransaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, returnRequest.RefundAmount.String, pending.PendingRefundAmount)
+	require.Equal(t, PaymentStatusCaptured, pending.Status)
+
+	started, err := testStore.StartRefundPaymentTransactionTx(context.Background(), StartRefundPaymentTransactionTxParams{
+		ID: paymentTransaction.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, returnRequest.RefundAmount.String, started.Amount)
+	require.Equal(t, returnRequest.RefundAmount.String, started.PaymentTransaction.RefundInFlightAmount)
+	require.True(t, udecimal.MustParse(started.PaymentTransaction.PendingRefundAmount).IsZero())
+	require.Equal(t, pending.RefundSequence+1, started.PaymentTransaction.RefundSequence)
+
+	// the provider didn't answer, the refund is asked again with the same sequence
+	retried, err := testStore.StartRefundPaymentTransactionTx(context.Background(), StartRefundPaymentTransactionTxParams{
+		ID: paymentTransaction.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, started.Amount, retried.Amount)
+	require.Equal(t, started.PaymentTransaction.RefundSequence, retried.PaymentTransaction.RefundSequence)
+
+	result, err := testStore.CompleteRefundPaymentTransactionTx(context.Background(), CompleteRefundPaymentTransactionTxParams{
+		ID:             paymentTransaction.ID,
+		RefundSequence: started.PaymentTransaction.RefundSequence,
+	})
+	require.NoError(t, err)
+	require.Equal(t, returnRequest.RefundAmount.String, result.Refunded)
+	require.Equal(t, PaymentStatusPartiallyRefunded, result.PaymentTransaction.Status)
+	require.Equal(t, returnRequest.RefundAmount.String, result.PaymentTransaction.RefundedAmount)
+	require.True(t, udecimal.MustParse(result.PaymentTransaction.RefundInFlightAmount).IsZero())
+
+	// the same answer recorded twice doesn't refund it again
+	result, err = testStore.CompleteRefundPaymentTransactionTx(context.Background(), CompleteRefundPaymentTransactionTxParams{
+		ID:             paymentTransaction.ID,
+		RefundSequence: started.PaymentTransaction.RefundSequence,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Refunded)
+	require.Equal(t, returnRequest.RefundAmount.String, result.PaymentTransaction.RefundedAmount)
+
+	// nothing is pending anymore so the provider isn't asked again
+	started, err = testStore.StartRefundPaymentTransactionTx(context.Background(), StartRefundPaymentTransactionTxParams{
+		ID: paymentTransaction.ID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, started.Amount)
+	require.Equal(t, PaymentStatusPartiallyRefunded, started.PaymentTransaction.Status)
+}
+
+func TestRefundPaymentTransactionTxCapped(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, userID, eligibleItem, otherItem := createRandomCapturedReturnPurchase(t, admin)
+	paymentTransaction := purchase.PaymentTransaction
+
+	returnRequest := refundReturnRequest(t, admin, userID, otherItem)
+	refundPaymentTransaction(t, paymentTransaction.ID)
+
+	// the other item is received back before the order is returned
+	created, err := testStore.CreateReturnRequestTx(context.Background(), CreateReturnRequestTxParams{
+		UserID:      userID,
+		ShopOrderID: purchase.ShopOrderID,
+		Reason:      "damaged",
+		Items: []ReturnRequestItemParams{
+			{ShopOrderItemID: eligibleItem.ID, Qty: eligibleItem.Quantity},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, status := range []string{ReturnStatusApproved, ReturnStatusReceived} {
+		_, err = testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+			AdminID:         admin.ID,
+			ReturnRequestID: created.ReturnRequest.ID,
+			Status:          status,
+		})
+		require.NoError(t, err)
+	}
+
+	// returning the order refunds what's left of the payment
+	returnedStatus, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusReturned))
+	require.NoError(t, err)
+
+	_, err = testStore.UpdateShopOrderTx(context.Background(), UpdateShopOrderTxParams{
+		UpdateShopOrderParams: UpdateShopOrderParams{
+			ID:            purchase.ShopOrderID,
+			AdminID:       admin.ID,
+			OrderStatusID: null.IntFrom(returnedStatus.ID),
+		},
+	})
+	require.NoError(t, err)
+
+	left := udecimal.MustParse(paymentTransaction.Amount).Sub(udecimal.MustParse(returnRequest.RefundAmount.String))
+	result := refundPaymentTransaction(t, paymentTransaction.ID)
+	require.Equal(t, left.StringFixed(2), result.Refunded)
+	require.Equal(t, PaymentStatusRefunded, result.PaymentTransaction.Status)
+	require.True(t, udecimal.MustParse(result.PaymentTransaction.RefundedAmount).Equal(udecimal.MustParse(paymentTransaction.Amount)))
+
+	// the return refunded after that can't give back more than the payment
+	updated, err := testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+		AdminID:         admin.ID,
+		ReturnRequestID: created.ReturnRequest.ID,
+		Status:          ReturnStatusRefunded,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0.00", updated.ReturnRequest.RefundAmount.String)
+
+	pending, err := testStore.GetPaymentTransaction(context.Background(), paymentTransaction.ID)
+	require.NoError(t, err)
+	require.True(t, udecimal.MustParse(pending.PendingRefundAmount).IsZero())
+}
+
+func TestRefundPaymentTransactionTxNotCaptured(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, userID, _, otherItem := createRandomScopedCouponPurchase(t, admin)
+
+	// a payment that wasn't captured has nothing to give back, the refund is made outside the provider
+	returnRequest := refundReturnRequest(t, admin, userID, otherItem)
+	require.True(t, udecimal.MustParse(returnRequest.RefundAmount.String).IsPos())
+
+	pending, err := testStore.GetPaymentTransaction(context.Background(), purchase.PaymentTransaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusPending, pending.Status)
+	require.True(t, udecimal.MustParse(pending.PendingRefundAmount).IsZero())
+}
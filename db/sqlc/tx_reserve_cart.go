@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReserveCartTxParams contains the input parameters of the cart reservation transaction
+type ReserveCartTxParams struct {
+	UserID         int64         `json:"user_id"`
+	ShoppingCartID int64         `json:"shopping_cart_id"`
+	Duration       time.Duration `json:"duration"`
+}
+
+// ReserveCartTxResult is the result of the cart reservation transaction
+type ReserveCartTxResult struct {
+	Reservations []*StockReservation `json:"reservations"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+}
+
+// StockNotAvailableError is returned when a cart item can't be held
+// because the stock left after the other carts' holds is not enough
+type StockNotAvailableError struct {
+	SizeID    int64  `json:"size_id"`
+	Available int32  `json:"available"`
+	Reason    string `json:"reason"`
+}
+
+func (e *StockNotAvailableError) Error() string {
+	return fmt.Sprintf("size %d is not available: %s", e.SizeID, e.Reason)
+}
+
+/*
+ReserveCartTx holds the quantities of the user's shopping cart for the given duration,
+
+the previous holds of the cart are replaced, every product size is locked and the
+active holds of the other carts are taken off its qty before checking it.
+FinishedPurchaseTx consumes the holds of the cart and the expired ones are released
+by the worker.
+*/
+func (store *SQLStore) ReserveCartTx(ctx context.Context, arg ReserveCartTxParams) (*ReserveCartTxResult, error) {
+	var result *ReserveCartTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		shopCartItems, err := q.GetShoppingCartItemByUserIDCartID(ctx, GetShoppingCartItemByUserIDCartIDParams{
+			UserID: arg.UserID,
+			ID:     arg.ShoppingCartID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(shopCartItems) == 0 {
+			return pgx.ErrNoRows
+		}
+
+		_, err = q.DeleteStockReservationsByCartID(ctx, arg.ShoppingCartID)
+		if err != nil {
+			return err
+		}
+
+		result = &ReserveCartTxResult{
+			Reservations: make([]*StockReservation, 0, len(shopCartItems)),
+			ExpiresAt:    time.Now().Add(arg.Duration),
+		}
+
+		for _, item := range shopCartItems {
+			productSize, err := q.GetProductItemSizeForUpdate(ctx, item.SizeID)
+			if err != nil {
+				return err
+			}
+
+			available, err := availableQty(ctx, q, productSize, arg.ShoppingCartID)
+			if err != nil {
+				return err
+			}
+
+			// same checks as FinishedPurchaseTx, so a held cart can always be purchased
+			if available <= 0 {
+				return &StockNotAvailableError{SizeID: item.SizeID, Available: available, Reason: "Stock is Empty"}
+			}
+
+			if available < item.Qty {
+				return &StockNotAvailableError{SizeID: item.SizeID, Available: available, Reason: "Not Enough Qty in Stock"}
+			}
+
+			reservation, err := q.CreateStockReservation(ctx, CreateStockReservationParams{
+				ShoppingCartID: arg.ShoppingCartID,
+				ProductItemID:  item.ProductItemID,
+				SizeID:         item.SizeID,
+				Qty:            item.Qty,
+				ExpiresAt:      result.ExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.Reservations = append(result.Reservations, reservation)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// availableQty returns the qty of the product size that is not held by another shopping cart
+func availableQty(ctx context.Context, q *Queries, productSize *ProductSize, shoppingCartID int64) (int32, error) {
+	reservedQty, err := q.GetReservedQtyBySizeIDForOtherCarts(ctx, GetReservedQtyBySizeIDForOtherCartsParams{
+		SizeID:         productSize.ID,
+		ShoppingCartID: shoppingCartID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return productSize.Qty - int32(reservedQty), nil
+}
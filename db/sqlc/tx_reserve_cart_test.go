@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveCartTx(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+
+	shoppingCart1, productItem := createRandomCartWithItem(t, user1.ID)
+
+	cartItems, err := testStore.ListShoppingCartItemsByCartID(context.Background(), shoppingCart1.ID)
+	require.NoError(t, err)
+	require.Len(t, cartItems, 1)
+
+	productSize, err := testStore.GetProductSize(context.Background(), cartItems[0].SizeID)
+	require.NoError(t, err)
+
+	result, err := testStore.ReserveCartTx(context.Background(), ReserveCartTxParams{
+		UserID:         user1.ID,
+		ShoppingCartID: shoppingCart1.ID,
+		Duration:       time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Reservations, 1)
+	require.Equal(t, productSize.ID, result.Reservations[0].SizeID)
+	require.Equal(t, cartItems[0].Qty, result.Reservations[0].Qty)
+	require.WithinDuration(t, time.Now().Add(time.Minute), result.ExpiresAt, time.Second)
+
+	// reserving again replaces the previous holds of the cart
+	result, err = testStore.ReserveCartTx(context.Background(), ReserveCartTxParams{
+		UserID:         user1.ID,
+		ShoppingCartID: shoppingCart1.ID,
+		Duration:       time.Minute,
+	})
+	require.NoError(t, err)
+
+	reservations, err := testStore.ListStockReservationsByCartID(context.Background(), shoppingCart1.ID)
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+
+	// the held qty is not in stock anymore
+	item, err := testStore.GetProductItem(context.Background(), productItem.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(productSize.Qty-cartItems[0].Qty), item.QtyInStock)
+
+	// another cart asking for more than the rest of the stock can't be held
+	shoppingCart2, err := testStore.CreateShoppingCart(context.Background(), user2.ID)
+	require.NoError(t, err)
+
+	_, err = testStore.CreateShoppingCartItem(context.Background(), CreateShoppingCartItemParams{
+		ShoppingCartID: shoppingCart2.ID,
+		ProductItemID:  productItem.ID,
+		SizeID:         productSize.ID,
+		Qty:            productSize.Qty - cartItems[0].Qty + 1,
+	})
+	require.NoError(t, err)
+
+	result, err = testStore.ReserveCartTx(context.Background(), ReserveCartTxParams{
+		UserID:         user2.ID,
+		ShoppingCartID: shoppingCart2.ID,
+		Duration:       time.Minute,
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var stockErr *StockNotAvailableError
+	require.ErrorAs(t, err, &stockErr)
+	require.Equal(t, productSize.ID, stockErr.SizeID)
+	require.Equal(t, productSize.Qty-cartItems[0].Qty, stockErr.Available)
+
+	// the cart does not belong to the user
+	result, err = testStore.ReserveCartTx(context.Background(), ReserveCartTxParams{
+		UserID:         user2.ID,
+		ShoppingCartID: shoppingCart1.ID,
+		Duration:       time.Minute,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Empty(t, result)
+}
+
+func TestDeleteExpiredStockReservations(t *testing.T) {
+	user := createRandomUser(t)
+	shoppingCart, productItem := createRandomCartWithItem(t, user.ID)
+
+	cartItems, err := testStore.ListShoppingCartItemsByCartID(context.Background(), shoppingCart.ID)
+	require.NoError(t, err)
+
+	_, err = testStore.CreateStockReservation(context.Background(), CreateStockReservationParams{
+		ShoppingCartID: shoppingCart.ID,
+		ProductItemID:  productItem.ID,
+		SizeID:         cartItems[0].SizeID,
+		Qty:            cartItems[0].Qty,
+		ExpiresAt:      time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	reservations, err := testStore.ListStockReservationsByCartID(context.Background(), shoppingCart.ID)
+	require.NoError(t, err)
+	require.Empty(t, reservations)
+
+	released, err := testStore.DeleteExpiredStockReservations(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, released, int64(1))
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RefreshTokenReusedError is returned when a refresh token that was already rotated is presented again
+type RefreshTokenReusedError struct {
+	SessionID uuid.UUID `json:"session_id"`
+	FamilyID  uuid.UUID `json:"family_id"`
+}
+
+func (e *RefreshTokenReusedError) Error() string {
+	return fmt.Sprintf("refresh token of session %s was already used", e.SessionID)
+}
+
+// RotateUserSessionTxParams contains the input parameters of the user session rotation transaction
+type RotateUserSessionTxParams struct {
+	// ID of the session the presented refresh token belongs to
+	ID uuid.UUID `json:"id"`
+	// NewSession is created in the same family as the rotated one
+	NewSession CreateUserSessionParams `json:"new_session"`
+}
+
+/*
+RotateUserSessionTx makes the refresh token of a user session single-use,
+
+the old session is marked as replaced by the new one only if it wasn't rotated or blocked before,
+so two requests racing with the same token can't both get a new session.
+*/
+func (store *SQLStore) RotateUserSessionTx(ctx context.Context, arg RotateUserSessionTxParams) (*UserSession, error) {
+	var result *UserSession
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		oldSession, err := q.RotateUserSession(ctx, RotateUserSessionParams{
+			ReplacedBy: arg.NewSession.ID,
+			ID:         arg.ID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &RefreshTokenReusedError{SessionID: arg.ID, FamilyID: arg.NewSession.FamilyID}
+			}
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = oldSession.FamilyID
+
+		result, err = q.CreateUserSession(ctx, newSession)
+		return err
+	})
+
+	return result, err
+}
+
+// RotateAdminSessionTxParams contains the input parameters of the admin session rotation transaction
+type RotateAdminSessionTxParams struct {
+	// ID of the session the presented refresh token belongs to
+	ID uuid.UUID `json:"id"`
+	// NewSession is created in the same family as the rotated one
+	NewSession CreateAdminSessionParams `json:"new_session"`
+}
+
+// RotateAdminSessionTx makes the refresh token of an admin session single-use, like RotateUserSessionTx
+func (store *SQLStore) RotateAdminSessionTx(ctx context.Context, arg RotateAdminSessionTxParams) (*AdminSession, error) {
+	var result *AdminSession
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		oldSession, err := q.RotateAdminSession(ctx, RotateAdminSessionParams{
+			ReplacedBy: arg.NewSession.ID,
+			ID:         arg.ID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &RefreshTokenReusedError{SessionID: arg.ID, FamilyID: arg.NewSession.FamilyID}
+			}
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = oldSession.FamilyID
+
+		result, err = q.CreateAdminSession(ctx, newSession)
+		return err
+	})
+
+	return result, err
+}
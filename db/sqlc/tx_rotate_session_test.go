@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateUserSessionTx(t *testing.T) {
+	userSession := createRandomUserSession(t)
+
+	newSession := CreateUserSessionParams{
+		ID:           uuid.New(),
+		UserID:       userSession.UserID,
+		RefreshToken: util.RandomString(100),
+		UserAgent:    util.RandomString(6),
+		ClientIp:     util.RandomString(10),
+		ExpiresAt:    time.Now().Add(time.Hour).UTC(),
+		FamilyID:     userSession.FamilyID,
+	}
+
+	rotatedSession, err := testStore.RotateUserSessionTx(context.Background(), RotateUserSessionTxParams{
+		ID:         userSession.ID,
+		NewSession: newSession,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newSession.ID, rotatedSession.ID)
+	require.Equal(t, userSession.FamilyID, rotatedSession.FamilyID)
+	require.False(t, rotatedSession.IsBlocked)
+
+	oldSession, err := testStore.GetUserSession(context.Background(), userSession.ID)
+	require.NoError(t, err)
+	require.True(t, oldSession.IsBlocked)
+	require.Equal(t, uuid.NullUUID{UUID: newSession.ID, Valid: true}, oldSession.ReplacedBy)
+
+	// the old refresh token is single-use
+	newSession.ID = uuid.New()
+	reusedSession, err := testStore.RotateUserSessionTx(context.Background(), RotateUserSessionTxParams{
+		ID:         userSession.ID,
+		NewSession: newSession,
+	})
+	require.Error(t, err)
+	require.Empty(t, reusedSession)
+
+	var reusedErr *RefreshTokenReusedError
+	require.ErrorAs(t, err, &reusedErr)
+	require.Equal(t, userSession.ID, reusedErr.SessionID)
+	require.Equal(t, userSession.FamilyID, reusedErr.FamilyID)
+
+	_, err = testStore.GetUserSession(context.Background(), newSession.ID)
+	require.Error(t, err)
+
+	revoked, err := testStore.RevokeUserSessionFamily(context.Background(), userSession.FamilyID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	rotatedSession, err = testStore.GetUserSession(context.Background(), rotatedSession.ID)
+	require.NoError(t, err)
+	require.True(t, rotatedSession.IsBlocked)
+}
+
+func TestRotateAdminSessionTx(t *testing.T) {
+	adminSession := createRandomAdminSession(t)
+
+	newSession := CreateAdminSessionParams{
+		ID:           uuid.New(),
+		AdminID:      adminSession.AdminID,
+		RefreshToken: util.RandomString(100),
+		AdminAgent:   util.RandomString(6),
+		ClientIp:     util.RandomString(10),
+		ExpiresAt:    time.Now().Add(time.Hour).UTC(),
+		FamilyID:     adminSession.FamilyID,
+	}
+
+	rotatedSession, err := testStore.RotateAdminSessionTx(context.Background(), RotateAdminSessionTxParams{
+		ID:         adminSession.ID,
+		NewSession: newSession,
+	})
+	require.NoError(t, err)
+	require.Equal(t, adminSession.FamilyID, rotatedSession.FamilyID)
+
+	newSession.ID = uuid.New()
+	_, err = testStore.RotateAdminSessionTx(context.Background(), RotateAdminSessionTxParams{
+		ID:         adminSession.ID,
+		NewSession: newSession,
+	})
+
+	var reusedErr *RefreshTokenReusedError
+	require.ErrorAs(t, err, &reusedErr)
+
+	revoked, err := testStore.RevokeAdminSessionFamily(context.Background(), adminSession.FamilyID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+}
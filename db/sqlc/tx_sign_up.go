@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/util"
 	"github.com/guregu/null/v6"
 )
@@ -23,15 +24,22 @@ type SignUpTxResult struct {
 	IsEmailVerified bool      `json:"is_email_verified"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
-	SecretCode      string    `json:"secret_code"`
+	// SecretCode is the plain otp to email, only its hash is stored
+	SecretCode string `json:"secret_code"`
 }
 
 /*
 SignUpTx performs a shop order item delete from DB, and update the new total price in shop order table
 */
 func (store *SQLStore) SignUpTx(ctx context.Context, arg SignUpTxParams) (*SignUpTxResult, error) {
+	secretCode := secure.OTP()
+	hashedSecretCode, err := util.HashPassword(secretCode)
+	if err != nil {
+		return nil, err
+	}
+
 	var result *SignUpTxResult
-	err := store.execTx(ctx, func(q *Queries) error {
+	err = store.execTx(ctx, func(q *Queries) error {
 		var err error
 
 		arg1 := CreateUserParams{
@@ -49,10 +57,10 @@ func (store *SQLStore) SignUpTx(ctx context.Context, arg SignUpTxParams) (*SignU
 		arg2 := CreateVerifyEmailParams{
 			UserID: null.IntFromPtr(&user.ID),
 			// Email:      user.Email,
-			SecretCode: util.GenerateOTP(),
+			SecretCode: hashedSecretCode,
 		}
 
-		verifyEmail, err := q.CreateVerifyEmail(ctx, arg2)
+		_, err = q.CreateVerifyEmail(ctx, arg2)
 		if err != nil {
 			return err
 		}
@@ -65,7 +73,7 @@ func (store *SQLStore) SignUpTx(ctx context.Context, arg SignUpTxParams) (*SignU
 			IsEmailVerified: user.IsEmailVerified,
 			CreatedAt:       user.CreatedAt,
 			UpdatedAt:       user.UpdatedAt,
-			SecretCode:      verifyEmail.SecretCode,
+			SecretCode:      secretCode,
 		}
 
 		return nil
@@ -35,4 +35,9 @@ func TestSignUpTx(t *testing.T) {
 	// require.Empty(t, result.DefaultPayment)
 	require.NotEmpty(t, result.SecretCode)
 
+	// only the hash of the otp is stored
+	verifyEmail, err := testStore.GetVerifyEmailByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.NotEqual(t, result.SecretCode, verifyEmail.SecretCode)
+	require.NoError(t, util.CheckPassword(result.SecretCode, verifyEmail.SecretCode))
 }
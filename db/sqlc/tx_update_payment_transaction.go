@@ -0,0 +1,220 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guregu/null/v6"
+	"github.com/quagmt/udecimal"
+)
+
+// payment_transaction statuses, the payment providers report the same values
+const (
+	PaymentStatusPending           = "pending"
+	PaymentStatusAuthorized        = "authorized"
+	PaymentStatusCaptured          = "captured"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusFailed            = "failed"
+)
+
+// PaymentProviderCOD is the provider of cash on delivery, it matches payment.ProviderCOD
+const PaymentProviderCOD = "cod"
+
+// paymentStatusTransitions lists the statuses a payment can move to from each status
+var paymentStatusTransitions = map[string][]string{
+	PaymentStatusPending:           {PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusFailed},
+	PaymentStatusAuthorized:        {PaymentStatusCaptured, PaymentStatusRefunded, PaymentStatusFailed},
+	PaymentStatusCaptured:          {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusRefunded},
+	PaymentStatusRefunded:          {},
+	PaymentStatusFailed:            {},
+}
+
+// CanTransitionPaymentStatus reports whether a payment in the from status can move to the to status
+func CanTransitionPaymentStatus(from, to string) bool {
+	for _, next := range paymentStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidPaymentStatusTransitionError is returned when the payment can't move to the reported status
+type InvalidPaymentStatusTransitionError struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (e *InvalidPaymentStatusTransitionError) Error() string {
+	return fmt.Sprintf("payment status can't change from %q to %q", e.From, e.To)
+}
+
+// PaymentAmountMismatchError is returned when the provider reported another amount than the order total
+type PaymentAmountMismatchError struct {
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+}
+
+func (e *PaymentAmountMismatchError) Error() string {
+	return fmt.Sprintf("payment amount mismatch: expected %s, got %s", e.Expected, e.Got)
+}
+
+// UpdatePaymentTransactionTxParams contains the input parameters of the payment update transaction
+type UpdatePaymentTransactionTxParams struct {
+	ID                int64       `json:"id"`
+	ProviderReference null.String `json:"provider_reference"`
+	Status            string      `json:"status"`
+	Amount            string      `json:"amount"` // optional, checked against the amount of the payment
+}
+
+// UpdatePaymentTransactionTxResult is the result of the payment update transaction
+type UpdatePaymentTransactionTxResult struct {
+	PaymentTransaction *PaymentTransaction `json:"payment_transaction"`
+	// ShopOrder, OrderStatus and History are only set when the payment confirmed or cancelled the order
+	ShopOrder   *ShopOrder              `json:"shop_order,omitempty"`
+	OrderStatus *OrderStatus            `json:"order_status,omitempty"`
+	History     *ShopOrderStatusHistory `json:"history,omitempty"`
+	// RestockedSizes is only set when the failed payment cancelled the order
+	RestockedSizes []*ProductSize `json:"restocked_sizes,omitempty"`
+}
+
+/*
+UpdatePaymentTransactionTx records the status a payment provider reported for a payment,
+
+the payment row is locked and the change is checked against the payment statuses,
+a status that was already recorded is ignored since the providers resend their webhooks,
+the provider reference is kept when it's given.
+once the payment is captured, an order that is awaiting payment moves to pending. a cash
+on delivery payment is only captured on delivery, so its order moves to pending once it's
+authorized, the payments of the other providers are captured right after they're authorized.
+once the payment failed the order is cancelled, its items are back in stock and its coupon
+is released. the change is recorded in shop_order_status_history.
+*/
+func (store *SQLStore) UpdatePaymentTransactionTx(ctx context.Context, arg UpdatePaymentTransactionTxParams) (*UpdatePaymentTransactionTxResult, error) {
+	var result *UpdatePaymentTransactionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		paymentTransaction, err := q.GetPaymentTransactionForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Amount != "" {
+			expected, err := udecimal.Parse(paymentTransaction.Amount)
+			if err != nil {
+				return err
+			}
+
+			got, err := udecimal.Parse(arg.Amount)
+			if err != nil || !got.Equal(expected) {
+				return &PaymentAmountMismatchError{
+					Expected: paymentTransaction.Amount,
+					Got:      arg.Amount,
+				}
+			}
+		}
+
+		result = &UpdatePaymentTransactionTxResult{
+			PaymentTransaction: paymentTransaction,
+		}
+
+		statusChanged := paymentTransaction.Status != arg.Status
+		if !statusChanged && !arg.ProviderReference.Valid {
+			return nil
+		}
+
+		if statusChanged && !CanTransitionPaymentStatus(paymentTransaction.Status, arg.Status) {
+			return &InvalidPaymentStatusTransitionError{
+				From: paymentTransaction.Status,
+				To:   arg.Status,
+			}
+		}
+
+		result.PaymentTransaction, err = q.UpdatePaymentTransaction(ctx, UpdatePaymentTransactionParams{
+			ID:                paymentTransaction.ID,
+			ProviderReference: arg.ProviderReference,
+			Status:            arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !statusChanged {
+			return nil
+		}
+
+		var toCode string
+		switch arg.Status {
+		case PaymentStatusCaptured:
+			toCode = OrderStatusPending
+		case PaymentStatusAuthorized:
+			if paymentTransaction.Provider != PaymentProviderCOD {
+				return nil
+			}
+			toCode = OrderStatusPending
+		case PaymentStatusFailed:
+			toCode = OrderStatusCancelled
+		default:
+			return nil
+		}
+
+		shopOrder, err := q.GetShopOrderForUpdate(ctx, paymentTransaction.ShopOrderID)
+		if err != nil {
+			return err
+		}
+
+		if !shopOrder.OrderStatusID.Valid {
+			return nil
+		}
+
+		fromStatus, err := q.GetOrderStatus(ctx, shopOrder.OrderStatusID.Int64)
+		if err != nil {
+			return err
+		}
+
+		// the order was already confirmed or an admin moved it on
+		if fromStatus.Code.String != OrderStatusAwaitingPayment {
+			return nil
+		}
+
+		toStatus, err := q.GetOrderStatusByCode(ctx, null.StringFrom(toCode))
+		if err != nil {
+			return err
+		}
+
+		if toCode == OrderStatusCancelled {
+			result.RestockedSizes, err = releaseShopOrder(ctx, q, shopOrder.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.ShopOrder, err = q.UpdateShopOrderStatusByUserID(ctx, UpdateShopOrderStatusByUserIDParams{
+			OrderStatusID: null.IntFrom(toStatus.ID),
+			ID:            shopOrder.ID,
+			UserID:        shopOrder.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.History, err = q.CreateShopOrderStatusHistory(ctx, CreateShopOrderStatusHistoryParams{
+			ShopOrderID:  shopOrder.ID,
+			FromStatusID: shopOrder.OrderStatusID,
+			ToStatusID:   toStatus.ID,
+			Note:         "payment " + arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.OrderStatus = toStatus
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionPaymentStatus(t *testing.T) {
+	testCases := []struct {
+		from string
+		to   string
+		ok   bool
+	}{
+		{from: PaymentStatusPending, to: PaymentStatusAuthorized, ok: true},
+		{from: PaymentStatusPending, to: PaymentStatusCaptured, ok: true},
+		{from: PaymentStatusPending, to: PaymentStatusFailed, ok: true},
+		{from: PaymentStatusPending, to: PaymentStatusRefunded, ok: false},
+		{from: PaymentStatusAuthorized, to: PaymentStatusCaptured, ok: true},
+		{from: PaymentStatusAuthorized, to: PaymentStatusPending, ok: false},
+		{from: PaymentStatusCaptured, to: PaymentStatusRefunded, ok: true},
+		{from: PaymentStatusCaptured, to: PaymentStatusFailed, ok: false},
+		{from: PaymentStatusCaptured, to: PaymentStatusPartiallyRefunded, ok: true},
+		{from: PaymentStatusPartiallyRefunded, to: PaymentStatusRefunded, ok: true},
+		{from: PaymentStatusPartiallyRefunded, to: PaymentStatusCaptured, ok: false},
+		{from: PaymentStatusRefunded, to: PaymentStatusCaptured, ok: false},
+		{from: PaymentStatusFailed, to: PaymentStatusAuthorized, ok: false},
+		{from: PaymentStatusPending, to: "unknown", ok: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.ok, CanTransitionPaymentStatus(tc.from, tc.to), "%q -> %q", tc.from, tc.to)
+	}
+}
+
+func createRandomAwaitingPaymentPurchase(t *testing.T) *FinishedPurchaseTxResult {
+	return createRandomAwaitingPaymentPurchaseWithType(t, createRandomPaymentType(t))
+}
+
+func createRandomAwaitingPaymentPurchaseWithType(t *testing.T, paymentType PaymentType) *FinishedPurchaseTxResult {
+	userAddress := createRandomAddressWithUser(t)
+	shippingMethod := createRandomShippingMethod(t)
+	shoppingCart, _ := createRandomCartWithItem(t, userAddress.UserID)
+
+	// without an order status the order waits for its payment
+	purchase, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, purchase.PaymentTransaction)
+
+	require.Equal(t, purchase.ShopOrderID, purchase.PaymentTransaction.ShopOrderID)
+	require.Equal(t, paymentType.Provider, purchase.PaymentTransaction.Provider)
+	require.Equal(t, PaymentStatusPending, purchase.PaymentTransaction.Status)
+	require.False(t, purchase.PaymentTransaction.ProviderReference.Valid)
+
+	return purchase
+}
+
+func TestUpdatePaymentTransactionTx(t *testing.T) {
+	purchase := createRandomAwaitingPaymentPurchase(t)
+	paymentTransaction := purchase.PaymentTransaction
+
+	// the amount reported by the provider must match the payment
+	result, err := testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     paymentTransaction.ID,
+		Status: PaymentStatusCaptured,
+		Amount: "0.01",
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var amountErr *PaymentAmountMismatchError
+	require.ErrorAs(t, err, &amountErr)
+
+	// the reference is saved while the payment stays pending
+	providerReference := null.StringFrom("fake_" + util.RandomString(16))
+	result, err = testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:                paymentTransaction.ID,
+		ProviderReference: providerReference,
+		Status:            PaymentStatusPending,
+	})
+	require.NoError(t, err)
+	require.Equal(t, providerReference, result.PaymentTransaction.ProviderReference)
+	require.Equal(t, PaymentStatusPending, result.PaymentTransaction.Status)
+	require.Empty(t, result.ShopOrder)
+
+	result, err = testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     paymentTransaction.ID,
+		Status: PaymentStatusCaptured,
+		Amount: paymentTransaction.Amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusCaptured, result.PaymentTransaction.Status)
+	require.Equal(t, providerReference, result.PaymentTransaction.ProviderReference)
+
+	require.NotEmpty(t, result.ShopOrder)
+	require.Equal(t, OrderStatusPending, result.OrderStatus.Code.String)
+	require.Equal(t, null.IntFrom(result.OrderStatus.ID), result.ShopOrder.OrderStatusID)
+	require.Equal(t, result.OrderStatus.ID, result.History.ToStatusID)
+	require.Equal(t, "payment "+PaymentStatusCaptured, result.History.Note)
+	require.False(t, result.History.AdminID.Valid)
+
+	// the provider resends the same webhook
+	result, err = testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     paymentTransaction.ID,
+		Status: PaymentStatusCaptured,
+		Amount: paymentTransaction.Amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusCaptured, result.PaymentTransaction.Status)
+	require.Empty(t, result.ShopOrder)
+	require.Empty(t, result.History)
+
+	// a captured payment can't fail anymore
+	result, err = testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     paymentTransaction.ID,
+		Status: PaymentStatusFailed,
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var transitionErr *InvalidPaymentStatusTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	require.Equal(t, PaymentStatusCaptured, transitionErr.From)
+	require.Equal(t, PaymentStatusFailed, transitionErr.To)
+}
+
+func TestUpdatePaymentTransactionTxAuthorized(t *testing.T) {
+	admin := createRandomAdmin(t)
+	paymentType, err := testStore.AdminCreatePaymentType(context.Background(), AdminCreatePaymentTypeParams{
+		AdminID:  admin.ID,
+		Value:    util.RandomString(10),
+		IsActive: true,
+		Provider: "fake",
+	})
+	require.NoError(t, err)
+
+	purchase := createRandomAwaitingPaymentPurchaseWithType(t, *paymentType)
+
+	// the order waits for the capture of a payment that isn't cash on delivery
+	result, err := testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:                purchase.PaymentTransaction.ID,
+		ProviderReference: null.StringFrom("fake_" + util.RandomString(16)),
+		Status:            PaymentStatusAuthorized,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusAuthorized, result.PaymentTransaction.Status)
+	require.Empty(t, result.ShopOrder)
+	require.Empty(t, result.History)
+
+	shopOrder, err := testStore.GetShopOrder(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
+
+	orderStatus, err := testStore.GetOrderStatus(context.Background(), shopOrder.OrderStatusID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusAwaitingPayment, orderStatus.Code.String)
+
+	result, err = testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     purchase.PaymentTransaction.ID,
+		Status: PaymentStatusCaptured,
+	})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusPending, result.OrderStatus.Code.String)
+}
+
+func TestUpdatePaymentTransactionTxAuthorizedCashOnDelivery(t *testing.T) {
+	purchase := createRandomAwaitingPaymentPurchase(t)
+	require.Equal(t, PaymentProviderCOD, purchase.PaymentTransaction.Provider)
+
+	// the cash is only collected on delivery so the authorization confirms the order
+	result, err := testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:                purchase.PaymentTransaction.ID,
+		ProviderReference: null.StringFrom(PaymentProviderCOD + "_" + util.RandomString(16)),
+		Status:            PaymentStatusAuthorized,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusAuthorized, result.PaymentTransaction.Status)
+
+	require.NotEmpty(t, result.ShopOrder)
+	require.Equal(t, OrderStatusPending, result.OrderStatus.Code.String)
+	require.Equal(t, "payment "+PaymentStatusAuthorized, result.History.Note)
+}
+
+func TestUpdatePaymentTransactionTxFailed(t *testing.T) {
+	purchase := createRandomAwaitingPaymentPurchase(t)
+
+	result, err := testStore.UpdatePaymentTransactionTx(context.Background(), UpdatePaymentTransactionTxParams{
+		ID:     purchase.PaymentTransaction.ID,
+		Status: PaymentStatusFailed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusFailed, result.PaymentTransaction.Status)
+
+	// the order is cancelled and its items are back in stock
+	require.NotEmpty(t, result.ShopOrder)
+	require.Equal(t, OrderStatusCancelled, result.OrderStatus.Code.String)
+	require.Equal(t, null.IntFrom(result.OrderStatus.ID), result.ShopOrder.OrderStatusID)
+	require.Equal(t, "payment "+PaymentStatusFailed, result.History.Note)
+	require.Len(t, result.RestockedSizes, 1)
+
+	shopOrder, err := testStore.GetShopOrder(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
+
+	orderStatus, err := testStore.GetOrderStatus(context.Background(), shopOrder.OrderStatusID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusCancelled, orderStatus.Code.String)
+}
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guregu/null/v6"
+	"github.com/quagmt/udecimal"
+)
+
+// return_request statuses
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+)
+
+// returnStatusTransitions lists the statuses a return request can move to from each status
+var returnStatusTransitions = map[string][]string{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:  {ReturnStatusReceived, ReturnStatusRejected},
+	ReturnStatusReceived:  {ReturnStatusRefunded},
+	ReturnStatusRejected:  {},
+	ReturnStatusRefunded:  {},
+}
+
+// CanTransitionReturnStatus reports whether a return request in the from status can move to the to status
+func CanTransitionReturnStatus(from, to string) bool {
+	for _, next := range returnStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidReturnStatusTransitionError is returned when the return request can't move to the requested status
+type InvalidReturnStatusTransitionError struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (e *InvalidReturnStatusTransitionError) Error() string {
+	return fmt.Sprintf("return request status can't change from %q to %q", e.From, e.To)
+}
+
+// UpdateReturnRequestTxParams contains the input parameters of the return request update transaction
+type UpdateReturnRequestTxParams struct {
+	AdminID         int64       `json:"admin_id"`
+	ReturnRequestID int64       `json:"return_request_id"`
+	Status          string      `json:"status"`
+	AdminNote       null.String `json:"admin_note"`
+}
+
+// UpdateReturnRequestTxResult is the result of the return request update transaction
+type UpdateReturnRequestTxResult struct {
+	ReturnRequest *ReturnRequest `json:"return_request"`
+	// RestockedSizes is only set when the items are received
+	RestockedSizes []*ProductSize `json:"restocked_sizes,omitempty"`
+}
+
+/*
+UpdateReturnRequestTx moves the return request to the next status as an admin,
+
+the return request row is locked and the change is checked against the return
+workflow. when the items are received their qty is returned to the product sizes,
+when the request is refunded the refund amount is computed from the price and the
+discount the items were bought with, minus the coupon discount kept on the returned lines,
+and it's recorded as a pending refund of the order's captured payment.
+*/
+func (store *SQLStore) UpdateReturnRequestTx(ctx context.Context, arg UpdateReturnRequestTxParams) (*UpdateReturnRequestTxResult, error) {
+	var result *UpdateReturnRequestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		returnRequest, err := q.GetReturnRequestForUpdate(ctx, arg.ReturnRequestID)
+		if err != nil {
+			return err
+		}
+
+		if !CanTransitionReturnStatus(returnRequest.Status, arg.Status) {
+			return &InvalidReturnStatusTransitionError{
+				From: returnRequest.Status,
+				To:   arg.Status,
+			}
+		}
+
+		result = &UpdateReturnRequestTxResult{}
+
+		var refundAmount null.String
+		if arg.Status == ReturnStatusReceived || arg.Status == ReturnStatusRefunded {
+			items, err := q.ListReturnRequestItemsByRequestID(ctx, returnRequest.ID)
+			if err != nil {
+				return err
+			}
+
+			switch arg.Status {
+			case ReturnStatusReceived:
+				result.RestockedSizes, err = restockReturnedItems(ctx, q, items)
+			case ReturnStatusRefunded:
+				refundAmount, err = returnRefundAmount(items)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.Status == ReturnStatusRefunded {
+			refund, captured, err := requestOrderRefund(ctx, q, returnRequest.ShopOrderID, refundAmount.String)
+			if err != nil {
+				return err
+			}
+			// the refund can't give back more than what's left of the captured payment
+			if captured {
+				refundAmount = null.StringFrom(refund.StringFixed(2))
+			}
+		}
+
+		result.ReturnRequest, err = q.AdminUpdateReturnRequest(ctx, AdminUpdateReturnRequestParams{
+			AdminID:      arg.AdminID,
+			ID:           returnRequest.ID,
+			Status:       arg.Status,
+			AdminNote:    arg.AdminNote,
+			RefundAmount: refundAmount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// restockReturnedItems returns the qty of the received items to their product sizes
+func restockReturnedItems(ctx context.Context, q *Queries, items []*ListReturnRequestItemsByRequestIDRow) ([]*ProductSize, error) {
+	restockedSizes := make([]*ProductSize, 0, len(items))
+	for _, item := range items {
+		// items made before the size was kept on the order can't be restocked
+		if !item.SizeID.Valid {
+			continue
+		}
+
+		productSize, err := q.RestockProductSize(ctx, RestockProductSizeParams{
+			ID:  item.SizeID.Int64,
+			Qty: item.Qty,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		restockedSizes = append(restockedSizes, productSize)
+	}
+	return restockedSizes, nil
+}
+
+/*
+returnRefundAmount sums the returned qty of every item at the price and discount it was bought with,
+
+every order item keeps its share of the order's coupon discount, the returned qty
+gives back its part of that share.
+*/
+func returnRefundAmount(items []*ListReturnRequestItemsByRequestIDRow) (null.String, error) {
+	total := udecimal.Zero
+	for _, item := range items {
+		lineTotal, err := discountedLineTotal(item.Price, item.Qty, int64(item.Discount))
+		if err != nil {
+			return null.String{}, err
+		}
+
+		couponShare, err := itemCouponShare(item.CouponDiscount, item.Qty, item.Quantity)
+		if err != nil {
+			return null.String{}, err
+		}
+		total = total.Add(lineTotal.Sub(couponShare))
+	}
+
+	return null.StringFrom(total.RoundBank(2).StringFixed(2)), nil
+}
+
+// itemCouponShare is the part of an order item's coupon discount the given qty of it was bought with
+func itemCouponShare(couponDiscount string, qty, quantity int32) (udecimal.Decimal, error) {
+	discount, err := udecimal.Parse(couponDiscount)
+	if err != nil {
+		return udecimal.Zero, err
+	}
+
+	if !discount.IsPos() || quantity <= 0 {
+		return udecimal.Zero, nil
+	}
+
+	if qty >= quantity {
+		return discount, nil
+	}
+
+	share, err := discount.Mul64(uint64(qty)).Div64(uint64(quantity))
+	if err != nil {
+		return udecimal.Zero, err
+	}
+	return share.RoundBank(2), nil
+}
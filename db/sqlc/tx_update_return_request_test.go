@@ -0,0 +1,252 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cshop/v3/util"
+	"github.com/guregu/null/v6"
+	"github.com/quagmt/udecimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionReturnStatus(t *testing.T) {
+	testCases := []struct {
+		from string
+		to   string
+		ok   bool
+	}{
+		{from: ReturnStatusRequested, to: ReturnStatusApproved, ok: true},
+		{from: ReturnStatusRequested, to: ReturnStatusRejected, ok: true},
+		{from: ReturnStatusRequested, to: ReturnStatusReceived, ok: false},
+		{from: ReturnStatusApproved, to: ReturnStatusReceived, ok: true},
+		{from: ReturnStatusApproved, to: ReturnStatusRefunded, ok: false},
+		{from: ReturnStatusReceived, to: ReturnStatusRefunded, ok: true},
+		{from: ReturnStatusReceived, to: ReturnStatusRejected, ok: false},
+		{from: ReturnStatusRejected, to: ReturnStatusApproved, ok: false},
+		{from: ReturnStatusRefunded, to: ReturnStatusReceived, ok: false},
+		{from: ReturnStatusRequested, to: "unknown", ok: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.ok, CanTransitionReturnStatus(tc.from, tc.to), "%q -> %q", tc.from, tc.to)
+	}
+}
+
+func TestUpdateReturnRequestTx(t *testing.T) {
+	admin := createRandomAdmin(t)
+	created, shopOrderItem := createRandomReturnRequestTx(t)
+
+	productSize, err := testStore.GetProductSize(context.Background(), shopOrderItem.SizeID.Int64)
+	require.NoError(t, err)
+
+	// the items can't be received before the request is approved
+	result, err := testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+		AdminID:         admin.ID,
+		ReturnRequestID: created.ReturnRequest.ID,
+		Status:          ReturnStatusReceived,
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var transitionErr *InvalidReturnStatusTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	require.Equal(t, ReturnStatusRequested, transitionErr.From)
+
+	result, err = testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+		AdminID:         admin.ID,
+		ReturnRequestID: created.ReturnRequest.ID,
+		Status:          ReturnStatusApproved,
+		AdminNote:       null.StringFrom("send it back with the tags"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReturnStatusApproved, result.ReturnRequest.Status)
+	require.Equal(t, null.IntFrom(admin.ID), result.ReturnRequest.AdminID)
+	require.Equal(t, "send it back with the tags", result.ReturnRequest.AdminNote)
+	require.Empty(t, result.RestockedSizes)
+
+	result, err = testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+		AdminID:         admin.ID,
+		ReturnRequestID: created.ReturnRequest.ID,
+		Status:          ReturnStatusReceived,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReturnStatusReceived, result.ReturnRequest.Status)
+	require.Equal(t, "send it back with the tags", result.ReturnRequest.AdminNote)
+	require.Len(t, result.RestockedSizes, 1)
+	require.Equal(t, productSize.ID, result.RestockedSizes[0].ID)
+	require.Equal(t, productSize.Qty+shopOrderItem.Quantity, result.RestockedSizes[0].Qty)
+
+	result, err = testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+		AdminID:         admin.ID,
+		ReturnRequestID: created.ReturnRequest.ID,
+		Status:          ReturnStatusRefunded,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReturnStatusRefunded, result.ReturnRequest.Status)
+	require.True(t, result.ReturnRequest.RefundAmount.Valid)
+
+	refundAmount, err := discountedLineTotal(shopOrderItem.Price, shopOrderItem.Quantity, int64(shopOrderItem.Discount))
+	require.NoError(t, err)
+	require.Equal(t, refundAmount.RoundBank(2).StringFixed(2), result.ReturnRequest.RefundAmount.String)
+}
+
+// createRandomScopedCouponPurchase buys two items with a coupon that only covers the product of the first one
+func createRandomScopedCouponPurchase(t *testing.T, admin Admin) (*FinishedPurchaseTxResult, int64, *ShopOrderItem, *ShopOrderItem) {
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	shoppingCart, eligibleItem := createRandomCartWithItem(t, userAddress.UserID)
+
+	otherItem := createRandomProductItem(t)
+	otherSize := createRandomProductSizeWithItemID(t, otherItem.ID)
+	_, err := testStore.CreateShoppingCartItem(context.Background(), CreateShoppingCartItemParams{
+		ShoppingCartID: shoppingCart.ID,
+		ProductItemID:  otherItem.ID,
+		SizeID:         otherSize.ID,
+		Qty:            1,
+	})
+	require.NoError(t, err)
+
+	coupon, err := testStore.AdminCreateCoupon(context.Background(), AdminCreateCouponParams{
+		AdminID:       admin.ID,
+		Code:          NormalizeCouponCode(util.RandomString(10)),
+		DiscountType:  CouponDiscountPercentage,
+		DiscountValue: "10",
+		MinOrderValue: "0",
+		ProductID:     null.IntFrom(eligibleItem.ProductID),
+		StartDate:     time.Now().Add(-time.Hour),
+		EndDate:       time.Now().Add(time.Hour),
+		Active:        true,
+	})
+	require.NoError(t, err)
+
+	orderStatus, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusDelivered))
+	require.NoError(t, err)
+
+	purchase, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    orderStatus.ID,
+		CouponCode:       coupon.Code,
+	})
+	require.NoError(t, err)
+
+	shopOrderItems, err := testStore.ListShopOrderItemsByOrderID(context.Background(), purchase.ShopOrderID)
+	require.NoError(t, err)
+	require.Len(t, shopOrderItems, 2)
+
+	if shopOrderItems[0].ProductItemID != eligibleItem.ID {
+		shopOrderItems[0], shopOrderItems[1] = shopOrderItems[1], shopOrderItems[0]
+	}
+	return purchase, userAddress.UserID, shopOrderItems[0], shopOrderItems[1]
+}
+
+// refundReturnRequest creates a return request for the whole qty of the item and moves it to refunded
+func refundReturnRequest(t *testing.T, admin Admin, userID int64, shopOrderItem *ShopOrderItem) *ReturnRequest {
+	created, err := testStore.CreateReturnRequestTx(context.Background(), CreateReturnRequestTxParams{
+		UserID:      userID,
+		ShopOrderID: shopOrderItem.OrderID,
+		Reason:      util.RandomString(20),
+		Items: []ReturnRequestItemParams{
+			{ShopOrderItemID: shopOrderItem.ID, Qty: shopOrderItem.Quantity},
+		},
+	})
+	require.NoError(t, err)
+
+	var result *UpdateReturnRequestTxResult
+	for _, status := range []string{ReturnStatusApproved, ReturnStatusReceived, ReturnStatusRefunded} {
+		result, err = testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+			AdminID:         admin.ID,
+			ReturnRequestID: created.ReturnRequest.ID,
+			Status:          status,
+		})
+		require.NoError(t, err)
+	}
+	return result.ReturnRequest
+}
+
+func TestUpdateReturnRequestTxCouponRefund(t *testing.T) {
+	admin := createRandomAdmin(t)
+	purchase, userID, eligibleItem, otherItem := createRandomScopedCouponPurchase(t, admin)
+
+	// the whole coupon discount is kept on the line the coupon applies to
+	require.Equal(t, purchase.CouponDiscount, eligibleItem.CouponDiscount)
+	require.True(t, udecimal.MustParse(otherItem.CouponDiscount).IsZero())
+
+	// the line outside the coupon scope gives back its full total
+	otherTotal, err := discountedLineTotal(otherItem.Price, otherItem.Quantity, int64(otherItem.Discount))
+	require.NoError(t, err)
+
+	returnRequest := refundReturnRequest(t, admin, userID, otherItem)
+	require.Equal(t, otherTotal.StringFixed(2), returnRequest.RefundAmount.String)
+
+	// the covered line gives back its total minus the whole coupon discount
+	eligibleTotal, err := discountedLineTotal(eligibleItem.Price, eligibleItem.Quantity, int64(eligibleItem.Discount))
+	require.NoError(t, err)
+
+	returnRequest = refundReturnRequest(t, admin, userID, eligibleItem)
+	couponDiscount := udecimal.MustParse(purchase.CouponDiscount)
+	require.Equal(t, eligibleTotal.Sub(couponDiscount).StringFixed(2), returnRequest.RefundAmount.String)
+}
+
+func TestSpreadCouponDiscount(t *testing.T) {
+	lines := []purchaseLine{
+		{lineTotal: udecimal.MustParse("10.00"), couponEligible: true},
+		{lineTotal: udecimal.MustParse("50.00"), couponEligible: false},
+		{lineTotal: udecimal.MustParse("20.00"), couponEligible: true},
+		{lineTotal: udecimal.MustParse("30.00"), couponEligible: true},
+	}
+
+	err := spreadCouponDiscount(lines, udecimal.MustParse("10.00"), udecimal.MustParse("60.00"))
+	require.NoError(t, err)
+
+	require.Equal(t, "1.67", lines[0].couponDiscount.StringFixed(2))
+	require.True(t, lines[1].couponDiscount.IsZero())
+	require.Equal(t, "3.33", lines[2].couponDiscount.StringFixed(2))
+	// the last eligible line takes the rest so the shares add up to the discount
+	require.Equal(t, "5.00", lines[3].couponDiscount.StringFixed(2))
+}
+
+func TestItemCouponShare(t *testing.T) {
+	testCases := []struct {
+		couponDiscount string
+		qty            int32
+		quantity       int32
+		expected       string
+	}{
+		{couponDiscount: "0", qty: 1, quantity: 2, expected: "0.00"},
+		{couponDiscount: "0.00", qty: 3, quantity: 3, expected: "0.00"},
+		{couponDiscount: "9.00", qty: 3, quantity: 3, expected: "9.00"},
+		{couponDiscount: "9.00", qty: 1, quantity: 3, expected: "3.00"},
+		{couponDiscount: "10.00", qty: 1, quantity: 3, expected: "3.33"},
+	}
+
+	for _, tc := range testCases {
+		share, err := itemCouponShare(tc.couponDiscount, tc.qty, tc.quantity)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, share.StringFixed(2))
+	}
+}
+
+func TestUpdateReturnRequestTxRejected(t *testing.T) {
+	admin := createRandomAdmin(t)
+	created, shopOrderItem := createRandomReturnRequestTx(t)
+
+	result, err := testStore.UpdateReturnRequestTx(context.Background(), UpdateReturnRequestTxParams{
+		AdminID:         admin.ID,
+		ReturnRequestID: created.ReturnRequest.ID,
+		Status:          ReturnStatusRejected,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReturnStatusRejected, result.ReturnRequest.Status)
+
+	// a rejected request doesn't hold the qty anymore
+	returnedQty, err := testStore.GetReturnedQtyByShopOrderItemID(context.Background(), shopOrderItem.ID)
+	require.NoError(t, err)
+	require.Zero(t, returnedQty)
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guregu/null/v6"
+)
+
+// order_status codes known by the order state machine
+const (
+	OrderStatusAwaitingPayment = "awaiting_payment"
+	OrderStatusPending         = "pending"
+	OrderStatusProcessing      = "processing"
+	OrderStatusShipped         = "shipped"
+	OrderStatusDelivered       = "delivered"
+	OrderStatusCancelled       = "cancelled"
+	OrderStatusReturned        = "returned"
+)
+
+// ActiveOrderStatuses are the codes of the orders that are not finished yet
+var ActiveOrderStatuses = []string{OrderStatusAwaitingPayment, OrderStatusPending, OrderStatusProcessing, OrderStatusShipped}
+
+// orderStatusTransitions lists the statuses an order can move to from each status
+var orderStatusTransitions = map[string][]string{
+	// only a confirmed payment moves an order out of awaiting payment to pending, see UpdatePaymentTransactionTx
+	OrderStatusAwaitingPayment: {OrderStatusCancelled},
+	OrderStatusPending:         {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing:      {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:         {OrderStatusDelivered, OrderStatusReturned},
+	OrderStatusDelivered:       {OrderStatusReturned},
+	OrderStatusCancelled:       {},
+	OrderStatusReturned:        {},
+}
+
+// CanTransitionOrderStatus reports whether an order in the from status can move to the to status,
+// an order with a status outside the state machine (from is empty) can move to any known status
+func CanTransitionOrderStatus(from, to string) bool {
+	if _, ok := orderStatusTransitions[to]; !ok {
+		return false
+	}
+
+	if from == "" {
+		return true
+	}
+
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidOrderStatusTransitionError is returned when the order can't move to the requested status
+type InvalidOrderStatusTransitionError struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (e *InvalidOrderStatusTransitionError) Error() string {
+	return fmt.Sprintf("order status can't change from %q to %q", e.From, e.To)
+}
+
+// UpdateShopOrderTxParams contains the input parameters of the shop order update transaction
+type UpdateShopOrderTxParams struct {
+	UpdateShopOrderParams
+	Note string `json:"note"`
+}
+
+// UpdateShopOrderTxResult is the result of the shop order update transaction
+type UpdateShopOrderTxResult struct {
+	ShopOrder *ShopOrder `json:"shop_order"`
+	// OrderStatus and History are only set when the order status changed
+	OrderStatus *OrderStatus            `json:"order_status,omitempty"`
+	History     *ShopOrderStatusHistory `json:"history,omitempty"`
+	// RestockedSizes is only set when the order moved to cancelled or returned
+	RestockedSizes []*ProductSize `json:"restocked_sizes,omitempty"`
+}
+
+/*
+UpdateShopOrderTx updates the shop order as an admin,
+
+when the order status changes, the order row is locked, the change is checked
+against the order state machine and recorded in shop_order_status_history
+with the admin id and the note. an order moving to cancelled or returned gets its
+items back in stock and its coupon released like a cancellation by the user.
+a new order total is followed by the payment of the order, see adjustOrderPayment.
+*/
+func (store *SQLStore) UpdateShopOrderTx(ctx context.Context, arg UpdateShopOrderTxParams) (*UpdateShopOrderTxResult, error) {
+	var result *UpdateShopOrderTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		shopOrder, err := q.GetShopOrderForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		statusChanged := arg.OrderStatusID.Valid && arg.OrderStatusID != shopOrder.OrderStatusID
+
+		var toStatus *OrderStatus
+		if statusChanged {
+			toStatus, err = q.GetOrderStatus(ctx, arg.OrderStatusID.Int64)
+			if err != nil {
+				return err
+			}
+
+			var fromCode string
+			if shopOrder.OrderStatusID.Valid {
+				fromStatus, err := q.GetOrderStatus(ctx, shopOrder.OrderStatusID.Int64)
+				if err != nil {
+					return err
+				}
+				fromCode = fromStatus.Code.String
+			}
+
+			if !CanTransitionOrderStatus(fromCode, toStatus.Code.String) {
+				return &InvalidOrderStatusTransitionError{
+					From: fromCode,
+					To:   toStatus.Code.String,
+				}
+			}
+		}
+
+		if arg.OrderTotal.Valid {
+			err = adjustOrderPayment(ctx, q, shopOrder.ID, shopOrder.OrderTotal, arg.OrderTotal.String)
+			if err != nil {
+				return err
+			}
+		}
+
+		updatedShopOrder, err := q.UpdateShopOrder(ctx, arg.UpdateShopOrderParams)
+		if err != nil {
+			return err
+		}
+
+		result = &UpdateShopOrderTxResult{
+			ShopOrder: updatedShopOrder,
+		}
+
+		if !statusChanged {
+			return nil
+		}
+
+		history, err := q.CreateShopOrderStatusHistory(ctx, CreateShopOrderStatusHistoryParams{
+			ShopOrderID:  shopOrder.ID,
+			FromStatusID: shopOrder.OrderStatusID,
+			ToStatusID:   toStatus.ID,
+			AdminID:      null.IntFrom(arg.AdminID),
+			Note:         arg.Note,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.OrderStatus = toStatus
+		result.History = history
+
+		if toStatus.Code.String == OrderStatusCancelled || toStatus.Code.String == OrderStatusReturned {
+			result.RestockedSizes, err = releaseShopOrder(ctx, q, shopOrder.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
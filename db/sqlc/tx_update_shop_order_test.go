@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guregu/null/v6"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionOrderStatus(t *testing.T) {
+	testCases := []struct {
+		from string
+		to   string
+		ok   bool
+	}{
+		{from: OrderStatusAwaitingPayment, to: OrderStatusPending, ok: false},
+		{from: OrderStatusAwaitingPayment, to: OrderStatusCancelled, ok: true},
+		{from: OrderStatusAwaitingPayment, to: OrderStatusProcessing, ok: false},
+		{from: OrderStatusPending, to: OrderStatusProcessing, ok: true},
+		{from: OrderStatusPending, to: OrderStatusCancelled, ok: true},
+		{from: OrderStatusPending, to: OrderStatusDelivered, ok: false},
+		{from: OrderStatusProcessing, to: OrderStatusShipped, ok: true},
+		{from: OrderStatusShipped, to: OrderStatusDelivered, ok: true},
+		{from: OrderStatusShipped, to: OrderStatusCancelled, ok: false},
+		{from: OrderStatusDelivered, to: OrderStatusReturned, ok: true},
+		{from: OrderStatusDelivered, to: OrderStatusShipped, ok: false},
+		{from: OrderStatusCancelled, to: OrderStatusPending, ok: false},
+		{from: OrderStatusReturned, to: OrderStatusDelivered, ok: false},
+		{from: "", to: OrderStatusShipped, ok: true},
+		{from: "", to: "unknown", ok: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.ok, CanTransitionOrderStatus(tc.from, tc.to), "%q -> %q", tc.from, tc.to)
+	}
+}
+
+func TestUpdateShopOrderTx(t *testing.T) {
+	admin := createRandomAdmin(t)
+	shopOrder := createRandomShopOrder(t)
+
+	pending, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusPending))
+	require.NoError(t, err)
+	processing, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusProcessing))
+	require.NoError(t, err)
+	delivered, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusDelivered))
+	require.NoError(t, err)
+
+	// start the order from pending, whatever status the random order got
+	_, err = testStore.UpdateShopOrder(context.Background(), UpdateShopOrderParams{
+		ID:            shopOrder.ID,
+		AdminID:       admin.ID,
+		OrderStatusID: null.IntFrom(pending.ID),
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.UpdateShopOrderTx(context.Background(), UpdateShopOrderTxParams{
+		UpdateShopOrderParams: UpdateShopOrderParams{
+			ID:            shopOrder.ID,
+			AdminID:       admin.ID,
+			OrderStatusID: null.IntFrom(processing.ID),
+		},
+		Note: "packing",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+	require.Equal(t, null.IntFrom(processing.ID), result.ShopOrder.OrderStatusID)
+	require.Equal(t, processing.ID, result.OrderStatus.ID)
+
+	require.NotEmpty(t, result.History)
+	require.Equal(t, shopOrder.ID, result.History.ShopOrderID)
+	require.Equal(t, null.IntFrom(pending.ID), result.History.FromStatusID)
+	require.Equal(t, processing.ID, result.History.ToStatusID)
+	require.Equal(t, null.IntFrom(admin.ID), result.History.AdminID)
+	require.Equal(t, "packing", result.History.Note)
+
+	history, err := testStore.ListShopOrderStatusHistoryByUserIDOrderID(context.Background(), ListShopOrderStatusHistoryByUserIDOrderIDParams{
+		UserID:      shopOrder.UserID,
+		ShopOrderID: shopOrder.ID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	require.Equal(t, null.StringFrom(OrderStatusProcessing), history[len(history)-1].ToStatusCode)
+
+	// processing can't jump to delivered
+	result, err = testStore.UpdateShopOrderTx(context.Background(), UpdateShopOrderTxParams{
+		UpdateShopOrderParams: UpdateShopOrderParams{
+			ID:            shopOrder.ID,
+			AdminID:       admin.ID,
+			OrderStatusID: null.IntFrom(delivered.ID),
+		},
+	})
+	require.Error(t, err)
+	require.Empty(t, result)
+
+	var transitionErr *InvalidOrderStatusTransitionError
+	require.True(t, errors.As(err, &transitionErr))
+	require.Equal(t, OrderStatusProcessing, transitionErr.From)
+	require.Equal(t, OrderStatusDelivered, transitionErr.To)
+
+	// updating other fields doesn't record any history
+	result, err = testStore.UpdateShopOrderTx(context.Background(), UpdateShopOrderTxParams{
+		UpdateShopOrderParams: UpdateShopOrderParams{
+			ID:          shopOrder.ID,
+			AdminID:     admin.ID,
+			TrackNumber: null.StringFrom(shopOrder.TrackNumber),
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.History)
+	require.Nil(t, result.OrderStatus)
+}
+
+func TestUpdateShopOrderTxCancelReleasesOrder(t *testing.T) {
+	admin := createRandomAdmin(t)
+	coupon := adminCreateRandomCoupon(t, admin)
+	userAddress := createRandomAddressWithUser(t)
+	paymentType := createRandomPaymentType(t)
+	shippingMethod := createRandomShippingMethod(t)
+	shoppingCart, _ := createRandomCartWithItem(t, userAddress.UserID)
+
+	pending, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusPending))
+	require.NoError(t, err)
+	cancelled, err := testStore.GetOrderStatusByCode(context.Background(), null.StringFrom(OrderStatusCancelled))
+	require.NoError(t, err)
+
+	cartItems, err := testStore.ListShoppingCartItemsByCartID(context.Background(), shoppingCart.ID)
+	require.NoError(t, err)
+	require.Len(t, cartItems, 1)
+
+	productSize, err := testStore.GetProductSize(context.Background(), cartItems[0].SizeID)
+	require.NoError(t, err)
+
+	purchase, err := testStore.FinishedPurchaseTx(context.Background(), FinishedPurchaseTxParams{
+		UserID:           userAddress.UserID,
+		AddressID:        userAddress.ID,
+		PaymentTypeID:    paymentType.ID,
+		ShoppingCartID:   shoppingCart.ID,
+		ShippingMethodID: shippingMethod.ID,
+		OrderStatusID:    pending.ID,
+		CouponCode:       coupon.Code,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.UpdateShopOrderTx(context.Background(), UpdateShopOrderTxParams{
+		UpdateShopOrderParams: UpdateShopOrderParams{
+			ID:            purchase.ShopOrderID,
+			AdminID:       admin.ID,
+			OrderStatusID: null.IntFrom(cancelled.ID),
+		},
+		Note: "out of stock",
+	})
+	require.NoError(t, err)
+	require.Equal(t, cancelled.ID, result.OrderStatus.ID)
+
+	require.Len(t, result.RestockedSizes, 1)
+	require.Equal(t, productSize.ID, result.RestockedSizes[0].ID)
+	require.Equal(t, productSize.Qty, result.RestockedSizes[0].Qty)
+
+	// the coupon can be used again
+	_, err = testStore.GetCouponRedemptionByShopOrderID(context.Background(), purchase.ShopOrderID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	gotCoupon, err := testStore.GetCouponByCodeForUpdate(context.Background(), coupon.Code)
+	require.NoError(t, err)
+	require.Zero(t, gotCoupon.UsedCount)
+}
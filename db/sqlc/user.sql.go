@@ -91,6 +91,40 @@ func (q *Queries) AdminUpdateUser(ctx context.Context, arg AdminUpdateUserParams
 	return &i, err
 }
 
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE "user"
+SET
+username = 'deleted user',
+email = 'deleted-' || id || '@deleted.invalid',
+password = '',
+default_payment = NULL,
+default_address_id = NULL,
+is_blocked = TRUE,
+is_email_verified = FALSE,
+updated_at = now()
+WHERE id = $1
+RETURNING id, username, email, password, default_payment, default_address_id, created_at, updated_at, is_blocked, is_email_verified
+`
+
+// the row is kept for the orders of the user, the email is freed for a new account
+func (q *Queries) AnonymizeUser(ctx context.Context, id int64) (*User, error) {
+	row := q.db.QueryRow(ctx, anonymizeUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.DefaultPayment,
+		&i.DefaultAddressID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsBlocked,
+		&i.IsEmailVerified,
+	)
+	return &i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO "user" (
   username,
@@ -216,6 +250,76 @@ func (q *Queries) CreateUserWithCartAndWishList(ctx context.Context, arg CreateU
 	return &i, err
 }
 
+const createVerifiedUserWithCartAndWishList = `-- name: CreateVerifiedUserWithCartAndWishList :one
+WITH t1 AS(
+INSERT INTO "user" (
+  username,
+  email,
+  password,
+  is_email_verified
+) VALUES (
+  $1, $2, $3, TRUE
+)
+RETURNING id, username, email, password, default_payment, default_address_id, created_at, updated_at, is_blocked, is_email_verified
+),
+t2 AS(
+  INSERT INTO "shopping_cart" (
+  user_id
+) VALUES ((Select id from t1))
+  RETURNING id
+),
+t3 AS(
+  INSERT INTO "wish_list" (
+    user_id
+) VALUES ((Select id from t1))
+  RETURNING id
+)
+
+SELECT t1.id, t1.username, t1.email, t1.password, t1.default_payment, t1.default_address_id, t1.created_at, t1.updated_at, t1.is_blocked, t1.is_email_verified, t2.id AS shopping_cart_id, t3.id AS wish_list_id FROM t1, t2, t3
+`
+
+type CreateVerifiedUserWithCartAndWishListParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type CreateVerifiedUserWithCartAndWishListRow struct {
+	ID               int64     `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	Password         string    `json:"password"`
+	DefaultPayment   null.Int  `json:"default_payment"`
+	DefaultAddressID null.Int  `json:"default_address_id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	IsBlocked        bool      `json:"is_blocked"`
+	IsEmailVerified  bool      `json:"is_email_verified"`
+	ShoppingCartID   int64     `json:"shopping_cart_id"`
+	WishListID       int64     `json:"wish_list_id"`
+}
+
+// the email of a social login is verified by its provider
+func (q *Queries) CreateVerifiedUserWithCartAndWishList(ctx context.Context, arg CreateVerifiedUserWithCartAndWishListParams) (*CreateVerifiedUserWithCartAndWishListRow, error) {
+	row := q.db.QueryRow(ctx, createVerifiedUserWithCartAndWishList, arg.Username, arg.Email, arg.Password)
+	var i CreateVerifiedUserWithCartAndWishListRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.DefaultPayment,
+		&i.DefaultAddressID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsBlocked,
+		&i.IsEmailVerified,
+		&i.ShoppingCartID,
+		&i.WishListID,
+	)
+	return &i, err
+}
+
 const deleteUser = `-- name: DeleteUser :one
 DELETE FROM "user"
 WHERE id = $1
@@ -489,6 +593,39 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (*User,
 	return &i, err
 }
 
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE "user"
+SET
+email = $1,
+is_email_verified = TRUE,
+updated_at = now()
+WHERE id = $2
+RETURNING id, username, email, password, default_payment, default_address_id, created_at, updated_at, is_blocked, is_email_verified
+`
+
+type UpdateUserEmailParams struct {
+	Email string `json:"email"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (*User, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.DefaultPayment,
+		&i.DefaultAddressID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsBlocked,
+		&i.IsEmailVerified,
+	)
+	return &i, err
+}
+
 const updateUserEmailisVerifiedForTest = `-- name: UpdateUserEmailisVerifiedForTest :exec
 UPDATE "user"
 SET is_email_verified = TRUE
@@ -536,3 +673,37 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	)
 	return &i, err
 }
+
+const verifyUserEmailForSocialLogin = `-- name: VerifyUserEmailForSocialLogin :one
+UPDATE "user"
+SET
+password = CASE WHEN is_email_verified THEN password ELSE $1 END,
+is_email_verified = TRUE,
+updated_at = now()
+WHERE id = $2
+RETURNING id, username, email, password, default_payment, default_address_id, created_at, updated_at, is_blocked, is_email_verified
+`
+
+type VerifyUserEmailForSocialLoginParams struct {
+	Password string `json:"password"`
+	ID       int64  `json:"id"`
+}
+
+// the password set before the email was verified might not be the owner's, so it's replaced
+func (q *Queries) VerifyUserEmailForSocialLogin(ctx context.Context, arg VerifyUserEmailForSocialLoginParams) (*User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmailForSocialLogin, arg.Password, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.DefaultPayment,
+		&i.DefaultAddressID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsBlocked,
+		&i.IsEmailVerified,
+	)
+	return &i, err
+}
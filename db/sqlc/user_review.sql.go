@@ -7,29 +7,52 @@ package db
 
 import (
 	"context"
+	"time"
 
 	null "github.com/guregu/null/v6"
 )
 
+const countUserReviewImages = `-- name: CountUserReviewImages :one
+SELECT COUNT(*) FROM "user_review_image"
+WHERE review_id = $1
+`
+
+func (q *Queries) CountUserReviewImages(ctx context.Context, reviewID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserReviewImages, reviewID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUserReview = `-- name: CreateUserReview :one
 INSERT INTO "user_review" (
   user_id,
   ordered_product_id,
-  rating_value
+  rating_value,
+  title,
+  body
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 )
-RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value
+RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at
 `
 
 type CreateUserReviewParams struct {
-	UserID           int64 `json:"user_id"`
-	OrderedProductID int64 `json:"ordered_product_id"`
-	RatingValue      int32 `json:"rating_value"`
+	UserID           int64  `json:"user_id"`
+	OrderedProductID int64  `json:"ordered_product_id"`
+	RatingValue      int32  `json:"rating_value"`
+	Title            string `json:"title"`
+	Body             string `json:"body"`
 }
 
 func (q *Queries) CreateUserReview(ctx context.Context, arg CreateUserReviewParams) (*UserReview, error) {
-	row := q.db.QueryRow(ctx, createUserReview, arg.UserID, arg.OrderedProductID, arg.RatingValue)
+	row := q.db.QueryRow(ctx, createUserReview,
+		arg.UserID,
+		arg.OrderedProductID,
+		arg.RatingValue,
+		arg.Title,
+		arg.Body,
+	)
 	var i UserReview
 	err := row.Scan(
 		&i.ID,
@@ -38,6 +61,50 @@ func (q *Queries) CreateUserReview(ctx context.Context, arg CreateUserReviewPara
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.RatingValue,
+		&i.ProductID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.AdminReply,
+		&i.RepliedAt,
+		&i.ModeratedAt,
+	)
+	return &i, err
+}
+
+const createUserReviewImage = `-- name: CreateUserReviewImage :one
+WITH review AS (
+UPDATE "user_review"
+SET
+status = CASE WHEN status = 'approved' THEN 'pending' ELSE status END,
+updated_at = now()
+WHERE id = $1
+AND user_id = $2
+RETURNING id
+)
+INSERT INTO "user_review_image" (
+  review_id,
+  image_url
+)
+SELECT id, $3::VARCHAR FROM review
+RETURNING id, review_id, image_url, created_at
+`
+
+type CreateUserReviewImageParams struct {
+	ReviewID int64  `json:"review_id"`
+	UserID   int64  `json:"user_id"`
+	ImageUrl string `json:"image_url"`
+}
+
+// a new image sends an approved review back to the moderation
+func (q *Queries) CreateUserReviewImage(ctx context.Context, arg CreateUserReviewImageParams) (*UserReviewImage, error) {
+	row := q.db.QueryRow(ctx, createUserReviewImage, arg.ReviewID, arg.UserID, arg.ImageUrl)
+	var i UserReviewImage
+	err := row.Scan(
+		&i.ID,
+		&i.ReviewID,
+		&i.ImageUrl,
+		&i.CreatedAt,
 	)
 	return &i, err
 }
@@ -46,7 +113,7 @@ const deleteUserReview = `-- name: DeleteUserReview :one
 DELETE FROM "user_review"
 WHERE id = $1
 And user_id =$2
-RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value
+RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at
 `
 
 type DeleteUserReviewParams struct {
@@ -64,12 +131,19 @@ func (q *Queries) DeleteUserReview(ctx context.Context, arg DeleteUserReviewPara
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.RatingValue,
+		&i.ProductID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.AdminReply,
+		&i.RepliedAt,
+		&i.ModeratedAt,
 	)
 	return &i, err
 }
 
 const getUserReview = `-- name: GetUserReview :one
-SELECT id, user_id, ordered_product_id, created_at, updated_at, rating_value FROM "user_review"
+SELECT id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at FROM "user_review"
 WHERE id = $1 
 AND user_id = $2
 LIMIT 1
@@ -90,12 +164,118 @@ func (q *Queries) GetUserReview(ctx context.Context, arg GetUserReviewParams) (*
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.RatingValue,
+		&i.ProductID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.AdminReply,
+		&i.RepliedAt,
+		&i.ModeratedAt,
 	)
 	return &i, err
 }
 
+const listProductReviews = `-- name: ListProductReviews :many
+SELECT
+ ur.id, ur.product_id, ur.rating_value, ur.title, ur.body, ur.admin_reply, ur.replied_at, ur.created_at, ur.updated_at,
+ u.username,
+ EXISTS (
+    SELECT 1 FROM "shop_order_item" AS soi
+    INNER JOIN "shop_order" AS so ON so.id = soi.order_id
+    WHERE soi.id = ur.ordered_product_id
+    AND so.user_id = ur.user_id
+    AND so.order_status_id = (SELECT id FROM "order_status" WHERE code = 'delivered')
+ ) AS verified_purchase,
+ ARRAY(
+    SELECT img.image_url FROM "user_review_image" AS img
+    WHERE img.review_id = ur.id
+    ORDER BY img.id
+ )::VARCHAR[] AS images,
+ COUNT(*) OVER() AS total_count
+FROM "user_review" AS ur
+INNER JOIN "user" AS u ON u.id = ur.user_id
+WHERE ur.product_id = $3
+AND ur.status = 'approved'
+ORDER BY
+CASE
+    WHEN $4::VARCHAR = 'highest_rating'
+    THEN ur.rating_value END DESC,
+CASE
+    WHEN $4::VARCHAR = 'lowest_rating'
+    THEN ur.rating_value END ASC,
+CASE
+    WHEN $4::VARCHAR = 'oldest'
+    THEN ur.id END ASC,
+ur.id DESC
+LIMIT $1
+OFFSET $2
+`
+
+type ListProductReviewsParams struct {
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+	ProductID int64  `json:"product_id"`
+	Sort      string `json:"sort"`
+}
+
+type ListProductReviewsRow struct {
+	ID               int64       `json:"id"`
+	ProductID        int64       `json:"product_id"`
+	RatingValue      int32       `json:"rating_value"`
+	Title            string      `json:"title"`
+	Body             string      `json:"body"`
+	AdminReply       null.String `json:"admin_reply"`
+	RepliedAt        null.Time   `json:"replied_at"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	Username         string      `json:"username"`
+	VerifiedPurchase bool        `json:"verified_purchase"`
+	Images           []string    `json:"images"`
+	TotalCount       int64       `json:"total_count"`
+}
+
+// the approved reviews of a product, a review is a verified purchase when its ordered product was delivered to the reviewer
+func (q *Queries) ListProductReviews(ctx context.Context, arg ListProductReviewsParams) ([]*ListProductReviewsRow, error) {
+	rows, err := q.db.Query(ctx, listProductReviews,
+		arg.Limit,
+		arg.Offset,
+		arg.ProductID,
+		arg.Sort,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListProductReviewsRow{}
+	for rows.Next() {
+		var i ListProductReviewsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.RatingValue,
+			&i.Title,
+			&i.Body,
+			&i.AdminReply,
+			&i.RepliedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Username,
+			&i.VerifiedPurchase,
+			&i.Images,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUserReviews = `-- name: ListUserReviews :many
-SELECT id, user_id, ordered_product_id, created_at, updated_at, rating_value FROM "user_review"
+SELECT id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at FROM "user_review"
 WHERE user_id = $3
 ORDER BY id
 LIMIT $1
@@ -124,6 +304,13 @@ func (q *Queries) ListUserReviews(ctx context.Context, arg ListUserReviewsParams
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.RatingValue,
+			&i.ProductID,
+			&i.Title,
+			&i.Body,
+			&i.Status,
+			&i.AdminReply,
+			&i.RepliedAt,
+			&i.ModeratedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -135,28 +322,212 @@ func (q *Queries) ListUserReviews(ctx context.Context, arg ListUserReviewsParams
 	return items, nil
 }
 
+const listUserReviewsForExport = `-- name: ListUserReviewsForExport :many
+SELECT id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at FROM "user_review"
+WHERE user_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListUserReviewsForExport(ctx context.Context, userID int64) ([]*UserReview, error) {
+	rows, err := q.db.Query(ctx, listUserReviewsForExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*UserReview{}
+	for rows.Next() {
+		var i UserReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrderedProductID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RatingValue,
+			&i.ProductID,
+			&i.Title,
+			&i.Body,
+			&i.Status,
+			&i.AdminReply,
+			&i.RepliedAt,
+			&i.ModeratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserReviewsForModeration = `-- name: ListUserReviewsForModeration :many
+SELECT
+ ur.id, ur.user_id, ur.ordered_product_id, ur.created_at, ur.updated_at, ur.rating_value, ur.product_id, ur.title, ur.body, ur.status, ur.admin_reply, ur.replied_at, ur.moderated_at, p.name AS product_name, u.username,
+ ARRAY(
+    SELECT img.image_url FROM "user_review_image" AS img
+    WHERE img.review_id = ur.id
+    ORDER BY img.id
+ )::VARCHAR[] AS images,
+ COUNT(*) OVER() AS total_count
+FROM "user_review" AS ur
+INNER JOIN "product" AS p ON p.id = ur.product_id
+INNER JOIN "user" AS u ON u.id = ur.user_id
+WHERE ur.status = $3
+ORDER BY ur.created_at, ur.id
+LIMIT $1
+OFFSET $2
+`
+
+type ListUserReviewsForModerationParams struct {
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	Status string `json:"status"`
+}
+
+type ListUserReviewsForModerationRow struct {
+	ID               int64       `json:"id"`
+	UserID           int64       `json:"user_id"`
+	OrderedProductID int64       `json:"ordered_product_id"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	RatingValue      int32       `json:"rating_value"`
+	ProductID        int64       `json:"product_id"`
+	Title            string      `json:"title"`
+	Body             string      `json:"body"`
+	Status           string      `json:"status"`
+	AdminReply       null.String `json:"admin_reply"`
+	RepliedAt        null.Time   `json:"replied_at"`
+	ModeratedAt      null.Time   `json:"moderated_at"`
+	ProductName      string      `json:"product_name"`
+	Username         string      `json:"username"`
+	Images           []string    `json:"images"`
+	TotalCount       int64       `json:"total_count"`
+}
+
+// the oldest reviews come first so the queue is worked through in order
+func (q *Queries) ListUserReviewsForModeration(ctx context.Context, arg ListUserReviewsForModerationParams) ([]*ListUserReviewsForModerationRow, error) {
+	rows, err := q.db.Query(ctx, listUserReviewsForModeration, arg.Limit, arg.Offset, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListUserReviewsForModerationRow{}
+	for rows.Next() {
+		var i ListUserReviewsForModerationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrderedProductID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RatingValue,
+			&i.ProductID,
+			&i.Title,
+			&i.Body,
+			&i.Status,
+			&i.AdminReply,
+			&i.RepliedAt,
+			&i.ModeratedAt,
+			&i.ProductName,
+			&i.Username,
+			&i.Images,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moderateUserReview = `-- name: ModerateUserReview :one
+UPDATE "user_review"
+SET
+status = COALESCE($1, status),
+moderated_at = CASE
+    WHEN $1 IS NULL THEN moderated_at
+    ELSE now()
+END,
+admin_reply = CASE
+    WHEN $2::VARCHAR IS NULL THEN admin_reply
+    ELSE NULLIF($2::VARCHAR, '')
+END,
+replied_at = CASE
+    WHEN $2::VARCHAR IS NULL THEN replied_at
+    WHEN $2::VARCHAR = '' THEN NULL
+    ELSE now()
+END
+WHERE id = $3
+RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at
+`
+
+type ModerateUserReviewParams struct {
+	Status     null.String `json:"status"`
+	AdminReply null.String `json:"admin_reply"`
+	ID         int64       `json:"id"`
+}
+
+// an empty reply removes the reply of the review
+func (q *Queries) ModerateUserReview(ctx context.Context, arg ModerateUserReviewParams) (*UserReview, error) {
+	row := q.db.QueryRow(ctx, moderateUserReview, arg.Status, arg.AdminReply, arg.ID)
+	var i UserReview
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrderedProductID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RatingValue,
+		&i.ProductID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.AdminReply,
+		&i.RepliedAt,
+		&i.ModeratedAt,
+	)
+	return &i, err
+}
+
 const updateUserReview = `-- name: UpdateUserReview :one
 UPDATE "user_review"
 SET 
 ordered_product_id = COALESCE($1,ordered_product_id),
 rating_value = COALESCE($2,rating_value),
+title = COALESCE($3,title),
+body = COALESCE($4,body),
+status = CASE
+    WHEN status = 'approved' AND ($3 IS NOT NULL OR $4 IS NOT NULL)
+    THEN 'pending'
+    ELSE status
+END,
 updated_at = now()
-WHERE id = $3
-AND user_id = $4
-RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value
+WHERE id = $5
+AND user_id = $6
+RETURNING id, user_id, ordered_product_id, created_at, updated_at, rating_value, product_id, title, body, status, admin_reply, replied_at, moderated_at
 `
 
 type UpdateUserReviewParams struct {
-	OrderedProductID null.Int `json:"ordered_product_id"`
-	RatingValue      null.Int `json:"rating_value"`
-	ID               int64    `json:"id"`
-	UserID           int64    `json:"user_id"`
+	OrderedProductID null.Int    `json:"ordered_product_id"`
+	RatingValue      null.Int    `json:"rating_value"`
+	Title            null.String `json:"title"`
+	Body             null.String `json:"body"`
+	ID               int64       `json:"id"`
+	UserID           int64       `json:"user_id"`
 }
 
+// an approved review goes back to the moderation when its text changes
 func (q *Queries) UpdateUserReview(ctx context.Context, arg UpdateUserReviewParams) (*UserReview, error) {
 	row := q.db.QueryRow(ctx, updateUserReview,
 		arg.OrderedProductID,
 		arg.RatingValue,
+		arg.Title,
+		arg.Body,
 		arg.ID,
 		arg.UserID,
 	)
@@ -168,6 +539,13 @@ func (q *Queries) UpdateUserReview(ctx context.Context, arg UpdateUserReviewPara
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.RatingValue,
+		&i.ProductID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.AdminReply,
+		&i.RepliedAt,
+		&i.ModeratedAt,
 	)
 	return &i, err
 }
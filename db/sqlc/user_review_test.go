@@ -141,3 +141,78 @@ func TestListUserReviews(t *testing.T) {
 	}
 
 }
+
+func TestModerateUserReviewRefreshesProductRating(t *testing.T) {
+	userReview := createRandomUserReview(t)
+	require.Equal(t, "pending", userReview.Status)
+	require.NotZero(t, userReview.ProductID)
+
+	product, err := testStore.GetProduct(context.Background(), userReview.ProductID)
+	require.NoError(t, err)
+	require.Zero(t, product.RatingCount)
+
+	moderated, err := testStore.ModerateUserReview(context.Background(), ModerateUserReviewParams{
+		Status:     null.StringFrom("approved"),
+		AdminReply: null.StringFrom("thank you"),
+		ID:         userReview.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "approved", moderated.Status)
+	require.Equal(t, "thank you", moderated.AdminReply.String)
+	require.True(t, moderated.RepliedAt.Valid)
+	require.True(t, moderated.ModeratedAt.Valid)
+
+	product, err = testStore.GetProduct(context.Background(), userReview.ProductID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), product.RatingCount)
+	require.Equal(t, float64(userReview.RatingValue), product.RatingAverage)
+
+	reviews, err := testStore.ListProductReviews(context.Background(), ListProductReviewsParams{
+		Limit:     10,
+		Offset:    0,
+		ProductID: userReview.ProductID,
+	})
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	require.Equal(t, userReview.ID, reviews[0].ID)
+	require.Empty(t, reviews[0].Images)
+
+	// an empty reply removes it and hiding the review takes it out of the rating
+	_, err = testStore.ModerateUserReview(context.Background(), ModerateUserReviewParams{
+		Status:     null.StringFrom("hidden"),
+		AdminReply: null.StringFrom(""),
+		ID:         userReview.ID,
+	})
+	require.NoError(t, err)
+
+	product, err = testStore.GetProduct(context.Background(), userReview.ProductID)
+	require.NoError(t, err)
+	require.Zero(t, product.RatingCount)
+}
+
+func TestCreateUserReviewImage(t *testing.T) {
+	userReview := createRandomUserReview(t)
+	otherUser := createRandomUser(t)
+
+	// the image can only be added by the reviewer
+	_, err := testStore.CreateUserReviewImage(context.Background(), CreateUserReviewImageParams{
+		ReviewID: userReview.ID,
+		UserID:   otherUser.ID,
+		ImageUrl: util.RandomURL(),
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	arg := CreateUserReviewImageParams{
+		ReviewID: userReview.ID,
+		UserID:   userReview.UserID,
+		ImageUrl: util.RandomURL(),
+	}
+	image, err := testStore.CreateUserReviewImage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ReviewID, image.ReviewID)
+	require.Equal(t, arg.ImageUrl, image.ImageUrl)
+
+	count, err := testStore.CountUserReviewImages(context.Background(), userReview.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
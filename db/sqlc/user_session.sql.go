@@ -21,11 +21,12 @@ INSERT INTO "user_session" (
   user_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked
+RETURNING id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by
 `
 
 type CreateUserSessionParams struct {
@@ -36,6 +37,7 @@ type CreateUserSessionParams struct {
 	ClientIp     string    `json:"client_ip"`
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	FamilyID     uuid.UUID `json:"family_id"`
 }
 
 func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionParams) (*UserSession, error) {
@@ -47,6 +49,7 @@ func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionPa
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.FamilyID,
 	)
 	var i UserSession
 	err := row.Scan(
@@ -59,12 +62,27 @@ func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionPa
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return &i, err
 }
 
+const deleteUserSessions = `-- name: DeleteUserSessions :execrows
+DELETE FROM "user_session"
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserSessions, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getUserSession = `-- name: GetUserSession :one
-SELECT id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked FROM "user_session"
+SELECT id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by FROM "user_session"
 WHERE id = $1 LIMIT 1
 `
 
@@ -81,6 +99,201 @@ func (q *Queries) GetUserSession(ctx context.Context, id uuid.UUID) (*UserSessio
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
+	)
+	return &i, err
+}
+
+const isUserSessionActive = `-- name: IsUserSessionActive :one
+SELECT EXISTS (
+  SELECT 1 FROM "user_session"
+  WHERE id = $1
+  AND user_id = $2
+  AND is_blocked = false
+  AND expires_at > now()
+)
+`
+
+type IsUserSessionActiveParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID int64     `json:"user_id"`
+}
+
+func (q *Queries) IsUserSessionActive(ctx context.Context, arg IsUserSessionActiveParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserSessionActive, arg.ID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listActiveUserSessions = `-- name: ListActiveUserSessions :many
+SELECT id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by FROM "user_session"
+WHERE user_id = $1
+AND is_blocked = false
+AND expires_at > now()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListActiveUserSessions(ctx context.Context, userID int64) ([]*UserSession, error) {
+	rows, err := q.db.Query(ctx, listActiveUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*UserSession{}
+	for rows.Next() {
+		var i UserSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.IsBlocked,
+			&i.FamilyID,
+			&i.ReplacedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserSessionsForExport = `-- name: ListUserSessionsForExport :many
+SELECT id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by FROM "user_session"
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListUserSessionsForExport(ctx context.Context, userID int64) ([]*UserSession, error) {
+	rows, err := q.db.Query(ctx, listUserSessionsForExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*UserSession{}
+	for rows.Next() {
+		var i UserSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.IsBlocked,
+			&i.FamilyID,
+			&i.ReplacedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeOtherUserSessions = `-- name: RevokeOtherUserSessions :execrows
+UPDATE "user_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE user_id = $1
+AND id != $2
+AND is_blocked = false
+`
+
+type RevokeOtherUserSessionsParams struct {
+	UserID           int64     `json:"user_id"`
+	CurrentSessionID uuid.UUID `json:"current_session_id"`
+}
+
+func (q *Queries) RevokeOtherUserSessions(ctx context.Context, arg RevokeOtherUserSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeOtherUserSessions, arg.UserID, arg.CurrentSessionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeUserSessionFamily = `-- name: RevokeUserSessionFamily :execrows
+UPDATE "user_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE family_id = $1
+AND is_blocked = false
+`
+
+func (q *Queries) RevokeUserSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeUserSessionFamily, familyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :execrows
+UPDATE "user_session"
+SET
+is_blocked = true,
+updated_at = now()
+WHERE user_id = $1
+AND is_blocked = false
+`
+
+func (q *Queries) RevokeUserSessions(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeUserSessions, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotateUserSession = `-- name: RotateUserSession :one
+UPDATE "user_session"
+SET
+replaced_by = $1::uuid,
+is_blocked = true,
+updated_at = now()
+WHERE id = $2
+AND replaced_by IS NULL
+AND is_blocked = false
+RETURNING id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by
+`
+
+type RotateUserSessionParams struct {
+	ReplacedBy uuid.UUID `json:"replaced_by"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (q *Queries) RotateUserSession(ctx context.Context, arg RotateUserSessionParams) (*UserSession, error) {
+	row := q.db.QueryRow(ctx, rotateUserSession, arg.ReplacedBy, arg.ID)
+	var i UserSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return &i, err
 }
@@ -93,7 +306,7 @@ updated_at = now()
 WHERE id = $2
 AND user_id = $3
 AND refresh_token = $4
-RETURNING id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked
+RETURNING id, user_id, refresh_token, user_agent, client_ip, created_at, updated_at, expires_at, is_blocked, family_id, replaced_by
 `
 
 type UpdateUserSessionParams struct {
@@ -121,6 +334,8 @@ func (q *Queries) UpdateUserSession(ctx context.Context, arg UpdateUserSessionPa
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.IsBlocked,
+		&i.FamilyID,
+		&i.ReplacedBy,
 	)
 	return &i, err
 }
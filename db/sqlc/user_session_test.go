@@ -23,6 +23,7 @@ func createRandomUserSession(t *testing.T) UserSession {
 		IsBlocked:    false,
 		ExpiresAt:    time.Now().Local().UTC(),
 	}
+	arg.FamilyID = arg.ID
 
 	userSession, err := testStore.CreateUserSession(context.Background(), arg)
 	require.NoError(t, err)
@@ -35,6 +36,8 @@ func createRandomUserSession(t *testing.T) UserSession {
 	require.Equal(t, arg.ClientIp, userSession.ClientIp)
 	require.Equal(t, arg.IsBlocked, userSession.IsBlocked)
 	require.WithinDuration(t, arg.ExpiresAt, userSession.ExpiresAt, time.Second)
+	require.Equal(t, arg.FamilyID, userSession.FamilyID)
+	require.False(t, userSession.ReplacedBy.Valid)
 
 	require.NotEmpty(t, userSession.CreatedAt)
 
@@ -88,3 +91,57 @@ func TestUpdateUserSession(t *testing.T) {
 	require.NotEqual(t, userSession1.UpdatedAt, userSession2.UpdatedAt)
 	require.Equal(t, userSession1.ExpiresAt, userSession2.ExpiresAt)
 }
+
+func TestActiveUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+
+	sessions := make([]*UserSession, 2)
+	for i := range sessions {
+		sessionID := uuid.New()
+		session, err := testStore.CreateUserSession(context.Background(), CreateUserSessionParams{
+			ID:           sessionID,
+			UserID:       user.ID,
+			RefreshToken: util.RandomString(100),
+			UserAgent:    util.RandomString(6),
+			ClientIp:     util.RandomString(10),
+			ExpiresAt:    time.Now().Add(time.Hour).UTC(),
+			FamilyID:     sessionID,
+		})
+		require.NoError(t, err)
+		sessions[i] = session
+	}
+
+	active, err := testStore.IsUserSessionActive(context.Background(), IsUserSessionActiveParams{
+		ID:     sessions[0].ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, active)
+
+	// the session must belong to the user of the token
+	active, err = testStore.IsUserSessionActive(context.Background(), IsUserSessionActiveParams{
+		ID:     sessions[0].ID,
+		UserID: user.ID + 1,
+	})
+	require.NoError(t, err)
+	require.False(t, active)
+
+	activeSessions, err := testStore.ListActiveUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, activeSessions, 2)
+
+	revoked, err := testStore.RevokeUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), revoked)
+
+	active, err = testStore.IsUserSessionActive(context.Background(), IsUserSessionActiveParams{
+		ID:     sessions[1].ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.False(t, active)
+
+	activeSessions, err = testStore.ListActiveUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, activeSessions)
+}
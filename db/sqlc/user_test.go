@@ -86,6 +86,48 @@ func TestCreateUserWithCart(t *testing.T) {
 
 }
 
+func TestCreateVerifiedUserWithCartAndWishList(t *testing.T) {
+	hashedPassword, err := util.HashPassword(util.RandomString(32))
+	require.NoError(t, err)
+
+	arg := CreateVerifiedUserWithCartAndWishListParams{
+		Username: util.RandomUser(),
+		Email:    util.RandomEmail(),
+		Password: hashedPassword,
+	}
+
+	user, err := testStore.CreateVerifiedUserWithCartAndWishList(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, user.Username)
+	require.Equal(t, arg.Email, user.Email)
+	require.True(t, user.IsEmailVerified)
+	require.False(t, user.IsBlocked)
+	require.NotZero(t, user.ShoppingCartID)
+	require.NotZero(t, user.WishListID)
+}
+
+func TestVerifyUserEmailForSocialLogin(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	// the unverified password is replaced
+	verified, err := testStore.VerifyUserEmailForSocialLogin(context.Background(), VerifyUserEmailForSocialLoginParams{
+		Password: "replaced",
+		ID:       user.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, verified.IsEmailVerified)
+	require.Equal(t, "replaced", verified.Password)
+
+	// a verified account keeps its password
+	verified, err = testStore.VerifyUserEmailForSocialLogin(context.Background(), VerifyUserEmailForSocialLoginParams{
+		Password: "ignored",
+		ID:       user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "replaced", verified.Password)
+}
+
 func TestGetUser(t *testing.T) {
 
 	user1 := createRandomUser(t)
@@ -19,7 +19,7 @@ INSERT INTO "verify_email" (
     secret_code
 ) VALUES (
     $1, $2
-) RETURNING id, user_id, secret_code, created_at, expired_at, is_used
+) RETURNING id, user_id, secret_code, created_at, expired_at, is_used, attempts
 `
 
 type CreateVerifyEmailParams struct {
@@ -37,12 +37,23 @@ func (q *Queries) CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailPa
 		&i.CreatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
 
+const deleteVerifyEmailsByUserID = `-- name: DeleteVerifyEmailsByUserID :exec
+DELETE FROM "verify_email"
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteVerifyEmailsByUserID(ctx context.Context, userID null.Int) error {
+	_, err := q.db.Exec(ctx, deleteVerifyEmailsByUserID, userID)
+	return err
+}
+
 const getVerifyEmail = `-- name: GetVerifyEmail :one
-SELECT id, user_id, secret_code, created_at, expired_at, is_used FROM "verify_email"
+SELECT id, user_id, secret_code, created_at, expired_at, is_used, attempts FROM "verify_email"
 WHERE id = $1 LIMIT 1
 `
 
@@ -56,13 +67,14 @@ func (q *Queries) GetVerifyEmail(ctx context.Context, id int64) (*VerifyEmail, e
 		&i.CreatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
 
 const getVerifyEmailByEmail = `-- name: GetVerifyEmailByEmail :one
 SELECT u.email, u.username, u.is_blocked, u.is_email_verified, 
-ve.id, ve.user_id, ve.secret_code, ve.created_at, ve.expired_at, ve.is_used FROM "verify_email" AS ve
+ve.id, ve.user_id, ve.secret_code, ve.created_at, ve.expired_at, ve.is_used, ve.attempts FROM "verify_email" AS ve
 JOIN "user" AS u ON u.id = ve.user_id
 WHERE u.email = $1
 ORDER BY ve.created_at DESC
@@ -80,6 +92,7 @@ type GetVerifyEmailByEmailRow struct {
 	CreatedAt       time.Time `json:"created_at"`
 	ExpiredAt       time.Time `json:"expired_at"`
 	IsUsed          bool      `json:"is_used"`
+	Attempts        int32     `json:"attempts"`
 }
 
 func (q *Queries) GetVerifyEmailByEmail(ctx context.Context, email string) (*GetVerifyEmailByEmailRow, error) {
@@ -96,6 +109,44 @@ func (q *Queries) GetVerifyEmailByEmail(ctx context.Context, email string) (*Get
 		&i.CreatedAt,
 		&i.ExpiredAt,
 		&i.IsUsed,
+		&i.Attempts,
+	)
+	return &i, err
+}
+
+const incrementVerifyEmailAttempts = `-- name: IncrementVerifyEmailAttempts :one
+UPDATE "verify_email"
+SET
+    attempts = attempts + 1,
+    expired_at = CASE WHEN attempts + 1 >= $1::int THEN NOW() ELSE expired_at END
+WHERE id = (
+    SELECT ve.id FROM "verify_email" AS ve
+    JOIN "user" AS u ON u.id = ve.user_id
+    WHERE u.email = $2
+    AND ve.is_used = FALSE
+    AND ve.expired_at > NOW()
+    ORDER BY ve.created_at DESC
+    LIMIT 1
+)
+RETURNING id, user_id, secret_code, created_at, expired_at, is_used, attempts
+`
+
+type IncrementVerifyEmailAttemptsParams struct {
+	MaxAttempts int32  `json:"max_attempts"`
+	Email       string `json:"email"`
+}
+
+func (q *Queries) IncrementVerifyEmailAttempts(ctx context.Context, arg IncrementVerifyEmailAttemptsParams) (*VerifyEmail, error) {
+	row := q.db.QueryRow(ctx, incrementVerifyEmailAttempts, arg.MaxAttempts, arg.Email)
+	var i VerifyEmail
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.SecretCode,
+		&i.CreatedAt,
+		&i.ExpiredAt,
+		&i.IsUsed,
+		&i.Attempts,
 	)
 	return &i, err
 }
@@ -109,10 +160,11 @@ AND u.is_email_verified = FALSE
 t2 AS (
 UPDATE "verify_email" 
 SET is_used = TRUE
-WHERE secret_code = $2
+WHERE id = $2
 AND user_id = (SELECT id FROM t1)
+AND is_used = FALSE
 AND expired_at > NOW()
-RETURNING id, user_id, secret_code, created_at, expired_at, is_used
+RETURNING id, user_id, secret_code, created_at, expired_at, is_used, attempts
 ),
 t3 AS (
 UPDATE "user"
@@ -137,8 +189,8 @@ SELECT t3.id, t3.username, t3.email, t3.is_blocked, t3.is_email_verified, t3.def
 `
 
 type UpdateVerifyEmailParams struct {
-	Email      string `json:"email"`
-	SecretCode string `json:"secret_code"`
+	Email string `json:"email"`
+	ID    int64  `json:"id"`
 }
 
 type UpdateVerifyEmailRow struct {
@@ -155,7 +207,7 @@ type UpdateVerifyEmailRow struct {
 }
 
 func (q *Queries) UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (*UpdateVerifyEmailRow, error) {
-	row := q.db.QueryRow(ctx, updateVerifyEmail, arg.Email, arg.SecretCode)
+	row := q.db.QueryRow(ctx, updateVerifyEmail, arg.Email, arg.ID)
 	var i UpdateVerifyEmailRow
 	err := row.Scan(
 		&i.ID,
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/cshop/v3/util"
 	"github.com/guregu/null/v6"
@@ -74,8 +75,8 @@ func TestUpdateVerifyEmail(t *testing.T) {
 	verifyEmail := createRandomVerifyEmail(t, user1)
 
 	arg := UpdateVerifyEmailParams{
-		Email:      user1.Email,
-		SecretCode: verifyEmail.SecretCode,
+		Email: user1.Email,
+		ID:    verifyEmail.ID,
 	}
 
 	verifyEmail2, err := testStore.UpdateVerifyEmail(context.Background(), arg)
@@ -93,3 +94,28 @@ func TestUpdateVerifyEmail(t *testing.T) {
 	require.NotEqual(t, verifyEmail.IsUsed, verifyEmail3.IsUsed)
 	require.NotEqual(t, user1.IsEmailVerified, verifyEmail2.IsEmailVerified)
 }
+
+func TestIncrementVerifyEmailAttempts(t *testing.T) {
+	user1 := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user1)
+
+	arg := IncrementVerifyEmailAttemptsParams{
+		MaxAttempts: 2,
+		Email:       user1.Email,
+	}
+
+	verifyEmail1, err := testStore.IncrementVerifyEmailAttempts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, verifyEmail.ID, verifyEmail1.ID)
+	require.Equal(t, int32(1), verifyEmail1.Attempts)
+	require.True(t, verifyEmail1.ExpiredAt.After(time.Now()))
+
+	// the last allowed wrong code expires the otp
+	verifyEmail2, err := testStore.IncrementVerifyEmailAttempts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), verifyEmail2.Attempts)
+	require.False(t, verifyEmail2.ExpiredAt.After(time.Now()))
+
+	_, err = testStore.IncrementVerifyEmailAttempts(context.Background(), arg)
+	require.Error(t, err)
+}
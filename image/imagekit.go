@@ -3,6 +3,7 @@ package image
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net/http"
 
 	"github.com/imagekit-developer/imagekit-go/v2"
@@ -13,6 +14,7 @@ import (
 type ImageKitManagement interface {
 	ListAndSearch(ctx context.Context, params imagekit.AssetListParams) (*[]imagekit.AssetListResponseUnion, error)
 	UrlGeneration(ctx context.Context, params shared.SrcOptionsParam) (*string, error)
+	UploadImage(ctx context.Context, file io.Reader, fileName string, folder string) (*string, error)
 }
 
 type ImageKit struct {
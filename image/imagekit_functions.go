@@ -2,6 +2,7 @@ package image
 
 import (
 	"context"
+	"io"
 
 	"github.com/imagekit-developer/imagekit-go/v2"
 	"github.com/imagekit-developer/imagekit-go/v2/shared"
@@ -19,3 +20,16 @@ func (image *ImageKit) UrlGeneration(ctx context.Context, params shared.SrcOptio
 	url := image.ik.Helper.BuildURL(params)
 	return &url, nil
 }
+
+// UploadImage uploads the file to the folder and returns its url, imagekit adds a suffix when the file name is taken
+func (image *ImageKit) UploadImage(ctx context.Context, file io.Reader, fileName string, folder string) (*string, error) {
+	resp, err := image.ik.Files.Upload(ctx, imagekit.FileUploadParams{
+		File:     file,
+		FileName: fileName,
+		Folder:   imagekit.String(folder),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp.URL, nil
+}
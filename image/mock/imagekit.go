@@ -11,6 +11,7 @@ package mockik
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	imagekit "github.com/imagekit-developer/imagekit-go/v2"
@@ -57,6 +58,21 @@ func (mr *MockImageKitManagementMockRecorder) ListAndSearch(ctx, params any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAndSearch", reflect.TypeOf((*MockImageKitManagement)(nil).ListAndSearch), ctx, params)
 }
 
+// UploadImage mocks base method.
+func (m *MockImageKitManagement) UploadImage(ctx context.Context, file io.Reader, fileName, folder string) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadImage", ctx, file, fileName, folder)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadImage indicates an expected call of UploadImage.
+func (mr *MockImageKitManagementMockRecorder) UploadImage(ctx, file, fileName, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadImage", reflect.TypeOf((*MockImageKitManagement)(nil).UploadImage), ctx, file, fileName, folder)
+}
+
 // UrlGeneration mocks base method.
 func (m *MockImageKitManagement) UrlGeneration(ctx context.Context, params shared.SrcOptionsParam) (*string, error) {
 	m.ctrl.T.Helper()
@@ -12,6 +12,7 @@ import (
 	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/image"
 	"github.com/cshop/v3/mail"
+	"github.com/cshop/v3/payment"
 	"github.com/cshop/v3/util"
 	"github.com/cshop/v3/worker"
 	"github.com/hibiken/asynq"
@@ -64,10 +65,16 @@ func main() {
 		log.Fatal("failed to create email sender:", err)
 	}
 
+	payments := payment.NewDefaultProviders(config.FakePaymentWebhookSecret)
+
 	waitGroup, ctx := errgroup.WithContext(ctx)
 
-	// runTaskProcessor(ctx, waitGroup, *config, redisOpt, store)
-	runFiberServer(*config, store, fb, taskDistributor, ik, sender)
+	// the background tasks need redis, without it only the server runs
+	if config.RedisAddress != "" {
+		runTaskProcessor(ctx, waitGroup, *config, redisOpt, store, fb, payments)
+		runTaskScheduler(ctx, waitGroup, redisOpt)
+	}
+	runFiberServer(*config, store, fb, taskDistributor, ik, sender, payments)
 
 	err = waitGroup.Wait()
 	if err != nil {
@@ -82,17 +89,19 @@ func runTaskProcessor(
 	config util.Config,
 	redisOpt asynq.RedisClientOpt,
 	store db.Store,
+	fb *firebase.App,
+	payments payment.Providers,
 ) {
 	mailer, err := mail.NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword)
 	if err != nil {
 		log.Fatal("failed to create email sender:", err)
 	}
-	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, store, mailer, config)
+	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, store, mailer, fb, payments, config)
 
 	// log.Info().Msg("start task processor")
 	err = taskProcessor.Start()
 	if err != nil {
-		// log.Fatal().Err(err).Msg("failed to start task processor")
+		log.Fatal("failed to start task processor:", err)
 	}
 
 	waitGroup.Go(func() error {
@@ -106,6 +115,30 @@ func runTaskProcessor(
 	})
 }
 
+func runTaskScheduler(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	redisOpt asynq.RedisClientOpt,
+) {
+	taskScheduler, err := worker.NewRedisTaskScheduler(redisOpt)
+	if err != nil {
+		log.Fatal("failed to create task scheduler:", err)
+	}
+
+	err = taskScheduler.Start()
+	if err != nil {
+		log.Fatal("failed to start task scheduler:", err)
+	}
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+
+		taskScheduler.Shutdown()
+
+		return nil
+	})
+}
+
 func runFiberServer(
 	// ctx context.Context,
 	// waitGroup *errgroup.Group,
@@ -115,8 +148,9 @@ func runFiberServer(
 	taskDistributor worker.TaskDistributor,
 	ik image.ImageKitManagement,
 	sender mail.EmailSender,
+	payments payment.Providers,
 ) {
-	server, err := api.NewServer(config, store, fb, taskDistributor, ik, sender)
+	server, err := api.NewServer(config, store, fb, taskDistributor, ik, sender, payments)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -0,0 +1,58 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+)
+
+// CashOnDeliveryProvider is used when the customer pays the courier,
+// the order is confirmed on authorize and the cash is captured on delivery
+type CashOnDeliveryProvider struct{}
+
+func NewCashOnDeliveryProvider() PaymentProvider {
+	return &CashOnDeliveryProvider{}
+}
+
+func (provider *CashOnDeliveryProvider) Name() string {
+	return ProviderCOD
+}
+
+func (provider *CashOnDeliveryProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	if _, err := parseAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		ProviderReference: fmt.Sprintf("%s_%d", ProviderCOD, req.TransactionID),
+		Status:            StatusAuthorized,
+		Amount:            req.Amount,
+	}, nil
+}
+
+func (provider *CashOnDeliveryProvider) Capture(ctx context.Context, req CaptureRequest) (*Result, error) {
+	if _, err := parseAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		ProviderReference: req.ProviderReference,
+		Status:            StatusCaptured,
+		Amount:            req.Amount,
+	}, nil
+}
+
+func (provider *CashOnDeliveryProvider) Refund(ctx context.Context, req RefundRequest) (*Result, error) {
+	if _, err := parseAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		ProviderReference: req.ProviderReference,
+		Status:            StatusRefunded,
+		Amount:            req.Amount,
+	}, nil
+}
+
+func (provider *CashOnDeliveryProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	return nil, ErrWebhookNotSupported
+}
@@ -0,0 +1,184 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/quagmt/udecimal"
+)
+
+// FakeProvider is an in-process payment gateway for local runs and tests,
+// payments stay pending until a webhook signed by SignWebhook reports them
+type FakeProvider struct {
+	secret   []byte
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	// refunds holds the answers of the refunds by idempotency key
+	refunds map[string]*Result
+}
+
+type fakePayment struct {
+	amount   udecimal.Decimal
+	refunded udecimal.Decimal
+	status   string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:   []byte(webhookSecret),
+		payments: make(map[string]*fakePayment),
+		refunds:  make(map[string]*Result),
+	}
+}
+
+func (provider *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+func (provider *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	reference, err := newFakeReference()
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.payments[reference] = &fakePayment{
+		amount: amount,
+		status: StatusPending,
+	}
+
+	return &Result{
+		ProviderReference: reference,
+		Status:            StatusPending,
+		Amount:            req.Amount,
+	}, nil
+}
+
+func (provider *FakeProvider) Capture(ctx context.Context, req CaptureRequest) (*Result, error) {
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	payment, ok := provider.payments[req.ProviderReference]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+
+	if payment.status != StatusPending && payment.status != StatusAuthorized {
+		return nil, ErrInvalidPaymentStatus
+	}
+
+	if amount.GreaterThan(payment.amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	payment.amount = amount
+	payment.status = StatusCaptured
+
+	return &Result{
+		ProviderReference: req.ProviderReference,
+		Status:            StatusCaptured,
+		Amount:            req.Amount,
+	}, nil
+}
+
+func (provider *FakeProvider) Refund(ctx context.Context, req RefundRequest) (*Result, error) {
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if refund, ok := provider.refunds[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return refund, nil
+	}
+
+	payment, ok := provider.payments[req.ProviderReference]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+
+	if payment.status != StatusCaptured && payment.status != StatusRefunded {
+		return nil, ErrInvalidPaymentStatus
+	}
+
+	refunded := payment.refunded.Add(amount)
+	if refunded.GreaterThan(payment.amount) {
+		return nil, ErrInvalidAmount
+	}
+
+	payment.refunded = refunded
+	payment.status = StatusRefunded
+
+	refund := &Result{
+		ProviderReference: req.ProviderReference,
+		Status:            StatusRefunded,
+		Amount:            req.Amount,
+	}
+	if req.IdempotencyKey != "" {
+		provider.refunds[req.IdempotencyKey] = refund
+	}
+	return refund, nil
+}
+
+func (provider *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, provider.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	event := &WebhookEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// SignWebhook returns the body and the signature of a webhook for the event,
+// it plays the gateway side when the flow is run offline so the payment takes the event status
+func (provider *FakeProvider) SignWebhook(event WebhookEvent) ([]byte, string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+
+	provider.mu.Lock()
+	if payment, ok := provider.payments[event.ProviderReference]; ok {
+		payment.status = event.Status
+	}
+	provider.mu.Unlock()
+
+	return payload, hex.EncodeToString(provider.sign(payload)), nil
+}
+
+func (provider *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, provider.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func newFakeReference() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return ProviderFake + "_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,118 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/quagmt/udecimal"
+)
+
+// names of the providers a payment_type can point to
+const (
+	ProviderCOD  = "cod"
+	ProviderFake = "fake"
+)
+
+// statuses a provider reports for a payment, they match the payment_transaction statuses
+const (
+	StatusPending    = "pending"
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusFailed     = "failed"
+)
+
+// SignatureHeader carries the signature of the webhook body
+const SignatureHeader = "X-Payment-Signature"
+
+var (
+	ErrProviderNotFound     = errors.New("payment provider not found")
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrInvalidPaymentStatus = errors.New("payment can't be changed in its current status")
+	ErrInvalidAmount        = errors.New("invalid payment amount")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	ErrWebhookNotSupported  = errors.New("payment provider doesn't send webhooks")
+)
+
+// AuthorizeRequest asks the provider to hold the amount of an order
+type AuthorizeRequest struct {
+	TransactionID int64  `json:"transaction_id"`
+	ShopOrderID   int64  `json:"shop_order_id"`
+	Amount        string `json:"amount"`
+}
+
+// CaptureRequest asks the provider to collect an authorized payment
+type CaptureRequest struct {
+	ProviderReference string `json:"provider_reference"`
+	Amount            string `json:"amount"`
+}
+
+// RefundRequest asks the provider to give back some or all of a captured payment,
+// a refund asked again with the same idempotency key is made only once
+type RefundRequest struct {
+	ProviderReference string `json:"provider_reference"`
+	Amount            string `json:"amount"`
+	IdempotencyKey    string `json:"idempotency_key"`
+}
+
+// Result is the answer of the provider to a payment request
+type Result struct {
+	ProviderReference string `json:"provider_reference"`
+	Status            string `json:"status"`
+	Amount            string `json:"amount"`
+}
+
+// WebhookEvent is a payment status change sent by the provider
+type WebhookEvent struct {
+	ProviderReference string `json:"provider_reference"`
+	Status            string `json:"status"`
+	Amount            string `json:"amount"`
+}
+
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, req CaptureRequest) (*Result, error)
+	Refund(ctx context.Context, req RefundRequest) (*Result, error)
+	// VerifyWebhook checks the signature of the webhook body and returns the event it carries
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// Providers holds the payment providers by name
+type Providers map[string]PaymentProvider
+
+func NewProviders(providers ...PaymentProvider) Providers {
+	registry := make(Providers, len(providers))
+	for _, provider := range providers {
+		registry[provider.Name()] = provider
+	}
+	return registry
+}
+
+// NewDefaultProviders returns cash on delivery and, when its webhook secret is set, the fake provider,
+// the server and the task processor share them since the fake provider keeps its payments in memory
+func NewDefaultProviders(fakeWebhookSecret string) Providers {
+	providers := []PaymentProvider{NewCashOnDeliveryProvider()}
+	if fakeWebhookSecret != "" {
+		providers = append(providers, NewFakeProvider(fakeWebhookSecret))
+	}
+	return NewProviders(providers...)
+}
+
+// Get returns the provider with the given name or ErrProviderNotFound
+func (providers Providers) Get(name string) (PaymentProvider, error) {
+	provider, ok := providers[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return provider, nil
+}
+
+// parseAmount returns the amount when it's a positive decimal
+func parseAmount(amount string) (udecimal.Decimal, error) {
+	value, err := udecimal.Parse(amount)
+	if err != nil || !value.GreaterThan(udecimal.Zero) {
+		return udecimal.Zero, ErrInvalidAmount
+	}
+	return value, nil
+}
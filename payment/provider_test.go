@@ -0,0 +1,149 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cshop/v3/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeProvider(t *testing.T) {
+	provider := NewFakeProvider(util.RandomString(32))
+	require.Equal(t, ProviderFake, provider.Name())
+
+	authorization, err := provider.Authorize(context.Background(), AuthorizeRequest{
+		TransactionID: util.RandomMoney(),
+		ShopOrderID:   util.RandomMoney(),
+		Amount:        "120.50",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, authorization.Status)
+	require.NotEmpty(t, authorization.ProviderReference)
+
+	// nothing can be refunded before the capture
+	_, err = provider.Refund(context.Background(), RefundRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "10",
+	})
+	require.ErrorIs(t, err, ErrInvalidPaymentStatus)
+
+	capture, err := provider.Capture(context.Background(), CaptureRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "120.50",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusCaptured, capture.Status)
+
+	refund, err := provider.Refund(context.Background(), RefundRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "100",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusRefunded, refund.Status)
+
+	// the refund asked again with the same key isn't made twice
+	retried, err := provider.Refund(context.Background(), RefundRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "10",
+		IdempotencyKey:    "refund_1_1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusRefunded, retried.Status)
+
+	retried, err = provider.Refund(context.Background(), RefundRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "10",
+		IdempotencyKey:    "refund_1_1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusRefunded, retried.Status)
+
+	// only 10.50 is left to refund
+	_, err = provider.Refund(context.Background(), RefundRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "30",
+	})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = provider.Capture(context.Background(), CaptureRequest{
+		ProviderReference: "fake_unknown",
+		Amount:            "1",
+	})
+	require.ErrorIs(t, err, ErrPaymentNotFound)
+}
+
+func TestFakeProviderInvalidAmount(t *testing.T) {
+	provider := NewFakeProvider(util.RandomString(32))
+
+	for _, amount := range []string{"", "abc", "0", "-5"} {
+		_, err := provider.Authorize(context.Background(), AuthorizeRequest{Amount: amount})
+		require.ErrorIs(t, err, ErrInvalidAmount, amount)
+	}
+}
+
+func TestFakeProviderWebhook(t *testing.T) {
+	provider := NewFakeProvider(util.RandomString(32))
+
+	event := WebhookEvent{
+		ProviderReference: "fake_" + util.RandomString(8),
+		Status:            StatusCaptured,
+		Amount:            "99.99",
+	}
+
+	payload, signature, err := provider.SignWebhook(event)
+	require.NoError(t, err)
+
+	gotEvent, err := provider.VerifyWebhook(payload, signature)
+	require.NoError(t, err)
+	require.Equal(t, event, *gotEvent)
+
+	// the body was changed after it was signed
+	_, err = provider.VerifyWebhook(append(payload, ' '), signature)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// signed with another secret
+	otherProvider := NewFakeProvider(util.RandomString(32))
+	_, otherSignature, err := otherProvider.SignWebhook(event)
+	require.NoError(t, err)
+
+	_, err = provider.VerifyWebhook(payload, otherSignature)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = provider.VerifyWebhook(payload, "not-hex")
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestCashOnDeliveryProvider(t *testing.T) {
+	provider := NewCashOnDeliveryProvider()
+	require.Equal(t, ProviderCOD, provider.Name())
+
+	authorization, err := provider.Authorize(context.Background(), AuthorizeRequest{
+		TransactionID: 7,
+		Amount:        "15",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusAuthorized, authorization.Status)
+	require.Equal(t, "cod_7", authorization.ProviderReference)
+
+	capture, err := provider.Capture(context.Background(), CaptureRequest{
+		ProviderReference: authorization.ProviderReference,
+		Amount:            "15",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusCaptured, capture.Status)
+
+	_, err = provider.VerifyWebhook([]byte(`{}`), "")
+	require.ErrorIs(t, err, ErrWebhookNotSupported)
+}
+
+func TestProviders(t *testing.T) {
+	providers := NewProviders(NewCashOnDeliveryProvider(), NewFakeProvider(util.RandomString(32)))
+
+	provider, err := providers.Get(ProviderCOD)
+	require.NoError(t, err)
+	require.Equal(t, ProviderCOD, provider.Name())
+
+	_, err = providers.Get("unknown")
+	require.ErrorIs(t, err, ErrProviderNotFound)
+}
@@ -0,0 +1,100 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/guregu/null/v6"
+)
+
+// AuthorizeTransaction asks the provider of the payment to authorize it and records the answer,
+// a payment the provider declined is recorded as failed and its order is cancelled. when the
+// provider can't be reached the payment stays pending and the error is returned so it's retried.
+func (providers Providers) AuthorizeTransaction(ctx context.Context, store db.Store, paymentTransaction *db.PaymentTransaction) (*db.UpdatePaymentTransactionTxResult, error) {
+	provider, err := providers.Get(paymentTransaction.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, err := provider.Authorize(ctx, AuthorizeRequest{
+		TransactionID: paymentTransaction.ID,
+		ShopOrderID:   paymentTransaction.ShopOrderID,
+		Amount:        paymentTransaction.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store.UpdatePaymentTransactionTx(ctx, db.UpdatePaymentTransactionTxParams{
+		ID:                paymentTransaction.ID,
+		Status:            authorization.Status,
+		ProviderReference: null.StringFrom(authorization.ProviderReference),
+	})
+}
+
+// CaptureTransaction asks the provider of the authorized payment to collect it and records the answer
+func (providers Providers) CaptureTransaction(ctx context.Context, store db.Store, paymentTransaction *db.PaymentTransaction) (*db.UpdatePaymentTransactionTxResult, error) {
+	provider, err := providers.Get(paymentTransaction.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	capture, err := provider.Capture(ctx, CaptureRequest{
+		ProviderReference: paymentTransaction.ProviderReference.String,
+		Amount:            paymentTransaction.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store.UpdatePaymentTransactionTx(ctx, db.UpdatePaymentTransactionTxParams{
+		ID:     paymentTransaction.ID,
+		Status: capture.Status,
+	})
+}
+
+/*
+RefundTransaction asks the provider of the payment to give back its pending refund and records the answer,
+
+the refund is put in flight and committed before the provider is called, then it's recorded in a second
+transaction. when the provider fails or the answer can't be recorded the refund stays in flight and is
+asked again with the same idempotency key, so the provider doesn't give it back twice.
+*/
+func (providers Providers) RefundTransaction(ctx context.Context, store db.Store, paymentTransactionID int64) (*db.CompleteRefundPaymentTransactionTxResult, error) {
+	started, err := store.StartRefundPaymentTransactionTx(ctx, db.StartRefundPaymentTransactionTxParams{
+		ID: paymentTransactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if started.Amount == "" {
+		return &db.CompleteRefundPaymentTransactionTxResult{PaymentTransaction: started.PaymentTransaction}, nil
+	}
+
+	paymentTransaction := started.PaymentTransaction
+	provider, err := providers.Get(paymentTransaction.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = provider.Refund(ctx, RefundRequest{
+		ProviderReference: paymentTransaction.ProviderReference.String,
+		Amount:            started.Amount,
+		IdempotencyKey:    refundIdempotencyKey(paymentTransaction),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store.CompleteRefundPaymentTransactionTx(ctx, db.CompleteRefundPaymentTransactionTxParams{
+		ID:             paymentTransaction.ID,
+		RefundSequence: paymentTransaction.RefundSequence,
+	})
+}
+
+// refundIdempotencyKey identifies the refund in flight of the payment at its provider
+func refundIdempotencyKey(paymentTransaction *db.PaymentTransaction) string {
+	return fmt.Sprintf("refund_%d_%d", paymentTransaction.ID, paymentTransaction.RefundSequence)
+}
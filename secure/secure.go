@@ -0,0 +1,76 @@
+package secure
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	OTPLength          = 6
+	TrackNumberLength  = 10
+	RecoveryCodeLength = 10
+
+	otpCharset         = "0123456789"
+	trackNumberCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	secretCharset      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// no 0, 1, i, l or o so the codes can be read back from paper
+	recoveryCodeCharset = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// String returns n characters picked uniformly from the charset with crypto/rand,
+// the charset must have between 1 and 256 characters
+func String(n int, charset string) string {
+	if len(charset) == 0 || len(charset) > 256 {
+		panic("secure: charset must have between 1 and 256 characters")
+	}
+
+	// bytes at or above limit are dropped so every character has the same chance
+	limit := 256 - 256%len(charset)
+
+	out := make([]byte, 0, n)
+	buf := make([]byte, n+n/4+1)
+	for len(out) < n {
+		// crypto/rand.Read never returns an error, it crashes the program when the os can't provide randomness
+		rand.Read(buf)
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			out = append(out, charset[int(b)%len(charset)])
+			if len(out) == n {
+				break
+			}
+		}
+	}
+	return string(out)
+}
+
+// OTP returns a 6 digits one time password
+func OTP() string {
+	return String(OTPLength, otpCharset)
+}
+
+// TrackNumber returns a shop order track number of upper case letters and digits
+func TrackNumber() string {
+	return String(TrackNumberLength, trackNumberCharset)
+}
+
+// Secret returns a random token of n letters and digits, for links sent by email
+func Secret(n int) string {
+	return String(n, secretCharset)
+}
+
+// RecoveryCode returns a one-time code an admin keeps to login without the authenticator app,
+// it is split in two halves by a dash to be easier to copy
+func RecoveryCode() string {
+	code := String(RecoveryCodeLength, recoveryCodeCharset)
+	return code[:RecoveryCodeLength/2] + "-" + code[RecoveryCodeLength/2:]
+}
+
+// Hash returns the hex sha256 of a random token so only the hash has to be stored,
+// it is only fit for tokens of high entropy, passwords and otps go through util.HashPassword
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
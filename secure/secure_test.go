@@ -0,0 +1,68 @@
+package secure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTP(t *testing.T) {
+	otp := OTP()
+	require.Len(t, otp, OTPLength)
+	for _, c := range otp {
+		require.Contains(t, otpCharset, string(c))
+	}
+}
+
+func TestTrackNumber(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		trackNumber := TrackNumber()
+		require.Len(t, trackNumber, TrackNumberLength)
+		require.Equal(t, strings.ToUpper(trackNumber), trackNumber)
+		require.False(t, seen[trackNumber])
+		seen[trackNumber] = true
+	}
+}
+
+func TestSecret(t *testing.T) {
+	secret := Secret(32)
+	require.Len(t, secret, 32)
+	require.NotEqual(t, secret, Secret(32))
+}
+
+func TestStringDistribution(t *testing.T) {
+	// every digit should come out about a tenth of the time
+	counts := make(map[byte]int)
+	s := String(100000, otpCharset)
+	for i := range len(s) {
+		counts[s[i]]++
+	}
+
+	require.Len(t, counts, len(otpCharset))
+	for _, count := range counts {
+		require.InDelta(t, 10000, count, 1000)
+	}
+}
+
+func TestStringInvalidCharset(t *testing.T) {
+	require.Panics(t, func() { String(6, "") })
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code := RecoveryCode()
+	require.Len(t, code, RecoveryCodeLength+1)
+	require.Equal(t, "-", code[RecoveryCodeLength/2:RecoveryCodeLength/2+1])
+	for _, c := range strings.ReplaceAll(code, "-", "") {
+		require.Contains(t, recoveryCodeCharset, string(c))
+	}
+	require.NotEqual(t, code, RecoveryCode())
+}
+
+func TestHash(t *testing.T) {
+	token := Secret(32)
+	require.Len(t, Hash(token), 64)
+	require.Equal(t, Hash(token), Hash(token))
+	require.NotEqual(t, Hash(token), Hash(Secret(32)))
+}
@@ -0,0 +1,85 @@
+package throttle
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limiter counts the failed attempts of a key, an account or a client ip, and tells how long it has to wait
+type Limiter interface {
+	// Wait returns how long the key is blocked for, zero when it may try again
+	Wait(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt of the key and returns how long it's blocked for after it
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failed attempts of the key after a successful one
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy decides how long a key is blocked after a number of failed attempts
+type Policy struct {
+	// FreeAttempts can fail before any delay is enforced
+	FreeAttempts int64
+	// BaseDelay is the wait after the first failure past FreeAttempts, it doubles with every failure after it
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff
+	MaxDelay time.Duration
+	// LockoutAttempts failures lock the key for LockoutDuration
+	LockoutAttempts int64
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+var (
+	// AccountPolicy guards a single account against password and otp guessing
+	AccountPolicy = Policy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAttempts: 10,
+		LockoutDuration: 15 * time.Minute,
+		Window:          15 * time.Minute,
+	}
+
+	// IPPolicy guards against a client spreading its guesses over many accounts,
+	// it's looser than AccountPolicy since many users can share an ip
+	IPPolicy = Policy{
+		FreeAttempts:    20,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAttempts: 100,
+		LockoutDuration: 30 * time.Minute,
+		Window:          time.Hour,
+	}
+)
+
+// Delay returns how long a key is blocked for after its failures-th failed attempt
+func (p Policy) Delay(failures int64) time.Duration {
+	if p.LockoutAttempts > 0 && failures >= p.LockoutAttempts {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// New returns a limiter backed by redis that falls back to memory when redis can't be reached,
+// or a memory limiter only when there's no redis client
+func New(client redis.UniversalClient, prefix string, policy Policy) Limiter {
+	memory := NewMemoryLimiter(policy)
+	if client == nil {
+		return memory
+	}
+	return NewRedisLimiter(client, prefix, policy, memory)
+}
@@ -0,0 +1,97 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	failures     int64
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// MemoryLimiter keeps the failed attempts in the process, they are lost on restart and not shared between replicas
+type MemoryLimiter struct {
+	policy    Policy
+	now       func() time.Time
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter(policy Policy) *MemoryLimiter {
+	return &MemoryLimiter{
+		policy:  policy,
+		now:     time.Now,
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// entry returns the live entry of the key, forgetting it once its window has passed
+func (limiter *MemoryLimiter) entry(key string, now time.Time) *memoryEntry {
+	entry, ok := limiter.entries[key]
+	if !ok {
+		return nil
+	}
+	if now.Sub(entry.lastFailure) > limiter.policy.Window && !now.Before(entry.blockedUntil) {
+		delete(limiter.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// sweep drops the forgotten entries so keys that never come back don't pile up
+func (limiter *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(limiter.lastSweep) < limiter.policy.Window {
+		return
+	}
+	limiter.lastSweep = now
+	for key := range limiter.entries {
+		limiter.entry(key, now)
+	}
+}
+
+func (limiter *MemoryLimiter) Wait(ctx context.Context, key string) (time.Duration, error) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	entry := limiter.entry(key, now)
+	if entry == nil || !now.Before(entry.blockedUntil) {
+		return 0, nil
+	}
+	return entry.blockedUntil.Sub(now), nil
+}
+
+func (limiter *MemoryLimiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	entry := limiter.entry(key, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+		limiter.entries[key] = entry
+	}
+
+	entry.failures++
+	entry.lastFailure = now
+
+	delay := limiter.policy.Delay(entry.failures)
+	if blockedUntil := now.Add(delay); blockedUntil.After(entry.blockedUntil) {
+		entry.blockedUntil = blockedUntil
+	}
+	return max(entry.blockedUntil.Sub(now), 0), nil
+}
+
+func (limiter *MemoryLimiter) Reset(ctx context.Context, key string) error {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	delete(limiter.entries, key)
+	return nil
+}
@@ -0,0 +1,112 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{
+	FreeAttempts:    2,
+	BaseDelay:       time.Second,
+	MaxDelay:        10 * time.Second,
+	LockoutAttempts: 8,
+	LockoutDuration: time.Hour,
+	Window:          15 * time.Minute,
+}
+
+func TestPolicyDelay(t *testing.T) {
+	testCases := []struct {
+		failures int64
+		delay    time.Duration
+	}{
+		{failures: 1, delay: 0},
+		{failures: 2, delay: 0},
+		{failures: 3, delay: time.Second},
+		{failures: 4, delay: 2 * time.Second},
+		{failures: 5, delay: 4 * time.Second},
+		{failures: 6, delay: 8 * time.Second},
+		{failures: 7, delay: 10 * time.Second},
+		{failures: 8, delay: time.Hour},
+		{failures: 20, delay: time.Hour},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.delay, testPolicy.Delay(tc.failures), "failures: %d", tc.failures)
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter := NewMemoryLimiter(testPolicy)
+	limiter.now = func() time.Time { return now }
+
+	for range testPolicy.FreeAttempts {
+		delay, err := limiter.Fail(ctx, "key")
+		require.NoError(t, err)
+		require.Zero(t, delay)
+	}
+
+	wait, err := limiter.Wait(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	delay, err := limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, time.Second, delay)
+
+	wait, err = limiter.Wait(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, time.Second, wait)
+
+	// other keys aren't affected
+	wait, err = limiter.Wait(ctx, "other")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	now = now.Add(time.Second)
+	wait, err = limiter.Wait(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	require.NoError(t, limiter.Reset(ctx, "key"))
+	delay, err = limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, delay)
+}
+
+func TestMemoryLimiterLockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter := NewMemoryLimiter(testPolicy)
+	limiter.now = func() time.Time { return now }
+
+	var delay time.Duration
+	var err error
+	for range testPolicy.LockoutAttempts {
+		delay, err = limiter.Fail(ctx, "key")
+		require.NoError(t, err)
+	}
+	require.Equal(t, testPolicy.LockoutDuration, delay)
+
+	// the lockout outlives the window of the failures
+	now = now.Add(testPolicy.Window + time.Minute)
+	wait, err := limiter.Wait(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, testPolicy.LockoutDuration-testPolicy.Window-time.Minute, wait)
+
+	now = now.Add(testPolicy.LockoutDuration)
+	wait, err = limiter.Wait(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	// the failures were forgotten with the window
+	delay, err = limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	require.Zero(t, delay)
+}
@@ -0,0 +1,81 @@
+package throttle
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// RedisLimiter shares the failed attempts between every replica of the api,
+// when redis can't be reached it keeps counting in the fallback so logins aren't left unguarded
+type RedisLimiter struct {
+	client   redis.UniversalClient
+	prefix   string
+	policy   Policy
+	fallback Limiter
+}
+
+func NewRedisLimiter(client redis.UniversalClient, prefix string, policy Policy, fallback Limiter) *RedisLimiter {
+	return &RedisLimiter{
+		client:   client,
+		prefix:   prefix,
+		policy:   policy,
+		fallback: fallback,
+	}
+}
+
+func (limiter *RedisLimiter) failuresKey(key string) string {
+	return limiter.prefix + ":failures:" + key
+}
+
+func (limiter *RedisLimiter) blockKey(key string) string {
+	return limiter.prefix + ":block:" + key
+}
+
+func (limiter *RedisLimiter) logFallback(err error, key string) {
+	log.Warn().Err(err).Str("prefix", limiter.prefix).Str("key", key).
+		Msg("redis unreachable, counting failed attempts in memory")
+}
+
+func (limiter *RedisLimiter) Wait(ctx context.Context, key string) (time.Duration, error) {
+	wait, err := limiter.client.PTTL(ctx, limiter.blockKey(key)).Result()
+	if err != nil {
+		limiter.logFallback(err, key)
+		return limiter.fallback.Wait(ctx, key)
+	}
+
+	// the ttl is negative when the key doesn't exist
+	return max(wait, 0), nil
+}
+
+func (limiter *RedisLimiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	pipe := limiter.client.TxPipeline()
+	failures := pipe.Incr(ctx, limiter.failuresKey(key))
+	pipe.PExpire(ctx, limiter.failuresKey(key), limiter.policy.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		limiter.logFallback(err, key)
+		return limiter.fallback.Fail(ctx, key)
+	}
+
+	delay := limiter.policy.Delay(failures.Val())
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	err := limiter.client.Set(ctx, limiter.blockKey(key), failures.Val(), delay).Err()
+	if err != nil {
+		limiter.logFallback(err, key)
+		return limiter.fallback.Fail(ctx, key)
+	}
+	return delay, nil
+}
+
+func (limiter *RedisLimiter) Reset(ctx context.Context, key string) error {
+	err := limiter.client.Del(ctx, limiter.failuresKey(key), limiter.blockKey(key)).Err()
+	if err != nil {
+		limiter.logFallback(err, key)
+	}
+	return limiter.fallback.Reset(ctx, key)
+}
@@ -0,0 +1,199 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+var (
+	ErrNoSigningKey   = errors.New("keyring has no secret key to sign tokens")
+	ErrUnknownKey     = errors.New("key id is not in the keyring")
+	ErrCurrentKey     = errors.New("the current signing key can't be retired")
+	ErrInvalidKeyID   = errors.New("key id must be non-empty and can't contain ',' or ':'")
+	ErrDuplicateKeyID = errors.New("key id is already in the keyring")
+)
+
+// PublicKey is a verification key of a keyring, published so other services can check the tokens
+type PublicKey struct {
+	ID string `json:"kid"`
+	// Key is the hex ed25519 public key
+	Key     string `json:"public_key"`
+	Current bool   `json:"current"`
+}
+
+/*
+Keyring holds the v4.public keys of one kind of token.
+
+Tokens are signed with the current key and carry its id in their footer,
+every key still in the ring verifies them, so a new key can become current
+without logging anyone out and the old one is retired once its tokens expired.
+*/
+type Keyring struct {
+	mu         sync.RWMutex
+	currentID  string
+	secretKeys map[string]paseto.V4AsymmetricSecretKey
+	publicKeys map[string]paseto.V4AsymmetricPublicKey
+	// order keeps the keys in the order they were added for PublicKeys
+	order []string
+}
+
+// NewKeyring returns an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{
+		secretKeys: make(map[string]paseto.V4AsymmetricSecretKey),
+		publicKeys: make(map[string]paseto.V4AsymmetricPublicKey),
+	}
+}
+
+/*
+ParseKeyring reads a keyring from a comma separated list of id:hex-secret-key pairs,
+
+the first key is the current one, like "2026-10:<hex>,2026-04:<hex>".
+A secret key can be generated with paseto.NewV4AsymmetricSecretKey().ExportHex().
+*/
+func ParseKeyring(keys string) (*Keyring, error) {
+	keyring := NewKeyring()
+
+	for i, entry := range strings.Split(keys, ",") {
+		id, hexKey, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("key %d must be written as id:hex-secret-key", i+1)
+		}
+
+		if err := keyring.AddSecretKey(id, hexKey); err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+
+	return keyring, nil
+}
+
+func validKeyID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, ",:")
+}
+
+func (keyring *Keyring) add(id string, publicKey paseto.V4AsymmetricPublicKey) error {
+	if !validKeyID(id) {
+		return ErrInvalidKeyID
+	}
+	if _, ok := keyring.publicKeys[id]; ok {
+		return ErrDuplicateKeyID
+	}
+
+	keyring.publicKeys[id] = publicKey
+	keyring.order = append(keyring.order, id)
+	return nil
+}
+
+// AddSecretKey adds a key that can sign and verify tokens, the first one added becomes the current key
+func (keyring *Keyring) AddSecretKey(id string, hexKey string) error {
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(hexKey)
+	if err != nil {
+		return err
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if err := keyring.add(id, secretKey.Public()); err != nil {
+		return err
+	}
+
+	keyring.secretKeys[id] = secretKey
+	if keyring.currentID == "" {
+		keyring.currentID = id
+	}
+	return nil
+}
+
+// AddPublicKey adds a key that only verifies tokens, a keyring of public keys can't sign
+func (keyring *Keyring) AddPublicKey(id string, hexKey string) error {
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromHex(hexKey)
+	if err != nil {
+		return err
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	return keyring.add(id, publicKey)
+}
+
+// Rotate adds a new secret key and signs the next tokens with it, the previous keys keep verifying
+func (keyring *Keyring) Rotate(id string, hexKey string) error {
+	if err := keyring.AddSecretKey(id, hexKey); err != nil {
+		return err
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	keyring.currentID = id
+	return nil
+}
+
+// Retire removes a key, the tokens it signed are rejected from now on
+func (keyring *Keyring) Retire(id string) error {
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if _, ok := keyring.publicKeys[id]; !ok {
+		return ErrUnknownKey
+	}
+	if id == keyring.currentID {
+		return ErrCurrentKey
+	}
+
+	delete(keyring.publicKeys, id)
+	delete(keyring.secretKeys, id)
+	keyring.order = slices.DeleteFunc(keyring.order, func(kid string) bool { return kid == id })
+	return nil
+}
+
+// CurrentID returns the id of the key signing the new tokens, empty for a keyring of public keys
+func (keyring *Keyring) CurrentID() string {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	return keyring.currentID
+}
+
+// PublicKeys returns every key that still verifies tokens
+func (keyring *Keyring) PublicKeys() []PublicKey {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	keys := make([]PublicKey, 0, len(keyring.order))
+	for _, id := range keyring.order {
+		keys = append(keys, PublicKey{
+			ID:      id,
+			Key:     keyring.publicKeys[id].ExportHex(),
+			Current: id == keyring.currentID,
+		})
+	}
+	return keys
+}
+
+func (keyring *Keyring) signingKey() (string, paseto.V4AsymmetricSecretKey, error) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	secretKey, ok := keyring.secretKeys[keyring.currentID]
+	if !ok {
+		return "", paseto.V4AsymmetricSecretKey{}, ErrNoSigningKey
+	}
+	return keyring.currentID, secretKey, nil
+}
+
+func (keyring *Keyring) publicKey(id string) (paseto.V4AsymmetricPublicKey, bool) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	publicKey, ok := keyring.publicKeys[id]
+	return publicKey, ok
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/cshop/v3/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,12 +15,13 @@ func TestPasetoMakerForUser(t *testing.T) {
 
 	userID := util.RandomMoney()
 	username := util.RandomUser()
+	sessionID := uuid.New()
 	duration := time.Hour
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateTokenForUser(userID, username, duration)
+	token, payload, err := maker.CreateTokenForUser(userID, username, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	require.NotEmpty(t, token)
@@ -29,6 +31,7 @@ func TestPasetoMakerForUser(t *testing.T) {
 	require.NotEmpty(t, token)
 
 	require.NotZero(t, payload.ID)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.Equal(t, username, payload.Username)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
@@ -38,7 +41,7 @@ func TestExpiredPasetoTokenForUser(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), -time.Minute)
+	token, payload, err := maker.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	require.NotEmpty(t, token)
@@ -57,12 +60,13 @@ func TestPasetoMakerForAdmin(t *testing.T) {
 	username := util.RandomUser()
 	typeID := util.RandomMoney()
 	active := util.RandomBool()
+	sessionID := uuid.New()
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateTokenForAdmin(adminID, username, typeID, active, duration)
+	token, payload, err := maker.CreateTokenForAdmin(adminID, username, typeID, active, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	require.NotEmpty(t, token)
@@ -75,6 +79,7 @@ func TestPasetoMakerForAdmin(t *testing.T) {
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, typeID, payload.TypeID)
 	require.Equal(t, active, payload.Active)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -83,7 +88,7 @@ func TestExpiredPasetoTokenForAdmin(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateTokenForAdmin(util.RandomMoney(), util.RandomUser(), util.RandomMoney(), util.RandomBool(), -time.Minute)
+	token, payload, err := maker.CreateTokenForAdmin(util.RandomMoney(), util.RandomUser(), util.RandomMoney(), util.RandomBool(), uuid.New(), -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	require.NotEmpty(t, token)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/chacha20poly1305"
 )
 
@@ -40,9 +41,9 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 }
 
 // CreateToken creates a new token for specific username and duration
-func (maker *PasetoMaker) CreateTokenForUser(userID int64, username string, duration time.Duration) (string, *UserPayload, error) {
+func (maker *PasetoMaker) CreateTokenForUser(userID int64, username string, sessionID uuid.UUID, duration time.Duration) (string, *UserPayload, error) {
 
-	payload, err := NewPayloadForUser(userID, username, duration)
+	payload, err := NewPayloadForUser(userID, username, sessionID, duration)
 	if err != nil {
 		return "", payload, err
 	}
@@ -78,8 +79,8 @@ func (maker *PasetoMaker) VerifyTokenForUser(signedToken string) (*UserPayload,
 }
 
 // CreateToken creates a new admin token for specific admin and duration
-func (maker *PasetoMaker) CreateTokenForAdmin(adminID int64, username string, type_id int64, active bool, duration time.Duration) (string, *AdminPayload, error) {
-	payload, err := NewPayloadForAdmin(adminID, username, type_id, active, duration)
+func (maker *PasetoMaker) CreateTokenForAdmin(adminID int64, username string, type_id int64, active bool, sessionID uuid.UUID, duration time.Duration) (string, *AdminPayload, error) {
+	payload, err := NewPayloadForAdmin(adminID, username, type_id, active, sessionID, duration)
 	if err != nil || payload == nil {
 		return "", payload, err
 	}
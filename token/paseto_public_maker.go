@@ -0,0 +1,146 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+)
+
+// the implicit assertions bind a token to its kind, a user token is never accepted as an admin one
+// even when both keyrings share a key
+var (
+	userImplicit  = []byte("cshop:user")
+	adminImplicit = []byte("cshop:admin")
+)
+
+// footer is the unencrypted part of the token, it tells which key of the keyring verifies it
+type footer struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicMaker is a v4.public PASETO token maker backed by a keyring
+type PasetoPublicMaker struct {
+	keyring *Keyring
+	parser  paseto.Parser
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker, with a keyring of public keys it can only verify tokens
+func NewPasetoPublicMaker(keyring *Keyring) (*PasetoPublicMaker, error) {
+	if keyring == nil || len(keyring.PublicKeys()) == 0 {
+		return nil, errors.New("keyring must hold at least one key")
+	}
+
+	return &PasetoPublicMaker{
+		keyring: keyring,
+		parser:  paseto.NewParserWithoutExpiryCheck(),
+	}, nil
+}
+
+// Keyring returns the keys of the maker, to publish its public keys or rotate them
+func (maker *PasetoPublicMaker) Keyring() *Keyring {
+	return maker.keyring
+}
+
+func (maker *PasetoPublicMaker) sign(payload any, implicit []byte) (string, error) {
+	keyID, secretKey, err := maker.keyring.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	footerData, err := json.Marshal(footer{KeyID: keyID})
+	if err != nil {
+		return "", err
+	}
+
+	token := paseto.NewToken()
+	if err := token.Set("payload", payload); err != nil {
+		return "", err
+	}
+	token.SetFooter(footerData)
+
+	return token.V4Sign(secretKey, implicit), nil
+}
+
+// verify finds the key of the token from its footer before checking the signature,
+// a token of a retired or unknown key is invalid
+func (maker *PasetoPublicMaker) verify(signedToken string, implicit []byte, payload any) error {
+	footerData, err := maker.parser.UnsafeParseFooter(paseto.V4Public, signedToken)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	var tokenFooter footer
+	if err := json.Unmarshal(footerData, &tokenFooter); err != nil {
+		return ErrInvalidToken
+	}
+
+	publicKey, ok := maker.keyring.publicKey(tokenFooter.KeyID)
+	if !ok {
+		return ErrInvalidToken
+	}
+
+	token, err := maker.parser.ParseV4Public(publicKey, signedToken, implicit)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := token.Get("payload", payload); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// CreateTokenForUser creates a new user token signed by the current key
+func (maker *PasetoPublicMaker) CreateTokenForUser(userID int64, username string, sessionID uuid.UUID, duration time.Duration) (string, *UserPayload, error) {
+	payload, err := NewPayloadForUser(userID, username, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.sign(payload, userImplicit)
+	return token, payload, err
+}
+
+// VerifyTokenForUser checks if the user token is valid or not
+func (maker *PasetoPublicMaker) VerifyTokenForUser(signedToken string) (*UserPayload, error) {
+	userPayload := &UserPayload{}
+
+	if err := maker.verify(signedToken, userImplicit, userPayload); err != nil {
+		return nil, err
+	}
+
+	if err := userPayload.ValidUser(); err != nil {
+		return nil, err
+	}
+
+	return userPayload, nil
+}
+
+// CreateTokenForAdmin creates a new admin token signed by the current key
+func (maker *PasetoPublicMaker) CreateTokenForAdmin(adminID int64, username string, type_id int64, active bool, sessionID uuid.UUID, duration time.Duration) (string, *AdminPayload, error) {
+	payload, err := NewPayloadForAdmin(adminID, username, type_id, active, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.sign(payload, adminImplicit)
+	return token, payload, err
+}
+
+// VerifyTokenForAdmin checks if the admin token is valid or not
+func (maker *PasetoPublicMaker) VerifyTokenForAdmin(signedToken string) (*AdminPayload, error) {
+	adminPayload := &AdminPayload{}
+
+	if err := maker.verify(signedToken, adminImplicit, adminPayload); err != nil {
+		return nil, err
+	}
+
+	if err := adminPayload.ValidAdmin(); err != nil {
+		return nil, err
+	}
+
+	return adminPayload, nil
+}
@@ -0,0 +1,187 @@
+package token
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/cshop/v3/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSecretKeyHex() string {
+	return paseto.NewV4AsymmetricSecretKey().ExportHex()
+}
+
+func randomPublicMaker(t *testing.T) *PasetoPublicMaker {
+	keyring, err := ParseKeyring(fmt.Sprintf("k1:%s", randomSecretKeyHex()))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+	return maker
+}
+
+func TestPasetoPublicMakerForUser(t *testing.T) {
+	maker := randomPublicMaker(t)
+
+	userID := util.RandomMoney()
+	username := util.RandomUser()
+	sessionID := uuid.New()
+	duration := time.Hour
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateTokenForUser(userID, username, sessionID, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+	require.NotEmpty(t, token)
+
+	footer, err := paseto.NewParser().UnsafeParseFooter(paseto.V4Public, token)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kid":"k1"}`, string(footer))
+
+	payload, err = maker.VerifyTokenForUser(token)
+	require.NoError(t, err)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, username, payload.Username)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestPasetoPublicMakerForAdmin(t *testing.T) {
+	maker := randomPublicMaker(t)
+
+	adminID := util.RandomMoney()
+	username := util.RandomUser()
+	typeID := util.RandomMoney()
+	sessionID := uuid.New()
+
+	token, _, err := maker.CreateTokenForAdmin(adminID, username, typeID, true, sessionID, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyTokenForAdmin(token)
+	require.NoError(t, err)
+	require.Equal(t, adminID, payload.AdminID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, typeID, payload.TypeID)
+	require.Equal(t, sessionID, payload.SessionID)
+
+	// an admin token isn't a user token even when they are signed by the same key
+	userPayload, err := maker.VerifyTokenForUser(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, userPayload)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker := randomPublicMaker(t)
+
+	token, _, err := maker.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyTokenForUser(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicMakerKeyRotation(t *testing.T) {
+	maker := randomPublicMaker(t)
+	keyring := maker.Keyring()
+
+	oldToken, _, err := maker.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, keyring.Rotate("k2", randomSecretKeyHex()))
+	require.Equal(t, "k2", keyring.CurrentID())
+
+	newToken, _, err := maker.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	footer, err := paseto.NewParser().UnsafeParseFooter(paseto.V4Public, newToken)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kid":"k2"}`, string(footer))
+
+	// the tokens of the previous key stay valid until it's retired
+	_, err = maker.VerifyTokenForUser(oldToken)
+	require.NoError(t, err)
+	_, err = maker.VerifyTokenForUser(newToken)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, keyring.Retire("k2"), ErrCurrentKey)
+	require.ErrorIs(t, keyring.Retire("k3"), ErrUnknownKey)
+	require.NoError(t, keyring.Retire("k1"))
+
+	_, err = maker.VerifyTokenForUser(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = maker.VerifyTokenForUser(newToken)
+	require.NoError(t, err)
+
+	keys := keyring.PublicKeys()
+	require.Len(t, keys, 1)
+	require.Equal(t, "k2", keys[0].ID)
+	require.True(t, keys[0].Current)
+}
+
+func TestPasetoPublicVerifierWithPublicKeys(t *testing.T) {
+	signer := randomPublicMaker(t)
+
+	token, _, err := signer.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	// another service only gets the published public keys
+	keyring := NewKeyring()
+	for _, key := range signer.Keyring().PublicKeys() {
+		require.NoError(t, keyring.AddPublicKey(key.ID, key.Key))
+	}
+	verifier, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyTokenForUser(token)
+	require.NoError(t, err)
+
+	_, _, err = verifier.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), time.Minute)
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestPasetoPublicMakerWrongKey(t *testing.T) {
+	signer := randomPublicMaker(t)
+	// same key id but another key
+	verifier := randomPublicMaker(t)
+
+	token, _, err := signer.CreateTokenForUser(util.RandomMoney(), util.RandomUser(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := verifier.VerifyTokenForUser(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	payload, err = verifier.VerifyTokenForUser("v4.public.not-a-token")
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestParseKeyring(t *testing.T) {
+	keyring, err := ParseKeyring(fmt.Sprintf("new:%s, old:%s", randomSecretKeyHex(), randomSecretKeyHex()))
+	require.NoError(t, err)
+	require.Equal(t, "new", keyring.CurrentID())
+	require.Len(t, keyring.PublicKeys(), 2)
+
+	_, err = ParseKeyring(randomSecretKeyHex())
+	require.Error(t, err)
+
+	_, err = ParseKeyring("k1:not-hex")
+	require.Error(t, err)
+
+	key := randomSecretKeyHex()
+	_, err = ParseKeyring(fmt.Sprintf("k1:%s,k1:%s", key, key))
+	require.ErrorIs(t, err, ErrDuplicateKeyID)
+
+	_, err = NewPasetoPublicMaker(NewKeyring())
+	require.Error(t, err)
+}
@@ -16,15 +16,17 @@ var (
 
 // Payload contains the user payload data of the token
 type UserPayload struct {
-	ID        uuid.UUID `json:"id"`
+	ID uuid.UUID `json:"id"`
+	// SessionID is the user_session the token was issued for
+	SessionID uuid.UUID `json:"session_id"`
 	UserID    int64     `json:"user_id"`
 	Username  string    `json:"username"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayloadForUser(userID int64, username string, duration time.Duration) (*UserPayload, error) {
+// NewPayload creates a new token payload with a specific username, session and duration
+func NewPayloadForUser(userID int64, username string, sessionID uuid.UUID, duration time.Duration) (*UserPayload, error) {
 	// tokenID, err := uuid.NewRandom()
 	tokenID, err := uuid.NewV7()
 	if err != nil {
@@ -33,6 +35,7 @@ func NewPayloadForUser(userID int64, username string, duration time.Duration) (*
 
 	payload := &UserPayload{
 		ID:        tokenID,
+		SessionID: sessionID,
 		UserID:    userID,
 		Username:  username,
 		IssuedAt:  time.Now(),
@@ -51,7 +54,9 @@ func (payload *UserPayload) ValidUser() error {
 
 // Payload contains the admin payload data of the token
 type AdminPayload struct {
-	ID        uuid.UUID `json:"id"`
+	ID uuid.UUID `json:"id"`
+	// SessionID is the admin_session the token was issued for
+	SessionID uuid.UUID `json:"session_id"`
 	AdminID   int64     `json:"admin_id"`
 	Username  string    `json:"username"`
 	TypeID    int64     `json:"type_id"`
@@ -60,8 +65,8 @@ type AdminPayload struct {
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific Admin and duration
-func NewPayloadForAdmin(adminID int64, username string, type_id int64, active bool, duration time.Duration) (*AdminPayload, error) {
+// NewPayload creates a new token payload with a specific Admin, session and duration
+func NewPayloadForAdmin(adminID int64, username string, type_id int64, active bool, sessionID uuid.UUID, duration time.Duration) (*AdminPayload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -69,6 +74,7 @@ func NewPayloadForAdmin(adminID int64, username string, type_id int64, active bo
 
 	payload := &AdminPayload{
 		ID:        tokenID,
+		SessionID: sessionID,
 		AdminID:   adminID,
 		Username:  username,
 		TypeID:    type_id,
@@ -0,0 +1,83 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the sha1 test vectors of RFC 6238, truncated to 6 digits
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tc := range testCases {
+		code, err := Code(secret, Step(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code, "unix: %d", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := GenerateSecret()
+	now := time.Now()
+	step := Step(now)
+
+	for _, offset := range []int64{-Skew, 0, Skew} {
+		code, err := Code(secret, step+offset)
+		require.NoError(t, err)
+
+		matched, err := Validate(secret, code, now)
+		require.NoError(t, err)
+		require.Equal(t, step+offset, matched)
+	}
+
+	code, err := Code(secret, step+Skew+1)
+	require.NoError(t, err)
+	_, err = Validate(secret, code, now)
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	_, err = Validate(secret, "12345", now)
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	_, err = Validate("not base32!", "123456", now)
+	require.ErrorIs(t, err, ErrInvalidSecret)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret := GenerateSecret()
+	require.Len(t, secret, 32)
+	require.NotEqual(t, secret, GenerateSecret())
+
+	key, err := decode(secret)
+	require.NoError(t, err)
+	require.Len(t, key, secretSize)
+}
+
+func TestURI(t *testing.T) {
+	secret := GenerateSecret()
+
+	uri, err := url.Parse(URI("CShop", "admin@example.com", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/CShop:admin@example.com", uri.Path)
+	require.Equal(t, secret, uri.Query().Get("secret"))
+	require.Equal(t, "CShop", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
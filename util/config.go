@@ -11,25 +11,47 @@ const (
 	ForeignKeyViolation = "foreign_key_violation"
 	UniqueViolation     = "unique_violation"
 	TokenHasExpired     = "token has expired"
+
+	// ForeignKeyViolationCode and UniqueViolationCode are the sqlstate of the errors,
+	// pgconn.PgError.Message is a human readable text that never equals their names
+	ForeignKeyViolationCode = "23503"
+	UniqueViolationCode     = "23505"
+
+	// DefaultStockReservationDuration is used when STOCK_RESERVATION_DURATION is not set
+	DefaultStockReservationDuration = 15 * time.Minute
+	// DefaultDeletionGracePeriod is used when ACCOUNT_DELETION_GRACE_PERIOD is not set
+	DefaultDeletionGracePeriod = 30 * 24 * time.Hour
 )
 
 // config stores all configuration of the application
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	DBDriver               string
-	DBSource               string
-	ServerAddress          string
-	RedisAddress           string
-	UserTokenSymmetricKey  string
-	AdminTokenSymmetricKey string
-	EmailSenderName        string
-	EmailSenderAddress     string
-	EmailSenderPassword    string
-	AccessTokenDuration    time.Duration
-	RefreshTokenDuration   time.Duration
-	ImageKitPrivateKey     string
-	ImageKitPublicKey      string
-	ImageKitUrlEndPoint    string
+	DBDriver                 string
+	DBSource                 string
+	ServerAddress            string
+	RedisAddress             string
+	UserTokenSymmetricKey    string
+	AdminTokenSymmetricKey   string
+	EmailSenderName          string
+	EmailSenderAddress       string
+	EmailSenderPassword      string
+	AccessTokenDuration      time.Duration
+	RefreshTokenDuration     time.Duration
+	StockReservationDuration time.Duration
+	ImageKitPrivateKey       string
+	ImageKitPublicKey        string
+	ImageKitUrlEndPoint      string
+	// FakePaymentWebhookSecret enables the in-process fake payment provider when it's set
+	FakePaymentWebhookSecret string
+	// UserTokenKeys and AdminTokenKeys are id:hex-secret-key lists that switch the tokens to v4.public,
+	// the first key signs and all of them verify
+	UserTokenKeys  string
+	AdminTokenKeys string
+	// DeletionGracePeriod is how long a user can cancel the deletion of the account
+	DeletionGracePeriod time.Duration
+	// TrustedProxies are the ips or cidr ranges of the reverse proxies that set the client ip,
+	// the loopback and private ranges of the docker networks are trusted when it's empty
+	TrustedProxies []string
 }
 
 func loadEnvVariable(environmentName string) (string, error) {
@@ -67,16 +89,17 @@ func LoadVault() (config *Config, err error) {
 	if err != nil {
 		return nil, err
 	}
-	// redisAddress, err := loadEnvVariable("REDIS_ADDRESS")
-	// if err != nil {
-	// 	return nil, err
-	// }
+	// the login throttle counts in memory when redis isn't configured
+	redisAddress, _ := loadEnvVariable("REDIS_ADDRESS")
+	// the symmetric keys are only needed when no keyring is configured
+	userTokenKeys, _ := loadEnvVariable("USER_TOKEN_KEYS")
 	userTokenSymmetricKey, err := loadEnvVariable("USER_TOKEN_SYMMETRIC_KEY")
-	if err != nil {
+	if err != nil && userTokenKeys == "" {
 		return nil, err
 	}
+	adminTokenKeys, _ := loadEnvVariable("ADMIN_TOKEN_KEYS")
 	adminTokenSymmetricKey, err := loadEnvVariable("ADMIN_TOKEN_SYMMETRIC_KEY")
-	if err != nil {
+	if err != nil && adminTokenKeys == "" {
 		return nil, err
 	}
 	emailSenderName, err := loadEnvVariable("EMAIL_SENDER_NAME")
@@ -122,20 +145,54 @@ func LoadVault() (config *Config, err error) {
 		return nil, err
 	}
 
+	stockReservationDuration := DefaultStockReservationDuration
+	if stockReservationDurationValue, err := loadEnvVariable("STOCK_RESERVATION_DURATION"); err == nil {
+		stockReservationDuration, err = time.ParseDuration(stockReservationDurationValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deletionGracePeriod := DefaultDeletionGracePeriod
+	if deletionGracePeriodValue, err := loadEnvVariable("ACCOUNT_DELETION_GRACE_PERIOD"); err == nil {
+		deletionGracePeriod, err = time.ParseDuration(deletionGracePeriodValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the fake payment provider is only for local runs
+	fakePaymentWebhookSecret, _ := loadEnvVariable("FAKE_PAYMENT_WEBHOOK_SECRET")
+
+	var trustedProxies []string
+	if trustedProxiesValue, err := loadEnvVariable("TRUSTED_PROXIES"); err == nil {
+		for _, proxy := range strings.Split(trustedProxiesValue, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				trustedProxies = append(trustedProxies, proxy)
+			}
+		}
+	}
+
 	return &Config{
-		DBDriver:               dbDriver,
-		DBSource:               dbSource,
-		ServerAddress:          serverAddress,
-		RedisAddress:           "",
-		UserTokenSymmetricKey:  userTokenSymmetricKey,
-		AdminTokenSymmetricKey: adminTokenSymmetricKey,
-		EmailSenderName:        emailSenderName,
-		EmailSenderAddress:     emailSenderAddress,
-		EmailSenderPassword:    emailSenderPassword,
-		AccessTokenDuration:    accessTokenDurration,
-		RefreshTokenDuration:   refreshTokenDuration,
-		ImageKitPrivateKey:     imageKitPrivateKey,
-		ImageKitPublicKey:      imageKitPublicKey,
-		ImageKitUrlEndPoint:    imageKitUrlEndPoint,
+		DBDriver:                 dbDriver,
+		DBSource:                 dbSource,
+		ServerAddress:            serverAddress,
+		RedisAddress:             redisAddress,
+		UserTokenSymmetricKey:    userTokenSymmetricKey,
+		AdminTokenSymmetricKey:   adminTokenSymmetricKey,
+		UserTokenKeys:            userTokenKeys,
+		AdminTokenKeys:           adminTokenKeys,
+		EmailSenderName:          emailSenderName,
+		EmailSenderAddress:       emailSenderAddress,
+		EmailSenderPassword:      emailSenderPassword,
+		AccessTokenDuration:      accessTokenDurration,
+		RefreshTokenDuration:     refreshTokenDuration,
+		StockReservationDuration: stockReservationDuration,
+		DeletionGracePeriod:      deletionGracePeriod,
+		ImageKitPrivateKey:       imageKitPrivateKey,
+		ImageKitPublicKey:        imageKitPublicKey,
+		ImageKitUrlEndPoint:      imageKitUrlEndPoint,
+		FakePaymentWebhookSecret: fakePaymentWebhookSecret,
+		TrustedProxies:           trustedProxies,
 	}, nil
 }
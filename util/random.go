@@ -9,12 +9,10 @@ import (
 	"github.com/quagmt/udecimal"
 )
 
+// the helpers of this file use math/rand and are only meant for tests and seed data,
+// otps, track numbers and secrets come from the secure package
 const alphabet = "abcdefghijklmnopqrstuvwxyz"
 
-func init() {
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-}
-
 // Random generate a random integer between min and max
 func RandomInt(min, max int64) int64 {
 	return min + rand.Int63n(max-min+1)
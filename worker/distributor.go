@@ -17,6 +17,26 @@ type TaskDistributor interface {
 		payload *PayloadSendResetPassword,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskSendOrderNotification(
+		ctx context.Context,
+		payload *PayloadSendOrderNotification,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskAuthorizePayment(
+		ctx context.Context,
+		payload *PayloadAuthorizePayment,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskCapturePayment(
+		ctx context.Context,
+		payload *PayloadCapturePayment,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskRefundPayment(
+		ctx context.Context,
+		payload *PayloadRefundPayment,
+		opts ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
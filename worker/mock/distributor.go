@@ -42,6 +42,82 @@ func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
 	return m.recorder
 }
 
+// DistributeTaskAuthorizePayment mocks base method.
+func (m *MockTaskDistributor) DistributeTaskAuthorizePayment(ctx context.Context, payload *worker.PayloadAuthorizePayment, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskAuthorizePayment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskAuthorizePayment indicates an expected call of DistributeTaskAuthorizePayment.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskAuthorizePayment(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskAuthorizePayment", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskAuthorizePayment), varargs...)
+}
+
+// DistributeTaskCapturePayment mocks base method.
+func (m *MockTaskDistributor) DistributeTaskCapturePayment(ctx context.Context, payload *worker.PayloadCapturePayment, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskCapturePayment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskCapturePayment indicates an expected call of DistributeTaskCapturePayment.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskCapturePayment(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskCapturePayment", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskCapturePayment), varargs...)
+}
+
+// DistributeTaskRefundPayment mocks base method.
+func (m *MockTaskDistributor) DistributeTaskRefundPayment(ctx context.Context, payload *worker.PayloadRefundPayment, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskRefundPayment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskRefundPayment indicates an expected call of DistributeTaskRefundPayment.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskRefundPayment(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskRefundPayment", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskRefundPayment), varargs...)
+}
+
+// DistributeTaskSendOrderNotification mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendOrderNotification(ctx context.Context, payload *worker.PayloadSendOrderNotification, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendOrderNotification", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendOrderNotification indicates an expected call of DistributeTaskSendOrderNotification.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendOrderNotification(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendOrderNotification", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendOrderNotification), varargs...)
+}
+
 // DistributeTaskSendResetPassword mocks base method.
 func (m *MockTaskDistributor) DistributeTaskSendResetPassword(ctx context.Context, payload *worker.PayloadSendResetPassword, opts ...asynq.Option) error {
 	m.ctrl.T.Helper()
@@ -3,8 +3,10 @@ package worker
 import (
 	"context"
 
+	firebase "firebase.google.com/go/v4"
 	db "github.com/cshop/v3/db/sqlc"
 	"github.com/cshop/v3/mail"
+	"github.com/cshop/v3/payment"
 	"github.com/cshop/v3/util"
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
@@ -21,19 +23,32 @@ type TaskProcessor interface {
 	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
 	ProcessTaskSendResetPassword(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReleaseExpiredStockReservations(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendOrderNotification(ctx context.Context, task *asynq.Task) error
+	ProcessTaskAnonymizeDeletedUsers(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeleteExpiredIdempotencyKeys(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeleteOldSearchQueryLogs(ctx context.Context, task *asynq.Task) error
+	ProcessTaskAuthorizePayment(ctx context.Context, task *asynq.Task) error
+	ProcessTaskCapturePayment(ctx context.Context, task *asynq.Task) error
+	ProcessTaskRefundPayment(ctx context.Context, task *asynq.Task) error
+	ProcessTaskRefundPendingPayments(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	config util.Config
-	server *asynq.Server
-	store  db.Store
-	mailer mail.EmailSender
+	config   util.Config
+	server   *asynq.Server
+	store    db.Store
+	mailer   mail.EmailSender
+	fb       *firebase.App
+	payments payment.Providers
 }
 
 func NewRedisTaskProcessor(
 	redisOpt asynq.RedisClientOpt,
 	store db.Store,
 	mailer mail.EmailSender,
+	fb *firebase.App,
+	payments payment.Providers,
 	config util.Config,
 ) TaskProcessor {
 	logger := NewLogger()
@@ -55,10 +70,12 @@ func NewRedisTaskProcessor(
 	)
 
 	return &RedisTaskProcessor{
-		config: config,
-		server: server,
-		store:  store,
-		mailer: mailer,
+		config:   config,
+		server:   server,
+		store:    store,
+		mailer:   mailer,
+		fb:       fb,
+		payments: payments,
 	}
 }
 
@@ -67,6 +84,15 @@ func (processor *RedisTaskProcessor) Start() error {
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
 	mux.HandleFunc(TaskSendResetPassword, processor.ProcessTaskSendResetPassword)
+	mux.HandleFunc(TaskReleaseExpiredStockReservations, processor.ProcessTaskReleaseExpiredStockReservations)
+	mux.HandleFunc(TaskSendOrderNotification, processor.ProcessTaskSendOrderNotification)
+	mux.HandleFunc(TaskAnonymizeDeletedUsers, processor.ProcessTaskAnonymizeDeletedUsers)
+	mux.HandleFunc(TaskDeleteExpiredIdempotencyKeys, processor.ProcessTaskDeleteExpiredIdempotencyKeys)
+	mux.HandleFunc(TaskDeleteOldSearchQueryLogs, processor.ProcessTaskDeleteOldSearchQueryLogs)
+	mux.HandleFunc(TaskAuthorizePayment, processor.ProcessTaskAuthorizePayment)
+	mux.HandleFunc(TaskCapturePayment, processor.ProcessTaskCapturePayment)
+	mux.HandleFunc(TaskRefundPayment, processor.ProcessTaskRefundPayment)
+	mux.HandleFunc(TaskRefundPendingPayments, processor.ProcessTaskRefundPendingPayments)
 
 	return processor.server.Start(mux)
 }
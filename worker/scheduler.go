@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	releaseExpiredStockReservationsSpec = "@every 1m"
+	anonymizeDeletedUsersSpec           = "@every 1h"
+	deleteExpiredIdempotencyKeysSpec    = "@every 1h"
+	deleteOldSearchQueryLogsSpec        = "@every 1h"
+	refundPendingPaymentsSpec           = "@every 10m"
+)
+
+type TaskScheduler interface {
+	Start() error
+	Shutdown()
+}
+
+type RedisTaskScheduler struct {
+	scheduler *asynq.Scheduler
+}
+
+// NewRedisTaskScheduler registers the periodic tasks, they are handled by the task processor
+func NewRedisTaskScheduler(redisOpt asynq.RedisClientOpt) (TaskScheduler, error) {
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		Logger: NewLogger(),
+	})
+
+	_, err := scheduler.Register(
+		releaseExpiredStockReservationsSpec,
+		asynq.NewTask(TaskReleaseExpiredStockReservations, nil),
+		asynq.Queue(QueueDefault),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register periodic task: %w", err)
+	}
+
+	_, err = scheduler.Register(
+		anonymizeDeletedUsersSpec,
+		asynq.NewTask(TaskAnonymizeDeletedUsers, nil),
+		asynq.Queue(QueueDefault),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register periodic task: %w", err)
+	}
+
+	_, err = scheduler.Register(
+		deleteExpiredIdempotencyKeysSpec,
+		asynq.NewTask(TaskDeleteExpiredIdempotencyKeys, nil),
+		asynq.Queue(QueueDefault),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register periodic task: %w", err)
+	}
+
+	_, err = scheduler.Register(
+		deleteOldSearchQueryLogsSpec,
+		asynq.NewTask(TaskDeleteOldSearchQueryLogs, nil),
+		asynq.Queue(QueueDefault),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register periodic task: %w", err)
+	}
+
+	_, err = scheduler.Register(
+		refundPendingPaymentsSpec,
+		asynq.NewTask(TaskRefundPendingPayments, nil),
+		asynq.Queue(QueueDefault),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register periodic task: %w", err)
+	}
+
+	return &RedisTaskScheduler{
+		scheduler: scheduler,
+	}, nil
+}
+
+func (scheduler *RedisTaskScheduler) Start() error {
+	return scheduler.scheduler.Start()
+}
+
+func (scheduler *RedisTaskScheduler) Shutdown() {
+	scheduler.scheduler.Shutdown()
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskAnonymizeDeletedUsers = "task:anonymize_deleted_users"
+	// anonymizeDeletedUsersBatch caps the users anonymized by a single run
+	anonymizeDeletedUsersBatch = 100
+)
+
+// ProcessTaskAnonymizeDeletedUsers anonymizes the users whose deletion grace period is over
+func (processor *RedisTaskProcessor) ProcessTaskAnonymizeDeletedUsers(
+	ctx context.Context,
+	task *asynq.Task,
+) error {
+	userIDs, err := processor.store.ListDueUserDeletions(ctx, anonymizeDeletedUsersBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list due user deletions: %w", err)
+	}
+
+	var anonymized int
+	for _, userID := range userIDs {
+		_, err := processor.store.AnonymizeUserTx(ctx, userID)
+		if err != nil {
+			// the deletion was cancelled since it was listed
+			if err == pgx.ErrNoRows {
+				continue
+			}
+			return fmt.Errorf("failed to anonymize user %d: %w", userID, err)
+		}
+		anonymized++
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("anonymized", anonymized).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/payment"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskAuthorizePayment = "task:authorize_payment"
+
+type PayloadAuthorizePayment struct {
+	PaymentTransactionID int64 `json:"payment_transaction_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskAuthorizePayment(
+	ctx context.Context,
+	payload *PayloadAuthorizePayment,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := sonic.ConfigFastest.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskAuthorizePayment, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskAuthorizePayment retries the authorization of a payment that couldn't be authorized
+// when the order was placed, and captures it right after like the purchase does
+func (processor *RedisTaskProcessor) ProcessTaskAuthorizePayment(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadAuthorizePayment
+	if err := sonic.ConfigFastest.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	paymentTransaction, err := processor.store.GetPaymentTransaction(ctx, payload.PaymentTransactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("payment transaction doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get payment transaction: %w", err)
+	}
+
+	if paymentTransaction.Status == db.PaymentStatusPending {
+		result, err := processor.payments.AuthorizeTransaction(ctx, processor.store, paymentTransaction)
+		if err != nil {
+			return fmt.Errorf("failed to authorize payment: %w", err)
+		}
+		paymentTransaction = result.PaymentTransaction
+	}
+
+	//? cash on delivery is only collected on delivery
+	if paymentTransaction.Status == db.PaymentStatusAuthorized && paymentTransaction.Provider != payment.ProviderCOD {
+		result, err := processor.payments.CaptureTransaction(ctx, processor.store, paymentTransaction)
+		if err != nil {
+			return fmt.Errorf("failed to capture payment: %w", err)
+		}
+		paymentTransaction = result.PaymentTransaction
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("status", paymentTransaction.Status).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	db "github.com/cshop/v3/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskCapturePayment = "task:capture_payment"
+
+type PayloadCapturePayment struct {
+	PaymentTransactionID int64 `json:"payment_transaction_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskCapturePayment(
+	ctx context.Context,
+	payload *PayloadCapturePayment,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := sonic.ConfigFastest.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskCapturePayment, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskCapturePayment retries the capture of an authorized payment the provider didn't collect
+func (processor *RedisTaskProcessor) ProcessTaskCapturePayment(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadCapturePayment
+	if err := sonic.ConfigFastest.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	paymentTransaction, err := processor.store.GetPaymentTransaction(ctx, payload.PaymentTransactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("payment transaction doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get payment transaction: %w", err)
+	}
+
+	//? the provider reported the capture or the payment moved on in the meantime
+	if paymentTransaction.Status != db.PaymentStatusAuthorized {
+		return nil
+	}
+
+	result, err := processor.payments.CaptureTransaction(ctx, processor.store, paymentTransaction)
+	if err != nil {
+		return fmt.Errorf("failed to capture payment: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("status", result.PaymentTransaction.Status).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskDeleteExpiredIdempotencyKeys = "task:delete_expired_idempotency_keys"
+
+// ProcessTaskDeleteExpiredIdempotencyKeys deletes the idempotency keys that can't be replayed anymore
+func (processor *RedisTaskProcessor) ProcessTaskDeleteExpiredIdempotencyKeys(
+	ctx context.Context,
+	task *asynq.Task,
+) error {
+	deleted, err := processor.store.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int64("deleted", deleted).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskDeleteOldSearchQueryLogs = "task:delete_old_search_query_logs"
+
+// ProcessTaskDeleteOldSearchQueryLogs deletes the logged searches that are too old to be suggested or counted
+func (processor *RedisTaskProcessor) ProcessTaskDeleteOldSearchQueryLogs(
+	ctx context.Context,
+	task *asynq.Task,
+) error {
+	deleted, err := processor.store.DeleteOldSearchQueryLogs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete old search query logs: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int64("deleted", deleted).Msg("processed task")
+	return nil
+}
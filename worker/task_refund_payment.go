@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskRefundPayment = "task:refund_payment"
+
+type PayloadRefundPayment struct {
+	PaymentTransactionID int64 `json:"payment_transaction_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskRefundPayment(
+	ctx context.Context,
+	payload *PayloadRefundPayment,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := sonic.ConfigFastest.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskRefundPayment, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskRefundPayment retries the refund of a payment the provider didn't make, with the same idempotency key
+func (processor *RedisTaskProcessor) ProcessTaskRefundPayment(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadRefundPayment
+	if err := sonic.ConfigFastest.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	result, err := processor.payments.RefundTransaction(ctx, processor.store, payload.PaymentTransactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("payment transaction doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("refunded", result.Refunded).Msg("processed task")
+	return nil
+}
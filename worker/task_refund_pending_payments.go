@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskRefundPendingPayments = "task:refund_pending_payments"
+
+// refundPendingPaymentsBatch caps the payments refunded by one run of the task
+const refundPendingPaymentsBatch = 100
+
+// ProcessTaskRefundPendingPayments makes the pending and in flight refunds that no refund task picked up,
+// like the ones recorded while the task couldn't be enqueued
+func (processor *RedisTaskProcessor) ProcessTaskRefundPendingPayments(
+	ctx context.Context,
+	task *asynq.Task,
+) error {
+	paymentTransactions, err := processor.store.ListPaymentTransactionsWithPendingRefund(ctx, refundPendingPaymentsBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list pending refunds: %w", err)
+	}
+
+	failed := 0
+	for _, paymentTransaction := range paymentTransactions {
+		_, err := processor.payments.RefundTransaction(ctx, processor.store, paymentTransaction.ID)
+		if err != nil {
+			log.Error().Err(err).Int64("payment_transaction_id", paymentTransaction.ID).Msg("failed to refund payment")
+			failed++
+		}
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("refunded", len(paymentTransactions)-failed).Int("failed", failed).Msg("processed task")
+	if failed > 0 {
+		return fmt.Errorf("failed to refund %d payments", failed)
+	}
+	return nil
+}
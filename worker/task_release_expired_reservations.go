@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskReleaseExpiredStockReservations = "task:release_expired_stock_reservations"
+
+func (processor *RedisTaskProcessor) ProcessTaskReleaseExpiredStockReservations(
+	ctx context.Context,
+	task *asynq.Task,
+) error {
+	released, err := processor.store.DeleteExpiredStockReservations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to release expired stock reservations: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int64("released", released).Msg("processed task")
+	return nil
+}
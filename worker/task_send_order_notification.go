@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"firebase.google.com/go/v4/messaging"
+	"github.com/bytedance/sonic"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendOrderNotification = "task:send_order_notification"
+
+type PayloadSendOrderNotification struct {
+	UserID      int64  `json:"user_id"`
+	ShopOrderID int64  `json:"shop_order_id"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendOrderNotification(
+	ctx context.Context,
+	payload *PayloadSendOrderNotification,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := sonic.ConfigFastest.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendOrderNotification, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendOrderNotification(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendOrderNotification
+	if err := sonic.ConfigFastest.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	notification, err := processor.store.GetNotificationV2(ctx, payload.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			//? the user never registered a device
+			return nil
+		}
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	if !notification.DeliveryUpdates || !notification.FcmToken.Valid {
+		return nil
+	}
+
+	fcmClient, err := processor.fb.Messaging(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get messaging client: %w", err)
+	}
+
+	message := &messaging.Message{
+		Data: map[string]string{
+			"page":          "orders",
+			"shop_order_id": fmt.Sprint(payload.ShopOrderID),
+		},
+		Notification: &messaging.Notification{
+			Title: payload.Title,
+			Body:  payload.Body,
+		},
+		Token: notification.FcmToken.String,
+	}
+
+	response, err := fcmClient.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send order notification: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("message_id", response).Msg("processed task")
+	return nil
+}
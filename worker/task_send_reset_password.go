@@ -6,6 +6,7 @@ import (
 
 	"github.com/bytedance/sonic"
 	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/util"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
@@ -52,9 +53,16 @@ func (processor *RedisTaskProcessor) ProcessTaskSendResetPassword(
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	// the link carries the secret, only its hash is stored
+	secretCode := secure.Secret(32)
+	hashedSecretCode, err := util.HashPassword(secretCode)
+	if err != nil {
+		return fmt.Errorf("failed to hash secret code: %w", err)
+	}
+
 	resetPassword, err := processor.store.CreateResetPassword(ctx, db.CreateResetPasswordParams{
 		UserID:     user.ID,
-		SecretCode: util.RandomString(32),
+		SecretCode: hashedSecretCode,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create verify email: %w", err)
@@ -63,7 +71,7 @@ func (processor *RedisTaskProcessor) ProcessTaskSendResetPassword(
 	subject := "Welcome to Classic Shop"
 	// TODO: replace this URL with an environment variable that points to a front-end page
 	verifyUrl := fmt.Sprintf("http://%s/api/v1/reset_password?email_id=%d&secret_code=%s", processor.config.ServerAddress,
-		resetPassword.ID, resetPassword.SecretCode)
+		resetPassword.ID, secretCode)
 	content := fmt.Sprintf(`Dear %s,<br/>
 	We received a request to reset your password. To proceed, please click the link below:<br/>
 	<a href="%s">Reset Password</a><br/>
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bytedance/sonic"
 	db "github.com/cshop/v3/db/sqlc"
+	"github.com/cshop/v3/secure"
 	"github.com/cshop/v3/util"
 	"github.com/guregu/null/v6"
 	"github.com/hibiken/asynq"
@@ -50,10 +51,17 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	// the link carries the secret, only its hash is stored
+	secretCode := secure.Secret(32)
+	hashedSecretCode, err := util.HashPassword(secretCode)
+	if err != nil {
+		return fmt.Errorf("failed to hash secret code: %w", err)
+	}
+
 	verifyEmail, err := processor.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
 		UserID: null.IntFrom(user.ID),
 		// Email:      user.Email,
-		SecretCode: util.RandomString(32),
+		SecretCode: hashedSecretCode,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create verify email: %w", err)
@@ -62,7 +70,7 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 	subject := "Welcome to Classic Shop"
 	// TODO: replace this URL with an environment variable that points to a front-end page
 	verifyUrl := fmt.Sprintf("http://localhost:8080/v1/verify_email?email_id=%d&secret_code=%s",
-		verifyEmail.ID, verifyEmail.SecretCode)
+		verifyEmail.ID, secretCode)
 	content := fmt.Sprintf(`Hello %s,<br/>
 	Thank you for registering with us!<br/>
 	Please <a href="%s">click here</a> to verify your email address.<br/>